@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -12,6 +13,12 @@ type AccountService service
 
 // New Creates a new account.
 func (a *AccountService) New(req acme.Account) (acme.ExtendedAccount, error) {
+	if !req.OnlyReturnExisting {
+		if err := a.core.CheckAccountKey(a.core.jws.PrivateKey()); err != nil {
+			return acme.ExtendedAccount{}, err
+		}
+	}
+
 	var account acme.Account
 	resp, err := a.core.post(a.core.GetDirectory().NewAccountURL, req, &account)
 	location := getLocation(resp)
@@ -81,3 +88,30 @@ func (a *AccountService) Deactivate(accountURL string) error {
 	_, err := a.core.post(accountURL, req, nil)
 	return err
 }
+
+// KeyChange Changes the key of the account (account key rollover): the new key is used by the following requests.
+// https://tools.ietf.org/html/rfc8555#section-7.3.5
+func (a *AccountService) KeyChange(newKey crypto.PrivateKey) error {
+	keyChangeURL := a.core.GetDirectory().KeyChangeURL
+	if len(keyChangeURL) == 0 {
+		return errors.New("account[keyChange]: the CA doesn't support the account key change")
+	}
+
+	if err := a.core.CheckAccountKey(newKey); err != nil {
+		return err
+	}
+
+	content, err := a.core.signKeyChangeContent(keyChangeURL, newKey)
+	if err != nil {
+		return fmt.Errorf("acme: error signing the key change content: %w", err)
+	}
+
+	_, err = a.core.retrievablePost(keyChangeURL, content, nil)
+	if err != nil {
+		return err
+	}
+
+	a.core.jws.SetPrivateKey(newKey)
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+func TestAccountService_KeyChange(t *testing.T) {
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var keyChange struct {
+		Account string          `json:"account"`
+		OldKey  jose.JSONWebKey `json:"oldKey"`
+	}
+
+	mux.HandleFunc("/keyChange", func(w http.ResponseWriter, r *http.Request) {
+		outer, err := readSignedBody(r, oldKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		inner, err := jose.ParseSigned(string(outer))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		content, err := inner.Verify(&newKey.PublicKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if inner.Signatures[0].Protected.JSONWebKey == nil {
+			http.Error(w, "missing jwk", http.StatusBadRequest)
+			return
+		}
+
+		err = json.Unmarshal(content, &keyChange)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", apiURL+"/account/1", oldKey)
+	require.NoError(t, err)
+
+	err = core.Accounts.KeyChange(newKey)
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/account/1", keyChange.Account)
+	assert.Equal(t, &oldKey.PublicKey, keyChange.OldKey.Key)
+	assert.Equal(t, newKey, core.jws.PrivateKey())
+}
+
+func TestCore_CheckAccountKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := tester.WriteJSONResponse(w, acme.Directory{
+			NewAccountURL: "https://example.com/account",
+			NewOrderURL:   "https://example.com/order",
+			Meta:          acme.Meta{SignatureAlgorithms: []string{"ES256"}},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	core, err := New(http.DefaultClient, "lego-test", ts.URL, "", rsaKey)
+	require.NoError(t, err)
+
+	require.NoError(t, core.CheckAccountKey(ecKey))
+
+	err = core.CheckAccountKey(rsaKey)
+	require.EqualError(t, err, "acme: the CA doesn't accept RS256 account keys (accepted: ES256)")
+
+	_, err = core.Accounts.New(acme.Account{TermsOfServiceAgreed: true})
+	require.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -35,10 +36,15 @@ type Core struct {
 }
 
 // New Creates a new Core.
-func New(httpClient *http.Client, userAgent string, caDirURL, kid string, privateKey crypto.PrivateKey) (*Core, error) {
+func New(httpClient *http.Client, userAgent string, caDirURL, kid string, privateKey crypto.PrivateKey, opts ...Option) (*Core, error) {
 	doer := sender.NewDoer(httpClient, userAgent)
 
-	dir, err := getDirectory(doer, caDirURL)
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dir, err := getDirectory(doer, caDirURL, o)
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +149,37 @@ func (a *Core) signEABContent(newAccountURL, kid string, hmac []byte) ([]byte, e
 	return []byte(eabJWS.FullSerialize()), nil
 }
 
+func (a *Core) signKeyChangeContent(keyChangeURL string, newKey crypto.PrivateKey) ([]byte, error) {
+	keyChangeJWS, err := a.jws.SignKeyChangeContent(keyChangeURL, newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(keyChangeJWS.FullSerialize()), nil
+}
+
+// CheckAccountKey checks that an account key is supported,
+// and that its algorithm is accepted by the CA (only if the CA advertises the accepted algorithms).
+func (a *Core) CheckAccountKey(privateKey crypto.PrivateKey) error {
+	alg, err := secure.SignatureAlgorithm(privateKey)
+	if err != nil {
+		return fmt.Errorf("acme: invalid account key: %w", err)
+	}
+
+	accepted := a.directory.Meta.SignatureAlgorithms
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	for _, name := range accepted {
+		if strings.EqualFold(name, string(alg)) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("acme: the CA doesn't accept %s account keys (accepted: %s)", alg, strings.Join(accepted, ", "))
+}
+
 // GetKeyAuthorization Gets the key authorization
 func (a *Core) GetKeyAuthorization(token string) (string, error) {
 	return a.jws.GetKeyAuthorization(token)
@@ -152,18 +189,9 @@ func (a *Core) GetDirectory() acme.Directory {
 	return a.directory
 }
 
-func getDirectory(do *sender.Doer, caDirURL string) (acme.Directory, error) {
-	var dir acme.Directory
-	if _, err := do.Get(caDirURL, &dir); err != nil {
-		return dir, fmt.Errorf("get directory at '%s': %w", caDirURL, err)
-	}
-
-	if dir.NewAccountURL == "" {
-		return dir, errors.New("directory missing new registration URL")
-	}
-	if dir.NewOrderURL == "" {
-		return dir, errors.New("directory missing new order URL")
-	}
-
-	return dir, nil
+// ClockSkew returns the difference between the time of the CA (the Date header of its last response) and the local time:
+// positive if the local clock is behind the CA.
+// It returns false if no response of the CA had a Date header yet.
+func (a *Core) ClockSkew() (time.Duration, bool) {
+	return a.doer.ClockSkew()
 }
@@ -10,14 +10,19 @@ type ChallengeService service
 
 // New Creates a challenge.
 func (c *ChallengeService) New(chlgURL string) (acme.ExtendedChallenge, error) {
+	// Challenge initiation is done by sending a JWS payload containing the trivial JSON object `{}`.
+	// We use an empty struct instance as the postJSON payload here to achieve this result.
+	return c.NewWithPayload(chlgURL, struct{}{})
+}
+
+// NewWithPayload Creates a challenge, the payload is the response of the challenge (ex: onion-csr-01).
+func (c *ChallengeService) NewWithPayload(chlgURL string, payload interface{}) (acme.ExtendedChallenge, error) {
 	if len(chlgURL) == 0 {
 		return acme.ExtendedChallenge{}, errors.New("challenge[new]: empty URL")
 	}
 
-	// Challenge initiation is done by sending a JWS payload containing the trivial JSON object `{}`.
-	// We use an empty struct instance as the postJSON payload here to achieve this result.
 	var chlng acme.ExtendedChallenge
-	resp, err := c.core.post(chlgURL, struct{}{}, &chlng)
+	resp, err := c.core.post(chlgURL, payload, &chlng)
 	if err != nil {
 		return acme.ExtendedChallenge{}, err
 	}
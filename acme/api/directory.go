@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/acme/api/internal/sender"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// CachedDirectory a directory stored in a DirectoryCache, with the information needed to revalidate it.
+type CachedDirectory struct {
+	Directory acme.Directory `json:"directory"`
+
+	// ETag and LastModified the validators of the directory (ETag and Last-Modified response headers).
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+
+	// Expires the end of the freshness of the directory: until then, the directory is used without request.
+	Expires time.Time `json:"expires"`
+}
+
+// DirectoryCache stores the directories of the CAs, so they are not fetched again by every client.
+type DirectoryCache interface {
+	// Load returns the cached directory of the CA, nil if none.
+	Load(caDirURL string) (*CachedDirectory, error)
+	// Store stores the directory of the CA.
+	Store(caDirURL string, entry *CachedDirectory) error
+}
+
+// Option configures the Core.
+type Option func(*options)
+
+type options struct {
+	directoryCache DirectoryCache
+	directoryTTL   time.Duration
+}
+
+// WithDirectoryCache uses a cache for the directory of the CA.
+// A cached directory is fresh for the max-age of the response (Cache-Control), at least ttl.
+// A stale directory is revalidated with a conditional request (ETag, Last-Modified) when the CA supports it.
+func WithDirectoryCache(cache DirectoryCache, ttl time.Duration) Option {
+	return func(o *options) {
+		o.directoryCache = cache
+		o.directoryTTL = ttl
+	}
+}
+
+func getDirectory(do *sender.Doer, caDirURL string, opts options) (acme.Directory, error) {
+	if opts.directoryCache == nil {
+		return fetchDirectory(do, caDirURL)
+	}
+
+	cached, err := opts.directoryCache.Load(caDirURL)
+	if err != nil {
+		log.Warnf("acme: could not load the cached directory: %v", err)
+		cached = nil
+	}
+
+	if cached != nil && time.Now().Before(cached.Expires) {
+		return cached.Directory, nil
+	}
+
+	entry := &CachedDirectory{}
+	if cached != nil {
+		entry.ETag = cached.ETag
+		entry.LastModified = cached.LastModified
+	}
+
+	resp, err := do.GetConditional(caDirURL, entry.ETag, entry.LastModified, &entry.Directory)
+	if err != nil {
+		return acme.Directory{}, fmt.Errorf("get directory at '%s': %w", caDirURL, err)
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		entry.Directory = cached.Directory
+	} else {
+		if err = checkDirectory(entry.Directory); err != nil {
+			return entry.Directory, err
+		}
+
+		entry.ETag = resp.Header.Get("ETag")
+		entry.LastModified = resp.Header.Get("Last-Modified")
+	}
+
+	entry.Expires = time.Now().Add(freshness(resp.Header.Get("Cache-Control"), opts.directoryTTL))
+
+	if err = opts.directoryCache.Store(caDirURL, entry); err != nil {
+		log.Warnf("acme: could not cache the directory: %v", err)
+	}
+
+	return entry.Directory, nil
+}
+
+func fetchDirectory(do *sender.Doer, caDirURL string) (acme.Directory, error) {
+	var dir acme.Directory
+	if _, err := do.Get(caDirURL, &dir); err != nil {
+		return dir, fmt.Errorf("get directory at '%s': %w", caDirURL, err)
+	}
+
+	return dir, checkDirectory(dir)
+}
+
+func checkDirectory(dir acme.Directory) error {
+	if dir.NewAccountURL == "" {
+		return errors.New("directory missing new registration URL")
+	}
+	if dir.NewOrderURL == "" {
+		return errors.New("directory missing new order URL")
+	}
+
+	return nil
+}
+
+// freshness returns the freshness lifetime of a response: its max-age (Cache-Control), at least ttl.
+func freshness(cacheControl string, ttl time.Duration) time.Duration {
+	var maxAge time.Duration
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		if !strings.HasPrefix(directive, "max-age=") {
+			continue
+		}
+
+		seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+		if err == nil && seconds > 0 {
+			maxAge = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if maxAge < ttl {
+		return ttl
+	}
+	return maxAge
+}
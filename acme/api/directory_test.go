@@ -0,0 +1,154 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryDirectoryCache map[string]*CachedDirectory
+
+func (m memoryDirectoryCache) Load(caDirURL string) (*CachedDirectory, error) {
+	return m[caDirURL], nil
+}
+
+func (m memoryDirectoryCache) Store(caDirURL string, entry *CachedDirectory) error {
+	m[caDirURL] = entry
+	return nil
+}
+
+func setupDirectoryServer(t *testing.T, cacheControl string) (string, *int, *int) {
+	t.Helper()
+
+	var fetches, notModified int
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fetches++
+
+		w.Header().Set("ETag", `"v1"`)
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+
+		err := tester.WriteJSONResponse(w, acme.Directory{
+			NewNonceURL:   ts.URL + "/nonce",
+			NewAccountURL: ts.URL + "/account",
+			NewOrderURL:   ts.URL + "/newOrder",
+			Meta: acme.Meta{
+				Website:       "https://example.com",
+				CaaIdentities: []string{"example.com"},
+				Profiles:      map[string]string{"classic": "The classic profile"},
+			},
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	return ts.URL + "/dir", &fetches, &notModified
+}
+
+func TestNew_directoryCache_fresh(t *testing.T) {
+	dirURL, fetches, notModified := setupDirectoryServer(t, "")
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	cache := memoryDirectoryCache{}
+
+	for i := 0; i < 3; i++ {
+		core, err := New(http.DefaultClient, "lego-test", dirURL, "", privateKey, WithDirectoryCache(cache, time.Hour))
+		require.NoError(t, err)
+
+		meta := core.GetDirectory().Meta
+		assert.Equal(t, "https://example.com", meta.Website)
+		assert.Equal(t, []string{"example.com"}, meta.CaaIdentities)
+		assert.Equal(t, map[string]string{"classic": "The classic profile"}, meta.Profiles)
+	}
+
+	assert.Equal(t, 1, *fetches)
+	assert.Equal(t, 0, *notModified)
+}
+
+func TestNew_directoryCache_revalidate(t *testing.T) {
+	dirURL, fetches, notModified := setupDirectoryServer(t, "")
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	cache := memoryDirectoryCache{}
+
+	_, err = New(http.DefaultClient, "lego-test", dirURL, "", privateKey, WithDirectoryCache(cache, 0))
+	require.NoError(t, err)
+
+	cache[dirURL].Expires = time.Now().Add(-time.Minute)
+
+	core, err := New(http.DefaultClient, "lego-test", dirURL, "", privateKey, WithDirectoryCache(cache, time.Hour))
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://example.com", core.GetDirectory().Meta.Website)
+	assert.Equal(t, 1, *fetches)
+	assert.Equal(t, 1, *notModified)
+	assert.True(t, cache[dirURL].Expires.After(time.Now().Add(59*time.Minute)))
+}
+
+func TestNew_directoryCache_maxAge(t *testing.T) {
+	dirURL, fetches, _ := setupDirectoryServer(t, "public, max-age=86400")
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	cache := memoryDirectoryCache{}
+
+	_, err = New(http.DefaultClient, "lego-test", dirURL, "", privateKey, WithDirectoryCache(cache, time.Minute))
+	require.NoError(t, err)
+
+	assert.True(t, cache[dirURL].Expires.After(time.Now().Add(23*time.Hour)))
+
+	_, err = New(http.DefaultClient, "lego-test", dirURL, "", privateKey, WithDirectoryCache(cache, time.Minute))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, *fetches)
+}
+
+func Test_freshness(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		cacheControl string
+		ttl          time.Duration
+		expected     time.Duration
+	}{
+		{desc: "no header", ttl: time.Hour, expected: time.Hour},
+		{desc: "max-age longer", cacheControl: "public, max-age=7200", ttl: time.Hour, expected: 2 * time.Hour},
+		{desc: "max-age shorter", cacheControl: "max-age=60", ttl: time.Hour, expected: time.Hour},
+		{desc: "invalid max-age", cacheControl: "max-age=abc", expected: 0},
+		{desc: "no-cache", cacheControl: "no-cache", expected: 0},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, freshness(test.cacheControl, test.ttl))
+		})
+	}
+}
@@ -5,15 +5,26 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/go-acme/lego/v3/acme/api/internal/sender"
+	"github.com/go-acme/lego/v3/platform/clock"
 )
 
+// maxNonceAge is the age after which a stored nonce is considered expired by the server.
+const maxNonceAge = 5 * time.Minute
+
+type storedNonce struct {
+	value    string
+	received time.Time
+}
+
 // Manager Manages nonces.
 type Manager struct {
 	do       *sender.Doer
 	nonceURL string
-	nonces   []string
+	nonces   []storedNonce
+	clock    clock.Clock
 	sync.Mutex
 }
 
@@ -22,28 +33,35 @@ func NewManager(do *sender.Doer, nonceURL string) *Manager {
 	return &Manager{
 		do:       do,
 		nonceURL: nonceURL,
+		clock:    clock.Real,
 	}
 }
 
 // Pop Pops a nonce.
+// The expired nonces are discarded.
 func (n *Manager) Pop() (string, bool) {
 	n.Lock()
 	defer n.Unlock()
 
-	if len(n.nonces) == 0 {
-		return "", false
+	now := n.clock.Now()
+
+	for len(n.nonces) > 0 {
+		nonce := n.nonces[len(n.nonces)-1]
+		n.nonces = n.nonces[:len(n.nonces)-1]
+
+		if now.Sub(nonce.received) < maxNonceAge {
+			return nonce.value, true
+		}
 	}
 
-	nonce := n.nonces[len(n.nonces)-1]
-	n.nonces = n.nonces[:len(n.nonces)-1]
-	return nonce, true
+	return "", false
 }
 
 // Push Pushes a nonce.
 func (n *Manager) Push(nonce string) {
 	n.Lock()
 	defer n.Unlock()
-	n.nonces = append(n.nonces, nonce)
+	n.nonces = append(n.nonces, storedNonce{value: nonce, received: n.clock.Now()})
 }
 
 // Nonce implement jose.NonceSource
@@ -8,7 +8,10 @@ import (
 
 	"github.com/go-acme/lego/v3/acme"
 	"github.com/go-acme/lego/v3/acme/api/internal/sender"
+	"github.com/go-acme/lego/v3/platform/clock"
 	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNotHoldingLockWhileMakingHTTPRequests(t *testing.T) {
@@ -53,3 +56,21 @@ func TestNotHoldingLockWhileMakingHTTPRequests(t *testing.T) {
 		t.Fatal("JWS is probably holding a lock while making HTTP request")
 	}
 }
+
+func TestManager_Pop_expired(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+
+	j := NewManager(nil, "")
+	j.clock = clk
+
+	j.Push("old")
+	clk.Advance(maxNonceAge)
+	j.Push("recent")
+
+	nonce, ok := j.Pop()
+	require.True(t, ok)
+	assert.Equal(t, "recent", nonce)
+
+	_, ok = j.Pop()
+	assert.False(t, ok, "the expired nonce must be discarded")
+}
@@ -6,12 +6,21 @@ import (
 	"crypto/elliptic"
 	"crypto/rsa"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/go-acme/lego/v3/acme/api/internal/nonces"
+	"github.com/go-acme/lego/v3/certcrypto"
 	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/cryptosigner"
 )
 
+func init() {
+	// the signatures use the source of randomness of lego.
+	jose.RandReader = certcrypto.Reader
+}
+
 // JWS Represents a JWS.
 type JWS struct {
 	privKey crypto.PrivateKey
@@ -20,6 +29,7 @@ type JWS struct {
 }
 
 // NewJWS Create a new JWS.
+// The private key can be an RSA or ECDSA private key, or a crypto.Signer delegating the signatures (ex: to an agent).
 func NewJWS(privateKey crypto.PrivateKey, kid string, nonceManager *nonces.Manager) *JWS {
 	return &JWS{
 		privKey: privateKey,
@@ -33,23 +43,26 @@ func (j *JWS) SetKid(kid string) {
 	j.kid = kid
 }
 
+// SetPrivateKey Sets the private key (ex: after an account key change).
+func (j *JWS) SetPrivateKey(privateKey crypto.PrivateKey) {
+	j.privKey = privateKey
+}
+
+// PrivateKey returns the private key.
+func (j *JWS) PrivateKey() crypto.PrivateKey {
+	return j.privKey
+}
+
 // SignContent Signs a content with the JWS.
 func (j *JWS) SignContent(url string, content []byte) (*jose.JSONWebSignature, error) {
-	var alg jose.SignatureAlgorithm
-	switch k := j.privKey.(type) {
-	case *rsa.PrivateKey:
-		alg = jose.RS256
-	case *ecdsa.PrivateKey:
-		if k.Curve == elliptic.P256() {
-			alg = jose.ES256
-		} else if k.Curve == elliptic.P384() {
-			alg = jose.ES384
-		}
+	alg, err := SignatureAlgorithm(j.privKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign content with the %T key: %w", j.privKey, err)
 	}
 
 	signKey := jose.SigningKey{
 		Algorithm: alg,
-		Key:       jose.JSONWebKey{Key: j.privKey, KeyID: j.kid},
+		Key:       jose.JSONWebKey{Key: signingKey(j.privKey), KeyID: j.kid},
 	}
 
 	options := jose.SignerOptions{
@@ -77,8 +90,8 @@ func (j *JWS) SignContent(url string, content []byte) (*jose.JSONWebSignature, e
 
 // SignEABContent Signs an external account binding content with the JWS.
 func (j *JWS) SignEABContent(url, kid string, hmac []byte) (*jose.JSONWebSignature, error) {
-	jwk := jose.JSONWebKey{Key: j.privKey}
-	jwkJSON, err := jwk.Public().MarshalJSON()
+	jwk := jose.JSONWebKey{Key: j.publicKey()}
+	jwkJSON, err := jwk.MarshalJSON()
 	if err != nil {
 		return nil, fmt.Errorf("acme: error encoding eab jwk key: %w", err)
 	}
@@ -105,18 +118,51 @@ func (j *JWS) SignEABContent(url, kid string, hmac []byte) (*jose.JSONWebSignatu
 	return signed, nil
 }
 
-// GetKeyAuthorization Gets the key authorization for a token.
-func (j *JWS) GetKeyAuthorization(token string) (string, error) {
-	var publicKey crypto.PublicKey
-	switch k := j.privKey.(type) {
-	case *ecdsa.PrivateKey:
-		publicKey = k.Public()
-	case *rsa.PrivateKey:
-		publicKey = k.Public()
+// SignKeyChangeContent Signs the inner JWS of an account key change with the new key.
+// https://tools.ietf.org/html/rfc8555#section-7.3.5
+func (j *JWS) SignKeyChangeContent(url string, newKey crypto.PrivateKey) (*jose.JSONWebSignature, error) {
+	if j.kid == "" {
+		return nil, errors.New("an account is required to change its key")
+	}
+
+	alg, err := SignatureAlgorithm(newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.Marshal(map[string]interface{}{
+		"account": j.kid,
+		"oldKey":  jose.JSONWebKey{Key: j.publicKey()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("acme: error encoding the key change: %w", err)
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: alg, Key: signingKey(newKey)},
+		&jose.SignerOptions{
+			EmbedJWK: true,
+			ExtraHeaders: map[jose.HeaderKey]interface{}{
+				"url": url,
+			},
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key change jose signer: %w", err)
 	}
 
+	signed, err := signer.Sign(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the key change content: %w", err)
+	}
+
+	return signed, nil
+}
+
+// GetKeyAuthorization Gets the key authorization for a token.
+func (j *JWS) GetKeyAuthorization(token string) (string, error) {
 	// Generate the Key Authorization for the challenge
-	jwk := &jose.JSONWebKey{Key: publicKey}
+	jwk := &jose.JSONWebKey{Key: j.publicKey()}
 
 	thumbBytes, err := jwk.Thumbprint(crypto.SHA256)
 	if err != nil {
@@ -128,3 +174,51 @@ func (j *JWS) GetKeyAuthorization(token string) (string, error) {
 
 	return token + "." + keyThumb, nil
 }
+
+// publicKey returns the public key of the private key.
+func (j *JWS) publicKey() crypto.PublicKey {
+	if signer, ok := j.privKey.(crypto.Signer); ok {
+		return signer.Public()
+	}
+	return nil
+}
+
+// SignatureAlgorithm returns the JWS algorithm of a private key: RS256, ES256 or ES384.
+// In FIPS mode, the key must be FIPS-approved (see certcrypto.SetFIPSMode).
+func SignatureAlgorithm(privateKey crypto.PrivateKey) (jose.SignatureAlgorithm, error) {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return "", fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+
+	if err := certcrypto.CheckFIPSKey(signer.Public()); err != nil {
+		return "", fmt.Errorf("account key: %w", err)
+	}
+
+	switch k := signer.Public().(type) {
+	case *rsa.PublicKey:
+		return jose.RS256, nil
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P256() {
+			return jose.ES256, nil
+		} else if k.Curve == elliptic.P384() {
+			return jose.ES384, nil
+		}
+		return "", fmt.Errorf("unsupported elliptic curve: %s", k.Curve.Params().Name)
+	default:
+		return "", fmt.Errorf("unsupported public key type: %T", k)
+	}
+}
+
+// signingKey returns the key used by the jose signer.
+// The keys which are not RSA or ECDSA private keys are used through their crypto.Signer implementation.
+func signingKey(privateKey crypto.PrivateKey) interface{} {
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+		return k
+	case crypto.Signer:
+		return cryptosigner.Opaque(k)
+	default:
+		return k
+	}
+}
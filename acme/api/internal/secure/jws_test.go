@@ -1,6 +1,11 @@
 package secure
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,7 +14,10 @@ import (
 	"github.com/go-acme/lego/v3/acme"
 	"github.com/go-acme/lego/v3/acme/api/internal/nonces"
 	"github.com/go-acme/lego/v3/acme/api/internal/sender"
+	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNotHoldingLockWhileMakingHTTPRequests(t *testing.T) {
@@ -54,3 +62,83 @@ func TestNotHoldingLockWhileMakingHTTPRequests(t *testing.T) {
 		t.Fatal("JWS is probably holding a lock while making HTTP request")
 	}
 }
+
+// signerOnly hides the concrete type of a private key, like a remote signer.
+type signerOnly struct {
+	crypto.Signer
+}
+
+func TestJWS_SignContent_cryptoSigner(t *testing.T) {
+	testCases := []struct {
+		desc string
+		key  func() (crypto.Signer, error)
+	}{
+		{
+			desc: "ECDSA",
+			key: func() (crypto.Signer, error) {
+				return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			},
+		},
+		{
+			desc: "RSA",
+			key: func() (crypto.Signer, error) {
+				return rsa.GenerateKey(rand.Reader, 1024)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			privateKey, err := test.key()
+			require.NoError(t, err)
+
+			nonceManager := nonces.NewManager(nil, "")
+			nonceManager.Push("nonce")
+
+			j := NewJWS(signerOnly{Signer: privateKey}, "", nonceManager)
+
+			signed, err := j.SignContent("https://example.com/acme/new-acct", []byte(`{}`))
+			require.NoError(t, err)
+
+			payload, err := signed.Verify(privateKey.Public())
+			require.NoError(t, err)
+			assert.Equal(t, `{}`, string(payload))
+
+			keyAuth, err := j.GetKeyAuthorization("token")
+			require.NoError(t, err)
+
+			expected, err := NewJWS(privateKey, "", nonceManager).GetKeyAuthorization("token")
+			require.NoError(t, err)
+			assert.Equal(t, expected, keyAuth)
+		})
+	}
+}
+
+func TestJWS_SignContent_unsupportedKey(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	j := NewJWS(privateKey, "", nonces.NewManager(nil, ""))
+
+	_, err = j.SignContent("https://example.com/acme/new-acct", []byte(`{}`))
+	require.EqualError(t, err, "failed to sign content with the *ecdsa.PrivateKey key: unsupported elliptic curve: P-521")
+}
+
+func TestSignatureAlgorithm_fipsMode(t *testing.T) {
+	certcrypto.SetFIPSMode(true)
+	defer certcrypto.SetFIPSMode(false)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = SignatureAlgorithm(rsaKey)
+	require.EqualError(t, err, "account key: RSA key of 1024 bits: not FIPS-approved")
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	alg, err := SignatureAlgorithm(ecKey)
+	require.NoError(t, err)
+	assert.EqualValues(t, "ES256", alg)
+}
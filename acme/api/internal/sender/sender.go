@@ -1,17 +1,29 @@
 package sender
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
+	"mime"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-acme/lego/v3/acme"
 )
 
+// maxBodySize is the maximum size of a JSON response body that we will read.
+const maxBodySize = 1024 * 1024
+
+// maxExcerptSize is the maximum size of the body excerpt in an UnexpectedResponseError.
+const maxExcerptSize = 256
+
 type RequestOption func(*http.Request) error
 
 func contentType(ct string) RequestOption {
@@ -21,9 +33,28 @@ func contentType(ct string) RequestOption {
 	}
 }
 
+func conditional(etag, lastModified string) RequestOption {
+	return func(req *http.Request) error {
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+		return nil
+	}
+}
+
 type Doer struct {
 	httpClient *http.Client
 	userAgent  string
+
+	// now the local time, when the Date headers of the responses are received.
+	now func() time.Time
+
+	mu        sync.Mutex
+	skew      time.Duration
+	skewKnown bool
 }
 
 // NewDoer Creates a new Doer.
@@ -31,9 +62,36 @@ func NewDoer(client *http.Client, userAgent string) *Doer {
 	return &Doer{
 		httpClient: client,
 		userAgent:  userAgent,
+		now:        time.Now,
 	}
 }
 
+// ClockSkew returns the difference between the time of the server (the Date header of the last response) and the local time:
+// positive if the local clock is behind the server.
+// It returns false if no response had a Date header.
+// The precision of the Date header is one second.
+func (d *Doer) ClockSkew() (time.Duration, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.skew, d.skewKnown
+}
+
+// observeDate records the clock skew from the Date header of a response.
+func (d *Doer) observeDate(resp *http.Response) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	skew := date.Sub(d.now().Truncate(time.Second))
+
+	d.mu.Lock()
+	d.skew = skew
+	d.skewKnown = true
+	d.mu.Unlock()
+}
+
 // Get performs a GET request with a proper User-Agent string.
 // If "response" is not provided, callers should close resp.Body when done reading from it.
 func (d *Doer) Get(url string, response interface{}) (*http.Response, error) {
@@ -45,6 +103,29 @@ func (d *Doer) Get(url string, response interface{}) (*http.Response, error) {
 	return d.do(req, response)
 }
 
+// GetConditional performs a conditional GET request (If-None-Match, If-Modified-Since) with a proper User-Agent string.
+// If the resource is not modified (304 Not Modified), the response body is closed and not parsed.
+func (d *Doer) GetConditional(url, etag, lastModified string, response interface{}) (*http.Response, error) {
+	req, err := d.newRequest(http.MethodGet, url, nil, conditional(etag, lastModified))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	d.observeDate(resp)
+
+	if resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		return resp, nil
+	}
+
+	return d.handle(req, resp, response)
+}
+
 // Head performs a HEAD request with a proper User-Agent string.
 // The response body (resp.Body) is already closed when this function returns.
 func (d *Doer) Head(url string) (*http.Response, error) {
@@ -91,17 +172,28 @@ func (d *Doer) do(req *http.Request, response interface{}) (*http.Response, erro
 		return nil, err
 	}
 
-	if err = checkError(req, resp); err != nil {
+	d.observeDate(resp)
+
+	return d.handle(req, resp, response)
+}
+
+// handle checks the response, and parses its body into response if provided.
+func (d *Doer) handle(req *http.Request, resp *http.Response, response interface{}) (*http.Response, error) {
+	if err := checkError(req, resp); err != nil {
 		return resp, err
 	}
 
 	if response != nil {
-		raw, err := ioutil.ReadAll(resp.Body)
+		defer resp.Body.Close()
+
+		raw, err := readBody(resp)
 		if err != nil {
-			return resp, err
+			return resp, fmt.Errorf("%s :: %s :: %w", req.Method, req.URL, err)
 		}
 
-		defer resp.Body.Close()
+		if !isJSON(resp, raw) {
+			return resp, newUnexpectedResponseError(req, resp, raw)
+		}
 
 		err = json.Unmarshal(raw, response)
 		if err != nil {
@@ -120,19 +212,25 @@ func (d *Doer) formatUserAgent() string {
 
 func checkError(req *http.Request, resp *http.Response) error {
 	if resp.StatusCode >= http.StatusBadRequest {
-		body, err := ioutil.ReadAll(resp.Body)
+		body, err := readBody(resp)
 		if err != nil {
 			return fmt.Errorf("%d :: %s :: %s :: %w", resp.StatusCode, req.Method, req.URL, err)
 		}
 
-		var errorDetails *acme.ProblemDetails
-		err = json.Unmarshal(body, &errorDetails)
+		if !isJSON(resp, body) {
+			return newUnexpectedResponseError(req, resp, body)
+		}
+
+		// allocated before the decoding: a "null" document would leave a nil pointer.
+		errorDetails := &acme.ProblemDetails{}
+		err = json.Unmarshal(body, errorDetails)
 		if err != nil {
 			return fmt.Errorf("%d ::%s :: %s :: %w :: %s", resp.StatusCode, req.Method, req.URL, err, string(body))
 		}
 
 		errorDetails.Method = req.Method
 		errorDetails.URL = req.URL.String()
+		errorDetails.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
 
 		// Check for errors we handle specifically
 		if errorDetails.HTTPStatus == http.StatusBadRequest && errorDetails.Type == acme.BadNonceErr {
@@ -143,3 +241,73 @@ func checkError(req *http.Request, resp *http.Response) error {
 	}
 	return nil
 }
+
+// readBody reads the response body, up to maxBodySize.
+func readBody(resp *http.Response) ([]byte, error) {
+	raw, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) > maxBodySize {
+		return nil, fmt.Errorf("the response body is too large (more than %d bytes)", maxBodySize)
+	}
+
+	return raw, nil
+}
+
+// isJSON checks if the response is a JSON document.
+// The body is only used to detect JSON documents sent with a generic content type (ex: text/plain).
+func isJSON(resp *http.Response, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err == nil {
+		switch {
+		case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+			return true
+		case mediaType == "text/html", mediaType == "application/xhtml+xml":
+			return false
+		}
+	}
+
+	return json.Valid(body)
+}
+
+func newUnexpectedResponseError(req *http.Request, resp *http.Response, body []byte) error {
+	excerpt := bytes.TrimSpace(body)
+	if len(excerpt) > maxExcerptSize {
+		excerpt = excerpt[:maxExcerptSize]
+	}
+
+	return &acme.UnexpectedResponseError{
+		StatusCode:  resp.StatusCode,
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Excerpt:     string(excerpt),
+		RetryAfter:  parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header: a number of seconds or an HTTP date.
+// It returns zero if the value is empty, invalid, or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		// a negative or overflowing delay is invalid.
+		if seconds < 0 || int64(seconds) > math.MaxInt64/int64(time.Second) {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+
+	return date.Sub(now)
+}
@@ -0,0 +1,77 @@
+//go:build go1.18
+// +build go1.18
+
+package sender
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+)
+
+// The error responses are sent by the CA, or by any server between the CA and the client.
+
+func FuzzCheckError(f *testing.F) {
+	f.Add(http.StatusForbidden, "application/problem+json", "", []byte(`{"type":"urn:ietf:params:acme:error:unauthorized","detail":"oops","status":403}`))
+	f.Add(http.StatusBadRequest, "application/problem+json", "", []byte(`{"type":"urn:ietf:params:acme:error:badNonce","status":400}`))
+	f.Add(http.StatusTooManyRequests, "application/problem+json", "120", []byte(`{"type":"urn:ietf:params:acme:error:rateLimited","subproblems":[{"type":"urn:ietf:params:acme:error:rejectedIdentifier","identifier":{"type":"dns","value":"example.com"}}]}`))
+	f.Add(http.StatusServiceUnavailable, "text/html", "Wed, 21 Oct 2015 07:28:00 GMT", []byte(`<html><body>Service Unavailable</body></html>`))
+	f.Add(http.StatusInternalServerError, "application/json", "", []byte(`null`))
+	f.Add(http.StatusBadGateway, "", "", []byte{})
+	f.Add(http.StatusOK, "application/json", "", []byte(`{}`))
+
+	f.Fuzz(func(t *testing.T, status int, contentType, retryAfter string, body []byte) {
+		req, err := http.NewRequest(http.MethodPost, "https://ca.example.com/acme/new-order", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{contentType}, "Retry-After": []string{retryAfter}},
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		}
+
+		err = checkError(req, resp)
+
+		if status < http.StatusBadRequest {
+			if err != nil {
+				t.Fatalf("unexpected error for the status %d: %v", status, err)
+			}
+			return
+		}
+
+		if err == nil {
+			t.Fatalf("no error for the status %d", status)
+		}
+
+		// the message of the error can always be built.
+		_ = err.Error()
+
+		var problem *acme.ProblemDetails
+		if errors.As(err, &problem) && problem.RetryAfter < 0 {
+			t.Fatalf("negative Retry-After: %v", problem.RetryAfter)
+		}
+	})
+}
+
+func FuzzParseRetryAfter(f *testing.F) {
+	f.Add("120")
+	f.Add("Wed, 21 Oct 2015 07:28:00 GMT")
+	f.Add("-1")
+	f.Add("99999999999999999999")
+	f.Add("")
+
+	now := time.Date(2015, time.October, 21, 7, 0, 0, 0, time.UTC)
+
+	f.Fuzz(func(t *testing.T, value string) {
+		if delay := parseRetryAfter(value, now); delay < 0 {
+			t.Fatalf("negative delay for %q: %v", value, delay)
+		}
+	})
+}
@@ -1,11 +1,14 @@
 package sender
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-acme/lego/v3/acme"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -65,3 +68,210 @@ func TestDo_CustomUserAgent(t *testing.T) {
 	}
 	assert.Len(t, strings.Split(ua, " "), 5)
 }
+
+func TestDo_unexpectedResponse(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		statusCode  int
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			desc:        "HTML error page",
+			statusCode:  http.StatusServiceUnavailable,
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><title>Login required</title></html>",
+		},
+		{
+			desc:        "HTML page",
+			statusCode:  http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><title>Login required</title></html>",
+		},
+		{
+			desc:        "not JSON body",
+			statusCode:  http.StatusOK,
+			contentType: "text/plain",
+			body:        "hello",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.Header().Set("Content-Type", test.contentType)
+				rw.WriteHeader(test.statusCode)
+				_, _ = rw.Write([]byte(test.body))
+			}))
+			defer ts.Close()
+
+			doer := NewDoer(http.DefaultClient, "")
+
+			var result map[string]interface{}
+			_, err := doer.Get(ts.URL, &result)
+			require.Error(t, err)
+
+			var unexpectedErr *acme.UnexpectedResponseError
+			require.True(t, errors.As(err, &unexpectedErr), "unexpected error type: %T", err)
+
+			assert.Equal(t, test.statusCode, unexpectedErr.StatusCode)
+			assert.Equal(t, test.contentType, unexpectedErr.ContentType)
+			assert.Equal(t, test.body, unexpectedErr.Excerpt)
+		})
+	}
+}
+
+func TestDo_problemDetails(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/problem+json")
+		rw.WriteHeader(http.StatusForbidden)
+		_, _ = rw.Write([]byte(`{"type":"urn:ietf:params:acme:error:unauthorized","detail":"oops","status":403}`))
+	}))
+	defer ts.Close()
+
+	doer := NewDoer(http.DefaultClient, "")
+
+	_, err := doer.Get(ts.URL, nil)
+	require.Error(t, err)
+
+	var problem *acme.ProblemDetails
+	require.True(t, errors.As(err, &problem), "unexpected error type: %T", err)
+
+	assert.Equal(t, "urn:ietf:params:acme:error:unauthorized", problem.Type)
+}
+
+func TestDo_problemDetailsNull(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/problem+json")
+		rw.WriteHeader(http.StatusInternalServerError)
+		_, _ = rw.Write([]byte(`null`))
+	}))
+	defer ts.Close()
+
+	doer := NewDoer(http.DefaultClient, "")
+
+	_, err := doer.Get(ts.URL, nil)
+	require.Error(t, err)
+
+	var problem *acme.ProblemDetails
+	require.True(t, errors.As(err, &problem), "unexpected error type: %T", err)
+
+	assert.Equal(t, http.MethodGet, problem.Method)
+}
+
+func TestDo_bodyTooLarge(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`"` + strings.Repeat("a", maxBodySize) + `"`))
+	}))
+	defer ts.Close()
+
+	doer := NewDoer(http.DefaultClient, "")
+
+	var result string
+	_, err := doer.Get(ts.URL, &result)
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "the response body is too large")
+}
+
+func TestDo_retryAfter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/problem+json")
+		rw.Header().Set("Retry-After", "120")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = rw.Write([]byte(`{"type":"urn:ietf:params:acme:error:serverInternal","detail":"maintenance","status":503}`))
+	}))
+	defer ts.Close()
+
+	doer := NewDoer(http.DefaultClient, "")
+
+	_, err := doer.Get(ts.URL, nil)
+	require.Error(t, err)
+
+	var problem *acme.ProblemDetails
+	require.True(t, errors.As(err, &problem), "unexpected error type: %T", err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, problem.HTTPStatus)
+	assert.Equal(t, 2*time.Minute, problem.RetryAfter)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+	}{
+		{desc: "empty", value: "", expected: 0},
+		{desc: "seconds", value: "30", expected: 30 * time.Second},
+		{desc: "negative", value: "-5", expected: 0},
+		{desc: "overflow", value: "9999999999999", expected: 0},
+		{desc: "HTTP date", value: "Sun, 01 Mar 2020 10:05:00 GMT", expected: 5 * time.Minute},
+		{desc: "past date", value: "Sun, 01 Mar 2020 09:00:00 GMT", expected: 0},
+		{desc: "invalid", value: "soon", expected: 0},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, parseRetryAfter(test.value, now))
+		})
+	}
+}
+
+func TestDoer_GetConditional(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"newOrder":"https://example.com/new-order"}`))
+	}))
+	defer ts.Close()
+
+	doer := NewDoer(http.DefaultClient, "")
+
+	var dir acme.Directory
+	resp, err := doer.GetConditional(ts.URL, "", "", &dir)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"v1"`, resp.Header.Get("ETag"))
+	assert.Equal(t, "https://example.com/new-order", dir.NewOrderURL)
+
+	var notModified acme.Directory
+	resp, err = doer.GetConditional(ts.URL, `"v1"`, "", &notModified)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, notModified.NewOrderURL)
+}
+
+func TestDoer_ClockSkew(t *testing.T) {
+	serverTime := time.Date(2020, 3, 1, 10, 5, 0, 0, time.UTC)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Date", serverTime.Format(http.TimeFormat))
+	}))
+	defer ts.Close()
+
+	doer := NewDoer(http.DefaultClient, "")
+	doer.now = func() time.Time { return time.Date(2020, 3, 1, 10, 0, 0, 500, time.UTC) }
+
+	_, known := doer.ClockSkew()
+	assert.False(t, known)
+
+	_, err := doer.Get(ts.URL, nil)
+	require.NoError(t, err)
+
+	skew, known := doer.ClockSkew()
+	assert.True(t, known)
+	assert.Equal(t, 5*time.Minute, skew)
+}
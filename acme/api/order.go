@@ -3,14 +3,28 @@ package api
 import (
 	"encoding/base64"
 	"errors"
+	"time"
 
 	"github.com/go-acme/lego/v3/acme"
 )
 
+// OrderOptions the optional fields of a new order.
+type OrderOptions struct {
+	// NotBefore the requested value of the notBefore field in the certificate.
+	NotBefore time.Time
+	// NotAfter the requested value of the notAfter field in the certificate.
+	NotAfter time.Time
+}
+
 type OrderService service
 
 // New Creates a new order.
 func (o *OrderService) New(domains []string) (acme.ExtendedOrder, error) {
+	return o.NewWithOptions(domains, nil)
+}
+
+// NewWithOptions Creates a new order with the given options.
+func (o *OrderService) NewWithOptions(domains []string, opts *OrderOptions) (acme.ExtendedOrder, error) {
 	var identifiers []acme.Identifier
 	for _, domain := range domains {
 		identifiers = append(identifiers, acme.Identifier{Type: "dns", Value: domain})
@@ -18,6 +32,16 @@ func (o *OrderService) New(domains []string) (acme.ExtendedOrder, error) {
 
 	orderReq := acme.Order{Identifiers: identifiers}
 
+	if opts != nil {
+		if !opts.NotBefore.IsZero() {
+			orderReq.NotBefore = opts.NotBefore.UTC().Format(time.RFC3339)
+		}
+
+		if !opts.NotAfter.IsZero() {
+			orderReq.NotAfter = opts.NotAfter.UTC().Format(time.RFC3339)
+		}
+	}
+
 	var order acme.Order
 	resp, err := o.core.post(o.core.GetDirectory().NewOrderURL, orderReq, &order)
 	if err != nil {
@@ -45,6 +69,32 @@ func (o *OrderService) Get(orderURL string) (acme.Order, error) {
 	return order, nil
 }
 
+// List Lists the URLs of the orders of an account, from the orders URL of the account.
+// The pages of the list (Link rel="next") are followed.
+func (o *OrderService) List(ordersURL string) ([]string, error) {
+	if len(ordersURL) == 0 {
+		return nil, errors.New("order[list]: empty URL")
+	}
+
+	var orders []string
+
+	seen := make(map[string]bool)
+	for next := ordersURL; next != "" && !seen[next]; {
+		seen[next] = true
+
+		var page acme.OrdersList
+		resp, err := o.core.postAsGet(next, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, page.Orders...)
+		next = getLink(resp.Header, "next")
+	}
+
+	return orders, nil
+}
+
 // UpdateForCSR Updates an order for a CSR.
 func (o *OrderService) UpdateForCSR(orderURL string, csr []byte) (acme.Order, error) {
 	csrMsg := acme.CSRMessage{
@@ -4,9 +4,11 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-acme/lego/v3/acme"
 	"github.com/go-acme/lego/v3/platform/tester"
@@ -66,6 +68,59 @@ func TestOrderService_New(t *testing.T) {
 	assert.Equal(t, expected, order)
 }
 
+func TestOrderService_NewWithOptions(t *testing.T) {
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order := acme.Order{}
+		err = json.Unmarshal(body, &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order.Status = acme.StatusPending
+
+		err = tester.WriteJSONResponse(w, order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	opts := &OrderOptions{
+		NotBefore: time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2020, time.March, 2, 12, 0, 0, 0, time.FixedZone("CET", 3600)),
+	}
+
+	order, err := core.Orders.NewWithOptions([]string{"example.com"}, opts)
+	require.NoError(t, err)
+
+	expected := acme.ExtendedOrder{
+		Order: acme.Order{
+			Status:      acme.StatusPending,
+			Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}},
+			NotBefore:   "2020-03-01T12:00:00Z",
+			NotAfter:    "2020-03-02T11:00:00Z",
+		},
+	}
+	assert.Equal(t, expected, order)
+}
+
 func readSignedBody(r *http.Request, privateKey *rsa.PrivateKey) ([]byte, error) {
 	reqBody, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -87,3 +142,48 @@ func readSignedBody(r *http.Request, privateKey *rsa.PrivateKey) ([]byte, error)
 
 	return body, nil
 }
+
+func TestOrderService_List(t *testing.T) {
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	// small value keeps test fast
+	privateKey, errK := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, errK, "Could not generate test key")
+
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		_, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Link", fmt.Sprintf(`<%s/orders/2>;rel="next"`, apiURL))
+		err = tester.WriteJSONResponse(w, acme.OrdersList{Orders: []string{apiURL + "/order/1", apiURL + "/order/2"}})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("/orders/2", func(w http.ResponseWriter, r *http.Request) {
+		_, err := readSignedBody(r, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = tester.WriteJSONResponse(w, acme.OrdersList{Orders: []string{apiURL + "/order/3"}})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	core, err := New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	orders, err := core.Orders.List(apiURL + "/orders")
+	require.NoError(t, err)
+
+	expected := []string{apiURL + "/order/1", apiURL + "/order/2", apiURL + "/order/3"}
+	assert.Equal(t, expected, orders)
+}
@@ -11,6 +11,7 @@ import (
 // https://tools.ietf.org/html/rfc8555#section-7.1.6
 const (
 	StatusPending     = "pending"
+	StatusReady       = "ready"
 	StatusInvalid     = "invalid"
 	StatusValid       = "valid"
 	StatusProcessing  = "processing"
@@ -29,6 +30,9 @@ type Directory struct {
 	RevokeCertURL string `json:"revokeCert"`
 	KeyChangeURL  string `json:"keyChange"`
 	Meta          Meta   `json:"meta"`
+
+	// RenewalInfoURL the base URL of the renewal information of the certificates (ACME Renewal Information, RFC 9773), optional.
+	RenewalInfoURL string `json:"renewalInfo,omitempty"`
 }
 
 // Meta the ACME meta object (related to Directory).
@@ -55,6 +59,15 @@ type Meta struct {
 	// then the CA requires that all new- account requests include an "externalAccountBinding" field
 	// associating the new account with an external account.
 	ExternalAccountRequired bool `json:"externalAccountRequired"`
+
+	// profiles (optional, object):
+	// The certificate profiles supported by the ACME server, with their description.
+	// https://datatracker.ietf.org/doc/draft-aaron-acme-profiles/
+	Profiles map[string]string `json:"profiles,omitempty"`
+
+	// signatureAlgorithms (optional, array of string, not standardized):
+	// The JWS algorithms accepted by the ACME server for the account keys (e.g. ["ES256"]), advertised by some private CAs.
+	SignatureAlgorithms []string `json:"signatureAlgorithms,omitempty"`
 }
 
 // ExtendedAccount a extended Account.
@@ -103,6 +116,15 @@ type Account struct {
 	ExternalAccountBinding json.RawMessage `json:"externalAccountBinding,omitempty"`
 }
 
+// OrdersList the list of the orders of an account.
+// - https://tools.ietf.org/html/rfc8555#section-7.1.2.1
+type OrdersList struct {
+	// orders (required, array of string):
+	// An array of URLs, each identifying an order belonging to the account.
+	// The server SHOULD include pending orders and SHOULD NOT include orders that are invalid in the array of URLs.
+	Orders []string `json:"orders"`
+}
+
 // ExtendedOrder a extended Order.
 type ExtendedOrder struct {
 	Order
@@ -246,6 +268,11 @@ type Challenge struct {
 
 	// https://tools.ietf.org/html/rfc8555#section-8.1
 	KeyAuthorization string `json:"keyAuthorization"`
+
+	// nonce (optional, string):
+	// The base64url-encoded nonce which must be included in the CSR of the "onion-csr-01" challenge.
+	// https://tools.ietf.org/html/draft-ietf-acme-onion-00#section-3.2
+	Nonce string `json:"nonce,omitempty"`
 }
 
 // Identifier the ACME identifier object.
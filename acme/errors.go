@@ -2,12 +2,14 @@ package acme
 
 import (
 	"fmt"
+	"time"
 )
 
 // Errors types
 const (
-	errNS       = "urn:ietf:params:acme:error:"
-	BadNonceErr = errNS + "badNonce"
+	errNS                  = "urn:ietf:params:acme:error:"
+	BadNonceErr            = errNS + "badNonce"
+	AccountDoesNotExistErr = errNS + "accountDoesNotExist"
 )
 
 // ProblemDetails the problem details object
@@ -23,6 +25,9 @@ type ProblemDetails struct {
 	// additional values to have a better error message (Not defined by the RFC)
 	Method string `json:"method,omitempty"`
 	URL    string `json:"url,omitempty"`
+
+	// RetryAfter the delay requested by the server with the Retry-After header (e.g. during a maintenance), zero if none.
+	RetryAfter time.Duration `json:"-"`
 }
 
 // SubProblem a "subproblems"
@@ -56,3 +61,21 @@ func (p ProblemDetails) Error() string {
 type NonceError struct {
 	*ProblemDetails
 }
+
+// UnexpectedResponseError represents a response which is not an ACME response,
+// like an HTML error page returned by a captive portal or a proxy.
+type UnexpectedResponseError struct {
+	StatusCode  int
+	Method      string
+	URL         string
+	ContentType string
+	// Excerpt the beginning of the response body.
+	Excerpt string
+	// RetryAfter the delay requested by the server with the Retry-After header, zero if none.
+	RetryAfter time.Duration
+}
+
+func (e *UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("acme: unexpected response: %d :: %s :: %s :: content type %q (an ACME server responds with JSON, check the proxy settings) :: %q",
+		e.StatusCode, e.Method, e.URL, e.ContentType, e.Excerpt)
+}
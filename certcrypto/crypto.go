@@ -25,6 +25,7 @@ const (
 	EC256   = KeyType("P256")
 	EC384   = KeyType("P384")
 	RSA2048 = KeyType("2048")
+	RSA3072 = KeyType("3072")
 	RSA4096 = KeyType("4096")
 	RSA8192 = KeyType("8192")
 )
@@ -85,6 +86,9 @@ func ParsePEMBundle(bundle []byte) ([]*x509.Certificate, error) {
 // https://github.com/golang/go/blob/693748e9fa385f1e2c3b91ca9acbb6c0ad2d133d/src/crypto/tls/tls.go#L238)
 func ParsePEMPrivateKey(key []byte) (crypto.PrivateKey, error) {
 	keyBlockDER, _ := pem.Decode(key)
+	if keyBlockDER == nil {
+		return nil, errors.New("PEM decode did not yield a valid block. Is the private key in the right format?")
+	}
 
 	if keyBlockDER.Type != "PRIVATE KEY" && !strings.HasSuffix(keyBlockDER.Type, " PRIVATE KEY") {
 		return nil, fmt.Errorf("unknown PEM header %q", keyBlockDER.Type)
@@ -111,23 +115,85 @@ func ParsePEMPrivateKey(key []byte) (crypto.PrivateKey, error) {
 }
 
 func GeneratePrivateKey(keyType KeyType) (crypto.PrivateKey, error) {
+	if err := CheckFIPSKeyType(keyType); err != nil {
+		return nil, err
+	}
+
 	switch keyType {
 	case EC256:
-		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		return ecdsa.GenerateKey(elliptic.P256(), Reader)
 	case EC384:
-		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		return ecdsa.GenerateKey(elliptic.P384(), Reader)
 	case RSA2048:
-		return rsa.GenerateKey(rand.Reader, 2048)
+		return rsa.GenerateKey(Reader, 2048)
+	case RSA3072:
+		return rsa.GenerateKey(Reader, 3072)
 	case RSA4096:
-		return rsa.GenerateKey(rand.Reader, 4096)
+		return rsa.GenerateKey(Reader, 4096)
 	case RSA8192:
-		return rsa.GenerateKey(rand.Reader, 8192)
+		return rsa.GenerateKey(Reader, 8192)
 	}
 
 	return nil, fmt.Errorf("invalid KeyType: %s", keyType)
 }
 
+// KeyTypeOf returns the type of a private or public key, empty if it is not one of the supported key types.
+func KeyTypeOf(key interface{}) KeyType {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return KeyTypeOf(&k.PublicKey)
+	case *ecdsa.PrivateKey:
+		return KeyTypeOf(&k.PublicKey)
+	case *rsa.PublicKey:
+		switch k.N.BitLen() {
+		case 2048:
+			return RSA2048
+		case 3072:
+			return RSA3072
+		case 4096:
+			return RSA4096
+		case 8192:
+			return RSA8192
+		}
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return EC256
+		case elliptic.P384():
+			return EC384
+		}
+	}
+
+	return ""
+}
+
+// keyStrengths the algorithm and the size (bits or curve) of the key types.
+var keyStrengths = map[KeyType]struct {
+	algorithm string
+	size      int
+}{
+	EC256:   {"EC", 256},
+	EC384:   {"EC", 384},
+	RSA2048: {"RSA", 2048},
+	RSA3072: {"RSA", 3072},
+	RSA4096: {"RSA", 4096},
+	RSA8192: {"RSA", 8192},
+}
+
+// Weaker returns true if the key type is weaker than another key type of the same algorithm (e.g. RSA2048 and RSA3072, or EC256 and EC384).
+// The key types of different algorithms are not comparable.
+func (k KeyType) Weaker(other KeyType) bool {
+	strength, ok := keyStrengths[k]
+	otherStrength, otherOk := keyStrengths[other]
+
+	return ok && otherOk && strength.algorithm == otherStrength.algorithm && strength.size < otherStrength.size
+}
+
 func GenerateCSR(privateKey crypto.PrivateKey, domain string, san []string, mustStaple bool) ([]byte, error) {
+	if err := CheckFIPSKey(privateKey); err != nil {
+		return nil, err
+	}
+
 	template := x509.CertificateRequest{
 		Subject:  pkix.Name{CommonName: domain},
 		DNSNames: san,
@@ -140,7 +206,7 @@ func GenerateCSR(privateKey crypto.PrivateKey, domain string, san []string, must
 		})
 	}
 
-	return x509.CreateCertificateRequest(rand.Reader, &template, privateKey)
+	return x509.CreateCertificateRequest(Reader, &template, privateKey)
 }
 
 func PEMEncode(data interface{}) []byte {
@@ -255,7 +321,7 @@ func GeneratePemCert(privateKey *rsa.PrivateKey, domain string, extensions []pki
 
 func generateDerCert(privateKey *rsa.PrivateKey, expiration time.Time, domain string, extensions []pkix.Extension) ([]byte, error) {
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
-	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	serialNumber, err := rand.Int(Reader, serialNumberLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -278,5 +344,5 @@ func generateDerCert(privateKey *rsa.PrivateKey, expiration time.Time, domain st
 		ExtraExtensions:       extensions,
 	}
 
-	return x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	return x509.CreateCertificate(Reader, &template, &template, &privateKey.PublicKey, privateKey)
 }
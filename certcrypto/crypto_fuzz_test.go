@@ -0,0 +1,126 @@
+//go:build go1.18
+// +build go1.18
+
+package certcrypto
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+// The parsers receive the certificates sent by the CA, and the files written by the users.
+
+func fuzzSeeds(f *testing.F) {
+	f.Helper()
+
+	privateKey, err := GeneratePrivateKey(RSA2048)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	certBytes, err := generateDerCert(privateKey.(*rsa.PrivateKey), time.Now().Add(time.Hour), "example.com", nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	csr, err := GenerateCSR(privateKey, "example.com", []string{"www.example.com"}, true)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	ecKey, err := GeneratePrivateKey(EC256)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	cert := PEMEncode(DERCertificateBytes(certBytes))
+
+	f.Add(cert)
+	f.Add(append(append([]byte{}, cert...), cert...))
+	f.Add(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+	f.Add(PEMEncode(privateKey))
+	f.Add(PEMEncode(ecKey))
+	f.Add([]byte("-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n"))
+	f.Add([]byte{})
+}
+
+func FuzzParsePEMBundle(f *testing.F) {
+	fuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		certs, err := ParsePEMBundle(data)
+		if err != nil {
+			return
+		}
+
+		if len(certs) == 0 {
+			t.Fatal("no certificate and no error")
+		}
+
+		// the certificates survive a round trip.
+		var bundle []byte
+		for _, cert := range certs {
+			bundle = append(bundle, PEMEncode(DERCertificateBytes(cert.Raw))...)
+		}
+
+		again, err := ParsePEMBundle(bundle)
+		if err != nil {
+			t.Fatalf("the encoded bundle can't be parsed: %v", err)
+		}
+
+		if len(again) != len(certs) {
+			t.Fatalf("got %d certificates, expected %d", len(again), len(certs))
+		}
+
+		for i, cert := range certs {
+			if !bytes.Equal(again[i].Raw, cert.Raw) {
+				t.Fatalf("the certificate %d changed", i)
+			}
+		}
+	})
+}
+
+func FuzzParsePEMCertificate(f *testing.F) {
+	fuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cert, err := ParsePEMCertificate(data)
+		if err != nil {
+			return
+		}
+
+		// the domains of the certificate can always be extracted.
+		_ = ExtractDomains(cert)
+	})
+}
+
+func FuzzPemDecodeTox509CSR(f *testing.F) {
+	fuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		csr, err := PemDecodeTox509CSR(data)
+		if err != nil {
+			return
+		}
+
+		_ = ExtractDomainsCSR(csr)
+	})
+}
+
+func FuzzParsePEMPrivateKey(f *testing.F) {
+	fuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		key, err := ParsePEMPrivateKey(data)
+		if err != nil {
+			return
+		}
+
+		if key == nil {
+			t.Fatal("no key and no error")
+		}
+	})
+}
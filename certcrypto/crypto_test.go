@@ -147,3 +147,35 @@ type MockRandReader struct {
 func (r MockRandReader) Read(p []byte) (int, error) {
 	return r.b.Read(p)
 }
+
+func TestParsePEMPrivateKey(t *testing.T) {
+	privateKey, err := GeneratePrivateKey(EC256)
+	require.NoError(t, err)
+
+	key, err := ParsePEMPrivateKey(PEMEncode(privateKey))
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, key)
+
+	// Some random string should return an error.
+	_, err = ParsePEMPrivateKey([]byte("TestingRSAIsSoMuchFun"))
+	require.Error(t, err)
+
+	_, err = ParsePEMPrivateKey(PEMEncode(DERCertificateBytes("foo")))
+	require.EqualError(t, err, `unknown PEM header "CERTIFICATE"`)
+}
+
+func TestKeyType_Weaker(t *testing.T) {
+	assert.True(t, RSA2048.Weaker(RSA3072))
+	assert.True(t, RSA3072.Weaker(RSA4096))
+	assert.True(t, EC256.Weaker(EC384))
+
+	assert.False(t, RSA3072.Weaker(RSA3072))
+	assert.False(t, RSA4096.Weaker(RSA3072))
+	assert.False(t, EC384.Weaker(EC256))
+
+	// different algorithms.
+	assert.False(t, EC256.Weaker(RSA4096))
+	assert.False(t, RSA2048.Weaker(EC384))
+
+	assert.False(t, KeyType("unknown").Weaker(RSA2048))
+}
@@ -0,0 +1,122 @@
+package certcrypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Reader the source of randomness of the keys, CSRs and signatures generated by lego:
+// crypto/rand, unless replaced with SetRandReader.
+var Reader io.Reader = randReader{}
+
+var (
+	randMu     sync.RWMutex
+	randSource io.Reader = rand.Reader
+)
+
+// SetRandReader replaces the source of randomness (e.g. with a hardware RNG), crypto/rand if nil.
+func SetRandReader(r io.Reader) {
+	randMu.Lock()
+	defer randMu.Unlock()
+
+	if r == nil {
+		r = rand.Reader
+	}
+	randSource = r
+}
+
+type randReader struct{}
+
+func (randReader) Read(p []byte) (int, error) {
+	randMu.RLock()
+	defer randMu.RUnlock()
+
+	return randSource.Read(p)
+}
+
+// fipsMode is enabled by default by the "fips" build tag.
+var (
+	fipsMu   sync.RWMutex
+	fipsMode = fipsBuild
+)
+
+// ErrNotFIPSApproved is returned for the keys and the algorithms which are not FIPS-approved, when the FIPS mode is enabled.
+var ErrNotFIPSApproved = errors.New("not FIPS-approved")
+
+// SetFIPSMode restricts the keys to the FIPS-approved ones (RSA of at least 2048 bits, ECDSA P-256 and P-384):
+// the other keys (e.g. Ed25519) can't be generated nor used to sign the CSRs and the JWS.
+// The FIPS mode is enabled by default when lego is built with the "fips" build tag.
+func SetFIPSMode(enabled bool) {
+	fipsMu.Lock()
+	defer fipsMu.Unlock()
+
+	fipsMode = enabled
+}
+
+// FIPSMode returns true if the FIPS mode is enabled.
+func FIPSMode() bool {
+	fipsMu.RLock()
+	defer fipsMu.RUnlock()
+
+	return fipsMode
+}
+
+// CheckFIPSKeyType checks that a key type is FIPS-approved, if the FIPS mode is enabled.
+func CheckFIPSKeyType(keyType KeyType) error {
+	if !FIPSMode() {
+		return nil
+	}
+
+	switch keyType {
+	case EC256, EC384, RSA2048, RSA3072, RSA4096, RSA8192:
+		return nil
+	default:
+		return fmt.Errorf("key type %q: %w", keyType, ErrNotFIPSApproved)
+	}
+}
+
+// CheckFIPSKey checks that a private or public key is FIPS-approved, if the FIPS mode is enabled.
+func CheckFIPSKey(key interface{}) error {
+	if !FIPSMode() {
+		return nil
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return CheckFIPSKey(&k.PublicKey)
+	case *ecdsa.PrivateKey:
+		return CheckFIPSKey(&k.PublicKey)
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return fmt.Errorf("RSA key of %d bits: %w", k.N.BitLen(), ErrNotFIPSApproved)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() && k.Curve != elliptic.P384() {
+			return fmt.Errorf("elliptic curve %s: %w", k.Curve.Params().Name, ErrNotFIPSApproved)
+		}
+		return nil
+	case crypto.Signer:
+		return CheckFIPSKey(k.Public())
+	default:
+		return fmt.Errorf("key %T: %w", key, ErrNotFIPSApproved)
+	}
+}
+
+// CheckFIPSModule checks that the FIPS module of the Go toolchain (BoringCrypto or the Go Cryptographic Module) is enabled,
+// if the toolchain has one. It returns the name of the module, empty if the toolchain has none.
+func CheckFIPSModule() (string, error) {
+	name, enabled := fipsModule()
+	if name != "" && !enabled {
+		return name, fmt.Errorf("the FIPS module of the Go toolchain (%s) is not enabled", name)
+	}
+
+	return name, nil
+}
@@ -0,0 +1,9 @@
+//go:build !boringcrypto && !go1.24
+// +build !boringcrypto,!go1.24
+
+package certcrypto
+
+// fipsModule returns the name of the FIPS module of the Go toolchain, and whether it is enabled.
+func fipsModule() (string, bool) {
+	return "", false
+}
@@ -0,0 +1,11 @@
+//go:build boringcrypto
+// +build boringcrypto
+
+package certcrypto
+
+import "crypto/boring"
+
+// fipsModule returns the name of the FIPS module of the Go toolchain, and whether it is enabled.
+func fipsModule() (string, bool) {
+	return "boringcrypto", boring.Enabled()
+}
@@ -0,0 +1,11 @@
+//go:build go1.24 && !boringcrypto
+// +build go1.24,!boringcrypto
+
+package certcrypto
+
+import "crypto/fips140"
+
+// fipsModule returns the name of the FIPS module of the Go toolchain, and whether it is enabled (GODEBUG=fips140=on).
+func fipsModule() (string, bool) {
+	return "fips140", fips140.Enabled()
+}
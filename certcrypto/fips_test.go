@@ -0,0 +1,86 @@
+package certcrypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("no entropy")
+}
+
+func setFIPSMode(t *testing.T, enabled bool) {
+	t.Helper()
+
+	previous := FIPSMode()
+	SetFIPSMode(enabled)
+	t.Cleanup(func() { SetFIPSMode(previous) })
+}
+
+func TestSetRandReader(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	SetRandReader(failingReader{})
+	t.Cleanup(func() { SetRandReader(nil) })
+
+	_, err = GeneratePemCert(key, "example.com", nil)
+	require.EqualError(t, err, "no entropy")
+
+	SetRandReader(nil)
+
+	_, err = GeneratePemCert(key, "example.com", nil)
+	require.NoError(t, err)
+}
+
+func TestCheckFIPSKey(t *testing.T) {
+	setFIPSMode(t, true)
+
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	assert.NoError(t, CheckFIPSKey(p256))
+	assert.NoError(t, CheckFIPSKey(&p256.PublicKey))
+
+	for _, key := range []interface{}{rsa1024, p521, edKey, edKey.Public()} {
+		err = CheckFIPSKey(key)
+		assert.True(t, errors.Is(err, ErrNotFIPSApproved), "%T: %v", key, err)
+	}
+
+	_, err = GenerateCSR(edKey, "example.com", nil, false)
+	assert.True(t, errors.Is(err, ErrNotFIPSApproved), err)
+
+	SetFIPSMode(false)
+
+	assert.NoError(t, CheckFIPSKey(edKey))
+}
+
+func TestCheckFIPSKeyType(t *testing.T) {
+	setFIPSMode(t, true)
+
+	for _, keyType := range []KeyType{EC256, EC384, RSA2048, RSA3072, RSA4096, RSA8192} {
+		assert.NoError(t, CheckFIPSKeyType(keyType), keyType)
+	}
+
+	_, err := GeneratePrivateKey("ed25519")
+	assert.True(t, errors.Is(err, ErrNotFIPSApproved), err)
+}
@@ -0,0 +1,109 @@
+package certcrypto
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TLSA certificate usages (RFC 6698 and RFC 7218).
+const (
+	TLSAUsagePKIXTA = 0
+	TLSAUsagePKIXEE = 1
+	TLSAUsageDANETA = 2
+	TLSAUsageDANEEE = 3
+)
+
+// TLSA selectors.
+const (
+	TLSASelectorCert = 0
+	TLSASelectorSPKI = 1
+)
+
+// TLSA matching types.
+const (
+	TLSAMatchingFull   = 0
+	TLSAMatchingSHA256 = 1
+	TLSAMatchingSHA512 = 2
+)
+
+// TLSAParams the parameters of a TLSA record.
+type TLSAParams struct {
+	Usage        uint8
+	Selector     uint8
+	MatchingType uint8
+}
+
+// ParseTLSAParams parses the parameters of a TLSA record, in the record format: "usage selector matching-type" (e.g. "3 1 1").
+func ParseTLSAParams(value string) (TLSAParams, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 3 {
+		return TLSAParams{}, fmt.Errorf("invalid TLSA parameters %q: expected 'usage selector matching-type'", value)
+	}
+
+	var params [3]uint8
+	for i, field := range fields {
+		v, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			return TLSAParams{}, fmt.Errorf("invalid TLSA parameters %q: %w", value, err)
+		}
+		params[i] = uint8(v)
+	}
+
+	p := TLSAParams{Usage: params[0], Selector: params[1], MatchingType: params[2]}
+
+	if p.Usage > TLSAUsageDANEEE || p.Selector > TLSASelectorSPKI || p.MatchingType > TLSAMatchingSHA512 {
+		return TLSAParams{}, fmt.Errorf("unsupported TLSA parameters %q", value)
+	}
+
+	return p, nil
+}
+
+// UsesIssuer returns true if the usage designates the issuer (the trust anchor) instead of the certificate itself.
+func (p TLSAParams) UsesIssuer() bool {
+	return p.Usage == TLSAUsagePKIXTA || p.Usage == TLSAUsageDANETA
+}
+
+func (p TLSAParams) String() string {
+	return fmt.Sprintf("%d %d %d", p.Usage, p.Selector, p.MatchingType)
+}
+
+// TLSARecord returns the data of a TLSA record ("usage selector matching-type certificate-association-data") for the certificate.
+// The certificate must be the issuer when the usage designates a trust anchor (see TLSAParams.UsesIssuer).
+func TLSARecord(cert *x509.Certificate, params TLSAParams) (string, error) {
+	var data []byte
+	switch params.Selector {
+	case TLSASelectorCert:
+		data = cert.Raw
+	case TLSASelectorSPKI:
+		data = cert.RawSubjectPublicKeyInfo
+	default:
+		return "", fmt.Errorf("unsupported TLSA selector: %d", params.Selector)
+	}
+
+	switch params.MatchingType {
+	case TLSAMatchingFull:
+	case TLSAMatchingSHA256:
+		sum := sha256.Sum256(data)
+		data = sum[:]
+	case TLSAMatchingSHA512:
+		sum := sha512.Sum512(data)
+		data = sum[:]
+	default:
+		return "", fmt.Errorf("unsupported TLSA matching type: %d", params.MatchingType)
+	}
+
+	return params.String() + " " + hex.EncodeToString(data), nil
+}
+
+// SPKIPin returns the base64 encoded SHA-256 hash of the subject public key info of the certificate,
+// as used by the pinning workflows (e.g. HPKP pin-sha256).
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
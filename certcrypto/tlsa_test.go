@@ -0,0 +1,139 @@
+package certcrypto
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTLSAParams(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected TLSAParams
+		hasError bool
+	}{
+		{
+			desc:     "DANE-EE SPKI SHA-256",
+			value:    "3 1 1",
+			expected: TLSAParams{Usage: TLSAUsageDANEEE, Selector: TLSASelectorSPKI, MatchingType: TLSAMatchingSHA256},
+		},
+		{
+			desc:     "extra spaces",
+			value:    " 2  0 2 ",
+			expected: TLSAParams{Usage: TLSAUsageDANETA, Selector: TLSASelectorCert, MatchingType: TLSAMatchingSHA512},
+		},
+		{
+			desc:     "missing field",
+			value:    "3 1",
+			hasError: true,
+		},
+		{
+			desc:     "not a number",
+			value:    "3 1 a",
+			hasError: true,
+		},
+		{
+			desc:     "unknown usage",
+			value:    "4 1 1",
+			hasError: true,
+		},
+		{
+			desc:     "unknown matching type",
+			value:    "3 1 3",
+			hasError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			params, err := ParseTLSAParams(test.value)
+			if test.hasError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, params)
+		})
+	}
+}
+
+func TestTLSARecord(t *testing.T) {
+	cert := generateTestCertificate(t)
+
+	spkiSHA256 := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	certSHA512 := sha512.Sum512(cert.Raw)
+
+	testCases := []struct {
+		desc     string
+		params   TLSAParams
+		expected string
+	}{
+		{
+			desc:     "SPKI SHA-256",
+			params:   TLSAParams{Usage: TLSAUsageDANEEE, Selector: TLSASelectorSPKI, MatchingType: TLSAMatchingSHA256},
+			expected: "3 1 1 " + hex.EncodeToString(spkiSHA256[:]),
+		},
+		{
+			desc:     "certificate SHA-512",
+			params:   TLSAParams{Usage: TLSAUsagePKIXEE, Selector: TLSASelectorCert, MatchingType: TLSAMatchingSHA512},
+			expected: "1 0 2 " + hex.EncodeToString(certSHA512[:]),
+		},
+		{
+			desc:     "full SPKI",
+			params:   TLSAParams{Usage: TLSAUsageDANEEE, Selector: TLSASelectorSPKI, MatchingType: TLSAMatchingFull},
+			expected: "3 1 0 " + hex.EncodeToString(cert.RawSubjectPublicKeyInfo),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			record, err := TLSARecord(cert, test.params)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, record)
+		})
+	}
+}
+
+func TestSPKIPin(t *testing.T) {
+	cert := generateTestCertificate(t)
+
+	pin := SPKIPin(cert)
+
+	raw, err := base64.StdEncoding.DecodeString(pin)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	assert.Equal(t, sum[:], raw)
+}
+
+func generateTestCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+
+	privateKey, err := GeneratePrivateKey(RSA2048)
+	require.NoError(t, err)
+
+	certBytes, err := generateDerCert(privateKey.(*rsa.PrivateKey), time.Now().Add(time.Hour), "example.com", nil)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+
+	return cert
+}
@@ -0,0 +1,27 @@
+package certificate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+)
+
+// ContentAddress identifies the certificates having the same domains and the same key type, whatever the order of the domains:
+// the SHA-256 hash (hexadecimal) of the key type and of the sorted normalized domains (see NormalizeDomains).
+func ContentAddress(domains []string, keyType certcrypto.KeyType) (string, error) {
+	normalized, err := NormalizeDomains(domains)
+	if err != nil {
+		return "", err
+	}
+
+	sorted := make([]string, len(normalized))
+	copy(sorted, normalized)
+	sort.Strings(sorted)
+
+	sum := sha256.Sum256([]byte(string(keyType) + "\n" + strings.Join(sorted, "\n")))
+
+	return hex.EncodeToString(sum[:]), nil
+}
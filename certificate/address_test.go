@@ -0,0 +1,30 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentAddress(t *testing.T) {
+	address, err := ContentAddress([]string{"example.com", "www.example.com"}, certcrypto.EC256)
+	require.NoError(t, err)
+	assert.Len(t, address, 64)
+
+	same, err := ContentAddress([]string{"WWW.example.com.", "example.com", "example.com"}, certcrypto.EC256)
+	require.NoError(t, err)
+	assert.Equal(t, address, same)
+
+	otherKeyType, err := ContentAddress([]string{"example.com", "www.example.com"}, certcrypto.RSA2048)
+	require.NoError(t, err)
+	assert.NotEqual(t, address, otherKeyType)
+
+	otherDomains, err := ContentAddress([]string{"example.com"}, certcrypto.EC256)
+	require.NoError(t, err)
+	assert.NotEqual(t, address, otherDomains)
+
+	_, err = ContentAddress([]string{"exa mple.com"}, certcrypto.EC256)
+	require.Error(t, err)
+}
@@ -21,7 +21,7 @@ func (c *Certifier) getAuthorizations(order acme.ExtendedOrder) ([]acme.Authoriz
 	delay := time.Second / overallRequestLimit
 
 	for _, authzURL := range order.Authorizations {
-		time.Sleep(delay)
+		clk.Sleep(delay)
 
 		go func(authzURL string) {
 			authz, err := c.core.Authorizations.Get(authzURL)
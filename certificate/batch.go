@@ -0,0 +1,212 @@
+package certificate
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// BatchResult the result of a request of ObtainBatch.
+type BatchResult struct {
+	Resource *Resource
+	Err      error
+}
+
+// batchItem the state of a request of ObtainBatch.
+type batchItem struct {
+	request  ObtainRequest
+	progress *progressReporter
+	pending  *pendingOrder
+	result   BatchResult
+}
+
+// ObtainBatch obtains many certificates in one call, and returns the result of each request, in the order of the requests.
+//
+// The requests share the ACME client (nonces and HTTP connections), and their challenges are solved together:
+// the orders are created first, then the challenges of all the orders are solved in a single pass of the resolver,
+// and the certificates are finally requested concurrently.
+// A failing domain only fails the requests containing it
+// (or drops it, and retries the request alone with the remaining domains, if ObtainRequest.AllowPartial is set).
+//
+// The progress functions (ObtainRequest.Progress) of the requests can be called concurrently during the finalization.
+func (c *Certifier) ObtainBatch(requests []ObtainRequest) []BatchResult {
+	items := make([]*batchItem, len(requests))
+
+	for i, request := range requests {
+		item := &batchItem{request: request, progress: newProgressReporter(request.Progress)}
+		items[i] = item
+
+		if len(request.Domains) == 0 {
+			item.result.Err = errors.New("no domains to obtain a certificate for")
+			continue
+		}
+
+		item.pending, item.result.Err = c.newOrder(request, item.progress)
+	}
+
+	solveErrs := c.solveBatch(items)
+
+	var wg sync.WaitGroup
+
+	for _, item := range items {
+		if item.pending == nil || solveErrs[item] != nil {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(item *batchItem) {
+			defer wg.Done()
+
+			item.result.Resource, item.result.Err = c.finalizeOrder(item.request, item.pending, item.progress)
+		}(item)
+	}
+
+	wg.Wait()
+
+	// the retries solve their challenges one after the other, as the resolver is not safe for concurrent use.
+	for _, item := range items {
+		if err := solveErrs[item]; err != nil {
+			// If any challenge fails, do not generate partial SAN certificates.
+			c.deactivateAuthorizations(item.pending.order)
+			item.result.Resource, item.result.Err = c.retryPartial(item, err)
+		}
+	}
+
+	results := make([]BatchResult, len(items))
+	for i, item := range items {
+		item.result.Err = item.progress.done(item.result.Err)
+		results[i] = item.result
+	}
+
+	return results
+}
+
+// solveBatch solves the challenges of all the pending orders, and returns the error of each order whose challenges failed.
+func (c *Certifier) solveBatch(items []*batchItem) map[*batchItem]error {
+	var authz []acme.Authorization
+	var unsubscribes []func()
+	seen := make(map[string]bool)
+
+	for _, item := range items {
+		if item.pending == nil {
+			continue
+		}
+
+		unsubscribes = append(unsubscribes, item.progress.subscribe(c.resolver))
+
+		for _, auth := range item.pending.authz {
+			// the same authorization can be shared by the orders.
+			key := authorizationKey(auth)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			authz = append(authz, auth)
+		}
+	}
+
+	errs := make(map[*batchItem]error)
+
+	err := c.resolver.Solve(authz)
+
+	for _, unsubscribe := range unsubscribes {
+		unsubscribe()
+	}
+
+	if err == nil {
+		return errs
+	}
+
+	failures := domainFailures(err)
+
+	for _, item := range items {
+		if item.pending == nil {
+			continue
+		}
+
+		// the resolver doesn't report the failing domains: all the orders fail.
+		if failures == nil {
+			errs[item] = err
+			continue
+		}
+
+		orderFailures := make(obtainError)
+		for _, domain := range item.pending.domains {
+			if failure, ok := failures[domain]; ok {
+				orderFailures[domain] = failure
+			}
+		}
+
+		if len(orderFailures) > 0 {
+			errs[item] = orderFailures
+		}
+	}
+
+	return errs
+}
+
+// retryPartial retries a request of ObtainBatch without its failing domains, if ObtainRequest.AllowPartial is set.
+func (c *Certifier) retryPartial(item *batchItem, err error) (*Resource, error) {
+	if !item.request.AllowPartial {
+		return nil, err
+	}
+
+	remaining, removed := removeDomains(item.pending.domains, failedDomains(err))
+	if len(remaining) == 0 || len(removed) == 0 {
+		return nil, err
+	}
+
+	log.Warnf("[%s] acme: Dropping the failing domains and retrying: %v", strings.Join(removed, ", "), err)
+	item.progress.report(Progress{Stage: ProgressRetrying, Dropped: removed, Err: err})
+	item.progress.attempt++
+
+	request := item.request
+	request.Domains = remaining
+
+	return c.obtainPartial(request, item.progress, removed)
+}
+
+// authorizationKey identifies an authorization by the URLs of its challenges.
+func authorizationKey(authz acme.Authorization) string {
+	var urls []string
+	for _, chlg := range authz.Challenges {
+		urls = append(urls, chlg.URL)
+	}
+
+	return challenge.GetTargetedDomain(authz) + " " + strings.Join(urls, " ")
+}
+
+// domainFailures returns the error of each failing domain reported by an error of the resolver,
+// nil if the error doesn't report the failing domains.
+func domainFailures(err error) map[string]error {
+	if failures, ok := err.(obtainError); ok {
+		return failures
+	}
+
+	e, ok := err.(interface {
+		Domains() []string
+		Errors() []error
+	})
+	if !ok {
+		return nil
+	}
+
+	// both are sorted by domain.
+	domains, errs := e.Domains(), e.Errors()
+	if len(domains) != len(errs) {
+		return nil
+	}
+
+	failures := make(map[string]error)
+	for i, domain := range domains {
+		failures[domain] = errs[i]
+	}
+
+	return failures
+}
@@ -0,0 +1,166 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+// batchResolverMock records the solved authorizations, and fails the challenges of some domains.
+type batchResolverMock struct {
+	mu     sync.Mutex
+	calls  int
+	solved []string
+	fail   map[string]bool
+}
+
+func (r *batchResolverMock) Solve(authorizations []acme.Authorization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+
+	failures := make(obtainError)
+	for _, authz := range authorizations {
+		domain := challenge.GetTargetedDomain(authz)
+		r.solved = append(r.solved, domain)
+
+		if r.fail[domain] {
+			failures[domain] = errors.New("invalid challenge")
+		}
+	}
+
+	if len(failures) > 0 {
+		return failures
+	}
+	return nil
+}
+
+func setupBatchAPI(t *testing.T) string {
+	t.Helper()
+
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	t.Cleanup(tearDown)
+
+	var orders int32
+
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		jws, err := jose.ParseSigned(string(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var order acme.Order
+		err = json.Unmarshal(jws.UnsafePayloadWithoutVerification(), &order)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		order.Status = acme.StatusPending
+		for _, identifier := range order.Identifiers {
+			order.Authorizations = append(order.Authorizations, apiURL+"/authz/"+identifier.Value)
+		}
+		order.Finalize = apiURL + "/finalize"
+
+		w.Header().Set("Location", fmt.Sprintf("%s/order/%d", apiURL, atomic.AddInt32(&orders, 1)))
+		w.WriteHeader(http.StatusCreated)
+		_ = tester.WriteJSONResponse(w, order)
+	})
+
+	mux.HandleFunc("/authz/", func(w http.ResponseWriter, r *http.Request) {
+		domain := strings.TrimPrefix(r.URL.Path, "/authz/")
+
+		_ = tester.WriteJSONResponse(w, acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: domain},
+			Challenges: []acme.Challenge{{Type: "http-01", URL: apiURL + "/chlg/" + domain, Token: domain}},
+		})
+	})
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, r *http.Request) {
+		_ = tester.WriteJSONResponse(w, acme.Order{Status: acme.StatusValid, Certificate: apiURL + "/certificate"})
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(certResponseMock))
+	})
+
+	return apiURL
+}
+
+func TestCertifier_ObtainBatch(t *testing.T) {
+	apiURL := setupBatchAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	res := &batchResolverMock{fail: map[string]bool{"fail.wtf": true}}
+
+	certifier := NewCertifier(core, res, CertifierOptions{KeyType: certcrypto.EC256})
+
+	var stages []string
+	var mu sync.Mutex
+
+	results := certifier.ObtainBatch([]ObtainRequest{
+		{
+			Domains: []string{"acme.wtf", "www.acme.wtf"},
+			Progress: func(progress Progress) {
+				mu.Lock()
+				stages = append(stages, progress.Stage.String())
+				mu.Unlock()
+			},
+		},
+		{Domains: []string{"acme.wtf", "fail.wtf"}},
+		{Domains: []string{"lego.wtf", "fail.wtf"}, AllowPartial: true},
+		{},
+	})
+
+	require.Len(t, results, 4)
+
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "acme.wtf", results[0].Resource.Domain)
+	assert.Equal(t, []string{"order-created", "authorizations", "finalizing", "completed"}, stages)
+
+	require.Error(t, results[1].Err)
+	assert.Contains(t, results[1].Err.Error(), "[fail.wtf] invalid challenge")
+	assert.Nil(t, results[1].Resource)
+
+	require.NoError(t, results[2].Err)
+	assert.Equal(t, "lego.wtf", results[2].Resource.Domain)
+	assert.Equal(t, []string{"fail.wtf"}, results[2].Resource.DroppedDomains)
+
+	require.EqualError(t, results[3].Err, "no domains to obtain a certificate for")
+
+	// the challenges of the orders are solved in a single pass, the shared authorization (acme.wtf) once.
+	// the request allowing partial certificates is retried alone.
+	assert.Equal(t, 2, res.calls)
+	assert.Equal(t, []string{"acme.wtf", "www.acme.wtf", "fail.wtf", "lego.wtf", "lego.wtf"}, res.solved)
+}
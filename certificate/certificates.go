@@ -17,14 +17,17 @@ import (
 	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/clock"
 	"github.com/go-acme/lego/v3/platform/wait"
 	"golang.org/x/crypto/ocsp"
-	"golang.org/x/net/idna"
 )
 
 // maxBodySize is the maximum size of body that we will read.
 const maxBodySize = 1024 * 1024
 
+// clk is used to throttle the requests and to wait for the certificate.
+var clk = clock.Real
+
 // Resource represents a CA issued certificate.
 // PrivateKey, Certificate and IssuerCertificate are all
 // already PEM encoded and can be directly written to disk.
@@ -38,6 +41,21 @@ type Resource struct {
 	Certificate       []byte `json:"-"`
 	IssuerCertificate []byte `json:"-"`
 	CSR               []byte `json:"-"`
+
+	// DroppedDomains the requested domains which are not in the certificate (see ObtainRequest.AllowPartial).
+	DroppedDomains []string `json:"droppedDomains,omitempty"`
+
+	// The metadata of the certificate, parsed at issuance (see ParseMetadata).
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+	// SerialNumber the serial number of the certificate, in hexadecimal.
+	SerialNumber     string   `json:"serialNumber,omitempty"`
+	SANs             []string `json:"sans,omitempty"`
+	IssuerCommonName string   `json:"issuerCommonName,omitempty"`
+	// OCSPServers the URLs of the OCSP responders of the certificate.
+	OCSPServers []string `json:"ocspServers,omitempty"`
+	// RenewalInfoURL the URL of the renewal information of the certificate (ARI), empty if the CA doesn't provide it.
+	RenewalInfoURL string `json:"renewalInfoUrl,omitempty"`
 }
 
 // ObtainRequest The request to obtain certificate.
@@ -55,6 +73,28 @@ type ObtainRequest struct {
 	Bundle     bool
 	PrivateKey crypto.PrivateKey
 	MustStaple bool
+
+	// NotBefore and NotAfter are the requested validity period of the certificate (optional).
+	// They are only honored by the CAs supporting them (RFC 8555 §7.4).
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// AllowPartial retries the order without the domains which fail,
+	// instead of failing the whole certificate.
+	// The dropped domains are reported in Resource.DroppedDomains.
+	AllowPartial bool
+
+	// Progress receives the progress of the Obtain (optional):
+	// the state of the order, the events of the challenge of each identifier, the retries, and the elapsed time.
+	Progress ProgressFunc
+
+	// CommonName the domain used as common name of the CSR (optional, the first domain by default).
+	// It must be one of the domains.
+	CommonName string
+	// NoCommonName generates a CSR without common name, only with subject alternative names.
+	NoCommonName bool
+	// SANOrder the order of the subject alternative names in the CSR.
+	SANOrder SANOrder
 }
 
 type resolver interface {
@@ -64,6 +104,8 @@ type resolver interface {
 type CertifierOptions struct {
 	KeyType certcrypto.KeyType
 	Timeout time.Duration
+	// Policy restricts the certificates which can be obtained (optional).
+	Policy *Policy
 }
 
 // Certifier A service to obtain/renew/revoke certificates.
@@ -84,14 +126,96 @@ func NewCertifier(core *api.Core, resolver resolver, options CertifierOptions) *
 
 // Obtain tries to obtain a single certificate using all domains passed into it.
 //
-// This function will never return a partial certificate.
+// This function will never return a partial certificate, unless ObtainRequest.AllowPartial is set.
 // If one domain in the list fails, the whole certificate will fail.
 func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 	if len(request.Domains) == 0 {
 		return nil, errors.New("no domains to obtain a certificate for")
 	}
 
-	domains := sanitizeDomain(request.Domains)
+	domains, err := NormalizeDomains(request.Domains)
+	if err != nil {
+		return nil, err
+	}
+	request.Domains = domains
+
+	progress := newProgressReporter(request.Progress)
+
+	if !request.AllowPartial {
+		cert, err := c.obtain(request, progress)
+		return cert, progress.done(err)
+	}
+
+	cert, err := c.obtainPartial(request, progress, nil)
+	return cert, progress.done(err)
+}
+
+// obtainPartial obtains the certificate, and retries without the failing domains until it succeeds or no domain remains.
+// dropped are the domains already dropped from the request.
+func (c *Certifier) obtainPartial(request ObtainRequest, progress *progressReporter, dropped []string) (*Resource, error) {
+	for {
+		cert, err := c.obtain(request, progress)
+		if err == nil {
+			cert.DroppedDomains = dropped
+			return cert, nil
+		}
+
+		remaining, removed := removeDomains(request.Domains, failedDomains(err))
+		if len(remaining) == 0 || len(removed) == 0 {
+			return cert, err
+		}
+
+		log.Warnf("[%s] acme: Dropping the failing domains and retrying: %v", strings.Join(removed, ", "), err)
+		progress.report(Progress{Stage: ProgressRetrying, Dropped: removed, Err: err})
+
+		dropped = append(dropped, removed...)
+		request.Domains = remaining
+		progress.attempt++
+	}
+}
+
+func (c *Certifier) obtain(request ObtainRequest, progress *progressReporter) (*Resource, error) {
+	pending, err := c.newOrder(request, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	unsubscribe := progress.subscribe(c.resolver)
+	err = c.resolver.Solve(pending.authz)
+	unsubscribe()
+	if err != nil {
+		// If any challenge fails, return. Do not generate partial SAN certificates.
+		c.deactivateAuthorizations(pending.order)
+		return nil, err
+	}
+
+	return c.finalizeOrder(request, pending, progress)
+}
+
+// pendingOrder an order whose authorizations are fetched, before the validation of the challenges.
+type pendingOrder struct {
+	domains []string
+	order   acme.ExtendedOrder
+	authz   []acme.Authorization
+}
+
+// newOrder creates the order of the request, and fetches its authorizations.
+func (c *Certifier) newOrder(request ObtainRequest, progress *progressReporter) (*pendingOrder, error) {
+	domains, err := NormalizeDomains(request.Domains)
+	if err != nil {
+		return nil, err
+	}
+	progress.domains = domains
+
+	err = checkCSROptions(request, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.checkPolicy(domains, request.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
 
 	if request.Bundle {
 		log.Infof("[%s] acme: Obtaining bundled SAN certificate", strings.Join(domains, ", "))
@@ -99,31 +223,39 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 		log.Infof("[%s] acme: Obtaining SAN certificate", strings.Join(domains, ", "))
 	}
 
-	order, err := c.core.Orders.New(domains)
-	if err != nil {
-		return nil, err
+	orderOpts := &api.OrderOptions{
+		NotBefore: request.NotBefore,
+		NotAfter:  request.NotAfter,
 	}
 
-	authz, err := c.getAuthorizations(order)
+	order, err := c.core.Orders.NewWithOptions(domains, orderOpts)
 	if err != nil {
-		// If any challenge fails, return. Do not generate partial SAN certificates.
-		c.deactivateAuthorizations(order)
 		return nil, err
 	}
 
-	err = c.resolver.Solve(authz)
+	progress.report(Progress{Stage: ProgressOrderCreated})
+
+	authz, err := c.getAuthorizations(order)
 	if err != nil {
 		// If any challenge fails, return. Do not generate partial SAN certificates.
 		c.deactivateAuthorizations(order)
 		return nil, err
 	}
 
-	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
+	progress.report(Progress{Stage: ProgressAuthorizations, Authorizations: authorizationStatuses(authz)})
+
+	return &pendingOrder{domains: domains, order: order, authz: authz}, nil
+}
+
+// finalizeOrder requests the certificate of an order whose challenges are validated.
+func (c *Certifier) finalizeOrder(request ObtainRequest, pending *pendingOrder, progress *progressReporter) (*Resource, error) {
+	log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(pending.domains, ", "))
+	progress.report(Progress{Stage: ProgressFinalizing})
 
 	failures := make(obtainError)
-	cert, err := c.getForOrder(domains, order, request.Bundle, request.PrivateKey, request.MustStaple)
+	cert, err := c.getForOrder(request, pending.domains, pending.order)
 	if err != nil {
-		for _, auth := range authz {
+		for _, auth := range pending.authz {
 			failures[challenge.GetTargetedDomain(auth)] = err
 		}
 	}
@@ -148,7 +280,20 @@ func (c *Certifier) Obtain(request ObtainRequest) (*Resource, error) {
 func (c *Certifier) ObtainForCSR(csr x509.CertificateRequest, bundle bool) (*Resource, error) {
 	// figure out what domains it concerns
 	// start with the common name
-	domains := certcrypto.ExtractDomainsCSR(&csr)
+	domains, err := NormalizeDomains(certcrypto.ExtractDomainsCSR(&csr))
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.options.Policy.Check(domains, certcrypto.KeyTypeOf(csr.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+
+	err = certcrypto.CheckFIPSKey(csr.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("the key of the CSR: %w", err)
+	}
 
 	if bundle {
 		log.Infof("[%s] acme: Obtaining bundled SAN certificate given a CSR", strings.Join(domains, ", "))
@@ -198,7 +343,165 @@ func (c *Certifier) ObtainForCSR(csr x509.CertificateRequest, bundle bool) (*Res
 	return cert, nil
 }
 
-func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, bundle bool, privateKey crypto.PrivateKey, mustStaple bool) (*Resource, error) {
+// OrderRequest the request to complete an existing order, see ObtainFromOrder.
+type OrderRequest struct {
+	Bundle     bool
+	MustStaple bool
+
+	// PrivateKey the key of the certificate (optional).
+	// A new key is generated if neither PrivateKey nor CSR is set.
+	PrivateKey crypto.PrivateKey
+	// CSR the CSR used to finalize the order (optional): its domains must be the identifiers of the order.
+	CSR *x509.CertificateRequest
+}
+
+// ObtainFromOrder completes an existing order (e.g. created by another tool, or by a run which crashed) instead of creating a new one.
+//
+// The pending authorizations of the order are solved, then the order is finalized with the CSR of the request,
+// or with a CSR generated for the identifiers of the order.
+// If the order is already finalized (processing or valid), its certificate is fetched:
+// the private key of the Resource is only set if it is supplied by the request.
+func (c *Certifier) ObtainFromOrder(orderURL string, request OrderRequest) (*Resource, error) {
+	o, err := c.core.Orders.Get(orderURL)
+	if err != nil {
+		return nil, err
+	}
+
+	order := acme.ExtendedOrder{Order: o, Location: orderURL}
+
+	domains, err := c.orderDomains(order, request)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("[%s] acme: Resuming the order %s (%s)", strings.Join(domains, ", "), orderURL, order.Status)
+
+	var privateKeyPem []byte
+	if request.PrivateKey != nil {
+		privateKeyPem = certcrypto.PEMEncode(request.PrivateKey)
+	}
+
+	switch order.Status {
+	case acme.StatusPending:
+		authz, errA := c.getAuthorizations(order)
+		if errA != nil {
+			return nil, errA
+		}
+
+		errA = c.resolver.Solve(authz)
+		if errA != nil {
+			// If any challenge fails, return. Do not generate partial SAN certificates.
+			c.deactivateAuthorizations(order)
+			return nil, errA
+		}
+
+		log.Infof("[%s] acme: Validations succeeded; requesting certificates", strings.Join(domains, ", "))
+
+		return c.finalizeExistingOrder(domains, order, request)
+
+	case acme.StatusReady:
+		return c.finalizeExistingOrder(domains, order, request)
+
+	case acme.StatusProcessing, acme.StatusValid:
+		certRes := &Resource{
+			Domain:     domains[0],
+			CertURL:    order.Certificate,
+			PrivateKey: privateKeyPem,
+		}
+
+		if order.Status == acme.StatusValid {
+			ok, errR := c.checkResponse(order.Order, certRes, request.Bundle)
+			if errR != nil || ok {
+				return certRes, errR
+			}
+		}
+
+		return certRes, c.waitForCertificate(order, certRes, request.Bundle)
+
+	case acme.StatusInvalid:
+		if order.Error != nil {
+			return nil, fmt.Errorf("the order %s is invalid: %w", orderURL, order.Error)
+		}
+		return nil, fmt.Errorf("the order %s is invalid", orderURL)
+
+	default:
+		return nil, fmt.Errorf("the order %s has an unexpected status: %s", orderURL, order.Status)
+	}
+}
+
+// orderDomains returns the domains of an existing order, checked against the CSR of the request and the policy.
+// The common name of the CSR, if any, is the first domain.
+func (c *Certifier) orderDomains(order acme.ExtendedOrder, request OrderRequest) ([]string, error) {
+	var identifiers []string
+	for _, identifier := range order.Identifiers {
+		identifiers = append(identifiers, identifier.Value)
+	}
+
+	domains, err := NormalizeDomains(identifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	keyType := c.options.KeyType
+	if request.PrivateKey != nil {
+		keyType = certcrypto.KeyTypeOf(request.PrivateKey)
+	}
+
+	if request.CSR != nil {
+		keyType = certcrypto.KeyTypeOf(request.CSR.PublicKey)
+
+		csrDomains, errC := NormalizeDomains(certcrypto.ExtractDomainsCSR(request.CSR))
+		if errC != nil {
+			return nil, errC
+		}
+
+		if !sameDomains(domains, csrDomains) {
+			return nil, fmt.Errorf("the domains of the CSR (%s) are not the identifiers of the order (%s)",
+				strings.Join(csrDomains, ", "), strings.Join(domains, ", "))
+		}
+
+		domains = csrDomains
+	}
+
+	err = c.options.Policy.Check(domains, keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	return domains, nil
+}
+
+// finalizeExistingOrder finalizes an existing order with the CSR of the request, or with a generated CSR.
+func (c *Certifier) finalizeExistingOrder(domains []string, order acme.ExtendedOrder, request OrderRequest) (*Resource, error) {
+	if request.CSR == nil {
+		return c.getForOrder(ObtainRequest{
+			Domains:    domains,
+			Bundle:     request.Bundle,
+			PrivateKey: request.PrivateKey,
+			MustStaple: request.MustStaple,
+		}, domains, order)
+	}
+
+	err := certcrypto.CheckFIPSKey(request.CSR.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("the key of the CSR: %w", err)
+	}
+
+	var privateKeyPem []byte
+	if request.PrivateKey != nil {
+		privateKeyPem = certcrypto.PEMEncode(request.PrivateKey)
+	}
+
+	cert, err := c.getForCSR(domains, order, request.Bundle, request.CSR.Raw, privateKeyPem)
+	if cert != nil {
+		cert.CSR = certcrypto.PEMEncode(request.CSR)
+	}
+
+	return cert, err
+}
+
+func (c *Certifier) getForOrder(request ObtainRequest, domains []string, order acme.ExtendedOrder) (*Resource, error) {
+	privateKey := request.PrivateKey
 	if privateKey == nil {
 		var err error
 		privateKey, err = certcrypto.GeneratePrivateKey(c.options.KeyType)
@@ -208,28 +511,17 @@ func (c *Certifier) getForOrder(domains []string, order acme.ExtendedOrder, bund
 	}
 
 	// Determine certificate name(s) based on the authorization resources
-	commonName := domains[0]
-
-	// RFC8555 Section 7.4 "Applying for Certificate Issuance"
-	// https://tools.ietf.org/html/rfc8555#section-7.4
-	// says:
-	//   Clients SHOULD NOT make any assumptions about the sort order of
-	//   "identifiers" or "authorizations" elements in the returned order
-	//   object.
-	san := []string{commonName}
-	for _, auth := range order.Identifiers {
-		if auth.Value != commonName {
-			san = append(san, auth.Value)
-		}
+	commonName, san, err := csrNames(request, domains, order.Identifiers)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: should the CSR be customizable?
-	csr, err := certcrypto.GenerateCSR(privateKey, commonName, san, mustStaple)
+	csr, err := certcrypto.GenerateCSR(privateKey, commonName, san, request.MustStaple)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.getForCSR(domains, order, bundle, csr, certcrypto.PEMEncode(privateKey))
+	return c.getForCSR(domains, order, request.Bundle, csr, certcrypto.PEMEncode(privateKey))
 }
 
 func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle bool, csr []byte, privateKeyPem []byte) (*Resource, error) {
@@ -257,12 +549,17 @@ func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle
 		}
 	}
 
+	return certRes, c.waitForCertificate(order, certRes, bundle)
+}
+
+// waitForCertificate waits for the certificate of a finalized order, and loads it into certRes.
+func (c *Certifier) waitForCertificate(order acme.ExtendedOrder, certRes *Resource, bundle bool) error {
 	timeout := c.options.Timeout
 	if c.options.Timeout <= 0 {
 		timeout = 30 * time.Second
 	}
 
-	err = wait.For("certificate", timeout, timeout/60, func() (bool, error) {
+	return wait.ForWithClock(clk, "certificate", timeout, timeout/60, func() (bool, error) {
 		ord, errW := c.core.Orders.Get(order.Location)
 		if errW != nil {
 			return false, errW
@@ -275,8 +572,6 @@ func (c *Certifier) getForCSR(domains []string, order acme.ExtendedOrder, bundle
 
 		return done, nil
 	})
-
-	return certRes, err
 }
 
 // checkResponse checks to see if the certificate is ready and a link is contained in the response.
@@ -305,6 +600,11 @@ func (c *Certifier) checkResponse(order acme.Order, certRes *Resource, bundle bo
 	certRes.CertURL = order.Certificate
 	certRes.CertStableURL = order.Certificate
 
+	err = certRes.ParseMetadata(c.core.GetDirectory().RenewalInfoURL)
+	if err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
 
@@ -352,7 +652,7 @@ func (c *Certifier) Renew(certRes Resource, bundle, mustStaple bool) (*Resource,
 	}
 
 	// This is just meant to be informal for the user.
-	timeLeft := x509Cert.NotAfter.Sub(time.Now().UTC())
+	timeLeft := x509Cert.NotAfter.Sub(clk.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", certRes.Domain, int(timeLeft.Hours()))
 
 	// We always need to request a new certificate to renew.
@@ -375,11 +675,13 @@ func (c *Certifier) Renew(certRes Resource, bundle, mustStaple bool) (*Resource,
 		}
 	}
 
+	// keep the main domain first, even if the common name of the certificate is another domain.
 	query := ObtainRequest{
-		Domains:    certcrypto.ExtractDomains(x509Cert),
+		Domains:    moveFirst(certcrypto.ExtractDomains(x509Cert), certRes.Domain),
 		Bundle:     bundle,
 		PrivateKey: privateKey,
 		MustStaple: mustStaple,
+		CommonName: x509Cert.Subject.CommonName,
 	}
 	return c.Obtain(query)
 }
@@ -482,13 +784,20 @@ func (c *Certifier) Get(url string, bundle bool) (*Resource, error) {
 		return nil, err
 	}
 
-	return &Resource{
+	certRes := &Resource{
 		Domain:            x509Certs[0].Subject.CommonName,
 		Certificate:       cert,
 		IssuerCertificate: issuer,
 		CertURL:           url,
 		CertStableURL:     url,
-	}, nil
+	}
+
+	err = certRes.ParseMetadata(c.core.GetDirectory().RenewalInfoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return certRes, nil
 }
 
 func checkOrderStatus(order acme.Order) (bool, error) {
@@ -501,22 +810,3 @@ func checkOrderStatus(order acme.Order) (bool, error) {
 		return false, nil
 	}
 }
-
-// https://tools.ietf.org/html/rfc8555#section-7.1.4
-// The domain name MUST be encoded
-//   in the form in which it would appear in a certificate.  That is, it
-//   MUST be encoded according to the rules in Section 7 of [RFC5280].
-//
-// https://tools.ietf.org/html/rfc5280#section-7
-func sanitizeDomain(domains []string) []string {
-	var sanitizedDomains []string
-	for _, domain := range domains {
-		sanitizedDomain, err := idna.ToASCII(domain)
-		if err != nil {
-			log.Infof("skip domain %q: unable to sanitize (punnycode): %v", domain, err)
-		} else {
-			sanitizedDomains = append(sanitizedDomains, sanitizedDomain)
-		}
-	}
-	return sanitizedDomains
-}
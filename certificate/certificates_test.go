@@ -3,8 +3,10 @@ package certificate
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/pem"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/go-acme/lego/v3/acme"
@@ -241,3 +243,148 @@ type resolverMock struct {
 func (r *resolverMock) Solve(authorizations []acme.Authorization) error {
 	return r.error
 }
+
+func setupOrderAPI(t *testing.T, status string) string {
+	t.Helper()
+
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	t.Cleanup(tearDown)
+
+	mux.HandleFunc("/order/1", func(w http.ResponseWriter, _ *http.Request) {
+		order := acme.Order{
+			Status: status,
+			Identifiers: []acme.Identifier{
+				{Type: "dns", Value: "www.acme.wtf"},
+				{Type: "dns", Value: "acme.wtf"},
+			},
+			Authorizations: []string{apiURL + "/authz/www.acme.wtf", apiURL + "/authz/acme.wtf"},
+			Finalize:       apiURL + "/finalize",
+		}
+
+		switch status {
+		case acme.StatusValid:
+			order.Certificate = apiURL + "/certificate"
+		case acme.StatusInvalid:
+			order.Error = &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:unauthorized", Detail: "no TXT record"}
+		}
+
+		_ = tester.WriteJSONResponse(w, order)
+	})
+
+	mux.HandleFunc("/authz/", func(w http.ResponseWriter, r *http.Request) {
+		domain := strings.TrimPrefix(r.URL.Path, "/authz/")
+
+		authz := acme.Authorization{
+			Status:     acme.StatusPending,
+			Identifier: acme.Identifier{Type: "dns", Value: domain},
+			Challenges: []acme.Challenge{{Type: "http-01", URL: apiURL + "/chlg/" + domain, Token: domain}},
+		}
+
+		// the authorization validated by the previous run.
+		if domain == "www.acme.wtf" {
+			authz.Status = acme.StatusValid
+		}
+
+		_ = tester.WriteJSONResponse(w, authz)
+	})
+
+	mux.HandleFunc("/finalize", func(w http.ResponseWriter, _ *http.Request) {
+		_ = tester.WriteJSONResponse(w, acme.Order{Status: acme.StatusValid, Certificate: apiURL + "/certificate"})
+	})
+
+	mux.HandleFunc("/certificate", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(certResponseMock))
+	})
+
+	return apiURL
+}
+
+func newOrderCertifier(t *testing.T, apiURL string, res resolver) *Certifier {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	return NewCertifier(core, res, CertifierOptions{KeyType: certcrypto.EC256})
+}
+
+func TestCertifier_ObtainFromOrder(t *testing.T) {
+	apiURL := setupOrderAPI(t, acme.StatusPending)
+
+	res := &batchResolverMock{}
+	certifier := newOrderCertifier(t, apiURL, res)
+
+	cert, err := certifier.ObtainFromOrder(apiURL+"/order/1", OrderRequest{Bundle: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "www.acme.wtf", cert.Domain)
+	assert.Equal(t, apiURL+"/certificate", cert.CertURL)
+	assert.NotEmpty(t, cert.PrivateKey)
+	assert.NotEmpty(t, cert.Certificate)
+
+	// the authorizations of the order are solved in a single pass, the resolver skips the valid ones.
+	assert.Equal(t, 1, res.calls)
+	assert.ElementsMatch(t, []string{"www.acme.wtf", "acme.wtf"}, res.solved)
+}
+
+func TestCertifier_ObtainFromOrder_csr(t *testing.T) {
+	apiURL := setupOrderAPI(t, acme.StatusReady)
+
+	res := &batchResolverMock{}
+	certifier := newOrderCertifier(t, apiURL, res)
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	csrDER, err := certcrypto.GenerateCSR(privateKey, "acme.wtf", []string{"www.acme.wtf"}, false)
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	require.NoError(t, err)
+
+	cert, err := certifier.ObtainFromOrder(apiURL+"/order/1", OrderRequest{CSR: csr})
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme.wtf", cert.Domain)
+	assert.Empty(t, cert.PrivateKey)
+	assert.NotEmpty(t, cert.CSR)
+	assert.Equal(t, 0, res.calls)
+
+	csrDER, err = certcrypto.GenerateCSR(privateKey, "acme.wtf", []string{"lego.wtf"}, false)
+	require.NoError(t, err)
+
+	csr, err = x509.ParseCertificateRequest(csrDER)
+	require.NoError(t, err)
+
+	_, err = certifier.ObtainFromOrder(apiURL+"/order/1", OrderRequest{CSR: csr})
+	require.EqualError(t, err, "the domains of the CSR (acme.wtf, lego.wtf) are not the identifiers of the order (www.acme.wtf, acme.wtf)")
+}
+
+func TestCertifier_ObtainFromOrder_valid(t *testing.T) {
+	apiURL := setupOrderAPI(t, acme.StatusValid)
+
+	res := &batchResolverMock{}
+	certifier := newOrderCertifier(t, apiURL, res)
+
+	cert, err := certifier.ObtainFromOrder(apiURL+"/order/1", OrderRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "www.acme.wtf", cert.Domain)
+	assert.Empty(t, cert.PrivateKey)
+	assert.NotEmpty(t, cert.Certificate)
+	assert.Equal(t, 0, res.calls)
+}
+
+func TestCertifier_ObtainFromOrder_invalid(t *testing.T) {
+	apiURL := setupOrderAPI(t, acme.StatusInvalid)
+
+	certifier := newOrderCertifier(t, apiURL, &batchResolverMock{})
+
+	_, err := certifier.ObtainFromOrder(apiURL+"/order/1", OrderRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the order "+apiURL+"/order/1 is invalid")
+	assert.Contains(t, err.Error(), "no TXT record")
+}
@@ -0,0 +1,149 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/x509"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+)
+
+// ChainOptions changes the chain of a certificate before it is stored,
+// e.g. for the appliances rejecting the chains containing the root, or not ordered from the leaf.
+type ChainOptions struct {
+	// StripRoot removes the self-signed CA certificates (roots) from the certificate bundle and from the issuer certificate.
+	StripRoot bool
+	// LeafOnly keeps only the leaf certificate: the certificate is not bundled, and the issuer certificate is removed.
+	LeafOnly bool
+	// LeafFirst orders the certificate bundle and the issuer certificate from the leaf to the root:
+	// each certificate is followed by its issuer.
+	LeafFirst bool
+}
+
+// Apply changes the chain of the certificate (Resource.Certificate and Resource.IssuerCertificate).
+func (o ChainOptions) Apply(certRes *Resource) error {
+	if !o.StripRoot && !o.LeafOnly && !o.LeafFirst {
+		return nil
+	}
+
+	certificates, err := certcrypto.ParsePEMBundle(certRes.Certificate)
+	if err != nil {
+		return err
+	}
+
+	var issuers []*x509.Certificate
+	if len(certRes.IssuerCertificate) > 0 {
+		issuers, err = certcrypto.ParsePEMBundle(certRes.IssuerCertificate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.LeafFirst || o.LeafOnly {
+		certificates = orderChain(certificates)
+		issuers = orderChain(issuers)
+	}
+
+	if o.LeafOnly {
+		certRes.Certificate = encodeChain(certificates[:1])
+		certRes.IssuerCertificate = nil
+		return nil
+	}
+
+	if o.StripRoot {
+		// the first certificate of the bundle is kept, even if self-signed.
+		certificates = append(certificates[:1], stripRoots(certificates[1:])...)
+		issuers = stripRoots(issuers)
+	}
+
+	certRes.Certificate = encodeChain(certificates)
+
+	if len(issuers) > 0 {
+		certRes.IssuerCertificate = encodeChain(issuers)
+	} else {
+		certRes.IssuerCertificate = nil
+	}
+
+	return nil
+}
+
+// orderChain orders the certificates from the leaf to the root.
+// The chain starts with the certificate issuing none of the others,
+// the certificates which are not part of the chain are kept at the end, in their order.
+func orderChain(certificates []*x509.Certificate) []*x509.Certificate {
+	if len(certificates) < 2 {
+		return certificates
+	}
+
+	start := 0
+	for i, cert := range certificates {
+		if !issuesAny(cert, certificates) {
+			start = i
+			break
+		}
+	}
+
+	used := make([]bool, len(certificates))
+	ordered := []*x509.Certificate{certificates[start]}
+	used[start] = true
+
+	for current := certificates[start]; ; {
+		next := -1
+		for i, cert := range certificates {
+			if !used[i] && isIssuer(cert, current) {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			break
+		}
+
+		used[next] = true
+		current = certificates[next]
+		ordered = append(ordered, current)
+	}
+
+	for i, cert := range certificates {
+		if !used[i] {
+			ordered = append(ordered, cert)
+		}
+	}
+
+	return ordered
+}
+
+// issuesAny returns true if the certificate is the issuer of another certificate of the list.
+func issuesAny(issuer *x509.Certificate, certificates []*x509.Certificate) bool {
+	for _, cert := range certificates {
+		if cert != issuer && isIssuer(issuer, cert) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIssuer returns true if the issuer signed the certificate.
+func isIssuer(issuer, cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, issuer.RawSubject) && cert.CheckSignatureFrom(issuer) == nil
+}
+
+// stripRoots removes the self-signed CA certificates.
+func stripRoots(certificates []*x509.Certificate) []*x509.Certificate {
+	var stripped []*x509.Certificate
+	for _, cert := range certificates {
+		if cert.IsCA && isIssuer(cert, cert) {
+			continue
+		}
+		stripped = append(stripped, cert)
+	}
+	return stripped
+}
+
+func encodeChain(certificates []*x509.Certificate) []byte {
+	var chain []byte
+	for _, cert := range certificates {
+		chain = append(chain, certcrypto.PEMEncode(certcrypto.DERCertificateBytes(cert.Raw))...)
+	}
+	return chain
+}
@@ -0,0 +1,171 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testChain a leaf, its intermediate, and the root.
+type testChain struct {
+	leaf, intermediate, root *x509.Certificate
+}
+
+func newTestChain(t *testing.T) testChain {
+	t.Helper()
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	root := createTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, rootKey, rootKey)
+
+	intermediateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	intermediate := createTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Intermediate"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, root, intermediateKey, rootKey)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	leaf := createTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+	}, intermediate, leafKey, intermediateKey)
+
+	return testChain{leaf: leaf, intermediate: intermediate, root: root}
+}
+
+func createTestCert(t *testing.T, template, parent *x509.Certificate, key, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(24 * time.Hour)
+
+	if parent == nil {
+		parent = template
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func commonNames(t *testing.T, chain []byte) []string {
+	t.Helper()
+
+	if len(chain) == 0 {
+		return nil
+	}
+
+	certificates, err := certcrypto.ParsePEMBundle(chain)
+	require.NoError(t, err)
+
+	var names []string
+	for _, cert := range certificates {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return names
+}
+
+func TestChainOptions_Apply(t *testing.T) {
+	chain := newTestChain(t)
+
+	testCases := []struct {
+		desc           string
+		options        ChainOptions
+		certificate    []*x509.Certificate
+		issuer         []*x509.Certificate
+		expectedCert   []string
+		expectedIssuer []string
+	}{
+		{
+			desc:           "no options",
+			certificate:    []*x509.Certificate{chain.root, chain.leaf, chain.intermediate},
+			issuer:         []*x509.Certificate{chain.root, chain.intermediate},
+			expectedCert:   []string{"Test Root", "example.com", "Test Intermediate"},
+			expectedIssuer: []string{"Test Root", "Test Intermediate"},
+		},
+		{
+			desc:           "strip root",
+			options:        ChainOptions{StripRoot: true},
+			certificate:    []*x509.Certificate{chain.leaf, chain.intermediate, chain.root},
+			issuer:         []*x509.Certificate{chain.intermediate, chain.root},
+			expectedCert:   []string{"example.com", "Test Intermediate"},
+			expectedIssuer: []string{"Test Intermediate"},
+		},
+		{
+			desc:         "strip root only",
+			options:      ChainOptions{StripRoot: true},
+			certificate:  []*x509.Certificate{chain.leaf, chain.root},
+			issuer:       []*x509.Certificate{chain.root},
+			expectedCert: []string{"example.com"},
+		},
+		{
+			desc:           "leaf first",
+			options:        ChainOptions{LeafFirst: true},
+			certificate:    []*x509.Certificate{chain.root, chain.intermediate, chain.leaf},
+			issuer:         []*x509.Certificate{chain.root, chain.intermediate},
+			expectedCert:   []string{"example.com", "Test Intermediate", "Test Root"},
+			expectedIssuer: []string{"Test Intermediate", "Test Root"},
+		},
+		{
+			desc:           "leaf first and strip root",
+			options:        ChainOptions{LeafFirst: true, StripRoot: true},
+			certificate:    []*x509.Certificate{chain.root, chain.intermediate, chain.leaf},
+			issuer:         []*x509.Certificate{chain.root, chain.intermediate},
+			expectedCert:   []string{"example.com", "Test Intermediate"},
+			expectedIssuer: []string{"Test Intermediate"},
+		},
+		{
+			desc:         "leaf only",
+			options:      ChainOptions{LeafOnly: true},
+			certificate:  []*x509.Certificate{chain.intermediate, chain.leaf},
+			issuer:       []*x509.Certificate{chain.intermediate},
+			expectedCert: []string{"example.com"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certRes := &Resource{
+				Certificate:       encodeChain(test.certificate),
+				IssuerCertificate: encodeChain(test.issuer),
+			}
+
+			err := test.options.Apply(certRes)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedCert, commonNames(t, certRes.Certificate))
+			assert.Equal(t, test.expectedIssuer, commonNames(t, certRes.IssuerCertificate))
+		})
+	}
+}
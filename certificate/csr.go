@@ -0,0 +1,153 @@
+package certificate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/log"
+	"golang.org/x/net/idna"
+)
+
+// maxCommonNameLength the upper bound of the common name (RFC 5280, ub-common-name).
+const maxCommonNameLength = 64
+
+// SANOrder the order of the subject alternative names in the CSR generated by Obtain.
+type SANOrder string
+
+const (
+	// SANOrderDefault the common name first, then the identifiers in the order of the ACME order.
+	SANOrderDefault SANOrder = ""
+	// SANOrderRequest the domains in the order of the request.
+	SANOrderRequest SANOrder = "request"
+	// SANOrderSorted the domains in lexicographic order.
+	SANOrderSorted SANOrder = "sorted"
+)
+
+// ParseSANOrder parses an order of the subject alternative names: default, request, or sorted.
+func ParseSANOrder(value string) (SANOrder, error) {
+	switch SANOrder(value) {
+	case SANOrderDefault, "default":
+		return SANOrderDefault, nil
+	case SANOrderRequest, SANOrderSorted:
+		return SANOrder(value), nil
+	default:
+		return "", fmt.Errorf("invalid SAN order %q: default, request, or sorted", value)
+	}
+}
+
+// checkCSROptions checks the common name and SAN order options of a request, against the sanitized domains.
+func checkCSROptions(request ObtainRequest, domains []string) error {
+	if _, err := ParseSANOrder(string(request.SANOrder)); err != nil {
+		return err
+	}
+
+	if request.CommonName == "" {
+		return nil
+	}
+
+	if request.NoCommonName {
+		return fmt.Errorf("the common name %q can't be set without common name", request.CommonName)
+	}
+
+	commonName, err := idna.ToASCII(request.CommonName)
+	if err != nil {
+		return fmt.Errorf("invalid common name %q: %w", request.CommonName, err)
+	}
+
+	if len(commonName) > maxCommonNameLength {
+		return fmt.Errorf("the common name %q is longer than %d bytes", commonName, maxCommonNameLength)
+	}
+
+	if !containsDomain(domains, commonName) {
+		return fmt.Errorf("the common name %q is not one of the domains of the request", commonName)
+	}
+
+	return nil
+}
+
+// csrNames returns the common name (empty if there is none) and the subject alternative names of the CSR of a request.
+func csrNames(request ObtainRequest, domains []string, identifiers []acme.Identifier) (string, []string, error) {
+	err := checkCSROptions(request, domains)
+	if err != nil {
+		return "", nil, err
+	}
+
+	first := domains[0]
+
+	var commonName string
+	switch {
+	case request.NoCommonName:
+	case request.CommonName != "":
+		commonName, _ = idna.ToASCII(request.CommonName)
+		first = commonName
+	case len(first) > maxCommonNameLength:
+		log.Infof("[%s] acme: the domain is longer than %d bytes, the CSR has no common name", first, maxCommonNameLength)
+	default:
+		commonName = first
+	}
+
+	switch request.SANOrder {
+	case SANOrderRequest:
+		return commonName, domains, nil
+
+	case SANOrderSorted:
+		san := append([]string(nil), domains...)
+		sort.Strings(san)
+		return commonName, san, nil
+
+	default:
+		// RFC8555 Section 7.4 "Applying for Certificate Issuance"
+		// https://tools.ietf.org/html/rfc8555#section-7.4
+		// says:
+		//   Clients SHOULD NOT make any assumptions about the sort order of
+		//   "identifiers" or "authorizations" elements in the returned order
+		//   object.
+		san := []string{first}
+		for _, ident := range identifiers {
+			if ident.Value != first {
+				san = append(san, ident.Value)
+			}
+		}
+		return commonName, san, nil
+	}
+}
+
+func containsDomain(domains []string, domain string) bool {
+	for _, d := range domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// sameDomains returns true if the two lists hold the same domains, in any order.
+func sameDomains(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, domain := range a {
+		if !containsDomain(b, domain) {
+			return false
+		}
+	}
+	return true
+}
+
+// moveFirst moves a domain to the first position, if it is one of the domains.
+func moveFirst(domains []string, domain string) []string {
+	if !containsDomain(domains, domain) {
+		return domains
+	}
+
+	result := []string{domain}
+	for _, d := range domains {
+		if d != domain {
+			result = append(result, d)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,106 @@
+package certificate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_csrNames(t *testing.T) {
+	domains := []string{"example.com", "www.example.com", "api.example.com"}
+
+	// the CA returns the identifiers in its own order.
+	identifiers := []acme.Identifier{
+		{Type: "dns", Value: "api.example.com"},
+		{Type: "dns", Value: "example.com"},
+		{Type: "dns", Value: "www.example.com"},
+	}
+
+	testCases := []struct {
+		desc       string
+		request    ObtainRequest
+		domains    []string
+		commonName string
+		san        []string
+		expected   string
+	}{
+		{
+			desc:       "default",
+			commonName: "example.com",
+			san:        []string{"example.com", "api.example.com", "www.example.com"},
+		},
+		{
+			desc:       "common name",
+			request:    ObtainRequest{CommonName: "www.example.com"},
+			commonName: "www.example.com",
+			san:        []string{"www.example.com", "api.example.com", "example.com"},
+		},
+		{
+			desc:    "no common name",
+			request: ObtainRequest{NoCommonName: true},
+			san:     []string{"example.com", "api.example.com", "www.example.com"},
+		},
+		{
+			desc:       "request order",
+			request:    ObtainRequest{CommonName: "api.example.com", SANOrder: SANOrderRequest},
+			commonName: "api.example.com",
+			san:        []string{"example.com", "www.example.com", "api.example.com"},
+		},
+		{
+			desc:       "sorted",
+			request:    ObtainRequest{SANOrder: SANOrderSorted},
+			commonName: "example.com",
+			san:        []string{"api.example.com", "example.com", "www.example.com"},
+		},
+		{
+			desc:    "long first domain",
+			domains: []string{strings.Repeat("a", 60) + ".example.com", "example.com"},
+			san:     []string{strings.Repeat("a", 60) + ".example.com", "api.example.com", "example.com", "www.example.com"},
+		},
+		{
+			desc:     "unknown common name",
+			request:  ObtainRequest{CommonName: "example.org"},
+			expected: `the common name "example.org" is not one of the domains of the request`,
+		},
+		{
+			desc:     "common name and no common name",
+			request:  ObtainRequest{CommonName: "example.com", NoCommonName: true},
+			expected: `the common name "example.com" can't be set without common name`,
+		},
+		{
+			desc:     "invalid SAN order",
+			request:  ObtainRequest{SANOrder: "random"},
+			expected: `invalid SAN order "random": default, request, or sorted`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			requestDomains := domains
+			if test.domains != nil {
+				requestDomains = test.domains
+			}
+
+			commonName, san, err := csrNames(test.request, requestDomains, identifiers)
+			if test.expected != "" {
+				require.EqualError(t, err, test.expected)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.commonName, commonName)
+			assert.Equal(t, test.san, san)
+		})
+	}
+}
+
+func Test_moveFirst(t *testing.T) {
+	assert.Equal(t, []string{"b", "a", "c"}, moveFirst([]string{"a", "b", "c"}, "b"))
+	assert.Equal(t, []string{"a", "b"}, moveFirst([]string{"a", "b"}, "z"))
+}
@@ -0,0 +1,148 @@
+package certificate
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	maxDomainLength = 253
+	maxLabelLength  = 63
+)
+
+// DomainProblem the problem of an invalid domain.
+type DomainProblem struct {
+	Domain string
+	Reason string
+}
+
+// DomainsError the problems of all the invalid domains of a request.
+type DomainsError struct {
+	Problems []DomainProblem
+}
+
+func (e *DomainsError) Error() string {
+	var msgs []string
+	for _, problem := range e.Problems {
+		msgs = append(msgs, fmt.Sprintf("%q: %s", problem.Domain, problem.Reason))
+	}
+
+	return "invalid domains: " + strings.Join(msgs, "; ")
+}
+
+// NormalizeDomains normalizes and validates the domains of a request, before any interaction with the CA:
+// the domains are trimmed, converted to ASCII (punycode), lowercased, without trailing dot, and deduplicated (the order is kept).
+//
+// A domain must be a valid hostname (letters, digits and hyphens, labels of 1 to 63 bytes, 253 bytes at most),
+// and a wildcard (*) is only allowed as the whole left-most label of a domain having at least two other labels.
+// All the problems are reported in a single DomainsError.
+//
+// https://tools.ietf.org/html/rfc8555#section-7.1.4
+// The domain name MUST be encoded
+//   in the form in which it would appear in a certificate.  That is, it
+//   MUST be encoded according to the rules in Section 7 of [RFC5280].
+//
+// https://tools.ietf.org/html/rfc5280#section-7
+func NormalizeDomains(domains []string) ([]string, error) {
+	var normalized []string
+	var problems []DomainProblem
+
+	seen := make(map[string]bool)
+
+	for _, domain := range domains {
+		value, reason := normalizeDomain(domain)
+		if reason != "" {
+			problems = append(problems, DomainProblem{Domain: domain, Reason: reason})
+			continue
+		}
+
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+
+		normalized = append(normalized, value)
+	}
+
+	if len(problems) > 0 {
+		return nil, &DomainsError{Problems: problems}
+	}
+
+	if len(normalized) == 0 {
+		return nil, &DomainsError{Problems: []DomainProblem{{Reason: "no domain"}}}
+	}
+
+	return normalized, nil
+}
+
+// normalizeDomain returns the normalized domain, or the reason why the domain is invalid.
+func normalizeDomain(domain string) (string, string) {
+	value := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	if value == "" {
+		return "", "empty domain"
+	}
+
+	var wildcard bool
+	if strings.HasPrefix(value, "*.") {
+		wildcard = true
+		value = strings.TrimPrefix(value, "*.")
+	}
+
+	ascii, err := idna.ToASCII(value)
+	if err != nil {
+		return "", fmt.Sprintf("invalid internationalized domain: %v", err)
+	}
+
+	ascii = strings.ToLower(ascii)
+
+	labels := strings.Split(ascii, ".")
+
+	if wildcard && len(labels) < 2 {
+		return "", "a wildcard must be followed by at least two labels"
+	}
+
+	for _, label := range labels {
+		if reason := checkLabel(label); reason != "" {
+			return "", reason
+		}
+	}
+
+	if wildcard {
+		ascii = "*." + ascii
+	}
+
+	if len(ascii) > maxDomainLength {
+		return "", fmt.Sprintf("longer than %d bytes", maxDomainLength)
+	}
+
+	return ascii, ""
+}
+
+func checkLabel(label string) string {
+	switch {
+	case label == "":
+		return "empty label"
+	case label == "*":
+		return "a wildcard is only allowed as the left-most label"
+	case len(label) > maxLabelLength:
+		return fmt.Sprintf("label %q longer than %d bytes", label, maxLabelLength)
+	case strings.HasPrefix(label, "-"), strings.HasSuffix(label, "-"):
+		return fmt.Sprintf("label %q starts or ends with a hyphen", label)
+	}
+
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+		case r == '_':
+			return fmt.Sprintf("underscore in label %q", label)
+		case r == '*':
+			return fmt.Sprintf("wildcard in label %q", label)
+		default:
+			return fmt.Sprintf("invalid character %q in label %q", r, label)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,95 @@
+package certificate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDomains(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		domains  []string
+		expected []string
+	}{
+		{
+			desc:     "simple",
+			domains:  []string{"example.com", "www.example.com"},
+			expected: []string{"example.com", "www.example.com"},
+		},
+		{
+			desc:     "normalized",
+			domains:  []string{" Example.COM. ", "*.Example.com"},
+			expected: []string{"example.com", "*.example.com"},
+		},
+		{
+			desc:     "deduplicated",
+			domains:  []string{"example.com", "www.example.com", "EXAMPLE.com.", "www.example.com"},
+			expected: []string{"example.com", "www.example.com"},
+		},
+		{
+			desc:     "internationalized",
+			domains:  []string{"Bücher.example", "*.bücher.example", "xn--bcher-kva.example"},
+			expected: []string{"xn--bcher-kva.example", "*.xn--bcher-kva.example"},
+		},
+		{
+			desc:     "onion",
+			domains:  []string{"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion"},
+			expected: []string{"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			domains, err := NormalizeDomains(test.domains)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, domains)
+		})
+	}
+}
+
+func TestNormalizeDomains_errors(t *testing.T) {
+	_, err := NormalizeDomains([]string{
+		"example.com",
+		"_acme-challenge.example.com",
+		"*.*.example.com",
+		"www.*.example.com",
+		"*.com",
+		"-example.com",
+		"exa mple.com",
+		"example..com",
+		"",
+		strings.Repeat("a", 64) + ".example.com",
+	})
+	require.Error(t, err)
+
+	var domainsErr *DomainsError
+	require.True(t, errors.As(err, &domainsErr), "unexpected error type: %T", err)
+
+	expected := []DomainProblem{
+		{Domain: "_acme-challenge.example.com", Reason: `underscore in label "_acme-challenge"`},
+		{Domain: "*.*.example.com", Reason: "a wildcard is only allowed as the left-most label"},
+		{Domain: "www.*.example.com", Reason: "a wildcard is only allowed as the left-most label"},
+		{Domain: "*.com", Reason: "a wildcard must be followed by at least two labels"},
+		{Domain: "-example.com", Reason: `label "-example" starts or ends with a hyphen`},
+		{Domain: "exa mple.com", Reason: `invalid character ' ' in label "exa mple"`},
+		{Domain: "example..com", Reason: "empty label"},
+		{Domain: "", Reason: "empty domain"},
+		{Domain: strings.Repeat("a", 64) + ".example.com", Reason: `label "` + strings.Repeat("a", 64) + `" longer than 63 bytes`},
+	}
+	assert.Equal(t, expected, domainsErr.Problems)
+
+	assert.Contains(t, err.Error(), `invalid domains: "_acme-challenge.example.com": underscore in label "_acme-challenge"; "*.*.example.com"`)
+}
+
+func TestNormalizeDomains_empty(t *testing.T) {
+	_, err := NormalizeDomains(nil)
+	require.EqualError(t, err, `invalid domains: "": no domain`)
+}
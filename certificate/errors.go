@@ -2,8 +2,11 @@ package certificate
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"sort"
+
+	"github.com/go-acme/lego/v3/acme"
 )
 
 // obtainError is returned when there are specific errors available per domain.
@@ -24,6 +27,76 @@ func (e obtainError) Error() string {
 	return buffer.String()
 }
 
+// Errors returns the errors of the domains, sorted by domain.
+func (e obtainError) Errors() []error {
+	var domains []string
+	for domain := range e {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	var errs []error
+	for _, domain := range domains {
+		errs = append(errs, e[domain])
+	}
+	return errs
+}
+
+// Domains returns the domains which had a problem.
+func (e obtainError) Domains() []string {
+	var domains []string
+	for domain, err := range e {
+		if sub := subProblemDomains(err); len(sub) > 0 {
+			domains = append(domains, sub...)
+		} else {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// failedDomains returns the domains reported as failing by an error:
+// the domains of an error per domain, or the identifiers of the subproblems of an ACME error.
+func failedDomains(err error) []string {
+	if e, ok := err.(interface{ Domains() []string }); ok {
+		return e.Domains()
+	}
+
+	return subProblemDomains(err)
+}
+
+func subProblemDomains(err error) []string {
+	var problem *acme.ProblemDetails
+	if !errors.As(err, &problem) {
+		return nil
+	}
+
+	var domains []string
+	for _, sub := range problem.SubProblems {
+		if sub.Identifier.Value != "" {
+			domains = append(domains, sub.Identifier.Value)
+		}
+	}
+	return domains
+}
+
+// removeDomains splits the domains between the ones which are not in toRemove, and the removed ones.
+func removeDomains(domains []string, toRemove []string) (remaining []string, removed []string) {
+	excluded := make(map[string]bool)
+	for _, domain := range toRemove {
+		excluded[domain] = true
+	}
+
+	for _, domain := range domains {
+		if excluded[domain] {
+			removed = append(removed, domain)
+		} else {
+			remaining = append(remaining, domain)
+		}
+	}
+	return remaining, removed
+}
+
 type domainError struct {
 	Domain string
 	Error  error
@@ -0,0 +1,70 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_failedDomains(t *testing.T) {
+	problem := &acme.ProblemDetails{
+		Type: "urn:ietf:params:acme:error:rejectedIdentifier",
+		SubProblems: []acme.SubProblem{
+			{Type: "urn:ietf:params:acme:error:rejectedIdentifier", Identifier: acme.Identifier{Type: "dns", Value: "b.com"}},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		err      error
+		expected []string
+	}{
+		{
+			desc: "simple error",
+			err:  errors.New("oops"),
+		},
+		{
+			desc: "problem without subproblems",
+			err:  &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:malformed"},
+		},
+		{
+			desc:     "problem with subproblems",
+			err:      problem,
+			expected: []string{"b.com"},
+		},
+		{
+			desc:     "wrapped problem with subproblems",
+			err:      fmt.Errorf("order: %w", problem),
+			expected: []string{"b.com"},
+		},
+		{
+			desc:     "error per domain",
+			err:      obtainError{"a.com": errors.New("oops")},
+			expected: []string{"a.com"},
+		},
+		{
+			desc:     "error per domain with subproblems",
+			err:      obtainError{"a.com": problem, "b.com": problem},
+			expected: []string{"b.com", "b.com"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, failedDomains(test.err))
+		})
+	}
+}
+
+func Test_removeDomains(t *testing.T) {
+	remaining, removed := removeDomains([]string{"a.com", "b.com", "c.com"}, []string{"b.com", "d.com"})
+
+	assert.Equal(t, []string{"a.com", "c.com"}, remaining)
+	assert.Equal(t, []string{"b.com"}, removed)
+}
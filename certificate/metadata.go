@@ -0,0 +1,53 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+)
+
+// ParseMetadata populates the metadata fields of the resource (validity, serial number, SANs, issuer, OCSP and ARI URLs)
+// from its certificate.
+// renewalInfoURL is the renewalInfo URL of the directory of the CA, empty if the CA doesn't support ARI.
+func (r *Resource) ParseMetadata(renewalInfoURL string) error {
+	cert, err := certcrypto.ParsePEMCertificate(r.Certificate)
+	if err != nil {
+		return err
+	}
+
+	r.NotBefore = cert.NotBefore
+	r.NotAfter = cert.NotAfter
+	r.SerialNumber = fmt.Sprintf("%x", cert.SerialNumber)
+	r.SANs = cert.DNSNames
+	r.IssuerCommonName = cert.Issuer.CommonName
+	r.OCSPServers = cert.OCSPServer
+	r.RenewalInfoURL = ""
+
+	if renewalInfoURL != "" {
+		if certID, ok := RenewalInfoCertID(cert); ok {
+			r.RenewalInfoURL = strings.TrimSuffix(renewalInfoURL, "/") + "/" + certID
+		}
+	}
+
+	return nil
+}
+
+// RenewalInfoCertID returns the identifier of a certificate in the ARI requests (RFC 9773 §4.1):
+// the key identifier of its authority key identifier and its serial number, encoded in base64url and joined by a dot.
+// It returns false if the certificate has no authority key identifier.
+func RenewalInfoCertID(cert *x509.Certificate) (string, bool) {
+	if len(cert.AuthorityKeyId) == 0 || cert.SerialNumber == nil {
+		return "", false
+	}
+
+	// the DER encoding of the serial number: a leading zero keeps it positive.
+	serial := cert.SerialNumber.Bytes()
+	if len(serial) == 0 || serial[0]&0x80 != 0 {
+		serial = append([]byte{0}, serial...)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(cert.AuthorityKeyId) + "." + base64.RawURLEncoding.EncodeToString(serial), true
+}
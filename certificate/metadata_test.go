@@ -0,0 +1,50 @@
+package certificate
+
+import (
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResource_ParseMetadata(t *testing.T) {
+	certRes := &Resource{Certificate: []byte(certResponseMock)}
+
+	err := certRes.ParseMetadata("")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2018, time.November, 7, 17, 46, 56, 0, time.UTC), certRes.NotBefore.UTC())
+	assert.Equal(t, time.Date(2023, time.November, 7, 17, 46, 56, 0, time.UTC), certRes.NotAfter.UTC())
+	assert.Equal(t, "3e1724a96e5f3c", certRes.SerialNumber)
+	assert.Equal(t, []string{"acme.wtf"}, certRes.SANs)
+	assert.Equal(t, "Pebble Intermediate CA 395e61", certRes.IssuerCommonName)
+	assert.Empty(t, certRes.RenewalInfoURL)
+}
+
+func TestResource_ParseMetadata_invalid(t *testing.T) {
+	certRes := &Resource{Certificate: []byte("not a certificate")}
+
+	err := certRes.ParseMetadata("")
+	require.Error(t, err)
+}
+
+func TestRenewalInfoCertID(t *testing.T) {
+	// RFC 9773 §4.1 example.
+	cert := &x509.Certificate{
+		AuthorityKeyId: []byte{
+			0x69, 0x88, 0x5B, 0x6B, 0x87, 0x46, 0x40, 0x41, 0xE1, 0xB3,
+			0x7B, 0x84, 0x7B, 0xA0, 0xAE, 0x2C, 0xDE, 0x01, 0xC8, 0xD4,
+		},
+		SerialNumber: big.NewInt(0x87654321),
+	}
+
+	certID, ok := RenewalInfoCertID(cert)
+	require.True(t, ok)
+	assert.Equal(t, "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE", certID)
+
+	_, ok = RenewalInfoCertID(&x509.Certificate{SerialNumber: big.NewInt(1)})
+	assert.False(t, ok)
+}
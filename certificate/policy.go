@@ -0,0 +1,204 @@
+package certificate
+
+import (
+	"crypto"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+)
+
+// regexpPrefix the prefix of the patterns of a Policy which are regular expressions.
+const regexpPrefix = "regexp:"
+
+// Policy restricts the certificates which can be obtained, e.g. by a lego deployment shared by several teams.
+// It is enforced before any order is created.
+//
+// A pattern is either a glob (path.Match syntax, e.g. *.example.com matches www.example.com and *.example.com),
+// or a regular expression prefixed by "regexp:" (e.g. regexp:^[a-z]+\.example\.com$).
+// The patterns are matched against the normalized domains (see NormalizeDomains).
+type Policy struct {
+	// Allow the patterns of the allowed domains, all the domains are allowed if empty.
+	Allow []string
+	// Deny the patterns of the denied domains, even if they are allowed.
+	Deny []string
+	// MaxSANs the maximum number of domains in a certificate, no limit if 0.
+	MaxSANs int
+	// KeyTypes the allowed types of the keys of the certificates, all the types are allowed if empty.
+	KeyTypes []certcrypto.KeyType
+	// MinKeyTypes the minimum key type of each algorithm (e.g. RSA3072 and EC384): the weaker keys are denied.
+	// See UpgradeKeyType to replace the weaker keys of the existing certificates.
+	MinKeyTypes []certcrypto.KeyType
+}
+
+// PolicyError the violations of the policy by a request.
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return "policy violation: " + strings.Join(e.Violations, "; ")
+}
+
+// Validate checks the patterns of the policy.
+func (p *Policy) Validate() error {
+	_, err := p.compile()
+	return err
+}
+
+// Check checks the domains of a certificate, and the type of its key, against the policy.
+// All the violations are reported in a single PolicyError.
+func (p *Policy) Check(domains []string, keyType certcrypto.KeyType) error {
+	if p == nil {
+		return nil
+	}
+
+	matchers, err := p.compile()
+	if err != nil {
+		return err
+	}
+
+	var violations []string
+
+	for _, domain := range domains {
+		if pattern, ok := matchers.deny.match(domain); ok {
+			violations = append(violations, fmt.Sprintf("%s is denied (%s)", domain, pattern))
+			continue
+		}
+
+		if len(matchers.allow) > 0 {
+			if _, ok := matchers.allow.match(domain); !ok {
+				violations = append(violations, fmt.Sprintf("%s is not allowed", domain))
+			}
+		}
+	}
+
+	if p.MaxSANs > 0 && len(domains) > p.MaxSANs {
+		violations = append(violations, fmt.Sprintf("%d domains, the maximum is %d", len(domains), p.MaxSANs))
+	}
+
+	if len(p.KeyTypes) > 0 && !containsKeyType(p.KeyTypes, keyType) {
+		violations = append(violations, fmt.Sprintf("the key type %q is not allowed", keyType))
+	}
+
+	if minKeyType, ok := p.UpgradeKeyType(keyType); ok {
+		violations = append(violations, fmt.Sprintf("the key type %q is weaker than %q", keyType, minKeyType))
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// UpgradeKeyType returns the minimum key type of the policy for the algorithm of a weaker key type,
+// e.g. RSA3072 for RSA2048 if the policy requires at least RSA3072.
+// It returns false if the key type is not weaker than the policy.
+func (p *Policy) UpgradeKeyType(keyType certcrypto.KeyType) (certcrypto.KeyType, bool) {
+	if p == nil {
+		return "", false
+	}
+
+	for _, minKeyType := range p.MinKeyTypes {
+		if keyType.Weaker(minKeyType) {
+			return minKeyType, true
+		}
+	}
+
+	return "", false
+}
+
+// checkPolicy checks a request against the policy of the certifier.
+func (c *Certifier) checkPolicy(domains []string, privateKey crypto.PrivateKey) error {
+	keyType := c.options.KeyType
+	if privateKey != nil {
+		keyType = certcrypto.KeyTypeOf(privateKey)
+	}
+
+	return c.options.Policy.Check(domains, keyType)
+}
+
+type policyMatchers struct {
+	allow patterns
+	deny  patterns
+}
+
+func (p *Policy) compile() (*policyMatchers, error) {
+	allow, err := compilePatterns(p.Allow)
+	if err != nil {
+		return nil, err
+	}
+
+	deny, err := compilePatterns(p.Deny)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.MaxSANs < 0 {
+		return nil, fmt.Errorf("invalid maximum number of SANs: %d", p.MaxSANs)
+	}
+
+	return &policyMatchers{allow: allow, deny: deny}, nil
+}
+
+type pattern struct {
+	raw    string
+	regexp *regexp.Regexp
+}
+
+type patterns []pattern
+
+func compilePatterns(values []string) (patterns, error) {
+	var compiled patterns
+
+	for _, value := range values {
+		if strings.HasPrefix(value, regexpPrefix) {
+			exp, err := regexp.Compile(strings.TrimPrefix(value, regexpPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("invalid policy pattern %q: %w", value, err)
+			}
+
+			compiled = append(compiled, pattern{raw: value, regexp: exp})
+			continue
+		}
+
+		value = strings.ToLower(strings.TrimSuffix(value, "."))
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, fmt.Errorf("invalid policy pattern %q: %w", value, err)
+		}
+
+		compiled = append(compiled, pattern{raw: value})
+	}
+
+	return compiled, nil
+}
+
+// match returns the first pattern matching the domain.
+func (p patterns) match(domain string) (string, bool) {
+	for _, pat := range p {
+		if pat.regexp != nil {
+			if pat.regexp.MatchString(domain) {
+				return pat.raw, true
+			}
+			continue
+		}
+
+		if ok, _ := path.Match(pat.raw, domain); ok {
+			return pat.raw, true
+		}
+	}
+
+	return "", false
+}
+
+func containsKeyType(keyTypes []certcrypto.KeyType, keyType certcrypto.KeyType) bool {
+	for _, k := range keyTypes {
+		if k == keyType {
+			return true
+		}
+	}
+	return false
+}
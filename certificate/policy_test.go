@@ -0,0 +1,155 @@
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	policy := &Policy{
+		Allow:    []string{"*.example.com", "example.com", `regexp:^[a-z]+\.example\.org$`},
+		Deny:     []string{"admin.example.com", "regexp:^internal\\."},
+		MaxSANs:  3,
+		KeyTypes: []certcrypto.KeyType{certcrypto.EC256, certcrypto.RSA4096},
+	}
+
+	testCases := []struct {
+		desc     string
+		domains  []string
+		keyType  certcrypto.KeyType
+		expected []string
+	}{
+		{
+			desc:    "allowed",
+			domains: []string{"example.com", "www.example.com", "*.example.com"},
+			keyType: certcrypto.EC256,
+		},
+		{
+			desc:    "regexp",
+			domains: []string{"shop.example.org"},
+			keyType: certcrypto.RSA4096,
+		},
+		{
+			desc:     "not allowed",
+			domains:  []string{"example.net", "a.b.example.org"},
+			keyType:  certcrypto.EC256,
+			expected: []string{"example.net is not allowed", "a.b.example.org is not allowed"},
+		},
+		{
+			desc:     "denied",
+			domains:  []string{"admin.example.com", "internal.example.com"},
+			keyType:  certcrypto.EC256,
+			expected: []string{"admin.example.com is denied (admin.example.com)", `internal.example.com is denied (regexp:^internal\.)`},
+		},
+		{
+			desc:     "too many SANs and key type",
+			domains:  []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com"},
+			keyType:  certcrypto.RSA2048,
+			expected: []string{"4 domains, the maximum is 3", `the key type "2048" is not allowed`},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := policy.Check(test.domains, test.keyType)
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var policyErr *PolicyError
+			require.True(t, errors.As(err, &policyErr), "unexpected error type: %T", err)
+
+			assert.Equal(t, test.expected, policyErr.Violations)
+		})
+	}
+}
+
+func TestPolicy_Check_nil(t *testing.T) {
+	var policy *Policy
+	require.NoError(t, policy.Check([]string{"example.com"}, certcrypto.RSA2048))
+}
+
+func TestPolicy_Check_minKeyTypes(t *testing.T) {
+	policy := &Policy{MinKeyTypes: []certcrypto.KeyType{certcrypto.RSA3072, certcrypto.EC384}}
+
+	require.NoError(t, policy.Check([]string{"example.com"}, certcrypto.RSA4096))
+	require.NoError(t, policy.Check([]string{"example.com"}, certcrypto.EC384))
+
+	err := policy.Check([]string{"example.com"}, certcrypto.RSA2048)
+	require.EqualError(t, err, `policy violation: the key type "2048" is weaker than "3072"`)
+
+	err = policy.Check([]string{"example.com"}, certcrypto.EC256)
+	require.EqualError(t, err, `policy violation: the key type "P256" is weaker than "P384"`)
+}
+
+func TestPolicy_UpgradeKeyType(t *testing.T) {
+	policy := &Policy{MinKeyTypes: []certcrypto.KeyType{certcrypto.RSA3072}}
+
+	keyType, ok := policy.UpgradeKeyType(certcrypto.RSA2048)
+	assert.True(t, ok)
+	assert.Equal(t, certcrypto.RSA3072, keyType)
+
+	_, ok = policy.UpgradeKeyType(certcrypto.RSA4096)
+	assert.False(t, ok)
+
+	// no minimum for EC.
+	_, ok = policy.UpgradeKeyType(certcrypto.EC256)
+	assert.False(t, ok)
+
+	var nilPolicy *Policy
+	_, ok = nilPolicy.UpgradeKeyType(certcrypto.RSA2048)
+	assert.False(t, ok)
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	require.NoError(t, (&Policy{Allow: []string{"*.example.com"}, Deny: []string{"regexp:^a"}}).Validate())
+
+	err := (&Policy{Allow: []string{"[example.com"}}).Validate()
+	require.EqualError(t, err, `invalid policy pattern "[example.com": syntax error in pattern`)
+
+	err = (&Policy{Deny: []string{"regexp:("}}).Validate()
+	require.Error(t, err)
+
+	err = (&Policy{MaxSANs: -1}).Validate()
+	require.EqualError(t, err, "invalid maximum number of SANs: -1")
+}
+
+func TestCertifier_Obtain_policy(t *testing.T) {
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	var ordered bool
+	mux.HandleFunc("/newOrder", func(w http.ResponseWriter, _ *http.Request) {
+		ordered = true
+		http.Error(w, "unexpected order", http.StatusInternalServerError)
+	})
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	certifier := NewCertifier(core, &batchResolverMock{}, CertifierOptions{
+		KeyType: certcrypto.EC256,
+		Policy:  &Policy{Allow: []string{"*.example.com"}},
+	})
+
+	_, err = certifier.Obtain(ObtainRequest{Domains: []string{"www.example.com", "www.example.net"}})
+	require.EqualError(t, err, "policy violation: www.example.net is not allowed")
+
+	assert.False(t, ordered, "the order must not be created")
+}
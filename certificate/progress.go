@@ -0,0 +1,136 @@
+package certificate
+
+import (
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/challenge"
+)
+
+// ProgressStage is the step of an Obtain reported by a Progress.
+type ProgressStage string
+
+const (
+	// ProgressOrderCreated the order is created: Progress.Domains are its identifiers.
+	ProgressOrderCreated = ProgressStage("order-created")
+
+	// ProgressAuthorizations the authorizations of the order are fetched:
+	// Progress.Authorizations is the status of the authorization of each identifier
+	// ("valid" if it is reused, "pending" if its challenge must be solved).
+	ProgressAuthorizations = ProgressStage("authorizations")
+
+	// ProgressChallenge a lifecycle event of the challenge of an identifier: Progress.Event.
+	ProgressChallenge = ProgressStage("challenge")
+
+	// ProgressFinalizing the validations succeeded, the certificate is requested.
+	ProgressFinalizing = ProgressStage("finalizing")
+
+	// ProgressRetrying the order has failed for some domains, and is retried without them (ObtainRequest.AllowPartial):
+	// Progress.Dropped are the dropped domains, Progress.Err is the failure.
+	ProgressRetrying = ProgressStage("retrying")
+
+	// ProgressCompleted the certificate is obtained.
+	ProgressCompleted = ProgressStage("completed")
+
+	// ProgressFailed the certificate cannot be obtained: Progress.Err is the failure.
+	ProgressFailed = ProgressStage("failed")
+)
+
+func (s ProgressStage) String() string {
+	return string(s)
+}
+
+// Progress is a step of an Obtain, reported to ObtainRequest.Progress.
+type Progress struct {
+	Stage ProgressStage
+
+	// Domains the domains of the order.
+	Domains []string
+	// Attempt the number of the order, greater than 1 when the order is retried without the failing domains.
+	Attempt int
+	// Elapsed the time since the start of the Obtain.
+	Elapsed time.Duration
+
+	// Authorizations the status of the authorization of each identifier (ProgressAuthorizations).
+	Authorizations map[string]string
+	// Event the lifecycle event of the challenge of an identifier (ProgressChallenge).
+	Event *challenge.Event
+	// Dropped the domains dropped from the order (ProgressRetrying).
+	Dropped []string
+
+	Err error
+}
+
+// ProgressFunc receives the progress of an Obtain.
+// It is called synchronously: a slow function delays the Obtain.
+type ProgressFunc func(progress Progress)
+
+// eventSource is implemented by the resolvers reporting the lifecycle events of the challenges (resolver.Prober).
+type eventSource interface {
+	Subscribe(handler challenge.EventHandler) func()
+}
+
+// progressReporter reports the progress of an Obtain, if a ProgressFunc is set.
+type progressReporter struct {
+	fn      ProgressFunc
+	start   time.Time
+	attempt int
+	domains []string
+}
+
+func newProgressReporter(fn ProgressFunc) *progressReporter {
+	return &progressReporter{fn: fn, start: clk.Now(), attempt: 1}
+}
+
+func (r *progressReporter) report(progress Progress) {
+	if r == nil || r.fn == nil {
+		return
+	}
+
+	progress.Domains = r.domains
+	progress.Attempt = r.attempt
+	progress.Elapsed = clk.Now().Sub(r.start)
+
+	r.fn(progress)
+}
+
+// subscribe reports the events of the challenges of the domains of the order, until the returned function is called.
+func (r *progressReporter) subscribe(res resolver) func() {
+	source, ok := res.(eventSource)
+	if r == nil || r.fn == nil || !ok {
+		return func() {}
+	}
+
+	domains := make(map[string]bool)
+	for _, domain := range r.domains {
+		domains[domain] = true
+	}
+
+	return source.Subscribe(func(event challenge.Event) {
+		// the events of the other orders solved at the same time.
+		if !domains[event.Domain] {
+			return
+		}
+
+		r.report(Progress{Stage: ProgressChallenge, Event: &event})
+	})
+}
+
+// done reports the end of the Obtain, and returns its error.
+func (r *progressReporter) done(err error) error {
+	if err != nil {
+		r.report(Progress{Stage: ProgressFailed, Err: err})
+	} else {
+		r.report(Progress{Stage: ProgressCompleted})
+	}
+
+	return err
+}
+
+func authorizationStatuses(authorizations []acme.Authorization) map[string]string {
+	statuses := make(map[string]string)
+	for _, authz := range authorizations {
+		statuses[challenge.GetTargetedDomain(authz)] = authz.Status
+	}
+	return statuses
+}
@@ -0,0 +1,101 @@
+package certificate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventResolverMock solves the authorizations by emitting the events of their challenges to the subscribers.
+type eventResolverMock struct {
+	handlers []challenge.EventHandler
+	events   []challenge.Event
+}
+
+func (r *eventResolverMock) Solve(_ []acme.Authorization) error {
+	for _, event := range r.events {
+		for _, handler := range r.handlers {
+			handler(event)
+		}
+	}
+	return nil
+}
+
+func (r *eventResolverMock) Subscribe(handler challenge.EventHandler) func() {
+	r.handlers = append(r.handlers, handler)
+	index := len(r.handlers) - 1
+
+	return func() { r.handlers[index] = func(challenge.Event) {} }
+}
+
+func Test_progressReporter_subscribe(t *testing.T) {
+	var stages []string
+
+	reporter := newProgressReporter(func(progress Progress) {
+		if progress.Event != nil {
+			stages = append(stages, progress.Stage.String()+" "+progress.Event.Domain+" "+string(progress.Event.Type))
+			return
+		}
+		stages = append(stages, progress.Stage.String())
+	})
+	reporter.domains = []string{"acme.wtf"}
+
+	res := &eventResolverMock{events: []challenge.Event{
+		{Type: challenge.EventPresented, Domain: "acme.wtf"},
+		// the challenge of another order.
+		{Type: challenge.EventPresented, Domain: "lego.wtf"},
+		{Type: challenge.EventCleaned, Domain: "acme.wtf"},
+	}}
+
+	unsubscribe := reporter.subscribe(res)
+	require.NoError(t, res.Solve(nil))
+	unsubscribe()
+
+	// the events after the unsubscription are not reported.
+	require.NoError(t, res.Solve(nil))
+
+	err := reporter.done(errors.New("oops"))
+	require.EqualError(t, err, "oops")
+
+	expected := []string{
+		"challenge acme.wtf presented",
+		"challenge acme.wtf cleaned",
+		"failed",
+	}
+	assert.Equal(t, expected, stages)
+}
+
+func Test_progressReporter_report(t *testing.T) {
+	var progresses []Progress
+
+	reporter := newProgressReporter(func(progress Progress) {
+		progresses = append(progresses, progress)
+	})
+	reporter.domains = []string{"acme.wtf", "lego.wtf"}
+	reporter.attempt = 2
+
+	reporter.report(Progress{Stage: ProgressAuthorizations, Authorizations: authorizationStatuses([]acme.Authorization{
+		{Status: acme.StatusValid, Identifier: acme.Identifier{Value: "acme.wtf"}},
+		{Status: acme.StatusPending, Identifier: acme.Identifier{Value: "lego.wtf"}, Wildcard: true},
+	})})
+
+	require.Len(t, progresses, 1)
+	assert.Equal(t, ProgressAuthorizations, progresses[0].Stage)
+	assert.Equal(t, []string{"acme.wtf", "lego.wtf"}, progresses[0].Domains)
+	assert.Equal(t, 2, progresses[0].Attempt)
+	assert.Equal(t, map[string]string{"acme.wtf": acme.StatusValid, "*.lego.wtf": acme.StatusPending}, progresses[0].Authorizations)
+}
+
+func Test_progressReporter_noFunc(t *testing.T) {
+	reporter := newProgressReporter(nil)
+
+	unsubscribe := reporter.subscribe(&eventResolverMock{})
+	unsubscribe()
+
+	reporter.report(Progress{Stage: ProgressFinalizing})
+	assert.NoError(t, reporter.done(nil))
+}
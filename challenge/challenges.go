@@ -20,6 +20,10 @@ const (
 
 	// TLSALPN01 is the "tls-alpn-01" ACME challenge https://tools.ietf.org/html/draft-ietf-acme-tls-alpn-07
 	TLSALPN01 = Type("tls-alpn-01")
+
+	// OnionCSR01 is the "onion-csr-01" ACME challenge https://tools.ietf.org/html/draft-ietf-acme-onion-00#section-3.2
+	// Note: the CSR is signed by the key of the onion service
+	OnionCSR01 = Type("onion-csr-01")
 )
 
 func (t Type) String() string {
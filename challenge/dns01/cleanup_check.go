@@ -0,0 +1,107 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/wait"
+	"github.com/miekg/dns"
+)
+
+// RecordGoneFunc checks that the TXT record has been removed.
+type RecordGoneFunc func(fqdn, value string) (bool, error)
+
+// VerifyCleanUp checks that the TXT record is removed from the authoritative nameservers after the cleanup.
+// If the record lingers, the cleanup is retried up to retries times, then a warning is logged:
+// a record left behind is not an error for the challenge, but it accumulates across renewals.
+func VerifyCleanUp(retries int) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if retries < 0 {
+			return errors.New("the number of cleanup retries must not be negative")
+		}
+
+		chlg.cleanUpCheck = &cleanUpCheck{retries: retries}
+		return nil
+	}
+}
+
+type cleanUpCheck struct {
+	retries int
+	// checkFunc checks the removal of the record, checkRecordRemoved if nil.
+	checkFunc RecordGoneFunc
+}
+
+// verify waits for the removal of the record, and retries the cleanup (cleanUp) if the record lingers.
+func (c *cleanUpCheck) verify(config ChallengeConfig, provider challenge.Provider, domain, keyAuth string, cleanUp func() error) {
+	fqdn, value := GetRecord(domain, keyAuth)
+
+	check := c.checkFunc
+	if check == nil {
+		check = func(fqdn, value string) (bool, error) {
+			return checkRecordRemoved(config, fqdn, value)
+		}
+	}
+
+	timeout, interval := DefaultPropagationTimeout, DefaultPollingInterval
+	if p, ok := provider.(challenge.ProviderTimeout); ok {
+		timeout, interval = p.Timeout()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := wait.ForWithClock(clk, "cleanup", timeout, interval, func() (bool, error) {
+			return check(fqdn, value)
+		})
+		if err == nil {
+			return
+		}
+
+		if attempt >= c.retries {
+			log.Warnf("[%s] acme: the TXT record %s is still present after the cleanup: %v", domain, fqdn, err)
+			return
+		}
+
+		log.Infof("[%s] acme: the TXT record %s is still present, retrying the cleanup", domain, fqdn)
+
+		err = cleanUp()
+		if err != nil {
+			log.Warnf("[%s] acme: the cleanup retry failed: %v", domain, err)
+			return
+		}
+	}
+}
+
+// checkRecordRemoved checks that none of the authoritative nameservers returns the TXT record anymore.
+func checkRecordRemoved(config ChallengeConfig, fqdn, value string) (bool, error) {
+	r, err := config.dnsQuery(fqdn, dns.TypeTXT, config.RecursiveNameservers, true)
+	if err != nil {
+		return false, err
+	}
+
+	if r.Rcode == dns.RcodeSuccess {
+		fqdn = updateDomainWithCName(r, fqdn)
+	}
+
+	authoritativeNss, err := config.lookupNameservers(fqdn)
+	if err != nil {
+		return false, err
+	}
+
+	for _, ns := range authoritativeNss {
+		r, err := config.dnsQuery(fqdn, dns.TypeTXT, []string{net.JoinHostPort(ns, "53")}, false)
+		if err != nil {
+			return false, err
+		}
+
+		for _, rr := range r.Answer {
+			if txt, ok := rr.(*dns.TXT); ok && strings.Join(txt.Txt, "") == value {
+				return false, fmt.Errorf("NS %s still returns the TXT record [fqdn: %s, value: %s]", ns, fqdn, value)
+			}
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,89 @@
+package dns01
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type providerCleanUpCounter struct {
+	cleanUps int
+}
+
+func (p *providerCleanUpCounter) Present(domain, token, keyAuth string) error { return nil }
+func (p *providerCleanUpCounter) CleanUp(domain, token, keyAuth string) error {
+	p.cleanUps++
+	return nil
+}
+func (p *providerCleanUpCounter) Timeout() (time.Duration, time.Duration) {
+	return 50 * time.Millisecond, 10 * time.Millisecond
+}
+
+func TestCleanUpCheck_verify(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		retries          int
+		goneAfterCleanUp int
+		expectedCleanUps int
+	}{
+		{
+			desc:             "record removed",
+			retries:          2,
+			goneAfterCleanUp: 0,
+			expectedCleanUps: 0,
+		},
+		{
+			desc:             "record removed by the retry",
+			retries:          2,
+			goneAfterCleanUp: 1,
+			expectedCleanUps: 1,
+		},
+		{
+			desc:             "record lingers",
+			retries:          2,
+			goneAfterCleanUp: 10,
+			expectedCleanUps: 2,
+		},
+		{
+			desc:             "record lingers without retry",
+			goneAfterCleanUp: 10,
+			expectedCleanUps: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			provider := &providerCleanUpCounter{}
+
+			check := &cleanUpCheck{
+				retries: test.retries,
+				checkFunc: func(_, _ string) (bool, error) {
+					if provider.cleanUps < test.goneAfterCleanUp {
+						return false, errors.New("still there")
+					}
+					return true, nil
+				},
+			}
+
+			check.verify(DefaultChallengeConfig(), provider, "example.com", "keyAuth", func() error {
+				return provider.CleanUp("example.com", "token", "keyAuth")
+			})
+
+			assert.Equal(t, test.expectedCleanUps, provider.cleanUps)
+		})
+	}
+}
+
+func TestVerifyCleanUp(t *testing.T) {
+	chlg := &Challenge{}
+
+	err := VerifyCleanUp(-1)(chlg)
+	assert.EqualError(t, err, "the number of cleanup retries must not be negative")
+	assert.Nil(t, chlg.cleanUpCheck)
+
+	err = VerifyCleanUp(3)(chlg)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, chlg.cleanUpCheck.retries)
+}
@@ -0,0 +1,104 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// ChallengeConfig the DNS configuration of a Challenge: the recursive nameservers, and the parameters of the DNS queries.
+// Each Challenge carries its own copy, so the challenges of concurrent clients can use different configurations.
+type ChallengeConfig struct {
+	// RecursiveNameservers the nameservers used to check the propagation of the records (host:port).
+	RecursiveNameservers []string
+	// Timeout the timeout of a DNS query.
+	Timeout time.Duration
+	// Retries the number of times a query is retried on a nameserver after a network error (e.g. a lost packet).
+	Retries int
+	// TCPMode the use of TCP by the queries.
+	TCPMode TCPMode
+	// EDNSBufferSize the UDP buffer size advertised with EDNS0, 0 disables EDNS0.
+	EDNSBufferSize uint16
+}
+
+// NewChallengeConfig returns the default DNS configuration:
+// the nameservers of /etc/resolv.conf (or the Google public DNS), a 10 seconds timeout, no retry,
+// TCP on truncated responses, and a 4096 bytes EDNS0 buffer.
+func NewChallengeConfig() ChallengeConfig {
+	return ChallengeConfig{
+		RecursiveNameservers: getNameservers(defaultResolvConf, defaultNameservers),
+		Timeout:              10 * time.Second,
+		TCPMode:              TCPOnTruncation,
+		EDNSBufferSize:       4096,
+	}
+}
+
+// Validate checks the values of the configuration.
+func (c ChallengeConfig) Validate() error {
+	if len(c.RecursiveNameservers) == 0 {
+		return errors.New("no recursive nameserver")
+	}
+
+	if c.Retries < 0 {
+		return fmt.Errorf("invalid number of DNS retries: %d", c.Retries)
+	}
+
+	if _, err := ParseTCPMode(string(c.TCPMode)); err != nil {
+		return err
+	}
+
+	if c.EDNSBufferSize != 0 && c.EDNSBufferSize < dns.MinMsgSize {
+		return fmt.Errorf("invalid EDNS buffer size %d: must be at least %d", c.EDNSBufferSize, dns.MinMsgSize)
+	}
+
+	return nil
+}
+
+func (c ChallengeConfig) clone() ChallengeConfig {
+	c.RecursiveNameservers = append([]string(nil), c.RecursiveNameservers...)
+	return c
+}
+
+var (
+	defaultConfig   = NewChallengeConfig()
+	muDefaultConfig sync.RWMutex
+)
+
+// DefaultChallengeConfig returns the default DNS configuration:
+// the initial configuration of the new challenges,
+// and the configuration of the package-level functions (FindZoneByFqdn, GetRecord, Trace, ...) used by the DNS providers.
+func DefaultChallengeConfig() ChallengeConfig {
+	muDefaultConfig.RLock()
+	defer muDefaultConfig.RUnlock()
+
+	return defaultConfig.clone()
+}
+
+// SetDefaultChallengeConfig replaces the default DNS configuration (see DefaultChallengeConfig).
+// The existing challenges keep their configuration.
+func SetDefaultChallengeConfig(config ChallengeConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	muDefaultConfig.Lock()
+	defaultConfig = config.clone()
+	muDefaultConfig.Unlock()
+
+	return nil
+}
+
+// WithConfig sets the DNS configuration of the challenge.
+func WithConfig(config ChallengeConfig) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+
+		chlg.config = config.clone()
+		return nil
+	}
+}
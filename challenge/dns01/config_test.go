@@ -0,0 +1,137 @@
+package dns01
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		update   func(config *ChallengeConfig)
+		expected string
+	}{
+		{
+			desc:   "default",
+			update: func(_ *ChallengeConfig) {},
+		},
+		{
+			desc:     "no nameserver",
+			update:   func(config *ChallengeConfig) { config.RecursiveNameservers = nil },
+			expected: "no recursive nameserver",
+		},
+		{
+			desc:     "negative retries",
+			update:   func(config *ChallengeConfig) { config.Retries = -1 },
+			expected: "invalid number of DNS retries: -1",
+		},
+		{
+			desc:     "unknown TCP mode",
+			update:   func(config *ChallengeConfig) { config.TCPMode = "sometimes" },
+			expected: `unknown TCP mode "sometimes": truncated, error, always, or never`,
+		},
+		{
+			desc:     "EDNS buffer too small",
+			update:   func(config *ChallengeConfig) { config.EDNSBufferSize = 100 },
+			expected: "invalid EDNS buffer size 100: must be at least 512",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewChallengeConfig()
+			test.update(&config)
+
+			err := config.Validate()
+			if test.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewChallenge_config(t *testing.T) {
+	defaults := DefaultChallengeConfig()
+
+	chlg := NewChallenge(nil, nil, nil,
+		AddRecursiveNameservers([]string{"10.0.0.1"}),
+		AddDNSTimeout(2*time.Second),
+		AddDNSRetries(3),
+		SetDNSTCPMode(TCPAlways),
+		SetEDNSBufferSize(1232))
+
+	expected := ChallengeConfig{
+		RecursiveNameservers: []string{"10.0.0.1:53"},
+		Timeout:              2 * time.Second,
+		Retries:              3,
+		TCPMode:              TCPAlways,
+		EDNSBufferSize:       1232,
+	}
+	assert.Equal(t, expected, chlg.config)
+
+	// the options only configure the challenge.
+	assert.Equal(t, defaults, DefaultChallengeConfig())
+	assert.Equal(t, defaults, NewChallenge(nil, nil, nil).config)
+}
+
+func TestWithConfig(t *testing.T) {
+	config := NewChallengeConfig()
+	config.RecursiveNameservers = []string{"10.0.0.1:53"}
+
+	chlg := NewChallenge(nil, nil, nil, WithConfig(config))
+	assert.Equal(t, config, chlg.config)
+
+	// the challenge keeps its copy.
+	config.RecursiveNameservers[0] = "10.0.0.2:53"
+	assert.Equal(t, []string{"10.0.0.1:53"}, chlg.config.RecursiveNameservers)
+
+	invalid := NewChallengeConfig()
+	invalid.Retries = -1
+
+	chlg = NewChallenge(nil, nil, nil, WithConfig(invalid))
+	assert.Equal(t, DefaultChallengeConfig(), chlg.config)
+}
+
+func TestSetDefaultChallengeConfig(t *testing.T) {
+	defaults := DefaultChallengeConfig()
+	defer func() { require.NoError(t, SetDefaultChallengeConfig(defaults)) }()
+
+	config := NewChallengeConfig()
+	config.RecursiveNameservers = []string{"10.0.0.1:53"}
+
+	existing := NewChallenge(nil, nil, nil)
+
+	err := SetDefaultChallengeConfig(config)
+	require.NoError(t, err)
+
+	assert.Equal(t, config, DefaultChallengeConfig())
+	assert.Equal(t, config, NewChallenge(nil, nil, nil).config)
+	assert.Equal(t, defaults, existing.config)
+
+	config.Retries = -1
+	err = SetDefaultChallengeConfig(config)
+	require.Error(t, err)
+}
+
+func TestNewChallenge_concurrentConfigs(t *testing.T) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(retries int) {
+			defer wg.Done()
+
+			chlg := NewChallenge(nil, nil, nil, AddDNSRetries(retries))
+			assert.Equal(t, retries, chlg.config.Retries)
+		}(i)
+	}
+
+	wg.Wait()
+}
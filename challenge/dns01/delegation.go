@@ -0,0 +1,246 @@
+package dns01
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/miekg/dns"
+)
+
+// DelegationReport is the result of the sanity checks of the delegation of a zone.
+type DelegationReport struct {
+	Zone string
+
+	// ParentNameservers are the nameservers of the zone according to the parent zone (the delegation).
+	ParentNameservers []string
+	// ChildNameservers are the nameservers of the zone according to the zone itself.
+	ChildNameservers []string
+
+	// Lame are the nameservers which don't answer authoritatively for the zone.
+	Lame []string
+
+	Problems []string
+}
+
+// OK returns true if no problem has been found.
+func (r *DelegationReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *DelegationReport) String() string {
+	var b strings.Builder
+
+	_, _ = fmt.Fprintf(&b, "delegation of %s\n", r.Zone)
+	_, _ = fmt.Fprintf(&b, "  parent NS: %s\n", formatNameservers(r.ParentNameservers))
+	_, _ = fmt.Fprintf(&b, "  child NS:  %s\n", formatNameservers(r.ChildNameservers))
+
+	if r.OK() {
+		b.WriteString("  no problem found")
+		return b.String()
+	}
+
+	for i, problem := range r.Problems {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		_, _ = fmt.Fprintf(&b, "  problem: %s", problem)
+	}
+
+	return b.String()
+}
+
+func formatNameservers(nameservers []string) string {
+	if len(nameservers) == 0 {
+		return "(none)"
+	}
+	return strings.Join(nameservers, ", ")
+}
+
+// CheckDelegation checks the delegation of the zone of the TXT record before it's created:
+// the NS records of the parent zone and of the zone itself must match,
+// and every nameserver must answer authoritatively the SOA of the zone.
+// The problems are reported in the logs, they don't stop the challenge:
+// a broken delegation usually shows up later as a propagation timeout.
+func CheckDelegation() ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.delegationCheck = func(fqdn string) (*DelegationReport, error) {
+			return chlg.config.inspectDelegation(fqdn)
+		}
+		return nil
+	}
+}
+
+// InspectDelegation builds the DelegationReport of the zone of the given fqdn.
+func InspectDelegation(fqdn string) (*DelegationReport, error) {
+	return DefaultChallengeConfig().inspectDelegation(fqdn)
+}
+
+func (c ChallengeConfig) inspectDelegation(fqdn string) (*DelegationReport, error) {
+	zone, err := c.findZoneByFqdn(fqdn)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the zone: %w", err)
+	}
+
+	report := &DelegationReport{Zone: zone}
+
+	report.ChildNameservers, err = c.queryNameservers(zone, c.RecursiveNameservers)
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("the zone doesn't list its nameservers: %v", err))
+	}
+
+	report.ParentNameservers, err = c.lookupDelegation(zone)
+	if err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("the delegation can't be read from the parent zone: %v", err))
+	}
+
+	report.Problems = append(report.Problems, compareNameservers(report.ParentNameservers, report.ChildNameservers)...)
+
+	for _, ns := range mergeNameservers(report.ParentNameservers, report.ChildNameservers) {
+		err = c.checkAuthoritative(zone, ns)
+		if err != nil {
+			report.Lame = append(report.Lame, ns)
+			report.Problems = append(report.Problems, fmt.Sprintf("lame delegation: %s: %v", ns, err))
+		}
+	}
+
+	return report, nil
+}
+
+// queryNameservers returns the NS records of the zone found in the answer section.
+func (c ChallengeConfig) queryNameservers(zone string, nameservers []string) ([]string, error) {
+	r, err := c.dnsQuery(zone, dns.TypeNS, nameservers, true)
+	if err != nil {
+		return nil, err
+	}
+
+	names := extractNameservers(r.Answer)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no NS records%s", formatDNSError(r, nil))
+	}
+
+	return names, nil
+}
+
+// lookupDelegation asks the nameservers of the parent zone for the NS records of the zone.
+// The parent answers with a referral: the NS records are in the authority section.
+func (c ChallengeConfig) lookupDelegation(zone string) ([]string, error) {
+	labels := dns.SplitDomainName(zone)
+	if len(labels) < 2 {
+		return nil, errors.New("top-level domains are not checked")
+	}
+
+	parentZone, err := c.findZoneByFqdn(dns.Fqdn(strings.Join(labels[1:], ".")))
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the parent zone: %w", err)
+	}
+
+	parentNameservers, err := c.queryNameservers(parentZone, c.RecursiveNameservers)
+	if err != nil {
+		return nil, fmt.Errorf("parent zone %s: %w", parentZone, err)
+	}
+
+	m := c.createDNSMsg(zone, dns.TypeNS, false)
+
+	for _, ns := range parentNameservers {
+		r, err := c.sendDNSQuery(m, nameserverAddress(ns))
+		if err != nil || r.Rcode != dns.RcodeSuccess {
+			log.Infof("delegation check: parent nameserver %s failed%s", ns, formatDNSError(r, err))
+			continue
+		}
+
+		names := extractNameservers(append(r.Ns, r.Answer...))
+		if len(names) > 0 {
+			return names, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no delegation found in the parent zone %s", parentZone)
+}
+
+// checkAuthoritative checks that the nameserver answers authoritatively the SOA of the zone.
+func (c ChallengeConfig) checkAuthoritative(zone, ns string) error {
+	r, err := c.sendDNSQuery(c.createDNSMsg(zone, dns.TypeSOA, false), nameserverAddress(ns))
+	if err != nil {
+		return err
+	}
+
+	if r.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("unexpected response%s", formatDNSError(r, nil))
+	}
+
+	if !r.Authoritative {
+		return errors.New("not authoritative for the zone")
+	}
+
+	for _, rr := range r.Answer {
+		if soa, ok := rr.(*dns.SOA); ok && strings.EqualFold(soa.Hdr.Name, zone) {
+			return nil
+		}
+	}
+
+	return errors.New("no SOA record in the answer")
+}
+
+// compareNameservers reports the nameservers listed on only one side of the delegation.
+func compareNameservers(parent, child []string) []string {
+	if len(parent) == 0 || len(child) == 0 {
+		return nil
+	}
+
+	var problems []string
+
+	for _, ns := range parent {
+		if !containsNameserver(child, ns) {
+			problems = append(problems, fmt.Sprintf("%s is delegated by the parent zone but not listed by the zone", ns))
+		}
+	}
+
+	for _, ns := range child {
+		if !containsNameserver(parent, ns) {
+			problems = append(problems, fmt.Sprintf("%s is listed by the zone but not delegated by the parent zone", ns))
+		}
+	}
+
+	return problems
+}
+
+func extractNameservers(rrs []dns.RR) []string {
+	var names []string
+	for _, rr := range rrs {
+		if ns, ok := rr.(*dns.NS); ok && !containsNameserver(names, ns.Ns) {
+			names = append(names, strings.ToLower(ns.Ns))
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func mergeNameservers(a, b []string) []string {
+	merged := append([]string{}, a...)
+	for _, ns := range b {
+		if !containsNameserver(merged, ns) {
+			merged = append(merged, ns)
+		}
+	}
+
+	sort.Strings(merged)
+	return merged
+}
+
+func containsNameserver(nameservers []string, ns string) bool {
+	for _, n := range nameservers {
+		if strings.EqualFold(n, ns) {
+			return true
+		}
+	}
+	return false
+}
+
+func nameserverAddress(ns string) string {
+	return net.JoinHostPort(strings.TrimSuffix(ns, "."), "53")
+}
@@ -0,0 +1,100 @@
+package dns01
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_compareNameservers(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		parent   []string
+		child    []string
+		expected []string
+	}{
+		{
+			desc:   "match",
+			parent: []string{"ns1.example.net.", "ns2.example.net."},
+			child:  []string{"NS2.example.net.", "ns1.example.net."},
+		},
+		{
+			desc:   "mismatch",
+			parent: []string{"ns1.example.net.", "ns2.example.net."},
+			child:  []string{"ns1.example.net.", "ns3.example.net."},
+			expected: []string{
+				"ns2.example.net. is delegated by the parent zone but not listed by the zone",
+				"ns3.example.net. is listed by the zone but not delegated by the parent zone",
+			},
+		},
+		{
+			desc:   "unknown parent",
+			parent: nil,
+			child:  []string{"ns1.example.net."},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, compareNameservers(test.parent, test.child))
+		})
+	}
+}
+
+func TestDelegationReport_String(t *testing.T) {
+	report := &DelegationReport{
+		Zone:              "example.com.",
+		ParentNameservers: []string{"ns1.example.net.", "ns2.example.net."},
+		ChildNameservers:  []string{"ns1.example.net."},
+		Problems:          []string{"ns2.example.net. is delegated by the parent zone but not listed by the zone"},
+	}
+
+	expected := `delegation of example.com.
+  parent NS: ns1.example.net., ns2.example.net.
+  child NS:  ns1.example.net.
+  problem: ns2.example.net. is delegated by the parent zone but not listed by the zone`
+
+	assert.False(t, report.OK())
+	assert.Equal(t, expected, report.String())
+}
+
+func TestChallenge_PreSolve_delegationCheck(t *testing.T) {
+	_, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	var checked string
+	check := func(chlg *Challenge) error {
+		chlg.delegationCheck = func(fqdn string) (*DelegationReport, error) {
+			checked = fqdn
+			return &DelegationReport{Zone: "example.com.", Problems: []string{"lame delegation"}}, nil
+		}
+		return nil
+	}
+
+	chlg := NewChallenge(core, nil, &providerMock{}, check)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String()}},
+	}
+
+	// a broken delegation is only reported.
+	err = chlg.PreSolve(authz)
+	require.NoError(t, err)
+
+	assert.Equal(t, "_acme-challenge.example.com.", checked)
+}
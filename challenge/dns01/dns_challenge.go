@@ -1,6 +1,7 @@
 package dns01
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"github.com/go-acme/lego/v3/acme/api"
 	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/clock"
 	"github.com/go-acme/lego/v3/platform/wait"
 	"github.com/miekg/dns"
 )
@@ -27,6 +29,9 @@ const (
 	DefaultTTL = 120
 )
 
+// clk is used to wait for the propagation and to expire the SOA cache entries.
+var clk = clock.Real
+
 type ValidateFunc func(core *api.Core, domain string, chlng acme.Challenge) error
 
 type ChallengeOption func(*Challenge) error
@@ -44,20 +49,40 @@ func CondOption(condition bool, opt ChallengeOption) ChallengeOption {
 
 // Challenge implements the dns-01 challenge
 type Challenge struct {
-	core       *api.Core
-	validate   ValidateFunc
-	provider   challenge.Provider
-	preCheck   preCheck
-	dnsTimeout time.Duration
+	core     *api.Core
+	validate ValidateFunc
+	provider challenge.Provider
+	preCheck preCheck
+
+	// config the DNS configuration of the challenge (nameservers and queries).
+	config ChallengeConfig
+
+	// cleanUpCheck checks the removal of the record after the cleanup, if set.
+	cleanUpCheck *cleanUpCheck
+
+	// delegationCheck inspects the delegation of the zone before the record is created, if set.
+	delegationCheck func(fqdn string) (*DelegationReport, error)
+
+	// propagationTuning sizes the propagation timeout from the observed durations, if set.
+	propagationTuning *propagationTuning
+
+	// onPropagation is called once the propagation of the record is confirmed, if set.
+	onPropagation func(domain string)
+
+	// continueOnTimeout requests the validation even if the propagation pre-check fails.
+	continueOnTimeout bool
+
+	// states the states returned by the provider (challenge.ProviderState) for the presented records, by token.
+	states challenge.TokenStates
 }
 
 func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Provider, opts ...ChallengeOption) *Challenge {
 	chlg := &Challenge{
-		core:       core,
-		validate:   validate,
-		provider:   provider,
-		preCheck:   newPreCheck(),
-		dnsTimeout: 10 * time.Second,
+		core:     core,
+		validate: validate,
+		provider: provider,
+		preCheck: newPreCheck(),
+		config:   DefaultChallengeConfig(),
 	}
 
 	for _, opt := range opts {
@@ -70,6 +95,26 @@ func NewChallenge(core *api.Core, validate ValidateFunc, provider challenge.Prov
 	return chlg
 }
 
+// OnPropagation calls fn with the targeted domain once the propagation of the TXT record is confirmed,
+// before the validation of the challenge.
+func OnPropagation(fn func(domain string)) ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.onPropagation = fn
+		return nil
+	}
+}
+
+// ContinueOnPropagationTimeout requests the validation of the challenge even if the propagation pre-check
+// fails or times out: the resolvers of the CA may see the record before the resolvers used by lego.
+// If the record is not visible to the CA, the authorization becomes invalid.
+// The propagation reported by a provider (ProviderPropagation) is still required.
+func ContinueOnPropagationTimeout() ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.continueOnTimeout = true
+		return nil
+	}
+}
+
 // PreSolve just submits the txt record to the dns provider.
 // It does not validate record propagation, or do anything at all with the acme server.
 func (c *Challenge) PreSolve(authz acme.Authorization) error {
@@ -91,14 +136,39 @@ func (c *Challenge) PreSolve(authz acme.Authorization) error {
 		return err
 	}
 
-	err = c.provider.Present(authz.Identifier.Value, chlng.Token, keyAuth)
+	if c.delegationCheck != nil {
+		c.reportDelegation(domain, authz.Identifier.Value, keyAuth)
+	}
+
+	err = c.present(authz.Identifier.Value, chlng.Token, keyAuth)
 	if err != nil {
-		return fmt.Errorf("[%s] acme: error presenting token: %w", domain, err)
+		return fmt.Errorf("[%s] acme: error presenting token: %w", domain, &challenge.ProviderError{Err: err})
+	}
+
+	if c.propagationTuning != nil {
+		c.propagationTuning.presented(GetRecord(authz.Identifier.Value, keyAuth))
 	}
 
 	return nil
 }
 
+func (c *Challenge) reportDelegation(domain, identifier, keyAuth string) {
+	fqdn, _ := GetRecord(identifier, keyAuth)
+
+	report, err := c.delegationCheck(fqdn)
+	if err != nil {
+		log.Warnf("[%s] acme: could not check the delegation: %v", domain, err)
+		return
+	}
+
+	if report.OK() {
+		log.Infof("[%s] acme: %s", domain, report)
+		return
+	}
+
+	log.Warnf("[%s] acme: broken %s", domain, report)
+}
+
 func (c *Challenge) Solve(authz acme.Authorization) error {
 	domain := challenge.GetTargetedDomain(authz)
 	log.Infof("[%s] acme: Trying to solve DNS-01", domain)
@@ -124,21 +194,63 @@ func (c *Challenge) Solve(authz acme.Authorization) error {
 		timeout, interval = DefaultPropagationTimeout, DefaultPollingInterval
 	}
 
-	log.Infof("[%s] acme: Checking DNS record propagation using %+v", domain, recursiveNameservers)
+	if c.propagationTuning != nil {
+		timeout = c.propagationTuning.timeout(domain, timeout)
+	}
+
+	err = c.waitForPropagation(domain, fqdn, value, timeout, interval)
+
+	if c.propagationTuning != nil {
+		c.propagationTuning.propagated(domain, fqdn, value)
+	}
+
+	if err != nil {
+		if !c.continueAfterPreCheck(domain, err) {
+			return &challenge.ProviderError{Err: err}
+		}
+	} else if c.onPropagation != nil {
+		c.onPropagation(domain)
+	}
+
+	chlng.KeyAuthorization = keyAuth
+	return c.validate(c.core, domain, chlng)
+}
+
+func (c *Challenge) waitForPropagation(domain, fqdn, value string, timeout, interval time.Duration) error {
+	if provider, ok := c.provider.(challenge.ProviderPropagation); ok {
+		log.Infof("[%s] acme: Waiting for DNS record propagation reported by the provider [timeout: %s]", domain, timeout)
 
-	err = wait.For("propagation", timeout, interval, func() (bool, error) {
-		stop, errP := c.preCheck.call(domain, fqdn, value)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		return provider.WaitForPropagation(ctx, fqdn, value)
+	}
+
+	log.Infof("[%s] acme: Checking DNS record propagation using %+v", domain, c.config.RecursiveNameservers)
+
+	return wait.ForWithClock(clk, "propagation", timeout, interval, func() (bool, error) {
+		stop, errP := c.preCheck.call(c.config, domain, fqdn, value)
 		if !stop || errP != nil {
 			log.Infof("[%s] acme: Waiting for DNS record propagation.", domain)
 		}
 		return stop, errP
 	})
-	if err != nil {
-		return err
+}
+
+// continueAfterPreCheck reports whether the validation is requested despite the failure of the propagation pre-check.
+func (c *Challenge) continueAfterPreCheck(domain string, err error) bool {
+	if !c.continueOnTimeout {
+		return false
 	}
 
-	chlng.KeyAuthorization = keyAuth
-	return c.validate(c.core, domain, chlng)
+	if _, ok := c.provider.(challenge.ProviderPropagation); ok {
+		return false
+	}
+
+	log.Warnf("[%s] acme: the propagation of the DNS record is not confirmed (%v): "+
+		"requesting the validation anyway, it fails if the resolvers of the CA don't see the record either", domain, err)
+
+	return true
 }
 
 // CleanUp cleans the challenge.
@@ -155,11 +267,63 @@ func (c *Challenge) CleanUp(authz acme.Authorization) error {
 		return err
 	}
 
-	return c.provider.CleanUp(authz.Identifier.Value, chlng.Token, keyAuth)
+	defer c.states.Delete(chlng.Token)
+
+	cleanUp := func() error {
+		return c.cleanUp(authz.Identifier.Value, chlng.Token, keyAuth)
+	}
+
+	err = cleanUp()
+	if err != nil {
+		return err
+	}
+
+	if c.cleanUpCheck != nil {
+		c.cleanUpCheck.verify(c.config, c.provider, authz.Identifier.Value, keyAuth, cleanUp)
+	}
+
+	return nil
+}
+
+// present presents the record, and keeps the state returned by the provider if it implements challenge.ProviderState.
+func (c *Challenge) present(domain, token, keyAuth string) error {
+	return ObserveProviderCall(c.provider, OperationPresent, domain, func() error {
+		p, ok := c.provider.(challenge.ProviderState)
+		if !ok {
+			return c.provider.Present(domain, token, keyAuth)
+		}
+
+		state, err := p.PresentWithState(domain, token, keyAuth)
+		if err != nil {
+			return err
+		}
+
+		c.states.Store(token, state)
+		return nil
+	})
+}
+
+// cleanUp cleans the record up, with the state kept by present if the provider implements challenge.ProviderState.
+func (c *Challenge) cleanUp(domain, token, keyAuth string) error {
+	return ObserveProviderCall(c.provider, OperationCleanUp, domain, func() error {
+		p, ok := c.provider.(challenge.ProviderState)
+		if !ok {
+			return c.provider.CleanUp(domain, token, keyAuth)
+		}
+
+		state, ok := c.states.Load(token)
+		if !ok {
+			// the record was not presented by this challenge (e.g. PreSolve failed before the provider was called).
+			return c.provider.CleanUp(domain, token, keyAuth)
+		}
+
+		return p.CleanUpWithState(domain, token, keyAuth, state)
+	})
 }
 
 func (c *Challenge) Sequential() (bool, time.Duration) {
-	if p, ok := c.provider.(sequential); ok {
+	// the middlewares (see challenge.Wrap) keep the sequential solving of the provider they wrap.
+	if p, ok := challenge.Unwrap(c.provider).(sequential); ok {
 		return ok, p.Sequential()
 	}
 	return false, 0
@@ -177,7 +341,8 @@ func GetRecord(domain, keyAuth string) (fqdn string, value string) {
 	fqdn = fmt.Sprintf("_acme-challenge.%s.", domain)
 
 	if ok, _ := strconv.ParseBool(os.Getenv("LEGO_EXPERIMENTAL_CNAME_SUPPORT")); ok {
-		r, err := dnsQuery(fqdn, dns.TypeCNAME, recursiveNameservers, true)
+		config := DefaultChallengeConfig()
+		r, err := config.dnsQuery(fqdn, dns.TypeCNAME, config.RecursiveNameservers, true)
 		// Check if the domain has CNAME then return that
 		if err == nil && r.Rcode == dns.RcodeSuccess {
 			fqdn = updateDomainWithCName(r, fqdn)
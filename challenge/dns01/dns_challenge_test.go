@@ -1,6 +1,7 @@
 package dns01
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"errors"
@@ -12,6 +13,8 @@ import (
 	"github.com/go-acme/lego/v3/acme/api"
 	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/go-acme/lego/v3/providers/dns/fake"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,6 +34,38 @@ func (p *providerTimeoutMock) Present(domain, token, keyAuth string) error { ret
 func (p *providerTimeoutMock) CleanUp(domain, token, keyAuth string) error { return p.cleanUp }
 func (p *providerTimeoutMock) Timeout() (time.Duration, time.Duration)     { return p.timeout, p.interval }
 
+type providerPropagationMock struct {
+	present, cleanUp, propagation error
+}
+
+func (p *providerPropagationMock) Present(domain, token, keyAuth string) error { return p.present }
+func (p *providerPropagationMock) CleanUp(domain, token, keyAuth string) error { return p.cleanUp }
+func (p *providerPropagationMock) WaitForPropagation(_ context.Context, _, _ string) error {
+	return p.propagation
+}
+
+type providerStateMock struct {
+	cleaned []interface{}
+}
+
+func (p *providerStateMock) Present(domain, token, keyAuth string) error {
+	return errors.New("Present must not be called")
+}
+
+func (p *providerStateMock) CleanUp(domain, token, keyAuth string) error {
+	p.cleaned = append(p.cleaned, nil)
+	return nil
+}
+
+func (p *providerStateMock) PresentWithState(domain, token, keyAuth string) (interface{}, error) {
+	return "record-" + token, nil
+}
+
+func (p *providerStateMock) CleanUpWithState(domain, token, keyAuth string, state interface{}) error {
+	p.cleaned = append(p.cleaned, state)
+	return nil
+}
+
 func TestChallenge_PreSolve(t *testing.T) {
 	_, apiURL, tearDown := tester.SetupFakeAPI()
 	defer tearDown()
@@ -157,6 +192,21 @@ func TestChallenge_Solve(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			desc:     "propagation reported by the provider",
+			validate: func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			preCheck: func(_, _, _ string, _ PreCheckFunc) (bool, error) { return false, errors.New("OOPS") },
+			provider: &providerPropagationMock{},
+		},
+		{
+			desc:     "propagation reported by the provider fail",
+			validate: func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
+			preCheck: func(_, _, _ string, _ PreCheckFunc) (bool, error) { return true, nil },
+			provider: &providerPropagationMock{
+				propagation: errors.New("OOPS"),
+			},
+			expectError: true,
+		},
 		{
 			desc:     "present fail",
 			validate: func(_ *api.Core, _ string, _ acme.Challenge) error { return nil },
@@ -284,3 +334,156 @@ func TestChallenge_CleanUp(t *testing.T) {
 		})
 	}
 }
+
+func TestChallenge_CleanUp_providerState(t *testing.T) {
+	_, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	provider := &providerStateMock{}
+
+	chlg := NewChallenge(core, func(_ *api.Core, _ string, _ acme.Challenge) error { return nil }, provider,
+		WrapPreCheck(func(_, _, _ string, _ PreCheckFunc) (bool, error) { return true, nil }))
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: "abc"}},
+	}
+
+	require.NoError(t, chlg.PreSolve(authz))
+
+	state, ok := chlg.states.Load("abc")
+	require.True(t, ok)
+	assert.Equal(t, "record-abc", state)
+
+	require.NoError(t, chlg.CleanUp(authz))
+
+	assert.Equal(t, []interface{}{"record-abc"}, provider.cleaned)
+
+	_, ok = chlg.states.Load("abc")
+	assert.False(t, ok, "the state must be removed after the cleanup")
+
+	// without state (not presented by this challenge), the cleanup falls back to CleanUp.
+	require.NoError(t, chlg.CleanUp(authz))
+
+	assert.Equal(t, []interface{}{"record-abc", nil}, provider.cleaned)
+}
+
+func TestChallenge_Solve_onPropagation(t *testing.T) {
+	_, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	var propagated []string
+
+	chlg := NewChallenge(core, func(_ *api.Core, _ string, _ acme.Challenge) error { return nil }, &providerMock{},
+		WrapPreCheck(func(_, _, _ string, _ PreCheckFunc) (bool, error) { return true, nil }),
+		OnPropagation(func(domain string) { propagated = append(propagated, domain) }))
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Wildcard:   true,
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String()}},
+	}
+
+	require.NoError(t, chlg.Solve(authz))
+
+	require.Equal(t, []string{"*.example.com"}, propagated)
+}
+
+func TestChallenge_Solve_continueOnPropagationTimeout(t *testing.T) {
+	_, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String()}},
+	}
+
+	var validated bool
+	var propagated []string
+
+	validate := func(_ *api.Core, _ string, _ acme.Challenge) error {
+		validated = true
+		return nil
+	}
+
+	provider := &providerTimeoutMock{timeout: 100 * time.Millisecond, interval: 10 * time.Millisecond}
+
+	chlg := NewChallenge(core, validate, provider,
+		WrapPreCheck(func(_, _, _ string, _ PreCheckFunc) (bool, error) { return false, errors.New("OOPS") }),
+		OnPropagation(func(domain string) { propagated = append(propagated, domain) }),
+		ContinueOnPropagationTimeout())
+
+	require.NoError(t, chlg.Solve(authz))
+	require.True(t, validated)
+	require.Empty(t, propagated)
+
+	// the propagation reported by the provider is still required.
+	validated = false
+
+	chlg = NewChallenge(core, validate, &providerPropagationMock{propagation: errors.New("OOPS")}, ContinueOnPropagationTimeout())
+
+	require.Error(t, chlg.Solve(authz))
+	require.False(t, validated)
+}
+
+func TestChallenge_fakeProvider(t *testing.T) {
+	_, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	provider := fake.NewProvider(fake.WithPropagation(2), fake.WithTimeout(time.Second, 10*time.Millisecond))
+
+	var lookups int
+
+	chlg := NewChallenge(core, func(_ *api.Core, _ string, _ acme.Challenge) error { return nil }, provider,
+		WrapPreCheck(func(_, fqdn, value string, _ PreCheckFunc) (bool, error) {
+			lookups++
+			return provider.Lookup(fqdn, value)
+		}))
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String(), Token: "abc"}},
+	}
+
+	require.NoError(t, chlg.PreSolve(authz))
+
+	records := provider.Records()
+	require.Len(t, records, 1)
+
+	keyAuth, err := core.GetKeyAuthorization("abc")
+	require.NoError(t, err)
+
+	fqdn, value := GetRecord("example.com", keyAuth)
+	assert.Equal(t, fqdn, records[0].FQDN)
+	assert.Equal(t, value, records[0].Value)
+
+	require.NoError(t, chlg.Solve(authz))
+	assert.Equal(t, 3, lookups)
+
+	require.NoError(t, chlg.CleanUp(authz))
+	assert.Empty(t, provider.Records())
+}
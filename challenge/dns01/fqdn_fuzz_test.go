@@ -0,0 +1,56 @@
+//go:build go1.18
+// +build go1.18
+
+package dns01
+
+import (
+	"strings"
+	"testing"
+)
+
+// The domains come from the users, and from the authorizations sent by the CA.
+
+func FuzzFqdn(f *testing.F) {
+	f.Add("example.com")
+	f.Add("example.com.")
+	f.Add("*.example.com")
+	f.Add("_acme-challenge.example.com..")
+	f.Add(".")
+	f.Add("")
+	f.Add("xn--bcher-kva.example")
+
+	f.Fuzz(func(t *testing.T, name string) {
+		fqdn := ToFqdn(name)
+
+		if name != "" && !strings.HasSuffix(fqdn, ".") {
+			t.Fatalf("ToFqdn(%q) = %q: no trailing dot", name, fqdn)
+		}
+
+		if ToFqdn(fqdn) != fqdn {
+			t.Fatalf("ToFqdn is not idempotent for %q", name)
+		}
+
+		if UnFqdn(fqdn) != UnFqdn(name) {
+			t.Fatalf("UnFqdn(ToFqdn(%q)) = %q, expected %q", name, UnFqdn(fqdn), UnFqdn(name))
+		}
+	})
+}
+
+func FuzzGetRecord(f *testing.F) {
+	f.Add("example.com", "token.thumbprint")
+	f.Add("*.example.com", "")
+	f.Add("example.com.", "\x00")
+
+	f.Fuzz(func(t *testing.T, domain, keyAuth string) {
+		fqdn, value := GetRecord(domain, keyAuth)
+
+		if !strings.HasPrefix(fqdn, "_acme-challenge.") || !strings.HasSuffix(fqdn, ".") {
+			t.Fatalf("invalid record name %q for %q", fqdn, domain)
+		}
+
+		// base64url of a SHA-256 digest, without padding.
+		if len(value) != 43 || strings.ContainsAny(value, "+/=") {
+			t.Fatalf("invalid record value %q", value)
+		}
+	})
+}
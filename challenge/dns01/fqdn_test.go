@@ -2,8 +2,10 @@ package dns01
 
 import (
 	"testing"
+	"testing/quick"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestToFqdn(t *testing.T) {
@@ -64,3 +66,15 @@ func TestUnFqdn(t *testing.T) {
 		})
 	}
 }
+
+func TestToFqdn_properties(t *testing.T) {
+	idempotent := func(name string) bool {
+		return ToFqdn(ToFqdn(name)) == ToFqdn(name)
+	}
+	require.NoError(t, quick.Check(idempotent, nil))
+
+	reversible := func(name string) bool {
+		return UnFqdn(ToFqdn(name)) == UnFqdn(name)
+	}
+	require.NoError(t, quick.Check(reversible, nil))
+}
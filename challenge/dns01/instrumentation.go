@@ -0,0 +1,252 @@
+package dns01
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// The operations of the DNS providers reported to the observers.
+const (
+	OperationPresent = "present"
+	OperationCleanUp = "cleanup"
+)
+
+// ProviderCall a call of a DNS provider API (Present or CleanUp).
+type ProviderCall struct {
+	// Provider the name of the provider (e.g. cloudflare).
+	Provider  string
+	Operation string
+	Domain    string
+	Duration  time.Duration
+	Err       error
+}
+
+// ProviderObserver receives the calls of the DNS providers.
+type ProviderObserver interface {
+	ObserveProviderCall(call ProviderCall)
+}
+
+// ProviderObserverFunc a function used as a ProviderObserver.
+type ProviderObserverFunc func(call ProviderCall)
+
+// ObserveProviderCall calls f.
+func (f ProviderObserverFunc) ObserveProviderCall(call ProviderCall) {
+	f(call)
+}
+
+// ProviderWrapper is implemented by the providers wrapping other providers (e.g. a fallback provider),
+// and reporting the calls of the wrapped providers instead of their own calls.
+type ProviderWrapper interface {
+	challenge.Provider
+	WrapsProviders()
+}
+
+// DefaultProviderMetrics the metrics of all the calls of the DNS providers.
+var DefaultProviderMetrics = NewProviderMetrics()
+
+var providerObservers struct {
+	sync.RWMutex
+	list []ProviderObserver
+}
+
+// AddProviderObserver registers an observer of all the calls of the DNS providers, in addition to DefaultProviderMetrics.
+func AddProviderObserver(observer ProviderObserver) {
+	providerObservers.Lock()
+	providerObservers.list = append(providerObservers.list, observer)
+	providerObservers.Unlock()
+}
+
+// ObserveProviderCall runs a call of a DNS provider, and reports its duration and its result to the observers.
+// The calls of a ProviderWrapper are not reported: it reports the calls of the providers it wraps.
+// The middlewares (see challenge.Wrap) are reported as the provider they wrap.
+func ObserveProviderCall(provider challenge.Provider, operation, domain string, call func() error) error {
+	if _, ok := challenge.Unwrap(provider).(ProviderWrapper); ok {
+		return call()
+	}
+
+	start := time.Now()
+	err := call()
+
+	c := ProviderCall{
+		Provider:  ProviderName(provider),
+		Operation: operation,
+		Domain:    domain,
+		Duration:  time.Since(start),
+		Err:       err,
+	}
+
+	log.Debugf("[%s] DNS provider %s: %s in %s (error: %v)", domain, c.Provider, operation, c.Duration.Round(time.Millisecond), err)
+
+	DefaultProviderMetrics.ObserveProviderCall(c)
+
+	providerObservers.RLock()
+	defer providerObservers.RUnlock()
+
+	for _, observer := range providerObservers.list {
+		observer.ObserveProviderCall(c)
+	}
+
+	return err
+}
+
+// ProviderName returns the name of a DNS provider: the name of its package (e.g. cloudflare).
+func ProviderName(provider challenge.Provider) string {
+	provider = challenge.Unwrap(provider)
+
+	if named, ok := provider.(interface{ ProviderName() string }); ok {
+		return named.ProviderName()
+	}
+
+	t := reflect.TypeOf(provider)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+
+	return path.Base(t.PkgPath())
+}
+
+// ProviderCallStats the timing metrics of an operation of a DNS provider.
+type ProviderCallStats struct {
+	Provider  string
+	Operation string
+	Calls     int64
+	Errors    int64
+	// Total the cumulated duration of the calls.
+	Total time.Duration
+	// Max the duration of the slowest call.
+	Max time.Duration
+	// Last the duration of the last call.
+	Last time.Duration
+}
+
+type providerOperation struct {
+	provider  string
+	operation string
+}
+
+// ProviderMetrics the timing metrics of the calls of the DNS providers, by provider and operation.
+type ProviderMetrics struct {
+	mu    sync.Mutex
+	stats map[providerOperation]*ProviderCallStats
+}
+
+// NewProviderMetrics creates a ProviderMetrics.
+func NewProviderMetrics() *ProviderMetrics {
+	return &ProviderMetrics{stats: make(map[providerOperation]*ProviderCallStats)}
+}
+
+// ObserveProviderCall records a call.
+func (m *ProviderMetrics) ObserveProviderCall(call ProviderCall) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := providerOperation{provider: call.Provider, operation: call.Operation}
+
+	stats, ok := m.stats[key]
+	if !ok {
+		stats = &ProviderCallStats{Provider: call.Provider, Operation: call.Operation}
+		m.stats[key] = stats
+	}
+
+	stats.Calls++
+	if call.Err != nil {
+		stats.Errors++
+	}
+
+	stats.Total += call.Duration
+	stats.Last = call.Duration
+	if call.Duration > stats.Max {
+		stats.Max = call.Duration
+	}
+}
+
+// Stats returns the metrics, sorted by provider and operation.
+func (m *ProviderMetrics) Stats() []ProviderCallStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var stats []ProviderCallStats
+	for _, s := range m.stats {
+		stats = append(stats, *s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Provider != stats[j].Provider {
+			return stats[i].Provider < stats[j].Provider
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+
+	return stats
+}
+
+// WritePrometheus writes the metrics in the Prometheus text format (e.g. for the textfile collector of the node exporter).
+func (m *ProviderMetrics) WritePrometheus(w io.Writer) error {
+	stats := m.Stats()
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(s ProviderCallStats) string
+	}{
+		{
+			name:  "lego_dns_provider_calls_total",
+			kind:  "counter",
+			help:  "The number of calls of the DNS provider.",
+			value: func(s ProviderCallStats) string { return fmt.Sprint(s.Calls) },
+		},
+		{
+			name:  "lego_dns_provider_errors_total",
+			kind:  "counter",
+			help:  "The number of failed calls of the DNS provider.",
+			value: func(s ProviderCallStats) string { return fmt.Sprint(s.Errors) },
+		},
+		{
+			name:  "lego_dns_provider_duration_seconds_total",
+			kind:  "counter",
+			help:  "The cumulated duration of the calls of the DNS provider.",
+			value: func(s ProviderCallStats) string { return fmt.Sprint(s.Total.Seconds()) },
+		},
+		{
+			name:  "lego_dns_provider_duration_seconds_max",
+			kind:  "gauge",
+			help:  "The duration of the slowest call of the DNS provider.",
+			value: func(s ProviderCallStats) string { return fmt.Sprint(s.Max.Seconds()) },
+		},
+		{
+			name:  "lego_dns_provider_duration_seconds_last",
+			kind:  "gauge",
+			help:  "The duration of the last call of the DNS provider.",
+			value: func(s ProviderCallStats) string { return fmt.Sprint(s.Last.Seconds()) },
+		},
+	}
+
+	for _, metric := range metrics {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range stats {
+			_, err = fmt.Fprintf(w, "%s{provider=%q,operation=%q} %s\n", metric.name, s.Provider, s.Operation, metric.value(s))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package dns01
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type wrapperMock struct {
+	providerMock
+}
+
+func (w *wrapperMock) WrapsProviders() {}
+
+type namedProviderMock struct {
+	providerMock
+}
+
+func (n *namedProviderMock) ProviderName() string { return "named" }
+
+func TestProviderName(t *testing.T) {
+	assert.Equal(t, "dns01", ProviderName(&providerMock{}))
+	assert.Equal(t, "named", ProviderName(&namedProviderMock{}))
+}
+
+func TestObserveProviderCall(t *testing.T) {
+	var calls []ProviderCall
+	AddProviderObserver(ProviderObserverFunc(func(call ProviderCall) {
+		if call.Domain == "observe.example.com" {
+			calls = append(calls, call)
+		}
+	}))
+
+	errBoom := errors.New("boom")
+
+	err := ObserveProviderCall(&namedProviderMock{}, OperationPresent, "observe.example.com", func() error { return errBoom })
+	assert.Equal(t, errBoom, err)
+
+	// the calls of a wrapper are reported by the wrapper itself, for the wrapped providers.
+	err = ObserveProviderCall(&wrapperMock{}, OperationCleanUp, "observe.example.com", func() error { return nil })
+	require.NoError(t, err)
+
+	require.Len(t, calls, 1)
+	assert.Equal(t, "named", calls[0].Provider)
+	assert.Equal(t, OperationPresent, calls[0].Operation)
+	assert.Equal(t, errBoom, calls[0].Err)
+}
+
+func TestProviderMetrics(t *testing.T) {
+	metrics := NewProviderMetrics()
+
+	metrics.ObserveProviderCall(ProviderCall{Provider: "route53", Operation: OperationPresent, Duration: 2 * time.Second})
+	metrics.ObserveProviderCall(ProviderCall{Provider: "route53", Operation: OperationPresent, Duration: time.Second, Err: errors.New("boom")})
+	metrics.ObserveProviderCall(ProviderCall{Provider: "cloudflare", Operation: OperationCleanUp, Duration: 500 * time.Millisecond})
+
+	expected := []ProviderCallStats{
+		{Provider: "cloudflare", Operation: OperationCleanUp, Calls: 1, Total: 500 * time.Millisecond, Max: 500 * time.Millisecond, Last: 500 * time.Millisecond},
+		{Provider: "route53", Operation: OperationPresent, Calls: 2, Errors: 1, Total: 3 * time.Second, Max: 2 * time.Second, Last: time.Second},
+	}
+	assert.Equal(t, expected, metrics.Stats())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, metrics.WritePrometheus(buf))
+
+	assert.Contains(t, buf.String(), "# TYPE lego_dns_provider_calls_total counter\n")
+	assert.Contains(t, buf.String(), `lego_dns_provider_calls_total{provider="route53",operation="present"} 2`+"\n")
+	assert.Contains(t, buf.String(), `lego_dns_provider_errors_total{provider="route53",operation="present"} 1`+"\n")
+	assert.Contains(t, buf.String(), `lego_dns_provider_duration_seconds_total{provider="route53",operation="present"} 3`+"\n")
+	assert.Contains(t, buf.String(), `lego_dns_provider_duration_seconds_max{provider="cloudflare",operation="cleanup"} 0.5`+"\n")
+}
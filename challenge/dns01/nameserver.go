@@ -13,8 +13,29 @@ import (
 
 const defaultResolvConf = "/etc/resolv.conf"
 
-// dnsTimeout is used to override the default DNS timeout of 10 seconds.
-var dnsTimeout = 10 * time.Second
+// TCPMode is the use of TCP by the DNS queries.
+type TCPMode string
+
+const (
+	// TCPOnTruncation retries the queries over TCP when the UDP response is truncated.
+	TCPOnTruncation TCPMode = "truncated"
+	// TCPOnError also retries the queries over TCP when the UDP query fails (e.g. times out).
+	TCPOnError TCPMode = "error"
+	// TCPAlways sends the queries over TCP only.
+	TCPAlways TCPMode = "always"
+	// TCPNever sends the queries over UDP only, even when the response is truncated.
+	TCPNever TCPMode = "never"
+)
+
+// ParseTCPMode parses a TCPMode: truncated, error, always, or never.
+func ParseTCPMode(mode string) (TCPMode, error) {
+	switch m := TCPMode(strings.ToLower(mode)); m {
+	case TCPOnTruncation, TCPOnError, TCPAlways, TCPNever:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown TCP mode %q: truncated, error, always, or never", mode)
+	}
+}
 
 var (
 	fqdnSoaCache   = map[string]*soaCacheEntry{}
@@ -26,9 +47,6 @@ var defaultNameservers = []string{
 	"google-public-dns-b.google.com:53",
 }
 
-// recursiveNameservers are used to pre-check DNS propagation
-var recursiveNameservers = getNameservers(defaultResolvConf, defaultNameservers)
-
 // soaCacheEntry holds a cached SOA record (only selected fields)
 type soaCacheEntry struct {
 	zone      string    // zone apex (a domain name)
@@ -40,13 +58,13 @@ func newSoaCacheEntry(soa *dns.SOA) *soaCacheEntry {
 	return &soaCacheEntry{
 		zone:      soa.Hdr.Name,
 		primaryNs: soa.Ns,
-		expires:   time.Now().Add(time.Duration(soa.Refresh) * time.Second),
+		expires:   clk.Now().Add(time.Duration(soa.Refresh) * time.Second),
 	}
 }
 
 // isExpired checks whether a cache entry should be considered expired.
 func (cache *soaCacheEntry) isExpired() bool {
-	return time.Now().After(cache.expires)
+	return clk.Now().After(cache.expires)
 }
 
 // ClearFqdnCache clears the cache of fqdn to zone mappings. Primarily used in testing.
@@ -56,16 +74,54 @@ func ClearFqdnCache() {
 	muFqdnSoaCache.Unlock()
 }
 
+// AddDNSTimeout sets the timeout of the DNS queries of the challenge (10 seconds by default).
 func AddDNSTimeout(timeout time.Duration) ChallengeOption {
-	return func(_ *Challenge) error {
-		dnsTimeout = timeout
+	return func(chlg *Challenge) error {
+		chlg.config.Timeout = timeout
+		return nil
+	}
+}
+
+// AddDNSRetries sets the number of times a query is retried on a nameserver after a network error.
+func AddDNSRetries(retries int) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if retries < 0 {
+			return fmt.Errorf("invalid number of DNS retries: %d", retries)
+		}
+		chlg.config.Retries = retries
 		return nil
 	}
 }
 
+// SetDNSTCPMode sets the use of TCP by the DNS queries (TCPOnTruncation by default).
+func SetDNSTCPMode(mode TCPMode) ChallengeOption {
+	return func(chlg *Challenge) error {
+		m, err := ParseTCPMode(string(mode))
+		if err != nil {
+			return err
+		}
+		chlg.config.TCPMode = m
+		return nil
+	}
+}
+
+// SetEDNSBufferSize sets the UDP buffer size advertised with EDNS0 (4096 by default), 0 disables EDNS0.
+// A size of 1232 avoids the IP fragmentation, dropped by some middleboxes.
+func SetEDNSBufferSize(size uint16) ChallengeOption {
+	return func(chlg *Challenge) error {
+		if size != 0 && size < dns.MinMsgSize {
+			return fmt.Errorf("invalid EDNS buffer size %d: must be at least %d", size, dns.MinMsgSize)
+		}
+		chlg.config.EDNSBufferSize = size
+		return nil
+	}
+}
+
+// AddRecursiveNameservers sets the recursive nameservers used by the challenge to check the propagation.
+// The package-level functions (e.g. FindZoneByFqdn) use the nameservers of DefaultChallengeConfig.
 func AddRecursiveNameservers(nameservers []string) ChallengeOption {
-	return func(_ *Challenge) error {
-		recursiveNameservers = ParseNameservers(nameservers)
+	return func(chlg *Challenge) error {
+		chlg.config.RecursiveNameservers = ParseNameservers(nameservers)
 		return nil
 	}
 }
@@ -94,15 +150,15 @@ func ParseNameservers(servers []string) []string {
 }
 
 // lookupNameservers returns the authoritative nameservers for the given fqdn.
-func lookupNameservers(fqdn string) ([]string, error) {
+func (c ChallengeConfig) lookupNameservers(fqdn string) ([]string, error) {
 	var authoritativeNss []string
 
-	zone, err := FindZoneByFqdn(fqdn)
+	zone, err := c.findZoneByFqdn(fqdn)
 	if err != nil {
 		return nil, fmt.Errorf("could not determine the zone: %w", err)
 	}
 
-	r, err := dnsQuery(zone, dns.TypeNS, recursiveNameservers, true)
+	r, err := c.dnsQuery(zone, dns.TypeNS, c.RecursiveNameservers, true)
 	if err != nil {
 		return nil, err
 	}
@@ -122,13 +178,17 @@ func lookupNameservers(fqdn string) ([]string, error) {
 // FindPrimaryNsByFqdn determines the primary nameserver of the zone apex for the given fqdn
 // by recursing up the domain labels until the nameserver returns a SOA record in the answer section.
 func FindPrimaryNsByFqdn(fqdn string) (string, error) {
-	return FindPrimaryNsByFqdnCustom(fqdn, recursiveNameservers)
+	soa, err := lookupSoaByFqdn(DefaultChallengeConfig(), fqdn)
+	if err != nil {
+		return "", err
+	}
+	return soa.primaryNs, nil
 }
 
 // FindPrimaryNsByFqdnCustom determines the primary nameserver of the zone apex for the given fqdn
 // by recursing up the domain labels until the nameserver returns a SOA record in the answer section.
 func FindPrimaryNsByFqdnCustom(fqdn string, nameservers []string) (string, error) {
-	soa, err := lookupSoaByFqdn(fqdn, nameservers)
+	soa, err := lookupSoaByFqdn(withNameservers(nameservers), fqdn)
 	if err != nil {
 		return "", err
 	}
@@ -138,20 +198,31 @@ func FindPrimaryNsByFqdnCustom(fqdn string, nameservers []string) (string, error
 // FindZoneByFqdn determines the zone apex for the given fqdn
 // by recursing up the domain labels until the nameserver returns a SOA record in the answer section.
 func FindZoneByFqdn(fqdn string) (string, error) {
-	return FindZoneByFqdnCustom(fqdn, recursiveNameservers)
+	return DefaultChallengeConfig().findZoneByFqdn(fqdn)
 }
 
 // FindZoneByFqdnCustom determines the zone apex for the given fqdn
 // by recursing up the domain labels until the nameserver returns a SOA record in the answer section.
 func FindZoneByFqdnCustom(fqdn string, nameservers []string) (string, error) {
-	soa, err := lookupSoaByFqdn(fqdn, nameservers)
+	return withNameservers(nameservers).findZoneByFqdn(fqdn)
+}
+
+func (c ChallengeConfig) findZoneByFqdn(fqdn string) (string, error) {
+	soa, err := lookupSoaByFqdn(c, fqdn)
 	if err != nil {
 		return "", err
 	}
 	return soa.zone, nil
 }
 
-func lookupSoaByFqdn(fqdn string, nameservers []string) (*soaCacheEntry, error) {
+// withNameservers returns the default configuration with the given recursive nameservers.
+func withNameservers(nameservers []string) ChallengeConfig {
+	config := DefaultChallengeConfig()
+	config.RecursiveNameservers = nameservers
+	return config
+}
+
+func lookupSoaByFqdn(config ChallengeConfig, fqdn string) (*soaCacheEntry, error) {
 	muFqdnSoaCache.Lock()
 	defer muFqdnSoaCache.Unlock()
 
@@ -160,7 +231,7 @@ func lookupSoaByFqdn(fqdn string, nameservers []string) (*soaCacheEntry, error)
 		return ent, nil
 	}
 
-	ent, err := fetchSoaByFqdn(fqdn, nameservers)
+	ent, err := fetchSoaByFqdn(config, fqdn)
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +240,7 @@ func lookupSoaByFqdn(fqdn string, nameservers []string) (*soaCacheEntry, error)
 	return ent, nil
 }
 
-func fetchSoaByFqdn(fqdn string, nameservers []string) (*soaCacheEntry, error) {
+func fetchSoaByFqdn(config ChallengeConfig, fqdn string) (*soaCacheEntry, error) {
 	var err error
 	var in *dns.Msg
 
@@ -177,7 +248,7 @@ func fetchSoaByFqdn(fqdn string, nameservers []string) (*soaCacheEntry, error) {
 	for _, index := range labelIndexes {
 		domain := fqdn[index:]
 
-		in, err = dnsQuery(domain, dns.TypeSOA, nameservers, true)
+		in, err = config.dnsQuery(domain, dns.TypeSOA, config.RecursiveNameservers, true)
 		if err != nil {
 			continue
 		}
@@ -225,14 +296,14 @@ func dnsMsgContainsCNAME(msg *dns.Msg) bool {
 	return false
 }
 
-func dnsQuery(fqdn string, rtype uint16, nameservers []string, recursive bool) (*dns.Msg, error) {
-	m := createDNSMsg(fqdn, rtype, recursive)
+func (c ChallengeConfig) dnsQuery(fqdn string, rtype uint16, nameservers []string, recursive bool) (*dns.Msg, error) {
+	m := c.createDNSMsg(fqdn, rtype, recursive)
 
 	var in *dns.Msg
 	var err error
 
 	for _, ns := range nameservers {
-		in, err = sendDNSQuery(m, ns)
+		in, err = c.sendDNSQuery(m, ns)
 		if err == nil && len(in.Answer) > 0 {
 			break
 		}
@@ -240,10 +311,12 @@ func dnsQuery(fqdn string, rtype uint16, nameservers []string, recursive bool) (
 	return in, err
 }
 
-func createDNSMsg(fqdn string, rtype uint16, recursive bool) *dns.Msg {
+func (c ChallengeConfig) createDNSMsg(fqdn string, rtype uint16, recursive bool) *dns.Msg {
 	m := new(dns.Msg)
 	m.SetQuestion(fqdn, rtype)
-	m.SetEdns0(4096, false)
+	if c.EDNSBufferSize > 0 {
+		m.SetEdns0(c.EDNSBufferSize, false)
+	}
 
 	if !recursive {
 		m.RecursionDesired = false
@@ -252,19 +325,37 @@ func createDNSMsg(fqdn string, rtype uint16, recursive bool) *dns.Msg {
 	return m
 }
 
-func sendDNSQuery(m *dns.Msg, ns string) (*dns.Msg, error) {
-	udp := &dns.Client{Net: "udp", Timeout: dnsTimeout}
-	in, _, err := udp.Exchange(m, ns)
+func (c ChallengeConfig) sendDNSQuery(m *dns.Msg, ns string) (*dns.Msg, error) {
+	in, err := c.exchangeWithFallback(m, ns)
+	for i := 0; i < c.Retries && err != nil; i++ {
+		in, err = c.exchangeWithFallback(m, ns)
+	}
+	return in, err
+}
+
+func (c ChallengeConfig) exchangeWithFallback(m *dns.Msg, ns string) (*dns.Msg, error) {
+	if c.TCPMode == TCPAlways {
+		return c.exchange(m, ns, "tcp")
+	}
 
-	if in != nil && in.Truncated {
-		tcp := &dns.Client{Net: "tcp", Timeout: dnsTimeout}
+	in, err := c.exchange(m, ns, "udp")
+
+	switch {
+	case c.TCPMode == TCPNever:
+	case in != nil && in.Truncated, err != nil && c.TCPMode == TCPOnError:
 		// If the TCP request succeeds, the err will reset to nil
-		in, _, err = tcp.Exchange(m, ns)
+		in, err = c.exchange(m, ns, "tcp")
 	}
 
 	return in, err
 }
 
+func (c ChallengeConfig) exchange(m *dns.Msg, ns, network string) (*dns.Msg, error) {
+	client := &dns.Client{Net: network, Timeout: c.Timeout}
+	in, _, err := client.Exchange(m, ns)
+	return in, err
+}
+
 func formatDNSError(msg *dns.Msg, err error) string {
 	var parts []string
 
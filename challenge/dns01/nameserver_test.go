@@ -1,9 +1,14 @@
 package dns01
 
 import (
+	"net"
 	"sort"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +37,7 @@ func TestLookupNameserversOK(t *testing.T) {
 		t.Run(test.fqdn, func(t *testing.T) {
 			t.Parallel()
 
-			nss, err := lookupNameservers(test.fqdn)
+			nss, err := DefaultChallengeConfig().lookupNameservers(test.fqdn)
 			require.NoError(t, err)
 
 			sort.Strings(nss)
@@ -61,7 +66,7 @@ func TestLookupNameserversErr(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := lookupNameservers(test.fqdn)
+			_, err := DefaultChallengeConfig().lookupNameservers(test.fqdn)
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), test.error)
 		})
@@ -81,28 +86,28 @@ var findXByFqdnTestCases = []struct {
 		fqdn:        "mail.google.com.",
 		zone:        "google.com.",
 		primaryNs:   "ns1.google.com.",
-		nameservers: recursiveNameservers,
+		nameservers: DefaultChallengeConfig().RecursiveNameservers,
 	},
 	{
 		desc:        "domain is a non-existent subdomain",
 		fqdn:        "foo.google.com.",
 		zone:        "google.com.",
 		primaryNs:   "ns1.google.com.",
-		nameservers: recursiveNameservers,
+		nameservers: DefaultChallengeConfig().RecursiveNameservers,
 	},
 	{
 		desc:        "domain is a eTLD",
 		fqdn:        "example.com.ac.",
 		zone:        "ac.",
 		primaryNs:   "a0.nic.ac.",
-		nameservers: recursiveNameservers,
+		nameservers: DefaultChallengeConfig().RecursiveNameservers,
 	},
 	{
 		desc:        "domain is a cross-zone CNAME",
 		fqdn:        "cross-zone-example.assets.sh.",
 		zone:        "assets.sh.",
 		primaryNs:   "gina.ns.cloudflare.com.",
-		nameservers: recursiveNameservers,
+		nameservers: DefaultChallengeConfig().RecursiveNameservers,
 	},
 	{
 		desc:          "NXDOMAIN",
@@ -197,3 +202,160 @@ func TestResolveConfServers(t *testing.T) {
 		})
 	}
 }
+
+func TestSoaCacheEntry_isExpired(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+
+	clk = fake
+	defer func() { clk = clock.Real }()
+
+	entry := newSoaCacheEntry(&dns.SOA{
+		Hdr:     dns.RR_Header{Name: "example.com."},
+		Ns:      "ns1.example.com.",
+		Refresh: 60,
+	})
+
+	assert.False(t, entry.isExpired())
+
+	fake.Advance(61 * time.Second)
+
+	assert.True(t, entry.isExpired())
+}
+
+// startTruncatingResolver starts a local DNS server truncating the UDP responses, and answering over TCP.
+// It returns its address and the networks of the received queries.
+func startTruncatingResolver(t *testing.T) (string, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var networks []string
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		network := w.LocalAddr().Network()
+
+		mu.Lock()
+		networks = append(networks, network)
+		mu.Unlock()
+
+		m := new(dns.Msg)
+		m.SetReply(req)
+
+		if network == "udp" {
+			m.Truncated = true
+		} else {
+			rr, _ := dns.NewRR(req.Question[0].Name + " 60 IN TXT \"value\"")
+			m.Answer = append(m.Answer, rr)
+		}
+
+		_ = w.WriteMsg(m)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	conn, err := net.ListenPacket("udp", listener.Addr().String())
+	require.NoError(t, err)
+
+	tcpServer := &dns.Server{Listener: listener, Handler: handler}
+	udpServer := &dns.Server{PacketConn: conn, Handler: handler}
+	go func() { _ = tcpServer.ActivateAndServe() }()
+	go func() { _ = udpServer.ActivateAndServe() }()
+	t.Cleanup(func() {
+		_ = tcpServer.Shutdown()
+		_ = udpServer.Shutdown()
+	})
+
+	return listener.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), networks...)
+	}
+}
+
+func Test_sendDNSQuery_tcpMode(t *testing.T) {
+	testCases := []struct {
+		mode             TCPMode
+		expectedNetworks []string
+		expectedAnswer   bool
+	}{
+		{mode: TCPOnTruncation, expectedNetworks: []string{"udp", "tcp"}, expectedAnswer: true},
+		{mode: TCPOnError, expectedNetworks: []string{"udp", "tcp"}, expectedAnswer: true},
+		{mode: TCPAlways, expectedNetworks: []string{"tcp"}, expectedAnswer: true},
+		{mode: TCPNever, expectedNetworks: []string{"udp"}},
+	}
+
+	for _, test := range testCases {
+		t.Run(string(test.mode), func(t *testing.T) {
+			resolver, networks := startTruncatingResolver(t)
+
+			config := NewChallengeConfig()
+			config.TCPMode = test.mode
+
+			in, err := config.sendDNSQuery(config.createDNSMsg("_acme-challenge.example.com.", dns.TypeTXT, true), resolver)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedNetworks, networks())
+			assert.Equal(t, test.expectedAnswer, len(in.Answer) > 0)
+		})
+	}
+}
+
+func Test_sendDNSQuery_retries(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	var mu sync.Mutex
+	var received int
+
+	// never answers: the queries time out.
+	go func() {
+		buf := make([]byte, dns.MaxMsgSize)
+		for {
+			if _, _, errR := conn.ReadFrom(buf); errR != nil {
+				return
+			}
+			mu.Lock()
+			received++
+			mu.Unlock()
+		}
+	}()
+
+	config := NewChallengeConfig()
+	config.Timeout, config.Retries = 50*time.Millisecond, 2
+
+	_, err = config.sendDNSQuery(config.createDNSMsg("example.com.", dns.TypeSOA, true), conn.LocalAddr().String())
+	require.Error(t, err)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return received == 3
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_createDNSMsg_ednsBufferSize(t *testing.T) {
+	config := NewChallengeConfig()
+
+	m := config.createDNSMsg("example.com.", dns.TypeSOA, true)
+	require.NotNil(t, m.IsEdns0())
+	assert.Equal(t, uint16(4096), m.IsEdns0().UDPSize())
+
+	config.EDNSBufferSize = 1232
+	m = config.createDNSMsg("example.com.", dns.TypeSOA, true)
+	require.NotNil(t, m.IsEdns0())
+	assert.Equal(t, uint16(1232), m.IsEdns0().UDPSize())
+
+	config.EDNSBufferSize = 0
+	m = config.createDNSMsg("example.com.", dns.TypeSOA, true)
+	assert.Nil(t, m.IsEdns0())
+}
+
+func TestParseTCPMode(t *testing.T) {
+	mode, err := ParseTCPMode("Always")
+	require.NoError(t, err)
+	assert.Equal(t, TCPAlways, mode)
+
+	_, err = ParseTCPMode("sometimes")
+	require.EqualError(t, err, `unknown TCP mode "sometimes": truncated, error, always, or never`)
+}
@@ -0,0 +1,37 @@
+package dns01
+
+import (
+	"strings"
+	"time"
+)
+
+// OwnershipMarker tags the challenge records created by lego,
+// in the comments of the records of the providers supporting them.
+const OwnershipMarker = "lego-acme-challenge"
+
+// OwnershipComment returns the comment of a challenge record created by lego: the marker and the creation time.
+func OwnershipComment(created time.Time) string {
+	return OwnershipMarker + " created=" + created.UTC().Format(time.RFC3339)
+}
+
+// ParseOwnershipComment reports whether the comment of a record carries the ownership marker,
+// and returns the creation time it records (zero if missing).
+func ParseOwnershipComment(comment string) (time.Time, bool) {
+	fields := strings.Fields(comment)
+	if len(fields) == 0 || fields[0] != OwnershipMarker {
+		return time.Time{}, false
+	}
+
+	for _, field := range fields[1:] {
+		if !strings.HasPrefix(field, "created=") {
+			continue
+		}
+
+		created, err := time.Parse(time.RFC3339, strings.TrimPrefix(field, "created="))
+		if err == nil {
+			return created, true
+		}
+	}
+
+	return time.Time{}, true
+}
@@ -0,0 +1,60 @@
+package dns01
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOwnershipComment(t *testing.T) {
+	created := time.Date(2020, time.April, 7, 9, 19, 38, 0, time.UTC)
+
+	testCases := []struct {
+		desc            string
+		comment         string
+		expectedCreated time.Time
+		expectedOwned   bool
+	}{
+		{
+			desc:            "marker and creation time",
+			comment:         OwnershipComment(created),
+			expectedCreated: created,
+			expectedOwned:   true,
+		},
+		{
+			desc:          "marker only",
+			comment:       OwnershipMarker,
+			expectedOwned: true,
+		},
+		{
+			desc:          "invalid creation time",
+			comment:       OwnershipMarker + " created=yesterday",
+			expectedOwned: true,
+		},
+		{
+			desc:    "empty",
+			comment: "",
+		},
+		{
+			desc:    "user comment",
+			comment: "verification of the domain for the mail provider",
+		},
+		{
+			desc:    "marker not first",
+			comment: "not a " + OwnershipMarker,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			created, owned := ParseOwnershipComment(test.comment)
+
+			assert.Equal(t, test.expectedOwned, owned)
+			assert.Equal(t, test.expectedCreated, created)
+		})
+	}
+}
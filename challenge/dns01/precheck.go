@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 
 	"github.com/miekg/dns"
@@ -60,18 +61,22 @@ func newPreCheck() preCheck {
 	}
 }
 
-func (p preCheck) call(domain, fqdn, value string) (bool, error) {
+func (p preCheck) call(config ChallengeConfig, domain, fqdn, value string) (bool, error) {
+	check := func(fqdn, value string) (bool, error) {
+		return p.checkDNSPropagation(config, fqdn, value)
+	}
+
 	if p.checkFunc == nil {
-		return p.checkDNSPropagation(fqdn, value)
+		return check(fqdn, value)
 	}
 
-	return p.checkFunc(domain, fqdn, value, p.checkDNSPropagation)
+	return p.checkFunc(domain, fqdn, value, check)
 }
 
 // checkDNSPropagation checks if the expected TXT record has been propagated to all authoritative nameservers.
-func (p preCheck) checkDNSPropagation(fqdn, value string) (bool, error) {
+func (p preCheck) checkDNSPropagation(config ChallengeConfig, fqdn, value string) (bool, error) {
 	// Initial attempt to resolve at the recursive NS
-	r, err := dnsQuery(fqdn, dns.TypeTXT, recursiveNameservers, true)
+	r, err := config.dnsQuery(fqdn, dns.TypeTXT, config.RecursiveNameservers, true)
 	if err != nil {
 		return false, err
 	}
@@ -84,44 +89,70 @@ func (p preCheck) checkDNSPropagation(fqdn, value string) (bool, error) {
 		fqdn = updateDomainWithCName(r, fqdn)
 	}
 
-	authoritativeNss, err := lookupNameservers(fqdn)
+	authoritativeNss, err := config.lookupNameservers(fqdn)
 	if err != nil {
 		return false, err
 	}
 
-	return checkAuthoritativeNss(fqdn, value, authoritativeNss)
+	return checkAuthoritativeNss(config, fqdn, value, authoritativeNss)
+}
+
+// TXTRecordError a name server didn't return the expected TXT record.
+// Observed holds the values of all the TXT records of the fqdn returned by the name server,
+// e.g. the values of the other challenges of the same domain, or a stale value.
+type TXTRecordError struct {
+	Nameserver string
+	FQDN       string
+	Value      string
+	Observed   []string
+}
+
+func (e *TXTRecordError) Error() string {
+	msg := fmt.Sprintf("NS %s did not return the expected TXT record [fqdn: %s, value: %s]", e.Nameserver, e.FQDN, e.Value)
+
+	if len(e.Observed) == 0 {
+		return msg + ": no TXT record"
+	}
+
+	observed := make([]string, len(e.Observed))
+	for i, value := range e.Observed {
+		observed[i] = strconv.Quote(value)
+	}
+
+	return msg + ": observed values: " + strings.Join(observed, ", ")
 }
 
 // checkAuthoritativeNss queries each of the given nameservers for the expected TXT record.
-func checkAuthoritativeNss(fqdn, value string, nameservers []string) (bool, error) {
+func checkAuthoritativeNss(config ChallengeConfig, fqdn, value string, nameservers []string) (bool, error) {
 	for _, ns := range nameservers {
-		r, err := dnsQuery(fqdn, dns.TypeTXT, []string{net.JoinHostPort(ns, "53")}, false)
+		err := checkNameserverTXT(config, fqdn, value, ns, net.JoinHostPort(ns, "53"))
 		if err != nil {
 			return false, err
 		}
+	}
 
-		if r.Rcode != dns.RcodeSuccess {
-			return false, fmt.Errorf("NS %s returned %s for %s", ns, dns.RcodeToString[r.Rcode], fqdn)
-		}
+	return true, nil
+}
 
-		var records []string
-
-		var found bool
-		for _, rr := range r.Answer {
-			if txt, ok := rr.(*dns.TXT); ok {
-				record := strings.Join(txt.Txt, "")
-				records = append(records, record)
-				if record == value {
-					found = true
-					break
-				}
-			}
-		}
+// checkNameserverTXT checks that the expected value is one of the TXT records of the fqdn returned by a name server (host:port).
+// The RRset can hold several values (e.g. the challenges of a domain and of its wildcard),
+// and the strings of a value longer than 255 bytes are joined.
+func checkNameserverTXT(config ChallengeConfig, fqdn, value, ns, server string) error {
+	r, err := config.dnsQuery(fqdn, dns.TypeTXT, []string{server}, false)
+	if err != nil {
+		return err
+	}
 
-		if !found {
-			return false, fmt.Errorf("NS %s did not return the expected TXT record [fqdn: %s, value: %s]: %s", ns, fqdn, value, strings.Join(records, " ,"))
+	if r.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("NS %s returned %s for %s", ns, dns.RcodeToString[r.Rcode], fqdn)
+	}
+
+	observed, _ := txtValues(r, fqdn)
+	for _, record := range observed {
+		if record == value {
+			return nil
 		}
 	}
 
-	return true, nil
+	return &TXTRecordError{Nameserver: ns, FQDN: fqdn, Value: value, Observed: observed}
 }
@@ -1,6 +1,7 @@
 package dns01
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,7 +36,7 @@ func TestCheckDNSPropagation(t *testing.T) {
 
 			check := newPreCheck()
 
-			ok, err := check.checkDNSPropagation(test.fqdn, test.value)
+			ok, err := check.checkDNSPropagation(DefaultChallengeConfig(), test.fqdn, test.value)
 			if test.expectError {
 				assert.Errorf(t, err, "PreCheckDNS must failed for %s", test.fqdn)
 				assert.False(t, ok, "PreCheckDNS must failed for %s", test.fqdn)
@@ -74,7 +75,7 @@ func TestCheckAuthoritativeNss(t *testing.T) {
 			t.Parallel()
 			ClearFqdnCache()
 
-			ok, _ := checkAuthoritativeNss(test.fqdn, test.value, test.ns)
+			ok, _ := checkAuthoritativeNss(DefaultChallengeConfig(), test.fqdn, test.value, test.ns)
 			assert.Equal(t, test.expected, ok, test.fqdn)
 		})
 	}
@@ -109,9 +110,76 @@ func TestCheckAuthoritativeNssErr(t *testing.T) {
 			t.Parallel()
 			ClearFqdnCache()
 
-			_, err := checkAuthoritativeNss(test.fqdn, test.value, test.ns)
+			_, err := checkAuthoritativeNss(DefaultChallengeConfig(), test.fqdn, test.value, test.ns)
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), test.error)
 		})
 	}
 }
+
+func Test_checkNameserverTXT(t *testing.T) {
+	long := strings.Repeat("a", 255) + strings.Repeat("b", 45)
+
+	server := startResolver(t,
+		`_acme-challenge.example.com. 60 IN TXT "LHDhK3oGRvkiefQnx7OOczTY5Tic_xZ6HcMOc_gmtoM"`,
+		`_acme-challenge.example.com. 60 IN TXT "9ihDbjxIGsmiWo-HHNHqjCpCSn5JqKZFNL8Nt1hXY8g"`,
+		`_acme-challenge.long.example.com. 60 IN TXT "`+long[:255]+`" "`+long[255:]+`"`,
+	)
+
+	config := DefaultChallengeConfig()
+
+	testCases := []struct {
+		desc     string
+		fqdn     string
+		value    string
+		expected string
+	}{
+		{
+			desc:  "first value of the RRset",
+			fqdn:  "_acme-challenge.example.com.",
+			value: "LHDhK3oGRvkiefQnx7OOczTY5Tic_xZ6HcMOc_gmtoM",
+		},
+		{
+			desc:  "second value of the RRset",
+			fqdn:  "_acme-challenge.example.com.",
+			value: "9ihDbjxIGsmiWo-HHNHqjCpCSn5JqKZFNL8Nt1hXY8g",
+		},
+		{
+			desc:  "value split in several strings",
+			fqdn:  "_acme-challenge.long.example.com.",
+			value: long,
+		},
+		{
+			desc:  "missing value",
+			fqdn:  "_acme-challenge.example.com.",
+			value: "fe01=",
+			expected: `NS ns1.example.com. did not return the expected TXT record [fqdn: _acme-challenge.example.com., value: fe01=]: ` +
+				`observed values: "LHDhK3oGRvkiefQnx7OOczTY5Tic_xZ6HcMOc_gmtoM", "9ihDbjxIGsmiWo-HHNHqjCpCSn5JqKZFNL8Nt1hXY8g"`,
+		},
+		{
+			desc:     "no record",
+			fqdn:     "_acme-challenge.other.example.com.",
+			value:    "fe01=",
+			expected: "NS ns1.example.com. returned NXDOMAIN for _acme-challenge.other.example.com.",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			err := checkNameserverTXT(config, test.fqdn, test.value, "ns1.example.com.", server)
+			if test.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestTXTRecordError(t *testing.T) {
+	err := &TXTRecordError{Nameserver: "ns1.example.com.", FQDN: "_acme-challenge.example.com.", Value: "fe01="}
+
+	assert.EqualError(t, err, "NS ns1.example.com. did not return the expected TXT record [fqdn: _acme-challenge.example.com., value: fe01=]: no TXT record")
+}
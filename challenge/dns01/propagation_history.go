@@ -0,0 +1,119 @@
+package dns01
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v3/log"
+)
+
+const (
+	// maxPropagationSamples the number of durations kept by a PropagationHistory.
+	maxPropagationSamples = 50
+	// minPropagationSamples the number of durations required to size the propagation timeout.
+	minPropagationSamples = 5
+	// minPropagationMargin the minimal margin added to the 95th percentile of the durations.
+	minPropagationMargin = 30 * time.Second
+)
+
+// PropagationHistory the propagation durations observed with a DNS provider:
+// the time between the creation of the TXT record and its propagation.
+type PropagationHistory struct {
+	Durations []time.Duration `json:"durations"`
+}
+
+// Add records a propagation duration, keeping only the most recent ones.
+func (h *PropagationHistory) Add(d time.Duration) {
+	h.Durations = append(h.Durations, d)
+
+	if len(h.Durations) > maxPropagationSamples {
+		h.Durations = h.Durations[len(h.Durations)-maxPropagationSamples:]
+	}
+}
+
+// Timeout returns the propagation timeout sized from the history:
+// the 95th percentile of the durations, plus a margin of 50% (at least 30 seconds).
+// It returns false while the history is too short.
+func (h *PropagationHistory) Timeout() (time.Duration, bool) {
+	if len(h.Durations) < minPropagationSamples {
+		return 0, false
+	}
+
+	durations := make([]time.Duration, len(h.Durations))
+	copy(durations, h.Durations)
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	// nearest-rank percentile.
+	p95 := durations[(len(durations)*95+99)/100-1]
+
+	margin := p95 / 2
+	if margin < minPropagationMargin {
+		margin = minPropagationMargin
+	}
+
+	return p95 + margin, true
+}
+
+// AutoTunePropagation sizes the propagation timeout from the durations observed with the DNS provider (see PropagationHistory.Timeout),
+// instead of the timeout of the provider, and records the new durations.
+// The history is passed to save after every propagation, successful or not: a timeout is recorded as its elapsed time.
+func AutoTunePropagation(history *PropagationHistory, save func(*PropagationHistory) error) ChallengeOption {
+	return func(chlg *Challenge) error {
+		chlg.propagationTuning = &propagationTuning{
+			history:  history,
+			save:     save,
+			presents: make(map[string]time.Time),
+		}
+		return nil
+	}
+}
+
+type propagationTuning struct {
+	mu       sync.Mutex
+	history  *PropagationHistory
+	save     func(*PropagationHistory) error
+	presents map[string]time.Time
+}
+
+// presented records the creation time of a record.
+func (p *propagationTuning) presented(fqdn, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.presents[fqdn+value] = clk.Now()
+}
+
+// timeout returns the tuned timeout, or the timeout of the provider while the history is too short.
+func (p *propagationTuning) timeout(domain string, providerTimeout time.Duration) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	timeout, ok := p.history.Timeout()
+	if !ok {
+		return providerTimeout
+	}
+
+	log.Infof("[%s] acme: Propagation timeout sized from %d observed durations: %s (provider: %s)",
+		domain, len(p.history.Durations), timeout, providerTimeout)
+
+	return timeout
+}
+
+// propagated records the propagation duration of a record.
+func (p *propagationTuning) propagated(domain, fqdn, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	start, ok := p.presents[fqdn+value]
+	if !ok {
+		return
+	}
+	delete(p.presents, fqdn+value)
+
+	p.history.Add(clk.Now().Sub(start))
+
+	if err := p.save(p.history); err != nil {
+		log.Warnf("[%s] acme: could not save the propagation history: %v", domain, err)
+	}
+}
@@ -0,0 +1,121 @@
+package dns01
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropagationHistory_Timeout(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		durations []time.Duration
+		expected  time.Duration
+		tuned     bool
+	}{
+		{
+			desc:      "too short",
+			durations: []time.Duration{10 * time.Second, 10 * time.Second},
+		},
+		{
+			desc:      "minimal margin",
+			durations: []time.Duration{10 * time.Second, 5 * time.Second, 20 * time.Second, 10 * time.Second, 15 * time.Second},
+			expected:  50 * time.Second,
+			tuned:     true,
+		},
+		{
+			desc: "95th percentile",
+			durations: []time.Duration{
+				1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 1 * time.Minute,
+				1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 1 * time.Minute,
+				1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 1 * time.Minute,
+				1 * time.Minute, 1 * time.Minute, 1 * time.Minute, 4 * time.Minute, 20 * time.Minute,
+			},
+			expected: 6 * time.Minute,
+			tuned:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			history := &PropagationHistory{Durations: test.durations}
+
+			timeout, ok := history.Timeout()
+			assert.Equal(t, test.tuned, ok)
+			assert.Equal(t, test.expected, timeout)
+		})
+	}
+}
+
+func TestPropagationHistory_Add(t *testing.T) {
+	history := &PropagationHistory{}
+
+	for i := 1; i <= maxPropagationSamples+10; i++ {
+		history.Add(time.Duration(i) * time.Second)
+	}
+
+	require.Len(t, history.Durations, maxPropagationSamples)
+	assert.Equal(t, 11*time.Second, history.Durations[0])
+}
+
+func TestAutoTunePropagation(t *testing.T) {
+	_, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", privateKey)
+	require.NoError(t, err)
+
+	fake := clock.NewFake(time.Now())
+	clk = fake
+	defer func() { clk = clock.Real }()
+
+	history := &PropagationHistory{Durations: []time.Duration{
+		10 * time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second,
+	}}
+
+	var saved int
+	save := func(h *PropagationHistory) error {
+		saved++
+		return nil
+	}
+
+	start := fake.Now()
+
+	// the record propagates after 20 seconds: more than the timeout of the provider, less than the tuned timeout (40 seconds).
+	preCheck := func(_, _, _ string, _ PreCheckFunc) (bool, error) {
+		return fake.Now().Sub(start) >= 20*time.Second, nil
+	}
+
+	provider := &providerTimeoutMock{timeout: 5 * time.Second, interval: time.Second}
+
+	chlg := NewChallenge(core, func(_ *api.Core, _ string, _ acme.Challenge) error { return nil }, provider,
+		WrapPreCheck(preCheck), AutoTunePropagation(history, save))
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: challenge.DNS01.String()}},
+	}
+
+	require.NoError(t, chlg.PreSolve(authz))
+	require.NoError(t, chlg.Solve(authz))
+
+	assert.Equal(t, 1, saved)
+	require.Len(t, history.Durations, 6)
+	assert.Equal(t, 20*time.Second, history.Durations[5])
+}
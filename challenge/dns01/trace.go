@@ -0,0 +1,198 @@
+package dns01
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// TraceReport describes the resolution path of a challenge record.
+type TraceReport struct {
+	FQDN string
+
+	// CNAMEs is the chain of aliases followed from FQDN, Target is the end of the chain.
+	CNAMEs []string
+	Target string
+
+	Zone string
+
+	// Resolver is the view of the recursive resolver.
+	Resolver NameserverTrace
+	// Authoritative is the view of each authoritative nameserver of the zone.
+	Authoritative []NameserverTrace
+
+	DNSSEC DNSSECStatus
+}
+
+// NameserverTrace the TXT values returned by a nameserver.
+type NameserverTrace struct {
+	Nameserver string
+	Values     []string
+	Error      string
+}
+
+// DNSSECStatus the DNSSEC status of the zone.
+type DNSSECStatus struct {
+	// Signed is true if the zone publishes DNSKEY records.
+	Signed bool
+	// Validated is true if the recursive resolver validated the answer (AD flag).
+	Validated bool
+}
+
+// Trace walks the resolution path of the given record:
+// the CNAMEs, the zone cut, the authoritative nameservers and their TXT values, and the DNSSEC status.
+// The recursive nameservers are the default ones if nameservers is empty.
+func Trace(fqdn string, nameservers []string) (*TraceReport, error) {
+	config := DefaultChallengeConfig()
+	if len(nameservers) == 0 {
+		nameservers = config.RecursiveNameservers
+	}
+	config.RecursiveNameservers = nameservers
+
+	fqdn = dns.Fqdn(fqdn)
+	report := &TraceReport{FQDN: fqdn, Target: fqdn}
+
+	r, err := config.dnsQuery(fqdn, dns.TypeTXT, nameservers, true)
+	if err != nil {
+		return nil, fmt.Errorf("could not query %s: %w", fqdn, err)
+	}
+
+	for {
+		target := updateDomainWithCName(r, report.Target)
+		if target == report.Target || containsNameserver(report.CNAMEs, target) {
+			break
+		}
+		report.CNAMEs = append(report.CNAMEs, target)
+		report.Target = target
+	}
+
+	report.Resolver = NameserverTrace{Nameserver: strings.Join(nameservers, ", ")}
+	report.Resolver.Values, report.Resolver.Error = txtValues(r, report.Target)
+
+	report.Zone, err = config.findZoneByFqdn(report.Target)
+	if err != nil {
+		return report, fmt.Errorf("could not determine the zone: %w", err)
+	}
+
+	authoritative, err := config.queryNameservers(report.Zone, nameservers)
+	if err != nil {
+		return report, fmt.Errorf("could not find the nameservers of %s: %w", report.Zone, err)
+	}
+
+	for _, ns := range authoritative {
+		trace := NameserverTrace{Nameserver: ns}
+
+		in, err := config.sendDNSQuery(config.createDNSMsg(report.Target, dns.TypeTXT, false), nameserverAddress(ns))
+		if err != nil {
+			trace.Error = err.Error()
+		} else {
+			trace.Values, trace.Error = txtValues(in, report.Target)
+		}
+
+		report.Authoritative = append(report.Authoritative, trace)
+	}
+
+	report.DNSSEC = dnssecStatus(config, report.Zone, report.Target)
+
+	return report, nil
+}
+
+func (r *TraceReport) String() string {
+	var b strings.Builder
+
+	_, _ = fmt.Fprintf(&b, "Record:   %s\n", r.FQDN)
+	for _, cname := range r.CNAMEs {
+		_, _ = fmt.Fprintf(&b, "  CNAME -> %s\n", cname)
+	}
+
+	_, _ = fmt.Fprintf(&b, "Zone:     %s\n", valueOrNone(r.Zone))
+
+	b.WriteString("Resolver:\n")
+	writeNameserverTrace(&b, r.Resolver)
+
+	b.WriteString("Authoritative nameservers:\n")
+	if len(r.Authoritative) == 0 {
+		b.WriteString("  (none)\n")
+	}
+	for _, trace := range r.Authoritative {
+		writeNameserverTrace(&b, trace)
+	}
+
+	_, _ = fmt.Fprintf(&b, "DNSSEC:   signed=%t validated=%t", r.DNSSEC.Signed, r.DNSSEC.Validated)
+
+	return b.String()
+}
+
+func writeNameserverTrace(b *strings.Builder, trace NameserverTrace) {
+	_, _ = fmt.Fprintf(b, "  %s\n", trace.Nameserver)
+
+	if trace.Error != "" {
+		_, _ = fmt.Fprintf(b, "    error: %s\n", trace.Error)
+		return
+	}
+
+	if len(trace.Values) == 0 {
+		b.WriteString("    no TXT record\n")
+	}
+
+	for _, value := range trace.Values {
+		_, _ = fmt.Fprintf(b, "    TXT %q\n", value)
+	}
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// txtValues extracts the TXT values of the given name, or describes the failure.
+func txtValues(r *dns.Msg, fqdn string) ([]string, string) {
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, dns.RcodeToString[r.Rcode]
+	}
+
+	var values []string
+	for _, rr := range r.Answer {
+		if txt, ok := rr.(*dns.TXT); ok && strings.EqualFold(txt.Hdr.Name, fqdn) {
+			values = append(values, strings.Join(txt.Txt, ""))
+		}
+	}
+
+	return values, ""
+}
+
+func dnssecStatus(config ChallengeConfig, zone, fqdn string) DNSSECStatus {
+	var status DNSSECStatus
+
+	r, err := config.dnsQuery(zone, dns.TypeDNSKEY, config.RecursiveNameservers, true)
+	if err == nil {
+		for _, rr := range r.Answer {
+			if _, ok := rr.(*dns.DNSKEY); ok {
+				status.Signed = true
+				break
+			}
+		}
+	}
+
+	m := config.createDNSMsg(fqdn, dns.TypeTXT, true)
+	if opt := m.IsEdns0(); opt != nil {
+		opt.SetDo()
+	} else {
+		// EDNS0 is required by DNSSEC, even if it is disabled for the other queries.
+		m.SetEdns0(dns.MinMsgSize, true)
+	}
+	m.AuthenticatedData = true
+
+	for _, ns := range config.RecursiveNameservers {
+		in, err := config.sendDNSQuery(m, ns)
+		if err == nil {
+			status.Validated = in.AuthenticatedData
+			break
+		}
+	}
+
+	return status
+}
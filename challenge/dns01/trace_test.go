@@ -0,0 +1,119 @@
+package dns01
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startResolver starts a local DNS server answering with the given records.
+func startResolver(t *testing.T, records ...string) string {
+	t.Helper()
+
+	var rrs []dns.RR
+	for _, record := range records {
+		rr, err := dns.NewRR(record)
+		require.NoError(t, err)
+		rrs = append(rrs, rr)
+	}
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+
+		name := req.Question[0].Name
+		for {
+			var cname string
+			for _, rr := range rrs {
+				if rr.Header().Name != name {
+					continue
+				}
+				if c, ok := rr.(*dns.CNAME); ok && req.Question[0].Qtype != dns.TypeCNAME {
+					m.Answer = append(m.Answer, rr)
+					cname = c.Target
+				} else if rr.Header().Rrtype == req.Question[0].Qtype {
+					m.Answer = append(m.Answer, rr)
+				}
+			}
+			if cname == "" {
+				break
+			}
+			name = cname
+		}
+
+		if len(m.Answer) == 0 {
+			m.Rcode = dns.RcodeNameError
+		}
+
+		_ = w.WriteMsg(m)
+	})
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{PacketConn: conn, Handler: handler}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return conn.LocalAddr().String()
+}
+
+func TestTrace(t *testing.T) {
+	ClearFqdnCache()
+	defer ClearFqdnCache()
+
+	resolver := startResolver(t,
+		"_acme-challenge.example.com. 60 IN CNAME _acme-challenge.example.net.",
+		"_acme-challenge.example.net. 60 IN TXT \"value\"",
+		"example.net. 60 IN SOA ns.invalid. hostmaster.example.net. 1 7200 3600 1209600 300",
+		"example.net. 60 IN NS ns.invalid.",
+	)
+
+	report, err := Trace("_acme-challenge.example.com", []string{resolver})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"_acme-challenge.example.net."}, report.CNAMEs)
+	assert.Equal(t, "_acme-challenge.example.net.", report.Target)
+	assert.Equal(t, "example.net.", report.Zone)
+	assert.Equal(t, []string{"value"}, report.Resolver.Values)
+	assert.Empty(t, report.Resolver.Error)
+
+	require.Len(t, report.Authoritative, 1)
+	assert.Equal(t, "ns.invalid.", report.Authoritative[0].Nameserver)
+	assert.NotEmpty(t, report.Authoritative[0].Error)
+
+	assert.False(t, report.DNSSEC.Signed)
+}
+
+func TestTraceReport_String(t *testing.T) {
+	report := &TraceReport{
+		FQDN:     "_acme-challenge.example.com.",
+		CNAMEs:   []string{"_acme-challenge.example.net."},
+		Target:   "_acme-challenge.example.net.",
+		Zone:     "example.net.",
+		Resolver: NameserverTrace{Nameserver: "127.0.0.1:53", Values: []string{"value"}},
+		Authoritative: []NameserverTrace{
+			{Nameserver: "ns1.example.net."},
+			{Nameserver: "ns2.example.net.", Error: "SERVFAIL"},
+		},
+		DNSSEC: DNSSECStatus{Signed: true},
+	}
+
+	expected := `Record:   _acme-challenge.example.com.
+  CNAME -> _acme-challenge.example.net.
+Zone:     example.net.
+Resolver:
+  127.0.0.1:53
+    TXT "value"
+Authoritative nameservers:
+  ns1.example.net.
+    no TXT record
+  ns2.example.net.
+    error: SERVFAIL
+DNSSEC:   signed=true validated=false`
+
+	assert.Equal(t, expected, report.String())
+}
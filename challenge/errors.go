@@ -0,0 +1,28 @@
+package challenge
+
+// ProviderError an error of the provider of a challenge, e.g. the DNS provider could not create the TXT record.
+type ProviderError struct {
+	Err error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError the CA could not validate a challenge, e.g. the TXT record was not found.
+// Err is the error reported by the CA (*acme.ProblemDetails), if any.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,46 @@
+package challenge
+
+import "time"
+
+// EventType is the lifecycle point of a challenge reported by an Event.
+type EventType string
+
+const (
+	// EventPresented the challenge is presented before its validation (e.g. the TXT record of a DNS-01 challenge is created).
+	// Only emitted for the challenges presented in advance (DNS-01).
+	EventPresented = EventType("presented")
+
+	// EventPropagated the propagation of the TXT record of a DNS-01 challenge is confirmed.
+	EventPropagated = EventType("propagated")
+
+	// EventValidationStarted the CA is asked to validate the challenge.
+	EventValidationStarted = EventType("validation-started")
+
+	// EventValidated the CA has validated the challenge.
+	EventValidated = EventType("validated")
+
+	// EventFailed the challenge has failed: Event.Err is the cause.
+	EventFailed = EventType("failed")
+
+	// EventCleaned the challenge is cleaned up (e.g. the TXT record is removed), after its success or its failure.
+	// Event.Err is set if the cleanup has failed.
+	EventCleaned = EventType("cleaned")
+)
+
+func (t EventType) String() string {
+	return string(t)
+}
+
+// Event is a lifecycle event of the challenge of an identifier.
+type Event struct {
+	Type EventType
+	// Domain is the targeted domain (e.g. "*.example.com" for a wildcard).
+	Domain    string
+	Challenge Type
+	Time      time.Time
+	Err       error
+}
+
+// EventHandler receives the lifecycle events of the challenges.
+// It is called synchronously: a slow handler delays the resolution of the challenges.
+type EventHandler func(event Event)
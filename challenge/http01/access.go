@@ -0,0 +1,225 @@
+package http01
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-acme/lego/v3/log"
+)
+
+// maxRateLimitedClients the number of source IPs tracked by the rate limiter before the expired ones are pruned.
+const maxRateLimitedClients = 1024
+
+// The results of the requests received by a ProviderServer.
+const (
+	// RequestServed the key authorization was served.
+	RequestServed = "served"
+	// RequestRejected the request didn't match the challenge (method or domain).
+	RequestRejected = "rejected"
+	// RequestNotFound the path is not the path of the challenge.
+	RequestNotFound = "not-found"
+	// RequestRateLimited the source IP exceeded the rate limit.
+	RequestRateLimited = "rate-limited"
+)
+
+// ServerRequest a request received by a ProviderServer.
+type ServerRequest struct {
+	Domain    string
+	RemoteIP  string
+	Method    string
+	Path      string
+	UserAgent string
+	Status    int
+	Result    string
+}
+
+// ServerStats the counters of the requests received by a ProviderServer.
+type ServerStats struct {
+	Requests    int64
+	Served      int64
+	Rejected    int64
+	NotFound    int64
+	RateLimited int64
+}
+
+type serverCounters struct {
+	requests    int64
+	served      int64
+	rejected    int64
+	notFound    int64
+	rateLimited int64
+}
+
+func (c *serverCounters) add(result string) {
+	atomic.AddInt64(&c.requests, 1)
+
+	switch result {
+	case RequestServed:
+		atomic.AddInt64(&c.served, 1)
+	case RequestRejected:
+		atomic.AddInt64(&c.rejected, 1)
+	case RequestNotFound:
+		atomic.AddInt64(&c.notFound, 1)
+	case RequestRateLimited:
+		atomic.AddInt64(&c.rateLimited, 1)
+	}
+}
+
+// SetAccessLog logs each request received by the server: source IP, method, path (with the token), status, and user agent.
+func (s *ProviderServer) SetAccessLog(enabled bool) {
+	s.accessLog = enabled
+}
+
+// SetRateLimit limits the number of requests from a source IP during a period, the other requests are rejected (429 Too Many Requests).
+// The CA validates a challenge with a few requests, from several IPs: the limit only stops the scanners hammering the server.
+// The rate limit is disabled if requests is 0.
+func (s *ProviderServer) SetRateLimit(requests int, period time.Duration) {
+	if requests <= 0 || period <= 0 {
+		s.limiter = nil
+		return
+	}
+
+	s.limiter = &rateLimiter{limit: requests, period: period, clients: make(map[string]*rateWindow)}
+}
+
+// OnRequest calls fn after each request received by the server.
+func (s *ProviderServer) OnRequest(fn func(req ServerRequest)) {
+	s.onRequest = fn
+}
+
+// Stats returns the counters of the requests received by the server.
+func (s *ProviderServer) Stats() ServerStats {
+	return ServerStats{
+		Requests:    atomic.LoadInt64(&s.counters.requests),
+		Served:      atomic.LoadInt64(&s.counters.served),
+		Rejected:    atomic.LoadInt64(&s.counters.rejected),
+		NotFound:    atomic.LoadInt64(&s.counters.notFound),
+		RateLimited: atomic.LoadInt64(&s.counters.rateLimited),
+	}
+}
+
+// WritePrometheus writes the counters of the requests in the Prometheus text format.
+func (s *ProviderServer) WritePrometheus(w io.Writer) error {
+	stats := s.Stats()
+
+	_, err := fmt.Fprintf(w, "# HELP lego_http01_requests_total The number of requests received by the HTTP-01 challenge server, by result.\n"+
+		"# TYPE lego_http01_requests_total counter\n"+
+		"lego_http01_requests_total{result=%q} %d\n"+
+		"lego_http01_requests_total{result=%q} %d\n"+
+		"lego_http01_requests_total{result=%q} %d\n"+
+		"lego_http01_requests_total{result=%q} %d\n",
+		RequestServed, stats.Served,
+		RequestRejected, stats.Rejected,
+		RequestNotFound, stats.NotFound,
+		RequestRateLimited, stats.RateLimited,
+	)
+
+	return err
+}
+
+// handler logs, counts, and rate limits the requests.
+func (s *ProviderServer) handler(domain string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteIP(r)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		var result string
+		if s.limiter != nil && !s.limiter.allow(ip, time.Now()) {
+			result = RequestRateLimited
+			http.Error(rec, "too many requests", http.StatusTooManyRequests)
+		} else {
+			rec.result = RequestNotFound
+			next.ServeHTTP(rec, r)
+			result = rec.result
+		}
+
+		s.counters.add(result)
+
+		req := ServerRequest{
+			Domain:    domain,
+			RemoteIP:  ip,
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			UserAgent: r.UserAgent(),
+			Status:    rec.status,
+			Result:    result,
+		}
+
+		if s.accessLog {
+			log.Infof("[%s] http-01: %s %s %s %d %s %q", domain, req.RemoteIP, req.Method, req.Path, req.Status, req.Result, req.UserAgent)
+		}
+
+		if s.onRequest != nil {
+			s.onRequest(req)
+		}
+	})
+}
+
+// setResult records the result of a request handled by the challenge handler.
+func setResult(w http.ResponseWriter, result string) {
+	if rec, ok := w.(*statusRecorder); ok {
+		rec.result = result
+	}
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	result string
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// rateLimiter limits the number of requests by source IP, in fixed windows.
+type rateLimiter struct {
+	limit  int
+	period time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func (l *rateLimiter) allow(ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.clients) >= maxRateLimitedClients {
+		for key, window := range l.clients {
+			if now.Sub(window.start) >= l.period {
+				delete(l.clients, key)
+			}
+		}
+	}
+
+	window, ok := l.clients[ip]
+	if !ok || now.Sub(window.start) >= l.period {
+		window = &rateWindow{start: now}
+		l.clients[ip] = window
+	}
+
+	window.count++
+
+	return window.count <= l.limit
+}
@@ -0,0 +1,80 @@
+package http01
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderServer_access(t *testing.T) {
+	server := NewProviderServer("127.0.0.1", "23458")
+	server.SetAccessLog(true)
+	server.SetRateLimit(3, time.Minute)
+
+	var requests []ServerRequest
+	server.OnRequest(func(req ServerRequest) {
+		requests = append(requests, req)
+	})
+
+	require.NoError(t, server.Present("localhost:23458", "token", "keyAuth"))
+
+	get := func(path string) int {
+		req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:23458"+path, nil)
+		require.NoError(t, err)
+
+		req.Host = "localhost:23458"
+		req.Header.Set("User-Agent", "scanner")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		_, _ = ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, get(ChallengePath("token")))
+	assert.Equal(t, http.StatusNotFound, get(ChallengePath("unknown")))
+	assert.Equal(t, http.StatusNotFound, get("/"))
+	assert.Equal(t, http.StatusTooManyRequests, get(ChallengePath("token")))
+
+	require.NoError(t, server.CleanUp("localhost:23458", "token", "keyAuth"))
+
+	expected := ServerStats{Requests: 4, Served: 1, NotFound: 2, RateLimited: 1}
+	assert.Equal(t, expected, server.Stats())
+
+	require.Len(t, requests, 4)
+	assert.Equal(t, ServerRequest{
+		Domain:    "localhost:23458",
+		RemoteIP:  "127.0.0.1",
+		Method:    http.MethodGet,
+		Path:      ChallengePath("token"),
+		UserAgent: "scanner",
+		Status:    http.StatusOK,
+		Result:    RequestServed,
+	}, requests[0])
+	assert.Equal(t, RequestRateLimited, requests[3].Result)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, server.WritePrometheus(buf))
+	assert.Contains(t, buf.String(), `lego_http01_requests_total{result="rate-limited"} 1`+"\n")
+}
+
+func Test_rateLimiter(t *testing.T) {
+	limiter := &rateLimiter{limit: 2, period: time.Minute, clients: make(map[string]*rateWindow)}
+
+	now := time.Now()
+
+	assert.True(t, limiter.allow("10.0.0.1", now))
+	assert.True(t, limiter.allow("10.0.0.1", now))
+	assert.False(t, limiter.allow("10.0.0.1", now))
+	assert.True(t, limiter.allow("10.0.0.2", now))
+
+	assert.True(t, limiter.allow("10.0.0.1", now.Add(time.Minute)))
+}
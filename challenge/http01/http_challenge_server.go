@@ -14,11 +14,18 @@ import (
 // It may be instantiated without using the NewProviderServer function if
 // you want only to use the default values.
 type ProviderServer struct {
+	// counters first: the 64-bit atomic operations require an aligned address on 32-bit platforms.
+	counters serverCounters
+
 	iface    string
 	port     string
 	matcher  domainMatcher
 	done     chan bool
 	listener net.Listener
+
+	accessLog bool
+	limiter   *rateLimiter
+	onRequest func(req ServerRequest)
 }
 
 // NewProviderServer creates a new ProviderServer on the selected interface and port.
@@ -97,8 +104,10 @@ func (s *ProviderServer) serve(domain, token, keyAuth string) {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
+			setResult(w, RequestServed)
 			log.Infof("[%s] Served key authentication", domain)
 		} else {
+			setResult(w, RequestRejected)
 			log.Warnf("Received request for domain %s with method %s but the domain did not match any challenge. Please ensure your are passing the %s header properly.", r.Host, r.Method, s.matcher.name())
 			_, err := w.Write([]byte("TEST"))
 			if err != nil {
@@ -108,7 +117,7 @@ func (s *ProviderServer) serve(domain, token, keyAuth string) {
 		}
 	})
 
-	httpServer := &http.Server{Handler: mux}
+	httpServer := &http.Server{Handler: s.handler(domain, mux)}
 
 	// Once httpServer is shut down
 	// we don't want any lingering connections, so disable KeepAlives.
@@ -0,0 +1,124 @@
+package http01
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-acme/lego/v3/challenge"
+)
+
+// ProbeFunc fetches the given URL, as seen from the internet, and returns the body of the response.
+type ProbeFunc func(rawURL string) (string, error)
+
+// DirectProbe fetches the URL from this host.
+// It detects the missing DNS records and the closed ports, but not the firewalls and NAT rules
+// which only apply to the traffic from the internet: it doesn't verify the reachability from the CA (see ReflectionProbe).
+func DirectProbe(client *http.Client) ProbeFunc {
+	return func(rawURL string) (string, error) {
+		return fetch(client, rawURL)
+	}
+}
+
+// ReflectionProbe fetches the URL through a reflection endpoint hosted outside of the network:
+// the endpoint receives the URL in the `url` query parameter, fetches it, and responds with the body it got.
+func ReflectionProbe(client *http.Client, endpoint string) ProbeFunc {
+	return func(rawURL string) (string, error) {
+		probeURL, err := url.Parse(endpoint)
+		if err != nil {
+			return "", fmt.Errorf("invalid reflection endpoint: %w", err)
+		}
+
+		query := probeURL.Query()
+		query.Set("url", rawURL)
+		probeURL.RawQuery = query.Encode()
+
+		return fetch(client, probeURL.String())
+	}
+}
+
+func fetch(client *http.Client, rawURL string) (string, error) {
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return string(body), nil
+}
+
+// Probe checks that the HTTP-01 challenge of the domain can be solved before placing an order:
+// a random test token is served by the provider, then fetched with probe.
+// The error explains the probable cause of the failure.
+func Probe(domain string, provider challenge.Provider, probe ProbeFunc) error {
+	token, keyAuth, err := newProbeToken()
+	if err != nil {
+		return err
+	}
+
+	err = provider.Present(domain, token, keyAuth)
+	if err != nil {
+		return fmt.Errorf("[%s] probe: could not serve the test token: %w", domain, err)
+	}
+	defer func() { _ = provider.CleanUp(domain, token, keyAuth) }()
+
+	challengeURL := (&url.URL{Scheme: "http", Host: domain, Path: ChallengePath(token)}).String()
+
+	body, err := probe(challengeURL)
+	if err != nil {
+		return fmt.Errorf("[%s] probe: %s is not reachable: %w%s", domain, challengeURL, err, probeHint(err))
+	}
+
+	if strings.TrimSpace(body) != keyAuth {
+		return fmt.Errorf("[%s] probe: %s doesn't serve the test token: "+
+			"another web server or a reverse proxy answers on the port 80 (see the webroot provider and --http.proxy-header)", domain, challengeURL)
+	}
+
+	return nil
+}
+
+func newProbeToken() (string, string, error) {
+	raw := make([]byte, 16)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := "lego-probe-" + hex.EncodeToString(raw)
+	return token, token + ".probe", nil
+}
+
+// probeHint suggests the probable cause of a network error.
+func probeHint(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return " (the domain doesn't resolve: check the A/AAAA records)"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return " (timeout: a firewall or a missing NAT/port forwarding rule probably drops the traffic to the port 80)"
+	}
+
+	if strings.Contains(err.Error(), "connection refused") {
+		return " (connection refused: nothing listens on the port 80, or the port forwarding points to the wrong host)"
+	}
+
+	return ""
+}
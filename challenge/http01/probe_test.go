@@ -0,0 +1,118 @@
+package http01
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryProvider struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (p *memoryProvider) Present(_, token, keyAuth string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokens[ChallengePath(token)] = keyAuth
+	return nil
+}
+
+func (p *memoryProvider) CleanUp(_, token, _ string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tokens, ChallengePath(token))
+	return nil
+}
+
+func (p *memoryProvider) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keyAuth, ok := p.tokens[req.URL.Path]
+	if !ok {
+		http.NotFound(rw, req)
+		return
+	}
+	_, _ = fmt.Fprint(rw, keyAuth)
+}
+
+func TestProbe(t *testing.T) {
+	provider := &memoryProvider{tokens: map[string]string{}}
+
+	server := httptest.NewServer(provider)
+	defer server.Close()
+
+	domain := strings.TrimPrefix(server.URL, "http://")
+
+	err := Probe(domain, provider, DirectProbe(server.Client()))
+	require.NoError(t, err)
+
+	assert.Empty(t, provider.tokens)
+}
+
+func TestProbe_wrongServer(t *testing.T) {
+	provider := &memoryProvider{tokens: map[string]string{}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(rw, "hello")
+	}))
+	defer server.Close()
+
+	domain := strings.TrimPrefix(server.URL, "http://")
+
+	err := Probe(domain, provider, DirectProbe(server.Client()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't serve the test token")
+}
+
+func TestProbe_unreachable(t *testing.T) {
+	provider := &memoryProvider{tokens: map[string]string{}}
+
+	server := httptest.NewServer(provider)
+	domain := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	err := Probe(domain, provider, DirectProbe(http.DefaultClient))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+}
+
+func TestReflectionProbe(t *testing.T) {
+	provider := &memoryProvider{tokens: map[string]string{}}
+
+	server := httptest.NewServer(provider)
+	defer server.Close()
+
+	reflection := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		target, err := url.Parse(req.URL.Query().Get("url"))
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp, err := http.Get(target.String())
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		_, _ = rw.Write(body)
+	}))
+	defer reflection.Close()
+
+	domain := strings.TrimPrefix(server.URL, "http://")
+
+	err := Probe(domain, provider, ReflectionProbe(reflection.Client(), reflection.URL+"/probe"))
+	require.NoError(t, err)
+}
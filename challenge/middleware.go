@@ -0,0 +1,357 @@
+package challenge
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v3/log"
+)
+
+// Middleware wraps a Provider to add a behavior to its calls (e.g. WithRetry).
+type Middleware func(Provider) Provider
+
+// Wrap wraps a provider with middlewares: the first middleware wraps the provider, the last one is the outermost.
+func Wrap(provider Provider, middlewares ...Middleware) Provider {
+	for _, middleware := range middlewares {
+		provider = middleware(provider)
+	}
+	return provider
+}
+
+// Unwrap returns the provider wrapped by a middleware, or the provider itself.
+func Unwrap(provider Provider) Provider {
+	for {
+		wrapper, ok := provider.(interface{ Unwrap() Provider })
+		if !ok {
+			return provider
+		}
+		provider = wrapper.Unwrap()
+	}
+}
+
+// WithRetry retries the failed calls of a provider, at most attempts times in total,
+// waiting delay before the first retry, and doubling it before each of the next ones.
+func WithRetry(provider Provider, attempts int, delay time.Duration) Provider {
+	return wrapProvider(provider, &retryMiddleware{attempts: attempts, delay: delay})
+}
+
+// WithLogging logs the calls of a provider, their durations, and their errors.
+func WithLogging(provider Provider) Provider {
+	return wrapProvider(provider, &loggingMiddleware{provider: provider})
+}
+
+// WithDryRun logs the calls of a provider instead of doing them: no record is created nor removed.
+// The challenges can't be validated, it is meant to check a configuration.
+// The records are still listed (ProviderRecordLister), but not deleted.
+func WithDryRun(provider Provider) Provider {
+	return wrapProvider(provider, &dryRunMiddleware{provider: provider})
+}
+
+// WithTimeout limits the duration of each call of a provider (e.g. an unresponsive API).
+// The call is abandoned, not canceled: the providers don't support the cancellation.
+// An abandoned Present which succeeds late is cleaned up,
+// unless another Present of the same challenge succeeded meanwhile (the provider can't tell the duplicated records apart).
+func WithTimeout(provider Provider, timeout time.Duration) Provider {
+	return wrapProvider(provider, &timeoutMiddleware{provider: provider, timeout: timeout, presented: make(map[string]bool)})
+}
+
+// middleware the behavior added to the calls of a provider.
+type middleware interface {
+	call(c call) error
+}
+
+// call a call of a provider.
+type call struct {
+	domain    string
+	operation string
+	// challenge identifies the challenge of Present and CleanUp (domain, token and key authorization),
+	// empty if the call can be told apart from the other calls of the same challenge (ProviderState).
+	challenge string
+	run       func() error
+	// undo reverts the call if it succeeds after being abandoned (see WithTimeout), nil if there is nothing to revert.
+	undo func() error
+}
+
+const (
+	operationPresent = "present"
+	operationCleanUp = "cleanup"
+	operationDelete  = "delete record"
+)
+
+// wrapped a provider wrapped by a middleware.
+// The optional interfaces of the provider (ProviderTimeout, ProviderState, ProviderRecordLister) are exposed by the wrapper,
+// their calls go through the middleware.
+type wrapped struct {
+	next       Provider
+	middleware middleware
+}
+
+func wrapProvider(next Provider, m middleware) Provider {
+	w := &wrapped{next: next, middleware: m}
+
+	_, timed := next.(ProviderTimeout)
+	_, stateful := next.(ProviderState)
+	_, lister := next.(ProviderRecordLister)
+
+	switch {
+	case timed && stateful && lister:
+		return struct {
+			*wrapped
+			timedWrapper
+			stateWrapper
+			listerWrapper
+		}{w, timedWrapper{w}, stateWrapper{w}, listerWrapper{w}}
+	case timed && stateful:
+		return struct {
+			*wrapped
+			timedWrapper
+			stateWrapper
+		}{w, timedWrapper{w}, stateWrapper{w}}
+	case timed && lister:
+		return struct {
+			*wrapped
+			timedWrapper
+			listerWrapper
+		}{w, timedWrapper{w}, listerWrapper{w}}
+	case stateful && lister:
+		return struct {
+			*wrapped
+			stateWrapper
+			listerWrapper
+		}{w, stateWrapper{w}, listerWrapper{w}}
+	case timed:
+		return struct {
+			*wrapped
+			timedWrapper
+		}{w, timedWrapper{w}}
+	case stateful:
+		return struct {
+			*wrapped
+			stateWrapper
+		}{w, stateWrapper{w}}
+	case lister:
+		return struct {
+			*wrapped
+			listerWrapper
+		}{w, listerWrapper{w}}
+	default:
+		return w
+	}
+}
+
+func (w *wrapped) Present(domain, token, keyAuth string) error {
+	return w.middleware.call(call{
+		domain:    domain,
+		operation: operationPresent,
+		challenge: challengeKey(domain, token, keyAuth),
+		run:       func() error { return w.next.Present(domain, token, keyAuth) },
+		undo:      func() error { return w.next.CleanUp(domain, token, keyAuth) },
+	})
+}
+
+func (w *wrapped) CleanUp(domain, token, keyAuth string) error {
+	return w.middleware.call(call{
+		domain:    domain,
+		operation: operationCleanUp,
+		challenge: challengeKey(domain, token, keyAuth),
+		run:       func() error { return w.next.CleanUp(domain, token, keyAuth) },
+	})
+}
+
+func (w *wrapped) Unwrap() Provider {
+	return w.next
+}
+
+// timedWrapper exposes the timeout of the wrapped provider (ProviderTimeout).
+type timedWrapper struct {
+	w *wrapped
+}
+
+func (t timedWrapper) Timeout() (timeout, interval time.Duration) {
+	return t.w.next.(ProviderTimeout).Timeout()
+}
+
+// stateWrapper exposes the states of the wrapped provider (ProviderState).
+type stateWrapper struct {
+	w *wrapped
+}
+
+func (s stateWrapper) PresentWithState(domain, token, keyAuth string) (interface{}, error) {
+	next := s.w.next.(ProviderState)
+
+	// the state is written by run, and read by the caller or by undo once run has returned.
+	var state interface{}
+
+	err := s.w.middleware.call(call{
+		domain:    domain,
+		operation: operationPresent,
+		run: func() error {
+			var err error
+			state, err = next.PresentWithState(domain, token, keyAuth)
+			return err
+		},
+		undo: func() error { return next.CleanUpWithState(domain, token, keyAuth, state) },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (s stateWrapper) CleanUpWithState(domain, token, keyAuth string, state interface{}) error {
+	next := s.w.next.(ProviderState)
+
+	return s.w.middleware.call(call{
+		domain:    domain,
+		operation: operationCleanUp,
+		run:       func() error { return next.CleanUpWithState(domain, token, keyAuth, state) },
+	})
+}
+
+// listerWrapper exposes the records of the wrapped provider (ProviderRecordLister).
+// The listing doesn't change the records: it is not affected by the middlewares.
+type listerWrapper struct {
+	w *wrapped
+}
+
+func (l listerWrapper) ListChallengeRecords() ([]Record, error) {
+	return l.w.next.(ProviderRecordLister).ListChallengeRecords()
+}
+
+func (l listerWrapper) DeleteChallengeRecord(record Record) error {
+	return l.w.middleware.call(call{
+		domain:    record.FQDN,
+		operation: operationDelete,
+		run:       func() error { return l.w.next.(ProviderRecordLister).DeleteChallengeRecord(record) },
+	})
+}
+
+func challengeKey(domain, token, keyAuth string) string {
+	return domain + "\x00" + token + "\x00" + keyAuth
+}
+
+type retryMiddleware struct {
+	attempts int
+	delay    time.Duration
+}
+
+func (m *retryMiddleware) call(c call) error {
+	delay := m.delay
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = c.run()
+		if err == nil || attempt >= m.attempts {
+			return err
+		}
+
+		log.Warnf("[%s] the provider failed to %s (attempt %d/%d), retrying in %s: %v", c.domain, c.operation, attempt, m.attempts, delay, err)
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+type loggingMiddleware struct {
+	provider Provider
+}
+
+func (m *loggingMiddleware) call(c call) error {
+	log.Infof("[%s] provider %T: %s", c.domain, Unwrap(m.provider), c.operation)
+
+	start := time.Now()
+	err := c.run()
+	duration := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		log.Warnf("[%s] provider %T: %s failed in %s: %v", c.domain, Unwrap(m.provider), c.operation, duration, err)
+		return err
+	}
+
+	log.Infof("[%s] provider %T: %s done in %s", c.domain, Unwrap(m.provider), c.operation, duration)
+
+	return nil
+}
+
+type dryRunMiddleware struct {
+	provider Provider
+}
+
+func (m *dryRunMiddleware) call(c call) error {
+	log.Infof("[%s] dry-run: provider %T: %s", c.domain, Unwrap(m.provider), c.operation)
+	return nil
+}
+
+type timeoutMiddleware struct {
+	provider Provider
+	timeout  time.Duration
+
+	mu sync.Mutex
+	// presented the challenges presented by a call which returned in time, and not cleaned up yet.
+	presented map[string]bool
+}
+
+func (m *timeoutMiddleware) call(c call) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.run() }()
+
+	timer := time.NewTimer(m.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-errCh:
+		m.track(c, err)
+		return err
+	case <-timer.C:
+		if c.undo != nil {
+			go m.undoLate(c, errCh)
+		}
+
+		return fmt.Errorf("provider %T: %s timed out after %s", Unwrap(m.provider), c.operation, m.timeout)
+	}
+}
+
+// track records the challenges presented and cleaned up by the calls which returned in time.
+func (m *timeoutMiddleware) track(c call, err error) {
+	if c.challenge == "" || err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch c.operation {
+	case operationPresent:
+		m.presented[c.challenge] = true
+	case operationCleanUp:
+		delete(m.presented, c.challenge)
+	}
+}
+
+// undoLate reverts an abandoned call if it succeeds late.
+func (m *timeoutMiddleware) undoLate(c call, errCh <-chan error) {
+	if <-errCh != nil {
+		return
+	}
+
+	if c.challenge != "" {
+		m.mu.Lock()
+		duplicate := m.presented[c.challenge]
+		m.mu.Unlock()
+
+		if duplicate {
+			log.Warnf("[%s] provider %T: the abandoned %s succeeded late, the duplicated record is left to the cleanup of the challenge",
+				c.domain, Unwrap(m.provider), c.operation)
+			return
+		}
+	}
+
+	log.Warnf("[%s] provider %T: the abandoned %s succeeded late, reverting it", c.domain, Unwrap(m.provider), c.operation)
+
+	err := c.undo()
+	if err != nil {
+		log.Warnf("[%s] provider %T: could not revert the abandoned %s: %v", c.domain, Unwrap(m.provider), c.operation, err)
+	}
+}
@@ -0,0 +1,205 @@
+package challenge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	failures int
+	calls    int
+	delay    time.Duration
+}
+
+func (p *countingProvider) Present(_, _, _ string) error {
+	p.calls++
+	time.Sleep(p.delay)
+	if p.calls <= p.failures {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func (p *countingProvider) CleanUp(_, _, _ string) error {
+	p.calls++
+	return nil
+}
+
+type timedProvider struct {
+	countingProvider
+}
+
+func (p *timedProvider) Timeout() (timeout, interval time.Duration) {
+	return 10 * time.Minute, 30 * time.Second
+}
+
+func TestWithRetry(t *testing.T) {
+	provider := &countingProvider{failures: 2}
+
+	err := WithRetry(provider, 3, time.Millisecond).Present("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+	assert.Equal(t, 3, provider.calls)
+
+	provider = &countingProvider{failures: 5}
+
+	err = WithRetry(provider, 3, time.Millisecond).Present("example.com", "token", "keyAuth")
+	require.EqualError(t, err, "boom")
+	assert.Equal(t, 3, provider.calls)
+}
+
+func TestWithDryRun(t *testing.T) {
+	provider := &countingProvider{failures: 1}
+
+	wrapped := WithDryRun(provider)
+
+	require.NoError(t, wrapped.Present("example.com", "token", "keyAuth"))
+	require.NoError(t, wrapped.CleanUp("example.com", "token", "keyAuth"))
+	assert.Equal(t, 0, provider.calls)
+}
+
+func TestWithTimeout(t *testing.T) {
+	provider := &countingProvider{delay: 100 * time.Millisecond}
+
+	err := WithTimeout(provider, 10*time.Millisecond).Present("example.com", "token", "keyAuth")
+	require.EqualError(t, err, "provider *challenge.countingProvider: present timed out after 10ms")
+
+	err = WithTimeout(&countingProvider{}, time.Second).Present("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+}
+
+// slowProvider a provider whose Present calls are released by the test.
+type slowProvider struct {
+	release chan struct{}
+	cleaned chan string
+}
+
+func (p *slowProvider) Present(_, token, _ string) error {
+	if token == "slow" {
+		<-p.release
+	}
+	return nil
+}
+
+func (p *slowProvider) CleanUp(_, token, _ string) error {
+	p.cleaned <- token
+	return nil
+}
+
+func TestWithTimeout_lateSuccess(t *testing.T) {
+	provider := &slowProvider{release: make(chan struct{}), cleaned: make(chan string, 1)}
+
+	wrapped := WithTimeout(provider, 10*time.Millisecond)
+
+	err := wrapped.Present("example.com", "slow", "keyAuth")
+	require.Error(t, err)
+
+	// the abandoned Present succeeds late: its record is cleaned up.
+	close(provider.release)
+
+	select {
+	case token := <-provider.cleaned:
+		assert.Equal(t, "slow", token)
+	case <-time.After(time.Second):
+		t.Fatal("the late Present was not cleaned up")
+	}
+}
+
+func TestWithTimeout_lateDuplicate(t *testing.T) {
+	provider := &slowProvider{release: make(chan struct{}), cleaned: make(chan string, 1)}
+
+	wrapped := WithTimeout(provider, 10*time.Millisecond).(*wrapped)
+	timeout := wrapped.middleware.(*timeoutMiddleware)
+
+	err := wrapped.Present("example.com", "slow", "keyAuth")
+	require.Error(t, err)
+
+	// a retry of the same challenge succeeds meanwhile.
+	timeout.track(call{operation: operationPresent, challenge: challengeKey("example.com", "slow", "keyAuth")}, nil)
+
+	close(provider.release)
+
+	// the late record is left to the cleanup of the challenge: cleaning it up would remove the record of the retry.
+	select {
+	case token := <-provider.cleaned:
+		t.Fatalf("the record of the retry was cleaned up: %s", token)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+type statefulProvider struct {
+	countingProvider
+	states []interface{}
+}
+
+func (p *statefulProvider) PresentWithState(_, token, _ string) (interface{}, error) {
+	return "state-" + token, nil
+}
+
+func (p *statefulProvider) CleanUpWithState(_, _, _ string, state interface{}) error {
+	p.states = append(p.states, state)
+	return nil
+}
+
+func (p *statefulProvider) ListChallengeRecords() ([]Record, error) {
+	return []Record{{FQDN: "_acme-challenge.example.com."}}, nil
+}
+
+func (p *statefulProvider) DeleteChallengeRecord(Record) error {
+	p.calls++
+	return nil
+}
+
+func TestWrap_optionalInterfaces(t *testing.T) {
+	provider := &statefulProvider{}
+
+	wrapped := Wrap(provider, WithLogging, func(p Provider) Provider { return WithTimeout(p, time.Second) })
+
+	stateful, ok := wrapped.(ProviderState)
+	require.True(t, ok)
+
+	state, err := stateful.PresentWithState("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+	assert.Equal(t, "state-token", state)
+
+	require.NoError(t, stateful.CleanUpWithState("example.com", "token", "keyAuth", state))
+	assert.Equal(t, []interface{}{"state-token"}, provider.states)
+
+	lister, ok := wrapped.(ProviderRecordLister)
+	require.True(t, ok)
+
+	records, err := lister.ListChallengeRecords()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	// the deletions are changes of the records: they go through the middlewares.
+	require.NoError(t, WithDryRun(wrapped).(ProviderRecordLister).DeleteChallengeRecord(records[0]))
+	assert.Equal(t, 0, provider.calls)
+
+	require.NoError(t, lister.DeleteChallengeRecord(records[0]))
+	assert.Equal(t, 1, provider.calls)
+
+	_, ok = Wrap(&countingProvider{}, WithLogging).(ProviderState)
+	assert.False(t, ok)
+}
+
+func TestWrap(t *testing.T) {
+	provider := &timedProvider{}
+
+	wrapped := Wrap(provider, WithLogging, func(p Provider) Provider { return WithRetry(p, 2, time.Millisecond) })
+
+	assert.Same(t, provider, Unwrap(wrapped))
+
+	timed, ok := wrapped.(ProviderTimeout)
+	require.True(t, ok)
+
+	timeout, interval := timed.Timeout()
+	assert.Equal(t, 10*time.Minute, timeout)
+	assert.Equal(t, 30*time.Second, interval)
+
+	_, ok = Wrap(&countingProvider{}, WithLogging).(ProviderTimeout)
+	assert.False(t, ok)
+}
@@ -0,0 +1,105 @@
+package onioncsr01
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+)
+
+var (
+	// https://cabforum.org/wp-content/uploads/CA-Browser-Forum-BR-1.7.0.pdf (Appendix B)
+	oidCASigningNonce        = asn1.ObjectIdentifier{2, 23, 140, 41}
+	oidApplicantSigningNonce = asn1.ObjectIdentifier{2, 23, 140, 42}
+
+	oidExtensionRequest      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 14}
+	oidSubjectAltName        = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidSignatureEd25519      = asn1.ObjectIdentifier{1, 3, 101, 112}
+	tagSubjectAltNameDNSName = 2
+)
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+type extension struct {
+	ID    asn1.ObjectIdentifier
+	Value []byte
+}
+
+type certificateRequestInfo struct {
+	Version    int
+	Subject    asn1.RawValue
+	PublicKey  asn1.RawValue
+	Attributes []attribute `asn1:"tag:0"`
+}
+
+type certificateRequest struct {
+	Info               asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+}
+
+// CreateCSR creates a DER encoded CSR signed by the Ed25519 key of the onion service,
+// with the signing nonces of the CA and of the applicant.
+// The attributes of the nonces cannot be created with the x509 package.
+func CreateCSR(key crypto.Signer, domain string, caNonce, applicantNonce []byte) ([]byte, error) {
+	subject, err := asn1.Marshal(pkix.Name{CommonName: domain}.ToRDNSequence())
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	san, err := asn1.Marshal([]asn1.RawValue{
+		{Class: asn1.ClassContextSpecific, Tag: tagSubjectAltNameDNSName, Bytes: []byte(domain)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	extensions, err := asn1.Marshal([]extension{{ID: oidSubjectAltName, Value: san}})
+	if err != nil {
+		return nil, err
+	}
+
+	caNonceValue, err := asn1.Marshal(caNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	applicantNonceValue, err := asn1.Marshal(applicantNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := asn1.Marshal(certificateRequestInfo{
+		Subject:   asn1.RawValue{FullBytes: subject},
+		PublicKey: asn1.RawValue{FullBytes: publicKey},
+		Attributes: []attribute{
+			{Type: oidExtensionRequest, Values: []asn1.RawValue{{FullBytes: extensions}}},
+			{Type: oidCASigningNonce, Values: []asn1.RawValue{{FullBytes: caNonceValue}}},
+			{Type: oidApplicantSigningNonce, Values: []asn1.RawValue{{FullBytes: applicantNonceValue}}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ed25519 signs the message itself, without hash.
+	signature, err := key.Sign(rand.Reader, info, crypto.Hash(0))
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(certificateRequest{
+		Info:               asn1.RawValue{FullBytes: info},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSignatureEd25519},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+}
@@ -0,0 +1,56 @@
+package onioncsr01
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCSR(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	domain := "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion"
+
+	raw, err := CreateCSR(key, domain, []byte("ca-nonce"), []byte("applicant-nonce"))
+	require.NoError(t, err)
+
+	csr, err := x509.ParseCertificateRequest(raw)
+	require.NoError(t, err)
+
+	require.NoError(t, csr.CheckSignature())
+	assert.Equal(t, x509.PureEd25519, csr.SignatureAlgorithm)
+	assert.Equal(t, key.Public(), csr.PublicKey)
+	assert.Equal(t, domain, csr.Subject.CommonName)
+	assert.Equal(t, []string{domain}, csr.DNSNames)
+
+	var info certificateRequestInfo
+	_, err = asn1.Unmarshal(csr.RawTBSCertificateRequest, &info)
+	require.NoError(t, err)
+
+	nonces := map[string]string{}
+	for _, attr := range info.Attributes {
+		if attr.Type.Equal(oidExtensionRequest) {
+			continue
+		}
+
+		require.Len(t, attr.Values, 1)
+
+		var nonce []byte
+		_, err = asn1.Unmarshal(attr.Values[0].FullBytes, &nonce)
+		require.NoError(t, err)
+
+		nonces[attr.Type.String()] = string(nonce)
+	}
+
+	expected := map[string]string{
+		oidCASigningNonce.String():        "ca-nonce",
+		oidApplicantSigningNonce.String(): "applicant-nonce",
+	}
+	assert.Equal(t, expected, nonces)
+}
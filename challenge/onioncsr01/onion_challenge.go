@@ -0,0 +1,74 @@
+package onioncsr01
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// applicantNonceSize the size of the applicant nonce (at least 64 bits of entropy are required).
+const applicantNonceSize = 16
+
+type ValidateFunc func(core *api.Core, domain string, chlng acme.Challenge, payload interface{}) error
+
+type Challenge struct {
+	core     *api.Core
+	validate ValidateFunc
+	key      crypto.Signer
+}
+
+// NewChallenge creates a solver of the "onion-csr-01" challenge,
+// the key is the Ed25519 key of the onion service.
+func NewChallenge(core *api.Core, validate ValidateFunc, key crypto.Signer) (*Challenge, error) {
+	if _, ok := key.Public().(ed25519.PublicKey); !ok {
+		return nil, fmt.Errorf("onion-csr-01: the key of an onion service must be an Ed25519 key, got %T", key.Public())
+	}
+
+	return &Challenge{
+		core:     core,
+		validate: validate,
+		key:      key,
+	}, nil
+}
+
+func (c *Challenge) Solve(authz acme.Authorization) error {
+	domain := challenge.GetTargetedDomain(authz)
+	log.Infof("[%s] acme: Trying to solve ONION-CSR-01", domain)
+
+	chlng, err := challenge.FindChallenge(challenge.OnionCSR01, authz)
+	if err != nil {
+		return err
+	}
+
+	caNonce, err := base64.RawURLEncoding.DecodeString(chlng.Nonce)
+	if err != nil {
+		return fmt.Errorf("[%s] acme: invalid nonce: %w", domain, err)
+	}
+
+	if len(caNonce) == 0 {
+		return fmt.Errorf("[%s] acme: missing nonce", domain)
+	}
+
+	applicantNonce := make([]byte, applicantNonceSize)
+	if _, err = rand.Read(applicantNonce); err != nil {
+		return err
+	}
+
+	csr, err := CreateCSR(c.key, authz.Identifier.Value, caNonce, applicantNonce)
+	if err != nil {
+		return fmt.Errorf("[%s] acme: %w", domain, err)
+	}
+
+	csrMsg := acme.CSRMessage{
+		Csr: base64.RawURLEncoding.EncodeToString(csr),
+	}
+
+	return c.validate(c.core, domain, chlng, csrMsg)
+}
@@ -0,0 +1,66 @@
+package onioncsr01
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallenge_Solve(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	domain := "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion"
+
+	validate := func(_ *api.Core, _ string, chlng acme.Challenge, payload interface{}) error {
+		assert.Equal(t, "https://example.com/chlg", chlng.URL)
+
+		csrMsg, ok := payload.(acme.CSRMessage)
+		require.True(t, ok)
+
+		raw, err := base64.RawURLEncoding.DecodeString(csrMsg.Csr)
+		require.NoError(t, err)
+
+		csr, err := x509.ParseCertificateRequest(raw)
+		require.NoError(t, err)
+
+		assert.NoError(t, csr.CheckSignature())
+		assert.Equal(t, []string{domain}, csr.DNSNames)
+
+		return nil
+	}
+
+	solver, err := NewChallenge(nil, validate, key)
+	require.NoError(t, err)
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Type: "dns", Value: domain},
+		Challenges: []acme.Challenge{
+			{
+				Type:  challenge.OnionCSR01.String(),
+				URL:   "https://example.com/chlg",
+				Nonce: base64.RawURLEncoding.EncodeToString([]byte("nonce")),
+			},
+		},
+	}
+
+	require.NoError(t, solver.Solve(authz))
+}
+
+func TestNewChallenge_invalidKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	_, err = NewChallenge(nil, nil, key)
+	require.Error(t, err)
+}
@@ -1,6 +1,9 @@
 package challenge
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Provider enables implementing a custom challenge
 // provider. Present presents the solution to a challenge available to
@@ -26,3 +29,56 @@ type ProviderTimeout interface {
 	Provider
 	Timeout() (timeout, interval time.Duration)
 }
+
+// ProviderPropagation allows for implementing a Provider able to report
+// the propagation of the DNS record through its own API (e.g. the status of a publication).
+// If a Provider provides a WaitForPropagation method, then it is used
+// by the dns-01 challenge instead of checking the DNS propagation through the nameservers.
+// The context is canceled when the timeout of the Provider (ProviderTimeout) expires.
+type ProviderPropagation interface {
+	Provider
+	WaitForPropagation(ctx context.Context, fqdn, value string) error
+}
+
+// Record is a TXT record of a DNS challenge, as listed by a ProviderRecordLister.
+type Record struct {
+	FQDN  string
+	Value string
+
+	// Zone and ID identify the record for the provider.
+	Zone string
+	ID   string
+
+	// Created is the creation time of the record, zero if the provider doesn't know it.
+	Created time.Time
+}
+
+// ProviderRecordLister allows for implementing a Provider able to list
+// the challenge records (the `_acme-challenge` TXT records) of all the zones it manages,
+// and to delete them. This is used to sweep the records left behind by failed cleanups.
+type ProviderRecordLister interface {
+	Provider
+	ListChallengeRecords() ([]Record, error)
+	DeleteChallengeRecord(record Record) error
+}
+
+// ProviderTLSAPublisher allows for implementing a Provider able to publish
+// the DANE TLSA records of a certificate (RFC 6698).
+// The records are the data of the TLSA records ("usage selector matching-type data"):
+// they replace all the TLSA records of the fqdn (e.g. `_443._tcp.example.com.`).
+type ProviderTLSAPublisher interface {
+	Provider
+	PublishTLSA(fqdn string, records []string) error
+}
+
+// ProviderState allows for implementing a Provider keeping no state between Present and CleanUp.
+// The state needed to clean the record up (e.g. the ID of the created record) is returned by PresentWithState,
+// kept by the dns-01 challenge for the token, and given back to CleanUpWithState.
+// If a Provider provides these methods, then they are used by the dns-01 challenge instead of Present and CleanUp.
+// Present and CleanUp are still used by the other callers (e.g. the providers wrapping another provider),
+// they can keep the states in a TokenStates.
+type ProviderState interface {
+	Provider
+	PresentWithState(domain, token, keyAuth string) (state interface{}, err error)
+	CleanUpWithState(domain, token, keyAuth string, state interface{}) error
+}
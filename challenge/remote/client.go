@@ -0,0 +1,122 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/providers/dns/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// callTimeout the maximum duration of a call to the agent (the DNS providers can be slow).
+const callTimeout = 5 * time.Minute
+
+// Client delegates the challenges to an agent.
+type Client struct {
+	conn   *grpc.ClientConn
+	client *plugin.DNSProviderClient
+}
+
+// Dial connects to the agent listening on the address (host:port).
+// The connection uses the TLS configuration (see ClientTLSConfig), or is not encrypted if it is nil.
+func Dial(ctx context.Context, address string, tlsConfig *tls.Config) (*Client, error) {
+	opt := grpc.WithInsecure()
+	if tlsConfig != nil {
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+
+	conn, err := grpc.DialContext(ctx, address, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn, client: plugin.NewDNSProviderClient(conn)}, nil
+}
+
+// Close closes the connection to the agent.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Provider returns a challenge.Provider presenting and cleaning up the challenges of a type (dns-01 or http-01) with the agent.
+func (c *Client) Provider(chlg challenge.Type) *Provider {
+	return &Provider{client: c, challenge: chlg}
+}
+
+// Provider a challenge.Provider delegating the challenges to an agent.
+type Provider struct {
+	client    *Client
+	challenge challenge.Type
+}
+
+// Present presents the challenge with the agent.
+func (p *Provider) Present(domain, token, keyAuth string) error {
+	return p.call("Present", domain, token, keyAuth)
+}
+
+// CleanUp cleans up the challenge with the agent.
+func (p *Provider) CleanUp(domain, token, keyAuth string) error {
+	return p.call("CleanUp", domain, token, keyAuth)
+}
+
+// Timeout returns the timeout and interval of the provider of the agent,
+// or the default values of the DNS challenge if the agent can't be reached.
+func (p *Provider) Timeout() (timeout, interval time.Duration) {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	resp, err := p.client.client.Timeout(ctx, &plugin.Empty{})
+	if err != nil {
+		log.Warnf("challenge agent: could not get the timeout of the %s provider, using the default values: %v", p.challenge, unwrapStatus(err))
+		return dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+	}
+
+	timeout = time.Duration(resp.TimeoutMs) * time.Millisecond
+	interval = time.Duration(resp.IntervalMs) * time.Millisecond
+	if timeout <= 0 || interval <= 0 {
+		return dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+	}
+
+	return timeout, interval
+}
+
+func (p *Provider) call(method, domain, token, keyAuth string) error {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	req := &plugin.ChallengeRequest{Domain: domain, Token: token, KeyAuth: keyAuth}
+
+	var err error
+	if method == "Present" {
+		_, err = p.client.client.Present(ctx, req)
+	} else {
+		_, err = p.client.client.CleanUp(ctx, req)
+	}
+	if err != nil {
+		return unwrapStatus(err)
+	}
+
+	return nil
+}
+
+// context returns the context of a call, holding the challenge type.
+func (p *Provider) context() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	return metadata.AppendToOutgoingContext(ctx, challengeMetadataKey, string(p.challenge)), cancel
+}
+
+// unwrapStatus returns the message of a gRPC error as a plain error.
+func unwrapStatus(err error) error {
+	if st, ok := status.FromError(err); ok {
+		return errors.New("challenge agent: " + st.Message())
+	}
+	return err
+}
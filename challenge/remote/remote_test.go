@@ -0,0 +1,189 @@
+package remote
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/providers/dns/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type httpProvider struct {
+	presented map[string]string
+}
+
+func (p *httpProvider) Present(domain, token, keyAuth string) error {
+	p.presented[token] = keyAuth
+	return nil
+}
+
+func (p *httpProvider) CleanUp(domain, token, keyAuth string) error {
+	delete(p.presented, token)
+	return nil
+}
+
+func setupAgent(t *testing.T, providers map[challenge.Type]challenge.Provider, serverTLS, clientTLS *tls.Config) *Client {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewServer(providers, serverTLS)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	client, err := Dial(context.Background(), listener.Addr().String(), clientTLS)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func TestProvider(t *testing.T) {
+	dnsProvider := fake.NewProvider(fake.WithTimeout(3*time.Minute, 10*time.Second))
+	webProvider := &httpProvider{presented: map[string]string{}}
+
+	client := setupAgent(t, map[challenge.Type]challenge.Provider{
+		challenge.DNS01:  dnsProvider,
+		challenge.HTTP01: webProvider,
+	}, nil, nil)
+
+	dns := client.Provider(challenge.DNS01)
+
+	require.NoError(t, dns.Present("example.com", "token", "keyAuth"))
+
+	records := dnsProvider.Records()
+	require.Len(t, records, 1)
+	assert.Equal(t, "_acme-challenge.example.com.", records[0].FQDN)
+	assert.Equal(t, "keyAuth", records[0].KeyAuth)
+
+	require.NoError(t, dns.CleanUp("example.com", "token", "keyAuth"))
+	assert.Empty(t, dnsProvider.Records())
+
+	timeout, interval := dns.Timeout()
+	assert.Equal(t, 3*time.Minute, timeout)
+	assert.Equal(t, 10*time.Second, interval)
+
+	web := client.Provider(challenge.HTTP01)
+
+	require.NoError(t, web.Present("example.org", "token2", "keyAuth2"))
+	assert.Equal(t, map[string]string{"token2": "keyAuth2"}, webProvider.presented)
+
+	timeout, interval = web.Timeout()
+	assert.Equal(t, dns01.DefaultPropagationTimeout, timeout)
+	assert.Equal(t, dns01.DefaultPollingInterval, interval)
+}
+
+func TestProvider_errors(t *testing.T) {
+	dnsProvider := fake.NewProvider(fake.WithFailures(fake.MethodPresent, errors.New("zone not found")))
+
+	client := setupAgent(t, map[challenge.Type]challenge.Provider{challenge.DNS01: dnsProvider}, nil, nil)
+
+	err := client.Provider(challenge.DNS01).Present("example.com", "token", "keyAuth")
+	require.EqualError(t, err, "challenge agent: [example.com] zone not found")
+
+	err = client.Provider(challenge.DNS01).Present("", "token", "keyAuth")
+	require.EqualError(t, err, "challenge agent: the domain is required")
+
+	err = client.Provider(challenge.HTTP01).Present("example.com", "token", "keyAuth")
+	require.EqualError(t, err, "challenge agent: no provider for the http-01 challenge")
+
+	timeout, interval := client.Provider(challenge.HTTP01).Timeout()
+	assert.Equal(t, dns01.DefaultPropagationTimeout, timeout)
+	assert.Equal(t, dns01.DefaultPollingInterval, interval)
+}
+
+func TestProvider_mutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-remote")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	ca, caKey := writeCertificate(t, dir, "ca", nil, nil)
+	writeCertificate(t, dir, "agent", ca, caKey)
+	writeCertificate(t, dir, "orchestrator", ca, caKey)
+
+	serverTLS, err := ServerTLSConfig(filepath.Join(dir, "agent.crt"), filepath.Join(dir, "agent.key"), filepath.Join(dir, "ca.crt"))
+	require.NoError(t, err)
+
+	clientTLS, err := ClientTLSConfig(filepath.Join(dir, "orchestrator.crt"), filepath.Join(dir, "orchestrator.key"), filepath.Join(dir, "ca.crt"))
+	require.NoError(t, err)
+
+	dnsProvider := fake.NewProvider()
+	providers := map[challenge.Type]challenge.Provider{challenge.DNS01: dnsProvider}
+
+	client := setupAgent(t, providers, serverTLS, clientTLS)
+
+	require.NoError(t, client.Provider(challenge.DNS01).Present("example.com", "token", "keyAuth"))
+	assert.Len(t, dnsProvider.Records(), 1)
+
+	// a client without certificate is rejected.
+	anonymous := setupAgent(t, providers, serverTLS, &tls.Config{RootCAs: clientTLS.RootCAs})
+
+	err = anonymous.Provider(challenge.DNS01).Present("example.com", "token", "keyAuth")
+	require.Error(t, err)
+	assert.Len(t, dnsProvider.Records(), 1)
+}
+
+func TestServerTLSConfig_missingFiles(t *testing.T) {
+	_, err := ServerTLSConfig("agent.crt", "agent.key", "")
+	require.EqualError(t, err, "the certificate, the private key, and the CA certificate are required")
+}
+
+// writeCertificate writes a certificate (<name>.crt) and its private key (<name>.key), self-signed if there is no parent.
+func writeCertificate(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
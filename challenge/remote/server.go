@@ -0,0 +1,155 @@
+// Package remote splits the solving of the challenges from the ACME operations:
+// an agent (Server), running next to the DNS credentials or the webroot, presents and cleans up the challenges
+// requested by an orchestrator (Client) over gRPC, so the credentials never leave the host of the agent,
+// and the account key never leaves the orchestrator.
+//
+// The agent serves the DNSProvider service of the DNS plugins (providers/dns/plugin/provider.proto),
+// the challenge type (dns-01 or http-01) of each request is sent in the metadata lego-challenge.
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/providers/dns/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// challengeMetadataKey the gRPC metadata holding the challenge type of a request.
+const challengeMetadataKey = "lego-challenge"
+
+// Server presents and cleans up the challenges with its local providers.
+type Server struct {
+	providers map[challenge.Type]challenge.Provider
+	server    *grpc.Server
+}
+
+// NewServer creates an agent serving the providers of the challenge types.
+// The connections use the TLS configuration (see ServerTLSConfig), or are not encrypted if it is nil.
+func NewServer(providers map[challenge.Type]challenge.Provider, tlsConfig *tls.Config) *Server {
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := &Server{
+		providers: providers,
+		server:    grpc.NewServer(opts...),
+	}
+
+	plugin.RegisterDNSProviderServer(s.server, s)
+
+	return s
+}
+
+// Serve accepts the connections on the listener, it returns when the server is stopped.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+// Stop stops the server.
+func (s *Server) Stop() {
+	s.server.Stop()
+}
+
+// Present implements the Present method of the DNSProvider service.
+func (s *Server) Present(ctx context.Context, req *plugin.ChallengeRequest) (*plugin.Empty, error) {
+	chlg, provider, err := s.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Domain == "" {
+		return nil, status.Error(codes.InvalidArgument, "the domain is required")
+	}
+
+	log.Infof("[%s] challenge-agent: presenting the %s challenge", req.Domain, chlg)
+
+	err = observeDNSCall(chlg, req.Domain, provider, dns01.OperationPresent, func() error {
+		return provider.Present(req.Domain, req.Token, req.KeyAuth)
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Unknown, "[%s] %v", req.Domain, err)
+	}
+
+	return &plugin.Empty{}, nil
+}
+
+// CleanUp implements the CleanUp method of the DNSProvider service.
+func (s *Server) CleanUp(ctx context.Context, req *plugin.ChallengeRequest) (*plugin.Empty, error) {
+	chlg, provider, err := s.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Domain == "" {
+		return nil, status.Error(codes.InvalidArgument, "the domain is required")
+	}
+
+	log.Infof("[%s] challenge-agent: cleaning up the %s challenge", req.Domain, chlg)
+
+	err = observeDNSCall(chlg, req.Domain, provider, dns01.OperationCleanUp, func() error {
+		return provider.CleanUp(req.Domain, req.Token, req.KeyAuth)
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Unknown, "[%s] %v", req.Domain, err)
+	}
+
+	return &plugin.Empty{}, nil
+}
+
+// observeDNSCall reports the calls of the DNS providers to their observers (see dns01.ObserveProviderCall).
+func observeDNSCall(chlg challenge.Type, domain string, provider challenge.Provider, operation string, call func() error) error {
+	if chlg != challenge.DNS01 {
+		return call()
+	}
+
+	return dns01.ObserveProviderCall(provider, operation, domain, call)
+}
+
+// Timeout implements the Timeout method of the DNSProvider service.
+func (s *Server) Timeout(ctx context.Context, _ *plugin.Empty) (*plugin.TimeoutResponse, error) {
+	_, provider, err := s.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, ok := provider.(challenge.ProviderTimeout)
+	if !ok {
+		return &plugin.TimeoutResponse{}, nil
+	}
+
+	timeout, interval := pt.Timeout()
+
+	return &plugin.TimeoutResponse{
+		TimeoutMs:  timeout.Milliseconds(),
+		IntervalMs: interval.Milliseconds(),
+	}, nil
+}
+
+// provider returns the provider of the challenge type of a request.
+func (s *Server) provider(ctx context.Context) (challenge.Type, challenge.Provider, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(challengeMetadataKey)
+	if len(values) != 1 {
+		return "", nil, status.Error(codes.InvalidArgument, "the challenge type is required")
+	}
+
+	chlg := challenge.Type(values[0])
+
+	provider, ok := s.providers[chlg]
+	if !ok {
+		return "", nil, status.Errorf(codes.Unimplemented, "no provider for the %s challenge", chlg)
+	}
+
+	return chlg, provider, nil
+}
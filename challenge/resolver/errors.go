@@ -23,3 +23,28 @@ func (e obtainError) Error() string {
 	}
 	return buffer.String()
 }
+
+// Errors returns the errors of the domains, sorted by domain.
+func (e obtainError) Errors() []error {
+	var domains []string
+	for domain := range e {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	var errs []error
+	for _, domain := range domains {
+		errs = append(errs, e[domain])
+	}
+	return errs
+}
+
+// Domains returns the domains which had a problem.
+func (e obtainError) Domains() []string {
+	var domains []string
+	for domain := range e {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
@@ -7,8 +7,12 @@ import (
 	"github.com/go-acme/lego/v3/acme"
 	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/clock"
 )
 
+// clk is used to wait between sequential challenges.
+var clk = clock.Real
+
 // Interface for all challenge solvers to implement.
 type solver interface {
 	Solve(authorization acme.Authorization) error
@@ -31,8 +35,9 @@ type sequential interface {
 
 // an authz with the solver we have chosen and the index of the challenge associated with it
 type selectedAuthSolver struct {
-	authz  acme.Authorization
-	solver solver
+	authz    acme.Authorization
+	solver   solver
+	chlgType challenge.Type
 }
 
 type Prober struct {
@@ -45,6 +50,11 @@ func NewProber(solverManager *SolverManager) *Prober {
 	}
 }
 
+// Subscribe adds a handler receiving the lifecycle events of the challenges until the returned function is called.
+func (p *Prober) Subscribe(handler challenge.EventHandler) func() {
+	return p.solverManager.Subscribe(handler)
+}
+
 // Solve Looks through the challenge combinations to find a solvable match.
 // Then solves the challenges in series and returns.
 func (p *Prober) Solve(authorizations []acme.Authorization) error {
@@ -59,12 +69,16 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 		domain := challenge.GetTargetedDomain(authz)
 		if authz.Status == acme.StatusValid {
 			// Boulder might recycle recent validated authz (see issue #267)
-			log.Infof("[%s] acme: authorization already valid; skipping challenge", domain)
+			if authz.Expires.IsZero() {
+				log.Infof("[%s] acme: authorization already valid; skipping challenge", domain)
+			} else {
+				log.Infof("[%s] acme: authorization already valid until %s; skipping challenge", domain, authz.Expires.Format(time.RFC3339))
+			}
 			continue
 		}
 
-		if solvr := p.solverManager.chooseSolver(authz); solvr != nil {
-			authSolver := &selectedAuthSolver{authz: authz, solver: solvr}
+		if chlgType, solvr := p.solverManager.selectSolver(authz); solvr != nil {
+			authSolver := &selectedAuthSolver{authz: authz, solver: solvr, chlgType: chlgType}
 
 			switch s := solvr.(type) {
 			case sequential:
@@ -81,9 +95,9 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 		}
 	}
 
-	parallelSolve(authSolvers, failures)
+	p.parallelSolve(authSolvers, failures)
 
-	sequentialSolve(authSolversSequential, failures)
+	p.sequentialSolve(authSolversSequential, failures)
 
 	// Be careful not to return an empty failures map,
 	// for even an empty obtainError is a non-nil error value
@@ -93,7 +107,7 @@ func (p *Prober) Solve(authorizations []acme.Authorization) error {
 	return nil
 }
 
-func sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
+func (p *Prober) sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 	for i, authSolver := range authSolvers {
 		// Submit the challenge
 		domain := challenge.GetTargetedDomain(authSolver.authz)
@@ -101,48 +115,53 @@ func sequentialSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 		if solvr, ok := authSolver.solver.(preSolver); ok {
 			err := solvr.PreSolve(authSolver.authz)
 			if err != nil {
-				failures[domain] = err
-				cleanUp(authSolver.solver, authSolver.authz)
+				p.fail(authSolver, failures, err)
+				p.cleanUp(authSolver)
 				continue
 			}
+
+			p.solverManager.emit(challenge.EventPresented, domain, authSolver.chlgType, nil)
 		}
 
 		// Solve challenge
 		err := authSolver.solver.Solve(authSolver.authz)
 		if err != nil {
-			failures[domain] = err
-			cleanUp(authSolver.solver, authSolver.authz)
+			p.fail(authSolver, failures, err)
+			p.cleanUp(authSolver)
 			continue
 		}
 
 		// Clean challenge
-		cleanUp(authSolver.solver, authSolver.authz)
+		p.cleanUp(authSolver)
 
 		if len(authSolvers)-1 > i {
 			solvr := authSolver.solver.(sequential)
 			_, interval := solvr.Sequential()
 			log.Infof("sequence: wait for %s", interval)
-			time.Sleep(interval)
+			clk.Sleep(interval)
 		}
 	}
 }
 
-func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
+func (p *Prober) parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 	// For all valid preSolvers, first submit the challenges so they have max time to propagate
 	for _, authSolver := range authSolvers {
 		authz := authSolver.authz
 		if solvr, ok := authSolver.solver.(preSolver); ok {
 			err := solvr.PreSolve(authz)
 			if err != nil {
-				failures[challenge.GetTargetedDomain(authz)] = err
+				p.fail(authSolver, failures, err)
+				continue
 			}
+
+			p.solverManager.emit(challenge.EventPresented, challenge.GetTargetedDomain(authz), authSolver.chlgType, nil)
 		}
 	}
 
 	defer func() {
 		// Clean all created TXT records
 		for _, authSolver := range authSolvers {
-			cleanUp(authSolver.solver, authSolver.authz)
+			p.cleanUp(authSolver)
 		}
 	}()
 
@@ -157,17 +176,27 @@ func parallelSolve(authSolvers []*selectedAuthSolver, failures obtainError) {
 
 		err := authSolver.solver.Solve(authz)
 		if err != nil {
-			failures[domain] = err
+			p.fail(authSolver, failures, err)
 		}
 	}
 }
 
-func cleanUp(solvr solver, authz acme.Authorization) {
-	if solvr, ok := solvr.(cleanup); ok {
-		domain := challenge.GetTargetedDomain(authz)
-		err := solvr.CleanUp(authz)
+// fail records the failure of the challenge of an authorization.
+func (p *Prober) fail(authSolver *selectedAuthSolver, failures obtainError, err error) {
+	domain := challenge.GetTargetedDomain(authSolver.authz)
+
+	failures[domain] = err
+	p.solverManager.emit(challenge.EventFailed, domain, authSolver.chlgType, err)
+}
+
+func (p *Prober) cleanUp(authSolver *selectedAuthSolver) {
+	if solvr, ok := authSolver.solver.(cleanup); ok {
+		domain := challenge.GetTargetedDomain(authSolver.authz)
+		err := solvr.CleanUp(authSolver.authz)
 		if err != nil {
 			log.Warnf("[%s] acme: cleaning up failed: %v ", domain, err)
 		}
+
+		p.solverManager.emit(challenge.EventCleaned, domain, authSolver.chlgType, err)
 	}
 }
@@ -2,10 +2,12 @@ package resolver
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/go-acme/lego/v3/acme"
 	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -116,3 +118,74 @@ func TestProber_Solve(t *testing.T) {
 		})
 	}
 }
+
+func TestProber_Solve_events(t *testing.T) {
+	var events []string
+
+	manager := &SolverManager{
+		solvers: map[challenge.Type]solver{
+			challenge.HTTP01: &preSolverMock{
+				preSolve: map[string]error{
+					"acme.wtf": errors.New("preSolve error acme.wtf"),
+				},
+				solve: map[string]error{},
+				cleanUp: map[string]error{
+					"lego.wtf": errors.New("clean error lego.wtf"),
+				},
+			},
+		},
+	}
+	manager.SetEventHandler(func(event challenge.Event) {
+		events = append(events, fmt.Sprintf("%s %s %s %v", event.Domain, event.Challenge, event.Type, event.Err))
+	})
+
+	prober := &Prober{solverManager: manager}
+
+	err := prober.Solve([]acme.Authorization{
+		createStubAuthorizationHTTP01("acme.wtf", acme.StatusProcessing),
+		createStubAuthorizationHTTP01("lego.wtf", acme.StatusProcessing),
+	})
+	require.Error(t, err)
+
+	expected := []string{
+		"acme.wtf http-01 failed preSolve error acme.wtf",
+		"lego.wtf http-01 presented <nil>",
+		"acme.wtf http-01 cleaned <nil>",
+		"lego.wtf http-01 cleaned clean error lego.wtf",
+	}
+	assert.Equal(t, expected, events)
+}
+
+func TestProber_Subscribe(t *testing.T) {
+	var handled, subscribed []string
+
+	manager := &SolverManager{
+		solvers: map[challenge.Type]solver{
+			challenge.HTTP01: &preSolverMock{
+				preSolve: map[string]error{},
+				solve:    map[string]error{},
+				cleanUp:  map[string]error{},
+			},
+		},
+	}
+	manager.SetEventHandler(func(event challenge.Event) {
+		handled = append(handled, fmt.Sprintf("%s %s", event.Domain, event.Type))
+	})
+
+	prober := &Prober{solverManager: manager}
+
+	unsubscribe := prober.Subscribe(func(event challenge.Event) {
+		subscribed = append(subscribed, fmt.Sprintf("%s %s", event.Domain, event.Type))
+	})
+
+	err := prober.Solve([]acme.Authorization{createStubAuthorizationHTTP01("acme.wtf", acme.StatusProcessing)})
+	require.NoError(t, err)
+
+	unsubscribe()
+
+	err = prober.Solve([]acme.Authorization{createStubAuthorizationHTTP01("lego.wtf", acme.StatusProcessing)})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"acme.wtf presented", "acme.wtf cleaned"}, subscribed)
+	assert.Len(t, handled, 4)
+}
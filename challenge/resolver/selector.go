@@ -0,0 +1,42 @@
+package resolver
+
+import (
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// ChallengeSelector returns the challenge types to use for an authorization, by order of preference.
+type ChallengeSelector func(authz acme.Authorization) []challenge.Type
+
+// ReachabilityProbe checks that the HTTP-01 challenge of a domain can be validated by the CA,
+// e.g. http01.Probe through a reflection endpoint hosted outside of the network.
+type ReachabilityProbe func(domain string) error
+
+// NewAutoSelector returns a ChallengeSelector choosing the challenge type of each identifier:
+// the wildcards use DNS-01, the domains passing the probe use HTTP-01, the other domains use DNS-01.
+// TLS-ALPN-01 is not probed (the TLS server isn't listening before the challenge is presented):
+// it follows HTTP-01 or DNS-01, as fallback when it is the only challenge offered or enabled.
+func NewAutoSelector(probe ReachabilityProbe) ChallengeSelector {
+	return func(authz acme.Authorization) []challenge.Type {
+		if authz.Wildcard {
+			return []challenge.Type{challenge.DNS01}
+		}
+
+		err := probe(authz.Identifier.Value)
+		if err == nil {
+			return []challenge.Type{challenge.HTTP01, challenge.TLSALPN01, challenge.DNS01}
+		}
+
+		log.Infof("[%s] the HTTP challenge is not reachable, using the DNS challenge: %v", authz.Identifier.Value, err)
+
+		return []challenge.Type{challenge.DNS01, challenge.TLSALPN01, challenge.HTTP01}
+	}
+}
+
+// NewOrderSelector returns a ChallengeSelector using the same order of preference of the challenge types for all the identifiers.
+func NewOrderSelector(types ...challenge.Type) ChallengeSelector {
+	return func(acme.Authorization) []challenge.Type {
+		return types
+	}
+}
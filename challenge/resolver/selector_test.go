@@ -0,0 +1,115 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAutoSelector(t *testing.T) {
+	probe := func(domain string) error {
+		if domain != "reachable.com" {
+			return errors.New("unreachable")
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		desc     string
+		authz    acme.Authorization
+		expected []challenge.Type
+	}{
+		{
+			desc:     "wildcard",
+			authz:    acme.Authorization{Identifier: acme.Identifier{Value: "reachable.com"}, Wildcard: true},
+			expected: []challenge.Type{challenge.DNS01},
+		},
+		{
+			desc:     "reachable",
+			authz:    acme.Authorization{Identifier: acme.Identifier{Value: "reachable.com"}},
+			expected: []challenge.Type{challenge.HTTP01, challenge.TLSALPN01, challenge.DNS01},
+		},
+		{
+			desc:     "unreachable",
+			authz:    acme.Authorization{Identifier: acme.Identifier{Value: "unreachable.com"}},
+			expected: []challenge.Type{challenge.DNS01, challenge.TLSALPN01, challenge.HTTP01},
+		},
+	}
+
+	selector := NewAutoSelector(probe)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, selector(test.authz))
+		})
+	}
+}
+
+func TestSolverManager_chooseSolver_order(t *testing.T) {
+	httpSolver := &preSolverMock{}
+	tlsSolver := &preSolverMock{}
+	dnsSolver := &preSolverMock{}
+
+	manager := &SolverManager{
+		solvers: map[challenge.Type]solver{
+			challenge.HTTP01:    httpSolver,
+			challenge.TLSALPN01: tlsSolver,
+			challenge.DNS01:     dnsSolver,
+		},
+	}
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: "http-01"}, {Type: "dns-01"}, {Type: "tls-alpn-01"}},
+	}
+
+	assert.Same(t, tlsSolver, manager.chooseSolver(authz))
+
+	manager.SetChallengeSelector(NewOrderSelector(challenge.HTTP01, challenge.TLSALPN01, challenge.DNS01))
+
+	assert.Same(t, httpSolver, manager.chooseSolver(authz))
+
+	// the wildcards are only offered the DNS challenge.
+	wildcard := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Wildcard:   true,
+		Challenges: []acme.Challenge{{Type: "dns-01"}},
+	}
+
+	assert.Same(t, dnsSolver, manager.chooseSolver(wildcard))
+}
+
+func TestSolverManager_chooseSolver_selector(t *testing.T) {
+	httpSolver := &preSolverMock{}
+	dnsSolver := &preSolverMock{}
+
+	manager := &SolverManager{
+		solvers: map[challenge.Type]solver{
+			challenge.HTTP01: httpSolver,
+			challenge.DNS01:  dnsSolver,
+		},
+	}
+
+	authz := acme.Authorization{
+		Identifier: acme.Identifier{Value: "example.com"},
+		Challenges: []acme.Challenge{{Type: "http-01"}, {Type: "dns-01"}},
+	}
+
+	assert.Same(t, httpSolver, manager.chooseSolver(authz))
+
+	manager.SetChallengeSelector(func(acme.Authorization) []challenge.Type {
+		return []challenge.Type{challenge.TLSALPN01, challenge.DNS01}
+	})
+
+	assert.Same(t, dnsSolver, manager.chooseSolver(authz))
+
+	manager.SetChallengeSelector(func(acme.Authorization) []challenge.Type {
+		return []challenge.Type{challenge.TLSALPN01}
+	})
+
+	assert.Same(t, httpSolver, manager.chooseSolver(authz))
+}
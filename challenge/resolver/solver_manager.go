@@ -2,18 +2,22 @@ package resolver
 
 import (
 	"context"
+	"crypto"
 	"errors"
 	"fmt"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/go-acme/lego/v3/acme"
 	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/challenge/dns01"
 	"github.com/go-acme/lego/v3/challenge/http01"
+	"github.com/go-acme/lego/v3/challenge/onioncsr01"
 	"github.com/go-acme/lego/v3/challenge/tlsalpn01"
 	"github.com/go-acme/lego/v3/log"
 )
@@ -25,8 +29,15 @@ func (a byType) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byType) Less(i, j int) bool { return a[i].Type > a[j].Type }
 
 type SolverManager struct {
-	core    *api.Core
-	solvers map[challenge.Type]solver
+	core         *api.Core
+	solvers      map[challenge.Type]solver
+	selector     ChallengeSelector
+	eventHandler challenge.EventHandler
+
+	// listeners receive the events in addition to the eventHandler, see Subscribe.
+	listeners   map[int]challenge.EventHandler
+	listenerID  int
+	listenersMu sync.Mutex
 }
 
 func NewSolversManager(core *api.Core) *SolverManager {
@@ -38,22 +49,99 @@ func NewSolversManager(core *api.Core) *SolverManager {
 
 // SetHTTP01Provider specifies a custom provider p that can solve the given HTTP-01 challenge.
 func (c *SolverManager) SetHTTP01Provider(p challenge.Provider) error {
-	c.solvers[challenge.HTTP01] = http01.NewChallenge(c.core, validate, p)
+	c.solvers[challenge.HTTP01] = http01.NewChallenge(c.core, c.validate, p)
 	return nil
 }
 
 // SetTLSALPN01Provider specifies a custom provider p that can solve the given TLS-ALPN-01 challenge.
 func (c *SolverManager) SetTLSALPN01Provider(p challenge.Provider) error {
-	c.solvers[challenge.TLSALPN01] = tlsalpn01.NewChallenge(c.core, validate, p)
+	c.solvers[challenge.TLSALPN01] = tlsalpn01.NewChallenge(c.core, c.validate, p)
 	return nil
 }
 
 // SetDNS01Provider specifies a custom provider p that can solve the given DNS-01 challenge.
 func (c *SolverManager) SetDNS01Provider(p challenge.Provider, opts ...dns01.ChallengeOption) error {
-	c.solvers[challenge.DNS01] = dns01.NewChallenge(c.core, validate, p, opts...)
+	onPropagation := dns01.OnPropagation(func(domain string) {
+		c.emit(challenge.EventPropagated, domain, challenge.DNS01, nil)
+	})
+
+	c.solvers[challenge.DNS01] = dns01.NewChallenge(c.core, c.validate, p, append([]dns01.ChallengeOption{onPropagation}, opts...)...)
+	return nil
+}
+
+// SetChallengeSelector specifies how to choose the challenge type of each authorization.
+// The challenge types which are not selected are used as fallback, in the default order.
+func (c *SolverManager) SetChallengeSelector(selector ChallengeSelector) {
+	c.selector = selector
+}
+
+// SetOnionCSR01Provider specifies the key of the onion service used to solve the given ONION-CSR-01 challenge.
+// The Ed25519 keys of the onion services can't be used in FIPS mode.
+func (c *SolverManager) SetOnionCSR01Provider(key crypto.Signer) error {
+	if err := certcrypto.CheckFIPSKey(key); err != nil {
+		return fmt.Errorf("the key of the onion service: %w", err)
+	}
+
+	chlg, err := onioncsr01.NewChallenge(c.core, c.validateWithPayload, key)
+	if err != nil {
+		return err
+	}
+
+	c.solvers[challenge.OnionCSR01] = chlg
 	return nil
 }
 
+// SetEventHandler specifies the handler receiving the lifecycle events of the challenges.
+func (c *SolverManager) SetEventHandler(handler challenge.EventHandler) {
+	c.eventHandler = handler
+}
+
+// Subscribe adds a handler receiving the lifecycle events of the challenges, in addition to the handler of SetEventHandler,
+// until the returned function is called.
+func (c *SolverManager) Subscribe(handler challenge.EventHandler) func() {
+	c.listenersMu.Lock()
+	defer c.listenersMu.Unlock()
+
+	if c.listeners == nil {
+		c.listeners = make(map[int]challenge.EventHandler)
+	}
+
+	c.listenerID++
+	id := c.listenerID
+	c.listeners[id] = handler
+
+	return func() {
+		c.listenersMu.Lock()
+		delete(c.listeners, id)
+		c.listenersMu.Unlock()
+	}
+}
+
+func (c *SolverManager) emit(eventType challenge.EventType, domain string, chlgType challenge.Type, err error) {
+	event := challenge.Event{
+		Type:      eventType,
+		Domain:    domain,
+		Challenge: chlgType,
+		Time:      time.Now(),
+		Err:       err,
+	}
+
+	if c.eventHandler != nil {
+		c.eventHandler(event)
+	}
+
+	c.listenersMu.Lock()
+	listeners := make([]challenge.EventHandler, 0, len(c.listeners))
+	for _, listener := range c.listeners {
+		listeners = append(listeners, listener)
+	}
+	c.listenersMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
 // Remove Remove a challenge type from the available solvers.
 func (c *SolverManager) Remove(chlgType challenge.Type) {
 	delete(c.solvers, chlgType)
@@ -61,23 +149,60 @@ func (c *SolverManager) Remove(chlgType challenge.Type) {
 
 // Checks all challenges from the server in order and returns the first matching solver.
 func (c *SolverManager) chooseSolver(authz acme.Authorization) solver {
+	_, solvr := c.selectSolver(authz)
+	return solvr
+}
+
+// selectSolver returns the first matching solver, with its challenge type.
+func (c *SolverManager) selectSolver(authz acme.Authorization) (challenge.Type, solver) {
 	// Allow to have a deterministic challenge order
 	sort.Sort(byType(authz.Challenges))
 
 	domain := challenge.GetTargetedDomain(authz)
+
+	if c.selector != nil {
+		for _, chlgType := range c.selector(authz) {
+			if _, err := challenge.FindChallenge(chlgType, authz); err != nil {
+				continue
+			}
+
+			if solvr, ok := c.solvers[chlgType]; ok {
+				log.Infof("[%s] acme: use %s solver (selected)", domain, chlgType)
+				return chlgType, solvr
+			}
+		}
+	}
+
 	for _, chlg := range authz.Challenges {
 		if solvr, ok := c.solvers[challenge.Type(chlg.Type)]; ok {
 			log.Infof("[%s] acme: use %s solver", domain, chlg.Type)
-			return solvr
+			return challenge.Type(chlg.Type), solvr
 		}
 		log.Infof("[%s] acme: Could not find solver for: %s", domain, chlg.Type)
 	}
 
+	return "", nil
+}
+
+// validate validates a challenge, and emits the validation events.
+func (c *SolverManager) validate(core *api.Core, domain string, chlg acme.Challenge) error {
+	return c.validateWithPayload(core, domain, chlg, struct{}{})
+}
+
+func (c *SolverManager) validateWithPayload(core *api.Core, domain string, chlg acme.Challenge, payload interface{}) error {
+	c.emit(challenge.EventValidationStarted, domain, challenge.Type(chlg.Type), nil)
+
+	err := validateWithPayload(core, domain, chlg, payload)
+	if err != nil {
+		return err
+	}
+
+	c.emit(challenge.EventValidated, domain, challenge.Type(chlg.Type), nil)
 	return nil
 }
 
-func validate(core *api.Core, domain string, chlg acme.Challenge) error {
-	chlng, err := core.Challenges.New(chlg.URL)
+func validateWithPayload(core *api.Core, domain string, chlg acme.Challenge, payload interface{}) error {
+	chlng, err := core.Challenges.NewWithPayload(chlg.URL, payload)
 	if err != nil {
 		return fmt.Errorf("failed to initiate challenge: %w", err)
 	}
@@ -142,7 +267,10 @@ func checkChallengeStatus(chlng acme.ExtendedChallenge) (bool, error) {
 	case acme.StatusPending, acme.StatusProcessing:
 		return false, nil
 	case acme.StatusInvalid:
-		return false, chlng.Error
+		if chlng.Error == nil {
+			return false, &challenge.ValidationError{Err: errors.New("the challenge is invalid")}
+		}
+		return false, &challenge.ValidationError{Err: chlng.Error}
 	default:
 		return false, errors.New("the server returned an unexpected state")
 	}
@@ -159,10 +287,10 @@ func checkAuthorizationStatus(authz acme.Authorization) (bool, error) {
 	case acme.StatusInvalid:
 		for _, chlg := range authz.Challenges {
 			if chlg.Status == acme.StatusInvalid && chlg.Error != nil {
-				return false, chlg.Error
+				return false, &challenge.ValidationError{Err: chlg.Error}
 			}
 		}
-		return false, fmt.Errorf("the authorization state %s", authz.Status)
+		return false, &challenge.ValidationError{Err: fmt.Errorf("the authorization state %s", authz.Status)}
 	default:
 		return false, errors.New("the server returned an unexpected state")
 	}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-acme/lego/v3/acme"
 	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/platform/tester"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -138,12 +139,19 @@ func TestValidate(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			statuses = test.statuses
 
-			err := validate(core, "example.com", acme.Challenge{Type: "http-01", Token: "token", URL: apiURL + "/chlg"})
+			var events []challenge.EventType
+			manager := &SolverManager{eventHandler: func(event challenge.Event) {
+				events = append(events, event.Type)
+			}}
+
+			err := manager.validate(core, "example.com", acme.Challenge{Type: "http-01", Token: "token", URL: apiURL + "/chlg"})
 			if test.want == "" {
 				require.NoError(t, err)
+				assert.Equal(t, []challenge.EventType{challenge.EventValidationStarted, challenge.EventValidated}, events)
 			} else {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), test.want)
+				assert.Equal(t, []challenge.EventType{challenge.EventValidationStarted}, events)
 			}
 		})
 	}
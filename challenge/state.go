@@ -0,0 +1,39 @@
+package challenge
+
+import "sync"
+
+// TokenStates stores the states of the records of a ProviderState by token.
+// It is safe for concurrent use, the zero value is ready to use.
+type TokenStates struct {
+	mu     sync.Mutex
+	states map[string]interface{}
+}
+
+// Store stores the state of the record of a token.
+func (s *TokenStates) Store(token string, state interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.states == nil {
+		s.states = make(map[string]interface{})
+	}
+
+	s.states[token] = state
+}
+
+// Load returns the state of the record of a token, false if it is unknown.
+func (s *TokenStates) Load(token string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[token]
+	return state, ok
+}
+
+// Delete removes the state of the record of a token.
+func (s *TokenStates) Delete(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, token)
+}
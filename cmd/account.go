@@ -10,7 +10,9 @@ import (
 type Account struct {
 	Email        string                 `json:"email"`
 	Registration *registration.Resource `json:"registration"`
-	key          crypto.PrivateKey
+	// TermsOfService the URL of the terms of service agreed by the account.
+	TermsOfService string `json:"termsOfService,omitempty"`
+	key            crypto.PrivateKey
 }
 
 /** Implementation of the registration.User interface **/
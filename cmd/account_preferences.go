@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+const accountPreferencesFileName = "preferences.json"
+
+// accountPreference a default of the account, stored by the 'config' command.
+type accountPreference struct {
+	Name  string
+	Usage string
+	// validate checks the value, and returns it normalized.
+	validate func(value string) (string, error)
+}
+
+var accountPreferences = []accountPreference{
+	{
+		Name:     "key-type",
+		Usage:    "The default key type of the certificates (--key-type).",
+		validate: validatePreferredKeyType,
+	},
+	{
+		Name:     "challenge",
+		Usage:    "The default challenge: http (--http), tls (--tls), or dns (--dns with the provider of the 'dns' preference).",
+		validate: validatePreferredChallenge,
+	},
+	{
+		Name:     "dns",
+		Usage:    "The default DNS provider (--dns).",
+		validate: validatePreferredDNSProvider,
+	},
+}
+
+func findAccountPreference(name string) (accountPreference, error) {
+	for _, pref := range accountPreferences {
+		if pref.Name == name {
+			return pref, nil
+		}
+	}
+
+	var names []string
+	for _, pref := range accountPreferences {
+		names = append(names, pref.Name)
+	}
+
+	return accountPreference{}, fmt.Errorf("unknown preference %q, supported: %s", name, strings.Join(names, ", "))
+}
+
+func validatePreferredKeyType(value string) (string, error) {
+	switch keyType := strings.ToLower(value); keyType {
+	case "rsa2048", "rsa3072", "rsa4096", "rsa8192", "ec256", "ec384":
+		return keyType, nil
+	default:
+		return "", fmt.Errorf("unsupported key type %q", value)
+	}
+}
+
+func validatePreferredChallenge(value string) (string, error) {
+	switch challenge := strings.ToLower(value); challenge {
+	case "http", "tls", "dns":
+		return challenge, nil
+	default:
+		return "", fmt.Errorf("unsupported challenge %q, supported: http, tls, dns", value)
+	}
+}
+
+func validatePreferredDNSProvider(value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", fmt.Errorf("empty DNS provider")
+	}
+	return value, nil
+}
+
+// getPreferencesPath returns the path of the preferences of the account.
+func (s *AccountsStorage) getPreferencesPath() string {
+	return filepath.Join(s.rootUserPath, accountPreferencesFileName)
+}
+
+// LoadPreferences loads the preferences of the account, by name.
+func (s *AccountsStorage) LoadPreferences() (map[string]string, error) {
+	preferences := make(map[string]string)
+
+	raw, err := ioutil.ReadFile(s.getPreferencesPath())
+	if os.IsNotExist(err) {
+		return preferences, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(raw, &preferences)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the preferences of the account %s: %w", s.userID, err)
+	}
+
+	return preferences, nil
+}
+
+// SavePreferences saves the preferences of the account.
+// The account directory is created if needed: the preferences can be set before the registration.
+func (s *AccountsStorage) SavePreferences(preferences map[string]string) error {
+	if err := createNonExistingFolder(s.rootUserPath); err != nil {
+		return err
+	}
+
+	jsonBytes, err := json.MarshalIndent(preferences, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.getPreferencesPath(), jsonBytes, filePerm)
+}
+
+// applyAccountPreferences sets the options from the preferences of the account,
+// except the ones explicitly set on the command line, or by the renewal metadata.
+func applyAccountPreferences(ctx *cli.Context, accountsStorage *AccountsStorage) {
+	preferences, err := accountsStorage.LoadPreferences()
+	if err != nil {
+		log.Fatalf("Could not load the preferences of the account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	if keyType, ok := preferences["key-type"]; ok && !ctx.GlobalIsSet("key-type") {
+		setPreferredOption(ctx, "key-type", keyType)
+	}
+
+	if hasChallengeOption(ctx) {
+		return
+	}
+
+	challenge := preferences["challenge"]
+	if challenge == "" && preferences["dns"] != "" {
+		challenge = "dns"
+	}
+
+	switch challenge {
+	case "http", "tls":
+		setPreferredOption(ctx, challenge, "true")
+	case "dns":
+		if preferences["dns"] == "" {
+			log.Warnf("Account preferences: the preferred challenge is dns, but no DNS provider is set (lego config set dns <provider>)")
+			return
+		}
+		setPreferredOption(ctx, "dns", preferences["dns"])
+	}
+}
+
+// hasChallengeOption returns true if a challenge is set on the command line.
+func hasChallengeOption(ctx *cli.Context) bool {
+	for _, name := range []string{"http", "tls", "dns", "onion.key", "challenge-agent"} {
+		if ctx.GlobalIsSet(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func setPreferredOption(ctx *cli.Context, name, value string) {
+	if err := ctx.GlobalSet(name, value); err != nil {
+		log.Warnf("Account preferences: option %s: %v", name, err)
+		return
+	}
+
+	log.Infof("Account preferences: --%s=%s", name, value)
+}
+
+func createConfig() cli.Command {
+	var usages []string
+	for _, pref := range accountPreferences {
+		usages = append(usages, fmt.Sprintf("%s: %s", pref.Name, pref.Usage))
+	}
+
+	return cli.Command{
+		Name:        "config",
+		Usage:       "Manage the default preferences of the account (selected by the global '--email' option), used when the options are not set",
+		Description: "Preferences:\n\n   " + strings.Join(usages, "\n   "),
+		Subcommands: []cli.Command{
+			{
+				Name:      "set",
+				Usage:     "Set a preference of the account",
+				ArgsUsage: "<name> <value>",
+				Action:    withStorageLock(configSet),
+			},
+			{
+				Name:      "get",
+				Usage:     "Display a preference of the account, or all the preferences",
+				ArgsUsage: "[name]",
+				Action:    configGet,
+			},
+			{
+				Name:      "unset",
+				Usage:     "Remove a preference of the account",
+				ArgsUsage: "<name>",
+				Action:    withStorageLock(configUnset),
+			},
+		},
+	}
+}
+
+func configSet(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		log.Fatal("Usage: lego config set <name> <value>")
+	}
+
+	pref, err := findAccountPreference(ctx.Args().Get(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	value, err := pref.validate(ctx.Args().Get(1))
+	if err != nil {
+		log.Fatalf("Invalid value of the preference %s: %v", pref.Name, err)
+	}
+
+	accountsStorage := NewAccountsStorage(ctx)
+
+	preferences, err := accountsStorage.LoadPreferences()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	preferences[pref.Name] = value
+
+	err = accountsStorage.SavePreferences(preferences)
+	if err != nil {
+		log.Fatalf("Could not save the preferences of the account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	log.Printf("The preference %s of the account %s has been set to %s", pref.Name, accountsStorage.GetUserID(), value)
+
+	return nil
+}
+
+func configGet(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		log.Fatal("Usage: lego config get [name]")
+	}
+
+	accountsStorage := NewAccountsStorage(ctx)
+
+	preferences, err := accountsStorage.LoadPreferences()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if ctx.NArg() == 1 {
+		pref, err := findAccountPreference(ctx.Args().First())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		value, ok := preferences[pref.Name]
+		if !ok {
+			log.Fatalf("The preference %s of the account %s is not set", pref.Name, accountsStorage.GetUserID())
+		}
+
+		fmt.Println(value)
+		return nil
+	}
+
+	var names []string
+	for name := range preferences {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("%s=%s\n", name, preferences[name])
+	}
+
+	return nil
+}
+
+func configUnset(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		log.Fatal("Usage: lego config unset <name>")
+	}
+
+	pref, err := findAccountPreference(ctx.Args().First())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	accountsStorage := NewAccountsStorage(ctx)
+
+	preferences, err := accountsStorage.LoadPreferences()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	delete(preferences, pref.Name)
+
+	err = accountsStorage.SavePreferences(preferences)
+	if err != nil {
+		log.Fatalf("Could not save the preferences of the account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	log.Printf("The preference %s of the account %s has been removed", pref.Name, accountsStorage.GetUserID())
+
+	return nil
+}
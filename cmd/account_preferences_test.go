@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func Test_applyAccountPreferences(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-preferences")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	accountsStorage := &AccountsStorage{userID: "foo@example.com", rootUserPath: dir}
+
+	err = accountsStorage.SavePreferences(map[string]string{"key-type": "rsa2048", "challenge": "dns", "dns": "cloudflare"})
+	require.NoError(t, err)
+
+	runWithFlags(t, []string{"-d", "example.com", "renew"}, func(ctx *cli.Context) {
+		applyAccountPreferences(ctx, accountsStorage)
+
+		assert.Equal(t, "rsa2048", ctx.GlobalString("key-type"))
+		assert.Equal(t, "cloudflare", ctx.GlobalString("dns"))
+		assert.False(t, ctx.GlobalBool("http"))
+	})
+
+	runWithFlags(t, []string{"-d", "example.com", "--key-type", "rsa4096", "--http", "renew"}, func(ctx *cli.Context) {
+		applyAccountPreferences(ctx, accountsStorage)
+
+		assert.Equal(t, "rsa4096", ctx.GlobalString("key-type"))
+		assert.Empty(t, ctx.GlobalString("dns"))
+		assert.True(t, ctx.GlobalBool("http"))
+	})
+}
+
+func Test_applyAccountPreferences_none(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-preferences")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	accountsStorage := &AccountsStorage{userID: "foo@example.com", rootUserPath: dir}
+
+	runWithFlags(t, []string{"-d", "example.com", "renew"}, func(ctx *cli.Context) {
+		applyAccountPreferences(ctx, accountsStorage)
+
+		assert.Equal(t, "ec384", ctx.GlobalString("key-type"))
+		assert.Empty(t, ctx.GlobalString("dns"))
+		assert.False(t, ctx.GlobalIsSet("key-type"))
+	})
+}
+
+func Test_findAccountPreference(t *testing.T) {
+	pref, err := findAccountPreference("key-type")
+	require.NoError(t, err)
+
+	value, err := pref.validate("EC384")
+	require.NoError(t, err)
+	assert.Equal(t, "ec384", value)
+
+	_, err = pref.validate("dsa")
+	require.Error(t, err)
+
+	_, err = findAccountPreference("foo")
+	require.EqualError(t, err, `unknown preference "foo", supported: key-type, challenge, dns`)
+}
@@ -8,10 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"net/url"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/go-acme/lego/v3/lego"
@@ -74,14 +72,13 @@ func NewAccountsStorage(ctx *cli.Context) *AccountsStorage {
 	// TODO: move to account struct? Currently MUST pass email.
 	email := getEmail(ctx)
 
-	serverURL, err := url.Parse(ctx.GlobalString("server"))
+	serverDir, err := serverPath(ctx.GlobalString("server"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	rootPath := filepath.Join(ctx.GlobalString("path"), baseAccountsRootFolderName)
-	serverPath := strings.NewReplacer(":", "_", "/", string(os.PathSeparator)).Replace(serverURL.Host)
-	accountsPath := filepath.Join(rootPath, serverPath)
+	accountsPath := filepath.Join(rootPath, serverDir)
 	rootUserPath := filepath.Join(accountsPath, email)
 
 	return &AccountsStorage{
@@ -99,7 +96,7 @@ func (s *AccountsStorage) ExistsAccountFilePath() bool {
 	if _, err := os.Stat(accountFile); os.IsNotExist(err) {
 		return false
 	} else if err != nil {
-		log.Fatal(err)
+		storageFatalf("%v", err)
 	}
 	return true
 }
@@ -128,13 +125,13 @@ func (s *AccountsStorage) Save(account *Account) error {
 func (s *AccountsStorage) LoadAccount(privateKey crypto.PrivateKey) *Account {
 	fileBytes, err := ioutil.ReadFile(s.accountFilePath)
 	if err != nil {
-		log.Fatalf("Could not load file for account %s: %v", s.userID, err)
+		storageFatalf("Could not load file for account %s: %v", s.userID, err)
 	}
 
 	var account Account
 	err = json.Unmarshal(fileBytes, &account)
 	if err != nil {
-		log.Fatalf("Could not parse file for account %s: %v", s.userID, err)
+		storageFatalf("Could not parse file for account %s: %v", s.userID, err)
 	}
 
 	account.key = privateKey
@@ -142,13 +139,13 @@ func (s *AccountsStorage) LoadAccount(privateKey crypto.PrivateKey) *Account {
 	if account.Registration == nil || account.Registration.Body.Status == "" {
 		reg, err := tryRecoverRegistration(s.ctx, privateKey)
 		if err != nil {
-			log.Fatalf("Could not load account for %s. Registration is nil: %#v", s.userID, err)
+			storageFatalf("Could not load account for %s. Registration is nil: %#v", s.userID, err)
 		}
 
 		account.Registration = reg
 		err = s.Save(&account)
 		if err != nil {
-			log.Fatalf("Could not save account for %s. Registration is nil: %#v", s.userID, err)
+			storageFatalf("Could not save account for %s. Registration is nil: %#v", s.userID, err)
 		}
 	}
 
@@ -156,72 +153,147 @@ func (s *AccountsStorage) LoadAccount(privateKey crypto.PrivateKey) *Account {
 }
 
 func (s *AccountsStorage) GetPrivateKey(keyType certcrypto.KeyType) crypto.PrivateKey {
-	accKeyPath := filepath.Join(s.keysPath, s.userID+".key")
+	accKeyPath := s.getPrivateKeyPath()
+
+	passphrase, err := getAccountPassphrase()
+	if err != nil {
+		storageFatalf("%v", err)
+	}
 
 	if _, err := os.Stat(accKeyPath); os.IsNotExist(err) {
 		log.Printf("No key found for account %s. Generating a %s key.", s.userID, keyType)
 		s.createKeysFolder()
 
-		privateKey, err := generatePrivateKey(accKeyPath, keyType)
+		privateKey, err := generatePrivateKey(accKeyPath, keyType, passphrase)
 		if err != nil {
-			log.Fatalf("Could not generate RSA private account key for account %s: %v", s.userID, err)
+			storageFatalf("Could not generate RSA private account key for account %s: %v", s.userID, err)
 		}
 
 		log.Printf("Saved key to %s", accKeyPath)
 		return privateKey
 	}
 
-	privateKey, err := loadPrivateKey(accKeyPath)
+	privateKey, encrypted, err := loadPrivateKey(accKeyPath, passphrase)
 	if err != nil {
-		log.Fatalf("Could not load RSA private key from file %s: %v", accKeyPath, err)
+		storageFatalf("Could not load RSA private key from file %s: %v", accKeyPath, err)
+	}
+
+	// the keys stored before the passphrase was set are encrypted on the fly.
+	if !encrypted && len(passphrase) > 0 {
+		err = savePrivateKey(accKeyPath, privateKey, passphrase)
+		if err != nil {
+			storageFatalf("Could not encrypt the private key %s: %v", accKeyPath, err)
+		}
+
+		log.Printf("The private key %s has been encrypted", accKeyPath)
 	}
 
 	return privateKey
 }
 
+// ReplacePrivateKey replaces the account key by a new key, applying the change of the key to the account.
+// The new key is written before the change, so it isn't lost if the change succeeds, and the previous key is kept (<email>.key.old).
+func (s *AccountsStorage) ReplacePrivateKey(newKey crypto.PrivateKey, change func() error) error {
+	accKeyPath := s.getPrivateKeyPath()
+
+	passphrase, err := getAccountPassphrase()
+	if err != nil {
+		return err
+	}
+
+	err = savePrivateKey(accKeyPath+".new", newKey, passphrase)
+	if err != nil {
+		return fmt.Errorf("could not save the new key: %w", err)
+	}
+
+	err = change()
+	if err != nil {
+		_ = os.Remove(accKeyPath + ".new")
+		return err
+	}
+
+	err = os.Rename(accKeyPath, accKeyPath+".old")
+	if err != nil {
+		return fmt.Errorf("the key has been changed, but the previous key could not be archived (the new key is %s.new): %w", accKeyPath, err)
+	}
+
+	err = os.Rename(accKeyPath+".new", accKeyPath)
+	if err != nil {
+		return fmt.Errorf("the key has been changed, but the new key could not be installed (the new key is %s.new): %w", accKeyPath, err)
+	}
+
+	return nil
+}
+
+func (s *AccountsStorage) getPrivateKeyPath() string {
+	return filepath.Join(s.keysPath, s.userID+".key")
+}
+
 func (s *AccountsStorage) createKeysFolder() {
 	if err := createNonExistingFolder(s.keysPath); err != nil {
-		log.Fatalf("Could not check/create directory for account %s: %v", s.userID, err)
+		storageFatalf("Could not check/create directory for account %s: %v", s.userID, err)
 	}
 }
 
-func generatePrivateKey(file string, keyType certcrypto.KeyType) (crypto.PrivateKey, error) {
+func generatePrivateKey(file string, keyType certcrypto.KeyType, passphrase []byte) (crypto.PrivateKey, error) {
 	privateKey, err := certcrypto.GeneratePrivateKey(keyType)
 	if err != nil {
 		return nil, err
 	}
 
-	certOut, err := os.Create(file)
+	err = savePrivateKey(file, privateKey, passphrase)
 	if err != nil {
 		return nil, err
 	}
-	defer certOut.Close()
 
+	return privateKey, nil
+}
+
+// savePrivateKey writes the private key, encrypted if a passphrase is given.
+func savePrivateKey(file string, privateKey crypto.PrivateKey, passphrase []byte) error {
 	pemKey := certcrypto.PEMBlock(privateKey)
-	err = pem.Encode(certOut, pemKey)
-	if err != nil {
-		return nil, err
+
+	if len(passphrase) > 0 {
+		var err error
+		pemKey, err = encryptPEMBlock(pemKey, encryptedKeyBlockType, passphrase)
+		if err != nil {
+			return err
+		}
 	}
 
-	return privateKey, nil
+	return ioutil.WriteFile(file, pem.EncodeToMemory(pemKey), filePerm)
 }
 
-func loadPrivateKey(file string) (crypto.PrivateKey, error) {
+// loadPrivateKey reads a private key, and reports if it was encrypted.
+func loadPrivateKey(file string, passphrase []byte) (crypto.PrivateKey, bool, error) {
 	keyBytes, err := ioutil.ReadFile(file)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	keyBlock, _ := pem.Decode(keyBytes)
+	if keyBlock == nil {
+		return nil, false, errors.New("no PEM block found")
+	}
+
+	encrypted := keyBlock.Type == encryptedKeyBlockType
+	if encrypted {
+		keyBlock, err = decryptPEMBlock(keyBlock, passphrase)
+		if err != nil {
+			return nil, true, err
+		}
+	}
 
 	switch keyBlock.Type {
 	case "RSA PRIVATE KEY":
-		return x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+		key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+		return key, encrypted, err
 	case "EC PRIVATE KEY":
-		return x509.ParseECPrivateKey(keyBlock.Bytes)
+		key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+		return key, encrypted, err
 	}
 
-	return nil, errors.New("unknown private key type")
+	return nil, encrypted, errors.New("unknown private key type")
 }
 
 func tryRecoverRegistration(ctx *cli.Context, privateKey crypto.PrivateKey) (*registration.Resource, error) {
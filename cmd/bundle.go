@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	bundleManifestName = "manifest.json"
+	bundleDataDir      = "lego"
+	bundleVersion      = 1
+
+	encryptedBundleBlockType = "LEGO ENCRYPTED BUNDLE"
+	bundleBlockType          = "LEGO BUNDLE"
+)
+
+// bundleManifest describes the content of a bundle.
+// The paths are relative to the storage directory ("path" option), so a bundle can be imported in another directory.
+type bundleManifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Files   []string  `json:"files"`
+}
+
+// writeBundle archives the files of the storage directory root (tar.gz).
+// The archives directory is skipped unless withArchives is true.
+// The bundle is encrypted if a passphrase is given.
+func writeBundle(w io.Writer, root string, withArchives bool, passphrase []byte) error {
+	var files []string
+
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if rel == baseArchivesFolderName && !withArchives {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Mode().IsRegular() {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("nothing to export in %s", root)
+	}
+
+	buf := &bytes.Buffer{}
+
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(bundleManifest{Version: bundleVersion, Created: time.Now().UTC(), Files: files}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	err = tw.WriteHeader(&tar.Header{Name: bundleManifestName, Mode: 0600, Size: int64(len(manifest)), ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	if _, err = tw.Write(manifest); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		err = addBundleFile(tw, root, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+
+	if len(passphrase) == 0 {
+		_, err = w.Write(buf.Bytes())
+		return err
+	}
+
+	block, err := encryptPEMBlock(&pem.Block{Type: bundleBlockType, Bytes: buf.Bytes()}, encryptedBundleBlockType, passphrase)
+	if err != nil {
+		return err
+	}
+
+	return pem.Encode(w, block)
+}
+
+func addBundleFile(tw *tar.Writer, root, file string) error {
+	filePath := filepath.Join(root, filepath.FromSlash(file))
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = path.Join(bundleDataDir, file)
+
+	if err = tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// readBundle extracts a bundle in the storage directory root.
+// The entries of the bundle must match its manifest exactly, and are all checked before extracting anything:
+// nothing is extracted if some files of the bundle already exist, unless overwrite is true.
+// It returns the paths of the extracted files, relative to root.
+func readBundle(r io.Reader, root string, overwrite bool, passphrase []byte) ([]string, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if block, _ := pem.Decode(raw); block != nil && block.Type == encryptedBundleBlockType {
+		block, err = decryptPEMBlock(block, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		raw = block.Bytes
+	}
+
+	tr, manifest, err := openBundle(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// first pass: the entries are validated, nothing is written.
+	var files []string
+
+	err = walkBundle(tr, func(rel string, _ *tar.Header, _ io.Reader) error {
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkBundleManifest(manifest, files)
+	if err != nil {
+		return nil, err
+	}
+
+	if !overwrite {
+		err = checkBundleConflicts(root, files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// second pass: the extraction.
+	tr, _, err = openBundle(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var extracted []string
+
+	err = walkBundle(tr, func(rel string, header *tar.Header, content io.Reader) error {
+		err := extractBundleFile(content, header, filepath.Join(root, filepath.FromSlash(rel)), overwrite)
+		if err != nil {
+			return err
+		}
+
+		extracted = append(extracted, rel)
+		return nil
+	})
+
+	return extracted, err
+}
+
+// openBundle opens the archive of a bundle, and reads its manifest.
+func openBundle(raw []byte) (*tar.Reader, *bundleManifest, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != bundleManifestName {
+		return nil, nil, errors.New("invalid bundle: missing manifest")
+	}
+
+	var manifest bundleManifest
+	err = json.NewDecoder(tr).Decode(&manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+
+	if manifest.Version != bundleVersion {
+		return nil, nil, fmt.Errorf("unsupported bundle version: %d", manifest.Version)
+	}
+
+	return tr, &manifest, nil
+}
+
+// walkBundle calls fn for each file of a bundle, with its path relative to the storage directory (slash-separated).
+func walkBundle(tr *tar.Reader, fn func(rel string, header *tar.Header, content io.Reader) error) error {
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		rel, err := bundleRelativePath(header.Name)
+		if err != nil {
+			return err
+		}
+
+		err = fn(filepath.ToSlash(rel), header, tr)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// checkBundleManifest checks that the files of a bundle are exactly the files listed by its manifest.
+func checkBundleManifest(manifest *bundleManifest, files []string) error {
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, file := range manifest.Files {
+		listed[path.Clean(file)] = true
+	}
+
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		if seen[file] {
+			return fmt.Errorf("invalid bundle: duplicated entry %s", file)
+		}
+		seen[file] = true
+
+		if !listed[file] {
+			return fmt.Errorf("invalid bundle: the entry %s is not listed in the manifest", file)
+		}
+	}
+
+	for file := range listed {
+		if !seen[file] {
+			return fmt.Errorf("invalid bundle: the file %s of the manifest is missing", file)
+		}
+	}
+
+	return nil
+}
+
+// checkBundleConflicts checks, before extracting anything, that the files of the bundle don't exist yet.
+func checkBundleConflicts(root string, files []string) error {
+	var conflicts []string
+	for _, file := range files {
+		target := filepath.Join(root, filepath.FromSlash(file))
+		if _, err := os.Lstat(target); err == nil {
+			conflicts = append(conflicts, target)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("the following files already exist: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// bundleRelativePath validates the name of an entry of the bundle, and returns its path relative to the storage directory.
+func bundleRelativePath(name string) (string, error) {
+	if !strings.HasPrefix(name, bundleDataDir+"/") {
+		return "", fmt.Errorf("invalid bundle entry: %s", name)
+	}
+
+	rel := path.Clean(strings.TrimPrefix(name, bundleDataDir+"/"))
+	if rel == "." || path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("invalid bundle entry: %s", name)
+	}
+
+	return filepath.FromSlash(rel), nil
+}
+
+// extractBundleFile writes a file of a bundle.
+// Without overwrite, the file must not exist: it is not replaced if it has been created since the conflicts check.
+func extractBundleFile(r io.Reader, header *tar.Header, target string, overwrite bool) error {
+	err := os.MkdirAll(filepath.Dir(target), 0700)
+	if err != nil {
+		return err
+	}
+
+	flag := os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	if overwrite {
+		flag = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(target, flag, os.FileMode(header.Mode).Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+
+	// preserves the modification time of the original file.
+	return os.Chtimes(target, header.ModTime, header.ModTime)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBundleSource(t *testing.T) string {
+	t.Helper()
+
+	root, err := ioutil.TempDir("", "lego-bundle")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(root) })
+
+	files := map[string]string{
+		"accounts/acme-v02.api.letsencrypt.org/foo@example.com/account.json": `{"email":"foo@example.com"}`,
+		"accounts/acme-v02.api.letsencrypt.org/foo@example.com/keys/foo.key": "key",
+		"certificates/example.com.crt":                                       "crt",
+		"certificates/example.com.json":                                      `{"domain":"example.com"}`,
+		"archives/1580000000.example.com.crt":                                "old",
+	}
+
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0700))
+		require.NoError(t, ioutil.WriteFile(p, []byte(content), 0600))
+	}
+
+	return root
+}
+
+func Test_bundle_roundTrip(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		passphrase []byte
+	}{
+		{desc: "plain"},
+		{desc: "encrypted", passphrase: []byte("secret")},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			source := setupBundleSource(t)
+
+			buf := &bytes.Buffer{}
+			err := writeBundle(buf, source, false, test.passphrase)
+			require.NoError(t, err)
+
+			target, err := ioutil.TempDir("", "lego-bundle")
+			require.NoError(t, err)
+			defer func() { _ = os.RemoveAll(target) }()
+
+			files, err := readBundle(bytes.NewReader(buf.Bytes()), target, false, test.passphrase)
+			require.NoError(t, err)
+
+			expected := []string{
+				"accounts/acme-v02.api.letsencrypt.org/foo@example.com/account.json",
+				"accounts/acme-v02.api.letsencrypt.org/foo@example.com/keys/foo.key",
+				"certificates/example.com.crt",
+				"certificates/example.com.json",
+			}
+			assert.Equal(t, expected, files)
+
+			content, err := ioutil.ReadFile(filepath.Join(target, "certificates", "example.com.crt"))
+			require.NoError(t, err)
+			assert.Equal(t, "crt", string(content))
+
+			// the files already exist.
+			_, err = readBundle(bytes.NewReader(buf.Bytes()), target, false, test.passphrase)
+			require.Error(t, err)
+
+			_, err = readBundle(bytes.NewReader(buf.Bytes()), target, true, test.passphrase)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_readBundle_wrongPassphrase(t *testing.T) {
+	source := setupBundleSource(t)
+
+	buf := &bytes.Buffer{}
+	err := writeBundle(buf, source, true, []byte("secret"))
+	require.NoError(t, err)
+
+	_, err = readBundle(buf, source, true, []byte("wrong"))
+	require.EqualError(t, err, "could not decrypt: wrong passphrase or corrupted file")
+}
+
+// buildTestBundle builds a plain bundle with a manifest and entries (name, content), written as is.
+func buildTestBundle(t *testing.T, manifest string, entries ...[2]string) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: bundleManifestName, Mode: 0600, Size: int64(len(manifest))}))
+	_, err := tw.Write([]byte(manifest))
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: entry[0], Mode: 0600, Size: int64(len(entry[1])), Typeflag: tar.TypeReg}))
+		_, err = tw.Write([]byte(entry[1]))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf
+}
+
+func Test_readBundle_pathTraversal(t *testing.T) {
+	buf := buildTestBundle(t, `{"version":1,"files":["../evil"]}`, [2]string{"lego/../evil", "evil"})
+
+	target, err := ioutil.TempDir("", "lego-bundle")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(target) }()
+
+	_, err = readBundle(buf, target, false, nil)
+	require.EqualError(t, err, "invalid bundle entry: lego/../evil")
+}
+
+func Test_readBundle_manifestMismatch(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		manifest string
+		entries  [][2]string
+		expected string
+	}{
+		{
+			desc:     "unlisted entry",
+			manifest: `{"version":1,"files":["certificates/example.org.crt"]}`,
+			entries:  [][2]string{{"lego/certificates/example.org.crt", "new"}, {"lego/certificates/example.com.crt", "evil"}},
+			expected: "invalid bundle: the entry certificates/example.com.crt is not listed in the manifest",
+		},
+		{
+			desc:     "duplicated entry",
+			manifest: `{"version":1,"files":["certificates/example.org.crt"]}`,
+			entries:  [][2]string{{"lego/certificates/example.org.crt", "new"}, {"lego/certificates/example.org.crt", "evil"}},
+			expected: "invalid bundle: duplicated entry certificates/example.org.crt",
+		},
+		{
+			desc:     "missing file",
+			manifest: `{"version":1,"files":["certificates/example.org.crt","certificates/example.org.key"]}`,
+			entries:  [][2]string{{"lego/certificates/example.org.crt", "new"}},
+			expected: "invalid bundle: the file certificates/example.org.key of the manifest is missing",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			target, err := ioutil.TempDir("", "lego-bundle")
+			require.NoError(t, err)
+			defer func() { _ = os.RemoveAll(target) }()
+
+			existing := filepath.Join(target, "certificates", "example.com.crt")
+			require.NoError(t, os.MkdirAll(filepath.Dir(existing), 0700))
+			require.NoError(t, ioutil.WriteFile(existing, []byte("crt"), 0600))
+
+			files, err := readBundle(buildTestBundle(t, test.manifest, test.entries...), target, false, nil)
+			require.EqualError(t, err, test.expected)
+			assert.Empty(t, files)
+
+			// nothing is extracted.
+			content, err := ioutil.ReadFile(existing)
+			require.NoError(t, err)
+			assert.Equal(t, "crt", string(content))
+
+			_, err = os.Stat(filepath.Join(target, "certificates", "example.org.crt"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
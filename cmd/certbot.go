@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/registration"
+	"gopkg.in/square/go-jose.v2"
+)
+
+// certbotImporter converts the accounts and the certificates of a certbot configuration directory (e.g. /etc/letsencrypt).
+//
+// certbot layout:
+//
+//     /etc/letsencrypt/accounts/<CA host>/<directory path>/<account ID>/{regr.json,private_key.json}
+//     /etc/letsencrypt/live/<name>/{cert.pem,chain.pem,fullchain.pem,privkey.pem}
+//
+type certbotImporter struct {
+	source string
+	// root is the lego storage directory ("path" option).
+	root string
+	// email is used for the accounts without contact.
+	email      string
+	overwrite  bool
+	passphrase []byte
+}
+
+// importAccounts imports the accounts and their keys, and returns the imported account directories.
+func (c *certbotImporter) importAccounts() ([]string, error) {
+	accountsDir := filepath.Join(c.source, "accounts")
+
+	var imported []string
+
+	err := filepath.Walk(accountsDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || info.Name() != "regr.json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(accountsDir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+
+		// the first element is the host of the CA.
+		host := strings.Split(filepath.ToSlash(rel), "/")[0]
+
+		dir, err := c.importAccount(filepath.Dir(p), host)
+		if err != nil {
+			return fmt.Errorf("account %s: %w", rel, err)
+		}
+
+		if dir != "" {
+			imported = append(imported, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		return imported, err
+	}
+
+	return imported, nil
+}
+
+func (c *certbotImporter) importAccount(dir, host string) (string, error) {
+	regrBytes, err := ioutil.ReadFile(filepath.Join(dir, "regr.json"))
+	if err != nil {
+		return "", err
+	}
+
+	var reg registration.Resource
+	err = json.Unmarshal(regrBytes, &reg)
+	if err != nil {
+		return "", fmt.Errorf("invalid regr.json: %w", err)
+	}
+
+	email := c.email
+	for _, contact := range reg.Body.Contact {
+		if strings.HasPrefix(contact, "mailto:") {
+			email = strings.TrimPrefix(contact, "mailto:")
+			break
+		}
+	}
+
+	if email == "" {
+		log.Warnf("import: the certbot account %s has no contact email, use --email to import it", reg.URI)
+		return "", nil
+	}
+
+	keyBytes, err := ioutil.ReadFile(filepath.Join(dir, "private_key.json"))
+	if err != nil {
+		return "", err
+	}
+
+	var jwk jose.JSONWebKey
+	err = jwk.UnmarshalJSON(keyBytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid private_key.json: %w", err)
+	}
+
+	if jwk.IsPublic() {
+		return "", errors.New("private_key.json doesn't contain a private key")
+	}
+
+	serverPath := strings.NewReplacer(":", "_", "/", string(os.PathSeparator)).Replace(host)
+	userPath := filepath.Join(c.root, baseAccountsRootFolderName, serverPath, email)
+	accountFile := filepath.Join(userPath, accountFileName)
+
+	if _, err = os.Stat(accountFile); err == nil && !c.overwrite {
+		return "", fmt.Errorf("%s already exists", accountFile)
+	}
+
+	keysPath := filepath.Join(userPath, baseKeysFolderName)
+	err = createNonExistingFolder(keysPath)
+	if err != nil {
+		return "", err
+	}
+
+	err = savePrivateKey(filepath.Join(keysPath, email+".key"), jwk.Key, c.passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	account := &Account{Email: email, Registration: &reg}
+
+	jsonBytes, err := json.MarshalIndent(account, "", "\t")
+	if err != nil {
+		return "", err
+	}
+
+	err = ioutil.WriteFile(accountFile, jsonBytes, filePerm)
+	if err != nil {
+		return "", err
+	}
+
+	return userPath, nil
+}
+
+// importCertificates imports the current certificates (the "live" directory), and returns their domains.
+func (c *certbotImporter) importCertificates() ([]string, error) {
+	liveDir := filepath.Join(c.source, "live")
+
+	entries, err := ioutil.ReadDir(liveDir)
+	if err != nil {
+		return nil, err
+	}
+
+	certsStorage := &CertificatesStorage{rootPath: filepath.Join(c.root, baseCertificatesFolderName)}
+	certsStorage.CreateRootFolder()
+
+	var domains []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		domain, err := c.importCertificate(certsStorage, filepath.Join(liveDir, entry.Name()))
+		if err != nil {
+			return domains, fmt.Errorf("certificate %s: %w", entry.Name(), err)
+		}
+
+		domains = append(domains, domain)
+	}
+
+	return domains, nil
+}
+
+func (c *certbotImporter) importCertificate(certsStorage *CertificatesStorage, dir string) (string, error) {
+	// the files of the "live" directory are symbolic links to the "archive" directory.
+	fullChain, err := ioutil.ReadFile(filepath.Join(dir, "fullchain.pem"))
+	if err != nil {
+		return "", err
+	}
+
+	certificates, err := certcrypto.ParsePEMBundle(fullChain)
+	if err != nil {
+		return "", err
+	}
+
+	domain := certificateDomain(certificates[0])
+	if domain == "" {
+		return "", errors.New("no domain found in the certificate")
+	}
+
+	if certsStorage.ExistsFile(domain, ".crt") && !c.overwrite {
+		return "", fmt.Errorf("the certificate of %s already exists", domain)
+	}
+
+	resource := &certificate.Resource{Domain: domain, Certificate: fullChain}
+
+	resource.PrivateKey, err = ioutil.ReadFile(filepath.Join(dir, "privkey.pem"))
+	if err != nil {
+		return "", err
+	}
+
+	resource.IssuerCertificate, err = ioutil.ReadFile(filepath.Join(dir, "chain.pem"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	certsStorage.SaveResource(resource)
+
+	return domain, nil
+}
+
+// certificateDomain returns the main domain of a certificate: the common name if it's one of the SANs, or the first SAN.
+func certificateDomain(cert *x509.Certificate) string {
+	for _, name := range cert.DNSNames {
+		if name == cert.Subject.CommonName {
+			return name
+		}
+	}
+
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+
+	return cert.Subject.CommonName
+}
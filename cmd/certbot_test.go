@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+)
+
+func setupCertbotDir(t *testing.T) (string, *rsa.PrivateKey) {
+	t.Helper()
+
+	source, err := ioutil.TempDir("", "lego-certbot")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(source) })
+
+	accountKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	accountDir := filepath.Join(source, "accounts", "acme-v02.api.letsencrypt.org", "directory", "0123abcd")
+	require.NoError(t, os.MkdirAll(accountDir, 0700))
+
+	jwk, err := jose.JSONWebKey{Key: accountKey}.MarshalJSON()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(accountDir, "private_key.json"), jwk, 0600))
+
+	regr := `{"body": {"contact": ["mailto:foo@example.com"], "status": "valid"}, "uri": "https://acme-v02.api.letsencrypt.org/acme/acct/123"}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(accountDir, "regr.json"), []byte(regr), 0600))
+
+	certKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	cert, err := certcrypto.GeneratePemCert(certKey, "example.com", nil)
+	require.NoError(t, err)
+
+	// certbot stores the files in the archive directory, the live directory contains symbolic links.
+	archiveDir := filepath.Join(source, "archive", "example.com")
+	liveDir := filepath.Join(source, "live", "example.com")
+	require.NoError(t, os.MkdirAll(archiveDir, 0700))
+	require.NoError(t, os.MkdirAll(liveDir, 0700))
+
+	files := map[string][]byte{
+		"fullchain1.pem": cert,
+		"chain1.pem":     cert,
+		"privkey1.pem":   certcrypto.PEMEncode(certKey),
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(archiveDir, name), content, 0600))
+	}
+
+	for link, target := range map[string]string{"fullchain.pem": "fullchain1.pem", "chain.pem": "chain1.pem", "privkey.pem": "privkey1.pem"} {
+		require.NoError(t, os.Symlink(filepath.Join("..", "..", "archive", "example.com", target), filepath.Join(liveDir, link)))
+	}
+
+	return source, accountKey
+}
+
+func Test_certbotImporter(t *testing.T) {
+	source, accountKey := setupCertbotDir(t)
+
+	root, err := ioutil.TempDir("", "lego-certbot")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	importer := &certbotImporter{source: source, root: root}
+
+	accounts, err := importer.importAccounts()
+	require.NoError(t, err)
+
+	userPath := filepath.Join(root, "accounts", "acme-v02.api.letsencrypt.org", "foo@example.com")
+	assert.Equal(t, []string{userPath}, accounts)
+
+	key, encrypted, err := loadPrivateKey(filepath.Join(userPath, "keys", "foo@example.com.key"), nil)
+	require.NoError(t, err)
+	assert.False(t, encrypted)
+	assert.Equal(t, accountKey.D, key.(*rsa.PrivateKey).D)
+
+	raw, err := ioutil.ReadFile(filepath.Join(userPath, "account.json"))
+	require.NoError(t, err)
+
+	var account Account
+	require.NoError(t, json.Unmarshal(raw, &account))
+	assert.Equal(t, "foo@example.com", account.Email)
+	assert.Equal(t, "https://acme-v02.api.letsencrypt.org/acme/acct/123", account.Registration.URI)
+
+	domains, err := importer.importCertificates()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, domains)
+
+	for _, ext := range []string{".crt", ".key", ".issuer.crt", ".json"} {
+		assert.FileExists(t, filepath.Join(root, "certificates", "example.com"+ext))
+	}
+
+	// the accounts and the certificates already exist.
+	_, err = importer.importAccounts()
+	require.Error(t, err)
+
+	_, err = importer.importCertificates()
+	require.Error(t, err)
+}
@@ -42,29 +42,55 @@ type CertificatesStorage struct {
 	archivePath string
 	pem         bool
 	filename    string // Deprecated
+
+	// chain changes the chain of the certificates before they are written.
+	chain certificate.ChainOptions
+
+	// adapters write the certificates in the layout of other programs.
+	adapters []storageAdapter
 }
 
 // NewCertificatesStorage create a new certificates storage.
+// With the "server.namespace" option, the directories are namespaced by CA server (e.g. ./.lego/certificates/acme-v02.api.letsencrypt.org/).
 func NewCertificatesStorage(ctx *cli.Context) *CertificatesStorage {
+	rootPath := filepath.Join(ctx.GlobalString("path"), baseCertificatesFolderName)
+	archivePath := filepath.Join(ctx.GlobalString("path"), baseArchivesFolderName)
+
+	if ctx.GlobalBool("server.namespace") {
+		serverDir, err := serverPath(ctx.GlobalString("server"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		rootPath = filepath.Join(rootPath, serverDir)
+		archivePath = filepath.Join(archivePath, serverDir)
+	}
+
 	return &CertificatesStorage{
-		rootPath:    filepath.Join(ctx.GlobalString("path"), baseCertificatesFolderName),
-		archivePath: filepath.Join(ctx.GlobalString("path"), baseArchivesFolderName),
+		rootPath:    rootPath,
+		archivePath: archivePath,
 		pem:         ctx.GlobalBool("pem"),
 		filename:    ctx.GlobalString("filename"),
+		chain: certificate.ChainOptions{
+			StripRoot: ctx.GlobalBool("chain.strip-root"),
+			LeafOnly:  ctx.GlobalBool("chain.leaf-only"),
+			LeafFirst: ctx.GlobalBool("chain.leaf-first"),
+		},
+		adapters: newStorageAdapters(ctx),
 	}
 }
 
 func (s *CertificatesStorage) CreateRootFolder() {
 	err := createNonExistingFolder(s.rootPath)
 	if err != nil {
-		log.Fatalf("Could not check/create path: %v", err)
+		storageFatalf("Could not check/create path: %v", err)
 	}
 }
 
 func (s *CertificatesStorage) CreateArchiveFolder() {
 	err := createNonExistingFolder(s.archivePath)
 	if err != nil {
-		log.Fatalf("Could not check/create path: %v", err)
+		storageFatalf("Could not check/create path: %v", err)
 	}
 }
 
@@ -75,17 +101,31 @@ func (s *CertificatesStorage) GetRootPath() string {
 func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
 	domain := certRes.Domain
 
+	// the chain options apply to the written files, not to the resource of the caller.
+	chained := *certRes
+	certRes = &chained
+
+	err := s.chain.Apply(certRes)
+	if err != nil {
+		storageFatalf("Unable to change the certificate chain for domain %s\n\t%v", domain, err)
+	}
+
 	// We store the certificate, private key and metadata in different files
 	// as web servers would not be able to work with a combined file.
-	err := s.WriteFile(domain, ".crt", certRes.Certificate)
+	err = s.WriteFile(domain, ".crt", certRes.Certificate)
 	if err != nil {
-		log.Fatalf("Unable to save Certificate for domain %s\n\t%v", domain, err)
+		storageFatalf("Unable to save Certificate for domain %s\n\t%v", domain, err)
 	}
 
 	if certRes.IssuerCertificate != nil {
 		err = s.WriteFile(domain, ".issuer.crt", certRes.IssuerCertificate)
 		if err != nil {
-			log.Fatalf("Unable to save IssuerCertificate for domain %s\n\t%v", domain, err)
+			storageFatalf("Unable to save IssuerCertificate for domain %s\n\t%v", domain, err)
+		}
+	} else if s.chain.LeafOnly {
+		err = s.RemoveFile(domain, ".issuer.crt")
+		if err != nil {
+			storageFatalf("Unable to remove IssuerCertificate for domain %s\n\t%v", domain, err)
 		}
 	}
 
@@ -93,40 +133,47 @@ func (s *CertificatesStorage) SaveResource(certRes *certificate.Resource) {
 		// if we were given a CSR, we don't know the private key
 		err = s.WriteFile(domain, ".key", certRes.PrivateKey)
 		if err != nil {
-			log.Fatalf("Unable to save PrivateKey for domain %s\n\t%v", domain, err)
+			storageFatalf("Unable to save PrivateKey for domain %s\n\t%v", domain, err)
 		}
 
 		if s.pem {
 			err = s.WriteFile(domain, ".pem", bytes.Join([][]byte{certRes.Certificate, certRes.PrivateKey}, nil))
 			if err != nil {
-				log.Fatalf("Unable to save Certificate and PrivateKey in .pem for domain %s\n\t%v", domain, err)
+				storageFatalf("Unable to save Certificate and PrivateKey in .pem for domain %s\n\t%v", domain, err)
 			}
 		}
 	} else if s.pem {
 		// we don't have the private key; can't write the .pem file
-		log.Fatalf("Unable to save pem without private key for domain %s\n\t%v; are you using a CSR?", domain, err)
+		storageFatalf("Unable to save pem without private key for domain %s\n\t%v; are you using a CSR?", domain, err)
 	}
 
 	jsonBytes, err := json.MarshalIndent(certRes, "", "\t")
 	if err != nil {
-		log.Fatalf("Unable to marshal CertResource for domain %s\n\t%v", domain, err)
+		storageFatalf("Unable to marshal CertResource for domain %s\n\t%v", domain, err)
 	}
 
 	err = s.WriteFile(domain, ".json", jsonBytes)
 	if err != nil {
-		log.Fatalf("Unable to save CertResource for domain %s\n\t%v", domain, err)
+		storageFatalf("Unable to save CertResource for domain %s\n\t%v", domain, err)
+	}
+
+	for _, adapter := range s.adapters {
+		err = adapter.Save(certRes)
+		if err != nil {
+			storageFatalf("Unable to save the certificate for domain %s in the %s storage\n\t%v", domain, adapter.Name(), err)
+		}
 	}
 }
 
 func (s *CertificatesStorage) ReadResource(domain string) certificate.Resource {
 	raw, err := s.ReadFile(domain, ".json")
 	if err != nil {
-		log.Fatalf("Error while loading the meta data for domain %s\n\t%v", domain, err)
+		storageFatalf("Error while loading the meta data for domain %s\n\t%v", domain, err)
 	}
 
 	var resource certificate.Resource
 	if err = json.Unmarshal(raw, &resource); err != nil {
-		log.Fatalf("Error while marshaling the meta data for domain %s\n\t%v", domain, err)
+		storageFatalf("Error while marshaling the meta data for domain %s\n\t%v", domain, err)
 	}
 
 	return resource
@@ -139,7 +186,7 @@ func (s *CertificatesStorage) ExistsFile(domain, extension string) bool {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return false
 	} else if err != nil {
-		log.Fatal(err)
+		storageFatalf("%v", err)
 	}
 	return true
 }
@@ -174,6 +221,18 @@ func (s *CertificatesStorage) WriteFile(domain, extension string, data []byte) e
 	return ioutil.WriteFile(filePath, data, filePerm)
 }
 
+// RemoveFile removes a file of the domain, if it exists.
+func (s *CertificatesStorage) RemoveFile(domain, extension string) error {
+	filePath := filepath.Join(s.rootPath, sanitizedDomain(domain)+extension)
+
+	err := os.Remove(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
 func (s *CertificatesStorage) MoveToArchive(domain string) error {
 	matches, err := filepath.Glob(filepath.Join(s.rootPath, sanitizedDomain(domain)+".*"))
 	if err != nil {
@@ -198,7 +257,7 @@ func (s *CertificatesStorage) MoveToArchive(domain string) error {
 func sanitizedDomain(domain string) string {
 	safe, err := idna.ToASCII(strings.Replace(domain, "*", "_", -1))
 	if err != nil {
-		log.Fatal(err)
+		storageFatalf("%v", err)
 	}
 	return safe
 }
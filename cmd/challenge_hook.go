@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// Environment variables passed to the challenge hook.
+const (
+	envChallengeEvent  = "LEGO_CHALLENGE_EVENT"
+	envChallengeDomain = "LEGO_CHALLENGE_DOMAIN"
+	envChallengeType   = "LEGO_CHALLENGE_TYPE"
+	envChallengeTime   = "LEGO_CHALLENGE_TIME"
+	envChallengeError  = "LEGO_CHALLENGE_ERROR"
+)
+
+// challengeHook returns the event handler running the hook command (--challenge-hook) for each lifecycle event of the challenges.
+// The failures of the hook are logged: they don't stop the resolution of the challenges.
+func challengeHook(hook string) challenge.EventHandler {
+	parts := strings.Fields(hook)
+
+	return func(event challenge.Event) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+		cmd.Env = append(os.Environ(), challengeHookEnv(event)...)
+
+		output, err := cmd.CombinedOutput()
+		if len(output) > 0 {
+			fmt.Println(string(output))
+		}
+
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Warnf("[%s] challenge hook: timed out (event %s)", event.Domain, event.Type)
+			return
+		}
+
+		if err != nil {
+			log.Warnf("[%s] challenge hook: event %s: %v", event.Domain, event.Type, err)
+		}
+	}
+}
+
+func challengeHookEnv(event challenge.Event) []string {
+	env := []string{
+		envChallengeEvent + "=" + event.Type.String(),
+		envChallengeDomain + "=" + event.Domain,
+		envChallengeType + "=" + event.Challenge.String(),
+		envChallengeTime + "=" + event.Time.UTC().Format(time.RFC3339),
+	}
+
+	if event.Err != nil {
+		env = append(env, envChallengeError+"="+event.Err.Error())
+	}
+
+	return env
+}
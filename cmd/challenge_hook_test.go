@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_challengeHookEnv(t *testing.T) {
+	event := challenge.Event{
+		Type:      challenge.EventFailed,
+		Domain:    "*.example.com",
+		Challenge: challenge.DNS01,
+		Time:      time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC),
+		Err:       errors.New("NXDOMAIN"),
+	}
+
+	expected := []string{
+		"LEGO_CHALLENGE_EVENT=failed",
+		"LEGO_CHALLENGE_DOMAIN=*.example.com",
+		"LEGO_CHALLENGE_TYPE=dns-01",
+		"LEGO_CHALLENGE_TIME=2020-03-01T10:00:00Z",
+		"LEGO_CHALLENGE_ERROR=NXDOMAIN",
+	}
+	assert.Equal(t, expected, challengeHookEnv(event))
+}
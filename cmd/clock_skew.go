@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/urfave/cli"
+)
+
+// clockCorrected the clock already follows the clock of the CA.
+var clockCorrected bool
+
+// checkClockSkew warns when the local clock differs from the clock of the CA (--clock-skew.warn),
+// and follows the clock of the CA for the scheduling decisions if requested (--clock-skew.correct):
+// the renewal checks, the renewal windows, and the backoffs.
+// The skew is measured on the Date header of the responses of the CA.
+func checkClockSkew(ctx *cli.Context, client *lego.Client) {
+	threshold := ctx.GlobalDuration("clock-skew.warn")
+	if threshold <= 0 {
+		return
+	}
+
+	skew, ok := client.GetClockSkew()
+	if !ok {
+		return
+	}
+
+	abs := skew
+	direction := "behind"
+	if skew < 0 {
+		abs = -skew
+		direction = "ahead of"
+	}
+
+	if abs < threshold {
+		return
+	}
+
+	log.Warnf("The local clock is %s %s the clock of the CA: the signatures, the nonces, and the validity of the certificates can be rejected or misjudged. Check the time synchronization (NTP).",
+		abs.Round(time.Second), direction)
+
+	if ctx.GlobalBool("clock-skew.correct") && !clockCorrected {
+		clk = clock.Offset(clk, skew)
+		clockCorrected = true
+
+		log.Infof("The scheduling decisions follow the clock of the CA (%s).", clk.Now().UTC().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func newSkewedClient(t *testing.T, skew time.Duration) *lego.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Date", time.Now().Add(skew).UTC().Format(http.TimeFormat))
+		_ = tester.WriteJSONResponse(rw, acme.Directory{NewNonceURL: "http://localhost/nonce", NewAccountURL: "http://localhost/account", NewOrderURL: "http://localhost/newOrder"})
+	}))
+	t.Cleanup(server.Close)
+
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	config := lego.NewConfig(&Account{Email: "foo@example.com", key: privateKey})
+	config.CADirURL = server.URL + "/dir"
+
+	client, err := lego.NewClient(config)
+	require.NoError(t, err)
+
+	return client
+}
+
+func Test_checkClockSkew(t *testing.T) {
+	defer func() {
+		clk = clock.Real
+		clockCorrected = false
+	}()
+
+	client := newSkewedClient(t, 10*time.Minute)
+
+	skew, ok := client.GetClockSkew()
+	require.True(t, ok)
+	assert.InDelta(t, float64(10*time.Minute), float64(skew), float64(2*time.Second))
+
+	// a warning only.
+	runWithFlags(t, []string{"renew"}, func(ctx *cli.Context) {
+		checkClockSkew(ctx, client)
+	})
+
+	assert.False(t, clockCorrected)
+
+	runWithFlags(t, []string{"--clock-skew.correct", "renew"}, func(ctx *cli.Context) {
+		checkClockSkew(ctx, client)
+	})
+
+	assert.True(t, clockCorrected)
+	assert.InDelta(t, float64(10*time.Minute), float64(clk.Now().Sub(time.Now())), float64(2*time.Second))
+}
+
+func Test_checkClockSkew_belowThreshold(t *testing.T) {
+	defer func() {
+		clk = clock.Real
+		clockCorrected = false
+	}()
+
+	client := newSkewedClient(t, 0)
+
+	runWithFlags(t, []string{"--clock-skew.correct", "renew"}, func(ctx *cli.Context) {
+		checkClockSkew(ctx, client)
+	})
+
+	assert.False(t, clockCorrected)
+}
@@ -1,6 +1,12 @@
 package cmd
 
-import "github.com/urfave/cli"
+import (
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/urfave/cli"
+)
+
+// clk is used by the renewal checks and the daemon scheduling.
+var clk = clock.Real
 
 // CreateCommands Creates all CLI commands
 func CreateCommands() []cli.Command {
@@ -9,6 +15,22 @@ func CreateCommands() []cli.Command {
 		createRevoke(),
 		createRenew(),
 		createDNSHelp(),
+		createDNSHelper(),
 		createList(),
+		createVerify(),
+		createSelftest(),
+		createAccount(),
+		createConfig(),
+		createOrders(),
+		createExport(),
+		createImport(),
+		createAgent(),
+		createDNSProxy(),
+		createChallengeAgent(),
+		createSweep(),
+		createProviders(),
+		createDaemon(),
+		createService(),
+		createCompletion(),
 	}
 }
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"crypto"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/registration"
+	"github.com/urfave/cli"
+)
+
+func createAccount() cli.Command {
+	return cli.Command{
+		Name:  "account",
+		Usage: "Manage the ACME account",
+		Subcommands: []cli.Command{
+			{
+				Name:   "update",
+				Usage:  "Update the contact emails of the account (the account is selected by the global '--email' option)",
+				Action: withStorageLock(accountUpdate),
+				Flags: []cli.Flag{
+					cli.StringSliceFlag{
+						Name:  "email",
+						Usage: "The new contact email of the account. Can be specified multiple times, if the CA permits it.",
+					},
+				},
+			},
+			{
+				Name:   "key-change",
+				Usage:  "Replace the key of the account by a new key (e.g. to move an RSA account to ECDSA); the previous key is kept as <email>.key.old",
+				Action: withStorageLock(accountKeyChange),
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "key-type",
+						Usage: "Key type of the new account key. Supported: rsa2048, rsa3072, rsa4096, rsa8192, ec256, ec384.",
+					},
+				},
+			},
+			{
+				Name:   "recover",
+				Usage:  "Recover the registration of an account from its key when the account file is lost, without creating a new account",
+				Action: withStorageLock(accountRecover),
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "key",
+						Usage: "The account key (PEM) to import, if it is not in the keys directory of the account.",
+					},
+				},
+			},
+		},
+	}
+}
+
+func accountUpdate(ctx *cli.Context) error {
+	emails := ctx.StringSlice("email")
+	if len(emails) == 0 {
+		log.Fatal("Please specify at least one contact with --email")
+	}
+
+	accountsStorage := NewAccountsStorage(ctx)
+	if !accountsStorage.ExistsAccountFilePath() {
+		log.Fatalf("Could not find the account %s, please register it with the 'run' command.", accountsStorage.GetUserID())
+	}
+
+	account, client := setup(ctx, accountsStorage)
+
+	reg, err := client.Registration.UpdateContact(emails)
+	if err != nil {
+		log.Fatalf("Could not update the contact of the account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	account.Registration = reg
+
+	err = accountsStorage.Save(account)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("The contact of the account %s has been updated: %s", accountsStorage.GetUserID(), strings.Join(emails, ", "))
+
+	return nil
+}
+
+func accountKeyChange(ctx *cli.Context) error {
+	if !ctx.IsSet("key-type") {
+		log.Fatal("Please specify the type of the new key with --key-type")
+	}
+
+	if ctx.GlobalString("account-key-agent") != "" {
+		log.Fatal("The key of an account held by an agent cannot be changed.")
+	}
+
+	accountsStorage := NewAccountsStorage(ctx)
+	if !accountsStorage.ExistsAccountFilePath() {
+		log.Fatalf("Could not find the account %s, please register it with the 'run' command.", accountsStorage.GetUserID())
+	}
+
+	_, client := setup(ctx, accountsStorage)
+
+	keyType := parseKeyType(ctx.String("key-type"))
+
+	newKey, err := certcrypto.GeneratePrivateKey(keyType)
+	if err != nil {
+		log.Fatalf("Could not generate the new account key: %v", err)
+	}
+
+	err = accountsStorage.ReplacePrivateKey(newKey, func() error {
+		return client.Registration.ChangeKey(newKey)
+	})
+	if err != nil {
+		log.Fatalf("Could not change the key of the account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	log.Printf("The key of the account %s has been replaced by a %s key.", accountsStorage.GetUserID(), keyType)
+
+	return nil
+}
+
+func accountRecover(ctx *cli.Context) error {
+	accountsStorage := NewAccountsStorage(ctx)
+	if accountsStorage.ExistsAccountFilePath() {
+		log.Fatalf("The account %s already exists: nothing to recover.", accountsStorage.GetUserID())
+	}
+
+	privateKey := getRecoveryKey(ctx, accountsStorage)
+
+	reg, err := tryRecoverRegistration(ctx, privateKey)
+	if errors.Is(err, registration.ErrAccountDoesNotExist) {
+		log.Fatalf("No account is registered with the key of %s on %s: use the 'run' command to register a new account.",
+			accountsStorage.GetUserID(), ctx.GlobalString("server"))
+	}
+	if err != nil {
+		log.Fatalf("Could not recover the account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	if ctx.IsSet("key") {
+		accountsStorage.createKeysFolder()
+
+		passphrase, errP := getAccountPassphrase()
+		if errP != nil {
+			storageFatalf("%v", errP)
+		}
+
+		err = savePrivateKey(accountsStorage.getPrivateKeyPath(), privateKey, passphrase)
+		if err != nil {
+			storageFatalf("Could not save the account key: %v", err)
+		}
+	}
+
+	account := &Account{Email: accountsStorage.GetUserID(), Registration: reg, key: privateKey}
+
+	err = accountsStorage.Save(account)
+	if err != nil {
+		storageFatalf("Could not save the account %s: %v", accountsStorage.GetUserID(), err)
+	}
+
+	log.Printf("The account %s has been recovered: %s (%s)", accountsStorage.GetUserID(), reg.URI, reg.Body.Status)
+
+	return nil
+}
+
+// getRecoveryKey loads the key of the account to recover: the key given with --key, or the key of the keys directory.
+// Unlike getAccountKey, no key is generated.
+func getRecoveryKey(ctx *cli.Context, accountsStorage *AccountsStorage) crypto.PrivateKey {
+	if ctx.GlobalString("account-key-agent") != "" {
+		return getAccountKey(ctx, accountsStorage, "")
+	}
+
+	passphrase, err := getAccountPassphrase()
+	if err != nil {
+		storageFatalf("%v", err)
+	}
+
+	file := ctx.String("key")
+	if file == "" {
+		file = accountsStorage.getPrivateKeyPath()
+	} else if _, err = os.Stat(accountsStorage.getPrivateKeyPath()); err == nil {
+		log.Fatalf("The account %s already has a key (%s): remove it, or recover the account without --key.",
+			accountsStorage.GetUserID(), accountsStorage.getPrivateKeyPath())
+	}
+
+	if _, err = os.Stat(file); os.IsNotExist(err) {
+		log.Fatalf("No key found for account %s (%s): use --key to import the account key.", accountsStorage.GetUserID(), file)
+	}
+
+	privateKey, _, err := loadPrivateKey(file, passphrase)
+	if err != nil {
+		storageFatalf("Could not load the private key %s: %v", file, err)
+	}
+
+	return privateKey
+}
+
+// checkAccountKeyType warns when the account key doesn't match the requested type (--account-key-type):
+// the option is only used to generate the account keys.
+func checkAccountKeyType(ctx *cli.Context, accountsStorage *AccountsStorage, privateKey crypto.PrivateKey) {
+	if !ctx.GlobalIsSet("account-key-type") {
+		return
+	}
+
+	current := keyTypeOf(privateKey)
+	if current == "" || current == getAccountKeyType(ctx) {
+		return
+	}
+
+	log.Warnf("The account key of %s is a %s key, not a %s key. Use 'lego account key-change --key-type %s' to change it.",
+		accountsStorage.GetUserID(), current, getAccountKeyType(ctx), ctx.GlobalString("account-key-type"))
+}
+
+// keyTypeOf returns the type of a private key, empty if unknown.
+func keyTypeOf(privateKey crypto.PrivateKey) certcrypto.KeyType {
+	return certcrypto.KeyTypeOf(privateKey)
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func Test_keyTypeOf(t *testing.T) {
+	for _, keyType := range []certcrypto.KeyType{certcrypto.EC256, certcrypto.EC384, certcrypto.RSA2048} {
+		privateKey, err := certcrypto.GeneratePrivateKey(keyType)
+		require.NoError(t, err)
+
+		assert.Equal(t, keyType, keyTypeOf(privateKey))
+	}
+
+	assert.Empty(t, keyTypeOf(nil))
+}
+
+func TestAccountsStorage_ReplacePrivateKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-keys")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	accountsStorage := &AccountsStorage{userID: "foo@example.com", keysPath: dir}
+	accKeyPath := filepath.Join(dir, "foo@example.com.key")
+
+	oldKey, err := generatePrivateKey(accKeyPath, certcrypto.RSA2048, nil)
+	require.NoError(t, err)
+
+	newKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	require.NoError(t, err)
+
+	err = accountsStorage.ReplacePrivateKey(newKey, func() error {
+		return errors.New("rejected")
+	})
+	require.EqualError(t, err, "rejected")
+
+	current, _, err := loadPrivateKey(accKeyPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, oldKey, current)
+	assert.NoFileExists(t, accKeyPath+".new")
+
+	err = accountsStorage.ReplacePrivateKey(newKey, func() error {
+		pending, _, errL := loadPrivateKey(accKeyPath+".new", nil)
+		require.NoError(t, errL)
+		assert.Equal(t, newKey, pending)
+		return nil
+	})
+	require.NoError(t, err)
+
+	current, _, err = loadPrivateKey(accKeyPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, newKey, current)
+
+	previous, _, err := loadPrivateKey(accKeyPath+".old", nil)
+	require.NoError(t, err)
+	assert.Equal(t, oldKey, previous)
+}
+
+func Test_accountRecover(t *testing.T) {
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, req *http.Request) {
+		var jws struct {
+			Payload string `json:"payload"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&jws))
+
+		payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+		require.NoError(t, err)
+
+		// the account must not be created.
+		assert.JSONEq(t, `{"onlyReturnExisting":true}`, string(payload))
+
+		w.Header().Set("Location", apiURL+"/account/1")
+		_, _ = w.Write([]byte("{}"))
+	})
+
+	mux.HandleFunc("/account/1", func(w http.ResponseWriter, _ *http.Request) {
+		_ = tester.WriteJSONResponse(w, acme.Account{Status: "valid", Contact: []string{"mailto:foo@example.com"}})
+	})
+
+	dir, err := ioutil.TempDir("", "lego-recover")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	keyFile := filepath.Join(dir, "kept.key")
+	privateKey, err := generatePrivateKey(keyFile, certcrypto.EC256, nil)
+	require.NoError(t, err)
+
+	app := cli.NewApp()
+	app.Flags = CreateFlags("")
+	app.Commands = []cli.Command{createAccount()}
+
+	err = app.Run([]string{"lego", "--path", dir, "--server", apiURL + "/dir", "-m", "foo@example.com", "account", "recover", "--key", keyFile})
+	require.NoError(t, err)
+
+	serverDir, err := serverPath(apiURL + "/dir")
+	require.NoError(t, err)
+
+	userPath := filepath.Join(dir, baseAccountsRootFolderName, serverDir, "foo@example.com")
+
+	raw, err := ioutil.ReadFile(filepath.Join(userPath, accountFileName))
+	require.NoError(t, err)
+
+	var account Account
+	require.NoError(t, json.Unmarshal(raw, &account))
+
+	assert.Equal(t, "foo@example.com", account.Email)
+	require.NotNil(t, account.Registration)
+	assert.Equal(t, apiURL+"/account/1", account.Registration.URI)
+	assert.Equal(t, "valid", account.Registration.Body.Status)
+
+	importedKey, _, err := loadPrivateKey(filepath.Join(userPath, baseKeysFolderName, "foo@example.com.key"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, importedKey)
+}
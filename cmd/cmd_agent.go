@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"crypto"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/registration/agent"
+	"github.com/urfave/cli"
+)
+
+func createAgent() cli.Command {
+	return cli.Command{
+		Name:   "agent",
+		Usage:  "Run an agent holding the account key, used by the other lego processes with '--account-key-agent'",
+		Action: runAgent,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "socket",
+				Usage: "The path of the Unix socket of the agent.",
+			},
+		},
+	}
+}
+
+func runAgent(ctx *cli.Context) error {
+	socketPath := ctx.String("socket")
+	if socketPath == "" {
+		log.Fatal("Please specify the path of the Unix socket with --socket")
+	}
+
+	accountsStorage := NewAccountsStorage(ctx)
+
+	// the key can be generated: the storage is only locked while loading it, not while serving it.
+	unlock := newStorageLock(ctx).acquire()
+	signer, ok := accountsStorage.GetPrivateKey(getAccountKeyType(ctx)).(crypto.Signer)
+	unlock()
+
+	if !ok {
+		log.Fatalf("The account key of %s cannot be used to sign", accountsStorage.GetUserID())
+	}
+
+	// only the owner of the socket can use the agent.
+	listener, err := listenUnix(socketPath)
+	if err != nil {
+		log.Fatalf("Could not listen on %s: %v", socketPath, err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	stopped := make(chan struct{})
+	go func() {
+		sig := <-signals
+		log.Infof("agent: received %s, stopping", sig)
+		close(stopped)
+		_ = listener.Close()
+	}()
+
+	log.Printf("The agent of the account %s is listening on %s", accountsStorage.GetUserID(), socketPath)
+
+	err = agent.Serve(listener, signer)
+
+	select {
+	case <-stopped:
+		return nil
+	default:
+		return err
+	}
+}
+
+// getAccountKey returns the account key, or a signer delegating the signatures to an agent.
+func getAccountKey(ctx *cli.Context, accountsStorage *AccountsStorage, keyType certcrypto.KeyType) crypto.PrivateKey {
+	socketPath := ctx.GlobalString("account-key-agent")
+	if socketPath == "" {
+		return accountsStorage.GetPrivateKey(keyType)
+	}
+
+	signer, err := agent.Dial(socketPath)
+	if err != nil {
+		log.Fatalf("Could not connect to the account key agent: %v", err)
+	}
+
+	return signer
+}
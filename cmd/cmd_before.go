@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/go-acme/lego/v3/log"
 	"github.com/urfave/cli"
 )
@@ -19,5 +20,42 @@ func Before(ctx *cli.Context) error {
 		log.Fatal("Could not determine current working server. Please pass --server.")
 	}
 
+	err = resolveServerPreset(ctx)
+	if err != nil {
+		log.Fatalf("Could not set the server: %v", err)
+	}
+
+	if ctx.GlobalBool("debug") {
+		log.Debug = true
+	}
+
+	if ctx.GlobalBool("fips") {
+		certcrypto.SetFIPSMode(true)
+	}
+
+	if certcrypto.FIPSMode() {
+		setupFIPSMode(ctx)
+	}
+
 	return nil
 }
+
+// setupFIPSMode checks the FIPS module of the Go toolchain, and the key types.
+func setupFIPSMode(ctx *cli.Context) {
+	module, err := certcrypto.CheckFIPSModule()
+	if err != nil {
+		log.Fatalf("FIPS mode: %v (GODEBUG=fips140=on)", err)
+	}
+
+	if module == "" {
+		log.Warnf("FIPS mode: the Go toolchain has no FIPS module, only the key types are restricted")
+	} else {
+		log.Infof("FIPS mode: using the %s module", module)
+	}
+
+	for _, keyType := range []certcrypto.KeyType{getKeyType(ctx), getAccountKeyType(ctx)} {
+		if err := certcrypto.CheckFIPSKeyType(keyType); err != nil {
+			log.Fatalf("FIPS mode: %v", err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/remote"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+func createChallengeAgent() cli.Command {
+	return cli.Command{
+		Name:   "challenge-agent",
+		Usage:  "Solve the HTTP (--http) and DNS (--dns) challenges for the lego instances using '--challenge-agent', over mutual TLS",
+		Action: runChallengeAgent,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "listen",
+				Usage: "The address to listen on.",
+				Value: ":9443",
+			},
+			cli.StringFlag{
+				Name:  "tls-cert",
+				Usage: "The path of the certificate (PEM) of the agent.",
+			},
+			cli.StringFlag{
+				Name:  "tls-key",
+				Usage: "The path of the private key (PEM) of the agent.",
+			},
+			cli.StringFlag{
+				Name:  "tls-ca",
+				Usage: "The path of the CA certificate (PEM) verifying the client certificates.",
+			},
+		},
+	}
+}
+
+func runChallengeAgent(ctx *cli.Context) error {
+	if ctx.GlobalIsSet("challenge-agent") {
+		log.Fatal("The challenge agent can't delegate its challenges to another agent (--challenge-agent)")
+	}
+
+	providers := make(map[challenge.Type]challenge.Provider)
+
+	if ctx.GlobalBool("http") {
+		providers[challenge.HTTP01] = setupHTTPProvider(ctx)
+	}
+
+	if ctx.GlobalIsSet("dns") {
+		provider, err := getDNSProvider(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		providers[challenge.DNS01] = provider
+	}
+
+	if len(providers) == 0 {
+		log.Fatal("No challenge selected. You must specify at least one challenge: `--http`, `--dns`.")
+	}
+
+	tlsConfig, err := remote.ServerTLSConfig(ctx.String("tls-cert"), ctx.String("tls-key"), ctx.String("tls-ca"))
+	if err != nil {
+		log.Fatalf("challenge-agent: %v (--tls-cert, --tls-key, --tls-ca)", err)
+	}
+
+	listener, err := net.Listen("tcp", ctx.String("listen"))
+	if err != nil {
+		log.Fatalf("Could not listen on %s: %v", ctx.String("listen"), err)
+	}
+
+	server := remote.NewServer(providers, tlsConfig)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		log.Infof("challenge-agent: received %s, stopping", sig)
+		server.Stop()
+	}()
+
+	log.Printf("The challenge agent is listening on %s", listener.Addr())
+
+	return server.Serve(listener)
+}
+
+// getChallengeAgentProvider returns a provider delegating the challenges of a type to the agent set with --challenge-agent.
+func getChallengeAgentProvider(ctx *cli.Context, chlg challenge.Type) challenge.Provider {
+	tlsConfig, err := remote.ClientTLSConfig(
+		ctx.GlobalString("challenge-agent.cert"), ctx.GlobalString("challenge-agent.key"), ctx.GlobalString("challenge-agent.ca"))
+	if err != nil {
+		log.Fatalf("Challenge agent: %v (--challenge-agent.cert, --challenge-agent.key, --challenge-agent.ca)", err)
+	}
+
+	client, err := remote.Dial(context.Background(), ctx.GlobalString("challenge-agent"), tlsConfig)
+	if err != nil {
+		log.Fatalf("Could not connect to the challenge agent: %v", err)
+	}
+
+	return client.Provider(chlg)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+const bashCompletion = `#! /bin/bash
+
+_lego_bash_autocomplete() {
+  if [[ "${COMP_WORDS[0]}" != "source" ]]; then
+    local cur opts
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ "$cur" == "-"* ]]; then
+      opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} ${cur} --generate-bash-completion 2>/dev/null )
+    else
+      opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion 2>/dev/null )
+    fi
+    COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
+    return 0
+  fi
+}
+
+complete -o bashdefault -o default -o nospace -F _lego_bash_autocomplete %[1]s
+`
+
+const zshCompletion = `#compdef %[1]s
+
+_lego_zsh_autocomplete() {
+  local -a opts
+  opts=("${(@f)$(_CLI_ZSH_AUTOCOMPLETE_HACK=1 ${words[@]:0:#words[@]-1} --generate-bash-completion 2>/dev/null)}")
+
+  _describe 'values' opts
+
+  return
+}
+
+compdef _lego_zsh_autocomplete %[1]s
+`
+
+func createCompletion() cli.Command {
+	return cli.Command{
+		Name:      "completion",
+		Usage:     "Output shell completion code for the specified shell (bash, zsh or fish)",
+		ArgsUsage: "bash|zsh|fish",
+		Action:    completion,
+		BashComplete: func(ctx *cli.Context) {
+			for _, shell := range []string{"bash", "zsh", "fish"} {
+				_, _ = fmt.Fprintln(ctx.App.Writer, shell)
+			}
+		},
+	}
+}
+
+func completion(ctx *cli.Context) error {
+	shell := ctx.Args().First()
+
+	switch shell {
+	case "bash":
+		_, err := fmt.Fprintf(ctx.App.Writer, bashCompletion, ctx.App.Name)
+		return err
+	case "zsh":
+		_, err := fmt.Fprintf(ctx.App.Writer, zshCompletion, ctx.App.Name)
+		return err
+	case "fish":
+		script, err := ctx.App.ToFishCompletion()
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(ctx.App.Writer, script)
+		if err != nil {
+			return err
+		}
+
+		// the DNS codes are not known by the generated script.
+		for _, info := range sortedDNSProviders() {
+			_, err = fmt.Fprintf(ctx.App.Writer, "complete -c %s -f -l dns -a '%s' -d '%s'\n",
+				ctx.App.Name, info.Code, strings.Replace(dnsCodeDescription(info), "'", `\'`, -1))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported shell %q: supported shells are bash, zsh and fish", shell)
+	}
+}
+
+// AppComplete completes the commands and the global options,
+// and the DNS codes when the previous argument is the '--dns' option.
+// The descriptions of the DNS codes (zsh and fish) list the environment variables of the providers.
+func AppComplete(ctx *cli.Context) {
+	if previousArg() == "--dns" {
+		printDNSCodes(ctx)
+		return
+	}
+
+	cli.DefaultAppComplete(ctx)
+}
+
+// completeDNSCode completes the flags of a command,
+// and the DNS codes when the previous argument is the '--code' option.
+func completeDNSCode(ctxCmd *cli.Context) {
+	switch previousArg() {
+	case "--code", "-c":
+		printDNSCodes(ctxCmd)
+	default:
+		cli.DefaultCompleteWithFlags(&ctxCmd.Command)(ctxCmd)
+	}
+}
+
+// previousArg returns the argument preceding the completion flag.
+func previousArg() string {
+	args := os.Args
+	if len(args) > 0 && args[len(args)-1] == "--"+cli.BashCompletionFlag.GetName() {
+		args = args[:len(args)-1]
+	}
+
+	if len(args) < 2 {
+		return ""
+	}
+
+	return args[len(args)-1]
+}
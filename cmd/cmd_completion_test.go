@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+// newTestApp returns an app with the flags and the commands of lego, writing to the buffer.
+func newTestApp(out *bytes.Buffer) *cli.App {
+	app := cli.NewApp()
+	app.Name = "lego"
+	app.Writer = out
+	app.EnableBashCompletion = true
+	app.BashComplete = AppComplete
+	app.Flags = CreateFlags("")
+	app.Commands = CreateCommands()
+	app.Action = func(*cli.Context) error { return nil }
+
+	return app
+}
+
+func Test_completion(t *testing.T) {
+	testCases := []struct {
+		shell    string
+		expected []string
+	}{
+		{
+			shell:    "bash",
+			expected: []string{"complete -o bashdefault -o default -o nospace -F _lego_bash_autocomplete lego"},
+		},
+		{
+			shell:    "zsh",
+			expected: []string{"#compdef lego", "compdef _lego_zsh_autocomplete lego"},
+		},
+		{
+			shell: "fish",
+			expected: []string{
+				"complete -c lego -n '__fish_lego_no_subcommand' -f -l dns",
+				"complete -c lego -f -l dns -a 'cloudflare' -d 'Cloudflare (CF_API_EMAIL, CF_API_KEY, ",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.shell, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			err := newTestApp(out).Run([]string{"lego", "completion", test.shell})
+			require.NoError(t, err)
+
+			for _, expected := range test.expected {
+				assert.Contains(t, out.String(), expected)
+			}
+		})
+	}
+}
+
+func Test_completion_unsupported(t *testing.T) {
+	err := newTestApp(&bytes.Buffer{}).Run([]string{"lego", "completion", "powershell"})
+	require.EqualError(t, err, `unsupported shell "powershell": supported shells are bash, zsh and fish`)
+}
+
+func TestAppComplete_dns(t *testing.T) {
+	args := []string{"lego", "--dns", "--generate-bash-completion"}
+
+	backupArgs := os.Args
+	defer func() { os.Args = backupArgs }()
+	os.Args = args
+
+	out := &bytes.Buffer{}
+	require.NoError(t, newTestApp(out).Run(args))
+
+	codes := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Contains(t, codes, "cloudflare")
+	assert.Contains(t, codes, "route53")
+	assert.NotContains(t, codes, "run")
+
+	// zsh displays the environment variables of the providers.
+	require.NoError(t, os.Setenv("_CLI_ZSH_AUTOCOMPLETE_HACK", "1"))
+	defer func() { _ = os.Unsetenv("_CLI_ZSH_AUTOCOMPLETE_HACK") }()
+
+	out.Reset()
+	require.NoError(t, newTestApp(out).Run(args))
+
+	assert.Contains(t, out.String(), "\nexoscale:Exoscale (EXOSCALE_API_KEY, EXOSCALE_API_SECRET, ")
+	assert.Contains(t, out.String(), "\nexec:External program\n")
+}
+
+func TestAppComplete_commands(t *testing.T) {
+	args := []string{"lego", "--generate-bash-completion"}
+
+	backupArgs := os.Args
+	defer func() { os.Args = backupArgs }()
+	os.Args = args
+
+	out := &bytes.Buffer{}
+	require.NoError(t, newTestApp(out).Run(args))
+
+	commands := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Contains(t, commands, "run")
+	assert.Contains(t, commands, "completion")
+	assert.NotContains(t, commands, "cloudflare")
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+func createDaemon() cli.Command {
+	renewCmd := createRenew()
+
+	return cli.Command{
+		Name:   "daemon",
+		Usage:  "Run in the foreground and renew a certificate periodically",
+		Action: daemon,
+		Before: func(ctx *cli.Context) error {
+			// the domains are defined by the configuration file.
+			if ctx.IsSet("config") {
+				return nil
+			}
+			return renewCmd.Before(ctx)
+		},
+		Flags: append(renewCmd.Flags,
+			cli.DurationFlag{
+				Name:  "interval",
+				Value: 12 * time.Hour,
+				Usage: "The time between two renewal checks.",
+			},
+			cli.DurationFlag{
+				Name:  "jitter",
+				Usage: "Delay each renewal check by a random duration up to this value, so the renewals of a fleet don't hit the CA simultaneously.",
+			},
+			cli.StringFlag{
+				Name:  "renew-window",
+				Usage: "Only check the renewals during this window of the day, in local time (HH:MM-HH:MM, e.g. 02:00-05:00, or 22:00-02:00). The checks are delayed to the next window.",
+			},
+			cli.StringSliceFlag{
+				Name:  "renew-window.exclude-days",
+				Usage: "Never check the renewals on these days of the week (mon, tue, wed, thu, fri, sat, sun). Can be specified multiple times.",
+			},
+			cli.StringFlag{
+				Name:  "config",
+				Usage: "A JSON file defining the certificates to renew, with their options. The file is reloaded when modified (or on SIGHUP).",
+			},
+			cli.StringFlag{
+				Name:  "api",
+				Usage: "Serve the management API on a Unix socket (unix:<path>) or on a TCP address (requires --api.token).",
+			},
+			cli.StringFlag{
+				Name:   "api.token",
+				Usage:  "The bearer token expected by the management API.",
+				EnvVar: "LEGO_API_TOKEN",
+			},
+			cli.StringFlag{
+				Name:  "journal",
+				Usage: "Record the scheduled checks, the renewals and the hooks in a journal file: after a crash, the daemon resumes the interrupted renewals and runs the pending hooks once.",
+			},
+			cli.StringFlag{
+				Name:  "delivery.socket",
+				Usage: "Serve the certificates and their private keys to the local services on a Unix socket (gRPC), with a notification on each renewal.",
+			},
+		),
+	}
+}
+
+func daemon(ctx *cli.Context) error {
+	interval := ctx.Duration("interval")
+	if interval <= 0 {
+		log.Fatalf("The interval must be positive: %s", interval)
+	}
+
+	jobs, err := newDaemonJobsFromContext(ctx)
+	if err != nil {
+		log.Fatalf("daemon: %v", err)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	if jobs.file != "" {
+		go jobs.watch(stop)
+	}
+
+	if ctx.IsSet("api") || ctx.IsSet("delivery.socket") {
+		jobs.events = newEventBroker()
+	}
+
+	if ctx.IsSet("api") {
+		api := &daemonAPI{jobs: jobs, certsStorage: jobs.certsStorage, token: ctx.String("api.token")}
+
+		shutdown, err := serveDaemonAPI(ctx.String("api"), api)
+		if err != nil {
+			log.Fatalf("daemon: management API: %v", err)
+		}
+		defer shutdown()
+	}
+
+	if ctx.IsSet("delivery.socket") {
+		shutdown, err := serveDelivery(ctx.String("delivery.socket"), jobs.certsStorage, jobs.events)
+		if err != nil {
+			log.Fatalf("daemon: certificate delivery: %v", err)
+		}
+		defer shutdown()
+	}
+
+	window, err := parseRenewalWindow(ctx.String("renew-window"), ctx.StringSlice("renew-window.exclude-days"))
+	if err != nil {
+		log.Fatalf("daemon: %v", err)
+	}
+
+	plan := &daemonPlan{interval: interval, jitter: ctx.Duration("jitter"), window: window}
+
+	if ctx.IsSet("journal") {
+		setupJournal(ctx.String("journal"), jobs, plan)
+	}
+
+	return runDaemon(ctx.App.Name, plan, func() { jobs.run() })
+}
+
+// setupJournal resumes the work interrupted by a crash, and the schedule of the periodic checks.
+func setupJournal(path string, jobs *daemonJobs, plan *daemonPlan) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		log.Fatalf("daemon: journal: %v", err)
+	}
+
+	j, err := openJournal(path)
+	if err != nil {
+		log.Fatalf("daemon: journal: %v", err)
+	}
+
+	// the renew commands run by the daemon record their progress in the journal.
+	err = os.Setenv(envDaemonJournal, path)
+	if err != nil {
+		log.Fatalf("daemon: journal: %v", err)
+	}
+
+	jobs.journal = j
+
+	plan.delay, err = jobs.recover(j)
+	if err != nil {
+		log.Fatalf("daemon: journal: %v", err)
+	}
+
+	plan.scheduled = func(at time.Time) {
+		j.record(journalEntry{Type: journalScheduled, At: at.UTC()})
+	}
+}
+
+// newDaemonJobsFromContext creates the renewal jobs of the certificates defined by the configuration file (--config),
+// or of the certificate defined by the command line.
+func newDaemonJobsFromContext(ctx *cli.Context) (*daemonJobs, error) {
+	if ctx.IsSet("config") {
+		jobs, err := newDaemonJobs(ctx, ctx.String("config"))
+		if err != nil {
+			return nil, err
+		}
+
+		jobs.certsStorage = NewCertificatesStorage(ctx)
+		return jobs, nil
+	}
+
+	args := globalArgs(ctx)
+	args = append(args, "renew")
+	args = append(args, flagsToArgs(createRenew().Flags, ctx.IsSet, ctx.Generic)...)
+
+	domain := ctx.GlobalString("csr")
+	if domains := normalizedDomains(ctx); len(domains) > 0 {
+		domain = domains[0]
+	}
+
+	return &daemonJobs{
+		jobs:         map[string][]string{domain: args},
+		renew:        renewOnce,
+		certsStorage: NewCertificatesStorage(ctx),
+	}, nil
+}
+
+// schedule runs the job at the times of the plan until stop is closed.
+func schedule(stop <-chan struct{}, plan *daemonPlan, job func()) {
+	at := plan.first(clk.Now())
+
+	for {
+		if wait := at.Sub(clk.Now()); wait > 0 {
+			log.Infof("daemon: the next check is at %s", at.Format(time.RFC3339))
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-clk.After(at.Sub(clk.Now())):
+			job()
+		}
+
+		at = plan.next(clk.Now())
+	}
+}
+
+// runForeground runs the job at the times of the plan until the process receives an interrupt or a termination signal.
+func runForeground(plan *daemonPlan, job func()) {
+	stop := make(chan struct{})
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		log.Infof("daemon: received %s, stopping", sig)
+		close(stop)
+	}()
+
+	schedule(stop, plan, job)
+}
+
+// renewOnce runs the renew command in a child process.
+// The renew command exits on errors, running it in a child process keeps the daemon alive.
+func renewOnce(args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("daemon: checking certificate renewal")
+
+	output := logWriter()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	return cmd.Run()
+}
+
+// globalArgs rebuilds the global options used to run the current command.
+func globalArgs(ctx *cli.Context) []string {
+	return flagsToArgs(ctx.App.Flags, ctx.GlobalIsSet, ctx.GlobalGeneric)
+}
+
+// flagsToArgs rebuilds the command line arguments of the flags which are set.
+func flagsToArgs(flags []cli.Flag, isSet func(string) bool, value func(string) interface{}) []string {
+	var args []string
+
+	for _, flag := range flags {
+		name := flagName(flag)
+		if !isSet(name) {
+			continue
+		}
+
+		switch f := flag.(type) {
+		case cli.BoolFlag:
+			args = append(args, "--"+name)
+		case cli.StringSliceFlag:
+			if v, ok := value(name).(*cli.StringSlice); ok {
+				for _, s := range v.Value() {
+					args = append(args, "--"+name, s)
+				}
+			}
+		case cli.IntFlag:
+			args = append(args, "--"+name, fmt.Sprint(value(name)))
+		case cli.DurationFlag:
+			args = append(args, "--"+name, fmt.Sprint(value(name)))
+		case cli.StringFlag:
+			args = append(args, "--"+name, fmt.Sprint(value(name)))
+		default:
+			log.Warnf("daemon: unsupported flag type %T for %q", f, name)
+		}
+	}
+
+	return args
+}
+
+// flagName returns the long name of a flag.
+func flagName(flag cli.Flag) string {
+	return strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_schedule(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+
+	clk = fake
+	defer func() { clk = clock.Real }()
+
+	var calls int32
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		schedule(stop, &daemonPlan{interval: time.Hour}, func() { atomic.AddInt32(&calls, 1) })
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		fake.Advance(time.Hour)
+		return atomic.LoadInt32(&calls) >= 3
+	}, time.Second, time.Millisecond)
+
+	close(stop)
+	<-done
+}
@@ -12,9 +12,10 @@ import (
 
 func createDNSHelp() cli.Command {
 	return cli.Command{
-		Name:   "dnshelp",
-		Usage:  "Shows additional help for the '--dns' global option",
-		Action: dnsHelp,
+		Name:         "dnshelp",
+		Usage:        "Shows additional help for the '--dns' global option",
+		Action:       dnsHelp,
+		BashComplete: completeDNSCode,
 		Flags: []cli.Flag{
 			cli.StringFlag{
 				Name:  "code, c",
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+	"golang.org/x/net/idna"
+)
+
+func createDNSHelper() cli.Command {
+	return cli.Command{
+		Name:  "dnshelper",
+		Usage: "Troubleshoot the DNS-01 challenge",
+		Subcommands: []cli.Command{
+			{
+				Name:      "trace",
+				Usage:     "Trace the resolution of the '_acme-challenge' record of the domains (CNAMEs, zone, authoritative name servers, TXT values, DNSSEC)",
+				ArgsUsage: "domain...",
+				Action:    dnsTrace,
+			},
+		},
+	}
+}
+
+func dnsTrace(ctx *cli.Context) error {
+	if !ctx.Args().Present() {
+		log.Fatal("Please specify at least one domain")
+	}
+
+	var resolvers []string
+	if ctx.GlobalIsSet("dns.resolvers") {
+		resolvers = dns01.ParseNameservers(ctx.GlobalStringSlice("dns.resolvers"))
+	}
+
+	for i, domain := range ctx.Args() {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		report, err := dns01.Trace(challengeRecordName(domain), resolvers)
+		if report != nil {
+			fmt.Println(report)
+		}
+		if err != nil {
+			log.Warnf("[%s] %v", domain, err)
+		}
+	}
+
+	return nil
+}
+
+// challengeRecordName returns the name of the TXT record of the DNS-01 challenge of the domain.
+func challengeRecordName(domain string) string {
+	domain = strings.TrimPrefix(strings.TrimSpace(domain), "*.")
+
+	// the records of the internationalized domains are named with their A-labels.
+	if ascii, err := idna.ToASCII(domain); err == nil {
+		domain = ascii
+	}
+
+	if strings.HasPrefix(domain, "_acme-challenge.") {
+		return domain
+	}
+	return "_acme-challenge." + domain
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_challengeRecordName(t *testing.T) {
+	assert.Equal(t, "_acme-challenge.example.com", challengeRecordName("example.com"))
+	assert.Equal(t, "_acme-challenge.example.com", challengeRecordName("*.example.com"))
+	assert.Equal(t, "_acme-challenge.example.com", challengeRecordName("_acme-challenge.example.com"))
+	assert.Equal(t, "_acme-challenge.xn--bcher-kva.example", challengeRecordName("bücher.example"))
+}
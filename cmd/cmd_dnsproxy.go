@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/providers/dns"
+	"github.com/go-acme/lego/v3/providers/dns/httpreq"
+	"github.com/urfave/cli"
+)
+
+func createDNSProxy() cli.Command {
+	return cli.Command{
+		Name:   "dns-proxy",
+		Usage:  "Serve the DNS provider selected with '--dns' to the other lego instances using the 'httpreq' provider in RAW mode",
+		Action: runDNSProxy,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "listen",
+				Usage: "The address to listen on.",
+				Value: "127.0.0.1:9090",
+			},
+			cli.StringFlag{
+				Name:  "tls-cert",
+				Usage: "The path of the certificate (PEM) used to serve HTTPS.",
+			},
+			cli.StringFlag{
+				Name:  "tls-key",
+				Usage: "The path of the private key (PEM) used to serve HTTPS.",
+			},
+			cli.StringFlag{
+				Name:   "username",
+				Usage:  "The basic authentication username expected from the clients.",
+				EnvVar: "LEGO_DNS_PROXY_USERNAME",
+			},
+			cli.StringFlag{
+				Name:   "password",
+				Usage:  "The basic authentication password expected from the clients.",
+				EnvVar: "LEGO_DNS_PROXY_PASSWORD",
+			},
+			cli.BoolFlag{
+				Name:  "insecure-no-auth",
+				Usage: "Serve the DNS provider without authentication: anybody reaching the address can change the DNS records.",
+			},
+			cli.StringSliceFlag{
+				Name:  "allowed-domain",
+				Usage: "Only serve the challenges of this domain and of its subdomains. Can be repeated.",
+			},
+		},
+	}
+}
+
+func runDNSProxy(ctx *cli.Context) error {
+	if !ctx.GlobalIsSet("dns") {
+		log.Fatal("Please specify the DNS provider to serve with --dns")
+	}
+
+	if ctx.String("tls-cert") == "" != (ctx.String("tls-key") == "") {
+		log.Fatal("Both --tls-cert and --tls-key must be set to serve HTTPS")
+	}
+
+	provider, err := dns.NewDNSChallengeProviderByName(ctx.GlobalString("dns"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	username, password := ctx.String("username"), ctx.String("password")
+
+	switch {
+	case username == "" && password == "":
+		if !ctx.Bool("insecure-no-auth") {
+			log.Fatal("Please specify the credentials expected from the clients with --username and --password, or use --insecure-no-auth")
+		}
+		log.Warnf("dns-proxy: no basic authentication, anybody reaching %s can change the DNS records", ctx.String("listen"))
+	case username == "" || password == "":
+		log.Fatal("Both --username and --password must be set to use the basic authentication")
+	}
+
+	if len(ctx.StringSlice("allowed-domain")) == 0 {
+		log.Warnf("dns-proxy: no allowed domain, the challenges of any domain are served")
+	}
+
+	server := &http.Server{
+		Addr:         ctx.String("listen"),
+		Handler:      httpreq.NewHandler(provider, username, password, ctx.StringSlice("allowed-domain")...),
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 5 * time.Minute,
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		log.Infof("dns-proxy: received %s, stopping", sig)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("The DNS provider %s is served on %s", ctx.GlobalString("dns"), server.Addr)
+
+	if ctx.String("tls-cert") != "" {
+		err = server.ListenAndServeTLS(ctx.String("tls-cert"), ctx.String("tls-key"))
+	} else {
+		log.Warnf("dns-proxy: serving plain HTTP, the credentials and the key authorizations are sent in clear text")
+		err = server.ListenAndServe()
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
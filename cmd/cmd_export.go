@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+func createExport() cli.Command {
+	return cli.Command{
+		Name:   "export",
+		Usage:  "Export the accounts, keys and certificates to a bundle, to move them to another host with 'import'",
+		Action: export,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "bundle",
+				Usage: "The path of the bundle (tar.gz) to create.",
+			},
+			cli.BoolFlag{
+				Name:  "encrypt",
+				Usage: "Encrypt the bundle with the passphrase of LEGO_ACCOUNT_PASSPHRASE or LEGO_ACCOUNT_PASSPHRASE_FILE.",
+			},
+			cli.BoolFlag{
+				Name:  "archives",
+				Usage: "Include the archived certificates.",
+			},
+		},
+	}
+}
+
+func export(ctx *cli.Context) error {
+	bundlePath := ctx.String("bundle")
+	if bundlePath == "" {
+		log.Fatal("Please specify the bundle to create with --bundle")
+	}
+
+	var passphrase []byte
+	if ctx.Bool("encrypt") {
+		var err error
+		passphrase, err = getAccountPassphrase()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(passphrase) == 0 {
+			log.Fatalf("Please set %s or %s to encrypt the bundle", envAccountPassphrase, envAccountPassphraseFile)
+		}
+	}
+
+	file, err := os.OpenFile(bundlePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, filePerm)
+	if err != nil {
+		log.Fatalf("Could not create the bundle: %v", err)
+	}
+
+	err = writeBundle(file, ctx.GlobalString("path"), ctx.Bool("archives"), passphrase)
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		_ = os.Remove(bundlePath)
+		log.Fatalf("Could not export %s: %v", ctx.GlobalString("path"), err)
+	}
+
+	log.Printf("The content of %s has been exported to %s", ctx.GlobalString("path"), bundlePath)
+
+	return nil
+}
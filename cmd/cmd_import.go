@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+func createImport() cli.Command {
+	return cli.Command{
+		Name:   "import",
+		Usage:  "Import the accounts, keys and certificates of a bundle created by 'export', or of a certbot configuration directory",
+		Action: withStorageLock(importBundle),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "bundle",
+				Usage: "The path of the bundle to import. An encrypted bundle requires LEGO_ACCOUNT_PASSPHRASE or LEGO_ACCOUNT_PASSPHRASE_FILE.",
+			},
+			cli.StringFlag{
+				Name:  "from-certbot",
+				Usage: "The certbot configuration directory to import (e.g. /etc/letsencrypt). The accounts without contact email are imported with the global '--email' option.",
+			},
+			cli.BoolFlag{
+				Name:  "overwrite",
+				Usage: "Overwrite the existing files.",
+			},
+		},
+	}
+}
+
+func importBundle(ctx *cli.Context) error {
+	bundlePath := ctx.String("bundle")
+	if (bundlePath == "") == (ctx.String("from-certbot") == "") {
+		log.Fatal("Please specify either the bundle to import with --bundle, or the certbot directory with --from-certbot")
+	}
+
+	passphrase, err := getAccountPassphrase()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if ctx.String("from-certbot") != "" {
+		return importFromCertbot(ctx, passphrase)
+	}
+
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		log.Fatalf("Could not open the bundle: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	files, err := readBundle(file, ctx.GlobalString("path"), ctx.Bool("overwrite"), passphrase)
+	if err != nil {
+		log.Fatalf("Could not import %s: %v", bundlePath, err)
+	}
+
+	for _, f := range files {
+		log.Infof("import: %s", f)
+	}
+
+	log.Printf("%d files imported from %s into %s", len(files), bundlePath, ctx.GlobalString("path"))
+
+	return nil
+}
+
+func importFromCertbot(ctx *cli.Context, passphrase []byte) error {
+	importer := &certbotImporter{
+		source:     ctx.String("from-certbot"),
+		root:       ctx.GlobalString("path"),
+		email:      ctx.GlobalString("email"),
+		overwrite:  ctx.Bool("overwrite"),
+		passphrase: passphrase,
+	}
+
+	accounts, err := importer.importAccounts()
+	if err != nil {
+		log.Fatalf("Could not import the certbot accounts: %v", err)
+	}
+
+	for _, account := range accounts {
+		log.Infof("import: account %s", account)
+	}
+
+	domains, err := importer.importCertificates()
+	if err != nil {
+		log.Fatalf("Could not import the certbot certificates: %v", err)
+	}
+
+	for _, domain := range domains {
+		log.Infof("import: certificate %s", domain)
+	}
+
+	log.Printf("%d accounts and %d certificates imported from %s into %s", len(accounts), len(domains), importer.source, importer.root)
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/urfave/cli"
+	"golang.org/x/net/idna"
 )
 
 func createList() cli.Command {
@@ -79,8 +80,8 @@ func listCertificates(ctx *cli.Context) error {
 		if names {
 			fmt.Println(pCert.Subject.CommonName)
 		} else {
-			fmt.Println("  Certificate Name:", pCert.Subject.CommonName)
-			fmt.Println("    Domains:", strings.Join(pCert.DNSNames, ", "))
+			fmt.Println("  Certificate Name:", toUnicodeDomain(pCert.Subject.CommonName))
+			fmt.Println("    Domains:", strings.Join(toUnicodeDomains(pCert.DNSNames), ", "))
 			fmt.Println("    Expiry Date:", pCert.NotAfter)
 			fmt.Println("    Certificate Path:", filename)
 			fmt.Println()
@@ -90,6 +91,23 @@ func listCertificates(ctx *cli.Context) error {
 	return nil
 }
 
+// toUnicodeDomains converts the domains to U-labels, to display them.
+func toUnicodeDomains(domains []string) []string {
+	var unicodeDomains []string
+	for _, domain := range domains {
+		unicodeDomains = append(unicodeDomains, toUnicodeDomain(domain))
+	}
+	return unicodeDomains
+}
+
+func toUnicodeDomain(domain string) string {
+	unicodeDomain, err := idna.ToUnicode(domain)
+	if err != nil {
+		return domain
+	}
+	return unicodeDomain
+}
+
 func listAccount(ctx *cli.Context) error {
 	// fake email, needed by NewAccountsStorage
 	if err := ctx.GlobalSet("email", "unknown"); err != nil {
@@ -127,6 +145,9 @@ func listAccount(ctx *cli.Context) error {
 		}
 
 		fmt.Println("  Email:", account.Email)
+		if contacts := account.Registration.Body.Contact; len(contacts) > 0 {
+			fmt.Println("  Contacts:", strings.Join(contacts, ", "))
+		}
 		fmt.Println("  Server:", uri.Host)
 		fmt.Println("  Path:", filepath.Dir(filename))
 		fmt.Println()
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+func createOrders() cli.Command {
+	return cli.Command{
+		Name:    "orders",
+		Aliases: []string{"order"},
+		Usage:   "Inspect the orders of the ACME account at the CA (the account is selected by the global '--email' option), or split an order between an offline host and a connected host",
+		Subcommands: []cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List the orders of the account. The CAs list the pending orders, and may omit the others.",
+				Action: ordersList,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "pending",
+						Usage: "Only display the pending orders (pending or ready), which count toward the pending authorizations rate limits.",
+					},
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "Display an order, with the status of its authorizations and the errors of its challenges",
+				ArgsUsage: "<order URL>",
+				Action:    ordersShow,
+			},
+			{
+				Name:      "resume",
+				Usage:     "Complete an existing order (e.g. created by another tool, or by a run which crashed): solve its pending authorizations with the enabled challenges, finalize it, and save the certificate",
+				ArgsUsage: "<order URL>",
+				Action:    withStorageLock(ordersResume),
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "key",
+						Usage: "The private key (PEM) of the certificate. By default, a new key is generated (--key-type), unless the order is finalized with the global '--csr' option.",
+					},
+					cli.BoolFlag{
+						Name:  "no-bundle",
+						Usage: "Do not create a certificate bundle by adding the issuers certificate to the new certificate.",
+					},
+					cli.BoolFlag{
+						Name:  "must-staple",
+						Usage: "Include the OCSP must staple TLS extension in the CSR. Only works if the CSR is generated by lego.",
+					},
+				},
+			},
+			{
+				Name:   "export-csr",
+				Usage:  "Generate the private key and the CSR of the domains (--domains) without contacting the CA, e.g. on an offline host. The private key stays in the certificates directory.",
+				Action: withStorageLock(ordersExportCSR),
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "output",
+						Usage: "Also write the CSR (PEM) to this file, e.g. on a removable drive.",
+					},
+					cli.BoolFlag{
+						Name:  "must-staple",
+						Usage: "Include the OCSP must staple TLS extension in the CSR.",
+					},
+				},
+			},
+			{
+				Name:   "import-cert",
+				Usage:  "Import the certificate issued on a connected host ('run --csr') for a CSR created by 'export-csr', with its private key",
+				Action: withStorageLock(ordersImportCert),
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "cert",
+						Usage: "The path of the certificate (PEM), or of the bundle (certificate and issuer).",
+					},
+					cli.StringFlag{
+						Name:  "issuer",
+						Usage: "The path of the issuer certificate (PEM). By default, the certificates following the certificate in the bundle.",
+					},
+				},
+			},
+		},
+	}
+}
+
+func ordersList(ctx *cli.Context) error {
+	client := setupOrdersClient(ctx)
+
+	orderURLs, err := client.Registration.ListOrders()
+	if err != nil {
+		log.Fatalf("Could not list the orders: %v", err)
+	}
+
+	pending := ctx.Bool("pending")
+
+	var found bool
+	for _, orderURL := range orderURLs {
+		order, err := client.Registration.GetOrder(orderURL)
+		if err != nil {
+			log.Warnf("Could not get the order %s: %v", orderURL, err)
+			continue
+		}
+
+		if pending && order.Status != acme.StatusPending && order.Status != acme.StatusReady {
+			continue
+		}
+
+		if !found {
+			fmt.Println("Found the following orders:")
+			found = true
+		}
+
+		displayOrder(os.Stdout, orderURL, order)
+		fmt.Println()
+	}
+
+	if !found {
+		fmt.Println("No orders found.")
+	}
+
+	return nil
+}
+
+func ordersShow(ctx *cli.Context) error {
+	orderURL := ctx.Args().First()
+	if orderURL == "" {
+		log.Fatal("Please specify the URL of the order: lego orders show <order URL>")
+	}
+
+	client := setupOrdersClient(ctx)
+
+	order, err := client.Registration.GetOrder(orderURL)
+	if err != nil {
+		log.Fatalf("Could not get the order %s: %v", orderURL, err)
+	}
+
+	displayOrder(os.Stdout, orderURL, order)
+
+	for _, authzURL := range order.Authorizations {
+		authz, err := client.Registration.GetAuthorization(authzURL)
+		if err != nil {
+			log.Warnf("Could not get the authorization %s: %v", authzURL, err)
+			continue
+		}
+
+		displayAuthorization(os.Stdout, authzURL, authz)
+	}
+
+	return nil
+}
+
+func ordersResume(ctx *cli.Context) error {
+	orderURL := ctx.Args().First()
+	if orderURL == "" {
+		log.Fatal("Please specify the URL of the order: lego orders resume <order URL>")
+	}
+
+	client := setupOrdersClient(ctx)
+
+	// the challenges are only needed by the orders having pending authorizations.
+	if hasChallengeOption(ctx) {
+		setupChallenges(ctx, client)
+	}
+
+	request := certificate.OrderRequest{
+		Bundle:     !ctx.Bool("no-bundle"),
+		MustStaple: ctx.Bool("must-staple"),
+	}
+
+	if ctx.GlobalIsSet("csr") {
+		csr, err := readCSRFile(ctx.GlobalString("csr"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		request.CSR = csr
+	}
+
+	if ctx.IsSet("key") {
+		keyBytes, err := ioutil.ReadFile(ctx.String("key"))
+		if err != nil {
+			log.Fatalf("Could not read the private key: %v", err)
+		}
+
+		request.PrivateKey, err = certcrypto.ParsePEMPrivateKey(keyBytes)
+		if err != nil {
+			log.Fatalf("Could not parse the private key: %v", err)
+		}
+	}
+
+	cert, err := client.Certificate.ObtainFromOrder(orderURL, request)
+	if err != nil {
+		fatalf(err, "Could not complete the order %s:\n\t%v", orderURL, err)
+	}
+
+	if cert.PrivateKey == nil && request.CSR == nil {
+		log.Warnf("[%s] The order was already finalized: the private key of the certificate is unknown (--key).", cert.Domain)
+	}
+
+	certsStorage := NewCertificatesStorage(ctx)
+	certsStorage.CreateRootFolder()
+	checkServerEnvironment(certsStorage, cert.Domain, ctx.GlobalString("server"))
+
+	certsStorage.SaveResource(cert)
+
+	log.Printf("[%s] The order %s has been completed.", cert.Domain, orderURL)
+
+	return nil
+}
+
+func setupOrdersClient(ctx *cli.Context) *lego.Client {
+	accountsStorage := NewAccountsStorage(ctx)
+	if !accountsStorage.ExistsAccountFilePath() {
+		log.Fatalf("Could not find the account %s, please register it with the 'run' command.", accountsStorage.GetUserID())
+	}
+
+	account, client := setup(ctx, accountsStorage)
+	if account.Registration == nil {
+		log.Fatalf("The account %s is not registered, please register it with the 'run' command.", accountsStorage.GetUserID())
+	}
+
+	return client
+}
+
+func displayOrder(w io.Writer, orderURL string, order acme.Order) {
+	var identifiers []string
+	for _, identifier := range order.Identifiers {
+		identifiers = append(identifiers, toUnicodeDomain(identifier.Value))
+	}
+
+	_, _ = fmt.Fprintln(w, "  Order:", orderURL)
+	_, _ = fmt.Fprintln(w, "    Status:", order.Status)
+	_, _ = fmt.Fprintln(w, "    Identifiers:", strings.Join(identifiers, ", "))
+	if order.Expires != "" {
+		_, _ = fmt.Fprintln(w, "    Expires:", order.Expires)
+	}
+	if order.Certificate != "" {
+		_, _ = fmt.Fprintln(w, "    Certificate:", order.Certificate)
+	}
+	if order.Error != nil {
+		_, _ = fmt.Fprintln(w, "    Error:", order.Error)
+	}
+}
+
+func displayAuthorization(w io.Writer, authzURL string, authz acme.Authorization) {
+	domain := toUnicodeDomain(authz.Identifier.Value)
+	if authz.Wildcard {
+		domain = "*." + domain
+	}
+
+	_, _ = fmt.Fprintln(w, "    Authorization:", authzURL)
+	_, _ = fmt.Fprintln(w, "      Identifier:", domain)
+	_, _ = fmt.Fprintln(w, "      Status:", authz.Status)
+	if !authz.Expires.IsZero() {
+		_, _ = fmt.Fprintln(w, "      Expires:", authz.Expires)
+	}
+
+	for _, chlg := range authz.Challenges {
+		if chlg.Error != nil {
+			_, _ = fmt.Fprintf(w, "      Challenge %s: %s (%s)\n", chlg.Type, chlg.Status, chlg.Error.Detail)
+		} else {
+			_, _ = fmt.Fprintf(w, "      Challenge %s: %s\n", chlg.Type, chlg.Status)
+		}
+	}
+}
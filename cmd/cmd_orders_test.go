@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_displayOrder(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	displayOrder(buf, "https://ca.example.com/order/1", acme.Order{
+		Status:      acme.StatusPending,
+		Expires:     "2020-03-08T10:00:00Z",
+		Identifiers: []acme.Identifier{{Type: "dns", Value: "example.com"}, {Type: "dns", Value: "xn--bcher-kva.example"}},
+	})
+
+	displayAuthorization(buf, "https://ca.example.com/authz/1", acme.Authorization{
+		Status:     acme.StatusInvalid,
+		Expires:    time.Date(2020, time.March, 8, 10, 0, 0, 0, time.UTC),
+		Identifier: acme.Identifier{Type: "dns", Value: "example.com"},
+		Wildcard:   true,
+		Challenges: []acme.Challenge{
+			{Type: "dns-01", Status: acme.StatusInvalid, Error: &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:dns", Detail: "NXDOMAIN"}},
+		},
+	})
+
+	expected := `  Order: https://ca.example.com/order/1
+    Status: pending
+    Identifiers: example.com, bücher.example
+    Expires: 2020-03-08T10:00:00Z
+    Authorization: https://ca.example.com/authz/1
+      Identifier: *.example.com
+      Status: invalid
+      Expires: 2020-03-08 10:00:00 +0000 UTC
+      Challenge dns-01: invalid (NXDOMAIN)
+`
+
+	assert.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli"
+)
+
+// dnsProviderInfo describes a DNS provider and its configuration keys.
+type dnsProviderInfo struct {
+	Code        string            `json:"code"`
+	Name        string            `json:"name"`
+	URL         string            `json:"url,omitempty"`
+	Since       string            `json:"since,omitempty"`
+	Credentials map[string]string `json:"credentials,omitempty"`
+	Additional  map[string]string `json:"additional,omitempty"`
+}
+
+// EnvVars returns the names of all the environment variables used to configure the provider.
+func (p dnsProviderInfo) EnvVars() []string {
+	var names []string
+	for name := range p.Credentials {
+		names = append(names, name)
+	}
+	for name := range p.Additional {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func createProviders() cli.Command {
+	return cli.Command{
+		Name:   "providers",
+		Usage:  "Display the DNS providers and their configuration keys.",
+		Action: providers,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "json",
+				Usage: "Display the providers as JSON.",
+			},
+			cli.StringFlag{
+				Name:  "code, c",
+				Usage: "Display only the provider with this DNS code.",
+			},
+		},
+		BashComplete: completeDNSCode,
+	}
+}
+
+func providers(ctx *cli.Context) error {
+	infos := sortedDNSProviders()
+
+	if code := ctx.String("code"); code != "" {
+		info, ok := findDNSProvider(code)
+		if !ok {
+			return fmt.Errorf("%q is not yet supported", code)
+		}
+		infos = []dnsProviderInfo{info}
+	}
+
+	if ctx.Bool("json") {
+		encoder := json.NewEncoder(ctx.App.Writer)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(infos)
+	}
+
+	w := tabwriter.NewWriter(ctx.App.Writer, 0, 0, 2, ' ', 0)
+	ew := &errWriter{w: w}
+
+	for _, info := range infos {
+		ew.writef("%s\t%s\n", info.Code, info.Name)
+		for _, name := range info.EnvVars() {
+			ew.writef("\t- %s\n", name)
+		}
+	}
+
+	if ew.err != nil {
+		return ew.err
+	}
+
+	return w.Flush()
+}
+
+func findDNSProvider(code string) (dnsProviderInfo, bool) {
+	for _, info := range allDNSProviders() {
+		if info.Code == code {
+			return info, true
+		}
+	}
+	return dnsProviderInfo{}, false
+}
+
+// printDNSCodes prints the DNS codes for the shell completion,
+// with the environment variables of the providers as descriptions for zsh.
+func printDNSCodes(ctx *cli.Context) {
+	zsh := os.Getenv("_CLI_ZSH_AUTOCOMPLETE_HACK") == "1"
+
+	for _, info := range sortedDNSProviders() {
+		if zsh {
+			_, _ = fmt.Fprintf(ctx.App.Writer, "%s:%s\n", info.Code, dnsCodeDescription(info))
+			continue
+		}
+
+		_, _ = fmt.Fprintln(ctx.App.Writer, info.Code)
+	}
+}
+
+// dnsCodeDescription describes a DNS code in the shell completion: the name of the provider and its environment variables.
+func dnsCodeDescription(info dnsProviderInfo) string {
+	names := info.EnvVars()
+	if len(names) == 0 {
+		return info.Name
+	}
+
+	return fmt.Sprintf("%s (%s)", info.Name, strings.Join(names, ", "))
+}
+
+// sortedDNSProviders returns the DNS providers sorted by code.
+func sortedDNSProviders() []dnsProviderInfo {
+	infos := allDNSProviders()
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Code < infos[j].Code
+	})
+	return infos
+}
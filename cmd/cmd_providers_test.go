@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_providers_json(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	err := newTestApp(out).Run([]string{"lego", "providers", "--json", "--code", "exoscale"})
+	require.NoError(t, err)
+
+	var infos []dnsProviderInfo
+	require.NoError(t, json.Unmarshal(out.Bytes(), &infos))
+
+	require.Len(t, infos, 1)
+	assert.Equal(t, "exoscale", infos[0].Code)
+	assert.Equal(t, "API key", infos[0].Credentials["EXOSCALE_API_KEY"])
+	assert.Contains(t, infos[0].Additional, "EXOSCALE_HTTP_TIMEOUT")
+}
+
+func Test_providers_unknown(t *testing.T) {
+	err := newTestApp(&bytes.Buffer{}).Run([]string{"lego", "providers", "--code", "unknown"})
+	require.EqualError(t, err, `"unknown" is not yet supported`)
+}
+
+func Test_sortedDNSProviders(t *testing.T) {
+	infos := sortedDNSProviders()
+	require.NotEmpty(t, infos)
+
+	for i := 1; i < len(infos); i++ {
+		assert.True(t, infos[i-1].Code < infos[i].Code, "%s < %s", infos[i-1].Code, infos[i].Code)
+	}
+}
+
+func Test_dnsCodeDescription(t *testing.T) {
+	info := dnsProviderInfo{
+		Code:        "example",
+		Name:        "Example",
+		Credentials: map[string]string{"EXAMPLE_TOKEN": "API token"},
+		Additional:  map[string]string{"EXAMPLE_HTTP_TIMEOUT": "API request timeout"},
+	}
+
+	assert.Equal(t, "Example (EXAMPLE_HTTP_TIMEOUT, EXAMPLE_TOKEN)", dnsCodeDescription(info))
+	assert.Equal(t, "Manual", dnsCodeDescription(dnsProviderInfo{Code: "manual", Name: "Manual"}))
+}
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
-	"strings"
 	"time"
 
 	"github.com/go-acme/lego/v3/certcrypto"
@@ -21,7 +20,7 @@ func createRenew() cli.Command {
 	return cli.Command{
 		Name:   "renew",
 		Usage:  "Renew a certificate",
-		Action: renew,
+		Action: withStorageLock(renew),
 		Before: func(ctx *cli.Context) error {
 			// we require either domains or csr, but not both
 			hasDomains := len(ctx.GlobalStringSlice("domains")) > 0
@@ -52,15 +51,58 @@ func createRenew() cli.Command {
 				Name:  "must-staple",
 				Usage: "Include the OCSP must staple TLS extension in the CSR and generated certificate. Only works if the CSR is generated by lego.",
 			},
+			cli.StringFlag{
+				Name:  "common-name",
+				Usage: "The domain used as common name of the CSR (the first domain by default). Only works if the CSR is generated by lego.",
+			},
+			cli.BoolFlag{
+				Name:  "no-common-name",
+				Usage: "Generate a CSR without common name, only with subject alternative names. Only works if the CSR is generated by lego.",
+			},
+			cli.StringFlag{
+				Name:  "san-order",
+				Usage: "The order of the subject alternative names in the CSR: default (the common name first, then the order of the CA), request (the order of --domains), or sorted.",
+			},
+			cli.BoolFlag{
+				Name:  "allow-partial",
+				Usage: "Drop the domains which fail the validation and obtain a certificate for the remaining domains. The dropped domains are recorded in the certificate metadata.",
+			},
+			cli.DurationFlag{
+				Name:  "lifetime",
+				Usage: "Request a certificate valid for this duration (e.g. 72h), to obtain short-lived certificates. Only honored by the CAs supporting it.",
+			},
 			cli.StringFlag{
 				Name:  "renew-hook",
-				Usage: "Define a hook. The hook is executed only when the certificates are effectively renewed.",
+				Usage: "Define a hook. The hook is executed only when the certificates are effectively renewed. The hook can be a Go template referencing the metadata of the certificate (e.g. {{.Domain}}, {{.NotAfter}}, {{.CertPath}}).",
+			},
+			cli.DurationFlag{
+				Name:  "backoff",
+				Usage: "After a failed renewal, don't attempt the renewal again before this delay, doubled after each consecutive failure. Disabled if 0.",
+			},
+			cli.DurationFlag{
+				Name:  "backoff.max",
+				Value: 24 * time.Hour,
+				Usage: "The maximum delay between two attempts after failed renewals.",
+			},
+			cli.IntFlag{
+				Name:  "circuit-breaker",
+				Usage: "Pause the renewal after this number of consecutive failures, until --reset-failures is used. Disabled if 0.",
+			},
+			cli.StringFlag{
+				Name:  "circuit-breaker.hook",
+				Usage: "Define a hook executed when the renewal is paused by the circuit breaker (LEGO_RENEW_DOMAIN, LEGO_RENEW_FAILURES, and LEGO_RENEW_ERROR are set). The hook can be a Go template, like --renew-hook.",
+			},
+			cli.BoolFlag{
+				Name:  "reset-failures",
+				Usage: "Reset the failures of the previous renewals, and resume a renewal paused by the circuit breaker.",
 			},
 		},
 	}
 }
 
 func renew(ctx *cli.Context) error {
+	certsStorage := newRenewalStorage(ctx)
+
 	account, client := setup(ctx, NewAccountsStorage(ctx))
 	setupChallenges(ctx, client)
 
@@ -68,8 +110,6 @@ func renew(ctx *cli.Context) error {
 		log.Fatalf("Account %s is not registered. Use 'run' to register a new account.\n", account.Email)
 	}
 
-	certsStorage := NewCertificatesStorage(ctx)
-
 	bundle := !ctx.Bool("no-bundle")
 
 	// CSR
@@ -82,7 +122,7 @@ func renew(ctx *cli.Context) error {
 }
 
 func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage, bundle bool) error {
-	domains := ctx.GlobalStringSlice("domains")
+	domains := getDomains(ctx)
 	domain := domains[0]
 
 	// load the cert resource from files.
@@ -95,18 +135,39 @@ func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *Certif
 
 	cert := certificates[0]
 
-	if !needRenewal(cert, domain, ctx.Int("days")) {
+	// a key weaker than the policy (--policy.min-key-types) is replaced, even if the certificate is not due for renewal.
+	keyType := certcrypto.KeyTypeOf(cert.PublicKey)
+	upgradedKeyType, weakKey := getPolicy(ctx).UpgradeKeyType(keyType)
+
+	if weakKey {
+		log.Warnf("[%s] The key of the certificate (%s) is weaker than the policy (%s): renewal with a new %s key.",
+			domain, keyType, upgradedKeyType, upgradedKeyType)
+	} else if !needRenewal(cert, domain, ctx.Int("days")) {
+		return nil
+	}
+
+	backoff := newRenewalBackoff(ctx, certsStorage)
+	if ctx.Bool("reset-failures") {
+		backoff.reset(domain)
+	}
+
+	if !backoff.allow(domain) {
 		return nil
 	}
 
 	// This is just meant to be informal for the user.
-	timeLeft := cert.NotAfter.Sub(time.Now().UTC())
+	timeLeft := cert.NotAfter.Sub(clk.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", domain, int(timeLeft.Hours()))
 
 	certDomains := certcrypto.ExtractDomains(cert)
 
 	var privateKey crypto.PrivateKey
-	if ctx.Bool("reuse-key") {
+	if weakKey {
+		privateKey, err = certcrypto.GeneratePrivateKey(upgradedKeyType)
+		if err != nil {
+			log.Fatalf("Could not generate the private key for domain %s\n\t%v", domain, err)
+		}
+	} else if ctx.Bool("reuse-key") {
 		keyBytes, errR := certsStorage.ReadFile(domain, ".key")
 		if errR != nil {
 			log.Fatalf("Error while loading the private key for domain %s\n\t%v", domain, errR)
@@ -118,20 +179,45 @@ func renewForDomains(ctx *cli.Context, client *lego.Client, certsStorage *Certif
 		}
 	}
 
+	// the main domain stays first, even if the common name of the certificate is another domain.
 	request := certificate.ObtainRequest{
-		Domains:    merge(certDomains, domains),
+		Domains:    merge([]string{domain}, merge(certDomains, domains)),
 		Bundle:     bundle,
 		PrivateKey: privateKey,
 		MustStaple: ctx.Bool("must-staple"),
+
+		AllowPartial: ctx.Bool("allow-partial"),
 	}
-	certRes, err := client.Certificate.Obtain(request)
+	setCSROptions(ctx, &request)
+	setLifetime(ctx, &request)
+
+	j := journalFromEnv()
+	j.record(journalEntry{Type: journalOrderStarted, Domain: domain})
+
+	certRes, err := obtainDuringMaintenance(ctx, func() (*certificate.Resource, error) {
+		return client.Certificate.Obtain(request)
+	})
 	if err != nil {
-		log.Fatal(err)
+		j.record(journalEntry{Type: journalOrderFailed, Domain: domain, Error: err.Error()})
+		backoff.failed(domain, err)
+		fatalf(err, "%v", err)
 	}
 
+	checkServerEnvironment(certsStorage, certRes.Domain, ctx.GlobalString("server"))
 	certsStorage.SaveResource(certRes)
+	backoff.succeeded(domain)
+	j.record(journalEntry{Type: journalCertificateSaved, Domain: domain, Serial: certRes.SerialNumber})
+
+	if weakKey {
+		log.Infof("[%s] The key of the certificate has been upgraded from %s to %s.", domain, keyType, upgradedKeyType)
+	}
+
+	handleTLSA(ctx, certsStorage, certRes)
+	handleSnippets(ctx, certsStorage, certRes)
+	reportInventory(ctx, inventoryEventRenew, certRes)
+	saveRenewalMetadata(ctx, certsStorage, certRes.Domain, request.Domains)
 
-	return renewHook(ctx)
+	return renewHook(ctx, j, certRes)
 }
 
 func renewForCSR(ctx *cli.Context, client *lego.Client, certsStorage *CertificatesStorage, bundle bool) error {
@@ -152,22 +238,52 @@ func renewForCSR(ctx *cli.Context, client *lego.Client, certsStorage *Certificat
 
 	cert := certificates[0]
 
+	// the key of the CSR can't be replaced by lego.
+	keyType := certcrypto.KeyTypeOf(csr.PublicKey)
+	if upgradedKeyType, weakKey := getPolicy(ctx).UpgradeKeyType(keyType); weakKey {
+		log.Warnf("[%s] The key of the CSR (%s) is weaker than the policy (%s): generate a new CSR with a stronger key.",
+			domain, keyType, upgradedKeyType)
+	}
+
 	if !needRenewal(cert, domain, ctx.Int("days")) {
 		return nil
 	}
 
+	backoff := newRenewalBackoff(ctx, certsStorage)
+	if ctx.Bool("reset-failures") {
+		backoff.reset(domain)
+	}
+
+	if !backoff.allow(domain) {
+		return nil
+	}
+
 	// This is just meant to be informal for the user.
-	timeLeft := cert.NotAfter.Sub(time.Now().UTC())
+	timeLeft := cert.NotAfter.Sub(clk.Now().UTC())
 	log.Infof("[%s] acme: Trying renewal with %d hours remaining", domain, int(timeLeft.Hours()))
 
-	certRes, err := client.Certificate.ObtainForCSR(*csr, bundle)
+	j := journalFromEnv()
+	j.record(journalEntry{Type: journalOrderStarted, Domain: domain})
+
+	certRes, err := obtainDuringMaintenance(ctx, func() (*certificate.Resource, error) {
+		return client.Certificate.ObtainForCSR(*csr, bundle)
+	})
 	if err != nil {
-		log.Fatal(err)
+		j.record(journalEntry{Type: journalOrderFailed, Domain: domain, Error: err.Error()})
+		backoff.failed(domain, err)
+		fatalf(err, "%v", err)
 	}
 
+	checkServerEnvironment(certsStorage, certRes.Domain, ctx.GlobalString("server"))
 	certsStorage.SaveResource(certRes)
+	backoff.succeeded(domain)
+	j.record(journalEntry{Type: journalCertificateSaved, Domain: domain, Serial: certRes.SerialNumber})
+
+	handleTLSA(ctx, certsStorage, certRes)
+	handleSnippets(ctx, certsStorage, certRes)
+	reportInventory(ctx, inventoryEventRenew, certRes)
 
-	return renewHook(ctx)
+	return renewHook(ctx, j, certRes)
 }
 
 func needRenewal(x509Cert *x509.Certificate, domain string, days int) bool {
@@ -175,8 +291,22 @@ func needRenewal(x509Cert *x509.Certificate, domain string, days int) bool {
 		log.Fatalf("[%s] Certificate bundle starts with a CA certificate", domain)
 	}
 
+	if days >= 0 && isShortLived(x509Cert, days) {
+		// the number of days is greater than the validity of the certificate:
+		// renews when a third of the validity remains.
+		lifetime := x509Cert.NotAfter.Sub(x509Cert.NotBefore)
+		remaining := x509Cert.NotAfter.Sub(clk.Now())
+		if remaining > lifetime/3 {
+			log.Printf("[%s] The short-lived certificate expires in %s, the renewal is performed when %s remain: no renewal.",
+				domain, remaining.Round(time.Minute), (lifetime / 3).Round(time.Minute))
+			return false
+		}
+
+		return true
+	}
+
 	if days >= 0 {
-		notAfter := int(time.Until(x509Cert.NotAfter).Hours() / 24.0)
+		notAfter := int(x509Cert.NotAfter.Sub(clk.Now()).Hours() / 24.0)
 		if notAfter > days {
 			log.Printf("[%s] The certificate expires in %d days, the number of days defined to perform the renewal is %d: no renewal.",
 				domain, notAfter, days)
@@ -187,6 +317,15 @@ func needRenewal(x509Cert *x509.Certificate, domain string, days int) bool {
 	return true
 }
 
+// isShortLived returns true if the validity of the certificate is not greater than the number of days before the renewal.
+func isShortLived(x509Cert *x509.Certificate, days int) bool {
+	if x509Cert.NotBefore.IsZero() {
+		return false
+	}
+
+	return x509Cert.NotAfter.Sub(x509Cert.NotBefore) <= time.Duration(days)*24*time.Hour
+}
+
 func merge(prevDomains []string, nextDomains []string) []string {
 	for _, next := range nextDomains {
 		var found bool
@@ -203,16 +342,31 @@ func merge(prevDomains []string, nextDomains []string) []string {
 	return prevDomains
 }
 
-func renewHook(ctx *cli.Context) error {
+// renewHook runs the renew hook of a renewed certificate.
+// When the renewal is run by the daemon, the hook is recorded in its journal, so it is run once even after a crash.
+func renewHook(ctx *cli.Context, j *journal, certRes *certificate.Resource) error {
 	hook := ctx.String("renew-hook")
 	if hook == "" {
+		j.record(journalEntry{Type: journalHookDone, Domain: certRes.Domain, Serial: certRes.SerialNumber})
 		return nil
 	}
 
+	data := newHookData(NewCertificatesStorage(ctx), certRes)
+	data.Event = inventoryEventRenew
+
+	return runJournaledHook(j, hook, certRes.Domain, certRes.SerialNumber, data)
+}
+
+// runRenewHook runs a renew hook, the hook can be a template of the command (see hookData).
+func runRenewHook(hook string, data *hookData) error {
+	parts, err := renderHook("renew-hook", hook, data)
+	if err != nil {
+		return err
+	}
+
 	ctxCmd, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	parts := strings.Fields(hook)
 	output, err := exec.CommandContext(ctxCmd, parts[0], parts[1:]...).CombinedOutput()
 	if len(output) > 0 {
 		fmt.Println(string(output))
@@ -5,7 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/platform/clock"
 	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
 )
 
 func Test_merge(t *testing.T) {
@@ -97,6 +100,24 @@ func Test_needRenewal(t *testing.T) {
 			days:     0,
 			expected: false,
 		},
+		{
+			desc: "30 days, short-lived 6 days, 5 days remaining",
+			x509Cert: &x509.Certificate{
+				NotBefore: time.Now().Add(-24 * time.Hour),
+				NotAfter:  time.Now().Add(5 * 24 * time.Hour),
+			},
+			days:     30,
+			expected: false,
+		},
+		{
+			desc: "30 days, short-lived 6 days, 1 day remaining",
+			x509Cert: &x509.Certificate{
+				NotBefore: time.Now().Add(-5 * 24 * time.Hour),
+				NotAfter:  time.Now().Add(24 * time.Hour),
+			},
+			days:     30,
+			expected: true,
+		},
 		{
 			desc: "-1 days, NotAfter 30 days: always renew",
 			x509Cert: &x509.Certificate{
@@ -116,3 +137,25 @@ func Test_needRenewal(t *testing.T) {
 		})
 	}
 }
+
+func Test_setLifetime(t *testing.T) {
+	now := time.Date(2020, time.March, 8, 10, 0, 0, 0, time.UTC)
+
+	clk = clock.NewFake(now)
+	defer func() { clk = clock.Real }()
+
+	runWithFlags(t, []string{"renew", "--lifetime", "72h"}, func(ctx *cli.Context) {
+		request := certificate.ObtainRequest{}
+		setLifetime(ctx, &request)
+
+		assert.Equal(t, now.Add(72*time.Hour), request.NotAfter)
+		assert.True(t, request.NotBefore.IsZero())
+	})
+
+	runWithFlags(t, []string{"renew"}, func(ctx *cli.Context) {
+		request := certificate.ObtainRequest{}
+		setLifetime(ctx, &request)
+
+		assert.True(t, request.NotAfter.IsZero())
+	})
+}
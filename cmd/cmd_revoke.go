@@ -9,7 +9,7 @@ func createRevoke() cli.Command {
 	return cli.Command{
 		Name:   "revoke",
 		Usage:  "Revoke a certificate",
-		Action: revoke,
+		Action: withStorageLock(revoke),
 		Flags: []cli.Flag{
 			cli.BoolFlag{
 				Name:  "keep, k",
@@ -29,7 +29,7 @@ func revoke(ctx *cli.Context) error {
 	certsStorage := NewCertificatesStorage(ctx)
 	certsStorage.CreateRootFolder()
 
-	for _, domain := range ctx.GlobalStringSlice("domains") {
+	for _, domain := range normalizedDomains(ctx) {
 		log.Printf("Trying to revoke certificate for domain %s", domain)
 
 		certBytes, err := certsStorage.ReadFile(domain, ".crt")
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/go-acme/lego/v3/certificate"
 	"github.com/go-acme/lego/v3/lego"
 	"github.com/go-acme/lego/v3/log"
@@ -29,7 +30,7 @@ func createRun() cli.Command {
 			}
 			return nil
 		},
-		Action: run,
+		Action: withStorageLock(run),
 		Flags: []cli.Flag{
 			cli.BoolFlag{
 				Name:  "no-bundle",
@@ -39,11 +40,43 @@ func createRun() cli.Command {
 				Name:  "must-staple",
 				Usage: "Include the OCSP must staple TLS extension in the CSR and generated certificate. Only works if the CSR is generated by lego.",
 			},
+			cli.StringFlag{
+				Name:  "common-name",
+				Usage: "The domain used as common name of the CSR (the first domain by default). Only works if the CSR is generated by lego.",
+			},
+			cli.BoolFlag{
+				Name:  "no-common-name",
+				Usage: "Generate a CSR without common name, only with subject alternative names. Only works if the CSR is generated by lego.",
+			},
+			cli.StringFlag{
+				Name:  "san-order",
+				Usage: "The order of the subject alternative names in the CSR: default (the common name first, then the order of the CA), request (the order of --domains), or sorted.",
+			},
+			cli.BoolFlag{
+				Name:  "allow-partial",
+				Usage: "Drop the domains which fail the validation and obtain a certificate for the remaining domains. The dropped domains are recorded in the certificate metadata.",
+			},
+			cli.DurationFlag{
+				Name:  "lifetime",
+				Usage: "Request a certificate valid for this duration (e.g. 72h), to obtain short-lived certificates. Only honored by the CAs supporting it.",
+			},
+			cli.StringFlag{
+				Name:  "dedup",
+				Usage: "Share the certificate of another name having the same domains and key type, instead of obtaining a new one: hardlink or symlink the files of the name to the shared certificate. Disabled by default.",
+			},
+			cli.BoolFlag{
+				Name:  "ephemeral-account",
+				Usage: "Register a throwaway account kept in memory, and deactivate it once the certificate is obtained. The account is not stored, --email is optional.",
+			},
 		},
 	}
 }
 
 func run(ctx *cli.Context) error {
+	if ctx.Bool("ephemeral-account") {
+		return runEphemeral(ctx)
+	}
+
 	accountsStorage := NewAccountsStorage(ctx)
 
 	account, client := setup(ctx, accountsStorage)
@@ -52,13 +85,14 @@ func run(ctx *cli.Context) error {
 	if account.Registration == nil {
 		reg, err := register(ctx, client)
 		if err != nil {
-			log.Fatalf("Could not complete registration\n\t%v", err)
+			fatalf(err, "Could not complete registration\n\t%v", err)
 		}
 
 		account.Registration = reg
+		account.TermsOfService = client.GetToSURL()
 
 		if err = accountsStorage.Save(account); err != nil {
-			log.Fatal(err)
+			storageFatalf("%v", err)
 		}
 
 		fmt.Println("!!!! HEADS UP !!!!")
@@ -74,14 +108,81 @@ func run(ctx *cli.Context) error {
 	certsStorage := NewCertificatesStorage(ctx)
 	certsStorage.CreateRootFolder()
 
+	dedup := newCertificateDedup(ctx, certsStorage)
+
+	cert, err := shareCertificate(ctx, dedup)
+	if err != nil {
+		log.Warnf("Could not share an existing certificate: %v", err)
+	}
+
+	if cert == nil {
+		cert, err = obtainCertificate(ctx, client)
+		if err != nil {
+			// Make sure to return a non-zero exit code if ObtainSANCertificate returned at least one error.
+			// Due to us not returning partial certificate we can just exit here instead of at the end.
+			fatalf(err, "Could not obtain certificates:\n\t%v", err)
+		}
+
+		checkServerEnvironment(certsStorage, cert.Domain, ctx.GlobalString("server"))
+		certsStorage.SaveResource(cert)
+
+		if err = dedup.record(cert); err != nil {
+			log.Warnf("[%s] Could not record the certificate for the deduplication: %v", cert.Domain, err)
+		}
+	}
+	handleTLSA(ctx, certsStorage, cert)
+	handleSnippets(ctx, certsStorage, cert)
+	reportInventory(ctx, inventoryEventObtain, cert)
+
+	if !ctx.GlobalIsSet("csr") {
+		saveRenewalMetadata(ctx, certsStorage, cert.Domain, getDomains(ctx))
+	}
+
+	return nil
+}
+
+// runEphemeral obtains a certificate with a throwaway account:
+// the account key only lives in memory, and the account is deactivated at the end.
+func runEphemeral(ctx *cli.Context) error {
+	keyType := getKeyType(ctx)
+
+	privateKey, err := certcrypto.GeneratePrivateKey(getAccountKeyType(ctx))
+	if err != nil {
+		log.Fatalf("Could not generate the account key: %v", err)
+	}
+
+	account := &Account{Email: ctx.GlobalString("email"), key: privateKey}
+
+	client := newClient(ctx, account, keyType)
+	setupChallenges(ctx, client)
+
+	account.Registration, err = register(ctx, client)
+	if err != nil {
+		fatalf(err, "Could not complete registration\n\t%v", err)
+	}
+
+	defer func() {
+		err := client.Registration.DeleteRegistration()
+		if err != nil {
+			log.Warnf("Could not deactivate the ephemeral account %s: %v", account.Registration.URI, err)
+			return
+		}
+		log.Infof("The ephemeral account %s has been deactivated", account.Registration.URI)
+	}()
+
+	certsStorage := NewCertificatesStorage(ctx)
+	certsStorage.CreateRootFolder()
+
 	cert, err := obtainCertificate(ctx, client)
 	if err != nil {
-		// Make sure to return a non-zero exit code if ObtainSANCertificate returned at least one error.
-		// Due to us not returning partial certificate we can just exit here instead of at the end.
-		log.Fatalf("Could not obtain certificates:\n\t%v", err)
+		return fmt.Errorf("could not obtain certificates:\n\t%w", err)
 	}
 
+	checkServerEnvironment(certsStorage, cert.Domain, ctx.GlobalString("server"))
 	certsStorage.SaveResource(cert)
+	handleTLSA(ctx, certsStorage, cert)
+	handleSnippets(ctx, certsStorage, cert)
+	reportInventory(ctx, inventoryEventObtain, cert)
 
 	return nil
 }
@@ -141,15 +242,22 @@ func register(ctx *cli.Context, client *lego.Client) (*registration.Resource, er
 func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Resource, error) {
 	bundle := !ctx.Bool("no-bundle")
 
-	domains := ctx.GlobalStringSlice("domains")
+	domains := getDomains(ctx)
 	if len(domains) > 0 {
 		// obtain a certificate, generating a new private key
 		request := certificate.ObtainRequest{
 			Domains:    domains,
 			Bundle:     bundle,
 			MustStaple: ctx.Bool("must-staple"),
+
+			AllowPartial: ctx.Bool("allow-partial"),
 		}
-		return client.Certificate.Obtain(request)
+		setCSROptions(ctx, &request)
+		setLifetime(ctx, &request)
+
+		return obtainDuringMaintenance(ctx, func() (*certificate.Resource, error) {
+			return client.Certificate.Obtain(request)
+		})
 	}
 
 	// read the CSR
@@ -159,5 +267,26 @@ func obtainCertificate(ctx *cli.Context, client *lego.Client) (*certificate.Reso
 	}
 
 	// obtain a certificate for this CSR
-	return client.Certificate.ObtainForCSR(*csr, bundle)
+	return obtainDuringMaintenance(ctx, func() (*certificate.Resource, error) {
+		return client.Certificate.ObtainForCSR(*csr, bundle)
+	})
+}
+
+// setCSROptions sets the common name and SAN order options (--common-name, --no-common-name, --san-order) of a request.
+func setCSROptions(ctx *cli.Context, request *certificate.ObtainRequest) {
+	sanOrder, err := certificate.ParseSANOrder(ctx.String("san-order"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	request.CommonName = ctx.String("common-name")
+	request.NoCommonName = ctx.Bool("no-common-name")
+	request.SANOrder = sanOrder
+}
+
+// setLifetime requests the validity period of the certificate (--lifetime) in a request, from now.
+func setLifetime(ctx *cli.Context, request *certificate.ObtainRequest) {
+	if lifetime := ctx.Duration("lifetime"); lifetime > 0 {
+		request.NotAfter = clk.Now().Add(lifetime)
+	}
 }
@@ -0,0 +1,620 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	stdlog "log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/wait"
+	"github.com/go-acme/lego/v3/registration"
+	"github.com/urfave/cli"
+)
+
+// selftestDomain the domain of the throwaway certificate, when the solver doesn't need a real domain.
+const selftestDomain = "selftest.lego.test"
+
+// The solvers of the self-test.
+const (
+	selftestSolverInternal = "dns-01 (challtestsrv)"
+	selftestSolverHTTP     = "http-01 (standalone server)"
+	selftestSolverDNS      = "dns-01 (%s)"
+)
+
+func createSelftest() cli.Command {
+	return cli.Command{
+		Name:   "selftest",
+		Usage:  "Check the deployment end-to-end: issue a throwaway certificate from a local Pebble CA, with the configured solver when possible",
+		Action: selftest,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "pebble",
+				Value: "pebble",
+				Usage: "The Pebble binary.",
+			},
+			cli.StringFlag{
+				Name:  "challtestsrv",
+				Value: "pebble-challtestsrv",
+				Usage: "The challtestsrv binary (the DNS server of Pebble).",
+			},
+			cli.StringFlag{
+				Name:  "domain",
+				Usage: "The domain of the throwaway certificate. By default, the first domain (--domains) with a DNS provider, " + selftestDomain + " otherwise.",
+			},
+			cli.StringFlag{
+				Name:  "output",
+				Usage: "The directory of the diagnostic bundle (report, logs of lego, Pebble and challtestsrv). By default, <path>/selftest/<timestamp>.",
+			},
+			cli.DurationFlag{
+				Name:  "timeout",
+				Value: 2 * time.Minute,
+				Usage: "The maximum duration of the issuance.",
+			},
+		},
+	}
+}
+
+// selftestReport the result of the self-test, written to the diagnostic bundle (report.json).
+type selftestReport struct {
+	Started time.Time      `json:"started"`
+	Solver  string         `json:"solver"`
+	Domain  string         `json:"domain"`
+	Passed  bool           `json:"passed"`
+	Steps   []selftestStep `json:"steps"`
+	Notes   []string       `json:"notes,omitempty"`
+	Output  string         `json:"-"`
+	failed  bool
+}
+
+// selftestStep the result of a step of the self-test.
+type selftestStep struct {
+	Name     string `json:"name"`
+	OK       bool   `json:"ok"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// run runs a step, unless a previous step failed.
+func (r *selftestReport) run(name string, step func() error) {
+	if r.failed {
+		return
+	}
+
+	log.Infof("selftest: %s", name)
+
+	start := time.Now()
+	err := step()
+
+	result := selftestStep{Name: name, OK: err == nil, Duration: time.Since(start).Round(time.Millisecond).String()}
+	if err != nil {
+		result.Error = err.Error()
+		r.failed = true
+		log.Warnf("selftest: %s: %v", name, err)
+	}
+
+	r.Steps = append(r.Steps, result)
+}
+
+func (r *selftestReport) note(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	log.Infof("selftest: %s", msg)
+	r.Notes = append(r.Notes, msg)
+}
+
+func selftest(ctx *cli.Context) error {
+	output := ctx.String("output")
+	if output == "" {
+		output = filepath.Join(ctx.GlobalString("path"), "selftest", time.Now().UTC().Format("20060102T150405Z"))
+	}
+
+	err := os.MkdirAll(output, 0700)
+	if err != nil {
+		log.Fatalf("selftest: %v", err)
+	}
+
+	// the logs of lego are also written to the bundle.
+	logFile, err := os.Create(filepath.Join(output, "lego.log"))
+	if err != nil {
+		log.Fatalf("selftest: %v", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	previous := log.Logger
+	log.Logger = stdlog.New(io.MultiWriter(os.Stdout, logFile), "", stdlog.LstdFlags)
+	defer func() { log.Logger = previous }()
+
+	report := &selftestReport{Started: time.Now().UTC(), Output: output}
+
+	env := &selftestEnv{dir: output}
+	defer env.stop()
+
+	runSelftest(ctx, env, report)
+
+	report.Passed = !report.failed
+
+	raw, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		log.Fatalf("selftest: %v", err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(output, "report.json"), raw, filePerm)
+	if err != nil {
+		log.Fatalf("selftest: %v", err)
+	}
+
+	printSelftestReport(os.Stdout, report)
+
+	if !report.Passed {
+		return errors.New("selftest: failed")
+	}
+
+	return nil
+}
+
+func runSelftest(ctx *cli.Context, env *selftestEnv, report *selftestReport) {
+	solver := newSelftestSolver(ctx, report)
+	report.Solver = solver.name
+	report.Domain = solver.domain
+
+	report.run("start challtestsrv", func() error {
+		return env.startChallSrv(ctx.String("challtestsrv"))
+	})
+
+	report.run("start pebble", func() error {
+		return env.startPebble(ctx.String("pebble"), solver)
+	})
+
+	var client *lego.Client
+	account := &Account{Email: "selftest@" + selftestDomain}
+
+	report.run("register an account", func() error {
+		var err error
+		client, err = env.newClient(account, getKeyType(ctx))
+		if err != nil {
+			return err
+		}
+
+		account.Registration, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+		return err
+	})
+
+	report.run("configure the solver", func() error {
+		return solver.setup(client, env)
+	})
+
+	var certRes *certificate.Resource
+
+	report.run("obtain a certificate", func() error {
+		return runWithTimeout(ctx.Duration("timeout"), func() error {
+			var err error
+			certRes, err = client.Certificate.Obtain(certificate.ObtainRequest{Domains: []string{solver.domain}, Bundle: true})
+			return err
+		})
+	})
+
+	report.run("check the certificate", func() error {
+		return checkSelftestCertificate(certRes, solver.domain, filepath.Join(env.dir, "certificate.pem"))
+	})
+
+	report.run("revoke the certificate", func() error {
+		return client.Certificate.Revoke(certRes.Certificate)
+	})
+}
+
+// runWithTimeout runs fn, and returns an error if it doesn't return before the timeout.
+func runWithTimeout(timeout time.Duration, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+func checkSelftestCertificate(certRes *certificate.Resource, domain, file string) error {
+	certs, err := certcrypto.ParsePEMBundle(certRes.Certificate)
+	if err != nil {
+		return err
+	}
+
+	if len(certs) < 2 {
+		return errors.New("the certificate has no issuer")
+	}
+
+	if err := certs[0].VerifyHostname(domain); err != nil {
+		return err
+	}
+
+	if err := certs[0].CheckSignatureFrom(certs[1]); err != nil {
+		return fmt.Errorf("the certificate is not signed by its issuer: %w", err)
+	}
+
+	if err := matchPrivateKey(certRes.PrivateKey, certs[0]); err != nil {
+		return err
+	}
+
+	// the throwaway certificate is kept in the bundle, without its key.
+	return ioutil.WriteFile(file, certRes.Certificate, filePerm)
+}
+
+func printSelftestReport(w io.Writer, report *selftestReport) {
+	fmt.Fprintf(w, "Solver: %s\nDomain: %s\n\n", report.Solver, report.Domain)
+
+	for _, step := range report.Steps {
+		status := "PASS"
+		if !step.OK {
+			status = "FAIL"
+		}
+
+		fmt.Fprintf(w, "%s  %-25s %s\n", status, step.Name, step.Duration)
+		if step.Error != "" {
+			fmt.Fprintf(w, "      %s\n", step.Error)
+		}
+	}
+
+	for _, note := range report.Notes {
+		fmt.Fprintf(w, "\nNote: %s", note)
+	}
+
+	result := "PASSED"
+	if !report.Passed {
+		result = "FAILED"
+	}
+
+	fmt.Fprintf(w, "\n\nSelftest %s, diagnostic bundle: %s\n", result, report.Output)
+}
+
+// selftestSolver the solver used by the self-test.
+type selftestSolver struct {
+	name   string
+	domain string
+
+	// httpPort the port of the HTTP-01 challenge (standalone server), 0 for the DNS-01 challenge.
+	httpPort int
+	// resolver the DNS server used by Pebble for the validation, challtestsrv if empty.
+	resolver string
+
+	setup func(client *lego.Client, env *selftestEnv) error
+}
+
+// newSelftestSolver chooses the configured solver when it can be used with a local CA:
+// a DNS provider (the record of a real domain is checked by Pebble with a public resolver), or the standalone HTTP server.
+// The other solvers (webroot, memcached, challenge agent, ...) are replaced with the DNS server of challtestsrv.
+func newSelftestSolver(ctx *cli.Context, report *selftestReport) *selftestSolver {
+	domain := ctx.String("domain")
+
+	if ctx.GlobalIsSet("dns") && !ctx.GlobalIsSet("challenge-agent") {
+		if domain == "" {
+			if domains := normalizedDomains(ctx); len(domains) > 0 {
+				domain = domains[0]
+			}
+		}
+
+		if domain != "" {
+			resolver := "8.8.8.8:53"
+			if servers := dns01.ParseNameservers(ctx.GlobalStringSlice("dns.resolvers")); len(servers) > 0 {
+				resolver = servers[0]
+			}
+
+			return &selftestSolver{
+				name:     fmt.Sprintf(selftestSolverDNS, ctx.GlobalString("dns")),
+				domain:   domain,
+				resolver: resolver,
+				setup: func(client *lego.Client, _ *selftestEnv) error {
+					provider, err := getDNSProvider(ctx)
+					if err != nil {
+						return err
+					}
+
+					return client.Challenge.SetDNS01Provider(provider)
+				},
+			}
+		}
+
+		report.note("the DNS provider needs a real domain (--domain or --domains), the DNS server of challtestsrv is used instead")
+	}
+
+	if domain == "" {
+		domain = selftestDomain
+	}
+
+	if ctx.GlobalBool("http") && !ctx.GlobalIsSet("http.webroot") && !ctx.GlobalIsSet("http.memcached-host") && !ctx.GlobalIsSet("challenge-agent") {
+		host, rawPort, err := net.SplitHostPort(ctx.GlobalString("http.port"))
+		if err == nil {
+			port, errP := strconv.Atoi(rawPort)
+			if errP == nil {
+				return &selftestSolver{
+					name:     selftestSolverHTTP,
+					domain:   domain,
+					httpPort: port,
+					setup: func(client *lego.Client, _ *selftestEnv) error {
+						return client.Challenge.SetHTTP01Provider(newHTTPProviderServer(ctx, host, rawPort))
+					},
+				}
+			}
+		}
+	}
+
+	if ctx.GlobalBool("http") || ctx.GlobalBool("tls") || ctx.GlobalIsSet("dns") || ctx.GlobalIsSet("onion.key") {
+		report.note("the configured solver can't be used with a local CA, the DNS server of challtestsrv is used instead")
+	}
+
+	return &selftestSolver{
+		name:   selftestSolverInternal,
+		domain: domain,
+		setup: func(client *lego.Client, env *selftestEnv) error {
+			provider := &challtestsrvProvider{url: env.challSrvURL, client: &http.Client{Timeout: 10 * time.Second}}
+
+			return client.Challenge.SetDNS01Provider(provider,
+				dns01.AddRecursiveNameservers([]string{env.challSrvDNS}))
+		},
+	}
+}
+
+// challtestsrvProvider a DNS provider creating the records in challtestsrv, through its management API.
+type challtestsrvProvider struct {
+	url    string
+	client *http.Client
+}
+
+func (p *challtestsrvProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+
+	return p.post("/set-txt", map[string]string{"host": fqdn, "value": value})
+}
+
+func (p *challtestsrvProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+
+	return p.post("/clear-txt", map[string]string{"host": fqdn})
+}
+
+func (p *challtestsrvProvider) post(path string, body interface{}) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Post(p.url+path, "application/json", bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("challtestsrv: %s: %d: %s", path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// selftestEnv the local CA (Pebble) and its DNS server (challtestsrv), with their logs in the diagnostic bundle.
+type selftestEnv struct {
+	dir string
+
+	challSrvURL string
+	challSrvDNS string
+	pebbleURL   string
+
+	roots *x509.CertPool
+	cmds  []*exec.Cmd
+	files []*os.File
+}
+
+func (e *selftestEnv) startChallSrv(binary string) error {
+	managementPort, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	dnsPort, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	e.challSrvURL = fmt.Sprintf("http://127.0.0.1:%d", managementPort)
+	e.challSrvDNS = fmt.Sprintf("127.0.0.1:%d", dnsPort)
+
+	// all the domains resolve to the local host (for the HTTP-01 challenge), the other challenge servers are disabled.
+	err = e.start("challtestsrv", binary, nil,
+		"-management", fmt.Sprintf("127.0.0.1:%d", managementPort),
+		"-dns01", e.challSrvDNS,
+		"-http01", "", "-https01", "", "-tlsalpn01", "",
+		"-defaultIPv4", "127.0.0.1", "-defaultIPv6", "")
+	if err != nil {
+		return err
+	}
+
+	return wait.For("challtestsrv", 10*time.Second, 200*time.Millisecond, func() (bool, error) {
+		conn, errD := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", managementPort), time.Second)
+		if errD != nil {
+			return false, nil
+		}
+		_ = conn.Close()
+		return true, nil
+	})
+}
+
+func (e *selftestEnv) startPebble(binary string, solver *selftestSolver) error {
+	// the TLS certificate of the API of Pebble.
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.RSA2048)
+	if err != nil {
+		return err
+	}
+
+	certPEM, err := certcrypto.GeneratePemCert(privateKey.(*rsa.PrivateKey), "localhost", nil)
+	if err != nil {
+		return err
+	}
+
+	e.roots = x509.NewCertPool()
+	e.roots.AppendCertsFromPEM(certPEM)
+
+	certFile := filepath.Join(e.dir, "pebble.crt")
+	keyFile := filepath.Join(e.dir, "pebble.key")
+
+	err = ioutil.WriteFile(certFile, certPEM, filePerm)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(keyFile, certcrypto.PEMEncode(privateKey), filePerm)
+	if err != nil {
+		return err
+	}
+
+	port, err := freePort()
+	if err != nil {
+		return err
+	}
+
+	httpPort := solver.httpPort
+	if httpPort == 0 {
+		httpPort = 5002
+	}
+
+	config := map[string]interface{}{
+		"pebble": map[string]interface{}{
+			"listenAddress": fmt.Sprintf("127.0.0.1:%d", port),
+			"certificate":   certFile,
+			"privateKey":    keyFile,
+			"httpPort":      httpPort,
+			"tlsPort":       5001,
+		},
+	}
+
+	raw, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	configFile := filepath.Join(e.dir, "pebble-config.json")
+
+	err = ioutil.WriteFile(configFile, raw, filePerm)
+	if err != nil {
+		return err
+	}
+
+	resolver := solver.resolver
+	if resolver == "" {
+		resolver = e.challSrvDNS
+	}
+
+	env := []string{"PEBBLE_VA_NOSLEEP=1", "PEBBLE_WFE_NONCEREJECT=0"}
+
+	err = e.start("pebble", binary, env, "-config", configFile, "-dnsserver", resolver)
+	if err != nil {
+		return err
+	}
+
+	e.pebbleURL = fmt.Sprintf("https://localhost:%d/dir", port)
+
+	client := e.httpClient()
+
+	return wait.For("pebble", 15*time.Second, 200*time.Millisecond, func() (bool, error) {
+		resp, errG := client.Get(e.pebbleURL)
+		if errG != nil {
+			return false, nil
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK, nil
+	})
+}
+
+func (e *selftestEnv) newClient(account *Account, keyType certcrypto.KeyType) (*lego.Client, error) {
+	privateKey, err := certcrypto.GeneratePrivateKey(certcrypto.EC256)
+	if err != nil {
+		return nil, err
+	}
+
+	account.key = privateKey
+
+	config := lego.NewConfig(account)
+	config.CADirURL = e.pebbleURL
+	config.HTTPClient = e.httpClient()
+	config.Certificate.KeyType = keyType
+
+	return lego.NewClient(config)
+}
+
+func (e *selftestEnv) httpClient() *http.Client {
+	transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: e.roots}}
+
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}
+}
+
+// start starts a process, with its output in <name>.log.
+func (e *selftestEnv) start(name, binary string, env []string, args ...string) error {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return fmt.Errorf("%s not found (see https://github.com/letsencrypt/pebble): %w", name, err)
+	}
+
+	out, err := os.Create(filepath.Join(e.dir, name+".log"))
+	if err != nil {
+		return err
+	}
+
+	e.files = append(e.files, out)
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	e.cmds = append(e.cmds, cmd)
+
+	return nil
+}
+
+// stop stops the processes.
+func (e *selftestEnv) stop() {
+	for _, cmd := range e.cmds {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}
+
+	for _, file := range e.files {
+		_ = file.Close()
+	}
+}
+
+// freePort returns a free TCP port of the local host.
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = listener.Close() }()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
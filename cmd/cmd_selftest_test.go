@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selftestReport(t *testing.T) {
+	report := &selftestReport{Solver: selftestSolverInternal, Domain: selftestDomain, Output: "/tmp/selftest"}
+
+	var calls int
+	report.run("first", func() error { calls++; return nil })
+	report.run("second", func() error { calls++; return errors.New("boom") })
+	report.run("third", func() error { calls++; return nil })
+
+	assert.Equal(t, 2, calls)
+	require.Len(t, report.Steps, 2)
+	assert.True(t, report.Steps[0].OK)
+	assert.False(t, report.Steps[1].OK)
+	assert.Equal(t, "boom", report.Steps[1].Error)
+
+	buf := &bytes.Buffer{}
+	printSelftestReport(buf, report)
+
+	assert.Contains(t, buf.String(), "FAIL  second")
+	assert.Contains(t, buf.String(), "Selftest FAILED, diagnostic bundle: /tmp/selftest")
+}
+
+func Test_challtestsrvProvider(t *testing.T) {
+	records := make(map[string]string)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/set-txt", func(rw http.ResponseWriter, req *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		records[body["host"]] = body["value"]
+	})
+	mux.HandleFunc("/clear-txt", func(rw http.ResponseWriter, req *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		delete(records, body["host"])
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider := &challtestsrvProvider{url: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+
+	require.NoError(t, provider.Present("example.com", "token", "keyAuth"))
+
+	fqdn, value := dns01.GetRecord("example.com", "keyAuth")
+	assert.Equal(t, map[string]string{fqdn: value}, records)
+
+	require.NoError(t, provider.CleanUp("example.com", "token", "keyAuth"))
+	assert.Empty(t, records)
+
+	err := provider.post("/unknown", nil)
+	assert.Error(t, err)
+}
+
+func Test_selftestEnv_missingBinary(t *testing.T) {
+	env := &selftestEnv{dir: t.Name()}
+	defer env.stop()
+
+	err := env.start("pebble", "lego-selftest-missing-binary", nil)
+	assert.Error(t, err)
+}
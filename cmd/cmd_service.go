@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// serviceOptions the options used to install a service.
+type serviceOptions struct {
+	Name       string
+	Executable string
+	Args       []string
+	Output     string
+	EnvFile    string
+	LogFile    string
+}
+
+func createService() cli.Command {
+	daemonCmd := createDaemon()
+
+	return cli.Command{
+		Name:  "service",
+		Usage: "Manage lego as a system service (systemd, launchd or Windows service) running the daemon",
+		Subcommands: []cli.Command{
+			{
+				Name:   "install",
+				Usage:  "Install a service running 'lego daemon' with the current global options",
+				Before: daemonCmd.Before,
+				Action: serviceInstall,
+				Flags: append(daemonCmd.Flags,
+					cli.StringFlag{
+						Name:  "name",
+						Usage: "The name of the service.",
+						Value: "lego",
+					},
+					cli.StringFlag{
+						Name:  "output",
+						Usage: "The path of the generated unit file (systemd and launchd only). Use '-' to print it.",
+					},
+					cli.StringFlag{
+						Name:  "env-file",
+						Usage: "A file containing the environment variables of the service, like the DNS provider credentials (systemd only).",
+					},
+				),
+			},
+			{
+				Name:   "uninstall",
+				Usage:  "Uninstall the service",
+				Action: serviceUninstall,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "name",
+						Usage: "The name of the service.",
+						Value: "lego",
+					},
+					cli.StringFlag{
+						Name:  "output",
+						Usage: "The path of the generated unit file (systemd and launchd only).",
+					},
+				},
+			},
+		},
+	}
+}
+
+func serviceInstall(ctx *cli.Context) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	path, err := filepath.Abs(ctx.GlobalString("path"))
+	if err != nil {
+		return err
+	}
+
+	// the working directory of a service is not the current one.
+	args := append([]string{"--path", path}, withoutFlag(globalArgs(ctx), "path")...)
+
+	args = append(args, "daemon")
+	daemonArgs := withoutFlag(flagsToArgs(createDaemon().Flags, ctx.IsSet, ctx.Generic), "api.token")
+
+	// the paths are relative to the working directory.
+	for _, name := range []string{"config", "journal", "delivery.socket"} {
+		if !ctx.IsSet(name) {
+			continue
+		}
+
+		value, err := filepath.Abs(ctx.String(name))
+		if err != nil {
+			return err
+		}
+		daemonArgs = append(withoutFlag(daemonArgs, name), "--"+name, value)
+	}
+
+	args = append(args, daemonArgs...)
+
+	if ctx.IsSet("api.token") {
+		log.Warnf("The API token is not written to the unit file, define LEGO_API_TOKEN in the environment file (--env-file).")
+	}
+
+	opts := serviceOptions{
+		Name:       ctx.String("name"),
+		Executable: executable,
+		Args:       args,
+		Output:     ctx.String("output"),
+		EnvFile:    ctx.String("env-file"),
+		LogFile:    filepath.Join(path, ctx.String("name")+".log"),
+	}
+
+	err = installService(opts)
+	if err != nil {
+		log.Fatalf("Could not install the service %s: %v", opts.Name, err)
+	}
+
+	return nil
+}
+
+func serviceUninstall(ctx *cli.Context) error {
+	opts := serviceOptions{
+		Name:   ctx.String("name"),
+		Output: ctx.String("output"),
+	}
+
+	err := uninstallService(opts)
+	if err != nil {
+		log.Fatalf("Could not uninstall the service %s: %v", opts.Name, err)
+	}
+
+	return nil
+}
+
+// writeUnitFile writes the content of a unit file, or prints it if the path is '-'.
+func writeUnitFile(path string, content []byte) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+
+	err := ioutil.WriteFile(path, content, 0644)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("The unit file has been written to %s", path)
+	return nil
+}
+
+// removeUnitFile removes a unit file.
+func removeUnitFile(path string) error {
+	err := os.Remove(path)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("The unit file %s has been removed", path)
+	return nil
+}
+
+// withoutFlag removes a flag and its value from command line arguments built by flagsToArgs.
+func withoutFlag(args []string, name string) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--"+name {
+			i++
+			continue
+		}
+		result = append(result, args[i])
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/providers/dns"
+	"github.com/urfave/cli"
+)
+
+func createSweep() cli.Command {
+	return cli.Command{
+		Name:   "sweep",
+		Usage:  "Delete the stale '_acme-challenge' TXT records from the zones managed by the DNS provider selected with '--dns'",
+		Action: sweep,
+		Flags: []cli.Flag{
+			cli.DurationFlag{
+				Name:  "older-than",
+				Usage: "Only the records created before this duration are deleted.",
+				Value: 24 * time.Hour,
+			},
+			cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Only list the stale records, without deleting them.",
+			},
+		},
+	}
+}
+
+func sweep(ctx *cli.Context) error {
+	if !ctx.GlobalIsSet("dns") {
+		log.Fatal("Please specify the DNS provider to sweep with --dns")
+	}
+
+	provider, err := dns.NewDNSChallengeProviderByName(ctx.GlobalString("dns"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lister, ok := provider.(challenge.ProviderRecordLister)
+	if !ok {
+		log.Fatalf("The DNS provider %s cannot list its records", ctx.GlobalString("dns"))
+	}
+
+	records, err := lister.ListChallengeRecords()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stale := staleRecords(records, ctx.Duration("older-than"))
+
+	log.Infof("sweep: %d challenge record(s) found, %d stale", len(records), len(stale))
+
+	for _, record := range stale {
+		if ctx.Bool("dry-run") {
+			log.Printf("%s %q (created %s)", record.FQDN, record.Value, record.Created.Format(time.RFC3339))
+			continue
+		}
+
+		err = lister.DeleteChallengeRecord(record)
+		if err != nil {
+			log.Warnf("sweep: %v", err)
+			continue
+		}
+
+		log.Infof("sweep: deleted %s %q", record.FQDN, record.Value)
+	}
+
+	return nil
+}
+
+// staleRecords returns the records created more than olderThan ago.
+// The records without creation time are skipped: their age is unknown,
+// so they could belong to a challenge in progress.
+func staleRecords(records []challenge.Record, olderThan time.Duration) []challenge.Record {
+	limit := clk.Now().Add(-olderThan)
+
+	var stale []challenge.Record
+	for _, record := range records {
+		if record.Created.IsZero() || record.Created.After(limit) {
+			continue
+		}
+		stale = append(stale, record)
+	}
+
+	return stale
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_staleRecords(t *testing.T) {
+	now := time.Date(2020, time.April, 7, 12, 0, 0, 0, time.UTC)
+
+	clk = clock.NewFake(now)
+	defer func() { clk = clock.Real }()
+
+	records := []challenge.Record{
+		{ID: "old", Created: now.Add(-48 * time.Hour)},
+		{ID: "recent", Created: now.Add(-time.Hour)},
+		{ID: "unknown"},
+	}
+
+	stale := staleRecords(records, 24*time.Hour)
+
+	assert.Equal(t, []challenge.Record{records[0]}, stale)
+}
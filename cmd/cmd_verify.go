@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// The checks of the verify command.
+const (
+	verifyCheckKey     = "key"
+	verifyCheckChain   = "chain"
+	verifyCheckNames   = "names"
+	verifyCheckExpiry  = "expiry"
+	verifyCheckLoading = "load"
+)
+
+func createVerify() cli.Command {
+	return cli.Command{
+		Name:   "verify",
+		Usage:  "Verify the stored certificates of the domains (--domains): key pair, chain of trust, names, and expiry",
+		Action: verify,
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "hostname",
+				Usage: "A hostname which must be covered by the certificates. Can be specified multiple times. By default, the domain of the certificate.",
+			},
+			cli.StringFlag{
+				Name:  "ca-bundle",
+				Usage: "The PEM file of the trusted roots used to verify the chain. By default, the system roots.",
+			},
+			cli.IntFlag{
+				Name:  "days",
+				Value: 30,
+				Usage: "The minimal number of days before the expiry of the certificates.",
+			},
+			cli.BoolFlag{
+				Name:  "json",
+				Usage: "Display the results as JSON.",
+			},
+		},
+	}
+}
+
+// verifyResult the result of the verification of a certificate.
+type verifyResult struct {
+	Domain   string        `json:"domain"`
+	Valid    bool          `json:"valid"`
+	NotAfter *time.Time    `json:"notAfter,omitempty"`
+	Checks   []verifyCheck `json:"checks"`
+}
+
+// verifyCheck the result of a check of a certificate.
+type verifyCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (r *verifyResult) add(name, detail string, err error) {
+	check := verifyCheck{Name: name, OK: err == nil, Detail: detail}
+	if err != nil {
+		check.Detail = err.Error()
+		r.Valid = false
+	}
+
+	r.Checks = append(r.Checks, check)
+}
+
+// verifyOptions the options of the verification of a certificate.
+type verifyOptions struct {
+	Hostnames []string
+	Roots     *x509.CertPool // nil to use the system roots.
+	MinDays   int
+}
+
+func verify(ctx *cli.Context) error {
+	domains := normalizedDomains(ctx)
+	if len(domains) == 0 {
+		log.Fatal("Please specify the certificates to verify with --domains/-d")
+	}
+
+	opts := verifyOptions{
+		Hostnames: ctx.StringSlice("hostname"),
+		MinDays:   ctx.Int("days"),
+	}
+
+	if bundle := ctx.String("ca-bundle"); bundle != "" {
+		raw, err := ioutil.ReadFile(bundle)
+		if err != nil {
+			log.Fatalf("Could not read the CA bundle: %v", err)
+		}
+
+		opts.Roots = x509.NewCertPool()
+		if !opts.Roots.AppendCertsFromPEM(raw) {
+			log.Fatalf("No certificate found in the CA bundle %s", bundle)
+		}
+	}
+
+	certsStorage := NewCertificatesStorage(ctx)
+
+	var results []verifyResult
+	for _, domain := range domains {
+		results = append(results, verifyCertificate(certsStorage, domain, opts))
+	}
+
+	if ctx.Bool("json") {
+		err := json.NewEncoder(os.Stdout).Encode(results)
+		if err != nil {
+			return err
+		}
+	} else {
+		displayVerifyResults(os.Stdout, results)
+	}
+
+	for _, result := range results {
+		if !result.Valid {
+			osExit(ExitCodeVerification)
+		}
+	}
+
+	return nil
+}
+
+// verifyCertificate verifies the stored certificate of a domain.
+func verifyCertificate(certsStorage *CertificatesStorage, domain string, opts verifyOptions) verifyResult {
+	result := verifyResult{Domain: domain, Valid: true}
+
+	certificates, err := certsStorage.ReadCertificate(domain, ".crt")
+	if err != nil {
+		result.add(verifyCheckLoading, "", fmt.Errorf("could not load the certificate: %w", err))
+		return result
+	}
+
+	leaf := certificates[0]
+	result.NotAfter = &leaf.NotAfter
+
+	detail, err := checkKeyPair(certsStorage, domain, leaf)
+	result.add(verifyCheckKey, detail, err)
+
+	intermediates := certificates[1:]
+	// the certificates stored without bundle (--no-bundle).
+	if len(intermediates) == 0 && certsStorage.ExistsFile(domain, ".issuer.crt") {
+		intermediates, err = certsStorage.ReadCertificate(domain, ".issuer.crt")
+		if err != nil {
+			result.add(verifyCheckLoading, "", fmt.Errorf("could not load the issuer certificate: %w", err))
+		}
+	}
+
+	detail, err = checkChain(leaf, intermediates, opts.Roots)
+	result.add(verifyCheckChain, detail, err)
+
+	hostnames := opts.Hostnames
+	if len(hostnames) == 0 {
+		hostnames = []string{domain}
+	}
+
+	detail, err = checkNames(leaf, hostnames)
+	result.add(verifyCheckNames, detail, err)
+
+	detail, err = checkExpiry(leaf, opts.MinDays)
+	result.add(verifyCheckExpiry, detail, err)
+
+	return result
+}
+
+func checkKeyPair(certsStorage *CertificatesStorage, domain string, leaf *x509.Certificate) (string, error) {
+	if !certsStorage.ExistsFile(domain, ".key") {
+		return "no private key (CSR)", nil
+	}
+
+	raw, err := certsStorage.ReadFile(domain, ".key")
+	if err != nil {
+		return "", fmt.Errorf("could not load the private key: %w", err)
+	}
+
+	return "", matchPrivateKey(raw, leaf)
+}
+
+// matchPrivateKey checks that the certificate is the certificate of the private key.
+func matchPrivateKey(keyPEM []byte, leaf *x509.Certificate) error {
+	privateKey, err := certcrypto.ParsePEMPrivateKey(keyPEM)
+	if err != nil {
+		return fmt.Errorf("could not parse the private key: %w", err)
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("unsupported private key: %T", privateKey)
+	}
+
+	keyPub, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return err
+	}
+
+	certPub, err := x509.MarshalPKIXPublicKey(leaf.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(keyPub, certPub) {
+		return errors.New("the private key doesn't match the certificate")
+	}
+
+	return nil
+}
+
+func checkChain(leaf *x509.Certificate, intermediates []*x509.Certificate, roots *x509.CertPool) (string, error) {
+	pool := x509.NewCertPool()
+	for _, cert := range intermediates {
+		pool.AddCert(cert)
+	}
+
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		Intermediates: pool,
+		Roots:         roots,
+		CurrentTime:   clk.Now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	root := chains[0][len(chains[0])-1]
+
+	return "root: " + root.Subject.String(), nil
+}
+
+func checkNames(leaf *x509.Certificate, hostnames []string) (string, error) {
+	var missing []string
+	for _, hostname := range hostnames {
+		if leaf.VerifyHostname(hostname) != nil {
+			missing = append(missing, hostname)
+		}
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("not covered: %v", missing)
+	}
+
+	return fmt.Sprintf("covered: %v", hostnames), nil
+}
+
+func checkExpiry(leaf *x509.Certificate, minDays int) (string, error) {
+	left := leaf.NotAfter.Sub(clk.Now())
+	days := int(left.Hours() / 24)
+
+	if left <= 0 {
+		return "", fmt.Errorf("expired since %s", leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	if days < minDays {
+		return "", fmt.Errorf("expires in %d days (less than %d days)", days, minDays)
+	}
+
+	return fmt.Sprintf("expires in %d days", days), nil
+}
+
+func displayVerifyResults(w io.Writer, results []verifyResult) {
+	for _, result := range results {
+		status := "OK"
+		if !result.Valid {
+			status = "FAILED"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s: %s\n", result.Domain, status)
+
+		for _, check := range result.Checks {
+			mark := "ok"
+			if !check.OK {
+				mark = "FAIL"
+			}
+
+			if check.Detail != "" {
+				_, _ = fmt.Fprintf(w, "  [%s] %s: %s\n", mark, check.Name, check.Detail)
+			} else {
+				_, _ = fmt.Fprintf(w, "  [%s] %s\n", mark, check.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPKI struct {
+	roots   *x509.CertPool
+	leaf    []byte
+	leafKey *ecdsa.PrivateKey
+}
+
+func newTestPKI(t *testing.T, notAfter time.Time, names ...string) testPKI {
+	t.Helper()
+
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	require.NoError(t, err)
+
+	root, err := x509.ParseCertificate(rootDER)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, root, &leafKey.PublicKey, rootKey)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	return testPKI{
+		roots:   roots,
+		leaf:    certcrypto.PEMEncode(certcrypto.DERCertificateBytes(leafDER)),
+		leafKey: leafKey,
+	}
+}
+
+func Test_verifyCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-verify")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	pki := newTestPKI(t, time.Now().Add(60*24*time.Hour), "example.com", "www.example.com")
+
+	require.NoError(t, certsStorage.WriteFile("example.com", ".crt", pki.leaf))
+	require.NoError(t, certsStorage.WriteFile("example.com", ".key", certcrypto.PEMEncode(pki.leafKey)))
+
+	result := verifyCertificate(certsStorage, "example.com", verifyOptions{
+		Hostnames: []string{"example.com", "www.example.com"},
+		Roots:     pki.roots,
+		MinDays:   30,
+	})
+
+	assert.True(t, result.Valid, result.Checks)
+	require.Len(t, result.Checks, 4)
+	assert.Equal(t, verifyCheck{Name: verifyCheckChain, OK: true, Detail: "root: CN=Test Root"}, result.Checks[1])
+
+	result = verifyCertificate(certsStorage, "example.com", verifyOptions{
+		Hostnames: []string{"api.example.com"},
+		Roots:     x509.NewCertPool(),
+		MinDays:   90,
+	})
+
+	assert.False(t, result.Valid)
+	assert.True(t, result.Checks[0].OK, "key")
+	assert.False(t, result.Checks[1].OK, "chain")
+	assert.Equal(t, verifyCheck{Name: verifyCheckNames, OK: false, Detail: "not covered: [api.example.com]"}, result.Checks[2])
+	assert.False(t, result.Checks[3].OK, "expiry")
+}
+
+func Test_verifyCertificate_keyMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-verify")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	pki := newTestPKI(t, time.Now().Add(60*24*time.Hour), "example.com")
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	require.NoError(t, certsStorage.WriteFile("example.com", ".crt", pki.leaf))
+	require.NoError(t, certsStorage.WriteFile("example.com", ".key", certcrypto.PEMEncode(otherKey)))
+
+	result := verifyCertificate(certsStorage, "example.com", verifyOptions{Roots: pki.roots, MinDays: 30})
+
+	assert.False(t, result.Valid)
+	assert.Equal(t, verifyCheck{Name: verifyCheckKey, OK: false, Detail: "the private key doesn't match the certificate"}, result.Checks[0])
+}
+
+func Test_verifyCertificate_missing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-verify")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	result := verifyCertificate(&CertificatesStorage{rootPath: dir}, "example.com", verifyOptions{})
+
+	assert.False(t, result.Valid)
+	require.Len(t, result.Checks, 1)
+	assert.Equal(t, verifyCheckLoading, result.Checks[0].Name)
+}
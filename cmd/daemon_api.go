@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v3/log"
+)
+
+// The types of the renewal events.
+const (
+	eventStarted = "started"
+	eventChecked = "checked"
+	eventRenewed = "renewed"
+	eventFailed  = "failed"
+)
+
+// daemonEvent an event of a renewal, streamed by the management API.
+type daemonEvent struct {
+	Time   time.Time `json:"time"`
+	Domain string    `json:"domain"`
+	Type   string    `json:"type"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// eventBroker dispatches the events to the subscribers.
+// The events are dropped for the subscribers which don't read them fast enough.
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan daemonEvent]struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{subscribers: make(map[chan daemonEvent]struct{})}
+}
+
+func (b *eventBroker) subscribe() chan daemonEvent {
+	ch := make(chan daemonEvent, 16)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch
+}
+
+func (b *eventBroker) unsubscribe(ch chan daemonEvent) {
+	b.mu.Lock()
+	delete(b.subscribers, ch)
+	b.mu.Unlock()
+}
+
+// publish sends an event to the subscribers. A nil broker ignores the events.
+func (b *eventBroker) publish(domain, eventType string, err error) {
+	if b == nil {
+		return
+	}
+
+	event := daemonEvent{Time: clk.Now().UTC(), Domain: domain, Type: eventType}
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// daemonCertificateInfo a certificate, as listed by the management API.
+type daemonCertificateInfo struct {
+	Domain   string    `json:"domain"`
+	Domains  []string  `json:"domains,omitempty"`
+	NotAfter time.Time `json:"notAfter,omitempty"`
+	Managed  bool      `json:"managed"`
+}
+
+// pemExtensions the files of a certificate served by the management API, by name.
+var pemExtensions = map[string]string{
+	"certificate": ".crt",
+	"issuer":      ".issuer.crt",
+	"key":         ".key",
+	"pem":         ".pem",
+}
+
+// daemonAPI the management API of the daemon.
+//
+//	GET  /certificates                   lists the certificates.
+//	POST /certificates/<domain>/renew    forces the renewal of a certificate, in the background.
+//	GET  /certificates/<domain>/<file>   returns a PEM file of a managed certificate: certificate, issuer, key, or pem.
+//	GET  /events                         streams the renewal events (one JSON object per line).
+//
+// The private keys (key and pem) are only served on a Unix socket: the TCP mode is plain HTTP.
+type daemonAPI struct {
+	jobs         *daemonJobs
+	certsStorage *CertificatesStorage
+	token        string
+	// serveKeys serves the private keys, only set on a Unix socket.
+	serveKeys bool
+}
+
+// privateFiles the files of a certificate holding the private key.
+var privateFiles = map[string]bool{"key": true, "pem": true}
+
+func (a *daemonAPI) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if a.token != "" {
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			http.Error(rw, "invalid token", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	switch {
+	case req.URL.Path == "/certificates" && req.Method == http.MethodGet:
+		a.listCertificates(rw)
+	case req.URL.Path == "/events" && req.Method == http.MethodGet:
+		a.streamEvents(rw, req)
+	case strings.HasPrefix(req.URL.Path, "/certificates/"):
+		parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/certificates/"), "/")
+		if len(parts) != 2 || parts[0] == "" {
+			http.NotFound(rw, req)
+			return
+		}
+
+		if parts[1] == "renew" && req.Method == http.MethodPost {
+			a.renew(rw, parts[0])
+			return
+		}
+
+		if ext, ok := pemExtensions[parts[1]]; ok && req.Method == http.MethodGet {
+			if privateFiles[parts[1]] && !a.serveKeys {
+				http.Error(rw, "the private keys are only served on a Unix socket", http.StatusForbidden)
+				return
+			}
+
+			a.getFile(rw, req, parts[0], ext)
+			return
+		}
+
+		http.NotFound(rw, req)
+	default:
+		http.NotFound(rw, req)
+	}
+}
+
+func (a *daemonAPI) listCertificates(rw http.ResponseWriter) {
+	managed := make(map[string]bool)
+	for _, domain := range a.jobs.domains() {
+		managed[sanitizedDomain(domain)] = true
+	}
+
+	certificates := []daemonCertificateInfo{}
+
+	matches, err := filepath.Glob(filepath.Join(a.certsStorage.GetRootPath(), "*.crt"))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stored := make(map[string]bool)
+	for _, filename := range matches {
+		if strings.HasSuffix(filename, ".issuer.crt") {
+			continue
+		}
+
+		domain := strings.TrimSuffix(filepath.Base(filename), ".crt")
+
+		info := daemonCertificateInfo{Domain: domain, Managed: managed[domain]}
+		if certs, err := a.certsStorage.ReadCertificate(domain, ".crt"); err == nil && len(certs) > 0 {
+			info.Domains = certs[0].DNSNames
+			info.NotAfter = certs[0].NotAfter
+		}
+
+		stored[domain] = true
+		certificates = append(certificates, info)
+	}
+
+	// the managed certificates which are not obtained yet.
+	for _, domain := range a.jobs.domains() {
+		if !stored[sanitizedDomain(domain)] {
+			certificates = append(certificates, daemonCertificateInfo{Domain: domain, Managed: true})
+		}
+	}
+
+	writeJSON(rw, http.StatusOK, certificates)
+}
+
+func (a *daemonAPI) renew(rw http.ResponseWriter, domain string) {
+	if !a.jobs.renewNow(domain) {
+		http.Error(rw, "the certificate is not managed by the daemon", http.StatusNotFound)
+		return
+	}
+
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+func (a *daemonAPI) getFile(rw http.ResponseWriter, req *http.Request, name, ext string) {
+	// only the files of the managed certificates are served,
+	// the name of the request is never used to build a path.
+	domain, ok := a.managedDomain(name)
+	if !ok {
+		http.NotFound(rw, req)
+		return
+	}
+
+	content, err := a.certsStorage.ReadFile(domain, ext)
+	if os.IsNotExist(err) {
+		http.NotFound(rw, req)
+		return
+	}
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/x-pem-file")
+	_, _ = rw.Write(content)
+}
+
+// managedDomain returns the managed domain whose files are named name (e.g. _.example.com for *.example.com).
+func (a *daemonAPI) managedDomain(name string) (string, bool) {
+	for _, domain := range a.jobs.domains() {
+		if domain == name || sanitizedDomain(domain) == name {
+			return domain, true
+		}
+	}
+	return "", false
+}
+
+func (a *daemonAPI) streamEvents(rw http.ResponseWriter, req *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events := a.jobs.events.subscribe()
+	defer a.jobs.events.unsubscribe(events)
+
+	rw.Header().Set("Content-Type", "application/x-ndjson")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(rw)
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case event := <-events:
+			if err := encoder.Encode(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, status int, value interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(value)
+}
+
+// serveDaemonAPI serves the management API on a Unix socket (unix:<path>) or on a TCP address,
+// and returns a function stopping the server.
+func serveDaemonAPI(address string, api *daemonAPI) (func(), error) {
+	var listener net.Listener
+	var err error
+
+	if path := strings.TrimPrefix(address, "unix:"); path != address {
+		// a socket left by a previous run.
+		_ = os.Remove(path)
+
+		listener, err = listenUnix(path)
+		if err != nil {
+			return nil, err
+		}
+
+		api.serveKeys = true
+	} else {
+		if api.token == "" {
+			return nil, errors.New("a token (--api.token) is required to serve the management API on a TCP address")
+		}
+
+		listener, err = net.Listen("tcp", address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	server := &http.Server{Handler: api, ReadTimeout: 30 * time.Second}
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warnf("daemon: management API: %v", err)
+		}
+	}()
+
+	log.Infof("daemon: the management API is served on %s", address)
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}, nil
+}
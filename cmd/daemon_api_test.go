@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDaemonAPI(t *testing.T) (*daemonJobs, *CertificatesStorage, *httptest.Server, chan []string) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lego-daemon-api")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	renewed := make(chan []string, 1)
+
+	jobs := &daemonJobs{
+		jobs: map[string][]string{
+			"example.com": {"--domains", "example.com", "renew", "--days", "30"},
+			"example.org": {"--domains", "example.org", "renew"},
+		},
+		renew: func(args []string) error {
+			renewed <- args
+			return nil
+		},
+		certsStorage: certsStorage,
+		events:       newEventBroker(),
+	}
+
+	server := httptest.NewServer(&daemonAPI{jobs: jobs, certsStorage: certsStorage, token: "secret"})
+	t.Cleanup(server.Close)
+
+	return jobs, certsStorage, server, renewed
+}
+
+func doAPIRequest(t *testing.T, method, url, token string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
+
+func Test_daemonAPI_token(t *testing.T) {
+	_, _, server, _ := setupDaemonAPI(t)
+
+	resp := doAPIRequest(t, http.MethodGet, server.URL+"/certificates", "")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = doAPIRequest(t, http.MethodGet, server.URL+"/certificates", "invalid")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func Test_daemonAPI_certificates(t *testing.T) {
+	_, certsStorage, server, _ := setupDaemonAPI(t)
+
+	notAfter := time.Now().Add(60 * 24 * time.Hour).Truncate(time.Second).UTC()
+	pki := newTestPKI(t, notAfter, "example.com", "www.example.com")
+	require.NoError(t, certsStorage.WriteFile("example.com", ".crt", pki.leaf))
+
+	resp := doAPIRequest(t, http.MethodGet, server.URL+"/certificates", "secret")
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var certificates []daemonCertificateInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&certificates))
+
+	expected := []daemonCertificateInfo{
+		{Domain: "example.com", Domains: []string{"example.com", "www.example.com"}, NotAfter: notAfter, Managed: true},
+		{Domain: "example.org", Managed: true},
+	}
+	assert.Equal(t, expected, certificates)
+
+	resp = doAPIRequest(t, http.MethodGet, server.URL+"/certificates/example.com/certificate", "secret")
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	content, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, pki.leaf, content)
+
+	// the private keys are not served on a TCP address.
+	resp = doAPIRequest(t, http.MethodGet, server.URL+"/certificates/example.com/key", "secret")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = doAPIRequest(t, http.MethodGet, server.URL+"/certificates/example.com/pem", "secret")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = doAPIRequest(t, http.MethodGet, server.URL+"/certificates/example.com/issuer", "secret")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = doAPIRequest(t, http.MethodGet, server.URL+"/certificates/example.com/json", "secret")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func Test_daemonAPI_renew(t *testing.T) {
+	_, _, server, renewed := setupDaemonAPI(t)
+
+	events := doAPIRequest(t, http.MethodGet, server.URL+"/events", "secret")
+	defer func() { _ = events.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, events.StatusCode)
+
+	resp := doAPIRequest(t, http.MethodPost, server.URL+"/certificates/example.net/renew", "secret")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = doAPIRequest(t, http.MethodPost, server.URL+"/certificates/example.com/renew", "secret")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	select {
+	case args := <-renewed:
+		assert.Equal(t, []string{"--domains", "example.com", "renew", "--days", "-1"}, args)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the renewal has not been run")
+	}
+
+	scanner := bufio.NewScanner(events.Body)
+
+	var types []string
+	for len(types) < 2 && scanner.Scan() {
+		var event daemonEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		assert.Equal(t, "example.com", event.Domain)
+		types = append(types, event.Type)
+	}
+
+	assert.Equal(t, []string{eventStarted, eventChecked}, types)
+}
+
+func Test_daemonAPI_unmanaged(t *testing.T) {
+	_, certsStorage, server, _ := setupDaemonAPI(t)
+
+	notAfter := time.Now().Add(60 * 24 * time.Hour)
+	pki := newTestPKI(t, notAfter, "example.net")
+	require.NoError(t, certsStorage.WriteFile("example.net", ".crt", pki.leaf))
+
+	// a stored certificate which is not managed by the daemon.
+	resp := doAPIRequest(t, http.MethodGet, server.URL+"/certificates/example.net/certificate", "secret")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// an invalid domain must not stop the daemon.
+	resp = doAPIRequest(t, http.MethodGet, server.URL+"/certificates/xn---/certificate", "secret")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func Test_daemonAPI_keys(t *testing.T) {
+	jobs, certsStorage, _, _ := setupDaemonAPI(t)
+
+	require.NoError(t, certsStorage.WriteFile("example.com", ".key", []byte("key")))
+
+	// the Unix socket mode.
+	server := httptest.NewServer(&daemonAPI{jobs: jobs, certsStorage: certsStorage, serveKeys: true})
+	defer server.Close()
+
+	resp := doAPIRequest(t, http.MethodGet, server.URL+"/certificates/example.com/key", "")
+	defer func() { _ = resp.Body.Close() }()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	content, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "key", string(content))
+}
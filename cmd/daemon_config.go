@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// configPollInterval the time between two checks of the modification of the daemon configuration file.
+const configPollInterval = 10 * time.Second
+
+// daemonConfig the certificates managed by the daemon (--config).
+type daemonConfig struct {
+	Certificates []daemonCertificate `json:"certificates"`
+}
+
+// daemonCertificate a certificate managed by the daemon.
+// The options are added to (or replace) the options of the daemon command line, by name.
+type daemonCertificate struct {
+	Domains []string `json:"domains"`
+
+	// GlobalOptions the global options, by name.
+	GlobalOptions map[string][]string `json:"globalOptions,omitempty"`
+	// Options the options of the renew command, by name.
+	Options map[string][]string `json:"options,omitempty"`
+}
+
+// loadDaemonConfig reads the daemon configuration file,
+// and returns the arguments of the renew command of each certificate, by main domain.
+func loadDaemonConfig(ctx *cli.Context, file string) (map[string][]string, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var config daemonConfig
+	err = json.Unmarshal(raw, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration %s: %w", file, err)
+	}
+
+	jobs := make(map[string][]string)
+
+	for i, cert := range config.Certificates {
+		if len(cert.Domains) == 0 {
+			return nil, fmt.Errorf("invalid configuration %s: certificate #%d: no domains", file, i)
+		}
+
+		// the jobs are identified by the A-label of the main domain, like the certificates.
+		domains, err := certificate.NormalizeDomains(cert.Domains)
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration %s: certificate #%d: %w", file, i, err)
+		}
+
+		domain := domains[0]
+		if _, exists := jobs[domain]; exists {
+			return nil, fmt.Errorf("invalid configuration %s: certificate %s: defined twice", file, domain)
+		}
+
+		args, err := certificateArgs(ctx, cert)
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration %s: certificate %s: %w", file, domain, err)
+		}
+
+		jobs[domain] = args
+	}
+
+	return jobs, nil
+}
+
+// certificateArgs builds the arguments of the renew command of a certificate:
+// the options of the daemon command line, replaced by the options of the certificate.
+func certificateArgs(ctx *cli.Context, cert daemonCertificate) ([]string, error) {
+	globals := make(map[string][]string, len(cert.GlobalOptions)+1)
+	for name, values := range cert.GlobalOptions {
+		globals[name] = values
+	}
+	globals["domains"] = cert.Domains
+
+	args, err := optionsToArgs(ctx.App.Flags, globals, ctx.GlobalIsSet, ctx.GlobalGeneric)
+	if err != nil {
+		return nil, err
+	}
+
+	args = append(args, "renew")
+
+	options, err := optionsToArgs(createRenew().Flags, cert.Options, ctx.IsSet, ctx.Generic)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(args, options...), nil
+}
+
+// optionsToArgs rebuilds the command line arguments of the flags which are set,
+// the values of the options replacing the values of the flags.
+// A boolean option is disabled with the value "false".
+func optionsToArgs(flags []cli.Flag, options map[string][]string, isSet func(string) bool, value func(string) interface{}) ([]string, error) {
+	args := flagsToArgs(flags, func(name string) bool {
+		_, replaced := options[name]
+		return !replaced && isSet(name)
+	}, value)
+
+	known := make(map[string]cli.Flag, len(flags))
+	for _, flag := range flags {
+		known[flagName(flag)] = flag
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		flag, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+
+		if _, ok := flag.(cli.BoolFlag); ok {
+			enabled := true
+			for _, v := range options[name] {
+				b, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, fmt.Errorf("option %q: invalid boolean %q", name, v)
+				}
+				enabled = b
+			}
+
+			if enabled {
+				args = append(args, "--"+name)
+			}
+			continue
+		}
+
+		for _, v := range options[name] {
+			args = append(args, "--"+name, v)
+		}
+	}
+
+	return args, nil
+}
+
+// daemonJobs the renewal jobs of the certificates of the daemon, by main domain.
+type daemonJobs struct {
+	ctx  *cli.Context
+	file string
+
+	mu      sync.Mutex
+	jobs    map[string][]string
+	modTime time.Time
+
+	// running serializes the renewals: the periodic checks, and the checks of the added certificates.
+	running sync.Mutex
+
+	renew func(args []string) error
+
+	// certsStorage is used to detect the renewals, if set.
+	certsStorage *CertificatesStorage
+	// events receives the events of the renewals, if set.
+	events *eventBroker
+	// journal records the completed checks, if set.
+	journal *journal
+}
+
+func newDaemonJobs(ctx *cli.Context, file string) (*daemonJobs, error) {
+	d := &daemonJobs{ctx: ctx, file: file, renew: renewOnce}
+
+	if _, err := d.reload(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// reload reads the configuration file, and returns the main domains of the added or modified certificates.
+// The renewals in progress are not interrupted: the removed certificates are no longer checked from the next run.
+func (d *daemonJobs) reload() ([]string, error) {
+	info, err := os.Stat(d.file)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := loadDaemonConfig(d.ctx, d.file)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var changed []string
+	for domain, args := range jobs {
+		previous, exists := d.jobs[domain]
+		if !exists || strings.Join(previous, "\x00") != strings.Join(args, "\x00") {
+			changed = append(changed, domain)
+		}
+	}
+	sort.Strings(changed)
+
+	for domain := range d.jobs {
+		if _, exists := jobs[domain]; !exists {
+			log.Infof("daemon: [%s] removed from the configuration", domain)
+		}
+	}
+
+	if d.jobs != nil {
+		for _, domain := range changed {
+			log.Infof("daemon: [%s] added or modified in the configuration", domain)
+		}
+	}
+
+	d.jobs = jobs
+	d.modTime = info.ModTime()
+
+	return changed, nil
+}
+
+// modified reports if the configuration file has been modified since the last load.
+func (d *daemonJobs) modified() bool {
+	info, err := os.Stat(d.file)
+	if err != nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return !info.ModTime().Equal(d.modTime)
+}
+
+// run checks the renewal of the certificates (all the certificates if no domain is given).
+func (d *daemonJobs) run(domains ...string) {
+	d.running.Lock()
+	defer d.running.Unlock()
+
+	if len(domains) == 0 {
+		domains = d.domains()
+	}
+
+	d.mu.Lock()
+	var jobs []string
+	var args [][]string
+	for _, domain := range domains {
+		if a, ok := d.jobs[domain]; ok {
+			jobs = append(jobs, domain)
+			args = append(args, a)
+		}
+	}
+	d.mu.Unlock()
+
+	for i, domain := range jobs {
+		d.check(domain, args[i])
+	}
+}
+
+// renewNow forces the renewal of a certificate, in the background.
+// It returns false if the certificate is not managed by the daemon.
+func (d *daemonJobs) renewNow(domain string) bool {
+	d.mu.Lock()
+	args, ok := d.jobs[domain]
+	d.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	// a negative number of days forces the renewal.
+	args = append(withoutFlag(args, "days"), "--days", "-1")
+
+	go func() {
+		d.running.Lock()
+		defer d.running.Unlock()
+
+		d.check(domain, args)
+	}()
+
+	return true
+}
+
+// domains returns the main domains of the certificates.
+func (d *daemonJobs) domains() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var domains []string
+	for domain := range d.jobs {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	return domains
+}
+
+// check runs the renew command of a certificate, and publishes the events of the renewal.
+func (d *daemonJobs) check(domain string, args []string) {
+	d.events.publish(domain, eventStarted, nil)
+
+	serial := d.serial(domain)
+
+	if err := d.renew(args); err != nil {
+		log.Warnf("daemon: [%s] renewal failed: %v", domain, err)
+		d.events.publish(domain, eventFailed, err)
+		return
+	}
+
+	d.journal.record(journalEntry{Type: journalChecked, Domain: domain})
+
+	if serial != d.serial(domain) {
+		d.events.publish(domain, eventRenewed, nil)
+		return
+	}
+
+	d.events.publish(domain, eventChecked, nil)
+}
+
+// serial returns the serial number of the stored certificate of a domain, or an empty string.
+func (d *daemonJobs) serial(domain string) string {
+	if d.certsStorage == nil {
+		return ""
+	}
+
+	certificates, err := d.certsStorage.ReadCertificate(domain, ".crt")
+	if err != nil || len(certificates) == 0 {
+		return ""
+	}
+
+	return certificates[0].SerialNumber.String()
+}
+
+// watch reloads the configuration when the file is modified or when the process receives a reload signal,
+// and checks immediately the renewal of the added or modified certificates, until stop is closed.
+func (d *daemonJobs) watch(stop <-chan struct{}) {
+	signals := make(chan os.Signal, 1)
+	if len(reloadSignals) > 0 {
+		signal.Notify(signals, reloadSignals...)
+		defer signal.Stop(signals)
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case sig := <-signals:
+			log.Infof("daemon: received %s, reloading %s", sig, d.file)
+		case <-clk.After(configPollInterval):
+			if !d.modified() {
+				continue
+			}
+			log.Infof("daemon: %s modified, reloading", d.file)
+		}
+
+		changed, err := d.reload()
+		if err != nil {
+			log.Warnf("daemon: the configuration is not reloaded, the previous configuration is kept: %v", err)
+			continue
+		}
+
+		if len(changed) > 0 {
+			go d.run(changed...)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func writeDaemonConfig(t *testing.T, file, content string, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), filePerm))
+	require.NoError(t, os.Chtimes(file, modTime, modTime))
+}
+
+func Test_loadDaemonConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-daemon")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "daemon.json")
+	writeDaemonConfig(t, file, `{
+	"certificates": [
+		{"domains": ["example.com", "www.example.com"]},
+		{"domains": ["example.org"], "globalOptions": {"dns": ["route53"], "http": ["false"]}, "options": {"days": ["10"], "reuse-key": ["true"]}},
+		{"domains": ["bücher.example"]}
+	]
+}`, time.Now())
+
+	runWithFlags(t, []string{"-m", "foo@example.com", "--http", "--dns", "cloudflare", "renew", "--days", "20"}, func(ctx *cli.Context) {
+		jobs, err := loadDaemonConfig(ctx, file)
+		require.NoError(t, err)
+
+		expected := map[string][]string{
+			"example.com": {
+				"--email", "foo@example.com", "--http", "--dns", "cloudflare", "--domains", "example.com", "--domains", "www.example.com",
+				"renew", "--days", "20",
+			},
+			"example.org": {
+				"--email", "foo@example.com", "--dns", "route53", "--domains", "example.org",
+				"renew", "--days", "10", "--reuse-key",
+			},
+			"xn--bcher-kva.example": {
+				"--email", "foo@example.com", "--http", "--dns", "cloudflare", "--domains", "bücher.example",
+				"renew", "--days", "20",
+			},
+		}
+		assert.Equal(t, expected, jobs)
+	})
+}
+
+func Test_loadDaemonConfig_invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-daemon")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "daemon.json")
+
+	testCases := []struct {
+		desc     string
+		content  string
+		expected string
+	}{
+		{
+			desc:     "no domains",
+			content:  `{"certificates": [{"domains": []}]}`,
+			expected: "certificate #0: no domains",
+		},
+		{
+			desc:     "defined twice",
+			content:  `{"certificates": [{"domains": ["example.com"]}, {"domains": ["example.com", "www.example.com"]}]}`,
+			expected: "certificate example.com: defined twice",
+		},
+		{
+			desc:     "unknown option",
+			content:  `{"certificates": [{"domains": ["example.com"], "options": {"foo": ["bar"]}}]}`,
+			expected: `certificate example.com: unknown option "foo"`,
+		},
+		{
+			desc:     "invalid boolean",
+			content:  `{"certificates": [{"domains": ["example.com"], "globalOptions": {"http": ["yes"]}}]}`,
+			expected: `certificate example.com: option "http": invalid boolean "yes"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			writeDaemonConfig(t, file, test.content, time.Now())
+
+			runWithFlags(t, []string{"renew"}, func(ctx *cli.Context) {
+				_, err := loadDaemonConfig(ctx, file)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expected)
+			})
+		})
+	}
+}
+
+func Test_daemonJobs_reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-daemon")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "daemon.json")
+	start := time.Now().Add(-time.Hour)
+	writeDaemonConfig(t, file, `{"certificates": [{"domains": ["a.example.com"]}, {"domains": ["b.example.com"]}]}`, start)
+
+	runWithFlags(t, []string{"--http", "renew"}, func(ctx *cli.Context) {
+		jobs, err := newDaemonJobs(ctx, file)
+		require.NoError(t, err)
+
+		var renewed []string
+		jobs.renew = func(args []string) error {
+			renewed = append(renewed, args[2])
+			return nil
+		}
+
+		jobs.run()
+		assert.Equal(t, []string{"a.example.com", "b.example.com"}, renewed)
+		assert.False(t, jobs.modified())
+
+		// b is modified, c is added, a is removed.
+		writeDaemonConfig(t, file, `{"certificates": [
+	{"domains": ["b.example.com"], "options": {"days": ["10"]}},
+	{"domains": ["c.example.com"]}
+]}`, start.Add(time.Minute))
+		assert.True(t, jobs.modified())
+
+		changed, err := jobs.reload()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b.example.com", "c.example.com"}, changed)
+
+		renewed = nil
+		jobs.run(changed...)
+		assert.Equal(t, []string{"b.example.com", "c.example.com"}, renewed)
+
+		// an invalid configuration keeps the previous one.
+		writeDaemonConfig(t, file, `{"certificates": [{"domains": []}]}`, start.Add(2*time.Minute))
+
+		_, err = jobs.reload()
+		require.Error(t, err)
+
+		renewed = nil
+		jobs.run()
+		assert.Equal(t, []string{"b.example.com", "c.example.com"}, renewed)
+	})
+}
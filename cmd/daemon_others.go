@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"io"
+	stdlog "log"
+	"os"
+
+	"github.com/go-acme/lego/v3/log"
+)
+
+func runDaemon(_ string, plan *daemonPlan, job func()) error {
+	// journald already timestamps the entries.
+	if os.Getenv("JOURNAL_STREAM") != "" {
+		log.Logger = stdlog.New(os.Stdout, "", 0)
+	}
+
+	runForeground(plan, job)
+
+	return nil
+}
+
+func logWriter() io.Writer {
+	return os.Stdout
+}
@@ -0,0 +1,9 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package cmd
+
+import "os"
+
+// reloadSignals the signals reloading the daemon configuration (none without SIGHUP, the file is watched).
+var reloadSignals []os.Signal
@@ -0,0 +1,12 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals the signals reloading the daemon configuration.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
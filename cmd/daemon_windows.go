@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-acme/lego/v3/log"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLog is used to route the logs when running as a Windows service.
+var eventLog *eventlog.Log
+
+func runDaemon(name string, plan *daemonPlan, job func()) error {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		return fmt.Errorf("daemon: failed to determine if the session is interactive: %w", err)
+	}
+
+	if interactive {
+		runForeground(plan, job)
+		return nil
+	}
+
+	eventLog, err = eventlog.Open(name)
+	if err != nil {
+		return fmt.Errorf("daemon: failed to open the event log: %w", err)
+	}
+	defer func() { _ = eventLog.Close() }()
+
+	log.Logger = &eventLogger{elog: eventLog}
+
+	return svc.Run(name, &windowsService{plan: plan, job: job})
+}
+
+func logWriter() io.Writer {
+	if eventLog == nil {
+		return os.Stdout
+	}
+
+	return eventLogWriter{elog: eventLog}
+}
+
+// eventLogWriter writes each line as an event log entry.
+type eventLogWriter struct {
+	elog *eventlog.Log
+}
+
+func (w eventLogWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\r\n"), "\n") {
+		if err := w.elog.Info(1, strings.TrimSuffix(line, "\r")); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// windowsService implements svc.Handler.
+type windowsService struct {
+	plan *daemonPlan
+	job  func()
+}
+
+func (s *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+
+	status <- svc.Status{State: svc.StartPending}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		schedule(stop, s.plan, s.job)
+		close(done)
+	}()
+
+	status <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for req := range requests {
+		switch req.Cmd {
+		case svc.Interrogate:
+			status <- req.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending}
+			close(stop)
+			<-done
+			return false, 0
+		default:
+			log.Warnf("daemon: unexpected control request #%d", req.Cmd)
+		}
+	}
+
+	return false, 0
+}
+
+// eventLogger implements log.StdLogger on top of the Windows event log.
+type eventLogger struct {
+	elog *eventlog.Log
+}
+
+func (l *eventLogger) Fatal(args ...interface{}) {
+	_ = l.elog.Error(1, fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+func (l *eventLogger) Fatalln(args ...interface{}) {
+	_ = l.elog.Error(1, fmt.Sprintln(args...))
+	os.Exit(1)
+}
+
+func (l *eventLogger) Fatalf(format string, args ...interface{}) {
+	_ = l.elog.Error(1, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func (l *eventLogger) Print(args ...interface{}) {
+	_ = l.elog.Info(1, fmt.Sprint(args...))
+}
+
+func (l *eventLogger) Println(args ...interface{}) {
+	_ = l.elog.Info(1, fmt.Sprintln(args...))
+}
+
+func (l *eventLogger) Printf(format string, args ...interface{}) {
+	_ = l.elog.Info(1, fmt.Sprintf(format, args...))
+}
+
+// reloadSignals the signals reloading the daemon configuration (none on Windows, the file is watched).
+var reloadSignals []os.Signal
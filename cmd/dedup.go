@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// dedupIndexFile the index of the shareable certificates, in the certificates directory.
+const dedupIndexFile = ".dedup.json"
+
+// Modes of the deduplication (--dedup).
+const (
+	dedupHardlink = "hardlink"
+	dedupSymlink  = "symlink"
+)
+
+// dedupExts the files linked to the shared certificate.
+// The resource (.json) and the renewal metadata stay specific to each name.
+var dedupExts = []string{".crt", ".issuer.crt", ".key", ".pem"}
+
+// certificateDedup shares the certificate of another name having the same domains and key type (content address),
+// instead of obtaining a new one.
+// The files of the name are links to the files of the shared certificate,
+// the renewal of any of the names updates the files in place, so the links stay valid.
+type certificateDedup struct {
+	certsStorage *CertificatesStorage
+	mode         string
+	keyType      certcrypto.KeyType
+}
+
+// newCertificateDedup returns nil if the deduplication is disabled.
+func newCertificateDedup(ctx *cli.Context, certsStorage *CertificatesStorage) *certificateDedup {
+	mode := ctx.String("dedup")
+	switch mode {
+	case "":
+		return nil
+	case dedupHardlink, dedupSymlink:
+	default:
+		log.Fatalf("Invalid --dedup mode %q: hardlink or symlink", mode)
+	}
+
+	if ctx.GlobalIsSet("csr") {
+		log.Warnf("--dedup is ignored with --csr")
+		return nil
+	}
+
+	return &certificateDedup{
+		certsStorage: certsStorage,
+		mode:         mode,
+		keyType:      getKeyType(ctx),
+	}
+}
+
+// shareCertificate shares an existing certificate for the domains of the command line (--dedup), if any.
+func shareCertificate(ctx *cli.Context, dedup *certificateDedup) (*certificate.Resource, error) {
+	if dedup == nil {
+		return nil, nil
+	}
+
+	domains := getDomains(ctx)
+
+	return dedup.share(domains[0], domains)
+}
+
+// share links the files of the name to an existing certificate for the same domains and key type,
+// and returns its resource, or nil if there is no usable certificate.
+func (d *certificateDedup) share(name string, domains []string) (*certificate.Resource, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	address, err := certificate.ContentAddress(domains, d.keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, ok := index[address]
+	if !ok || sanitizedDomain(canonical) == sanitizedDomain(name) {
+		return nil, nil
+	}
+
+	if !d.usable(canonical, address) {
+		return nil, nil
+	}
+
+	for _, ext := range dedupExts {
+		if !d.certsStorage.ExistsFile(canonical, ext) {
+			continue
+		}
+
+		err = d.link(canonical, name, ext)
+		if err != nil {
+			return nil, fmt.Errorf("could not link the %s file of %s: %w", ext, canonical, err)
+		}
+	}
+
+	resource := d.certsStorage.ReadResource(canonical)
+	resource.Domain = name
+
+	resource.Certificate, err = d.certsStorage.ReadFile(name, ".crt")
+	if err != nil {
+		return nil, err
+	}
+
+	resource.PrivateKey, err = d.certsStorage.ReadFile(name, ".key")
+	if err != nil {
+		return nil, err
+	}
+
+	if d.certsStorage.ExistsFile(name, ".issuer.crt") {
+		resource.IssuerCertificate, err = d.certsStorage.ReadFile(name, ".issuer.crt")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	jsonBytes, err := json.MarshalIndent(resource, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.certsStorage.WriteFile(name, ".json", jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("[%s] The certificate of %s has the same domains and key type: shared (%s)", name, canonical, d.mode)
+
+	return &resource, nil
+}
+
+// record makes the certificate of the name shareable with the next names having the same domains and key type.
+func (d *certificateDedup) record(certRes *certificate.Resource) error {
+	if d == nil {
+		return nil
+	}
+
+	cert, err := certcrypto.ParsePEMCertificate(certRes.Certificate)
+	if err != nil {
+		return err
+	}
+
+	address, err := certificate.ContentAddress(certcrypto.ExtractDomains(cert), d.keyType)
+	if err != nil {
+		return err
+	}
+
+	index, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	// the first usable certificate stays the canonical one.
+	if canonical, ok := index[address]; ok && d.usable(canonical, address) {
+		return nil
+	}
+
+	index[address] = certRes.Domain
+
+	return d.save(index)
+}
+
+// usable checks that the certificate of the name still has the content address, and that a third of its validity remains.
+func (d *certificateDedup) usable(name, address string) bool {
+	if !d.certsStorage.ExistsFile(name, ".crt") || !d.certsStorage.ExistsFile(name, ".key") {
+		return false
+	}
+
+	certificates, err := d.certsStorage.ReadCertificate(name, ".crt")
+	if err != nil {
+		log.Warnf("[%s] Could not read the certificate: %v", name, err)
+		return false
+	}
+
+	keyBytes, err := d.certsStorage.ReadFile(name, ".key")
+	if err != nil {
+		log.Warnf("[%s] Could not read the private key: %v", name, err)
+		return false
+	}
+
+	privateKey, err := certcrypto.ParsePEMPrivateKey(keyBytes)
+	if err != nil {
+		log.Warnf("[%s] Could not parse the private key: %v", name, err)
+		return false
+	}
+
+	cert := certificates[0]
+
+	current, err := certificate.ContentAddress(certcrypto.ExtractDomains(cert), certcrypto.KeyTypeOf(privateKey))
+	if err != nil || current != address {
+		return false
+	}
+
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+
+	return cert.NotAfter.Sub(clk.Now()) > lifetime/3
+}
+
+// link replaces the file of the name by a link to the file of the canonical name.
+func (d *certificateDedup) link(canonical, name, ext string) error {
+	source := filepath.Join(d.certsStorage.GetRootPath(), sanitizedDomain(canonical)+ext)
+	target := filepath.Join(d.certsStorage.GetRootPath(), sanitizedDomain(name)+ext)
+
+	err := os.Remove(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if d.mode == dedupSymlink {
+		return os.Symlink(filepath.Base(source), target)
+	}
+
+	return os.Link(source, target)
+}
+
+// load reads the index, by content address.
+func (d *certificateDedup) load() (map[string]string, error) {
+	index := make(map[string]string)
+
+	raw, err := ioutil.ReadFile(filepath.Join(d.certsStorage.GetRootPath(), dedupIndexFile))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(raw, &index)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file %s: %w", dedupIndexFile, err)
+	}
+
+	return index, nil
+}
+
+func (d *certificateDedup) save(index map[string]string) error {
+	raw, err := json.MarshalIndent(index, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(d.certsStorage.GetRootPath(), dedupIndexFile), raw, filePerm)
+}
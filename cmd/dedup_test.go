@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDedup(t *testing.T, mode string, notAfter time.Time) (*certificateDedup, *certificate.Resource) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lego-dedup")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	pki := newTestPKI(t, notAfter, "example.com", "www.example.com")
+
+	certRes := &certificate.Resource{
+		Domain:      "example.com",
+		Certificate: pki.leaf,
+		PrivateKey:  certcrypto.PEMEncode(pki.leafKey),
+	}
+	certsStorage.SaveResource(certRes)
+
+	return &certificateDedup{certsStorage: certsStorage, mode: mode, keyType: certcrypto.EC256}, certRes
+}
+
+func Test_certificateDedup_hardlink(t *testing.T) {
+	dedup, certRes := setupDedup(t, dedupHardlink, time.Now().Add(90*24*time.Hour))
+
+	require.NoError(t, dedup.record(certRes))
+
+	shared, err := dedup.share("www.example.com", []string{"www.example.com", "example.com"})
+	require.NoError(t, err)
+	require.NotNil(t, shared)
+
+	assert.Equal(t, "www.example.com", shared.Domain)
+	assert.Equal(t, certRes.Certificate, shared.Certificate)
+	assert.Equal(t, certRes.PrivateKey, shared.PrivateKey)
+
+	root := dedup.certsStorage.GetRootPath()
+
+	source, err := os.Stat(filepath.Join(root, "example.com.crt"))
+	require.NoError(t, err)
+
+	target, err := os.Lstat(filepath.Join(root, "www.example.com.crt"))
+	require.NoError(t, err)
+
+	assert.True(t, os.SameFile(source, target))
+
+	// the renewal of any of the names updates both.
+	require.NoError(t, dedup.certsStorage.WriteFile("www.example.com", ".crt", []byte("renewed")))
+
+	content, err := dedup.certsStorage.ReadFile("example.com", ".crt")
+	require.NoError(t, err)
+	assert.Equal(t, "renewed", string(content))
+}
+
+func Test_certificateDedup_symlink(t *testing.T) {
+	dedup, certRes := setupDedup(t, dedupSymlink, time.Now().Add(90*24*time.Hour))
+
+	require.NoError(t, dedup.record(certRes))
+
+	shared, err := dedup.share("www.example.com", []string{"example.com", "www.example.com"})
+	require.NoError(t, err)
+	require.NotNil(t, shared)
+
+	link, err := os.Readlink(filepath.Join(dedup.certsStorage.GetRootPath(), "www.example.com.key"))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com.key", link)
+}
+
+func Test_certificateDedup_notShared(t *testing.T) {
+	dedup, certRes := setupDedup(t, dedupHardlink, time.Now().Add(90*24*time.Hour))
+
+	// nothing recorded.
+	shared, err := dedup.share("www.example.com", []string{"www.example.com", "example.com"})
+	require.NoError(t, err)
+	assert.Nil(t, shared)
+
+	require.NoError(t, dedup.record(certRes))
+
+	// other domains.
+	shared, err = dedup.share("www.example.com", []string{"www.example.com"})
+	require.NoError(t, err)
+	assert.Nil(t, shared)
+
+	// other key type.
+	dedup.keyType = certcrypto.RSA2048
+
+	shared, err = dedup.share("www.example.com", []string{"www.example.com", "example.com"})
+	require.NoError(t, err)
+	assert.Nil(t, shared)
+}
+
+func Test_certificateDedup_expiring(t *testing.T) {
+	// less than a third of the validity remains.
+	dedup, certRes := setupDedup(t, dedupHardlink, time.Now().Add(10*time.Minute))
+
+	require.NoError(t, dedup.record(certRes))
+
+	shared, err := dedup.share("www.example.com", []string{"www.example.com", "example.com"})
+	require.NoError(t, err)
+	assert.Nil(t, shared)
+}
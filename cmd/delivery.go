@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/go-acme/lego/v3/delivery"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// certificatesSource loads the certificates and the private keys of the storage.
+func certificatesSource(certsStorage *CertificatesStorage) delivery.SourceFunc {
+	return func(domain string) (*delivery.Certificate, error) {
+		cert, err := certsStorage.ReadFile(domain, ".crt")
+		if os.IsNotExist(err) {
+			return nil, delivery.ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := certsStorage.ReadFile(domain, ".key")
+		if os.IsNotExist(err) {
+			return nil, delivery.ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// the issuer certificate is not stored with --no-bundle.
+		issuer, err := certsStorage.ReadFile(domain, ".issuer.crt")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		return &delivery.Certificate{Domain: domain, Certificate: cert, PrivateKey: key, IssuerCertificate: issuer}, nil
+	}
+}
+
+// serveDelivery serves the certificates on a Unix socket, and pushes the renewed certificates to the watchers.
+// It returns a function stopping the server.
+func serveDelivery(socket string, certsStorage *CertificatesStorage, events *eventBroker) (func(), error) {
+	// a socket left by a previous run.
+	_ = os.Remove(socket)
+
+	listener, err := listenUnix(socket)
+	if err != nil {
+		return nil, err
+	}
+
+	server := delivery.NewServer(certificatesSource(certsStorage))
+
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Warnf("daemon: certificate delivery: %v", err)
+		}
+	}()
+
+	renewals := events.subscribe()
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case event := <-renewals:
+				if event.Type == eventRenewed {
+					server.Notify(event.Domain)
+				}
+			}
+		}
+	}()
+
+	log.Infof("daemon: the certificates are delivered on %s", socket)
+
+	return func() {
+		events.unsubscribe(renewals)
+		close(done)
+		server.Stop()
+	}, nil
+}
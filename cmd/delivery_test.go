@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/go-acme/lego/v3/delivery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_certificatesSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-delivery")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+	source := certificatesSource(certsStorage)
+
+	_, err = source.Load("*.example.com")
+	assert.Equal(t, delivery.ErrNotFound, err)
+
+	require.NoError(t, certsStorage.WriteFile("*.example.com", ".crt", []byte("cert")))
+
+	_, err = source.Load("*.example.com")
+	assert.Equal(t, delivery.ErrNotFound, err)
+
+	require.NoError(t, certsStorage.WriteFile("*.example.com", ".key", []byte("key")))
+
+	cert, err := source.Load("*.example.com")
+	require.NoError(t, err)
+
+	expected := &delivery.Certificate{Domain: "*.example.com", Certificate: []byte("cert"), PrivateKey: []byte("key")}
+	assert.Equal(t, expected, cert)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-acme/lego/v3/acme/api"
+)
+
+// directoryCacheFileName the file caching the directories of the CAs ("path" option).
+const directoryCacheFileName = "directories.json"
+
+// fileDirectoryCache an api.DirectoryCache storing the directories in a file, by directory URL.
+type fileDirectoryCache struct {
+	mu       sync.Mutex
+	filename string
+}
+
+func newFileDirectoryCache(root string) *fileDirectoryCache {
+	return &fileDirectoryCache{filename: filepath.Join(root, directoryCacheFileName)}
+}
+
+func (c *fileDirectoryCache) Load(caDirURL string) (*api.CachedDirectory, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return entries[caDirURL], nil
+}
+
+func (c *fileDirectoryCache) Store(caDirURL string, entry *api.CachedDirectory) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	entries[caDirURL] = entry
+
+	raw, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	err = createNonExistingFolder(filepath.Dir(c.filename))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.filename, raw, filePerm)
+}
+
+func (c *fileDirectoryCache) read() (map[string]*api.CachedDirectory, error) {
+	entries := make(map[string]*api.CachedDirectory)
+
+	raw, err := ioutil.ReadFile(c.filename)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(raw, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/acme/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fileDirectoryCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-directory")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	cache := newFileDirectoryCache(dir)
+
+	entry, err := cache.Load("https://ca.example.com/directory")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	expires := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	err = cache.Store("https://ca.example.com/directory", &api.CachedDirectory{
+		Directory: acme.Directory{NewOrderURL: "https://ca.example.com/new-order"},
+		ETag:      `"v1"`,
+		Expires:   expires,
+	})
+	require.NoError(t, err)
+
+	err = cache.Store("https://other.example.com/directory", &api.CachedDirectory{
+		Directory: acme.Directory{NewOrderURL: "https://other.example.com/new-order"},
+	})
+	require.NoError(t, err)
+
+	entry, err = newFileDirectoryCache(dir).Load("https://ca.example.com/directory")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+
+	assert.Equal(t, "https://ca.example.com/new-order", entry.Directory.NewOrderURL)
+	assert.Equal(t, `"v1"`, entry.ETag)
+	assert.True(t, expires.Equal(entry.Expires))
+}
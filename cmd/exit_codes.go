@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// The exit codes of the process, by failure class.
+// They are part of the interface of the CLI: the existing values must not change.
+const (
+	// ExitCodeError any other error (e.g. invalid options).
+	ExitCodeError = 1
+	// ExitCodeAuthentication the CA rejected the account (unknown or deactivated account, invalid EAB, rejected key, ...).
+	ExitCodeAuthentication = 10
+	// ExitCodeRateLimited the CA rate limits the account or the domains.
+	ExitCodeRateLimited = 11
+	// ExitCodeValidation the CA could not validate a challenge.
+	ExitCodeValidation = 12
+	// ExitCodeProvider the challenge provider failed (e.g. the DNS provider could not create the TXT record).
+	ExitCodeProvider = 13
+	// ExitCodeStorage the accounts or the certificates could not be read or written.
+	ExitCodeStorage = 14
+	// ExitCodeUnavailable the CA is unavailable (503, e.g. during a maintenance).
+	ExitCodeUnavailable = 15
+	// ExitCodeVerification the verify command found a problem with a certificate.
+	ExitCodeVerification = 16
+)
+
+// authenticationProblems the ACME error types reported as authentication failures.
+var authenticationProblems = map[string]bool{
+	"urn:ietf:params:acme:error:accountDoesNotExist":     true,
+	"urn:ietf:params:acme:error:externalAccountRequired": true,
+	"urn:ietf:params:acme:error:badPublicKey":            true,
+	"urn:ietf:params:acme:error:badSignatureAlgorithm":   true,
+	"urn:ietf:params:acme:error:userActionRequired":      true,
+	"urn:ietf:params:acme:error:unauthorized":            true,
+}
+
+// osExit exits the process, replaced in the tests.
+var osExit = os.Exit
+
+// ExitCode returns the exit code of the process for an error (see the ExitCode constants).
+// For an error per domain, the code of the first classified error is used.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if multi, ok := err.(interface{ Errors() []error }); ok {
+		for _, e := range multi.Errors() {
+			if code := ExitCode(e); code != ExitCodeError {
+				return code
+			}
+		}
+		return ExitCodeError
+	}
+
+	var providerErr *challenge.ProviderError
+	if errors.As(err, &providerErr) {
+		return ExitCodeProvider
+	}
+
+	var validationErr *challenge.ValidationError
+	if errors.As(err, &validationErr) {
+		return ExitCodeValidation
+	}
+
+	var problem *acme.ProblemDetails
+	if errors.As(err, &problem) {
+		if problem.Type == "urn:ietf:params:acme:error:rateLimited" {
+			return ExitCodeRateLimited
+		}
+		if authenticationProblems[problem.Type] {
+			return ExitCodeAuthentication
+		}
+	}
+
+	if _, ok := caUnavailable(err); ok {
+		return ExitCodeUnavailable
+	}
+
+	return ExitCodeError
+}
+
+// fatalf logs the error message, then exits with the exit code of the error.
+func fatalf(err error, format string, args ...interface{}) {
+	log.Printf(format, args...)
+	osExit(ExitCode(err))
+}
+
+// storageFatalf logs the error message, then exits with the storage exit code.
+func storageFatalf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+	osExit(ExitCodeStorage)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
+)
+
+type domainErrors map[string]error
+
+func (e domainErrors) Error() string { return "error per domain" }
+
+func (e domainErrors) Errors() []error {
+	var errs []error
+	for _, domain := range []string{"a.example.com", "b.example.com"} {
+		if err, ok := e[domain]; ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected int
+	}{
+		{
+			desc:     "no error",
+			expected: 0,
+		},
+		{
+			desc:     "generic error",
+			err:      errors.New("boom"),
+			expected: ExitCodeError,
+		},
+		{
+			desc:     "rate limited",
+			err:      fmt.Errorf("new order: %w", &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:rateLimited", HTTPStatus: http.StatusTooManyRequests}),
+			expected: ExitCodeRateLimited,
+		},
+		{
+			desc:     "account does not exist",
+			err:      &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:accountDoesNotExist", HTTPStatus: http.StatusBadRequest},
+			expected: ExitCodeAuthentication,
+		},
+		{
+			desc:     "CA unavailable",
+			err:      &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:serverInternal", HTTPStatus: http.StatusServiceUnavailable},
+			expected: ExitCodeUnavailable,
+		},
+		{
+			desc:     "validation failed",
+			err:      &challenge.ValidationError{Err: &acme.ProblemDetails{Type: "urn:ietf:params:acme:error:unauthorized", HTTPStatus: http.StatusForbidden}},
+			expected: ExitCodeValidation,
+		},
+		{
+			desc:     "provider error",
+			err:      fmt.Errorf("[example.com] acme: error presenting token: %w", &challenge.ProviderError{Err: errors.New("API error")}),
+			expected: ExitCodeProvider,
+		},
+		{
+			desc: "errors per domain",
+			err: domainErrors{
+				"a.example.com": errors.New("boom"),
+				"b.example.com": &challenge.ValidationError{Err: errors.New("invalid")},
+			},
+			expected: ExitCodeValidation,
+		},
+		{
+			desc:     "unclassified errors per domain",
+			err:      domainErrors{"a.example.com": errors.New("boom")},
+			expected: ExitCodeError,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, ExitCode(test.err))
+		})
+	}
+}
+
+func Test_fatalf(t *testing.T) {
+	var code int
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	fatalf(&challenge.ProviderError{Err: errors.New("API error")}, "Could not obtain certificates: %v", "API error")
+	assert.Equal(t, ExitCodeProvider, code)
+
+	storageFatalf("Unable to save the certificate: %v", "disk full")
+	assert.Equal(t, ExitCodeStorage, code)
+}
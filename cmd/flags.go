@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/go-acme/lego/v3/lego"
 	"github.com/urfave/cli"
 )
@@ -11,15 +13,32 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Name:  "domains, d",
 			Usage: "Add a domain to the process. Can be specified multiple times.",
 		},
+		cli.BoolFlag{
+			Name:  "with-wildcard",
+			Usage: "Add the wildcard of each domain (*.domain) to the certificate. Requires a DNS challenge.",
+		},
 		cli.StringFlag{
 			Name:  "server, s",
-			Usage: "CA hostname (and optionally :port). The server certificate must be trusted in order to avoid further modifications to the client.",
+			Usage: "CA hostname (and optionally :port). The server certificate must be trusted in order to avoid further modifications to the client. Presets: le-prod, le-staging, zerossl, buypass, buypass-staging.",
 			Value: lego.LEDirectoryProduction,
 		},
+		cli.BoolFlag{
+			Name:  "server.namespace",
+			Usage: "Store the certificates of each CA server in their own directory (certificates/<server>/).",
+		},
+		cli.StringSliceFlag{
+			Name:  "server.pin",
+			Usage: "Pin the TLS certificate of the CA server, to detect a TLS interception proxy: the SPKI SHA-256 hash (base64, like pin-sha256) of a certificate of its chain, or a PEM file of the expected certificates. Can be specified multiple times.",
+		},
 		cli.BoolFlag{
 			Name:  "accept-tos, a",
 			Usage: "By setting this flag to true you indicate that you accept the current Let's Encrypt terms of service.",
 		},
+		cli.BoolFlag{
+			Name:   "accept-tos-update",
+			EnvVar: "LEGO_ACCEPT_TOS_UPDATE",
+			Usage:  "By setting this flag to true you indicate that you accept the updated terms of service of the CA, when they have changed since the registration of the account.",
+		},
 		cli.StringFlag{
 			Name:  "email, m",
 			Usage: "Email used for registration and recovery contact.",
@@ -40,10 +59,29 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Name:  "hmac",
 			Usage: "MAC key from External CA. Should be in Base64 URL Encoding without padding format. Used for External Account Binding.",
 		},
+		cli.StringFlag{
+			Name:   "account-key-agent",
+			EnvVar: "LEGO_ACCOUNT_KEY_AGENT",
+			Usage:  "The path of the Unix socket of an agent holding the account key (see the 'agent' command). The account key is not loaded by lego.",
+		},
 		cli.StringFlag{
 			Name:  "key-type, k",
 			Value: "ec384",
-			Usage: "Key type to use for private keys. Supported: rsa2048, rsa4096, rsa8192, ec256, ec384.",
+			Usage: "Key type to use for private keys. Supported: rsa2048, rsa3072, rsa4096, rsa8192, ec256, ec384.",
+		},
+		cli.StringFlag{
+			Name:  "account-key-type",
+			Usage: "Key type to use for the account key, when it is generated (e.g. ec256 for the CAs only accepting ES256). By default, the key type of the private keys. Use 'account key-change' to change the key of an existing account.",
+		},
+		cli.BoolFlag{
+			Name:   "debug",
+			EnvVar: "LEGO_DEBUG",
+			Usage:  "Log the debug entries (e.g. the duration of each call of the DNS provider).",
+		},
+		cli.BoolFlag{
+			Name:   "fips",
+			EnvVar: "LEGO_FIPS",
+			Usage:  "Only use the FIPS-approved keys (RSA of at least 2048 bits, ECDSA P-256 and P-384), and require the FIPS module of the Go toolchain to be enabled, if it has one. Enabled by default by the 'fips' build tag.",
 		},
 		cli.StringFlag{
 			Name:  "filename",
@@ -55,6 +93,11 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage:  "Directory to use for storing the data.",
 			Value:  defaultPath,
 		},
+		cli.DurationFlag{
+			Name:  "lock.timeout",
+			Value: 5 * time.Minute,
+			Usage: "The maximum time to wait for another lego process modifying the accounts or the certificates of the same path.",
+		},
 		cli.BoolFlag{
 			Name:  "http",
 			Usage: "Use the HTTP challenge to solve challenges. Can be mixed with other types of challenges.",
@@ -69,6 +112,18 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Usage: "Validate against this HTTP header when solving HTTP based challenges behind a reverse proxy.",
 			Value: "Host",
 		},
+		cli.BoolFlag{
+			Name:  "http.access-log",
+			Usage: "Log each request received by the HTTP-01 challenge server (source IP, path with the token, status, and user agent).",
+		},
+		cli.IntFlag{
+			Name:  "http.rate-limit",
+			Usage: "Limit the number of requests per minute from a source IP to the HTTP-01 challenge server, the other requests are rejected. Disabled if 0.",
+		},
+		cli.StringFlag{
+			Name:  "http.metrics",
+			Usage: "Write the counters of the requests of the HTTP-01 challenge server to this file, in the Prometheus text format.",
+		},
 		cli.StringFlag{
 			Name:  "http.webroot",
 			Usage: "Set the webroot folder to use for HTTP based challenges to write directly in a file in .well-known/acme-challenge. This disables the built-in server and expects the given directory to be publicly served with access to .well-known/acme-challenge",
@@ -77,6 +132,14 @@ func CreateFlags(defaultPath string) []cli.Flag {
 			Name:  "http.memcached-host",
 			Usage: "Set the memcached host(s) to use for HTTP based challenges. Challenges will be written to all specified hosts.",
 		},
+		cli.BoolFlag{
+			Name:  "http.probe",
+			Usage: "Before ordering, check that a test token served by the HTTP challenge provider is reachable from the internet on the port 80 of each domain. Requires --http.probe-url.",
+		},
+		cli.StringFlag{
+			Name:  "http.probe-url",
+			Usage: "The reflection endpoint used by --http.probe and --auto-challenge to fetch the test token from the internet. The endpoint receives the URL to fetch in the 'url' query parameter and responds with its body.",
+		},
 		cli.BoolFlag{
 			Name:  "tls",
 			Usage: "Use the TLS challenge to solve challenges. Can be mixed with other types of challenges.",
@@ -88,33 +151,231 @@ func CreateFlags(defaultPath string) []cli.Flag {
 		},
 		cli.StringFlag{
 			Name:  "dns",
-			Usage: "Solve a DNS challenge using the specified provider. Can be mixed with other types of challenges. Run 'lego dnshelp' for help on usage.",
+			Usage: "Solve a DNS challenge using the specified provider (or an external provider with 'plugin:<path>'). Can be mixed with other types of challenges. Run 'lego dnshelp' for help on usage.",
+		},
+		cli.StringFlag{
+			Name:  "dns.fallback",
+			Usage: "Use this DNS provider when the provider of '--dns' fails to create a record. Credential and permission errors don't trigger the fallback.",
+		},
+		cli.StringSliceFlag{
+			Name:  "dns.delegate",
+			Usage: "A domain whose _acme-challenge name is delegated to a zone managed by the provider of '--dns.delegate-dns'. Supported: '<domain>' (NS delegation of _acme-challenge.<domain>), '<domain>=<target>' (CNAME to _acme-challenge.<target>). Can be specified multiple times.",
+		},
+		cli.StringFlag{
+			Name:  "dns.delegate-dns",
+			Usage: "The DNS provider managing the delegated zones of '--dns.delegate'.",
 		},
 		cli.BoolFlag{
 			Name:  "dns.disable-cp",
 			Usage: "By setting this flag to true, disables the need to wait the propagation of the TXT record to all authoritative name servers.",
 		},
+		cli.BoolFlag{
+			Name:  "dns.check-delegation",
+			Usage: "Before creating the TXT record, check the NS delegation of the zone (parent and child NS records, lame name servers) and log a report.",
+		},
+		cli.BoolFlag{
+			Name:  "dns.verify-cleanup",
+			Usage: "After the cleanup, check that the TXT record is removed from the authoritative name servers, and log a warning if it lingers.",
+		},
+		cli.IntFlag{
+			Name:  "dns.cleanup-retry",
+			Usage: "The number of times the cleanup is retried when the TXT record lingers. Used with --dns.verify-cleanup.",
+		},
+		cli.BoolFlag{
+			Name:  "dns.continue-on-timeout",
+			Usage: "Request the validation even if the propagation check of the TXT record times out: the resolvers of the CA may see the record before the resolvers used by lego.",
+		},
+		cli.BoolFlag{
+			Name:  "dns.auto-timeout",
+			Usage: "Size the propagation timeout from the propagation durations previously observed with the DNS provider (95th percentile with a margin), instead of the timeout of the provider.",
+		},
+		cli.StringFlag{
+			Name:  "dns.metrics",
+			Usage: "Write the timing metrics of the calls of the DNS providers to this file, in the Prometheus text format (e.g. for the textfile collector of the node exporter).",
+		},
+		cli.IntFlag{
+			Name:  "provider.retries",
+			Usage: "Retry the failed calls of the HTTP and DNS providers (e.g. the creation of the TXT record) this number of times, with an exponential backoff starting at --provider.retry-delay.",
+		},
+		cli.DurationFlag{
+			Name:  "provider.retry-delay",
+			Usage: "The delay before the first retry of a failed call of a provider (see --provider.retries), doubled before each of the next ones.",
+			Value: 5 * time.Second,
+		},
+		cli.DurationFlag{
+			Name:  "provider.timeout",
+			Usage: "Fail the calls of the HTTP and DNS providers lasting longer than this duration (e.g. an unresponsive API). Disabled if 0.",
+		},
+		cli.BoolFlag{
+			Name:  "provider.log",
+			Usage: "Log the calls of the HTTP and DNS providers, with their durations and their errors.",
+		},
+		cli.BoolFlag{
+			Name:  "provider.dry-run",
+			Usage: "Log the calls of the HTTP and DNS providers instead of doing them: no record is created nor removed. The challenges can't be validated, to check a configuration only.",
+		},
 		cli.StringSliceFlag{
 			Name:  "dns.resolvers",
 			Usage: "Set the resolvers to use for performing recursive DNS queries. Supported: host:port. The default is to use the system resolvers, or Google's DNS resolvers if the system's cannot be determined.",
 		},
+		cli.StringFlag{
+			Name:  "onion.key",
+			Usage: "Use the ONION-CSR challenge to solve challenges of .onion domains, with the Ed25519 key of the onion service (PEM, PKCS#8). Can be mixed with other types of challenges.",
+		},
+		cli.BoolFlag{
+			Name:  "auto-challenge",
+			Usage: "Choose the challenge of each domain among the enabled ones: DNS for the wildcards, HTTP for the domains whose HTTP challenge is reachable through the reflection endpoint of --http.probe-url, DNS otherwise, then TLS. Requires --http.probe-url, and at least two of --http, --tls and --dns.",
+		},
+		cli.StringSliceFlag{
+			Name:  "challenge.order",
+			Usage: "The order of preference of the enabled challenges, for all the domains (default: tls, http, dns). Supported: http, tls, dns. Can be specified multiple times.",
+		},
+		cli.StringFlag{
+			Name:  "challenge-hook",
+			Usage: "Run this command at each lifecycle event of the challenges (presented, propagated, validation-started, validated, failed, cleaned). The event is passed in the LEGO_CHALLENGE_* environment variables.",
+		},
+		cli.StringFlag{
+			Name:  "challenge-agent",
+			Usage: "Delegate the HTTP (--http) and DNS (--dns) challenges to a remote agent (see the 'challenge-agent' command). Supported: host:port. The providers are configured on the agent.",
+		},
+		cli.StringFlag{
+			Name:  "challenge-agent.cert",
+			Usage: "The path of the client certificate (PEM) presented to the challenge agent.",
+		},
+		cli.StringFlag{
+			Name:  "challenge-agent.key",
+			Usage: "The path of the private key (PEM) of the client certificate presented to the challenge agent.",
+		},
+		cli.StringFlag{
+			Name:  "challenge-agent.ca",
+			Usage: "The path of the CA certificate (PEM) verifying the certificate of the challenge agent.",
+		},
 		cli.IntFlag{
 			Name:  "http-timeout",
 			Usage: "Set the HTTP timeout value to a specific value in seconds.",
 		},
 		cli.IntFlag{
-			Name:  "dns-timeout",
-			Usage: "Set the DNS timeout value to a specific value in seconds. Used only when performing authoritative name servers queries.",
-			Value: 10,
+			Name:   "dns-timeout",
+			Usage:  "Set the DNS timeout value to a specific value in seconds. Used only when performing authoritative name servers queries.",
+			EnvVar: "LEGO_DNS_TIMEOUT",
+			Value:  10,
+		},
+		cli.IntFlag{
+			Name:   "dns.retries",
+			Usage:  "The number of times a DNS query is retried on a name server after a network error (e.g. a lost packet).",
+			EnvVar: "LEGO_DNS_RETRIES",
+		},
+		cli.StringFlag{
+			Name:   "dns.tcp",
+			Usage:  "The use of TCP by the DNS queries. Supported: 'truncated' (retry over TCP when the response is truncated), 'error' (also when the UDP query fails), 'always', 'never'.",
+			EnvVar: "LEGO_DNS_TCP",
+			Value:  "truncated",
+		},
+		cli.IntFlag{
+			Name:   "dns.edns-size",
+			Usage:  "The UDP buffer size advertised with EDNS0 by the DNS queries, 0 disables EDNS0. 1232 avoids the IP fragmentation dropped by some middleboxes.",
+			EnvVar: "LEGO_DNS_EDNS_SIZE",
+			Value:  4096,
 		},
 		cli.BoolFlag{
 			Name:  "pem",
 			Usage: "Generate a .pem file by concatenating the .key and .crt files together.",
 		},
+		cli.BoolFlag{
+			Name:  "chain.strip-root",
+			Usage: "Remove the root certificate (self-signed) from the chain in the .crt and .issuer.crt files, e.g. for the appliances rejecting the chains containing the root.",
+		},
+		cli.BoolFlag{
+			Name:  "chain.leaf-only",
+			Usage: "Only write the leaf certificate: the .crt file doesn't contain the chain, and no .issuer.crt file is written.",
+		},
+		cli.BoolFlag{
+			Name:  "chain.leaf-first",
+			Usage: "Order the chain in the .crt and .issuer.crt files from the leaf to the root, each certificate followed by its issuer.",
+		},
+		cli.StringSliceFlag{
+			Name:  "snippet",
+			Usage: "Generate a configuration snippet (<domain>.<name>.conf) for the certificate. Supported: 'nginx', 'apache', or '<name>=<template file>'. Can be specified multiple times.",
+		},
+		cli.StringFlag{
+			Name:  "storage.caddy",
+			Usage: "Also write the certificates to a Caddy storage directory (e.g. ~/.local/share/caddy).",
+		},
+		cli.StringFlag{
+			Name:  "storage.traefik",
+			Usage: "Also write the certificates to a Traefik ACME file (acme.json).",
+		},
+		cli.StringFlag{
+			Name:  "traefik.resolver",
+			Usage: "The name of the Traefik certificate resolver of the certificates written to the Traefik ACME file.",
+			Value: "lego",
+		},
+		cli.StringFlag{
+			Name:  "tlsa",
+			Usage: "Write the TLSA records (<domain>.tlsa) and the SPKI pin (<domain>.pin) of the certificate. The value is the 'usage selector matching-type' of the records (e.g. '3 1 1').",
+		},
+		cli.IntFlag{
+			Name:  "tlsa.port",
+			Usage: "The TCP port of the TLSA records.",
+			Value: 443,
+		},
+		cli.BoolFlag{
+			Name:  "tlsa.publish",
+			Usage: "Publish the TLSA records with the DNS provider (--dns), replacing the previous ones.",
+		},
+		cli.StringSliceFlag{
+			Name:  "inventory.url",
+			Usage: "After every issuance, POST the metadata of the certificate (domains, serial, notAfter, fingerprint) in JSON to this endpoint. Can be specified multiple times.",
+		},
+		cli.StringSliceFlag{
+			Name:  "inventory.header",
+			Usage: "Add a header to the requests sent to the inventory endpoints. Supported: 'Name: value'. Can be specified multiple times.",
+		},
+		cli.StringFlag{
+			Name:  "inventory.template",
+			Usage: "Send the body rendered by this Go template file to the inventory endpoints, instead of the JSON metadata. The template can reference the metadata of the certificate (e.g. {{.Domain}}, {{.NotAfter}}, {{.CertPath}}). The Content-Type can be set with --inventory.header.",
+		},
+		cli.DurationFlag{
+			Name:  "clock-skew.warn",
+			Usage: "Warn when the local clock differs from the clock of the CA (Date header of its responses) by more than this duration. Disabled if 0.",
+			Value: 30 * time.Second,
+		},
+		cli.BoolFlag{
+			Name:  "clock-skew.correct",
+			Usage: "When the clock skew exceeds --clock-skew.warn, use the clock of the CA instead of the local clock for the scheduling decisions (renewal checks, renewal windows, backoffs).",
+		},
+		cli.DurationFlag{
+			Name:  "maintenance.wait",
+			Usage: "When the CA is unavailable (503, e.g. during a maintenance), wait and retry for at most this duration instead of failing. The delay between two attempts is the Retry-After of the CA.",
+		},
+		cli.DurationFlag{
+			Name:  "directory.ttl",
+			Usage: "Cache the directory of the CA in the storage and reuse it for this duration (or the max-age of the CA if longer), then revalidate it. By default the directory is fetched by every run.",
+		},
 		cli.IntFlag{
 			Name:  "cert.timeout",
 			Usage: "Set the certificate timeout value to a specific value in seconds. Only used when obtaining certificates.",
 			Value: 30,
 		},
+		cli.StringSliceFlag{
+			Name:  "policy.allow",
+			Usage: "Only obtain certificates for the domains matching one of these patterns: a glob (e.g. '*.example.com') or a regular expression ('regexp:<expression>'). Can be specified multiple times.",
+		},
+		cli.StringSliceFlag{
+			Name:  "policy.deny",
+			Usage: "Never obtain certificates for the domains matching one of these patterns (glob or 'regexp:<expression>'), even if they are allowed. Can be specified multiple times.",
+		},
+		cli.IntFlag{
+			Name:  "policy.max-sans",
+			Usage: "The maximum number of domains in a certificate. No limit if 0.",
+		},
+		cli.StringSliceFlag{
+			Name:  "policy.key-types",
+			Usage: "The allowed key types of the certificates (rsa2048, rsa3072, rsa4096, rsa8192, ec256, ec384). Can be specified multiple times.",
+		},
+		cli.StringSliceFlag{
+			Name:  "policy.min-key-types",
+			Usage: "The minimum key type of each algorithm (e.g. rsa3072, ec384): the weaker keys are denied, and renew replaces the weaker keys of the certificates, even if they are not due for renewal. Can be specified multiple times.",
+		},
 	}
 }
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+)
+
+// hookData the metadata of a certificate available to the templates of the hooks and of the notification bodies,
+// e.g. {{.Domain}}, {{.NotAfter.Format "2006-01-02"}}, {{.CertPath}}, or {{json .}}.
+type hookData struct {
+	Event       string
+	Domain      string
+	Domains     []string
+	Serial      string
+	Issuer      string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	Fingerprint string
+	CertURL     string
+
+	// CertPath, KeyPath and IssuerPath the files of the certificate in the storage.
+	CertPath   string
+	KeyPath    string
+	IssuerPath string
+
+	// Server and Hostname the CA server and the host running lego (notification bodies).
+	Server   string
+	Hostname string
+
+	// Failures and Error the consecutive failures of the renewal (circuit breaker hook).
+	Failures int
+	Error    string
+}
+
+// newHookData builds the data of the templates from a certificate.
+// Only the domain and the paths are set if the certificate can't be parsed.
+func newHookData(certsStorage *CertificatesStorage, certRes *certificate.Resource) *hookData {
+	data := &hookData{
+		Domain:  certRes.Domain,
+		Serial:  certRes.SerialNumber,
+		CertURL: certRes.CertURL,
+	}
+
+	pemCert := certRes.Certificate
+
+	if certsStorage != nil {
+		base := filepath.Join(certsStorage.GetRootPath(), sanitizedDomain(certRes.Domain))
+		data.CertPath = base + ".crt"
+		data.KeyPath = base + ".key"
+		data.IssuerPath = base + ".issuer.crt"
+
+		if len(pemCert) == 0 {
+			pemCert, _ = certsStorage.ReadFile(certRes.Domain, ".crt")
+		}
+	}
+
+	cert, err := certcrypto.ParsePEMCertificate(pemCert)
+	if err != nil {
+		return data
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	data.Domains = certcrypto.ExtractDomains(cert)
+	data.Serial = cert.SerialNumber.Text(16)
+	data.Issuer = cert.Issuer.String()
+	data.NotBefore = cert.NotBefore.UTC()
+	data.NotAfter = cert.NotAfter.UTC()
+	data.Fingerprint = hex.EncodeToString(fingerprint[:])
+
+	return data
+}
+
+// isTemplate returns true if the value contains template actions.
+func isTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// renderTemplate renders a template of a hook or of a notification body.
+// A reference to an unknown field is an error.
+func renderTemplate(name, text string, data *hookData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			raw, err := json.Marshal(v)
+			return string(raw), err
+		},
+		"join": strings.Join,
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// renderHook renders the command of a hook, if it is a template.
+// The command is split on the white spaces (outside the template actions) before rendering each argument,
+// so a value containing spaces stays in a single argument. An argument rendered as empty is dropped.
+func renderHook(name, hook string, data *hookData) ([]string, error) {
+	var parts []string
+
+	for _, field := range splitHookFields(hook) {
+		if isTemplate(field) {
+			var err error
+			field, err = renderTemplate(name, field, data)
+			if err != nil {
+				return nil, err
+			}
+
+			if field == "" {
+				continue
+			}
+		}
+
+		parts = append(parts, field)
+	}
+
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty %s", name)
+	}
+
+	return parts, nil
+}
+
+// splitHookFields splits a command on the white spaces, except inside the template actions ({{...}}).
+func splitHookFields(hook string) []string {
+	var fields []string
+	var field strings.Builder
+
+	inAction := false
+
+	for i := 0; i < len(hook); i++ {
+		switch {
+		case !inAction && strings.HasPrefix(hook[i:], "{{"):
+			inAction = true
+			field.WriteString("{{")
+			i++
+		case inAction && strings.HasPrefix(hook[i:], "}}"):
+			inAction = false
+			field.WriteString("}}")
+			i++
+		case !inAction && unicode.IsSpace(rune(hook[i])):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteByte(hook[i])
+		}
+	}
+
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
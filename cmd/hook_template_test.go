@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newHookData(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-hook")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	certPEM, err := certcrypto.GeneratePemCert(privateKey, "example.com", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, certsStorage.WriteFile("*.example.com", ".crt", certPEM))
+
+	// the certificate is read from the storage.
+	data := newHookData(certsStorage, &certificate.Resource{Domain: "*.example.com"})
+
+	assert.Equal(t, "*.example.com", data.Domain)
+	assert.Equal(t, []string{"ACME Challenge TEMP", "example.com"}, data.Domains)
+	assert.Equal(t, filepath.Join(dir, "_.example.com.crt"), data.CertPath)
+	assert.Equal(t, filepath.Join(dir, "_.example.com.key"), data.KeyPath)
+	assert.NotEmpty(t, data.Serial)
+	assert.Len(t, data.Fingerprint, 64)
+	assert.False(t, data.NotAfter.IsZero())
+
+	// no certificate.
+	data = newHookData(nil, &certificate.Resource{Domain: "example.org", SerialNumber: "01"})
+
+	assert.Equal(t, "example.org", data.Domain)
+	assert.Equal(t, "01", data.Serial)
+	assert.Empty(t, data.CertPath)
+}
+
+func Test_renderHook(t *testing.T) {
+	data := &hookData{
+		Domain:   "example.com",
+		Domains:  []string{"example.com", "www.example.com"},
+		Issuer:   "CN=Test CA, O=Test",
+		NotAfter: time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC),
+		CertPath: "/certs/example.com.crt",
+	}
+
+	testCases := []struct {
+		desc     string
+		hook     string
+		expected []string
+		err      string
+	}{
+		{
+			desc:     "not a template",
+			hook:     "systemctl reload nginx",
+			expected: []string{"systemctl", "reload", "nginx"},
+		},
+		{
+			desc:     "fields",
+			hook:     "deploy --domain {{.Domain}} --cert {{.CertPath}}",
+			expected: []string{"deploy", "--domain", "example.com", "--cert", "/certs/example.com.crt"},
+		},
+		{
+			desc:     "values with spaces",
+			hook:     `notify --issuer {{.Issuer}} --expiry {{.NotAfter.Format "2006-01-02 15:04"}}`,
+			expected: []string{"notify", "--issuer", "CN=Test CA, O=Test", "--expiry", "2020-03-04 05:06"},
+		},
+		{
+			desc:     "empty argument",
+			hook:     "deploy {{.Serial}} --cert {{.CertPath}}",
+			expected: []string{"deploy", "--cert", "/certs/example.com.crt"},
+		},
+		{
+			desc:     "functions",
+			hook:     `notify {{join .Domains ","}} {{json .Domain}}`,
+			expected: []string{"notify", "example.com,www.example.com", `"example.com"`},
+		},
+		{
+			desc: "unknown field",
+			hook: "deploy {{.Unknown}}",
+			err:  "template renew-hook",
+		},
+		{
+			desc: "invalid template",
+			hook: "deploy {{.Domain",
+			err:  "invalid template renew-hook",
+		},
+		{
+			desc: "empty",
+			hook: "{{if false}}deploy{{end}}",
+			err:  "empty renew-hook",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			parts, err := renderHook("renew-hook", test.hook, data)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, parts)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// Inventory events.
+const (
+	inventoryEventObtain = "obtain"
+	inventoryEventRenew  = "renew"
+)
+
+// inventoryReport the metadata of a certificate, sent to the inventory endpoints after every issuance.
+type inventoryReport struct {
+	Event       string    `json:"event"`
+	Domain      string    `json:"domain"`
+	Domains     []string  `json:"domains"`
+	Serial      string    `json:"serial"`
+	Issuer      string    `json:"issuer"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+	Fingerprint string    `json:"fingerprint"`
+	CertURL     string    `json:"certUrl,omitempty"`
+	Server      string    `json:"server"`
+	Hostname    string    `json:"hostname,omitempty"`
+}
+
+// newInventoryReport builds the report of a certificate.
+func newInventoryReport(event string, certRes *certificate.Resource) (*inventoryReport, error) {
+	cert, err := certcrypto.ParsePEMCertificate(certRes.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	return &inventoryReport{
+		Event:       event,
+		Domain:      certRes.Domain,
+		Domains:     certcrypto.ExtractDomains(cert),
+		Serial:      cert.SerialNumber.Text(16),
+		Issuer:      cert.Issuer.String(),
+		NotBefore:   cert.NotBefore.UTC(),
+		NotAfter:    cert.NotAfter.UTC(),
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+		CertURL:     certRes.CertURL,
+	}, nil
+}
+
+// reportInventory sends the metadata of a new certificate to the inventory endpoints (--inventory.url).
+// The certificate is already saved: a failure is only logged.
+func reportInventory(ctx *cli.Context, event string, certRes *certificate.Resource) {
+	endpoints := ctx.GlobalStringSlice("inventory.url")
+	if len(endpoints) == 0 {
+		return
+	}
+
+	report, err := newInventoryReport(event, certRes)
+	if err != nil {
+		log.Warnf("[%s] inventory: %v", certRes.Domain, err)
+		return
+	}
+
+	report.Server = ctx.GlobalString("server")
+	report.Hostname, _ = os.Hostname()
+
+	headers, err := parseInventoryHeaders(ctx.GlobalStringSlice("inventory.header"))
+	if err != nil {
+		log.Warnf("[%s] inventory: %v", certRes.Domain, err)
+		return
+	}
+
+	body, err := inventoryBody(ctx, event, certRes, report)
+	if err != nil {
+		log.Warnf("[%s] inventory: %v", certRes.Domain, err)
+		return
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	for _, endpoint := range endpoints {
+		err = postInventoryReport(client, endpoint, headers, body)
+		if err != nil {
+			log.Warnf("[%s] inventory: %s: %v", certRes.Domain, endpoint, err)
+			continue
+		}
+
+		log.Infof("[%s] inventory: reported to %s", certRes.Domain, endpoint)
+	}
+}
+
+// inventoryBody returns the body sent to the inventory endpoints:
+// the report in JSON, or the rendered template of the body (--inventory.template).
+func inventoryBody(ctx *cli.Context, event string, certRes *certificate.Resource, report *inventoryReport) ([]byte, error) {
+	file := ctx.GlobalString("inventory.template")
+	if file == "" {
+		return json.Marshal(report)
+	}
+
+	text, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	data := newHookData(NewCertificatesStorage(ctx), certRes)
+	data.Event = event
+	data.Server = report.Server
+	data.Hostname = report.Hostname
+
+	body, err := renderTemplate(filepath.Base(file), string(text), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(body), nil
+}
+
+// postInventoryReport sends a body to an inventory endpoint.
+// The body is in JSON, unless the headers contain another Content-Type.
+func postInventoryReport(client *http.Client, endpoint string, headers http.Header, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		raw, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	return nil
+}
+
+// parseInventoryHeaders parses the headers sent to the inventory endpoints ("Name: value").
+func parseInventoryHeaders(values []string) (http.Header, error) {
+	headers := http.Header{}
+
+	for _, value := range values {
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid header %q: expected 'Name: value'", value)
+		}
+
+		headers.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func Test_reportInventory(t *testing.T) {
+	reports := make(chan inventoryReport, 2)
+
+	handler := func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var report inventoryReport
+		if err := json.NewDecoder(req.Body).Decode(&report); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		reports <- report
+	}
+
+	server1 := httptest.NewServer(http.HandlerFunc(handler))
+	defer server1.Close()
+
+	server2 := httptest.NewServer(http.HandlerFunc(handler))
+	defer server2.Close()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	certPEM, err := certcrypto.GeneratePemCert(privateKey, "example.com", nil)
+	require.NoError(t, err)
+
+	certRes := &certificate.Resource{Domain: "example.com", Certificate: certPEM}
+
+	runWithFlags(t, []string{
+		"--inventory.url", server1.URL, "--inventory.url", server2.URL,
+		"--inventory.header", "Authorization: Bearer secret",
+		"renew",
+	}, func(ctx *cli.Context) {
+		reportInventory(ctx, inventoryEventRenew, certRes)
+	})
+
+	require.Len(t, reports, 2)
+
+	report := <-reports
+
+	assert.Equal(t, inventoryEventRenew, report.Event)
+	assert.Equal(t, "example.com", report.Domain)
+	assert.Equal(t, []string{"ACME Challenge TEMP", "example.com"}, report.Domains)
+	assert.NotEmpty(t, report.Serial)
+	assert.Len(t, report.Fingerprint, 64)
+	assert.False(t, report.NotAfter.IsZero())
+}
+
+func Test_reportInventory_template(t *testing.T) {
+	bodies := make(chan string, 1)
+	contentTypes := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		raw, _ := ioutil.ReadAll(req.Body)
+		bodies <- string(raw)
+		contentTypes <- req.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "lego-inventory")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "body.tmpl")
+	err = ioutil.WriteFile(file, []byte(`{"text": "{{.Event}}: {{.Domain}} expires on {{.NotAfter.Format "2006-01-02"}}"}`), 0600)
+	require.NoError(t, err)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	certPEM, err := certcrypto.GeneratePemCert(privateKey, "example.com", nil)
+	require.NoError(t, err)
+
+	certRes := &certificate.Resource{Domain: "example.com", Certificate: certPEM}
+
+	cert, err := certcrypto.ParsePEMCertificate(certPEM)
+	require.NoError(t, err)
+
+	runWithFlags(t, []string{
+		"--path", dir,
+		"--inventory.url", server.URL,
+		"--inventory.template", file,
+		"--inventory.header", "Content-Type: text/plain",
+		"renew",
+	}, func(ctx *cli.Context) {
+		reportInventory(ctx, inventoryEventRenew, certRes)
+	})
+
+	require.Len(t, bodies, 1)
+
+	assert.Equal(t, `{"text": "renew: example.com expires on `+cert.NotAfter.UTC().Format("2006-01-02")+`"}`, <-bodies)
+	assert.Equal(t, "text/plain", <-contentTypes)
+}
+
+func Test_parseInventoryHeaders(t *testing.T) {
+	headers, err := parseInventoryHeaders([]string{"Authorization: Bearer a:b", "X-Team:  ops "})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer a:b", headers.Get("Authorization"))
+	assert.Equal(t, "ops", headers.Get("X-Team"))
+
+	_, err = parseInventoryHeaders([]string{"no-colon"})
+	require.Error(t, err)
+}
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// envDaemonJournal the path of the journal of the daemon, passed to the renew commands run by the daemon.
+const envDaemonJournal = "LEGO_DAEMON_JOURNAL"
+
+const (
+	// journalLockSuffix the suffix of the lock file of the journal.
+	journalLockSuffix = ".lock"
+	// journalLockTimeout the maximum time to wait for the lock of the journal.
+	journalLockTimeout = 30 * time.Second
+)
+
+// journalEntryType the type of a journal entry.
+type journalEntryType string
+
+// Types of the journal entries.
+const (
+	// journalScheduled the next periodic check of the certificates.
+	journalScheduled journalEntryType = "scheduled"
+	// journalOrderStarted a renewal is ordered.
+	journalOrderStarted journalEntryType = "order-started"
+	// journalOrderFailed the order of a renewal failed.
+	journalOrderFailed journalEntryType = "order-failed"
+	// journalCertificateSaved the renewed certificate is saved, its hook has not been started yet.
+	journalCertificateSaved journalEntryType = "certificate-saved"
+	// journalHookStarted the hook of a certificate is started.
+	journalHookStarted journalEntryType = "hook-started"
+	// journalHookDone the hook of a certificate is done (successfully or not).
+	journalHookDone journalEntryType = "hook-done"
+	// journalChecked the renewal check of a certificate is completed.
+	journalChecked journalEntryType = "checked"
+)
+
+// journalEntry an entry of the journal, one JSON object by line.
+type journalEntry struct {
+	Time   time.Time        `json:"time"`
+	Type   journalEntryType `json:"type"`
+	Domain string           `json:"domain,omitempty"`
+	// Serial the serial number of the renewed certificate.
+	Serial string `json:"serial,omitempty"`
+	// At the time of the next check (scheduled).
+	At    time.Time `json:"at,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// journal a write-ahead journal of the daemon: each entry is written (and synced) before the action it describes,
+// so the daemon resumes the interrupted renewals and hooks after a crash.
+// A nil journal records nothing.
+type journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// openJournal returns the journal stored in the file, nil if the path is empty.
+func openJournal(path string) (*journal, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	err := createNonExistingFolder(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return &journal{path: path}, nil
+}
+
+// journalFromEnv returns the journal of the daemon running the current command, nil if there is none.
+func journalFromEnv() *journal {
+	j, err := openJournal(os.Getenv(envDaemonJournal))
+	if err != nil {
+		log.Warnf("daemon: journal: %v", err)
+		return nil
+	}
+	return j
+}
+
+// record appends an entry to the journal.
+// The failures are only logged: the journal must not prevent the renewals.
+func (j *journal) record(entry journalEntry) {
+	if j == nil {
+		return
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = clk.Now().UTC()
+	}
+
+	err := j.append(entry)
+	if err != nil {
+		log.Warnf("daemon: journal: could not record %s of %s: %v", entry.Type, entry.Domain, err)
+	}
+}
+
+func (j *journal) append(entries ...journalEntry) error {
+	unlock, err := j.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return appendJournalEntries(j.path, entries)
+}
+
+// lock takes the lock of the journal, shared with the renew commands run by the daemon (appending to the same file),
+// and returns the function releasing it.
+func (j *journal) lock() (func(), error) {
+	j.mu.Lock()
+
+	l := &storageLock{path: j.path + journalLockSuffix, timeout: journalLockTimeout}
+
+	file, err := l.lock()
+	if err != nil {
+		j.mu.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		l.unlock(file)
+		j.mu.Unlock()
+	}, nil
+}
+
+func appendJournalEntries(path string, entries []journalEntry) error {
+	buf := &bytes.Buffer{}
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePerm)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(buf.Bytes())
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	err = file.Sync()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// read returns the entries of the journal.
+// A truncated last line (a crash during a write) is ignored.
+func (j *journal) read() ([]journalEntry, error) {
+	raw, err := ioutil.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []journalEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Warnf("daemon: journal: ignoring the invalid line %d of %s: %v", line, j.path, err)
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// compact replaces the journal with the entries needed to rebuild its state.
+// The lock is held from the read to the rename, so an entry appended meanwhile (e.g. by a renew command) is not lost.
+func (j *journal) compact() error {
+	unlock, err := j.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		return err
+	}
+
+	tmp := j.path + ".tmp"
+
+	err = os.Remove(tmp)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	err = appendJournalEntries(tmp, replayJournal(entries).entries())
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, j.path)
+}
+
+// journalState the state of the daemon replayed from the journal.
+type journalState struct {
+	// next the time of the next periodic check, zero if unknown.
+	next time.Time
+	// domains the last entry of each domain.
+	domains map[string]journalEntry
+	// hooksDone the serial numbers of the certificates whose hook has been started, by domain.
+	hooksDone map[string]map[string]bool
+}
+
+func replayJournal(entries []journalEntry) journalState {
+	state := journalState{
+		domains:   make(map[string]journalEntry),
+		hooksDone: make(map[string]map[string]bool),
+	}
+
+	for _, entry := range entries {
+		if entry.Type == journalScheduled {
+			state.next = entry.At
+			continue
+		}
+
+		if entry.Type == journalHookStarted || entry.Type == journalHookDone {
+			if state.hooksDone[entry.Domain] == nil {
+				state.hooksDone[entry.Domain] = make(map[string]bool)
+			}
+			state.hooksDone[entry.Domain][entry.Serial] = true
+		}
+
+		state.domains[entry.Domain] = entry
+	}
+
+	return state
+}
+
+// entries returns the entries needed to rebuild the state.
+func (s journalState) entries() []journalEntry {
+	var entries []journalEntry
+
+	if !s.next.IsZero() {
+		entries = append(entries, journalEntry{Time: clk.Now().UTC(), Type: journalScheduled, At: s.next})
+	}
+
+	domains := make([]string, 0, len(s.domains))
+	for domain := range s.domains {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	for _, domain := range domains {
+		entries = append(entries, s.domains[domain])
+	}
+
+	return entries
+}
+
+// recover resumes the work interrupted by a crash, from the journal:
+// the hooks of the saved certificates are run (once), and the interrupted renewals are checked again.
+// It returns the delay before the next periodic check.
+func (d *daemonJobs) recover(j *journal) (time.Duration, error) {
+	entries, err := j.read()
+	if err != nil {
+		return 0, fmt.Errorf("could not read the journal: %w", err)
+	}
+
+	state := replayJournal(entries)
+
+	var interrupted []string
+
+	for _, domain := range d.domains() {
+		entry, ok := state.domains[domain]
+		if !ok {
+			continue
+		}
+
+		switch entry.Type {
+		case journalOrderStarted:
+			log.Infof("daemon: [%s] the renewal was interrupted, checking it again", domain)
+			interrupted = append(interrupted, domain)
+
+		case journalCertificateSaved:
+			if state.hooksDone[domain][entry.Serial] {
+				continue
+			}
+
+			log.Infof("daemon: [%s] the certificate %s was saved, but its hook was not run: running it", domain, entry.Serial)
+			d.runPendingHook(j, domain, entry.Serial)
+
+		case journalHookStarted:
+			log.Warnf("daemon: [%s] the hook of the certificate %s was interrupted, it is not run again: check the deployment", domain, entry.Serial)
+			j.record(journalEntry{Type: journalHookDone, Domain: domain, Serial: entry.Serial, Error: "interrupted"})
+		}
+	}
+
+	err = j.compact()
+	if err != nil {
+		return 0, fmt.Errorf("could not compact the journal: %w", err)
+	}
+
+	if len(interrupted) > 0 {
+		d.run(interrupted...)
+	}
+
+	if delay := state.next.Sub(clk.Now()); delay > 0 {
+		log.Infof("daemon: resuming the schedule, the next check is at %s", state.next.Format(time.RFC3339))
+		return delay, nil
+	}
+
+	return 0, nil
+}
+
+// runPendingHook runs the renew hook of a certificate saved before a crash.
+func (d *daemonJobs) runPendingHook(j *journal, domain, serial string) {
+	d.mu.Lock()
+	args := d.jobs[domain]
+	d.mu.Unlock()
+
+	hook := flagValue(args, "renew-hook")
+	if hook == "" {
+		j.record(journalEntry{Type: journalHookDone, Domain: domain, Serial: serial})
+		return
+	}
+
+	data := newHookData(d.certsStorage, &certificate.Resource{Domain: domain, SerialNumber: serial})
+	data.Event = inventoryEventRenew
+
+	err := runJournaledHook(j, hook, domain, serial, data)
+	if err != nil {
+		log.Warnf("daemon: [%s] the renew hook failed: %v", domain, err)
+	}
+}
+
+// runJournaledHook runs a renew hook at most once: the start of the hook is recorded before running it.
+func runJournaledHook(j *journal, hook, domain, serial string, data *hookData) error {
+	j.record(journalEntry{Type: journalHookStarted, Domain: domain, Serial: serial})
+
+	err := runRenewHook(hook, data)
+
+	entry := journalEntry{Type: journalHookDone, Domain: domain, Serial: serial}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	j.record(entry)
+
+	return err
+}
+
+// flagValue returns the last value of a flag in command line arguments, empty if the flag is not set.
+func flagValue(args []string, name string) string {
+	var value string
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == "--"+name {
+			value = args[i+1]
+			i++
+		}
+	}
+	return value
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJournal(t *testing.T) (string, *journal) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lego-journal")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	j, err := openJournal(filepath.Join(dir, "daemon", "journal.jsonl"))
+	require.NoError(t, err)
+
+	return dir, j
+}
+
+func Test_journal_read(t *testing.T) {
+	_, j := newTestJournal(t)
+
+	entries, err := j.read()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	j.record(journalEntry{Type: journalOrderStarted, Domain: "example.com"})
+	j.record(journalEntry{Type: journalCertificateSaved, Domain: "example.com", Serial: "01"})
+
+	// a crash during a write.
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, filePerm)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"time":"2020-03-08T10:00:00Z","type":"hook-st`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	entries, err = j.read()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, journalOrderStarted, entries[0].Type)
+	assert.Equal(t, journalCertificateSaved, entries[1].Type)
+	assert.Equal(t, "01", entries[1].Serial)
+	assert.False(t, entries[1].Time.IsZero())
+}
+
+func Test_journal_compact(t *testing.T) {
+	_, j := newTestJournal(t)
+
+	// the journal of a renew command run by the daemon: another process, sharing only the file.
+	renewJournal, err := openJournal(j.path)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			renewJournal.record(journalEntry{Type: journalChecked, Domain: fmt.Sprintf("%d.example.com", i)})
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, j.compact())
+	}
+
+	wg.Wait()
+
+	require.NoError(t, j.compact())
+
+	entries, err := j.read()
+	require.NoError(t, err)
+	assert.Len(t, entries, 50)
+}
+
+func Test_replayJournal(t *testing.T) {
+	next := time.Date(2020, time.March, 8, 22, 0, 0, 0, time.UTC)
+
+	state := replayJournal([]journalEntry{
+		{Type: journalScheduled, At: next.Add(-12 * time.Hour)},
+		{Type: journalOrderStarted, Domain: "example.com"},
+		{Type: journalCertificateSaved, Domain: "example.com", Serial: "01"},
+		{Type: journalHookStarted, Domain: "example.com", Serial: "01"},
+		{Type: journalHookDone, Domain: "example.com", Serial: "01"},
+		{Type: journalOrderStarted, Domain: "example.org"},
+		{Type: journalScheduled, At: next},
+	})
+
+	assert.Equal(t, next, state.next)
+	assert.Equal(t, journalHookDone, state.domains["example.com"].Type)
+	assert.Equal(t, journalOrderStarted, state.domains["example.org"].Type)
+	assert.True(t, state.hooksDone["example.com"]["01"])
+
+	entries := state.entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, journalScheduled, entries[0].Type)
+	assert.Equal(t, "example.com", entries[1].Domain)
+	assert.Equal(t, "example.org", entries[2].Domain)
+}
+
+func Test_daemonJobs_recover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook uses touch")
+	}
+
+	dir, j := newTestJournal(t)
+
+	hookFile := filepath.Join(dir, "hook")
+
+	var renewed []string
+	jobs := &daemonJobs{
+		jobs: map[string][]string{
+			"example.com": {"--domains", "example.com", "renew", "--renew-hook", "touch " + hookFile},
+			"example.net": {"--domains", "example.net", "renew", "--renew-hook", "touch " + hookFile},
+			"example.org": {"--domains", "example.org", "renew"},
+		},
+		renew: func(args []string) error {
+			renewed = append(renewed, args[1])
+			return nil
+		},
+		journal: j,
+	}
+
+	next := clk.Now().Add(time.Hour).UTC()
+
+	j.record(journalEntry{Type: journalScheduled, At: next})
+	// the certificate is saved, the hook must be run.
+	j.record(journalEntry{Type: journalOrderStarted, Domain: "example.com"})
+	j.record(journalEntry{Type: journalCertificateSaved, Domain: "example.com", Serial: "01"})
+	// the hook was interrupted, it must not be run again.
+	j.record(journalEntry{Type: journalCertificateSaved, Domain: "example.net", Serial: "02"})
+	j.record(journalEntry{Type: journalHookStarted, Domain: "example.net", Serial: "02"})
+	// the renewal was interrupted.
+	j.record(journalEntry{Type: journalOrderStarted, Domain: "example.org"})
+
+	delay, err := jobs.recover(j)
+	require.NoError(t, err)
+
+	assert.True(t, delay > 0 && delay <= time.Hour, delay)
+	assert.Equal(t, []string{"example.org"}, renewed)
+	assert.FileExists(t, hookFile)
+
+	entries, err := j.read()
+	require.NoError(t, err)
+
+	state := replayJournal(entries)
+	assert.Equal(t, next.Unix(), state.next.Unix())
+	assert.Equal(t, journalHookDone, state.domains["example.com"].Type)
+	assert.Equal(t, journalChecked, state.domains["example.org"].Type)
+	assert.Equal(t, journalEntry{Time: state.domains["example.net"].Time, Type: journalHookDone, Domain: "example.net", Serial: "02", Error: "interrupted"},
+		state.domains["example.net"])
+
+	// the hooks are not run twice.
+	require.NoError(t, os.Remove(hookFile))
+
+	_, err = jobs.recover(j)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.org"}, renewed)
+
+	_, err = os.Stat(hookFile)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_flagValue(t *testing.T) {
+	args := []string{"--domains", "example.com", "renew", "--renew-hook", "a", "--days", "30", "--renew-hook", "b"}
+
+	assert.Equal(t, "b", flagValue(args, "renew-hook"))
+	assert.Equal(t, "30", flagValue(args, "days"))
+	assert.Equal(t, "", flagValue(args, "reuse-key"))
+}
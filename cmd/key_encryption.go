@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Environment variables holding the passphrase of the account keys.
+const (
+	envAccountPassphrase     = "LEGO_ACCOUNT_PASSPHRASE"
+	envAccountPassphraseFile = "LEGO_ACCOUNT_PASSPHRASE_FILE"
+)
+
+const encryptedKeyBlockType = "LEGO ENCRYPTED PRIVATE KEY"
+
+// scrypt parameters recommended for interactive logins.
+const (
+	scryptN      = 32768
+	scryptR      = 8
+	scryptP      = 1
+	scryptParams = "scrypt-32768-8-1"
+)
+
+// getAccountPassphrase returns the passphrase of the account keys, nil if the keys are not encrypted.
+func getAccountPassphrase() ([]byte, error) {
+	if filename := os.Getenv(envAccountPassphraseFile); filename != "" {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the passphrase file: %w", err)
+		}
+
+		passphrase := strings.TrimRight(string(content), "\r\n")
+		if passphrase == "" {
+			return nil, fmt.Errorf("the passphrase file %s is empty", filename)
+		}
+		return []byte(passphrase), nil
+	}
+
+	if passphrase := os.Getenv(envAccountPassphrase); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	return nil, nil
+}
+
+// encryptPEMBlock encrypts a PEM block with a key derived from the passphrase (scrypt, AES-256-GCM).
+// The type of the resulting block is blockType.
+func encryptPEMBlock(block *pem.Block, blockType string, passphrase []byte) (*pem.Block, error) {
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(certcrypto.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newKeyCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(certcrypto.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := aead.Seal(nonce, nonce, pem.EncodeToMemory(block), []byte(blockType))
+
+	return &pem.Block{
+		Type: blockType,
+		Headers: map[string]string{
+			"KDF":  scryptParams,
+			"Salt": base64.StdEncoding.EncodeToString(salt),
+		},
+		Bytes: sealed,
+	}, nil
+}
+
+// decryptPEMBlock decrypts a PEM block encrypted by encryptPEMBlock.
+func decryptPEMBlock(block *pem.Block, passphrase []byte) (*pem.Block, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("encrypted content: set %s or %s", envAccountPassphrase, envAccountPassphraseFile)
+	}
+
+	if block.Headers["KDF"] != scryptParams {
+		return nil, fmt.Errorf("unsupported key derivation: %q", block.Headers["KDF"])
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(block.Headers["Salt"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+
+	aead, err := newKeyCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(block.Bytes) < aead.NonceSize() {
+		return nil, errors.New("the encrypted content is truncated")
+	}
+
+	nonce, sealed := block.Bytes[:aead.NonceSize()], block.Bytes[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, sealed, []byte(block.Type))
+	if err != nil {
+		return nil, errors.New("could not decrypt: wrong passphrase or corrupted file")
+	}
+
+	inner, _ := pem.Decode(plain)
+	if inner == nil {
+		return nil, errors.New("the decrypted content is not a PEM block")
+	}
+
+	return inner, nil
+}
+
+func newKeyCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
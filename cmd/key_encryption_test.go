@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_savePrivateKey_encrypted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-keys")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "account.key")
+	passphrase := []byte("secret")
+
+	privateKey, err := generatePrivateKey(file, certcrypto.EC256, passphrase)
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "BEGIN "+encryptedKeyBlockType)
+	assert.NotContains(t, string(content), "EC PRIVATE KEY")
+
+	loaded, encrypted, err := loadPrivateKey(file, passphrase)
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+	assert.Equal(t, privateKey, loaded)
+
+	_, _, err = loadPrivateKey(file, []byte("wrong"))
+	require.EqualError(t, err, "could not decrypt: wrong passphrase or corrupted file")
+
+	_, _, err = loadPrivateKey(file, nil)
+	require.EqualError(t, err, "encrypted content: set LEGO_ACCOUNT_PASSPHRASE or LEGO_ACCOUNT_PASSPHRASE_FILE")
+}
+
+func Test_savePrivateKey_plain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-keys")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "account.key")
+
+	privateKey, err := generatePrivateKey(file, certcrypto.EC256, nil)
+	require.NoError(t, err)
+
+	loaded, encrypted, err := loadPrivateKey(file, []byte("secret"))
+	require.NoError(t, err)
+	assert.False(t, encrypted)
+	assert.Equal(t, privateKey, loaded)
+}
@@ -23,6 +23,7 @@ func main() {
 	app.HelpName = "lego"
 	app.Usage = "Let's Encrypt client written in Go"
 	app.EnableBashCompletion = true
+	app.BashComplete = cmd.AppComplete
 
 	app.Version = version
 	cli.VersionPrinter = func(c *cli.Context) {
@@ -43,6 +44,7 @@ func main() {
 
 	err = app.Run(os.Args)
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
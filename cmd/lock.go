@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+const (
+	// lockFileName the lock file of the storage, in the "path" directory.
+	lockFileName = ".lock"
+	// lockRetryInterval the interval between two attempts to take the lock.
+	lockRetryInterval = 100 * time.Millisecond
+)
+
+// storageLock an advisory lock of the storage (--path), shared by the lego processes (flock on Unix, LockFileEx on Windows).
+// The commands modifying the storage hold it during their whole cycle (check, order, write; see withStorageLock),
+// so two parallel runs can't both issue the same certificate.
+// The lock is not reentrant: each acquisition opens its own file, locked like by another process,
+// which also excludes the other acquisitions of the process. The lock is released by the OS if the process exits.
+type storageLock struct {
+	path    string
+	timeout time.Duration
+}
+
+// newStorageLock returns the lock of the storage.
+func newStorageLock(ctx *cli.Context) *storageLock {
+	return &storageLock{
+		path:    filepath.Join(ctx.GlobalString("path"), lockFileName),
+		timeout: ctx.GlobalDuration("lock.timeout"),
+	}
+}
+
+// withStorageLock runs the action of a command modifying the storage, holding the lock of the storage.
+func withStorageLock(action func(*cli.Context) error) func(*cli.Context) error {
+	return func(ctx *cli.Context) error {
+		unlock := newStorageLock(ctx).acquire()
+		defer unlock()
+
+		return action(ctx)
+	}
+}
+
+// acquire takes the lock, and returns the function releasing it.
+// It exits if the lock can't be taken before the timeout.
+func (l *storageLock) acquire() func() {
+	if l == nil {
+		return func() {}
+	}
+
+	file, err := l.lock()
+	if err != nil {
+		storageFatalf("Could not lock the storage: %v", err)
+	}
+
+	return func() { l.unlock(file) }
+}
+
+func (l *storageLock) lock() (*os.File, error) {
+	err := createNonExistingFolder(filepath.Dir(l.path))
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, filePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(l.timeout)
+
+	for waiting := false; ; waiting = true {
+		ok, err := tryLockFile(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("%s: %w", l.path, err)
+		}
+
+		if ok {
+			return file, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			_ = file.Close()
+			return nil, fmt.Errorf("%s is locked by another lego process (waited %s, see --lock.timeout)", l.path, l.timeout)
+		}
+
+		if !waiting {
+			log.Infof("Waiting for another lego process using the storage (%s)", l.path)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func (l *storageLock) unlock(file *os.File) {
+	err := unlockFile(file)
+	if err != nil {
+		log.Warnf("Could not unlock the storage %s: %v", l.path, err)
+	}
+
+	_ = file.Close()
+}
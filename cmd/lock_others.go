@@ -0,0 +1,15 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package cmd
+
+import "os"
+
+// tryLockFile the advisory locks are not supported: the storage is not locked.
+func tryLockFile(_ *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func Test_storageLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-lock")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, lockFileName)
+
+	// two locks of the same file behave like the locks of two processes.
+	first := &storageLock{path: path, timeout: time.Second}
+	second := &storageLock{path: path, timeout: 200 * time.Millisecond}
+
+	file, err := first.lock()
+	require.NoError(t, err)
+
+	_, err = second.lock()
+	assert.Error(t, err)
+
+	// not reentrant: the lock also excludes the other acquisitions of the process.
+	_, err = (&storageLock{path: path, timeout: 200 * time.Millisecond}).lock()
+	assert.Error(t, err)
+
+	first.unlock(file)
+
+	file, err = second.lock()
+	require.NoError(t, err)
+	second.unlock(file)
+}
+
+func Test_storageLock_wait(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-lock")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, lockFileName)
+
+	first := &storageLock{path: path}
+	second := &storageLock{path: path, timeout: 5 * time.Second}
+
+	held, err := first.lock()
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		first.unlock(held)
+	}()
+
+	start := time.Now()
+
+	file, err := second.lock()
+	require.NoError(t, err)
+	second.unlock(file)
+
+	assert.True(t, time.Since(start) >= 300*time.Millisecond)
+}
+
+func Test_withStorageLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-lock")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "storage")
+
+	// the whole action runs holding the lock, the storage directory is created if needed.
+	runWithFlags(t, []string{"--path", path, "--lock.timeout", "200ms", "renew"}, func(ctx *cli.Context) {
+		err := withStorageLock(func(ctx *cli.Context) error {
+			_, err := newStorageLock(ctx).lock()
+			assert.Error(t, err)
+			return nil
+		})(ctx)
+		require.NoError(t, err)
+
+		file, err := newStorageLock(ctx).lock()
+		require.NoError(t, err)
+		newStorageLock(ctx).unlock(file)
+	})
+}
+
+func Test_storageLock_nil(t *testing.T) {
+	var lock *storageLock
+
+	unlock := lock.acquire()
+	unlock()
+}
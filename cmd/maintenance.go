@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// defaultMaintenanceDelay the delay between two attempts when the CA doesn't send a Retry-After header.
+const defaultMaintenanceDelay = time.Minute
+
+// caUnavailable returns true if the error is a "503 Service Unavailable" response of the CA (e.g. during a maintenance),
+// with the delay requested by the CA.
+func caUnavailable(err error) (time.Duration, bool) {
+	var problem *acme.ProblemDetails
+	if errors.As(err, &problem) && problem.HTTPStatus == http.StatusServiceUnavailable {
+		return problem.RetryAfter, true
+	}
+
+	var unexpected *acme.UnexpectedResponseError
+	if errors.As(err, &unexpected) && unexpected.StatusCode == http.StatusServiceUnavailable {
+		return unexpected.RetryAfter, true
+	}
+
+	return 0, false
+}
+
+// retryDuringMaintenance calls the operation, and retries it while the CA is unavailable, for at most maxWait.
+// The delay between two attempts is the Retry-After of the CA.
+// The other errors, and the errors once maxWait is exhausted, are returned as is.
+func retryDuringMaintenance(maxWait time.Duration, operation func() error) error {
+	deadline := clk.Now().Add(maxWait)
+
+	for {
+		err := operation()
+		if err == nil || maxWait <= 0 {
+			return err
+		}
+
+		delay, ok := caUnavailable(err)
+		if !ok {
+			return err
+		}
+
+		if delay <= 0 {
+			delay = defaultMaintenanceDelay
+		}
+
+		remaining := deadline.Sub(clk.Now())
+		if remaining <= 0 {
+			log.Warnf("The CA is still unavailable after %s, giving up.", maxWait)
+			return err
+		}
+
+		if delay > remaining {
+			delay = remaining
+		}
+
+		log.Warnf("The CA is unavailable (maintenance?), retrying in %s (%s left): %v", delay, remaining.Round(time.Second), err)
+
+		clk.Sleep(delay)
+	}
+}
+
+// maintenanceWait returns the time to wait for the CA when it is unavailable (--maintenance.wait),
+// by default the time of the quirks of the CA.
+func maintenanceWait(ctx *cli.Context) time.Duration {
+	if quirks, ok := lego.GetQuirks(ctx.GlobalString("server")); ok && !ctx.GlobalIsSet("maintenance.wait") {
+		return quirks.MaintenanceWait
+	}
+
+	return ctx.GlobalDuration("maintenance.wait")
+}
+
+// obtainDuringMaintenance obtains a certificate, retrying while the CA is unavailable (--maintenance.wait).
+func obtainDuringMaintenance(ctx *cli.Context, obtain func() (*certificate.Resource, error)) (*certificate.Resource, error) {
+	var certRes *certificate.Resource
+
+	err := retryDuringMaintenance(maintenanceWait(ctx), func() error {
+		var err error
+		certRes, err = obtain()
+		return err
+	})
+
+	return certRes, err
+}
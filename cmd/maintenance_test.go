@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/acme"
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func Test_retryDuringMaintenance(t *testing.T) {
+	start := time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	unavailable := fmt.Errorf("get directory: %w", &acme.ProblemDetails{HTTPStatus: http.StatusServiceUnavailable, RetryAfter: 10 * time.Minute})
+
+	testCases := []struct {
+		desc          string
+		maxWait       time.Duration
+		errors        []error
+		expectedCalls int
+		expectedWait  time.Duration
+		hasError      bool
+	}{
+		{
+			desc:          "disabled",
+			errors:        []error{unavailable, nil},
+			expectedCalls: 1,
+			hasError:      true,
+		},
+		{
+			desc:          "recovered",
+			maxWait:       time.Hour,
+			errors:        []error{unavailable, unavailable, nil},
+			expectedCalls: 3,
+			expectedWait:  20 * time.Minute,
+		},
+		{
+			desc:          "without Retry-After",
+			maxWait:       time.Hour,
+			errors:        []error{&acme.UnexpectedResponseError{StatusCode: http.StatusServiceUnavailable}, nil},
+			expectedCalls: 2,
+			expectedWait:  defaultMaintenanceDelay,
+		},
+		{
+			desc:          "exhausted",
+			maxWait:       15 * time.Minute,
+			errors:        []error{unavailable, unavailable, unavailable, nil},
+			expectedCalls: 3,
+			expectedWait:  15 * time.Minute,
+			hasError:      true,
+		},
+		{
+			desc:          "other error",
+			maxWait:       time.Hour,
+			errors:        []error{errors.New("unauthorized"), nil},
+			expectedCalls: 1,
+			hasError:      true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			fake := clock.NewFake(start)
+			clk = fake
+			defer func() { clk = clock.Real }()
+
+			var calls int
+			err := retryDuringMaintenance(test.maxWait, func() error {
+				calls++
+				return test.errors[calls-1]
+			})
+
+			if test.hasError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedCalls, calls)
+			assert.Equal(t, test.expectedWait, fake.Now().Sub(start))
+		})
+	}
+}
+
+func Test_maintenanceWait(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		args     []string
+		expected time.Duration
+	}{
+		{
+			desc:     "default",
+			args:     []string{"renew"},
+			expected: 0,
+		},
+		{
+			desc:     "quirks of the CA",
+			args:     []string{"--server", "https://acme.zerossl.com/v2/DV90", "renew"},
+			expected: 10 * time.Minute,
+		},
+		{
+			desc:     "explicit value",
+			args:     []string{"--server", "https://acme.zerossl.com/v2/DV90", "--maintenance.wait", "1m", "renew"},
+			expected: time.Minute,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			runWithFlags(t, test.args, func(ctx *cli.Context) {
+				assert.Equal(t, test.expected, maintenanceWait(ctx))
+			})
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/challenge/http01"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// providerMetricsFile returns the observer writing the metrics of the DNS providers to the file after each call (--dns.metrics).
+func providerMetricsFile(path string) dns01.ProviderObserver {
+	return dns01.ProviderObserverFunc(func(call dns01.ProviderCall) {
+		err := writeMetricsFile(path, dns01.DefaultProviderMetrics.WritePrometheus)
+		if err != nil {
+			log.Warnf("[%s] DNS provider metrics: %v", call.Domain, err)
+		}
+	})
+}
+
+// serverMetricsFile writes the counters of the requests of the HTTP-01 challenge server to the file after each request (--http.metrics).
+func serverMetricsFile(srv *http01.ProviderServer, path string) {
+	srv.OnRequest(func(req http01.ServerRequest) {
+		err := writeMetricsFile(path, srv.WritePrometheus)
+		if err != nil {
+			log.Warnf("[%s] HTTP-01 challenge server metrics: %v", req.Domain, err)
+		}
+	})
+}
+
+// writeMetricsFile writes metrics in the Prometheus text format to the file.
+// The file is replaced atomically, so it can be read at any time by a collector.
+func writeMetricsFile(path string, write func(w io.Writer) error) error {
+	buf := &bytes.Buffer{}
+
+	err := write(buf)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(buf.Bytes())
+	if errC := tmp.Close(); err == nil {
+		err = errC
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp.Name(), 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// Extensions of the files of an offline order, waiting for the certificate issued on another host.
+const (
+	pendingKeyExtension = ".pending.key"
+	pendingCSRExtension = ".csr"
+)
+
+// ordersExportCSR generates the private key and the CSR of the domains (--domains), without any request to the CA:
+// the private key stays in the certificates directory, the CSR is carried to a connected host ('run --csr').
+func ordersExportCSR(ctx *cli.Context) error {
+	domains := getDomains(ctx)
+	if len(domains) == 0 {
+		log.Fatal("Please specify --domains or -d")
+	}
+
+	certsStorage := NewCertificatesStorage(ctx)
+	certsStorage.CreateRootFolder()
+
+	if certsStorage.ExistsFile(domains[0], pendingKeyExtension) {
+		log.Fatalf("A CSR of %s is already waiting for its certificate, import it with 'orders import-cert', or remove %s",
+			domains[0], filepath.Join(certsStorage.GetRootPath(), sanitizedDomain(domains[0])+pendingKeyExtension))
+	}
+
+	keyPEM, csrPEM, err := createOfflineCSR(getKeyType(ctx), domains, ctx.Bool("must-staple"))
+	if err != nil {
+		log.Fatalf("Could not create the CSR of %s: %v", domains[0], err)
+	}
+
+	err = certsStorage.WriteFile(domains[0], pendingKeyExtension, keyPEM)
+	if err != nil {
+		log.Fatalf("Could not save the private key of %s: %v", domains[0], err)
+	}
+
+	err = certsStorage.WriteFile(domains[0], pendingCSRExtension, csrPEM)
+	if err != nil {
+		log.Fatalf("Could not save the CSR of %s: %v", domains[0], err)
+	}
+
+	output := ctx.String("output")
+	if output == "" {
+		output = filepath.Join(certsStorage.GetRootPath(), sanitizedDomain(domains[0])+pendingCSRExtension)
+	} else {
+		err = ioutil.WriteFile(output, csrPEM, filePerm)
+		if err != nil {
+			log.Fatalf("Could not write the CSR of %s: %v", domains[0], err)
+		}
+	}
+
+	log.Printf("The CSR of %s is in %s: obtain the certificate with 'lego --csr %s run' on a connected host, then import it with 'lego orders import-cert'",
+		domains[0], output, filepath.Base(output))
+
+	return nil
+}
+
+// ordersImportCert imports the certificate issued for a CSR created by 'orders export-csr', with its private key.
+func ordersImportCert(ctx *cli.Context) error {
+	if ctx.String("cert") == "" {
+		log.Fatal("Please specify the certificate with --cert")
+	}
+
+	bundle, err := ioutil.ReadFile(ctx.String("cert"))
+	if err != nil {
+		log.Fatalf("Could not read the certificate: %v", err)
+	}
+
+	var issuer []byte
+	if ctx.String("issuer") != "" {
+		issuer, err = ioutil.ReadFile(ctx.String("issuer"))
+		if err != nil {
+			log.Fatalf("Could not read the issuer certificate: %v", err)
+		}
+	}
+
+	certsStorage := NewCertificatesStorage(ctx)
+	certsStorage.CreateRootFolder()
+
+	var domain string
+	if domains := getDomains(ctx); len(domains) > 0 {
+		domain = domains[0]
+	}
+
+	certRes, err := importCertificate(certsStorage, domain, bundle, issuer)
+	if err != nil {
+		log.Fatalf("Could not import the certificate: %v", err)
+	}
+
+	certsStorage.SaveResource(certRes)
+
+	for _, extension := range []string{pendingKeyExtension, pendingCSRExtension} {
+		err = os.Remove(filepath.Join(certsStorage.GetRootPath(), sanitizedDomain(certRes.Domain)+extension))
+		if err != nil && !os.IsNotExist(err) {
+			log.Warnf("Could not remove the %s file of %s: %v", extension, certRes.Domain, err)
+		}
+	}
+
+	log.Printf("[%s] The certificate has been imported, it expires on %s", certRes.Domain, certRes.NotAfter)
+
+	return nil
+}
+
+// createOfflineCSR generates a private key and the CSR of the domains (the first domain is the common name), both PEM encoded.
+func createOfflineCSR(keyType certcrypto.KeyType, domains []string, mustStaple bool) ([]byte, []byte, error) {
+	privateKey, err := certcrypto.GeneratePrivateKey(keyType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	csrDER, err := certcrypto.GenerateCSR(privateKey, domains[0], domains, mustStaple)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	return certcrypto.PEMEncode(privateKey), csrPEM, nil
+}
+
+// importCertificate builds the resource of a certificate issued for an offline CSR, with the pending private key of the domain.
+// The domain is the common name of the certificate (or its first SAN) if empty.
+// The issuer is the rest of the bundle if empty.
+func importCertificate(certsStorage *CertificatesStorage, domain string, bundle, issuer []byte) (*certificate.Resource, error) {
+	certs, err := certcrypto.ParsePEMBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := certs[0]
+
+	if domain == "" {
+		domain = leaf.Subject.CommonName
+	}
+	if domain == "" && len(leaf.DNSNames) > 0 {
+		domain = leaf.DNSNames[0]
+	}
+	if domain == "" {
+		return nil, errors.New("the certificate has no domain, please specify it with --domains")
+	}
+
+	if !certsStorage.ExistsFile(domain, pendingKeyExtension) {
+		return nil, fmt.Errorf("no pending private key for %s, the CSR must be created with 'orders export-csr'", domain)
+	}
+
+	keyPEM, err := certsStorage.ReadFile(domain, pendingKeyExtension)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the private key: %w", err)
+	}
+
+	err = matchPrivateKey(keyPEM, leaf)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(issuer) == 0 && len(certs) > 1 {
+		for _, cert := range certs[1:] {
+			issuer = append(issuer, certcrypto.PEMEncode(certcrypto.DERCertificateBytes(cert.Raw))...)
+		}
+	}
+
+	certRes := &certificate.Resource{
+		Domain:            domain,
+		Certificate:       bundle,
+		IssuerCertificate: issuer,
+		PrivateKey:        keyPEM,
+	}
+
+	err = certRes.ParseMetadata("")
+	if err != nil {
+		return nil, err
+	}
+
+	return certRes, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_createOfflineCSR(t *testing.T) {
+	keyPEM, csrPEM, err := createOfflineCSR(certcrypto.EC256, []string{"example.com", "www.example.com"}, false)
+	require.NoError(t, err)
+
+	privateKey, err := certcrypto.ParsePEMPrivateKey(keyPEM)
+	require.NoError(t, err)
+
+	csr, err := certcrypto.PemDecodeTox509CSR(csrPEM)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", csr.Subject.CommonName)
+	assert.Equal(t, []string{"example.com", "www.example.com"}, csr.DNSNames)
+	assert.Equal(t, &privateKey.(*ecdsa.PrivateKey).PublicKey, csr.PublicKey)
+}
+
+func Test_importCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-offline")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	pki := newTestPKI(t, time.Now().Add(90*24*time.Hour), "example.com", "www.example.com")
+	keyPEM := certcrypto.PEMEncode(pki.leafKey)
+
+	require.NoError(t, certsStorage.WriteFile("example.com", pendingKeyExtension, keyPEM))
+
+	issuer := []byte("-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n")
+
+	certRes, err := importCertificate(certsStorage, "", pki.leaf, issuer)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", certRes.Domain)
+	assert.Equal(t, pki.leaf, certRes.Certificate)
+	assert.Equal(t, issuer, certRes.IssuerCertificate)
+	assert.Equal(t, keyPEM, certRes.PrivateKey)
+	assert.Equal(t, []string{"example.com", "www.example.com"}, certRes.SANs)
+}
+
+func Test_importCertificate_errors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-offline")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	pki := newTestPKI(t, time.Now().Add(90*24*time.Hour), "example.com")
+
+	_, err = importCertificate(certsStorage, "", pki.leaf, nil)
+	require.EqualError(t, err, "no pending private key for example.com, the CSR must be created with 'orders export-csr'")
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	require.NoError(t, certsStorage.WriteFile("example.com", pendingKeyExtension, certcrypto.PEMEncode(otherKey)))
+
+	_, err = importCertificate(certsStorage, "example.com", pki.leaf, nil)
+	require.EqualError(t, err, "the private key doesn't match the certificate")
+}
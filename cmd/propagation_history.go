@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+)
+
+// propagationHistoryFileName the file storing the propagation durations observed with each DNS provider ("path" option).
+const propagationHistoryFileName = "propagation.json"
+
+// propagationHistories the propagation histories, by DNS provider name.
+type propagationHistories map[string]*dns01.PropagationHistory
+
+// loadPropagationHistory loads the propagation history of a DNS provider,
+// and returns it with the function saving it.
+func loadPropagationHistory(root, provider string) (*dns01.PropagationHistory, func(*dns01.PropagationHistory) error, error) {
+	filename := filepath.Join(root, propagationHistoryFileName)
+
+	histories, err := readPropagationHistories(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	history, ok := histories[provider]
+	if !ok {
+		history = &dns01.PropagationHistory{}
+	}
+
+	save := func(h *dns01.PropagationHistory) error {
+		// reloads the file: the histories of the other providers may have changed since.
+		current, err := readPropagationHistories(filename)
+		if err != nil {
+			return err
+		}
+
+		current[provider] = h
+
+		raw, err := json.MarshalIndent(current, "", "\t")
+		if err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(filename, raw, filePerm)
+	}
+
+	return history, save, nil
+}
+
+func readPropagationHistories(filename string) (propagationHistories, error) {
+	histories := make(propagationHistories)
+
+	raw, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return histories, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(raw, &histories)
+	if err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
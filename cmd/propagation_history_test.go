@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_loadPropagationHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-propagation")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	history, save, err := loadPropagationHistory(dir, "hetzner")
+	require.NoError(t, err)
+	assert.Empty(t, history.Durations)
+
+	history.Add(42 * time.Second)
+	require.NoError(t, save(history))
+
+	other, saveOther, err := loadPropagationHistory(dir, "cloudflare")
+	require.NoError(t, err)
+	assert.Empty(t, other.Durations)
+
+	other.Add(5 * time.Second)
+	require.NoError(t, saveOther(other))
+
+	history, _, err = loadPropagationHistory(dir, "hetzner")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{42 * time.Second}, history.Durations)
+
+	other, _, err = loadPropagationHistory(dir, "cloudflare")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Second}, other.Durations)
+}
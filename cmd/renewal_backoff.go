@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+const renewalFailuresExt = ".failures.json"
+
+// Environment variables passed to the circuit breaker hook.
+const (
+	envBreakerDomain   = "LEGO_RENEW_DOMAIN"
+	envBreakerFailures = "LEGO_RENEW_FAILURES"
+	envBreakerError    = "LEGO_RENEW_ERROR"
+)
+
+// renewalFailures the consecutive failures of the renewal of a certificate, stored next to it (<domain>.failures.json).
+// The file is removed by the next successful renewal.
+type renewalFailures struct {
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"lastFailure"`
+	LastError   string    `json:"lastError"`
+	// NextAttempt the time before which the renewal is not attempted (backoff).
+	NextAttempt time.Time `json:"nextAttempt,omitempty"`
+	// Open the circuit breaker is open: the renewal is paused until the failures are reset.
+	Open bool `json:"open,omitempty"`
+}
+
+// renewalBackoff the backoff and the circuit breaker of the renewals of a certificate.
+type renewalBackoff struct {
+	certsStorage *CertificatesStorage
+
+	// initial the delay after the first failure, doubled after each failure, no backoff if 0.
+	initial time.Duration
+	// max the maximum delay.
+	max time.Duration
+	// threshold the number of consecutive failures opening the circuit breaker, no circuit breaker if 0.
+	threshold int
+	// hook the command run when the circuit breaker opens.
+	hook string
+}
+
+func newRenewalBackoff(ctx *cli.Context, certsStorage *CertificatesStorage) *renewalBackoff {
+	return &renewalBackoff{
+		certsStorage: certsStorage,
+		initial:      ctx.Duration("backoff"),
+		max:          ctx.Duration("backoff.max"),
+		threshold:    ctx.Int("circuit-breaker"),
+		hook:         ctx.String("circuit-breaker.hook"),
+	}
+}
+
+// enabled reports if the failures are tracked.
+func (b *renewalBackoff) enabled() bool {
+	return b.initial > 0 || b.threshold > 0
+}
+
+// allow reports if the renewal of the domain can be attempted now.
+func (b *renewalBackoff) allow(domain string) bool {
+	if !b.enabled() {
+		return true
+	}
+
+	state, err := b.load(domain)
+	if err != nil {
+		log.Warnf("[%s] renewal failures: %v", domain, err)
+		return true
+	}
+
+	if state == nil {
+		return true
+	}
+
+	if state.Open {
+		log.Warnf("[%s] The renewal is paused after %d consecutive failures (last error: %s). Use --reset-failures to resume it.",
+			domain, state.Failures, state.LastError)
+		return false
+	}
+
+	if now := clk.Now(); now.Before(state.NextAttempt) {
+		log.Warnf("[%s] The renewal failed %d times, the next attempt is after %s (%s left).",
+			domain, state.Failures, state.NextAttempt.Local().Format(time.RFC3339), state.NextAttempt.Sub(now).Round(time.Second))
+		return false
+	}
+
+	return true
+}
+
+// failed records a failure of the renewal of the domain, and opens the circuit breaker after the threshold.
+func (b *renewalBackoff) failed(domain string, cause error) {
+	if !b.enabled() {
+		return
+	}
+
+	state, err := b.load(domain)
+	if err != nil {
+		log.Warnf("[%s] renewal failures: %v", domain, err)
+	}
+
+	if state == nil {
+		state = &renewalFailures{}
+	}
+
+	state.Failures++
+	state.LastFailure = clk.Now().UTC()
+	state.LastError = cause.Error()
+	state.NextAttempt = state.LastFailure.Add(b.delay(state.Failures))
+
+	if b.threshold > 0 && state.Failures >= b.threshold && !state.Open {
+		state.Open = true
+		log.Warnf("[%s] The renewal failed %d consecutive times: the renewal is paused.", domain, state.Failures)
+		b.notify(domain, state)
+	}
+
+	err = b.save(domain, state)
+	if err != nil {
+		log.Warnf("[%s] renewal failures: %v", domain, err)
+	}
+}
+
+// succeeded resets the failures of the domain.
+func (b *renewalBackoff) succeeded(domain string) {
+	b.reset(domain)
+}
+
+// reset removes the failures of the domain, and closes the circuit breaker.
+func (b *renewalBackoff) reset(domain string) {
+	err := b.certsStorage.RemoveFile(domain, renewalFailuresExt)
+	if err != nil {
+		log.Warnf("[%s] renewal failures: %v", domain, err)
+	}
+}
+
+// delay returns the backoff delay after a number of consecutive failures.
+func (b *renewalBackoff) delay(failures int) time.Duration {
+	if b.initial <= 0 {
+		return 0
+	}
+
+	delay := b.initial
+	for i := 1; i < failures && (b.max <= 0 || delay < b.max); i++ {
+		delay *= 2
+	}
+
+	if b.max > 0 && delay > b.max {
+		return b.max
+	}
+
+	return delay
+}
+
+// notify runs the circuit breaker hook.
+func (b *renewalBackoff) notify(domain string, state *renewalFailures) {
+	if b.hook == "" {
+		return
+	}
+
+	data := newHookData(b.certsStorage, &certificate.Resource{Domain: domain})
+	data.Failures = state.Failures
+	data.Error = state.LastError
+
+	parts, err := renderHook("circuit-breaker.hook", b.hook, data)
+	if err != nil {
+		log.Warnf("[%s] circuit breaker hook: %v", domain, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	cmd.Env = append(os.Environ(),
+		envBreakerDomain+"="+domain,
+		envBreakerFailures+"="+strconv.Itoa(state.Failures),
+		envBreakerError+"="+state.LastError,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		fmt.Println(string(output))
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		log.Warnf("[%s] circuit breaker hook: timed out", domain)
+		return
+	}
+
+	if err != nil {
+		log.Warnf("[%s] circuit breaker hook: %v", domain, err)
+	}
+}
+
+func (b *renewalBackoff) load(domain string) (*renewalFailures, error) {
+	raw, err := b.certsStorage.ReadFile(domain, renewalFailuresExt)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state renewalFailures
+	err = json.Unmarshal(raw, &state)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file %s: %w", renewalFailuresExt, err)
+	}
+
+	return &state, nil
+}
+
+func (b *renewalBackoff) save(domain string, state *renewalFailures) error {
+	raw, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return b.certsStorage.WriteFile(domain, renewalFailuresExt, raw)
+}
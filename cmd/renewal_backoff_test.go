@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_renewalBackoff_delay(t *testing.T) {
+	backoff := &renewalBackoff{initial: time.Hour, max: 6 * time.Hour}
+
+	assert.Equal(t, time.Hour, backoff.delay(1))
+	assert.Equal(t, 2*time.Hour, backoff.delay(2))
+	assert.Equal(t, 4*time.Hour, backoff.delay(3))
+	assert.Equal(t, 6*time.Hour, backoff.delay(4))
+	assert.Equal(t, 6*time.Hour, backoff.delay(100))
+}
+
+func Test_renewalBackoff(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	clk = fake
+	defer func() { clk = clock.Real }()
+
+	dir, err := ioutil.TempDir("", "lego-backoff")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	notified := filepath.Join(dir, "notified")
+
+	backoff := &renewalBackoff{
+		certsStorage: &CertificatesStorage{rootPath: dir},
+		initial:      time.Hour,
+		max:          24 * time.Hour,
+		threshold:    3,
+		hook:         "touch " + notified,
+	}
+
+	assert.True(t, backoff.allow("example.com"))
+
+	backoff.failed("example.com", errors.New("boom"))
+	assert.False(t, backoff.allow("example.com"))
+
+	fake.Advance(time.Hour)
+	assert.True(t, backoff.allow("example.com"))
+
+	backoff.failed("example.com", errors.New("boom"))
+
+	fake.Advance(time.Hour)
+	assert.False(t, backoff.allow("example.com"))
+
+	fake.Advance(time.Hour)
+	assert.True(t, backoff.allow("example.com"))
+
+	assert.NoFileExists(t, notified)
+
+	backoff.failed("example.com", errors.New("boom"))
+
+	state, err := backoff.load("example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 3, state.Failures)
+	assert.Equal(t, "boom", state.LastError)
+	assert.True(t, state.Open)
+	assert.FileExists(t, notified)
+
+	// the circuit breaker stays open after the backoff.
+	fake.Advance(48 * time.Hour)
+	assert.False(t, backoff.allow("example.com"))
+
+	backoff.reset("example.com")
+	assert.True(t, backoff.allow("example.com"))
+
+	backoff.failed("example.com", errors.New("boom"))
+	backoff.succeeded("example.com")
+
+	state, err = backoff.load("example.com")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func Test_renewalBackoff_disabled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-backoff")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	backoff := &renewalBackoff{certsStorage: &CertificatesStorage{rootPath: dir}}
+
+	backoff.failed("example.com", errors.New("boom"))
+	assert.True(t, backoff.allow("example.com"))
+	assert.NoFileExists(t, filepath.Join(dir, "example.com"+renewalFailuresExt))
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+const renewalMetadataExt = ".renewal.json"
+
+// renewalGlobalFlags the global options recorded in the renewal metadata.
+// The domains, the storage, and the secrets (EAB, inventory headers) are not recorded.
+var renewalGlobalFlags = []string{
+	"server", "server.pin", "email", "with-wildcard", "key-type",
+	"http", "http.port", "http.proxy-header", "http.access-log", "http.rate-limit", "http.metrics", "http.webroot", "http.memcached-host",
+	"tls", "tls.port",
+	"dns", "dns.fallback", "dns.delegate", "dns.delegate-dns", "dns.disable-cp", "dns.check-delegation", "dns.verify-cleanup", "dns.cleanup-retry", "dns.resolvers", "dns.auto-timeout", "dns.continue-on-timeout", "dns-timeout", "dns.retries", "dns.tcp", "dns.edns-size",
+	"onion.key", "auto-challenge", "challenge.order", "challenge-hook", "pem", "chain.strip-root", "chain.leaf-only", "chain.leaf-first", "cert.timeout", "tlsa", "tlsa.port", "tlsa.publish", "inventory.url", "inventory.template", "maintenance.wait", "clock-skew.warn", "clock-skew.correct", "directory.ttl", "snippet",
+	"dns.metrics", "provider.retries", "provider.retry-delay", "provider.timeout", "provider.log",
+}
+
+// renewalCommandFlags the options of the run and renew commands recorded in the renewal metadata.
+var renewalCommandFlags = []string{
+	"no-bundle", "must-staple", "allow-partial", "reuse-key", "lifetime", "renew-hook",
+	"backoff", "backoff.max", "circuit-breaker", "circuit-breaker.hook",
+}
+
+// renewalMetadata the parameters used to obtain a certificate, stored next to it (<domain>.renewal.json).
+// The renew command uses them as defaults, so the original options don't have to be repeated.
+type renewalMetadata struct {
+	Domains []string  `json:"domains"`
+	Updated time.Time `json:"updated"`
+	// Server the CA server which issued the certificate (see checkServerEnvironment).
+	Server string `json:"server,omitempty"`
+
+	// GlobalOptions the global options, by name.
+	GlobalOptions map[string][]string `json:"globalOptions,omitempty"`
+	// Options the options of the command, by name.
+	Options map[string][]string `json:"options,omitempty"`
+}
+
+// saveRenewalMetadata records the options used to obtain the certificate of the domain.
+func saveRenewalMetadata(ctx *cli.Context, certsStorage *CertificatesStorage, domain string, domains []string) {
+	metadata := renewalMetadata{
+		Domains:       domains,
+		Updated:       clk.Now().UTC(),
+		Server:        ctx.GlobalString("server"),
+		GlobalOptions: make(map[string][]string),
+		Options:       make(map[string][]string),
+	}
+
+	for _, name := range renewalGlobalFlags {
+		if ctx.GlobalIsSet(name) {
+			metadata.GlobalOptions[name] = flagValues(ctx.GlobalGeneric(name))
+		}
+	}
+
+	for _, name := range renewalCommandFlags {
+		if ctx.IsSet(name) {
+			metadata.Options[name] = flagValues(ctx.Generic(name))
+		}
+	}
+
+	jsonBytes, err := json.MarshalIndent(metadata, "", "\t")
+	if err != nil {
+		log.Fatalf("Unable to marshal the renewal metadata for domain %s\n\t%v", domain, err)
+	}
+
+	err = certsStorage.WriteFile(domain, renewalMetadataExt, jsonBytes)
+	if err != nil {
+		log.Fatalf("Unable to save the renewal metadata for domain %s\n\t%v", domain, err)
+	}
+}
+
+// newRenewalStorage returns the certificates storage of the renew command,
+// built once the renewal metadata is applied, so the recorded storage options (e.g. --pem) are used.
+// The metadata is located with the options of the command line: the recorded options can't move the storage.
+func newRenewalStorage(ctx *cli.Context) *CertificatesStorage {
+	certsStorage := NewCertificatesStorage(ctx)
+
+	if ctx.GlobalIsSet("csr") {
+		return certsStorage
+	}
+
+	domain := getDomains(ctx)[0]
+
+	applyRenewalMetadata(ctx, certsStorage, domain)
+
+	renewalStorage := NewCertificatesStorage(ctx)
+	if renewalStorage.GetRootPath() != certsStorage.GetRootPath() {
+		log.Fatalf("The renewal metadata of %s moves the storage from %s to %s (e.g. --server with --server.namespace): specify the options on the command line",
+			domain, certsStorage.GetRootPath(), renewalStorage.GetRootPath())
+	}
+
+	return renewalStorage
+}
+
+// applyRenewalMetadata sets the options recorded in the renewal metadata of the domain,
+// except the ones explicitly set on the command line.
+func applyRenewalMetadata(ctx *cli.Context, certsStorage *CertificatesStorage, domain string) {
+	metadata, err := readRenewalMetadata(certsStorage, domain)
+	if err != nil {
+		log.Fatalf("Error while loading the renewal metadata for domain %s\n\t%v", domain, err)
+	}
+	if metadata == nil {
+		return
+	}
+
+	for name, values := range metadata.GlobalOptions {
+		if ctx.GlobalIsSet(name) {
+			continue
+		}
+
+		for _, value := range values {
+			if err := ctx.GlobalSet(name, value); err != nil {
+				log.Warnf("[%s] renewal metadata: option %s: %v", domain, name, err)
+			}
+		}
+	}
+
+	for name, values := range metadata.Options {
+		if ctx.IsSet(name) {
+			continue
+		}
+
+		for _, value := range values {
+			// the options of the run command which don't exist for the renew command are ignored.
+			_ = ctx.Set(name, value)
+		}
+	}
+}
+
+// readRenewalMetadata returns the renewal metadata of the domain, nil if there is none.
+func readRenewalMetadata(certsStorage *CertificatesStorage, domain string) (*renewalMetadata, error) {
+	raw, err := certsStorage.ReadFile(domain, renewalMetadataExt)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata renewalMetadata
+	err = json.Unmarshal(raw, &metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// flagValues returns the values of a flag, as they are passed on the command line.
+func flagValues(value interface{}) []string {
+	switch v := value.(type) {
+	case *cli.StringSlice:
+		return v.Value()
+	case fmt.Stringer:
+		return []string{v.String()}
+	default:
+		return nil
+	}
+}
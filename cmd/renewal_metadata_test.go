@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func runWithFlags(t *testing.T, args []string, action func(ctx *cli.Context)) {
+	t.Helper()
+
+	app := cli.NewApp()
+	app.Flags = CreateFlags("")
+	app.Commands = []cli.Command{
+		{
+			Name:  "renew",
+			Flags: createRenew().Flags,
+			Action: func(ctx *cli.Context) error {
+				action(ctx)
+				return nil
+			},
+		},
+	}
+
+	require.NoError(t, app.Run(append([]string{"lego"}, args...)))
+}
+
+func Test_renewalMetadata(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-renewal")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	runWithFlags(t, []string{
+		"-d", "example.com", "-m", "foo@example.com", "--dns", "cloudflare",
+		"--dns.resolvers", "1.1.1.1:53", "--dns.resolvers", "8.8.8.8:53",
+		"--key-type", "ec256",
+		"renew", "--must-staple", "--renew-hook", "./hook.sh",
+	}, func(ctx *cli.Context) {
+		saveRenewalMetadata(ctx, certsStorage, "example.com", []string{"example.com"})
+	})
+
+	runWithFlags(t, []string{"-d", "example.com", "--key-type", "rsa4096", "renew"}, func(ctx *cli.Context) {
+		applyRenewalMetadata(ctx, certsStorage, "example.com")
+
+		assert.Equal(t, "foo@example.com", ctx.GlobalString("email"))
+		assert.Equal(t, "cloudflare", ctx.GlobalString("dns"))
+		assert.Equal(t, []string{"1.1.1.1:53", "8.8.8.8:53"}, ctx.GlobalStringSlice("dns.resolvers"))
+		assert.True(t, ctx.Bool("must-staple"))
+		assert.Equal(t, "./hook.sh", ctx.String("renew-hook"))
+
+		// the options of the command line take precedence.
+		assert.Equal(t, "rsa4096", ctx.GlobalString("key-type"))
+		assert.False(t, ctx.GlobalBool("http"))
+
+		// applying twice doesn't duplicate the values.
+		applyRenewalMetadata(ctx, certsStorage, "example.com")
+		assert.Equal(t, []string{"1.1.1.1:53", "8.8.8.8:53"}, ctx.GlobalStringSlice("dns.resolvers"))
+	})
+}
+
+func Test_newRenewalStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-renewal")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	runWithFlags(t, []string{"--path", dir, "-d", "example.com", "--pem", "--chain.leaf-only", "renew"}, func(ctx *cli.Context) {
+		certsStorage := NewCertificatesStorage(ctx)
+		certsStorage.CreateRootFolder()
+
+		saveRenewalMetadata(ctx, certsStorage, "example.com", []string{"example.com"})
+	})
+
+	// the storage options of the metadata apply to the storage of the renewal.
+	runWithFlags(t, []string{"--path", dir, "-d", "example.com", "renew"}, func(ctx *cli.Context) {
+		certsStorage := newRenewalStorage(ctx)
+
+		assert.True(t, certsStorage.pem)
+		assert.True(t, certsStorage.chain.LeafOnly)
+	})
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// renewalWindow the times of the periodic checks of the daemon, in local time: hours of the day, and excluded days of the week.
+type renewalWindow struct {
+	// start and end the window of the day (minutes since midnight), the whole day if equal.
+	// The window crosses midnight if the end is before the start (e.g. 22:00-02:00).
+	start, end int
+	excluded   map[time.Weekday]bool
+}
+
+// parseRenewalWindow parses a window of the day (HH:MM-HH:MM, empty for the whole day),
+// and the excluded days of the week (mon, tue, ...). It returns nil if the window is the whole week.
+func parseRenewalWindow(hours string, excludedDays []string) (*renewalWindow, error) {
+	w := &renewalWindow{excluded: make(map[time.Weekday]bool)}
+
+	if hours != "" {
+		parts := strings.Split(hours, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid renewal window %q: HH:MM-HH:MM", hours)
+		}
+
+		var err error
+		w.start, err = parseTimeOfDay(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid renewal window %q: %w", hours, err)
+		}
+
+		w.end, err = parseTimeOfDay(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid renewal window %q: %w", hours, err)
+		}
+	}
+
+	for _, day := range excludedDays {
+		weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(day))]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q: mon, tue, wed, thu, fri, sat, or sun", day)
+		}
+		w.excluded[weekday] = true
+	}
+
+	if len(w.excluded) == len(weekdays) {
+		return nil, fmt.Errorf("all the days are excluded from the renewal window")
+	}
+
+	if w.start == w.end && len(w.excluded) == 0 {
+		return nil, nil
+	}
+
+	return w, nil
+}
+
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// contains reports if the time is in the window.
+func (w *renewalWindow) contains(t time.Time) bool {
+	t = t.Local()
+
+	if w.excluded[t.Weekday()] {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+
+	switch {
+	case w.start == w.end:
+		return true
+	case w.start < w.end:
+		return minute >= w.start && minute < w.end
+	default:
+		return minute >= w.start || minute < w.end
+	}
+}
+
+// next returns the time if it is in the window, the start of the next window otherwise.
+func (w *renewalWindow) next(t time.Time) time.Time {
+	if w.contains(t) {
+		return t
+	}
+
+	local := t.Local()
+
+	// the window opens either at its start, or at midnight (a window across midnight, after an excluded day).
+	for i := 0; i <= 8; i++ {
+		day := local.AddDate(0, 0, i)
+
+		midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+		if !midnight.Before(t) && w.contains(midnight) {
+			return midnight
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), w.start/60, w.start%60, 0, 0, time.Local)
+		if !start.Before(t) && w.contains(start) {
+			return start
+		}
+	}
+
+	// unreachable: at least one day is not excluded.
+	return t
+}
+
+// length returns the duration of the window of a day.
+func (w *renewalWindow) length() time.Duration {
+	switch {
+	case w.start == w.end:
+		return 24 * time.Hour
+	case w.start < w.end:
+		return time.Duration(w.end-w.start) * time.Minute
+	default:
+		return time.Duration(24*60-w.start+w.end) * time.Minute
+	}
+}
+
+// daemonPlan the times of the periodic checks of the daemon.
+type daemonPlan struct {
+	// delay the exact delay before the first check (e.g. resumed from the journal), the first check is immediate if 0.
+	delay time.Duration
+	// interval the time between two checks.
+	interval time.Duration
+	// jitter the maximum random delay added to each check, so the checks of a fleet are spread.
+	jitter time.Duration
+	// window the times of the checks, any time if nil.
+	window *renewalWindow
+	// scheduled is called with the time of each next check, if set.
+	scheduled func(at time.Time)
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// first returns the time of the first check.
+func (p *daemonPlan) first(now time.Time) time.Time {
+	if p.delay > 0 {
+		return now.Add(p.delay)
+	}
+
+	return p.align(now)
+}
+
+// next returns the time of the check following a check.
+func (p *daemonPlan) next(now time.Time) time.Time {
+	at := p.align(now.Add(p.interval))
+
+	if p.scheduled != nil {
+		p.scheduled(at)
+	}
+
+	return at
+}
+
+// align adds the jitter to the time, and moves it to the next window.
+func (p *daemonPlan) align(t time.Time) time.Time {
+	t = t.Add(p.randomDelay(p.jitter))
+
+	if p.window == nil {
+		return t
+	}
+
+	at := p.window.next(t)
+	if at.Equal(t) {
+		return t
+	}
+
+	// the checks are spread over the beginning of the window.
+	spread := p.jitter
+	if length := p.window.length(); spread > length {
+		spread = length
+	}
+
+	return at.Add(p.randomDelay(spread))
+}
+
+func (p *daemonPlan) randomDelay(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rand == nil {
+		p.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return time.Duration(p.rand.Int63n(int64(max)))
+}
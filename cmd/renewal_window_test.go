@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseRenewalWindow(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		hours    string
+		days     []string
+		expected *renewalWindow
+	}{
+		{
+			desc: "whole week",
+		},
+		{
+			desc:     "hours",
+			hours:    "02:00-05:30",
+			expected: &renewalWindow{start: 120, end: 330, excluded: map[time.Weekday]bool{}},
+		},
+		{
+			desc:     "across midnight",
+			hours:    "22:00-02:00",
+			expected: &renewalWindow{start: 1320, end: 120, excluded: map[time.Weekday]bool{}},
+		},
+		{
+			desc:     "excluded days",
+			days:     []string{"fri", " Sat"},
+			expected: &renewalWindow{excluded: map[time.Weekday]bool{time.Friday: true, time.Saturday: true}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			window, err := parseRenewalWindow(test.hours, test.days)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, window)
+		})
+	}
+}
+
+func Test_parseRenewalWindow_errors(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		hours string
+		days  []string
+	}{
+		{desc: "missing end", hours: "02:00"},
+		{desc: "invalid time", hours: "02:00-25:00"},
+		{desc: "invalid day", days: []string{"friday"}},
+		{desc: "all days", days: []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseRenewalWindow(test.hours, test.days)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_renewalWindow_next(t *testing.T) {
+	window, err := parseRenewalWindow("22:00-02:00", []string{"fri"})
+	require.NoError(t, err)
+
+	// 2020-01-01 is a Wednesday.
+	date := func(day, hour, minute int) time.Time {
+		return time.Date(2020, time.January, day, hour, minute, 0, 0, time.Local)
+	}
+
+	testCases := []struct {
+		desc     string
+		now      time.Time
+		expected time.Time
+	}{
+		{desc: "in the window", now: date(1, 23, 0), expected: date(1, 23, 0)},
+		{desc: "after midnight", now: date(2, 1, 59), expected: date(2, 1, 59)},
+		{desc: "before the window", now: date(1, 12, 0), expected: date(1, 22, 0)},
+		{desc: "end of the window", now: date(2, 2, 0), expected: date(2, 22, 0)},
+		{desc: "excluded day", now: date(3, 12, 0), expected: date(4, 0, 0)},
+		{desc: "after midnight of an excluded day", now: date(3, 1, 0), expected: date(4, 0, 0)},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, window.next(test.now))
+		})
+	}
+}
+
+func Test_daemonPlan(t *testing.T) {
+	window, err := parseRenewalWindow("02:00-05:00", nil)
+	require.NoError(t, err)
+
+	var scheduled []time.Time
+
+	plan := &daemonPlan{
+		interval:  12 * time.Hour,
+		jitter:    time.Hour,
+		window:    window,
+		scheduled: func(at time.Time) { scheduled = append(scheduled, at) },
+		rand:      rand.New(rand.NewSource(1)),
+	}
+
+	now := time.Date(2020, time.January, 1, 3, 0, 0, 0, time.Local)
+
+	first := plan.first(now)
+	assert.True(t, !first.Before(now) && first.Before(now.Add(time.Hour)), first)
+
+	// 15:00 is out of the window: the check is spread over the first hour of the next window.
+	next := plan.next(now)
+	start := time.Date(2020, time.January, 2, 2, 0, 0, 0, time.Local)
+	assert.True(t, !next.Before(start) && next.Before(start.Add(time.Hour)), next)
+
+	assert.Equal(t, []time.Time{next}, scheduled)
+}
+
+func Test_daemonPlan_delay(t *testing.T) {
+	plan := &daemonPlan{delay: 10 * time.Minute, interval: time.Hour, jitter: time.Hour}
+
+	now := time.Now()
+
+	assert.Equal(t, now.Add(10*time.Minute), plan.first(now))
+}
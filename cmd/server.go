@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// serverPresets the names accepted by the "server" option instead of a directory URL.
+var serverPresets = map[string]string{
+	"le-prod":         lego.LEDirectoryProduction,
+	"le-staging":      lego.LEDirectoryStaging,
+	"zerossl":         "https://acme.zerossl.com/v2/DV90",
+	"buypass":         "https://api.buypass.com/acme/directory",
+	"buypass-staging": "https://api.test4.buypass.no/acme/directory",
+}
+
+// stagingServers the directory URLs of the known test environments.
+var stagingServers = map[string]bool{
+	lego.LEDirectoryStaging:                       true,
+	"https://api.test4.buypass.no/acme/directory": true,
+}
+
+// resolveServerPreset replaces a preset name of the "server" option by the directory URL of the CA.
+func resolveServerPreset(ctx *cli.Context) error {
+	server, ok := serverPresets[ctx.GlobalString("server")]
+	if !ok {
+		return nil
+	}
+
+	return ctx.GlobalSet("server", server)
+}
+
+// serverPath returns the name of the storage directory of a CA server (e.g. acme-v02.api.letsencrypt.org, localhost_14000).
+func serverPath(server string) (string, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.NewReplacer(":", "_", "/", string(os.PathSeparator)).Replace(serverURL.Host), nil
+}
+
+// isStagingServer returns true if the server is a test environment: its certificates are not trusted.
+func isStagingServer(server string) bool {
+	if stagingServers[server] {
+		return true
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(serverURL.Host, "staging")
+}
+
+func serverEnvironment(server string) string {
+	if isStagingServer(server) {
+		return "staging"
+	}
+	return "production"
+}
+
+// checkServerEnvironment warns when the certificate of a domain, obtained from a staging server, replaces one obtained from a production server, or the opposite.
+// The server of the previous certificate is read from its renewal metadata.
+func checkServerEnvironment(certsStorage *CertificatesStorage, domain, server string) {
+	metadata, err := readRenewalMetadata(certsStorage, domain)
+	if err != nil {
+		log.Warnf("[%s] Could not read the server of the previous certificate: %v", domain, err)
+		return
+	}
+	if metadata == nil || metadata.Server == "" {
+		return
+	}
+
+	if isStagingServer(metadata.Server) == isStagingServer(server) {
+		return
+	}
+
+	log.Warnf("[%s] The certificate was obtained from a %s server (%s), it is replaced by a certificate of a %s server (%s). "+
+		"Use another --path, or --server.namespace to store the certificates of each CA in their own directory.",
+		domain, serverEnvironment(metadata.Server), metadata.Server, serverEnvironment(server), server)
+}
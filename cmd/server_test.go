@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	stdlog "log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func Test_resolveServerPreset(t *testing.T) {
+	testCases := []struct {
+		server   string
+		expected string
+	}{
+		{server: "le-staging", expected: lego.LEDirectoryStaging},
+		{server: "zerossl", expected: "https://acme.zerossl.com/v2/DV90"},
+		{server: "https://localhost:14000/dir", expected: "https://localhost:14000/dir"},
+	}
+
+	for _, test := range testCases {
+		runWithFlags(t, []string{"--server", test.server, "renew"}, func(ctx *cli.Context) {
+			require.NoError(t, resolveServerPreset(ctx))
+			assert.Equal(t, test.expected, ctx.GlobalString("server"))
+		})
+	}
+}
+
+func Test_isStagingServer(t *testing.T) {
+	assert.True(t, isStagingServer(lego.LEDirectoryStaging))
+	assert.True(t, isStagingServer(serverPresets["buypass-staging"]))
+	assert.True(t, isStagingServer("https://acme.staging.example.com/directory"))
+	assert.False(t, isStagingServer(lego.LEDirectoryProduction))
+	assert.False(t, isStagingServer(serverPresets["zerossl"]))
+}
+
+func Test_checkServerEnvironment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-server")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	runWithFlags(t, []string{"--path", dir, "--server", lego.LEDirectoryProduction, "renew"}, func(ctx *cli.Context) {
+		certsStorage := NewCertificatesStorage(ctx)
+		certsStorage.CreateRootFolder()
+
+		saveRenewalMetadata(ctx, certsStorage, "example.com", []string{"example.com"})
+
+		metadata, err := readRenewalMetadata(certsStorage, "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, lego.LEDirectoryProduction, metadata.Server)
+
+		backupLogger := log.Logger
+		defer func() {
+			log.Logger = backupLogger
+		}()
+
+		logs := &bytes.Buffer{}
+		log.Logger = stdlog.New(logs, "", 0)
+
+		checkServerEnvironment(certsStorage, "example.com", lego.LEDirectoryProduction)
+		assert.Empty(t, logs.String())
+
+		// the certificates of the other domains are not affected.
+		checkServerEnvironment(certsStorage, "example.org", lego.LEDirectoryStaging)
+		assert.Empty(t, logs.String())
+
+		checkServerEnvironment(certsStorage, "example.com", lego.LEDirectoryStaging)
+		assert.Contains(t, logs.String(), "obtained from a production server")
+	})
+}
+
+func TestNewCertificatesStorage_namespace(t *testing.T) {
+	runWithFlags(t, []string{"--path", "/tmp/.lego", "--server", "https://localhost:14000/dir", "--server.namespace", "renew"}, func(ctx *cli.Context) {
+		certsStorage := NewCertificatesStorage(ctx)
+
+		assert.Equal(t, filepath.Join("/tmp/.lego", "certificates", "localhost_14000"), certsStorage.GetRootPath())
+		assert.Equal(t, filepath.Join("/tmp/.lego", "archives", "localhost_14000"), certsStorage.archivePath)
+	})
+}
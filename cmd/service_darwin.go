@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path/filepath"
+	"text/template"
+
+	"github.com/go-acme/lego/v3/log"
+)
+
+const launchdPlistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{ xml .Label }}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{ xml .Executable }}</string>
+{{- range .Args }}
+		<string>{{ xml . }}</string>
+{{- end }}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>{{ xml .LogFile }}</string>
+	<key>StandardErrorPath</key>
+	<string>{{ xml .LogFile }}</string>
+</dict>
+</plist>
+`
+
+func installService(opts serviceOptions) error {
+	data := struct {
+		serviceOptions
+		Label string
+	}{
+		serviceOptions: opts,
+		Label:          launchdLabel(opts),
+	}
+
+	tmpl := template.New("launchd").Funcs(template.FuncMap{
+		"xml": func(s string) (string, error) {
+			b := &bytes.Buffer{}
+			err := xml.EscapeText(b, []byte(s))
+			return b.String(), err
+		},
+	})
+
+	buf := &bytes.Buffer{}
+	err := template.Must(tmpl.Parse(launchdPlistTemplate)).Execute(buf, data)
+	if err != nil {
+		return err
+	}
+
+	path := launchdPlistPath(opts)
+
+	err = writeUnitFile(path, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if opts.Output != "-" {
+		log.Printf("Load the service with: launchctl load -w %s", path)
+	}
+
+	return nil
+}
+
+func uninstallService(opts serviceOptions) error {
+	path := launchdPlistPath(opts)
+
+	log.Printf("Unload the service before with: launchctl unload -w %s", path)
+
+	return removeUnitFile(path)
+}
+
+func launchdLabel(opts serviceOptions) string {
+	return "com.github.go-acme." + opts.Name
+}
+
+func launchdPlistPath(opts serviceOptions) string {
+	if opts.Output != "" {
+		return opts.Output
+	}
+	return filepath.Join("/Library/LaunchDaemons", launchdLabel(opts)+".plist")
+}
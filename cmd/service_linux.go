@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/go-acme/lego/v3/log"
+)
+
+const systemdUnitTemplate = `[Unit]
+Description=lego ACME client ({{ .Name }})
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart={{ .ExecStart }}
+{{- if .EnvFile }}
+EnvironmentFile={{ .EnvFile }}
+{{- end }}
+Restart=on-failure
+RestartSec=60
+
+[Install]
+WantedBy=multi-user.target
+`
+
+func installService(opts serviceOptions) error {
+	var parts []string
+	for _, arg := range append([]string{opts.Executable}, opts.Args...) {
+		parts = append(parts, strconv.Quote(arg))
+	}
+
+	data := struct {
+		serviceOptions
+		ExecStart string
+	}{
+		serviceOptions: opts,
+		ExecStart:      strings.Join(parts, " "),
+	}
+
+	buf := &bytes.Buffer{}
+	err := template.Must(template.New("systemd").Parse(systemdUnitTemplate)).Execute(buf, data)
+	if err != nil {
+		return err
+	}
+
+	err = writeUnitFile(systemdUnitPath(opts), buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if opts.Output != "-" {
+		log.Printf("Enable the service with: systemctl daemon-reload && systemctl enable --now %s", opts.Name)
+	}
+
+	return nil
+}
+
+func uninstallService(opts serviceOptions) error {
+	log.Printf("Disable the service before with: systemctl disable --now %s", opts.Name)
+
+	return removeUnitFile(systemdUnitPath(opts))
+}
+
+func systemdUnitPath(opts serviceOptions) string {
+	if opts.Output != "" {
+		return opts.Output
+	}
+	return filepath.Join("/etc/systemd/system", opts.Name+".service")
+}
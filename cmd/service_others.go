@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func installService(_ serviceOptions) error {
+	return fmt.Errorf("services are not supported on %s", runtime.GOOS)
+}
+
+func uninstallService(_ serviceOptions) error {
+	return fmt.Errorf("services are not supported on %s", runtime.GOOS)
+}
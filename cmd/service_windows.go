@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-acme/lego/v3/log"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func installService(opts serviceOptions) error {
+	if opts.Output != "" {
+		return errors.New("the output option is not supported by Windows services")
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	s, err := m.OpenService(opts.Name)
+	if err == nil {
+		_ = s.Close()
+		return fmt.Errorf("service %s already exists", opts.Name)
+	}
+
+	config := mgr.Config{
+		DisplayName: "lego ACME client (" + opts.Name + ")",
+		Description: "Renews the certificates managed by lego.",
+		StartType:   mgr.StartAutomatic,
+	}
+
+	s, err = m.CreateService(opts.Name, opts.Executable, config, opts.Args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	err = eventlog.InstallAsEventCreate(opts.Name, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil {
+		_ = s.Delete()
+		return fmt.Errorf("failed to setup the event log: %w", err)
+	}
+
+	log.Printf("The service %s has been installed, start it with: sc.exe start %s", opts.Name, opts.Name)
+
+	return nil
+}
+
+func uninstallService(opts serviceOptions) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	s, err := m.OpenService(opts.Name)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", opts.Name)
+	}
+	defer func() { _ = s.Close() }()
+
+	err = s.Delete()
+	if err != nil {
+		return err
+	}
+
+	err = eventlog.Remove(opts.Name)
+	if err != nil {
+		return fmt.Errorf("failed to remove the event log: %w", err)
+	}
+
+	log.Printf("The service %s has been uninstalled", opts.Name)
+
+	return nil
+}
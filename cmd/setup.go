@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
@@ -10,6 +12,7 @@ import (
 	"time"
 
 	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
 	"github.com/go-acme/lego/v3/lego"
 	"github.com/go-acme/lego/v3/log"
 	"github.com/go-acme/lego/v3/registration"
@@ -19,8 +22,11 @@ import (
 const filePerm os.FileMode = 0600
 
 func setup(ctx *cli.Context, accountsStorage *AccountsStorage) (*Account, *lego.Client) {
+	applyAccountPreferences(ctx, accountsStorage)
+
 	keyType := getKeyType(ctx)
-	privateKey := accountsStorage.GetPrivateKey(keyType)
+	privateKey := getAccountKey(ctx, accountsStorage, getAccountKeyType(ctx))
+	checkAccountKeyType(ctx, accountsStorage, privateKey)
 
 	var account *Account
 	if accountsStorage.ExistsAccountFilePath() {
@@ -31,9 +37,61 @@ func setup(ctx *cli.Context, accountsStorage *AccountsStorage) (*Account, *lego.
 
 	client := newClient(ctx, account, keyType)
 
+	if account.Registration != nil {
+		handleTOSUpdate(ctx, client, account, accountsStorage)
+	}
+
 	return account, client
 }
 
+// handleTOSUpdate requires the agreement of the terms of service when they have changed since the last agreement of the account.
+func handleTOSUpdate(ctx *cli.Context, client *lego.Client, account *Account, accountsStorage *AccountsStorage) {
+	tosURL := client.GetToSURL()
+	if tosURL == "" || tosURL == account.TermsOfService {
+		return
+	}
+
+	// the accounts created before the recording of the terms of service.
+	if account.TermsOfService == "" {
+		account.TermsOfService = tosURL
+
+		if err := accountsStorage.Save(account); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	log.Printf("The terms of service have changed: %s (previously agreed: %s)", tosURL, account.TermsOfService)
+
+	// the CA doesn't ask for a new agreement: the new terms of service are only recorded.
+	if client.GetQuirks().ImplicitTOSUpdate {
+		account.TermsOfService = tosURL
+
+		if err := accountsStorage.Save(account); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if !ctx.GlobalBool("accept-tos-update") {
+		log.Fatal("Please review the new terms of service and use --accept-tos-update to accept them.")
+	}
+
+	reg, err := client.Registration.AgreeToTermsOfService()
+	if err != nil {
+		log.Fatalf("Could not accept the new terms of service: %v", err)
+	}
+
+	account.Registration = reg
+	account.TermsOfService = tosURL
+
+	if err = accountsStorage.Save(account); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("The new terms of service have been accepted.")
+}
+
 func newClient(ctx *cli.Context, acc registration.User, keyType certcrypto.KeyType) *lego.Client {
 	config := lego.NewConfig(acc)
 	config.CADirURL = ctx.GlobalString("server")
@@ -41,6 +99,7 @@ func newClient(ctx *cli.Context, acc registration.User, keyType certcrypto.KeyTy
 	config.Certificate = lego.CertificateConfig{
 		KeyType: keyType,
 		Timeout: time.Duration(ctx.GlobalInt("cert.timeout")) * time.Second,
+		Policy:  getPolicy(ctx),
 	}
 	config.UserAgent = fmt.Sprintf("lego-cli/%s", ctx.App.Version)
 
@@ -48,24 +107,111 @@ func newClient(ctx *cli.Context, acc registration.User, keyType certcrypto.KeyTy
 		config.HTTPClient.Timeout = time.Duration(ctx.GlobalInt("http-timeout")) * time.Second
 	}
 
-	client, err := lego.NewClient(config)
+	pins, err := getServerPins(ctx.GlobalStringSlice("server.pin"))
+	if err != nil {
+		log.Fatalf("Invalid --server.pin: %v", err)
+	}
+	config.DirectoryPins = pins
+
+	if ttl := ctx.GlobalDuration("directory.ttl"); ttl > 0 {
+		config.DirectoryCache = newFileDirectoryCache(ctx.GlobalString("path"))
+		config.DirectoryCacheTTL = ttl
+	}
+
+	var client *lego.Client
+	err = retryDuringMaintenance(maintenanceWait(ctx), func() error {
+		var err error
+		client, err = lego.NewClient(config)
+		return err
+	})
 	if err != nil {
-		log.Fatalf("Could not create client: %v", err)
+		fatalf(err, "Could not create client: %v", err)
 	}
 
+	checkClockSkew(ctx, client)
+
 	if client.GetExternalAccountRequired() && !ctx.GlobalIsSet("eab") {
+		if url := client.GetQuirks().EABCredentialsURL; url != "" {
+			log.Fatalf("Server requires External Account Binding. Use --eab with --kid and --hmac (credentials: %s).", url)
+		}
 		log.Fatal("Server requires External Account Binding. Use --eab with --kid and --hmac.")
 	}
 
 	return client
 }
 
+// getPolicy returns the policy restricting the certificates (--policy.*), nil if there is none.
+func getPolicy(ctx *cli.Context) *certificate.Policy {
+	policy := &certificate.Policy{
+		Allow:   ctx.GlobalStringSlice("policy.allow"),
+		Deny:    ctx.GlobalStringSlice("policy.deny"),
+		MaxSANs: ctx.GlobalInt("policy.max-sans"),
+	}
+
+	for _, keyType := range ctx.GlobalStringSlice("policy.key-types") {
+		policy.KeyTypes = append(policy.KeyTypes, parseKeyType(keyType))
+	}
+
+	for _, keyType := range ctx.GlobalStringSlice("policy.min-key-types") {
+		policy.MinKeyTypes = append(policy.MinKeyTypes, parseKeyType(keyType))
+	}
+
+	if len(policy.Allow) == 0 && len(policy.Deny) == 0 && policy.MaxSANs == 0 && len(policy.KeyTypes) == 0 && len(policy.MinKeyTypes) == 0 {
+		return nil
+	}
+
+	return policy
+}
+
+// getServerPins returns the SPKI pins of the CA server (--server.pin):
+// the values are pins (base64 SHA-256 hashes), or PEM files of certificates.
+func getServerPins(values []string) ([]string, error) {
+	var pins []string
+
+	for _, value := range values {
+		if raw, err := base64.StdEncoding.DecodeString(value); err == nil && len(raw) == sha256.Size {
+			pins = append(pins, value)
+			continue
+		}
+
+		bundle, err := ioutil.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither a base64 SHA-256 hash nor a readable PEM file: %w", value, err)
+		}
+
+		certificates, err := certcrypto.ParsePEMBundle(bundle)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", value, err)
+		}
+
+		for _, cert := range certificates {
+			pins = append(pins, certcrypto.SPKIPin(cert))
+		}
+	}
+
+	return pins, nil
+}
+
 // getKeyType the type from which private keys should be generated
 func getKeyType(ctx *cli.Context) certcrypto.KeyType {
-	keyType := ctx.GlobalString("key-type")
+	return parseKeyType(ctx.GlobalString("key-type"))
+}
+
+// getAccountKeyType the type from which the account keys should be generated: by default, the type of the private keys.
+func getAccountKeyType(ctx *cli.Context) certcrypto.KeyType {
+	if !ctx.GlobalIsSet("account-key-type") {
+		return getKeyType(ctx)
+	}
+
+	return parseKeyType(ctx.GlobalString("account-key-type"))
+}
+
+func parseKeyType(keyType string) certcrypto.KeyType {
 	switch strings.ToUpper(keyType) {
 	case "RSA2048":
 		return certcrypto.RSA2048
+	case "RSA3072":
+		return certcrypto.RSA3072
 	case "RSA4096":
 		return certcrypto.RSA4096
 	case "RSA8192":
@@ -88,6 +234,57 @@ func getEmail(ctx *cli.Context) string {
 	return email
 }
 
+// getDomains returns the domains of the certificate (see normalizedDomains), and their wildcards with "with-wildcard".
+func getDomains(ctx *cli.Context) []string {
+	domains := normalizedDomains(ctx)
+	if len(domains) == 0 || !ctx.GlobalBool("with-wildcard") {
+		return domains
+	}
+
+	return withWildcards(domains)
+}
+
+// normalizedDomains returns the domains of the command line, normalized (see certificate.NormalizeDomains).
+// The internationalized domains are converted to A-labels (punycode) here, once:
+// the A-labels are used everywhere else (identifiers, storage filenames, DNS providers), the U-labels are only displayed.
+func normalizedDomains(ctx *cli.Context) []string {
+	domains := ctx.GlobalStringSlice("domains")
+	if len(domains) == 0 {
+		return nil
+	}
+
+	// all the invalid domains are reported before any request to the CA.
+	domains, err := certificate.NormalizeDomains(domains)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return domains
+}
+
+// withWildcards adds the wildcard of each domain after it, without duplicates.
+func withWildcards(domains []string) []string {
+	var expanded []string
+
+	seen := make(map[string]bool)
+	add := func(domain string) {
+		if !seen[domain] {
+			seen[domain] = true
+			expanded = append(expanded, domain)
+		}
+	}
+
+	for _, domain := range domains {
+		add(domain)
+
+		if !strings.HasPrefix(domain, "*.") {
+			add("*." + domain)
+		}
+	}
+
+	return expanded
+}
+
 func createNonExistingFolder(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return os.MkdirAll(path, 0700)
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
 	"strings"
 	"time"
 
+	"github.com/go-acme/lego/v3/certcrypto"
 	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/challenge/dns01"
 	"github.com/go-acme/lego/v3/challenge/http01"
+	"github.com/go-acme/lego/v3/challenge/resolver"
 	"github.com/go-acme/lego/v3/challenge/tlsalpn01"
 	"github.com/go-acme/lego/v3/lego"
 	"github.com/go-acme/lego/v3/log"
@@ -18,12 +25,21 @@ import (
 )
 
 func setupChallenges(ctx *cli.Context, client *lego.Client) {
-	if !ctx.GlobalBool("http") && !ctx.GlobalBool("tls") && !ctx.GlobalIsSet("dns") {
-		log.Fatal("No challenge selected. You must specify at least one challenge: `--http`, `--tls`, `--dns`.")
+	if !ctx.GlobalBool("http") && !ctx.GlobalBool("tls") && !ctx.GlobalIsSet("dns") && !ctx.GlobalIsSet("onion.key") {
+		log.Fatal("No challenge selected. You must specify at least one challenge: `--http`, `--tls`, `--dns`, `--onion.key`.")
 	}
 
+	var httpProvider challenge.Provider
+
 	if ctx.GlobalBool("http") {
-		err := client.Challenge.SetHTTP01Provider(setupHTTPProvider(ctx))
+		provider := wrapProvider(ctx, setupHTTPProvider(ctx))
+		httpProvider = provider
+
+		if ctx.GlobalBool("http.probe") {
+			probeHTTP(ctx, provider)
+		}
+
+		err := client.Challenge.SetHTTP01Provider(provider)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -39,10 +55,119 @@ func setupChallenges(ctx *cli.Context, client *lego.Client) {
 	if ctx.GlobalIsSet("dns") {
 		setupDNS(ctx, client)
 	}
+
+	if ctx.GlobalIsSet("onion.key") {
+		err := client.Challenge.SetOnionCSR01Provider(loadOnionKey(ctx.GlobalString("onion.key")))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	setupChallengeSelector(ctx, client, httpProvider)
+
+	if hook := ctx.GlobalString("challenge-hook"); hook != "" {
+		client.Challenge.SetEventHandler(challengeHook(hook))
+	}
+}
+
+// setupChallengeSelector sets how the challenge of each domain is chosen among the enabled challenges.
+// The automatic selection probes the HTTP challenge of each domain (httpProvider) through the reflection endpoint of --http.probe-url.
+func setupChallengeSelector(ctx *cli.Context, client *lego.Client, httpProvider challenge.Provider) {
+	enabled := enabledChallenges(ctx)
+	if len(enabled) > 1 {
+		log.Infof("Enabled challenges: %s", strings.Join(enabled, ", "))
+	}
+
+	if ctx.GlobalBool("auto-challenge") && ctx.GlobalIsSet("challenge.order") {
+		log.Fatal("The automatic challenge selection (`--auto-challenge`) and the order of the challenges (`--challenge.order`) are exclusive.")
+	}
+
+	if ctx.GlobalBool("auto-challenge") {
+		if len(enabled) < 2 {
+			log.Fatal("The automatic challenge selection (`--auto-challenge`) requires at least two of `--http`, `--tls` and `--dns`.")
+		}
+
+		if httpProvider == nil || !ctx.GlobalIsSet("http.probe-url") {
+			log.Fatal("The automatic challenge selection (`--auto-challenge`) requires `--http` and a reflection endpoint (`--http.probe-url`) " +
+				"to check the HTTP challenge from the internet. Use `--challenge.order` to choose the challenges without probe.")
+		}
+
+		probe := http01.ReflectionProbe(&http.Client{Timeout: 15 * time.Second}, ctx.GlobalString("http.probe-url"))
+
+		client.Challenge.SetChallengeSelector(resolver.NewAutoSelector(func(domain string) error {
+			return http01.Probe(domain, httpProvider, probe)
+		}))
+		return
+	}
+
+	if ctx.GlobalIsSet("challenge.order") {
+		order, err := parseChallengeOrder(ctx.GlobalStringSlice("challenge.order"))
+		if err != nil {
+			log.Fatalf("Invalid --challenge.order: %v", err)
+		}
+
+		client.Challenge.SetChallengeSelector(resolver.NewOrderSelector(order...))
+	}
+}
+
+// enabledChallenges returns the types of the enabled challenges, which can be chosen for each domain.
+func enabledChallenges(ctx *cli.Context) []string {
+	var enabled []string
+	if ctx.GlobalBool("http") {
+		enabled = append(enabled, string(challenge.HTTP01))
+	}
+	if ctx.GlobalBool("tls") {
+		enabled = append(enabled, string(challenge.TLSALPN01))
+	}
+	if ctx.GlobalIsSet("dns") {
+		enabled = append(enabled, string(challenge.DNS01))
+	}
+	return enabled
+}
+
+// parseChallengeOrder parses the challenge types of the --challenge.order option (http, tls, dns, or the ACME names, e.g. http-01).
+func parseChallengeOrder(values []string) ([]challenge.Type, error) {
+	var order []challenge.Type
+
+	for _, value := range values {
+		switch strings.ToLower(value) {
+		case "http", string(challenge.HTTP01):
+			order = append(order, challenge.HTTP01)
+		case "tls", string(challenge.TLSALPN01):
+			order = append(order, challenge.TLSALPN01)
+		case "dns", string(challenge.DNS01):
+			order = append(order, challenge.DNS01)
+		default:
+			return nil, fmt.Errorf("unsupported challenge %q, supported: http, tls, dns", value)
+		}
+	}
+
+	return order, nil
+}
+
+func loadOnionKey(filename string) crypto.Signer {
+	keyBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Fatalf("Could not read the onion service key: %v", err)
+	}
+
+	key, err := certcrypto.ParsePEMPrivateKey(keyBytes)
+	if err != nil {
+		log.Fatalf("Could not parse the onion service key: %v", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		log.Fatalf("The onion service key cannot be used to sign: %T", key)
+	}
+
+	return signer
 }
 
 func setupHTTPProvider(ctx *cli.Context) challenge.Provider {
 	switch {
+	case ctx.GlobalIsSet("challenge-agent"):
+		return getChallengeAgentProvider(ctx, challenge.HTTP01)
 	case ctx.GlobalIsSet("http.webroot"):
 		ps, err := webroot.NewHTTPProvider(ctx.GlobalString("http.webroot"))
 		if err != nil {
@@ -66,23 +191,56 @@ func setupHTTPProvider(ctx *cli.Context) challenge.Provider {
 			log.Fatal(err)
 		}
 
-		srv := http01.NewProviderServer(host, port)
-		if header := ctx.GlobalString("http.proxy-header"); header != "" {
-			srv.SetProxyHeader(header)
-		}
-		return srv
+		return newHTTPProviderServer(ctx, host, port)
 	case ctx.GlobalBool("http"):
-		srv := http01.NewProviderServer("", "")
-		if header := ctx.GlobalString("http.proxy-header"); header != "" {
-			srv.SetProxyHeader(header)
-		}
-		return srv
+		return newHTTPProviderServer(ctx, "", "")
 	default:
 		log.Fatal("Invalid HTTP challenge options.")
 		return nil
 	}
 }
 
+// newHTTPProviderServer creates the standalone server of the HTTP-01 challenge.
+func newHTTPProviderServer(ctx *cli.Context, host, port string) *http01.ProviderServer {
+	srv := http01.NewProviderServer(host, port)
+	if header := ctx.GlobalString("http.proxy-header"); header != "" {
+		srv.SetProxyHeader(header)
+	}
+
+	srv.SetAccessLog(ctx.GlobalBool("http.access-log"))
+	srv.SetRateLimit(ctx.GlobalInt("http.rate-limit"), time.Minute)
+
+	if ctx.GlobalIsSet("http.metrics") {
+		serverMetricsFile(srv, ctx.GlobalString("http.metrics"))
+	}
+
+	return srv
+}
+
+// probeHTTP checks that the HTTP-01 challenge of the domains is reachable, and stops at the first failure.
+func probeHTTP(ctx *cli.Context, provider challenge.Provider) {
+	// a request from this host doesn't go through the firewall and NAT rules applied to the traffic from the internet.
+	if !ctx.GlobalIsSet("http.probe-url") {
+		log.Fatal("The HTTP challenge probe (`--http.probe`) requires a reflection endpoint hosted outside of the network (`--http.probe-url`).")
+	}
+
+	probe := http01.ReflectionProbe(&http.Client{Timeout: 15 * time.Second}, ctx.GlobalString("http.probe-url"))
+
+	for _, domain := range getDomains(ctx) {
+		// the wildcards can only be validated with the DNS challenge.
+		if strings.HasPrefix(domain, "*.") {
+			continue
+		}
+
+		err := http01.Probe(domain, provider, probe)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Infof("[%s] probe: the HTTP challenge is reachable", domain)
+	}
+}
+
 func setupTLSProvider(ctx *cli.Context) challenge.Provider {
 	switch {
 	case ctx.GlobalIsSet("tls.port"):
@@ -106,21 +264,125 @@ func setupTLSProvider(ctx *cli.Context) challenge.Provider {
 }
 
 func setupDNS(ctx *cli.Context, client *lego.Client) {
-	provider, err := dns.NewDNSChallengeProviderByName(ctx.GlobalString("dns"))
+	provider, err := getDNSProvider(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	servers := ctx.GlobalStringSlice("dns.resolvers")
+	provider = wrapProvider(ctx, provider)
+
+	if ctx.GlobalIsSet("dns.metrics") {
+		dns01.AddProviderObserver(providerMetricsFile(ctx.GlobalString("dns.metrics")))
+	}
+
+	var propagationTuning dns01.ChallengeOption
+	if ctx.GlobalBool("dns.auto-timeout") {
+		history, save, errH := loadPropagationHistory(ctx.GlobalString("path"), ctx.GlobalString("dns"))
+		if errH != nil {
+			log.Fatalf("Could not load the propagation history: %v", errH)
+		}
+
+		propagationTuning = dns01.AutoTunePropagation(history, save)
+	}
+
+	tcpMode, err := dns01.ParseTCPMode(ctx.GlobalString("dns.tcp"))
+	if err != nil {
+		log.Fatalf("Invalid --dns.tcp: %v", err)
+	}
+
+	ednsSize := ctx.GlobalInt("dns.edns-size")
+	if ednsSize != 0 && (ednsSize < 512 || ednsSize > 65535) {
+		log.Fatalf("Invalid --dns.edns-size %d: 0 (disabled), or between 512 and 65535", ednsSize)
+	}
+
+	config := dns01.DefaultChallengeConfig()
+	if servers := ctx.GlobalStringSlice("dns.resolvers"); len(servers) > 0 {
+		config.RecursiveNameservers = dns01.ParseNameservers(servers)
+	}
+	if ctx.GlobalIsSet("dns-timeout") {
+		config.Timeout = time.Duration(ctx.GlobalInt("dns-timeout")) * time.Second
+	}
+	config.Retries = ctx.GlobalInt("dns.retries")
+	config.TCPMode = tcpMode
+	config.EDNSBufferSize = uint16(ednsSize)
+
+	// the zone lookups of the DNS providers use the default configuration.
+	err = dns01.SetDefaultChallengeConfig(config)
+	if err != nil {
+		log.Fatalf("Invalid DNS configuration: %v", err)
+	}
+
 	err = client.Challenge.SetDNS01Provider(provider,
-		dns01.CondOption(len(servers) > 0,
-			dns01.AddRecursiveNameservers(dns01.ParseNameservers(ctx.GlobalStringSlice("dns.resolvers")))),
+		dns01.WithConfig(config),
 		dns01.CondOption(ctx.GlobalBool("dns.disable-cp"),
 			dns01.DisableCompletePropagationRequirement()),
-		dns01.CondOption(ctx.GlobalIsSet("dns-timeout"),
-			dns01.AddDNSTimeout(time.Duration(ctx.GlobalInt("dns-timeout"))*time.Second)),
+		dns01.CondOption(ctx.GlobalBool("dns.check-delegation"),
+			dns01.CheckDelegation()),
+		dns01.CondOption(ctx.GlobalBool("dns.verify-cleanup"),
+			dns01.VerifyCleanUp(ctx.GlobalInt("dns.cleanup-retry"))),
+		dns01.CondOption(propagationTuning != nil, propagationTuning),
+		dns01.CondOption(ctx.GlobalBool("dns.continue-on-timeout"),
+			dns01.ContinueOnPropagationTimeout()),
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// wrapProvider wraps a challenge provider with the middlewares selected by the provider.* options.
+// The dry-run is the outermost middleware: the calls are logged, and neither done nor retried.
+func wrapProvider(ctx *cli.Context, provider challenge.Provider) challenge.Provider {
+	var middlewares []challenge.Middleware
+
+	if timeout := ctx.GlobalDuration("provider.timeout"); timeout > 0 {
+		middlewares = append(middlewares, func(p challenge.Provider) challenge.Provider {
+			return challenge.WithTimeout(p, timeout)
+		})
+	}
+
+	if retries := ctx.GlobalInt("provider.retries"); retries > 0 {
+		delay := ctx.GlobalDuration("provider.retry-delay")
+		middlewares = append(middlewares, func(p challenge.Provider) challenge.Provider {
+			return challenge.WithRetry(p, retries+1, delay)
+		})
+	}
+
+	if ctx.GlobalBool("provider.log") {
+		middlewares = append(middlewares, challenge.WithLogging)
+	}
+
+	if ctx.GlobalBool("provider.dry-run") {
+		middlewares = append(middlewares, challenge.WithDryRun)
+	}
+
+	return challenge.Wrap(provider, middlewares...)
+}
+
+func getDNSProvider(ctx *cli.Context) (challenge.Provider, error) {
+	if ctx.GlobalIsSet("challenge-agent") {
+		// the provider, and its credentials, are configured on the agent.
+		return getChallengeAgentProvider(ctx, challenge.DNS01), nil
+	}
+
+	var provider challenge.Provider
+	var err error
+
+	if ctx.GlobalIsSet("dns.fallback") {
+		provider, err = dns.NewDNSChallengeProviderWithFallback(ctx.GlobalString("dns"), ctx.GlobalString("dns.fallback"))
+	} else {
+		provider, err = dns.NewDNSChallengeProviderByName(ctx.GlobalString("dns"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !ctx.GlobalIsSet("dns.delegate") {
+		return provider, nil
+	}
+
+	if !ctx.GlobalIsSet("dns.delegate-dns") {
+		return nil, errors.New("the provider of the delegated zones must be defined with --dns.delegate-dns")
+	}
+
+	return dns.NewDNSChallengeProviderWithDelegation(provider, ctx.GlobalString("dns.delegate-dns"), ctx.GlobalStringSlice("dns.delegate"))
+}
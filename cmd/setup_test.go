@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func Test_withWildcards(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		domains  []string
+		expected []string
+	}{
+		{
+			desc:     "apex",
+			domains:  []string{"example.com"},
+			expected: []string{"example.com", "*.example.com"},
+		},
+		{
+			desc:     "several domains",
+			domains:  []string{"example.com", "example.org"},
+			expected: []string{"example.com", "*.example.com", "example.org", "*.example.org"},
+		},
+		{
+			desc:     "apex and wildcard",
+			domains:  []string{"example.com", "*.example.com"},
+			expected: []string{"example.com", "*.example.com"},
+		},
+		{
+			desc:     "wildcard only",
+			domains:  []string{"*.example.com"},
+			expected: []string{"*.example.com"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, withWildcards(test.domains))
+		})
+	}
+}
+
+func Test_getDomains(t *testing.T) {
+	runWithFlags(t, []string{"-d", "Bücher.example", "-d", "*.bücher.example", "-d", "example.com", "--with-wildcard", "renew"}, func(ctx *cli.Context) {
+		expected := []string{"xn--bcher-kva.example", "*.xn--bcher-kva.example", "example.com"}
+		assert.Equal(t, expected, normalizedDomains(ctx))
+
+		domains := getDomains(ctx)
+		assert.Equal(t, []string{"xn--bcher-kva.example", "*.xn--bcher-kva.example", "example.com", "*.example.com"}, domains)
+
+		// the domains of the certificates are A-labels.
+		assert.Equal(t, domains, merge([]string{"xn--bcher-kva.example"}, domains))
+	})
+}
+
+func Test_getServerPins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-pins")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	certPEM, err := certcrypto.GeneratePemCert(privateKey, "acme.example.com", nil)
+	require.NoError(t, err)
+
+	cert, err := certcrypto.ParsePEMCertificate(certPEM)
+	require.NoError(t, err)
+
+	file := filepath.Join(dir, "ca.pem")
+	require.NoError(t, ioutil.WriteFile(file, certPEM, 0600))
+
+	pin := "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+
+	pins, err := getServerPins([]string{pin, file})
+	require.NoError(t, err)
+	assert.Equal(t, []string{pin, certcrypto.SPKIPin(cert)}, pins)
+
+	_, err = getServerPins([]string{"not-a-pin"})
+	require.Error(t, err)
+}
+
+func Test_parseChallengeOrder(t *testing.T) {
+	order, err := parseChallengeOrder([]string{"http", "TLS", "dns-01"})
+	require.NoError(t, err)
+	assert.Equal(t, []challenge.Type{challenge.HTTP01, challenge.TLSALPN01, challenge.DNS01}, order)
+
+	_, err = parseChallengeOrder([]string{"http", "onion"})
+	require.EqualError(t, err, `unsupported challenge "onion", supported: http, tls, dns`)
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/urfave/cli"
+)
+
+// Mozilla "intermediate" configuration.
+const snippetCiphers = "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:ECDHE-ECDSA-AES256-GCM-SHA384:ECDHE-RSA-AES256-GCM-SHA384:" +
+	"ECDHE-ECDSA-CHACHA20-POLY1305:ECDHE-RSA-CHACHA20-POLY1305:DHE-RSA-AES128-GCM-SHA256:DHE-RSA-AES256-GCM-SHA384"
+
+const nginxSnippetTemplate = `# Generated by lego for {{ .Domain }} ({{ join .Domains ", " }}).
+# Include this file in the server block: include {{ .Path }};
+ssl_certificate {{ .Certificate }};
+ssl_certificate_key {{ .Key }};
+
+ssl_protocols TLSv1.2 TLSv1.3;
+ssl_ciphers {{ .Ciphers }};
+ssl_prefer_server_ciphers off;
+ssl_session_timeout 1d;
+ssl_session_cache shared:lego:10m;
+ssl_session_tickets off;
+{{- if .OCSPStapling }}
+
+ssl_stapling on;
+ssl_stapling_verify on;
+ssl_trusted_certificate {{ .Issuer }};
+{{- end }}
+`
+
+const apacheSnippetTemplate = `# Generated by lego for {{ .Domain }} ({{ join .Domains ", " }}).
+# Include this file in the <VirtualHost *:443> block: Include {{ .Path }}
+SSLEngine on
+SSLCertificateFile {{ .Certificate }}
+SSLCertificateKeyFile {{ .Key }}
+
+SSLProtocol -all +TLSv1.2 +TLSv1.3
+SSLCipherSuite {{ .Ciphers }}
+SSLHonorCipherOrder off
+SSLSessionTickets off
+{{- if .OCSPStapling }}
+
+# OCSP stapling also requires a cache, outside of the virtual hosts: SSLStaplingCache "shmcb:logs/ssl_stapling(32768)"
+SSLUseStapling on
+{{- end }}
+`
+
+// snippetTemplates the built-in templates of the configuration snippets, by name.
+var snippetTemplates = map[string]string{
+	"nginx":  nginxSnippetTemplate,
+	"apache": apacheSnippetTemplate,
+}
+
+// snippetData the data of the templates of the configuration snippets.
+type snippetData struct {
+	Domain  string
+	Domains []string
+
+	// Path the path of the snippet.
+	Path        string
+	Certificate string
+	Key         string
+	// Issuer the path of the issuer certificate, empty if it is not stored.
+	Issuer string
+
+	Ciphers string
+	// OCSPStapling true if the certificate has an OCSP responder and the issuer certificate is stored.
+	OCSPStapling bool
+}
+
+// handleSnippets writes the configuration snippets (<domain>.<name>.conf) selected with --snippet.
+// A snippet is either a built-in template (nginx, apache), or a custom template (<name>=<template file>).
+func handleSnippets(ctx *cli.Context, certsStorage *CertificatesStorage, certRes *certificate.Resource) {
+	snippets := ctx.GlobalStringSlice("snippet")
+	if len(snippets) == 0 {
+		return
+	}
+
+	data, err := newSnippetData(certsStorage, certRes)
+	if err != nil {
+		log.Fatalf("Unable to generate the configuration snippets for domain %s\n\t%v", certRes.Domain, err)
+	}
+
+	for _, snippet := range snippets {
+		name, tmpl, err := getSnippetTemplate(snippet)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ext := "." + name + ".conf"
+		data.Path = filepath.Join(filepath.Dir(data.Certificate), sanitizedDomain(certRes.Domain)+ext)
+
+		content, err := renderSnippet(tmpl, data)
+		if err != nil {
+			log.Fatalf("Unable to generate the %s snippet for domain %s\n\t%v", name, certRes.Domain, err)
+		}
+
+		err = certsStorage.WriteFile(certRes.Domain, ext, content)
+		if err != nil {
+			log.Fatalf("Unable to save the %s snippet for domain %s\n\t%v", name, certRes.Domain, err)
+		}
+	}
+}
+
+// getSnippetTemplate returns the name and the template of a snippet: a built-in name, or <name>=<template file>.
+func getSnippetTemplate(snippet string) (string, string, error) {
+	if tmpl, ok := snippetTemplates[snippet]; ok {
+		return snippet, tmpl, nil
+	}
+
+	parts := strings.SplitN(snippet, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unknown snippet %q: nginx, apache, or <name>=<template file>", snippet)
+	}
+
+	raw, err := ioutil.ReadFile(parts[1])
+	if err != nil {
+		return "", "", fmt.Errorf("snippet %s: %w", parts[0], err)
+	}
+
+	return parts[0], string(raw), nil
+}
+
+func newSnippetData(certsStorage *CertificatesStorage, certRes *certificate.Resource) (snippetData, error) {
+	cert, err := certcrypto.ParsePEMCertificate(certRes.Certificate)
+	if err != nil {
+		return snippetData{}, err
+	}
+
+	root, err := filepath.Abs(certsStorage.GetRootPath())
+	if err != nil {
+		return snippetData{}, err
+	}
+
+	base := filepath.Join(root, sanitizedDomain(certRes.Domain))
+
+	data := snippetData{
+		Domain:      certRes.Domain,
+		Domains:     cert.DNSNames,
+		Certificate: base + ".crt",
+		Key:         base + ".key",
+		Ciphers:     snippetCiphers,
+	}
+
+	if certRes.IssuerCertificate != nil {
+		data.Issuer = base + ".issuer.crt"
+		data.OCSPStapling = len(cert.OCSPServer) > 0
+	}
+
+	return data, nil
+}
+
+func renderSnippet(tmpl string, data snippetData) ([]byte, error) {
+	t, err := template.New("snippet").Funcs(template.FuncMap{"join": strings.Join}).Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	err = t.Execute(buf, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
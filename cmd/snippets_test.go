@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func Test_handleSnippets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-snippets")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	certsStorage := &CertificatesStorage{rootPath: dir}
+
+	pki := newTestPKI(t, time.Now().Add(24*time.Hour), "example.com", "www.example.com")
+
+	custom := filepath.Join(dir, "haproxy.tmpl")
+	require.NoError(t, ioutil.WriteFile(custom, []byte("crt {{ .Certificate }} # {{ join .Domains \" \" }}\n"), filePerm))
+
+	certRes := &certificate.Resource{Domain: "example.com", Certificate: pki.leaf}
+
+	runWithFlags(t, []string{"--snippet", "nginx", "--snippet", "apache", "--snippet", "haproxy=" + custom, "renew"}, func(ctx *cli.Context) {
+		handleSnippets(ctx, certsStorage, certRes)
+	})
+
+	nginx, err := ioutil.ReadFile(filepath.Join(dir, "example.com.nginx.conf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(nginx), "# Include this file in the server block: include "+filepath.Join(dir, "example.com.nginx.conf")+";\n")
+	assert.Contains(t, string(nginx), "ssl_certificate "+filepath.Join(dir, "example.com.crt")+";\n")
+	assert.Contains(t, string(nginx), "ssl_certificate_key "+filepath.Join(dir, "example.com.key")+";\n")
+	// no issuer certificate.
+	assert.NotContains(t, string(nginx), "ssl_stapling")
+
+	apache, err := ioutil.ReadFile(filepath.Join(dir, "example.com.apache.conf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(apache), "SSLCertificateFile "+filepath.Join(dir, "example.com.crt")+"\n")
+	assert.Contains(t, string(apache), "SSLProtocol -all +TLSv1.2 +TLSv1.3\n")
+
+	haproxy, err := ioutil.ReadFile(filepath.Join(dir, "example.com.haproxy.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, "crt "+filepath.Join(dir, "example.com.crt")+" # example.com www.example.com\n", string(haproxy))
+}
+
+func Test_newSnippetData_stapling(t *testing.T) {
+	pki := newTestPKI(t, time.Now().Add(24*time.Hour), "example.com")
+
+	certsStorage := &CertificatesStorage{rootPath: "/etc/lego/certificates"}
+
+	data, err := newSnippetData(certsStorage, &certificate.Resource{Domain: "example.com", Certificate: pki.leaf, IssuerCertificate: pki.leaf})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/etc/lego/certificates/example.com.issuer.crt", data.Issuer)
+	// the test certificate has no OCSP responder.
+	assert.False(t, data.OCSPStapling)
+
+	content, err := renderSnippet(nginxSnippetTemplate, snippetData{Domain: "example.com", Issuer: "/issuer.crt", OCSPStapling: true})
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ssl_stapling on;\nssl_stapling_verify on;\nssl_trusted_certificate /issuer.crt;\n")
+}
+
+func Test_getSnippetTemplate_unknown(t *testing.T) {
+	_, _, err := getSnippetTemplate("caddy")
+	require.EqualError(t, err, `unknown snippet "caddy": nginx, apache, or <name>=<template file>`)
+}
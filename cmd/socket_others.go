@@ -0,0 +1,26 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package cmd
+
+import (
+	"net"
+	"os"
+)
+
+// listenUnix listens on a Unix socket.
+// The access to the socket depends on the permissions of its directory: the file permissions are only restricted after the listen.
+func listenUnix(path string) (net.Listener, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Chmod(path, filePerm)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
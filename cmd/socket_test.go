@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_listenUnix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the permissions of the Unix sockets are not supported on Windows")
+	}
+
+	dir, err := ioutil.TempDir("", "lego-socket")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	socket := filepath.Join(dir, "agent.sock")
+
+	listener, err := listenUnix(socket)
+	require.NoError(t, err)
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the private directory of the creation is removed.
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "agent.sock", files[0].Name())
+
+	go func() {
+		conn, errA := listener.Accept()
+		if errA == nil {
+			_ = conn.Close()
+		}
+	}()
+
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	_ = conn.Close()
+
+	// the socket is removed with the listener.
+	require.NoError(t, listener.Close())
+
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err))
+}
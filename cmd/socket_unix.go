@@ -0,0 +1,58 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package cmd
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// listenUnix listens on a Unix socket only usable by its owner.
+// The socket is created in a private directory (0700), restricted, and then moved to its path:
+// there is no window where another user can connect to it, and the umask of the process is not changed.
+func listenUnix(path string) (net.Listener, error) {
+	dir, err := ioutil.TempDir(filepath.Dir(path), ".lego-socket")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tmp := filepath.Join(dir, "socket")
+
+	listener, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	// the socket is moved: the listener must not remove its temporary path when closed.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	err = os.Chmod(tmp, filePerm)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	return &unixListener{Listener: listener, path: path}, nil
+}
+
+// unixListener a listener of a Unix socket created by listenUnix: the socket is removed when the listener is closed.
+type unixListener struct {
+	net.Listener
+	path string
+}
+
+func (l *unixListener) Close() error {
+	err := l.Listener.Close()
+	_ = os.Remove(l.path)
+	return err
+}
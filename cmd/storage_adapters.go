@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/urfave/cli"
+)
+
+// storageAdapter writes the certificates in the layout of another program, in addition to the lego storage.
+type storageAdapter interface {
+	Name() string
+	Save(certRes *certificate.Resource) error
+}
+
+// newStorageAdapters creates the storage adapters enabled by the global options.
+func newStorageAdapters(ctx *cli.Context) []storageAdapter {
+	var adapters []storageAdapter
+
+	if root := ctx.GlobalString("storage.caddy"); root != "" {
+		adapters = append(adapters, &caddyStorage{root: root, server: ctx.GlobalString("server")})
+	}
+
+	if file := ctx.GlobalString("storage.traefik"); file != "" {
+		adapters = append(adapters, &traefikStorage{file: file, resolver: ctx.GlobalString("traefik.resolver")})
+	}
+
+	return adapters
+}
+
+// caddyStorage writes the certificates in the layout of the file storage of Caddy (v2):
+//
+//	<root>/certificates/<issuer key>/<name>/<name>.{crt,key,json}
+//
+// The issuer key is derived from the directory URL of the CA (e.g. acme-v02.api.letsencrypt.org-directory).
+type caddyStorage struct {
+	root   string
+	server string
+}
+
+// caddyMetadata the metadata of a certificate (<name>.json).
+type caddyMetadata struct {
+	SANs       []string          `json:"sans,omitempty"`
+	IssuerData caddyACMEMetadata `json:"issuer_data"`
+}
+
+// caddyACMEMetadata the metadata of a certificate issued by an ACME CA.
+type caddyACMEMetadata struct {
+	URL string `json:"url"`
+	CA  string `json:"ca"`
+}
+
+func (s *caddyStorage) Name() string {
+	return "Caddy"
+}
+
+func (s *caddyStorage) Save(certRes *certificate.Resource) error {
+	if certRes.PrivateKey == nil {
+		return errors.New("the private key is unknown (CSR)")
+	}
+
+	cert, err := certcrypto.ParsePEMCertificate(certRes.Certificate)
+	if err != nil {
+		return err
+	}
+
+	name := caddySafeKey(certRes.Domain)
+	dir := filepath.Join(s.root, "certificates", caddyIssuerKey(s.server), name)
+
+	err = createNonExistingFolder(dir)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.MarshalIndent(caddyMetadata{
+		SANs:       cert.DNSNames,
+		IssuerData: caddyACMEMetadata{URL: certRes.CertURL, CA: s.server},
+	}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		name + ".crt":  certRes.Certificate,
+		name + ".key":  certRes.PrivateKey,
+		name + ".json": metadata,
+	}
+
+	for filename, content := range files {
+		err = writeFileAtomic(filepath.Join(dir, filename), content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// caddyIssuerKey returns the key of the directory of the certificates of a CA (host and path of the directory URL).
+func caddyIssuerKey(server string) string {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return caddySafeKey(server)
+	}
+
+	key := serverURL.Host
+
+	path := strings.Trim(strings.NewReplacer("/", "-", "\\", "-").Replace(serverURL.Path), "-")
+	if path != "" {
+		key += "-" + path
+	}
+
+	return caddySafeKey(key)
+}
+
+// caddyUnsafeChars the characters removed from the storage keys by Caddy.
+var caddyUnsafeChars = regexp.MustCompile(`[^\w@.-]`)
+
+// caddySafeKey returns the name of the files of a domain, as Caddy does (e.g. *.example.com -> wildcard_.example.com).
+func caddySafeKey(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	domain = strings.NewReplacer(" ", "_", "+", "_plus_", "*", "wildcard_", ":", "-", "..", "").Replace(domain)
+
+	return caddyUnsafeChars.ReplaceAllLiteralString(domain, "")
+}
+
+// traefikStorage writes the certificates in an ACME file of Traefik (v2, acme.json), under a certificate resolver.
+// The other resolvers, the account, and the other certificates of the resolver are kept.
+type traefikStorage struct {
+	file     string
+	resolver string
+}
+
+// traefikResolver the data of a certificate resolver in the ACME file.
+type traefikResolver struct {
+	Account      json.RawMessage       `json:"Account"`
+	Certificates []*traefikCertificate `json:"Certificates"`
+}
+
+// traefikCertificate a certificate in the ACME file: the PEM contents are encoded in base64.
+type traefikCertificate struct {
+	Domain      traefikDomain `json:"domain"`
+	Certificate []byte        `json:"certificate"`
+	Key         []byte        `json:"key"`
+	Store       string        `json:"Store"`
+}
+
+type traefikDomain struct {
+	Main string   `json:"main"`
+	SANs []string `json:"sans,omitempty"`
+}
+
+func (s *traefikStorage) Name() string {
+	return "Traefik"
+}
+
+func (s *traefikStorage) Save(certRes *certificate.Resource) error {
+	if certRes.PrivateKey == nil {
+		return errors.New("the private key is unknown (CSR)")
+	}
+
+	cert, err := certcrypto.ParsePEMCertificate(certRes.Certificate)
+	if err != nil {
+		return err
+	}
+
+	resolvers := make(map[string]json.RawMessage)
+
+	raw, err := ioutil.ReadFile(s.file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if len(raw) > 0 {
+		err = json.Unmarshal(raw, &resolvers)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.file, err)
+		}
+	}
+
+	resolver := traefikResolver{Account: json.RawMessage("null")}
+	if data, ok := resolvers[s.resolver]; ok {
+		err = json.Unmarshal(data, &resolver)
+		if err != nil {
+			return fmt.Errorf("%s: resolver %s: %w", s.file, s.resolver, err)
+		}
+	}
+
+	entry := &traefikCertificate{
+		Domain:      traefikDomain{Main: certRes.Domain},
+		Certificate: certRes.Certificate,
+		Key:         certRes.PrivateKey,
+		Store:       "default",
+	}
+
+	for _, name := range cert.DNSNames {
+		if name != certRes.Domain {
+			entry.Domain.SANs = append(entry.Domain.SANs, name)
+		}
+	}
+
+	var replaced bool
+	for i, c := range resolver.Certificates {
+		if c.Domain.Main == certRes.Domain {
+			resolver.Certificates[i] = entry
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		resolver.Certificates = append(resolver.Certificates, entry)
+	}
+
+	resolvers[s.resolver], err = json.Marshal(resolver)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(resolvers, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Traefik requires an ACME file readable by the owner only.
+	return writeFileAtomic(s.file, content)
+}
+
+// writeFileAtomic writes a file through a temporary file, so the readers never see a partial content.
+func writeFileAtomic(file string, content []byte) error {
+	tmp := file + ".tmp"
+
+	err := ioutil.WriteFile(tmp, content, filePerm)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp, file)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/lego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_caddyIssuerKey(t *testing.T) {
+	assert.Equal(t, "acme-v02.api.letsencrypt.org-directory", caddyIssuerKey(lego.LEDirectoryProduction))
+	assert.Equal(t, "acme.zerossl.com-v2-dv90", caddyIssuerKey("https://acme.zerossl.com/v2/DV90"))
+	assert.Equal(t, "localhost-14000-dir", caddyIssuerKey("https://localhost:14000/dir"))
+}
+
+func Test_caddyStorage_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-caddy")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	pki := newTestPKI(t, time.Now().Add(24*time.Hour), "*.example.com", "example.com")
+
+	storage := &caddyStorage{root: dir, server: lego.LEDirectoryProduction}
+
+	err = storage.Save(&certificate.Resource{
+		Domain:      "*.example.com",
+		CertURL:     "https://acme-v02.api.letsencrypt.org/acme/cert/1",
+		Certificate: pki.leaf,
+		PrivateKey:  certcrypto.PEMEncode(pki.leafKey),
+	})
+	require.NoError(t, err)
+
+	certDir := filepath.Join(dir, "certificates", "acme-v02.api.letsencrypt.org-directory", "wildcard_.example.com")
+
+	crt, err := ioutil.ReadFile(filepath.Join(certDir, "wildcard_.example.com.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, pki.leaf, crt)
+
+	assert.FileExists(t, filepath.Join(certDir, "wildcard_.example.com.key"))
+
+	raw, err := ioutil.ReadFile(filepath.Join(certDir, "wildcard_.example.com.json"))
+	require.NoError(t, err)
+
+	var metadata caddyMetadata
+	require.NoError(t, json.Unmarshal(raw, &metadata))
+
+	expected := caddyMetadata{
+		SANs:       []string{"*.example.com", "example.com"},
+		IssuerData: caddyACMEMetadata{URL: "https://acme-v02.api.letsencrypt.org/acme/cert/1", CA: lego.LEDirectoryProduction},
+	}
+	assert.Equal(t, expected, metadata)
+}
+
+func Test_traefikStorage_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-traefik")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	file := filepath.Join(dir, "acme.json")
+
+	// the other resolvers and the account are kept.
+	existing := `{
+  "letsencrypt": {"Account": {"Email": "foo@example.com"}, "Certificates": []},
+  "lego": {"Account": {"Email": "bar@example.com"}, "Certificates": [
+    {"domain": {"main": "example.org"}, "certificate": "Y2VydA==", "key": "a2V5", "Store": "default"},
+    {"domain": {"main": "example.com"}, "certificate": "b2xk", "key": "b2xk", "Store": "default"}
+  ]}
+}`
+	require.NoError(t, ioutil.WriteFile(file, []byte(existing), filePerm))
+
+	pki := newTestPKI(t, time.Now().Add(24*time.Hour), "example.com", "www.example.com")
+
+	storage := &traefikStorage{file: file, resolver: "lego"}
+
+	err = storage.Save(&certificate.Resource{
+		Domain:      "example.com",
+		Certificate: pki.leaf,
+		PrivateKey:  certcrypto.PEMEncode(pki.leafKey),
+	})
+	require.NoError(t, err)
+
+	raw, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+
+	var resolvers map[string]traefikResolver
+	require.NoError(t, json.Unmarshal(raw, &resolvers))
+
+	require.Len(t, resolvers, 2)
+	assert.JSONEq(t, `{"Email": "foo@example.com"}`, string(resolvers["letsencrypt"].Account))
+	assert.JSONEq(t, `{"Email": "bar@example.com"}`, string(resolvers["lego"].Account))
+
+	certificates := resolvers["lego"].Certificates
+	require.Len(t, certificates, 2)
+
+	assert.Equal(t, traefikDomain{Main: "example.org"}, certificates[0].Domain)
+	assert.Equal(t, []byte("cert"), certificates[0].Certificate)
+
+	assert.Equal(t, traefikDomain{Main: "example.com", SANs: []string{"www.example.com"}}, certificates[1].Domain)
+	assert.Equal(t, pki.leaf, certificates[1].Certificate)
+	assert.Equal(t, "default", certificates[1].Store)
+}
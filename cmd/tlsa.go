@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/providers/dns"
+	"github.com/urfave/cli"
+)
+
+// handleTLSA writes the SPKI pin (<domain>.pin) and, with --tlsa, the TLSA records (<domain>.tlsa) of a certificate.
+// With --tlsa.publish, the TLSA records are published by the DNS provider.
+func handleTLSA(ctx *cli.Context, certsStorage *CertificatesStorage, certRes *certificate.Resource) {
+	if !ctx.GlobalIsSet("tlsa") {
+		return
+	}
+
+	params, err := certcrypto.ParseTLSAParams(ctx.GlobalString("tlsa"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	certificates, err := certcrypto.ParsePEMBundle(certRes.Certificate)
+	if err != nil {
+		log.Fatalf("Unable to parse the certificate of domain %s\n\t%v", certRes.Domain, err)
+	}
+
+	cert := certificates[0]
+
+	err = certsStorage.WriteFile(certRes.Domain, ".pin", []byte(fmt.Sprintf("pin-sha256=%q\n", certcrypto.SPKIPin(cert))))
+	if err != nil {
+		log.Fatalf("Unable to save the SPKI pin for domain %s\n\t%v", certRes.Domain, err)
+	}
+
+	if params.UsesIssuer() {
+		cert, err = tlsaIssuer(certRes, certificates)
+		if err != nil {
+			log.Fatalf("Unable to compute the TLSA records for domain %s\n\t%v", certRes.Domain, err)
+		}
+	}
+
+	record, err := certcrypto.TLSARecord(cert, params)
+	if err != nil {
+		log.Fatalf("Unable to compute the TLSA records for domain %s\n\t%v", certRes.Domain, err)
+	}
+
+	names := tlsaNames(certificates[0], ctx.GlobalInt("tlsa.port"))
+
+	var zoneFile strings.Builder
+	for _, name := range names {
+		_, _ = fmt.Fprintf(&zoneFile, "%s IN TLSA %s\n", name, record)
+	}
+
+	err = certsStorage.WriteFile(certRes.Domain, ".tlsa", []byte(zoneFile.String()))
+	if err != nil {
+		log.Fatalf("Unable to save the TLSA records for domain %s\n\t%v", certRes.Domain, err)
+	}
+
+	if !ctx.GlobalBool("tlsa.publish") {
+		return
+	}
+
+	publisher, err := getTLSAPublisher(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, name := range names {
+		err = publisher.PublishTLSA(name, []string{record})
+		if err != nil {
+			log.Fatalf("Unable to publish the TLSA record %s\n\t%v", name, err)
+		}
+
+		log.Infof("[%s] TLSA record published: %s", certRes.Domain, name)
+	}
+}
+
+func getTLSAPublisher(ctx *cli.Context) (challenge.ProviderTLSAPublisher, error) {
+	if !ctx.GlobalIsSet("dns") {
+		return nil, errors.New("--tlsa.publish requires a DNS provider (--dns)")
+	}
+
+	provider, err := dns.NewDNSChallengeProviderByName(ctx.GlobalString("dns"))
+	if err != nil {
+		return nil, err
+	}
+
+	publisher, ok := provider.(challenge.ProviderTLSAPublisher)
+	if !ok {
+		return nil, fmt.Errorf("the DNS provider %s cannot publish TLSA records", ctx.GlobalString("dns"))
+	}
+
+	return publisher, nil
+}
+
+// tlsaIssuer returns the issuer of the certificate, used by the trust anchor usages.
+func tlsaIssuer(certRes *certificate.Resource, certificates []*x509.Certificate) (*x509.Certificate, error) {
+	if len(certRes.IssuerCertificate) > 0 {
+		issuers, err := certcrypto.ParsePEMBundle(certRes.IssuerCertificate)
+		if err != nil {
+			return nil, err
+		}
+		return issuers[0], nil
+	}
+
+	if len(certificates) > 1 {
+		return certificates[1], nil
+	}
+
+	return nil, errors.New("the issuer certificate is unknown")
+}
+
+// tlsaNames returns the owner names of the TLSA records of the certificate (e.g. `_443._tcp.example.com.`).
+// The wildcard domains are skipped: a TLSA record cannot be attached to a wildcard below the port and protocol labels.
+func tlsaNames(cert *x509.Certificate, port int) []string {
+	var names []string
+	for _, domain := range cert.DNSNames {
+		if strings.HasPrefix(domain, "*.") {
+			log.Warnf("[%s] no TLSA record for a wildcard domain", domain)
+			continue
+		}
+
+		names = append(names, fmt.Sprintf("_%d._tcp.%s", port, dns01.ToFqdn(domain)))
+	}
+
+	return names
+}
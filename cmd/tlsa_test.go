@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tlsaNames(t *testing.T) {
+	cert := &x509.Certificate{DNSNames: []string{"example.com", "*.example.com", "www.example.org"}}
+
+	names := tlsaNames(cert, 8443)
+
+	expected := []string{"_8443._tcp.example.com.", "_8443._tcp.www.example.org."}
+	assert.Equal(t, expected, names)
+}
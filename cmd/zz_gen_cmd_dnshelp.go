@@ -27,6 +27,7 @@ func allDNSCodes() string {
 		"cloudxns",
 		"conoha",
 		"constellix",
+		"desec",
 		"designate",
 		"digitalocean",
 		"dnsimple",
@@ -46,9 +47,11 @@ func allDNSCodes() string {
 		"gcloud",
 		"glesys",
 		"godaddy",
+		"hetzner",
 		"hostingde",
 		"httpreq",
 		"iij",
+		"infomaniak",
 		"inwx",
 		"joker",
 		"lightsail",
@@ -66,6 +69,7 @@ func allDNSCodes() string {
 		"otc",
 		"ovh",
 		"pdns",
+		"porkbun",
 		"rackspace",
 		"regru",
 		"rfc2136",
@@ -88,6 +92,1210 @@ func allDNSCodes() string {
 	return strings.Join(providers, ", ")
 }
 
+func allDNSProviders() []dnsProviderInfo {
+	return []dnsProviderInfo{
+		{
+			Code:  "manual",
+			Name:  "Manual",
+			Since: "v0.3.0",
+		},
+		{
+			Code:  "acme-dns",
+			Name:  "Joohoi's ACME-DNS",
+			URL:   "https://github.com/joohoi/acme-dns",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"ACME_DNS_API_BASE":     "The ACME-DNS API address",
+				"ACME_DNS_STORAGE_PATH": "The ACME-DNS JSON account data file. A per-domain account will be registered/persisted to this file and used for TXT updates.",
+			},
+		},
+		{
+			Code:  "alidns",
+			Name:  "Alibaba Cloud DNS",
+			URL:   "https://www.alibabacloud.com/product/dns",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"ALICLOUD_ACCESS_KEY": "Access key ID",
+				"ALICLOUD_SECRET_KEY": "Access Key secret",
+			},
+			Additional: map[string]string{
+				"ALICLOUD_HTTP_TIMEOUT":        "API request timeout",
+				"ALICLOUD_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"ALICLOUD_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"ALICLOUD_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "auroradns",
+			Name:  "Aurora DNS",
+			URL:   "https://www.pcextreme.com/aurora/dns",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"AURORA_ENDPOINT": "API endpoint URL",
+				"AURORA_KEY":      "User API key",
+				"AURORA_USER_ID":  "User ID",
+			},
+			Additional: map[string]string{
+				"AURORA_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"AURORA_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"AURORA_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "autodns",
+			Name:  "Autodns",
+			URL:   "https://www.internetx.com/domains/autodns/",
+			Since: "v3.2.0",
+			Credentials: map[string]string{
+				"AUTODNS_API_PASSWORD": "User Password",
+				"AUTODNS_API_USER":     "Username",
+			},
+			Additional: map[string]string{
+				"AUTODNS_CONTEXT":             "API context (4 for production, 1 for testing. Defaults to 4)",
+				"AUTODNS_ENDPOINT":            "API endpoint URL, defaults to https://api.autodns.com/v1/",
+				"AUTODNS_HTTP_TIMEOUT":        "API request timeout, defaults to 30 seconds",
+				"AUTODNS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"AUTODNS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"AUTODNS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "azure",
+			Name:  "Azure",
+			URL:   "https://azure.microsoft.com/services/dns/",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"AZURE_CLIENT_ID":           "Client ID",
+				"AZURE_CLIENT_SECRET":       "Client secret",
+				"AZURE_RESOURCE_GROUP":      "Resource group",
+				"AZURE_SUBSCRIPTION_ID":     "Subscription ID",
+				"AZURE_TENANT_ID":           "Tenant ID",
+				"instance metadata service": "If the credentials are **not** set via the environment, then it will attempt to get a bearer token via the [instance metadata service](https://docs.microsoft.com/en-us/azure/virtual-machines/windows/instance-metadata-service).",
+			},
+			Additional: map[string]string{
+				"AZURE_AUTH_METHOD":          "Force the authentication method: `env`, `msi` (managed identity), `wli` (workload identity)",
+				"AZURE_FEDERATED_TOKEN_FILE": "The path of the federated token of the workload identity",
+				"AZURE_METADATA_ENDPOINT":    "Metadata Service endpoint URL",
+				"AZURE_POLLING_INTERVAL":     "Time between DNS propagation check",
+				"AZURE_PRIVATE_ZONE":         "Use the Azure Private DNS zones (Default: false)",
+				"AZURE_PROPAGATION_TIMEOUT":  "Maximum waiting time for DNS propagation",
+				"AZURE_TTL":                  "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "bindman",
+			Name:  "Bindman",
+			URL:   "https://github.com/labbsr0x/bindman-dns-webhook",
+			Since: "v2.6.0",
+			Credentials: map[string]string{
+				"BINDMAN_MANAGER_ADDRESS": "The server URL, should have scheme, hostname, and port (if required) of the Bindman-DNS Manager server",
+			},
+			Additional: map[string]string{
+				"BINDMAN_HTTP_TIMEOUT":        "API request timeout",
+				"BINDMAN_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"BINDMAN_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+			},
+		},
+		{
+			Code:  "bluecat",
+			Name:  "Bluecat",
+			URL:   "https://www.bluecatnetworks.com",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"BLUECAT_CONFIG_NAME": "Configuration name",
+				"BLUECAT_DNS_VIEW":    "External DNS View Name",
+				"BLUECAT_PASSWORD":    "API password",
+				"BLUECAT_SERVER_URL":  "The server URL, should have scheme, hostname, and port (if required) of the authoritative Bluecat BAM serve",
+				"BLUECAT_USER_NAME":   "API username",
+			},
+			Additional: map[string]string{
+				"BLUECAT_HTTP_TIMEOUT":        "API request timeout",
+				"BLUECAT_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"BLUECAT_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"BLUECAT_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "checkdomain",
+			Name:  "Checkdomain",
+			URL:   "https://checkdomain.de/",
+			Since: "v3.3.0",
+			Credentials: map[string]string{
+				"CHECKDOMAIN_TOKEN": "API token",
+			},
+			Additional: map[string]string{
+				"CHECKDOMAIN_ENDPOINT":            "API endpoint URL, defaults to https://api.checkdomain.de",
+				"CHECKDOMAIN_HTTP_TIMEOUT":        "API request timeout, defaults to 30 seconds",
+				"CHECKDOMAIN_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"CHECKDOMAIN_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"CHECKDOMAIN_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "cloudflare",
+			Name:  "Cloudflare",
+			URL:   "https://www.cloudflare.com/dns/",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"CF_API_EMAIL":                "Account email",
+				"CF_API_KEY":                  "API key",
+				"CF_DNS_API_TOKEN":            "API token with DNS:Edit permission (since v3.1.0)",
+				"CF_ZONE_API_TOKEN":           "API token with Zone:Read permission (since v3.1.0)",
+				"CF_ZONE_TOKENS_FILE":         "File mapping each zone to an API token scoped to it",
+				"CLOUDFLARE_API_KEY":          "Alias to CF_API_KEY",
+				"CLOUDFLARE_DNS_API_TOKEN":    "Alias to CF_DNS_API_TOKEN",
+				"CLOUDFLARE_EMAIL":            "Alias to CF_API_EMAIL",
+				"CLOUDFLARE_ZONE_API_TOKEN":   "Alias to CF_ZONE_API_TOKEN",
+				"CLOUDFLARE_ZONE_TOKENS_FILE": "Alias to CF_ZONE_TOKENS_FILE",
+			},
+			Additional: map[string]string{
+				"CLOUDFLARE_HTTP_TIMEOUT":            "API request timeout",
+				"CLOUDFLARE_POLLING_INTERVAL":        "Time between DNS propagation check",
+				"CLOUDFLARE_PROPAGATION_TIMEOUT":     "Maximum waiting time for DNS propagation",
+				"CLOUDFLARE_SKIP_TOKEN_VERIFICATION": "Disable the permission self-check of the zone tokens",
+				"CLOUDFLARE_TTL":                     "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "cloudns",
+			Name:  "ClouDNS",
+			URL:   "https://www.cloudns.net",
+			Since: "v2.3.0",
+			Credentials: map[string]string{
+				"CLOUDNS_AUTH_ID":       "The API user ID",
+				"CLOUDNS_AUTH_PASSWORD": "The password for API user ID",
+			},
+			Additional: map[string]string{
+				"CLOUDNS_HTTP_TIMEOUT":        "API request timeout",
+				"CLOUDNS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"CLOUDNS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"CLOUDNS_SUB_AUTH_ID":         "The API sub user ID",
+				"CLOUDNS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "cloudxns",
+			Name:  "CloudXNS",
+			URL:   "https://www.cloudxns.net/",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"CLOUDXNS_API_KEY":    "The API key",
+				"CLOUDXNS_SECRET_KEY": "THe API secret key",
+			},
+			Additional: map[string]string{
+				"CLOUDXNS_HTTP_TIMEOUT":        "API request timeout",
+				"CLOUDXNS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"CLOUDXNS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"CLOUDXNS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "conoha",
+			Name:  "ConoHa",
+			URL:   "https://www.conoha.jp/",
+			Since: "v1.2.0",
+			Credentials: map[string]string{
+				"CONOHA_API_PASSWORD": "The API password",
+				"CONOHA_API_USERNAME": "The API username",
+				"CONOHA_TENANT_ID":    "Tenant ID",
+			},
+			Additional: map[string]string{
+				"CONOHA_HTTP_TIMEOUT":        "API request timeout",
+				"CONOHA_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"CONOHA_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"CONOHA_REGION":              "The region",
+				"CONOHA_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "constellix",
+			Name:  "Constellix",
+			URL:   "https://constellix.com",
+			Since: "v0.3.4",
+			Credentials: map[string]string{
+				"CONSTELLIX_API_KEY":    "User API key",
+				"CONSTELLIX_SECRET_KEY": "User secret key",
+			},
+			Additional: map[string]string{
+				"CONSTELLIX_HTTP_TIMEOUT":        "API request timeout",
+				"CONSTELLIX_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"CONSTELLIX_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"CONSTELLIX_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "desec",
+			Name:  "deSEC.io",
+			URL:   "https://desec.io",
+			Since: "v3.6.0",
+			Credentials: map[string]string{
+				"DESEC_TOKEN": "Domain token",
+			},
+			Additional: map[string]string{
+				"DESEC_HTTP_TIMEOUT":        "API request timeout",
+				"DESEC_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DESEC_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DESEC_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "designate",
+			Name:  "Designate DNSaaS for Openstack",
+			URL:   "https://docs.openstack.org/designate/latest/",
+			Since: "v2.2.0",
+			Credentials: map[string]string{
+				"OS_AUTH_URL":     "Identity endpoint URL",
+				"OS_PASSWORD":     "Password",
+				"OS_PROJECT_NAME": "Project name",
+				"OS_REGION_NAME":  "Region name",
+				"OS_TENANT_NAME":  "Tenant name (deprecated see OS_PROJECT_NAME and OS_PROJECT_ID)",
+				"OS_USERNAME":     "Username",
+			},
+			Additional: map[string]string{
+				"DESIGNATE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DESIGNATE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DESIGNATE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+				"OS_PROJECT_ID":                 "Project ID",
+			},
+		},
+		{
+			Code:  "digitalocean",
+			Name:  "Digital Ocean",
+			URL:   "https://www.digitalocean.com/docs/networking/dns/",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"DO_AUTH_TOKEN": "Authentication token",
+			},
+			Additional: map[string]string{
+				"DO_HTTP_TIMEOUT":        "API request timeout",
+				"DO_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DO_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DO_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "dnsimple",
+			Name:  "DNSimple",
+			URL:   "https://dnsimple.com/",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"DNSIMPLE_BASE_URL":    "API endpoint URL",
+				"DNSIMPLE_OAUTH_TOKEN": "OAuth token",
+			},
+			Additional: map[string]string{
+				"DNSIMPLE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DNSIMPLE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DNSIMPLE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "dnsmadeeasy",
+			Name:  "DNS Made Easy",
+			URL:   "https://dnsmadeeasy.com/",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"DNSMADEEASY_API_KEY":    "The API key",
+				"DNSMADEEASY_API_SECRET": "The API Secret key",
+			},
+			Additional: map[string]string{
+				"DNSMADEEASY_HTTP_TIMEOUT":        "API request timeout",
+				"DNSMADEEASY_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DNSMADEEASY_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DNSMADEEASY_SANDBOX":             "Activate the sandbox (boolean)",
+				"DNSMADEEASY_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "dnspod",
+			Name:  "DNSPod",
+			URL:   "http://www.dnspod.com/",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"DNSPOD_API_KEY": "The user token",
+			},
+			Additional: map[string]string{
+				"DNSPOD_HTTP_TIMEOUT":        "API request timeout",
+				"DNSPOD_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DNSPOD_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DNSPOD_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "dode",
+			Name:  "Domain Offensive (do.de)",
+			URL:   "https://www.do.de/",
+			Since: "v2.4.0",
+			Credentials: map[string]string{
+				"DODE_TOKEN": "API token",
+			},
+			Additional: map[string]string{
+				"DODE_HTTP_TIMEOUT":        "API request timeout",
+				"DODE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DODE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DODE_SEQUENCE_INTERVAL":   "Interval between iteration",
+				"DODE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "dreamhost",
+			Name:  "DreamHost",
+			URL:   "https://www.dreamhost.com",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"DREAMHOST_API_KEY": "The API key",
+			},
+			Additional: map[string]string{
+				"DREAMHOST_HTTP_TIMEOUT":        "API request timeout",
+				"DREAMHOST_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DREAMHOST_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DREAMHOST_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "duckdns",
+			Name:  "Duck DNS",
+			URL:   "https://www.duckdns.org/",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"DUCKDNS_TOKEN": "Account token",
+			},
+			Additional: map[string]string{
+				"DUCKDNS_HTTP_TIMEOUT":        "API request timeout",
+				"DUCKDNS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DUCKDNS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DUCKDNS_SEQUENCE_INTERVAL":   "Interval between iteration",
+				"DUCKDNS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "dyn",
+			Name:  "Dyn",
+			URL:   "https://dyn.com/",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"DYN_CUSTOMER_NAME": "Customer name",
+				"DYN_PASSWORD":      "Paswword",
+				"DYN_USER_NAME":     "User name",
+			},
+			Additional: map[string]string{
+				"DYN_HTTP_TIMEOUT":        "API request timeout",
+				"DYN_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DYN_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DYN_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "dynu",
+			Name:  "Dynu",
+			URL:   "https://www.dynu.com/",
+			Since: "v3.5.0",
+			Credentials: map[string]string{
+				"DYNU_API_KEY": "API key",
+			},
+			Additional: map[string]string{
+				"DYNU_HTTP_TIMEOUT":        "API request timeout",
+				"DYNU_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"DYNU_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"DYNU_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "easydns",
+			Name:  "EasyDNS",
+			URL:   "https://easydns.com/",
+			Since: "v2.6.0",
+			Credentials: map[string]string{
+				"EASYDNS_KEY":   "API Key",
+				"EASYDNS_TOKEN": "API Token",
+			},
+			Additional: map[string]string{
+				"EASYDNS_ENDPOINT":            "The endpoint URL of the API Server",
+				"EASYDNS_HTTP_TIMEOUT":        "API request timeout",
+				"EASYDNS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"EASYDNS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"EASYDNS_SEQUENCE_INTERVAL":   "Time between sequential requests",
+				"EASYDNS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "exec",
+			Name:  "External program",
+			URL:   "/dns/exec",
+			Since: "v0.5.0",
+		},
+		{
+			Code:  "exoscale",
+			Name:  "Exoscale",
+			URL:   "https://www.exoscale.com/",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"EXOSCALE_API_KEY":    "API key",
+				"EXOSCALE_API_SECRET": "API secret",
+				"EXOSCALE_ENDPOINT":   "API endpoint URL",
+			},
+			Additional: map[string]string{
+				"EXOSCALE_HTTP_TIMEOUT":        "API request timeout",
+				"EXOSCALE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"EXOSCALE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"EXOSCALE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "fastdns",
+			Name:  "FastDNS",
+			URL:   "https://www.akamai.com/us/en/products/security/fast-dns.jsp",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"AKAMAI_ACCESS_TOKEN":  "Access token",
+				"AKAMAI_CLIENT_SECRET": "Client secret",
+				"AKAMAI_CLIENT_TOKEN":  "Client token",
+				"AKAMAI_HOST":          "API host",
+			},
+			Additional: map[string]string{
+				"AKAMAI_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"AKAMAI_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"AKAMAI_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "gandi",
+			Name:  "Gandi",
+			URL:   "https://www.gandi.net",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"GANDI_API_KEY": "API key",
+			},
+			Additional: map[string]string{
+				"GANDI_HTTP_TIMEOUT":        "API request timeout",
+				"GANDI_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"GANDI_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"GANDI_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "gandiv5",
+			Name:  "Gandi Live DNS (v5)",
+			URL:   "https://www.gandi.net",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"GANDIV5_API_KEY": "API key",
+			},
+			Additional: map[string]string{
+				"GANDIV5_HTTP_TIMEOUT":        "API request timeout",
+				"GANDIV5_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"GANDIV5_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"GANDIV5_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "gcloud",
+			Name:  "Google Cloud",
+			URL:   "https://cloud.google.com",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"Application Default Credentials": "[Documentation](https://cloud.google.com/docs/authentication/production#providing_credentials_to_your_application)",
+				"GCE_PROJECT":                     "Project name (by default, the project name is auto-detected by using the metadata service)",
+				"GCE_SERVICE_ACCOUNT":             "Account",
+				"GCE_SERVICE_ACCOUNT_FILE":        "Account file path",
+			},
+			Additional: map[string]string{
+				"GCE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"GCE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"GCE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+				"GCE_ZONE_ID":             "The name of the managed zone to use (by default, the public zone of the domain)",
+			},
+		},
+		{
+			Code:  "glesys",
+			Name:  "Glesys",
+			URL:   "https://glesys.com/",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"GLESYS_API_KEY":  "API key",
+				"GLESYS_API_USER": "API user",
+			},
+			Additional: map[string]string{
+				"GLESYS_HTTP_TIMEOUT":        "API request timeout",
+				"GLESYS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"GLESYS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"GLESYS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "godaddy",
+			Name:  "Go Daddy",
+			URL:   "https://godaddy.com",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"GODADDY_API_KEY":    "API key",
+				"GODADDY_API_SECRET": "API secret",
+			},
+			Additional: map[string]string{
+				"GODADDY_HTTP_TIMEOUT":        "API request timeout",
+				"GODADDY_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"GODADDY_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"GODADDY_SEQUENCE_INTERVAL":   "Interval between iteration",
+				"GODADDY_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "hetzner",
+			Name:  "Hetzner",
+			URL:   "https://hetzner.com",
+			Since: "v3.6.0",
+			Credentials: map[string]string{
+				"HETZNER_API_KEY": "API key",
+			},
+			Additional: map[string]string{
+				"HETZNER_HTTP_TIMEOUT":        "API request timeout",
+				"HETZNER_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"HETZNER_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"HETZNER_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "hostingde",
+			Name:  "Hosting.de",
+			URL:   "https://www.hosting.de/",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"HOSTINGDE_API_KEY":   "API key",
+				"HOSTINGDE_ZONE_NAME": "Zone name in ACE format",
+			},
+			Additional: map[string]string{
+				"HOSTINGDE_HTTP_TIMEOUT":        "API request timeout",
+				"HOSTINGDE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"HOSTINGDE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"HOSTINGDE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "httpreq",
+			Name:  "HTTP request",
+			URL:   "/dns/httpreq/",
+			Since: "v2.0.0",
+			Credentials: map[string]string{
+				"HTTPREQ_ENDPOINT": "The URL of the server",
+				"HTTPREQ_MODE":     "`RAW`, none",
+			},
+			Additional: map[string]string{
+				"HTTPREQ_HTTP_TIMEOUT":        "API request timeout",
+				"HTTPREQ_PASSWORD":            "Basic authentication password",
+				"HTTPREQ_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"HTTPREQ_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"HTTPREQ_USERNAME":            "Basic authentication username",
+			},
+		},
+		{
+			Code:  "iij",
+			Name:  "Internet Initiative Japan",
+			URL:   "https://www.iij.ad.jp/en/",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"IIJ_API_ACCESS_KEY":  "API access key",
+				"IIJ_API_SECRET_KEY":  "API secret key",
+				"IIJ_DO_SERVICE_CODE": "DO service code",
+			},
+			Additional: map[string]string{
+				"IIJ_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"IIJ_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"IIJ_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "infomaniak",
+			Name:  "Infomaniak",
+			URL:   "https://www.infomaniak.com/",
+			Since: "v3.6.0",
+			Credentials: map[string]string{
+				"INFOMANIAK_ACCESS_TOKEN": "Access token",
+			},
+			Additional: map[string]string{
+				"INFOMANIAK_ENDPOINT":            "https://api.infomaniak.com",
+				"INFOMANIAK_HTTP_TIMEOUT":        "API request timeout",
+				"INFOMANIAK_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"INFOMANIAK_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"INFOMANIAK_TTL":                 "The TTL of the TXT record used for the DNS challenge in seconds",
+			},
+		},
+		{
+			Code:  "inwx",
+			Name:  "INWX",
+			URL:   "https://www.inwx.de/en",
+			Since: "v2.0.0",
+			Credentials: map[string]string{
+				"INWX_PASSWORD": "Password",
+				"INWX_USERNAME": "Username",
+			},
+			Additional: map[string]string{
+				"INWX_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"INWX_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"INWX_SANDBOX":             "Activate the sandbox (boolean)",
+				"INWX_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "joker",
+			Name:  "Joker",
+			URL:   "https://joker.com",
+			Since: "v2.6.0",
+			Credentials: map[string]string{
+				"JOKER_API_KEY":  "API key",
+				"JOKER_PASSWORD": "Joker.com password",
+				"JOKER_USERNAME": "Joker.com username (email address)",
+			},
+			Additional: map[string]string{
+				"JOKER_HTTP_TIMEOUT":        "API request timeout",
+				"JOKER_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"JOKER_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"JOKER_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "lightsail",
+			Name:  "Amazon Lightsail",
+			URL:   "https://aws.amazon.com/lightsail/",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"AWS_ACCESS_KEY_ID":     "Access key ID",
+				"AWS_SECRET_ACCESS_KEY": "Secret access key",
+				"DNS_ZONE":              "DNS zone",
+			},
+			Additional: map[string]string{
+				"LIGHTSAIL_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"LIGHTSAIL_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+			},
+		},
+		{
+			Code:  "linode",
+			Name:  "Linode (deprecated)",
+			URL:   "https://www.linode.com/",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"LINODE_API_KEY": "API key",
+			},
+			Additional: map[string]string{
+				"LINODE_HTTP_TIMEOUT":     "API request timeout",
+				"LINODE_POLLING_INTERVAL": "Time between DNS propagation check",
+				"LINODE_TTL":              "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "linodev4",
+			Name:  "Linode (v4)",
+			URL:   "https://www.linode.com/",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"LINODE_TOKEN": "API token",
+			},
+			Additional: map[string]string{
+				"LINODE_HTTP_TIMEOUT":        "API request timeout",
+				"LINODE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"LINODE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"LINODE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "liquidweb",
+			Name:  "Liquid Web",
+			URL:   "https://cart.liquidweb.com/storm/api/docs/v1/",
+			Since: "v3.1.0",
+			Credentials: map[string]string{
+				"LIQUID_WEB_PASSWORD": "Storm API Password",
+				"LIQUID_WEB_USERNAME": "Storm API Username",
+				"LIQUID_WEB_ZONE":     "DNS Zone",
+			},
+			Additional: map[string]string{
+				"LIQUID_WEB_HTTP_TIMEOUT":        "Maximum waiting time for the DNS records to be created (not verified)",
+				"LIQUID_WEB_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"LIQUID_WEB_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"LIQUID_WEB_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+				"LIQUID_WEB_URL":                 "Storm API endpoint",
+			},
+		},
+		{
+			Code:  "mydnsjp",
+			Name:  "MyDNS.jp",
+			URL:   "https://www.mydns.jp",
+			Since: "v1.2.0",
+			Credentials: map[string]string{
+				"MYDNSJP_MASTER_ID": "Master ID",
+				"MYDNSJP_PASSWORD":  "Password",
+			},
+			Additional: map[string]string{
+				"MYDNSJP_HTTP_TIMEOUT":        "API request timeout",
+				"MYDNSJP_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"MYDNSJP_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"MYDNSJP_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "namecheap",
+			Name:  "Namecheap",
+			URL:   "https://www.namecheap.com",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"NAMECHEAP_API_KEY":  "API key",
+				"NAMECHEAP_API_USER": "API user",
+			},
+			Additional: map[string]string{
+				"NAMECHEAP_HTTP_TIMEOUT":        "API request timeout",
+				"NAMECHEAP_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"NAMECHEAP_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"NAMECHEAP_SANDBOX":             "Activate the sandbox (boolean)",
+				"NAMECHEAP_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "namedotcom",
+			Name:  "Name.com",
+			URL:   "https://www.name.com",
+			Since: "v0.5.0",
+			Credentials: map[string]string{
+				"NAMECOM_API_TOKEN": "API token",
+				"NAMECOM_USERNAME":  "Username",
+			},
+			Additional: map[string]string{
+				"NAMECOM_HTTP_TIMEOUT":        "API request timeout",
+				"NAMECOM_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"NAMECOM_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"NAMECOM_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "namesilo",
+			Name:  "Namesilo",
+			URL:   "https://www.namesilo.com/",
+			Since: "v2.7.0",
+			Credentials: map[string]string{
+				"NAMESILO_API_KEY": "Client ID",
+			},
+			Additional: map[string]string{
+				"NAMESILO_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"NAMESILO_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation, it is better to set larger than 15m",
+				"NAMESILO_TTL":                 "The TTL of the TXT record used for the DNS challenge, should be in [3600, 2592000]",
+			},
+		},
+		{
+			Code:  "netcup",
+			Name:  "Netcup",
+			URL:   "https://www.netcup.eu/",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"NETCUP_API_KEY":         "API key",
+				"NETCUP_API_PASSWORD":    "API password",
+				"NETCUP_CUSTOMER_NUMBER": "Customer number",
+			},
+			Additional: map[string]string{
+				"NETCUP_HTTP_TIMEOUT":        "API request timeout",
+				"NETCUP_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"NETCUP_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"NETCUP_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "nifcloud",
+			Name:  "NIFCloud",
+			URL:   "https://www.nifcloud.com/",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"NIFCLOUD_ACCESS_KEY_ID":     "Access key",
+				"NIFCLOUD_SECRET_ACCESS_KEY": "Secret access key",
+			},
+			Additional: map[string]string{
+				"NIFCLOUD_HTTP_TIMEOUT":        "API request timeout",
+				"NIFCLOUD_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"NIFCLOUD_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"NIFCLOUD_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "ns1",
+			Name:  "NS1",
+			URL:   "https://ns1.com",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"NS1_API_KEY": "API key",
+			},
+			Additional: map[string]string{
+				"NS1_HTTP_TIMEOUT":        "API request timeout",
+				"NS1_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"NS1_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"NS1_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "oraclecloud",
+			Name:  "Oracle Cloud",
+			URL:   "https://cloud.oracle.com/home",
+			Since: "v2.3.0",
+			Credentials: map[string]string{
+				"OCI_COMPARTMENT_OCID":   "Compartment OCID",
+				"OCI_PRIVKEY_FILE":       "Private key file",
+				"OCI_PRIVKEY_PASS":       "Private key password",
+				"OCI_PUBKEY_FINGERPRINT": "Public key fingerprint",
+				"OCI_REGION":             "Region",
+				"OCI_TENANCY_OCID":       "Tenanct OCID",
+				"OCI_USER_OCID":          "User OCID",
+			},
+			Additional: map[string]string{
+				"OCI_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"OCI_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"OCI_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "otc",
+			Name:  "Open Telekom Cloud",
+			URL:   "https://cloud.telekom.de/en",
+			Since: "v0.4.1",
+			Credentials: map[string]string{
+				"OTC_DOMAIN_NAME":       "Domain name",
+				"OTC_IDENTITY_ENDPOINT": "Identity endpoint URL",
+				"OTC_PASSWORD":          "Password",
+				"OTC_PROJECT_NAME":      "Project name",
+				"OTC_USER_NAME":         "User name",
+			},
+			Additional: map[string]string{
+				"OTC_HTTP_TIMEOUT":        "API request timeout",
+				"OTC_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"OTC_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"OTC_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "ovh",
+			Name:  "OVH",
+			URL:   "https://www.ovh.com/",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"OVH_APPLICATION_KEY":    "Application key",
+				"OVH_APPLICATION_SECRET": "Application secret",
+				"OVH_CONSUMER_KEY":       "Consumer key",
+				"OVH_ENDPOINT":           "Endpoint URL (ovh-eu or ovh-ca)",
+			},
+			Additional: map[string]string{
+				"OVH_HTTP_TIMEOUT":        "API request timeout",
+				"OVH_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"OVH_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"OVH_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "pdns",
+			Name:  "PowerDNS",
+			URL:   "https://www.powerdns.com/",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"PDNS_API_KEY": "API key",
+				"PDNS_API_URL": "API url",
+			},
+			Additional: map[string]string{
+				"PDNS_HTTP_TIMEOUT":        "API request timeout",
+				"PDNS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"PDNS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"PDNS_SERVER_NAME":         "Name of the server in the URL, 'localhost' by default",
+				"PDNS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "porkbun",
+			Name:  "Porkbun",
+			URL:   "https://porkbun.com/",
+			Since: "v3.6.0",
+			Credentials: map[string]string{
+				"PORKBUN_API_KEY":        "API key",
+				"PORKBUN_SECRET_API_KEY": "secret API key",
+			},
+			Additional: map[string]string{
+				"PORKBUN_HTTP_TIMEOUT":        "API request timeout",
+				"PORKBUN_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"PORKBUN_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"PORKBUN_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "rackspace",
+			Name:  "Rackspace",
+			URL:   "https://www.rackspace.com/",
+			Since: "v0.4.0",
+			Credentials: map[string]string{
+				"RACKSPACE_API_KEY": "API key",
+				"RACKSPACE_USER":    "API user",
+			},
+			Additional: map[string]string{
+				"RACKSPACE_HTTP_TIMEOUT":        "API request timeout",
+				"RACKSPACE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"RACKSPACE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"RACKSPACE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "regru",
+			Name:  "reg.ru",
+			URL:   "https://www.reg.ru/",
+			Since: "v3.5.0",
+			Credentials: map[string]string{
+				"REGRU_PASSWORD": "API password",
+				"REGRU_USERNAME": "API username",
+			},
+			Additional: map[string]string{
+				"REGRU_HTTP_TIMEOUT":        "API request timeout",
+				"REGRU_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"REGRU_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"REGRU_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "rfc2136",
+			Name:  "RFC2136",
+			URL:   "https://tools.ietf.org/html/rfc2136",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"RFC2136_NAMESERVER":     "Network address in the form \"host\" or \"host:port\"",
+				"RFC2136_TSIG_ALGORITHM": "TSIG algorythm. See [miekg/dns#tsig.go](https://github.com/miekg/dns/blob/master/tsig.go) for supported values. To disable TSIG authentication, leave the `RFC2136_TSIG*` variables unset.",
+				"RFC2136_TSIG_KEY":       "Name of the secret key as defined in DNS server configuration. To disable TSIG authentication, leave the `RFC2136_TSIG*` variables unset.",
+				"RFC2136_TSIG_SECRET":    "Secret key payload. To disable TSIG authentication, leave the` RFC2136_TSIG*` variables unset.",
+			},
+			Additional: map[string]string{
+				"RFC2136_DNS_TIMEOUT":         "API request timeout",
+				"RFC2136_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"RFC2136_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"RFC2136_SEQUENCE_INTERVAL":   "Interval between iteration",
+				"RFC2136_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "rimuhosting",
+			Name:  "RimuHosting",
+			URL:   "https://rimuhosting.com",
+			Since: "v0.3.5",
+			Credentials: map[string]string{
+				"RIMUHOSTING_API_KEY": "User API key",
+			},
+			Additional: map[string]string{
+				"RIMUHOSTING_HTTP_TIMEOUT":        "API request timeout",
+				"RIMUHOSTING_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"RIMUHOSTING_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"RIMUHOSTING_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "route53",
+			Name:  "Amazon Route 53",
+			URL:   "https://aws.amazon.com/route53/",
+			Since: "v0.3.0",
+			Credentials: map[string]string{
+				"AWS_ACCESS_KEY_ID":     "Managed by the AWS client (`AWS_ACCESS_KEY_ID_FILE` is not supported)",
+				"AWS_ASSUME_ROLE_ARN":   "The ARN of the role to assume with the resolved credentials (optional)",
+				"AWS_EXTERNAL_ID":       "The external ID used to assume the role (optional)",
+				"AWS_HOSTED_ZONE_ID":    "Override the hosted zone ID",
+				"AWS_REGION":            "Managed by the AWS client (`AWS_REGION_FILE` is not supported)",
+				"AWS_SECRET_ACCESS_KEY": "Managed by the AWS client (`AWS_SECRET_ACCESS_KEY_FILE` is not supported)",
+			},
+			Additional: map[string]string{
+				"AWS_MAX_RETRIES":         "The number of maximum returns the service will use to make an individual API request",
+				"AWS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"AWS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"AWS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "sakuracloud",
+			Name:  "Sakura Cloud",
+			URL:   "https://cloud.sakura.ad.jp/",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"SAKURACLOUD_ACCESS_TOKEN":        "Access token",
+				"SAKURACLOUD_ACCESS_TOKEN_SECRET": "Access token secret",
+			},
+			Additional: map[string]string{
+				"SAKURACLOUD_HTTP_TIMEOUT":        "API request timeout",
+				"SAKURACLOUD_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"SAKURACLOUD_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"SAKURACLOUD_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "scaleway",
+			Name:  "Scaleway",
+			URL:   "https://developers.scaleway.com/",
+			Since: "v3.4.0",
+			Credentials: map[string]string{
+				"SCALEWAY_API_TOKEN": "API token",
+			},
+			Additional: map[string]string{
+				"SCALEWAY_API_VERSION":         "API version",
+				"SCALEWAY_BASE_URL":            "API endpoint URL",
+				"SCALEWAY_HTTP_TIMEOUT":        "API request timeout",
+				"SCALEWAY_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"SCALEWAY_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"SCALEWAY_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "selectel",
+			Name:  "Selectel",
+			URL:   "https://kb.selectel.com/",
+			Since: "v1.2.0",
+			Credentials: map[string]string{
+				"SELECTEL_API_TOKEN": "API token",
+			},
+			Additional: map[string]string{
+				"SELECTEL_BASE_URL":            "API endpoint URL",
+				"SELECTEL_HTTP_TIMEOUT":        "API request timeout",
+				"SELECTEL_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"SELECTEL_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"SELECTEL_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "servercow",
+			Name:  "Servercow",
+			URL:   "https://servercow.de/",
+			Since: "v3.4.0",
+			Credentials: map[string]string{
+				"SERVERCOW_PASSWORD": "API password",
+				"SERVERCOW_USERNAME": "API username",
+			},
+			Additional: map[string]string{
+				"SERVERCOW_HTTP_TIMEOUT":        "API request timeout",
+				"SERVERCOW_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"SERVERCOW_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"SERVERCOW_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "stackpath",
+			Name:  "Stackpath",
+			URL:   "https://www.stackpath.com/",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"STACKPATH_CLIENT_ID":     "Client ID",
+				"STACKPATH_CLIENT_SECRET": "Client secret",
+				"STACKPATH_STACK_ID":      "Stack ID",
+			},
+			Additional: map[string]string{
+				"STACKPATH_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"STACKPATH_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"STACKPATH_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "transip",
+			Name:  "TransIP",
+			URL:   "https://www.transip.nl/",
+			Since: "v2.0.0",
+			Credentials: map[string]string{
+				"TRANSIP_ACCOUNT_NAME":     "Account name",
+				"TRANSIP_PRIVATE_KEY_PATH": "Private key path",
+			},
+			Additional: map[string]string{
+				"TRANSIP_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"TRANSIP_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"TRANSIP_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "vegadns",
+			Name:  "VegaDNS",
+			URL:   "https://github.com/shupp/VegaDNS-API",
+			Since: "v1.1.0",
+			Credentials: map[string]string{
+				"SECRET_VEGADNS_KEY":    "API key",
+				"SECRET_VEGADNS_SECRET": "API secret",
+				"VEGADNS_URL":           "API endpoint URL",
+			},
+			Additional: map[string]string{
+				"VEGADNS_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"VEGADNS_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"VEGADNS_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "versio",
+			Name:  "Versio.[nl|eu|uk]",
+			URL:   "https://www.versio.nl/domeinnamen",
+			Since: "v2.7.0",
+			Credentials: map[string]string{
+				"VERSIO_PASSWORD": "Basic authentication password",
+				"VERSIO_USERNAME": "Basic authentication username",
+			},
+			Additional: map[string]string{
+				"VERSIO_ENDPOINT":            "The endpoint URL of the API Server",
+				"VERSIO_HTTP_TIMEOUT":        "API request timeout",
+				"VERSIO_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"VERSIO_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"VERSIO_SEQUENCE_INTERVAL":   "Interval between iteration, default 60s",
+				"VERSIO_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "vscale",
+			Name:  "Vscale",
+			URL:   "https://vscale.io/",
+			Since: "v2.0.0",
+			Credentials: map[string]string{
+				"VSCALE_API_TOKEN": "API token",
+			},
+			Additional: map[string]string{
+				"VSCALE_BASE_URL":            "API enddpoint URL",
+				"VSCALE_HTTP_TIMEOUT":        "API request timeout",
+				"VSCALE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"VSCALE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"VSCALE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "vultr",
+			Name:  "Vultr",
+			URL:   "https://www.vultr.com/",
+			Since: "v0.3.1",
+			Credentials: map[string]string{
+				"VULTR_API_KEY": "API key",
+			},
+			Additional: map[string]string{
+				"VULTR_HTTP_TIMEOUT":        "API request timeout",
+				"VULTR_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"VULTR_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"VULTR_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "zoneee",
+			Name:  "Zone.ee",
+			URL:   "https://www.zone.ee/",
+			Since: "v2.1.0",
+			Credentials: map[string]string{
+				"ZONEEE_API_KEY":  "API key",
+				"ZONEEE_API_USER": "API user",
+			},
+			Additional: map[string]string{
+				"ZONEEE_ENDPOINT":            "API endpoint URL",
+				"ZONEEE_HTTP_TIMEOUT":        "API request timeout",
+				"ZONEEE_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"ZONEEE_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"ZONEEE_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+		{
+			Code:  "zonomi",
+			Name:  "Zonomi",
+			URL:   "https://zonomi.com",
+			Since: "v0.3.5",
+			Credentials: map[string]string{
+				"ZONOMI_API_KEY": "User API key",
+			},
+			Additional: map[string]string{
+				"ZONOMI_HTTP_TIMEOUT":        "API request timeout",
+				"ZONOMI_POLLING_INTERVAL":    "Time between DNS propagation check",
+				"ZONOMI_PROPAGATION_TIMEOUT": "Maximum waiting time for DNS propagation",
+				"ZONOMI_TTL":                 "The TTL of the TXT record used for the DNS challenge",
+			},
+		},
+	}
+}
+
 func displayDNSHelp(name string) error {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	ew := &errWriter{w: w}
@@ -190,8 +1398,11 @@ func displayDNSHelp(name string) error {
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "AZURE_AUTH_METHOD":	Force the authentication method: 'env', 'msi' (managed identity), 'wli' (workload identity)`)
+		ew.writeln(`	- "AZURE_FEDERATED_TOKEN_FILE":	The path of the federated token of the workload identity`)
 		ew.writeln(`	- "AZURE_METADATA_ENDPOINT":	Metadata Service endpoint URL`)
 		ew.writeln(`	- "AZURE_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "AZURE_PRIVATE_ZONE":	Use the Azure Private DNS zones (Default: false)`)
 		ew.writeln(`	- "AZURE_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "AZURE_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
@@ -274,16 +1485,19 @@ func displayDNSHelp(name string) error {
 		ew.writeln(`	- "CF_API_KEY":	API key`)
 		ew.writeln(`	- "CF_DNS_API_TOKEN":	API token with DNS:Edit permission (since v3.1.0)`)
 		ew.writeln(`	- "CF_ZONE_API_TOKEN":	API token with Zone:Read permission (since v3.1.0)`)
+		ew.writeln(`	- "CF_ZONE_TOKENS_FILE":	File mapping each zone to an API token scoped to it`)
 		ew.writeln(`	- "CLOUDFLARE_API_KEY":	Alias to CF_API_KEY`)
 		ew.writeln(`	- "CLOUDFLARE_DNS_API_TOKEN":	Alias to CF_DNS_API_TOKEN`)
 		ew.writeln(`	- "CLOUDFLARE_EMAIL":	Alias to CF_API_EMAIL`)
 		ew.writeln(`	- "CLOUDFLARE_ZONE_API_TOKEN":	Alias to CF_ZONE_API_TOKEN`)
+		ew.writeln(`	- "CLOUDFLARE_ZONE_TOKENS_FILE":	Alias to CF_ZONE_TOKENS_FILE`)
 		ew.writeln()
 
 		ew.writeln(`Additional Configuration:`)
 		ew.writeln(`	- "CLOUDFLARE_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "CLOUDFLARE_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "CLOUDFLARE_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "CLOUDFLARE_SKIP_TOKEN_VERIFICATION":	Disable the permission self-check of the zone tokens`)
 		ew.writeln(`	- "CLOUDFLARE_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
 		ew.writeln()
@@ -376,6 +1590,26 @@ func displayDNSHelp(name string) error {
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/constellix`)
 
+	case "desec":
+		// generated from: providers/dns/desec/desec.toml
+		ew.writeln(`Configuration for deSEC.io.`)
+		ew.writeln(`Code:	'desec'`)
+		ew.writeln(`Since:	'v3.6.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "DESEC_TOKEN":	Domain token`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "DESEC_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "DESEC_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "DESEC_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "DESEC_TTL":	The TTL of the TXT record used for the DNS challenge`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/desec`)
+
 	case "designate":
 		// generated from: providers/dns/designate/designate.toml
 		ew.writeln(`Configuration for Designate DNSaaS for Openstack.`)
@@ -722,6 +1956,7 @@ func displayDNSHelp(name string) error {
 		ew.writeln(`	- "GCE_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "GCE_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
 		ew.writeln(`	- "GCE_TTL":	The TTL of the TXT record used for the DNS challenge`)
+		ew.writeln(`	- "GCE_ZONE_ID":	The name of the managed zone to use (by default, the public zone of the domain)`)
 
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/gcloud`)
@@ -769,6 +2004,26 @@ func displayDNSHelp(name string) error {
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/godaddy`)
 
+	case "hetzner":
+		// generated from: providers/dns/hetzner/hetzner.toml
+		ew.writeln(`Configuration for Hetzner.`)
+		ew.writeln(`Code:	'hetzner'`)
+		ew.writeln(`Since:	'v3.6.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "HETZNER_API_KEY":	API key`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "HETZNER_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "HETZNER_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "HETZNER_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "HETZNER_TTL":	The TTL of the TXT record used for the DNS challenge`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/hetzner`)
+
 	case "hostingde":
 		// generated from: providers/dns/hostingde/hostingde.toml
 		ew.writeln(`Configuration for Hosting.de.`)
@@ -833,6 +2088,27 @@ func displayDNSHelp(name string) error {
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/iij`)
 
+	case "infomaniak":
+		// generated from: providers/dns/infomaniak/infomaniak.toml
+		ew.writeln(`Configuration for Infomaniak.`)
+		ew.writeln(`Code:	'infomaniak'`)
+		ew.writeln(`Since:	'v3.6.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "INFOMANIAK_ACCESS_TOKEN":	Access token`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "INFOMANIAK_ENDPOINT":	https://api.infomaniak.com`)
+		ew.writeln(`	- "INFOMANIAK_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "INFOMANIAK_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "INFOMANIAK_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "INFOMANIAK_TTL":	The TTL of the TXT record used for the DNS challenge in seconds`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/infomaniak`)
+
 	case "inwx":
 		// generated from: providers/dns/inwx/inwx.toml
 		ew.writeln(`Configuration for INWX.`)
@@ -1192,11 +2468,33 @@ func displayDNSHelp(name string) error {
 		ew.writeln(`	- "PDNS_HTTP_TIMEOUT":	API request timeout`)
 		ew.writeln(`	- "PDNS_POLLING_INTERVAL":	Time between DNS propagation check`)
 		ew.writeln(`	- "PDNS_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "PDNS_SERVER_NAME":	Name of the server in the URL, 'localhost' by default`)
 		ew.writeln(`	- "PDNS_TTL":	The TTL of the TXT record used for the DNS challenge`)
 
 		ew.writeln()
 		ew.writeln(`More information: https://go-acme.github.io/lego/dns/pdns`)
 
+	case "porkbun":
+		// generated from: providers/dns/porkbun/porkbun.toml
+		ew.writeln(`Configuration for Porkbun.`)
+		ew.writeln(`Code:	'porkbun'`)
+		ew.writeln(`Since:	'v3.6.0'`)
+		ew.writeln()
+
+		ew.writeln(`Credentials:`)
+		ew.writeln(`	- "PORKBUN_API_KEY":	API key`)
+		ew.writeln(`	- "PORKBUN_SECRET_API_KEY":	secret API key`)
+		ew.writeln()
+
+		ew.writeln(`Additional Configuration:`)
+		ew.writeln(`	- "PORKBUN_HTTP_TIMEOUT":	API request timeout`)
+		ew.writeln(`	- "PORKBUN_POLLING_INTERVAL":	Time between DNS propagation check`)
+		ew.writeln(`	- "PORKBUN_PROPAGATION_TIMEOUT":	Maximum waiting time for DNS propagation`)
+		ew.writeln(`	- "PORKBUN_TTL":	The TTL of the TXT record used for the DNS challenge`)
+
+		ew.writeln()
+		ew.writeln(`More information: https://go-acme.github.io/lego/dns/porkbun`)
+
 	case "rackspace":
 		// generated from: providers/dns/rackspace/rackspace.toml
 		ew.writeln(`Configuration for Rackspace.`)
@@ -1292,6 +2590,8 @@ func displayDNSHelp(name string) error {
 
 		ew.writeln(`Credentials:`)
 		ew.writeln(`	- "AWS_ACCESS_KEY_ID":	Managed by the AWS client ('AWS_ACCESS_KEY_ID_FILE' is not supported)`)
+		ew.writeln(`	- "AWS_ASSUME_ROLE_ARN":	The ARN of the role to assume with the resolved credentials (optional)`)
+		ew.writeln(`	- "AWS_EXTERNAL_ID":	The external ID used to assume the role (optional)`)
 		ew.writeln(`	- "AWS_HOSTED_ZONE_ID":	Override the hosted zone ID`)
 		ew.writeln(`	- "AWS_REGION":	Managed by the AWS client ('AWS_REGION_FILE' is not supported)`)
 		ew.writeln(`	- "AWS_SECRET_ACCESS_KEY":	Managed by the AWS client ('AWS_SECRET_ACCESS_KEY_FILE' is not supported)`)
@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+// Client fetches the certificates from a Server listening on a Unix socket.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// Dial connects to the server listening on the Unix socket.
+func Dial(ctx context.Context, socket string) (*Client, error) {
+	conn, err := grpc.DialContext(ctx, socket,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn}, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Fetch returns the current certificate of a domain.
+func (c *Client) Fetch(ctx context.Context, domain string) (*Certificate, error) {
+	out := new(Certificate)
+	err := c.conn.Invoke(ctx, "/"+serviceName+"/Fetch", &CertificateRequest{Domain: domain}, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Watch calls fn with the current certificate of a domain, then with the new certificate on each rotation,
+// until the context is canceled or fn returns an error.
+func (c *Client) Watch(ctx context.Context, domain string, fn func(*Certificate) error) error {
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/Watch")
+	if err != nil {
+		return err
+	}
+
+	if err = stream.SendMsg(&CertificateRequest{Domain: domain}); err != nil {
+		return err
+	}
+
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		cert := new(Certificate)
+		if err := stream.RecvMsg(cert); err != nil {
+			return err
+		}
+
+		if err := fn(cert); err != nil {
+			return err
+		}
+	}
+}
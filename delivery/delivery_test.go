@@ -0,0 +1,149 @@
+package delivery
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeSource struct {
+	mu    sync.Mutex
+	certs map[string]*Certificate
+}
+
+func (f *fakeSource) Load(domain string) (*Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cert, ok := f.certs[domain]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cert, nil
+}
+
+func (f *fakeSource) set(cert *Certificate) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.certs[cert.Domain] = cert
+}
+
+func setupServer(t *testing.T) (*fakeSource, *Server, *Client) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lego-delivery")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "delivery.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	source := &fakeSource{certs: map[string]*Certificate{
+		"example.com": {Domain: "example.com", Certificate: []byte("cert 1"), PrivateKey: []byte("key 1")},
+	}}
+
+	server := NewServer(source)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	client, err := Dial(context.Background(), socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return source, server, client
+}
+
+func TestClient_Fetch(t *testing.T) {
+	_, _, client := setupServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cert, err := client.Fetch(ctx, "example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", cert.Domain)
+	assert.Equal(t, []byte("cert 1"), cert.Certificate)
+	assert.Equal(t, []byte("key 1"), cert.PrivateKey)
+
+	_, err = client.Fetch(ctx, "example.org")
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClient_Watch(t *testing.T) {
+	source, server, client := setupServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan *Certificate)
+
+	go func() {
+		_ = client.Watch(ctx, "example.com", func(cert *Certificate) error {
+			received <- cert
+			return nil
+		})
+	}()
+
+	select {
+	case cert := <-received:
+		assert.Equal(t, []byte("cert 1"), cert.Certificate)
+	case <-ctx.Done():
+		t.Fatal("the current certificate has not been received")
+	}
+
+	source.set(&Certificate{Domain: "example.com", Certificate: []byte("cert 2"), PrivateKey: []byte("key 2")})
+	server.Notify("example.com")
+
+	select {
+	case cert := <-received:
+		assert.Equal(t, []byte("cert 2"), cert.Certificate)
+		assert.Equal(t, []byte("key 2"), cert.PrivateKey)
+	case <-ctx.Done():
+		t.Fatal("the new certificate has not been received")
+	}
+}
+
+func TestClient_Watch_notObtained(t *testing.T) {
+	source, server, client := setupServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan *Certificate)
+
+	go func() {
+		_ = client.Watch(ctx, "example.org", func(cert *Certificate) error {
+			received <- cert
+			return nil
+		})
+	}()
+
+	// the certificate is sent once obtained: the notification is retried until the stream is registered.
+	source.set(&Certificate{Domain: "example.org", Certificate: []byte("cert")})
+
+	assert.Eventually(t, func() bool {
+		server.Notify("example.org")
+
+		select {
+		case cert := <-received:
+			return string(cert.Certificate) == "cert"
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,70 @@
+package delivery
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// The messages and the service mirror the definitions of delivery.proto.
+
+const serviceName = "lego.delivery.v1.CertificateDelivery"
+
+// CertificateRequest the domain of the requested certificate.
+type CertificateRequest struct {
+	Domain string `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+}
+
+func (m *CertificateRequest) Reset()         { *m = CertificateRequest{} }
+func (m *CertificateRequest) String() string { return proto.CompactTextString(m) }
+func (*CertificateRequest) ProtoMessage()    {}
+
+// Certificate a certificate (PEM) and its private key (PEM).
+type Certificate struct {
+	Domain            string `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Certificate       []byte `protobuf:"bytes,2,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	PrivateKey        []byte `protobuf:"bytes,3,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`
+	IssuerCertificate []byte `protobuf:"bytes,4,opt,name=issuer_certificate,json=issuerCertificate,proto3" json:"issuer_certificate,omitempty"`
+}
+
+func (m *Certificate) Reset()         { *m = Certificate{} }
+func (m *Certificate) String() string { return proto.CompactTextString(m) }
+func (*Certificate) ProtoMessage()    {}
+
+// deliveryServer the server side of the CertificateDelivery service.
+type deliveryServer interface {
+	Fetch(context.Context, *CertificateRequest) (*Certificate, error)
+	Watch(*CertificateRequest, grpc.ServerStream) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*deliveryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Fetch",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(CertificateRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return srv.(deliveryServer).Fetch(ctx, in)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "Watch",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				in := new(CertificateRequest)
+				if err := stream.RecvMsg(in); err != nil {
+					return err
+				}
+				return srv.(deliveryServer).Watch(in, stream)
+			},
+			ServerStreams: true,
+		},
+	},
+	Metadata: "delivery.proto",
+}
@@ -0,0 +1,141 @@
+// Package delivery serves the certificates to the local services over a Unix socket (gRPC),
+// and pushes the new certificates on each rotation, so the services don't have to watch the files.
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotFound is returned by a Source when there is no certificate for the domain.
+var ErrNotFound = errors.New("no certificate found")
+
+// Source loads the current certificate of a domain.
+type Source interface {
+	Load(domain string) (*Certificate, error)
+}
+
+// SourceFunc is an adapter to use a function as a Source.
+type SourceFunc func(domain string) (*Certificate, error)
+
+// Load calls f(domain).
+func (f SourceFunc) Load(domain string) (*Certificate, error) {
+	return f(domain)
+}
+
+// Server serves the certificates of a Source.
+type Server struct {
+	source Source
+	server *grpc.Server
+
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]struct{}
+}
+
+// NewServer creates a server of the certificates of a source.
+func NewServer(source Source) *Server {
+	s := &Server{
+		source:   source,
+		server:   grpc.NewServer(),
+		watchers: make(map[string]map[chan struct{}]struct{}),
+	}
+
+	s.server.RegisterService(&serviceDesc, s)
+
+	return s
+}
+
+// Serve accepts the connections on the listener, it returns when the server is stopped.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+// Stop stops the server, and closes the streams.
+func (s *Server) Stop() {
+	s.server.Stop()
+}
+
+// Notify pushes the current certificate of the domain to its watchers (ex: after a renewal).
+func (s *Server) Notify(domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.watchers[domain] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// a notification is already pending.
+		}
+	}
+}
+
+// Fetch implements the Fetch method of the CertificateDelivery service.
+func (s *Server) Fetch(_ context.Context, req *CertificateRequest) (*Certificate, error) {
+	return s.load(req.Domain)
+}
+
+// Watch implements the Watch method of the CertificateDelivery service.
+func (s *Server) Watch(req *CertificateRequest, stream grpc.ServerStream) error {
+	notifications := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	if s.watchers[req.Domain] == nil {
+		s.watchers[req.Domain] = make(map[chan struct{}]struct{})
+	}
+	s.watchers[req.Domain][notifications] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers[req.Domain], notifications)
+		if len(s.watchers[req.Domain]) == 0 {
+			delete(s.watchers, req.Domain)
+		}
+		s.mu.Unlock()
+	}()
+
+	// the current certificate, then the new ones.
+	notifications <- struct{}{}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-notifications:
+			cert, err := s.load(req.Domain)
+			if status.Code(err) == codes.NotFound {
+				// the certificate is sent once obtained.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := stream.SendMsg(cert); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) load(domain string) (*Certificate, error) {
+	if domain == "" {
+		return nil, status.Error(codes.InvalidArgument, "the domain is required")
+	}
+
+	cert, err := s.source.Load(domain)
+	if errors.Is(err, ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "%s: %v", domain, err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: %v", domain, err)
+	}
+
+	return cert, nil
+}
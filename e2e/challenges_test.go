@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/go-acme/lego/v3/certificate"
@@ -65,6 +66,30 @@ func TestChallengeHTTP_Run(t *testing.T) {
 	}
 }
 
+func TestChallengeHTTP_Run_EphemeralAccount(t *testing.T) {
+	loader.CleanLegoFiles()
+
+	output, err := load.RunLego(
+		"--accept-tos",
+		"-s", "https://localhost:14000/dir",
+		"-d", "acme.wtf",
+		"--http",
+		"--http.port", ":5002",
+		"run", "--ephemeral-account")
+
+	if len(output) > 0 {
+		fmt.Fprintf(os.Stdout, "%s\n", output)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = os.Stat(filepath.Join(".lego", "accounts"))
+	if !os.IsNotExist(err) {
+		t.Fatalf("the ephemeral account must not be stored: %v", err)
+	}
+}
+
 func TestChallengeTLS_Run_Domains(t *testing.T) {
 	loader.CleanLegoFiles()
 
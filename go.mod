@@ -20,6 +20,7 @@ require (
 	github.com/cpu/goacmedns v0.0.2
 	github.com/dnsimple/dnsimple-go v0.30.0
 	github.com/exoscale/egoscale v0.18.1
+	github.com/golang/protobuf v1.3.4
 	github.com/google/go-querystring v1.0.0
 	github.com/gophercloud/gophercloud v0.3.0
 	github.com/iij/doapi v0.0.0-20190504054126-0bbf12d6d7df
@@ -45,7 +46,9 @@ require (
 	golang.org/x/crypto v0.0.0-20200302210943-78000ba7a073
 	golang.org/x/net v0.0.0-20200301022130-244492dfa37a
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
+	golang.org/x/sys v0.0.0-20200302150141-5c8b2ff67527
 	google.golang.org/api v0.20.0
+	google.golang.org/grpc v1.27.1
 	gopkg.in/ns1/ns1-go.v2 v2.0.0-20190730140822-b51389932cbc
 	gopkg.in/square/go-jose.v2 v2.3.1
 )
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -137,6 +138,7 @@ func generateCLIHelp(models *Providers) error {
 		"safe": func(src string) string {
 			return strings.ReplaceAll(src, "`", "'")
 		},
+		"quote": strconv.Quote,
 	})
 
 	b := &bytes.Buffer{}
@@ -3,10 +3,13 @@ package lego
 import (
 	"errors"
 	"net/url"
+	"time"
 
+	"github.com/go-acme/lego/v3/acme"
 	"github.com/go-acme/lego/v3/acme/api"
 	"github.com/go-acme/lego/v3/certificate"
 	"github.com/go-acme/lego/v3/challenge/resolver"
+	"github.com/go-acme/lego/v3/log"
 	"github.com/go-acme/lego/v3/registration"
 )
 
@@ -16,6 +19,7 @@ type Client struct {
 	Challenge    *resolver.SolverManager
 	Registration *registration.Registrar
 	core         *api.Core
+	quirks       Quirks
 }
 
 // NewClient creates a new ACME client on behalf of the user.
@@ -35,6 +39,20 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, errors.New("the HTTP client cannot be nil")
 	}
 
+	httpClient := config.HTTPClient
+	if len(config.DirectoryPins) > 0 {
+		httpClient, err = pinnedHTTPClient(config.HTTPClient, config.CADirURL, config.DirectoryPins)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Certificate.Policy != nil {
+		if err = config.Certificate.Policy.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	privateKey := config.User.GetPrivateKey()
 	if privateKey == nil {
 		return nil, errors.New("private key was nil")
@@ -45,21 +63,42 @@ func NewClient(config *Config) (*Client, error) {
 		kid = reg.URI
 	}
 
-	core, err := api.New(config.HTTPClient, config.UserAgent, config.CADirURL, kid, privateKey)
+	var opts []api.Option
+	if config.DirectoryCache != nil {
+		opts = append(opts, api.WithDirectoryCache(config.DirectoryCache, config.DirectoryCacheTTL))
+	}
+
+	core, err := api.New(httpClient, config.UserAgent, config.CADirURL, kid, privateKey, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	timeout := config.Certificate.Timeout
+
+	quirks, ok := GetQuirks(config.CADirURL)
+	if ok {
+		log.Infof("acme: Applying the quirks of %s", quirks.Name)
+
+		if timeout < quirks.CertificateTimeout {
+			timeout = quirks.CertificateTimeout
+		}
+	}
+
 	solversManager := resolver.NewSolversManager(core)
 
 	prober := resolver.NewProber(solversManager)
-	certifier := certificate.NewCertifier(core, prober, certificate.CertifierOptions{KeyType: config.Certificate.KeyType, Timeout: config.Certificate.Timeout})
+	certifier := certificate.NewCertifier(core, prober, certificate.CertifierOptions{
+		KeyType: config.Certificate.KeyType,
+		Timeout: timeout,
+		Policy:  config.Certificate.Policy,
+	})
 
 	return &Client{
 		Certificate:  certifier,
 		Challenge:    solversManager,
 		Registration: registration.NewRegistrar(core, config.User),
 		core:         core,
+		quirks:       quirks,
 	}, nil
 }
 
@@ -68,7 +107,25 @@ func (c *Client) GetToSURL() string {
 	return c.core.GetDirectory().Meta.TermsOfService
 }
 
-// GetExternalAccountRequired returns the External Account Binding requirement of the Directory
+// GetExternalAccountRequired returns the External Account Binding requirement of the Directory,
+// or of the quirks of the CA.
 func (c *Client) GetExternalAccountRequired() bool {
-	return c.core.GetDirectory().Meta.ExternalAccountRequired
+	return c.core.GetDirectory().Meta.ExternalAccountRequired || c.quirks.EABRequired
+}
+
+// GetQuirks returns the quirks of the CA applied to the client (see GetQuirks), empty if none.
+func (c *Client) GetQuirks() Quirks {
+	return c.quirks
+}
+
+// GetClockSkew returns the difference between the time of the CA and the local time, measured on the responses of the CA:
+// positive if the local clock is behind the CA.
+// It returns false if no response of the CA had a Date header yet (e.g. the directory was cached).
+func (c *Client) GetClockSkew() (time.Duration, bool) {
+	return c.core.ClockSkew()
+}
+
+// GetDirectoryMeta returns the metadata of the CA from the Directory (website, CAA identities, profiles, ...).
+func (c *Client) GetDirectoryMeta() acme.Meta {
+	return c.core.GetDirectory().Meta
 }
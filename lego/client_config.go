@@ -5,12 +5,14 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
-	"net"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/go-acme/lego/v3/acme/api"
 	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/go-acme/lego/v3/certificate"
+	"github.com/go-acme/lego/v3/platform/httpclient"
 	"github.com/go-acme/lego/v3/registration"
 )
 
@@ -40,6 +42,16 @@ type Config struct {
 	UserAgent   string
 	HTTPClient  *http.Client
 	Certificate CertificateConfig
+
+	// DirectoryCache caches the directory of the CA between the clients (optional).
+	// A cached directory is used without request for DirectoryCacheTTL (at least), then revalidated.
+	DirectoryCache    api.DirectoryCache
+	DirectoryCacheTTL time.Duration
+
+	// DirectoryPins the SPKI pins (see certcrypto.SPKIPin) of the TLS certificates of the CA server (optional):
+	// a certificate of the chain presented by the server must have one of these keys,
+	// otherwise the requests fail with a *PinMismatchError (e.g. a TLS interception proxy).
+	DirectoryPins []string
 }
 
 func NewConfig(user registration.User) *Config {
@@ -57,28 +69,24 @@ func NewConfig(user registration.User) *Config {
 type CertificateConfig struct {
 	KeyType certcrypto.KeyType
 	Timeout time.Duration
+	// Policy restricts the certificates which can be obtained (optional).
+	Policy *certificate.Policy
 }
 
 // createDefaultHTTPClient Creates an HTTP client with a reasonable timeout value
 // and potentially a custom *x509.CertPool
-// based on the caCertificatesEnvVar environment variable (see the `initCertPool` function)
+// based on the caCertificatesEnvVar environment variable (see the `initCertPool` function).
+// The connections to the CA are kept alive, and use HTTP/2 if the CA supports it.
 func createDefaultHTTPClient() *http.Client {
-	return &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-			TLSHandshakeTimeout:   15 * time.Second,
-			ResponseHeaderTimeout: 15 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-			TLSClientConfig: &tls.Config{
-				ServerName: os.Getenv(caServerNameEnvVar),
-				RootCAs:    initCertPool(),
-			},
-		},
+	transport := httpclient.NewTransport()
+	transport.TLSHandshakeTimeout = 15 * time.Second
+	transport.ResponseHeaderTimeout = 15 * time.Second
+	transport.TLSClientConfig = &tls.Config{
+		ServerName: os.Getenv(caServerNameEnvVar),
+		RootCAs:    initCertPool(),
 	}
+
+	return &http.Client{Transport: transport}
 }
 
 // initCertPool creates a *x509.CertPool populated with the PEM certificates
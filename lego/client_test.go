@@ -44,3 +44,23 @@ type mockUser struct {
 func (u mockUser) GetEmail() string                        { return u.email }
 func (u mockUser) GetRegistration() *registration.Resource { return u.regres }
 func (u mockUser) GetPrivateKey() crypto.PrivateKey        { return u.privatekey }
+
+func TestNewClient_quirks(t *testing.T) {
+	_, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	caQuirks[apiURL+"/dir"] = Quirks{Name: "Test CA", EABRequired: true, EABCredentialsURL: "https://example.com/eab"}
+	defer delete(caQuirks, apiURL+"/dir")
+
+	key, err := rsa.GenerateKey(rand.Reader, 32)
+	require.NoError(t, err, "Could not generate test key")
+
+	config := NewConfig(mockUser{email: "test@test.com", regres: new(registration.Resource), privatekey: key})
+	config.CADirURL = apiURL + "/dir"
+
+	client, err := NewClient(config)
+	require.NoError(t, err, "Could not create client")
+
+	assert.Equal(t, "Test CA", client.GetQuirks().Name)
+	assert.True(t, client.GetExternalAccountRequired())
+}
@@ -0,0 +1,114 @@
+package lego
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+)
+
+// PinMismatchError the TLS certificate of the CA server doesn't match the pinned keys (Config.DirectoryPins),
+// e.g. because the connection is intercepted by a TLS proxy trusted by the system.
+type PinMismatchError struct {
+	Host string
+	// Subject and Issuer the subject and the issuer of the certificate presented by the server.
+	Subject string
+	Issuer  string
+	// Pins the SPKI pins of the certificates of the verified chains.
+	Pins []string
+}
+
+func (e *PinMismatchError) Error() string {
+	return fmt.Sprintf("acme: the TLS certificate of %s (subject %q, issuer %q) doesn't match the pinned keys: "+
+		"the connection is probably intercepted by a TLS proxy (pins of the verified chains: %s)",
+		e.Host, e.Subject, e.Issuer, strings.Join(e.Pins, ", "))
+}
+
+// pinnedHTTPClient returns a copy of the HTTP client checking the TLS certificates of the CA server against the pins:
+// at least one certificate of a verified chain must have one of the pinned keys.
+// The requests to the other hosts (e.g. the issuer certificate URL) are not pinned.
+func pinnedHTTPClient(client *http.Client, caDirURL string, pins []string) (*http.Client, error) {
+	dirURL, err := url.Parse(caDirURL)
+	if err != nil {
+		return nil, err
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, errors.New("the pinning of the CA server requires an *http.Transport")
+	}
+
+	pinned := transport.Clone()
+	if pinned.TLSClientConfig == nil {
+		pinned.TLSClientConfig = &tls.Config{}
+	}
+	pinned.TLSClientConfig.VerifyPeerCertificate = verifyPins(dirURL.Hostname(), pins)
+
+	pinnedClient := *client
+	pinnedClient.Transport = &pinnedTransport{host: dirURL.Host, pinned: pinned, base: base}
+
+	return &pinnedClient, nil
+}
+
+// pinnedTransport uses the pinned transport for the requests to the CA server.
+type pinnedTransport struct {
+	host   string
+	pinned http.RoundTripper
+	base   http.RoundTripper
+}
+
+func (t *pinnedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == t.host {
+		return t.pinned.RoundTrip(req)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// verifyPins checks the verified chains against the pins.
+// The presented certificates are not trusted: a proxy could append a pinned certificate to its own chain.
+func verifyPins(host string, pins []string) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	expected := make(map[string]bool)
+	for _, pin := range pins {
+		expected[pin] = true
+	}
+
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		var presented []string
+		seen := make(map[string]bool)
+
+		for _, chain := range verifiedChains {
+			for _, cert := range chain {
+				pin := certcrypto.SPKIPin(cert)
+				if expected[pin] {
+					return nil
+				}
+
+				if !seen[pin] {
+					seen[pin] = true
+					presented = append(presented, pin)
+				}
+			}
+		}
+
+		mismatch := &PinMismatchError{Host: host, Pins: presented}
+		if len(rawCerts) > 0 {
+			if leaf, err := x509.ParseCertificate(rawCerts[0]); err == nil {
+				mismatch.Subject = leaf.Subject.String()
+				mismatch.Issuer = leaf.Issuer.String()
+			}
+		}
+
+		return mismatch
+	}
+}
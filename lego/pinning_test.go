@@ -0,0 +1,167 @@
+package lego
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pinnedHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	pin := certcrypto.SPKIPin(server.Certificate())
+
+	client, err := pinnedHTTPClient(server.Client(), server.URL+"/dir", []string{"AAAA", pin})
+	require.NoError(t, err)
+
+	resp, err := client.Get(server.URL + "/dir")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func Test_pinnedHTTPClient_mismatch(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := pinnedHTTPClient(server.Client(), server.URL+"/dir", []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="})
+	require.NoError(t, err)
+
+	_, err = client.Get(server.URL + "/dir")
+	require.Error(t, err)
+
+	var mismatch *PinMismatchError
+	require.True(t, errors.As(err, &mismatch), err)
+
+	assert.Equal(t, "127.0.0.1", mismatch.Host)
+	assert.Equal(t, []string{certcrypto.SPKIPin(server.Certificate())}, mismatch.Pins)
+	assert.Contains(t, err.Error(), "probably intercepted by a TLS proxy")
+}
+
+// Test_pinnedHTTPClient_extraCertificate a proxy appends the pinned (public) certificate to its own chain:
+// the pinned certificate is presented, but not part of the verified chain.
+func Test_pinnedHTTPClient_extraCertificate(t *testing.T) {
+	proxyCA, proxyCAKey := newTestCertificate(t, "Corp Proxy CA", nil, nil)
+	leaf, leafKey := newTestCertificate(t, "127.0.0.1", proxyCA, proxyCAKey)
+	pinnedCA, _ := newTestCertificate(t, "Public CA", nil, nil)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{leaf.Raw, proxyCA.Raw, pinnedCA.Raw},
+			PrivateKey:  leafKey,
+		}},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	// the proxy CA is trusted by the system.
+	roots := x509.NewCertPool()
+	roots.AddCert(proxyCA)
+
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+	client, err := pinnedHTTPClient(httpClient, server.URL+"/dir", []string{certcrypto.SPKIPin(pinnedCA)})
+	require.NoError(t, err)
+
+	_, err = client.Get(server.URL + "/dir")
+	require.Error(t, err)
+
+	var mismatch *PinMismatchError
+	require.True(t, errors.As(err, &mismatch), err)
+
+	assert.Equal(t, "CN=127.0.0.1", mismatch.Subject)
+	assert.Equal(t, "CN=Corp Proxy CA", mismatch.Issuer)
+	assert.Equal(t, []string{certcrypto.SPKIPin(leaf), certcrypto.SPKIPin(proxyCA)}, mismatch.Pins)
+}
+
+// newTestCertificate creates a CA certificate (self-signed if parent is nil), or a leaf certificate for an IP address.
+func newTestCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	} else {
+		template.IPAddresses = []net.IP{net.ParseIP(name)}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func Test_pinnedHTTPClient_otherHost(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// the pins only apply to the CA server.
+	client, err := pinnedHTTPClient(server.Client(), "https://acme.example.com/dir", []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="})
+	require.NoError(t, err)
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+}
+
+func TestNewClient_directoryPins(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	config := NewConfig(&mockUser{email: "test@test.com", privatekey: key})
+	config.CADirURL = server.URL + "/dir"
+	config.HTTPClient = server.Client()
+	config.DirectoryPins = []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}
+
+	_, err = NewClient(config)
+	require.Error(t, err)
+
+	var mismatch *PinMismatchError
+	assert.True(t, errors.As(err, &mismatch), err)
+}
@@ -0,0 +1,60 @@
+package lego
+
+import "time"
+
+// Quirks the particularities of a CA, applied automatically to the clients of this CA.
+type Quirks struct {
+	// Name the name of the CA.
+	Name string
+
+	// CertificateTimeout the minimal time to wait for the issuance of a certificate, for the CAs slow to finalize the orders.
+	CertificateTimeout time.Duration
+
+	// MaintenanceWait the time to wait for the CA when it is unavailable, for the CAs often unavailable for short periods.
+	MaintenanceWait time.Duration
+
+	// EABRequired the CA requires External Account Binding, even if its directory doesn't advertise it.
+	EABRequired bool
+	// EABCredentialsURL the page providing the EAB credentials (key ID and HMAC key).
+	EABCredentialsURL string
+
+	// ImplicitTOSUpdate the CA doesn't require the existing accounts to agree to its updated terms of service.
+	ImplicitTOSUpdate bool
+}
+
+// caQuirks the known quirks, by directory URL.
+var caQuirks = map[string]Quirks{
+	"https://acme.zerossl.com/v2/DV90": {
+		Name:               "ZeroSSL",
+		CertificateTimeout: 5 * time.Minute,
+		MaintenanceWait:    10 * time.Minute,
+		EABRequired:        true,
+		EABCredentialsURL:  "https://app.zerossl.com/developer",
+	},
+	"https://api.buypass.com/acme/directory": {
+		Name:               "Buypass",
+		CertificateTimeout: 2 * time.Minute,
+		ImplicitTOSUpdate:  true,
+	},
+	"https://api.test4.buypass.no/acme/directory": {
+		Name:               "Buypass (staging)",
+		CertificateTimeout: 2 * time.Minute,
+		ImplicitTOSUpdate:  true,
+	},
+	"https://dv.acme-v02.api.pki.goog/directory": {
+		Name:              "Google Trust Services",
+		EABRequired:       true,
+		EABCredentialsURL: "https://cloud.google.com/certificate-manager/docs/public-ca-tutorial",
+	},
+	"https://dv.acme-v02.test-api.pki.goog/directory": {
+		Name:              "Google Trust Services (staging)",
+		EABRequired:       true,
+		EABCredentialsURL: "https://cloud.google.com/certificate-manager/docs/public-ca-tutorial",
+	},
+}
+
+// GetQuirks returns the known quirks of the CA of the directory URL.
+func GetQuirks(caDirURL string) (Quirks, bool) {
+	quirks, ok := caQuirks[caDirURL]
+	return quirks, ok
+}
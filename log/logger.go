@@ -57,3 +57,13 @@ func Warnf(format string, args ...interface{}) {
 func Infof(format string, args ...interface{}) {
 	Printf("[INFO] "+format, args...)
 }
+
+// Debug enables the debug log entries.
+var Debug bool
+
+// Debugf writes a log entry if Debug is true.
+func Debugf(format string, args ...interface{}) {
+	if Debug {
+		Printf("[DEBUG] "+format, args...)
+	}
+}
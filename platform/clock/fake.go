@@ -0,0 +1,71 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock which only moves forward when Advance or Sleep are called.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	until time.Time
+	c     chan time.Time
+}
+
+// NewFake creates a fake clock starting at the given time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the current time of the fake clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// After returns a channel receiving the time when the fake clock has been advanced by at least d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c := make(chan time.Time, 1)
+	if d <= 0 {
+		c <- f.now
+		return c
+	}
+
+	f.waiters = append(f.waiters, waiter{until: f.now.Add(d), c: c})
+
+	return c
+}
+
+// Sleep advances the fake clock by d instead of blocking.
+func (f *Fake) Sleep(d time.Duration) {
+	f.Advance(d)
+}
+
+// Advance moves the fake clock forward by d and fires the expired waiters.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+
+	var pending []waiter
+	for _, w := range f.waiters {
+		if w.until.After(f.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.c <- f.now
+	}
+
+	f.waiters = pending
+}
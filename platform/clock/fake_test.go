@@ -0,0 +1,46 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake_Advance(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clk := NewFake(start)
+
+	after := clk.After(time.Minute)
+
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(30*time.Second), clk.Now())
+
+	select {
+	case <-after:
+		t.Fatal("the waiter must not be fired before its deadline")
+	default:
+	}
+
+	clk.Sleep(30 * time.Second)
+	assert.Equal(t, start.Add(time.Minute), clk.Now())
+
+	select {
+	case now := <-after:
+		assert.Equal(t, start.Add(time.Minute), now)
+	default:
+		t.Fatal("the waiter must be fired at its deadline")
+	}
+}
+
+func TestFake_After_nonPositive(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clk := NewFake(start)
+
+	select {
+	case now := <-clk.After(0):
+		assert.Equal(t, start, now)
+	default:
+		t.Fatal("the waiter must be fired immediately")
+	}
+}
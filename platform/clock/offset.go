@@ -0,0 +1,27 @@
+package clock
+
+import "time"
+
+// Offset returns a Clock shifted by a fixed duration,
+// e.g. to follow the time of a server when the local clock is skewed.
+// The durations (After, Sleep) are not changed.
+func Offset(base Clock, offset time.Duration) Clock {
+	return offsetClock{base: base, offset: offset}
+}
+
+type offsetClock struct {
+	base   Clock
+	offset time.Duration
+}
+
+func (c offsetClock) Now() time.Time {
+	return c.base.Now().Add(c.offset)
+}
+
+func (c offsetClock) After(d time.Duration) <-chan time.Time {
+	return c.base.After(d)
+}
+
+func (c offsetClock) Sleep(d time.Duration) {
+	c.base.Sleep(d)
+}
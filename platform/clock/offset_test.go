@@ -0,0 +1,22 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffset(t *testing.T) {
+	start := time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	clock := Offset(fake, 5*time.Minute)
+
+	assert.Equal(t, start.Add(5*time.Minute), clock.Now())
+
+	clock.Sleep(time.Hour)
+
+	assert.Equal(t, start.Add(time.Hour), fake.Now())
+	assert.Equal(t, start.Add(time.Hour+5*time.Minute), clock.Now())
+}
@@ -0,0 +1,59 @@
+package cloudcreds
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// AWSConfig the credentials configuration of the AWS DNS providers.
+type AWSConfig struct {
+	// AccessKeyID, SecretAccessKey and SessionToken are the static credentials (optional).
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// AssumeRoleARN the role to assume with the resolved credentials (optional).
+	AssumeRoleARN string
+	// ExternalID the external ID used to assume the role (optional).
+	ExternalID string
+}
+
+// NewAWSSession creates an AWS session.
+//
+// The credentials are resolved in the following order:
+// 1. Static credentials (AWSConfig)
+// 2. Environment variables: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, [AWS_SESSION_TOKEN]
+// 3. Shared credentials file (defaults to ~/.aws/credentials)
+// 4. Web identity token: AWS_WEB_IDENTITY_TOKEN_FILE, AWS_ROLE_ARN (ex: IAM roles for Kubernetes service accounts)
+// 5. Amazon EC2 IAM role
+//
+// Then, if AWSConfig.AssumeRoleARN is defined, the role is assumed with the resolved credentials.
+func NewAWSSession(config AWSConfig, awsConfig *aws.Config) (*session.Session, error) {
+	if awsConfig == nil {
+		awsConfig = aws.NewConfig()
+	}
+
+	if config.AccessKeyID != "" && config.SecretAccessKey != "" {
+		awsConfig = awsConfig.Copy().WithCredentials(
+			credentials.NewStaticCredentials(config.AccessKeyID, config.SecretAccessKey, config.SessionToken))
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{Config: *awsConfig})
+	if err != nil {
+		return nil, err
+	}
+
+	if config.AssumeRoleARN == "" {
+		return sess, nil
+	}
+
+	creds := stscreds.NewCredentials(sess, config.AssumeRoleARN, func(p *stscreds.AssumeRoleProvider) {
+		if config.ExternalID != "" {
+			p.ExternalID = aws.String(config.ExternalID)
+		}
+	})
+
+	return sess.Copy(aws.NewConfig().WithCredentials(creds)), nil
+}
@@ -0,0 +1,85 @@
+package cloudcreds
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAWSSession_static(t *testing.T) {
+	config := AWSConfig{
+		AccessKeyID:     "id",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+	}
+
+	sess, err := NewAWSSession(config, aws.NewConfig().WithRegion("us-east-1"))
+	require.NoError(t, err)
+
+	value, err := sess.Config.Credentials.Get()
+	require.NoError(t, err)
+
+	assert.Equal(t, "id", value.AccessKeyID)
+	assert.Equal(t, "secret", value.SecretAccessKey)
+	assert.Equal(t, "token", value.SessionToken)
+}
+
+func TestNewAWSSession_assumeRole(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if err := req.ParseForm(); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		expected := url.Values{
+			"Action":          {"AssumeRole"},
+			"RoleArn":         {"arn:aws:iam::123456789012:role/lego"},
+			"ExternalId":      {"external"},
+			"DurationSeconds": {"900"},
+			"Version":         {"2011-06-15"},
+		}
+		for key, values := range expected {
+			if req.Form.Get(key) != values[0] {
+				http.Error(rw, fmt.Sprintf("%s: %q", key, req.Form.Get(key)), http.StatusBadRequest)
+				return
+			}
+		}
+
+		rw.Header().Set("Content-Type", "text/xml")
+		_, _ = fmt.Fprintf(rw, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>assumed-id</AccessKeyId>
+      <SecretAccessKey>assumed-secret</SecretAccessKey>
+      <SessionToken>assumed-token</SessionToken>
+      <Expiration>%s</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	config := AWSConfig{
+		AccessKeyID:     "id",
+		SecretAccessKey: "secret",
+		AssumeRoleARN:   "arn:aws:iam::123456789012:role/lego",
+		ExternalID:      "external",
+	}
+
+	sess, err := NewAWSSession(config, aws.NewConfig().WithRegion("us-east-1").WithEndpoint(server.URL))
+	require.NoError(t, err)
+
+	value, err := sess.Config.Credentials.Get()
+	require.NoError(t, err)
+
+	assert.Equal(t, "assumed-id", value.AccessKeyID)
+	assert.Equal(t, "assumed-secret", value.SecretAccessKey)
+	assert.Equal(t, "assumed-token", value.SessionToken)
+}
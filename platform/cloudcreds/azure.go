@@ -0,0 +1,155 @@
+package cloudcreds
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+)
+
+// Authentication methods of Azure.
+const (
+	// AzureAuthMethodEnv the credentials of the environment variables, or the managed identity.
+	AzureAuthMethodEnv = "env"
+	// AzureAuthMethodMSI the managed identity (instance metadata service).
+	AzureAuthMethodMSI = "msi"
+	// AzureAuthMethodWorkloadIdentity the workload identity (federated token).
+	AzureAuthMethodWorkloadIdentity = "wli"
+)
+
+// Environment variables of the Azure workload identity (defined by the Azure workload identity webhook).
+const (
+	envAzureFederatedTokenFile = "AZURE_FEDERATED_TOKEN_FILE"
+	envAzureAuthorityHost      = "AZURE_AUTHORITY_HOST"
+)
+
+// AzureConfig the credentials configuration of the Azure DNS providers.
+type AzureConfig struct {
+	// TenantID, ClientID and ClientSecret are the static credentials of a service principal (optional).
+	// The ClientID is also the ID of a user-assigned managed identity, or of the application of a workload identity.
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+
+	// AuthMethod forces an authentication method (optional): AzureAuthMethodEnv, AzureAuthMethodMSI, AzureAuthMethodWorkloadIdentity.
+	AuthMethod string
+	// FederatedTokenFile the path of the federated token of a workload identity (optional, AZURE_FEDERATED_TOKEN_FILE by default).
+	FederatedTokenFile string
+
+	// HTTPClient the client used to get the tokens (optional).
+	HTTPClient *http.Client
+}
+
+// NewAzureAuthorizer creates an authorizer for the Azure Resource Manager.
+//
+// Without AzureConfig.AuthMethod, the credentials are resolved in the following order:
+// 1. Static credentials of a service principal (AzureConfig)
+// 2. Workload identity: federated token file (AZURE_FEDERATED_TOKEN_FILE), AZURE_TENANT_ID, AZURE_CLIENT_ID
+// 3. Environment variables: AZURE_TENANT_ID, AZURE_CLIENT_ID, and AZURE_CLIENT_SECRET or AZURE_CERTIFICATE_PATH
+// 4. Managed identity (instance metadata service)
+func NewAzureAuthorizer(config AzureConfig) (autorest.Authorizer, error) {
+	if config.FederatedTokenFile == "" {
+		config.FederatedTokenFile = os.Getenv(envAzureFederatedTokenFile)
+	}
+
+	switch config.AuthMethod {
+	case "":
+		if config.ClientID != "" && config.ClientSecret != "" && config.TenantID != "" {
+			return newAzureClientSecretAuthorizer(config)
+		}
+
+		if config.FederatedTokenFile != "" {
+			return newAzureWorkloadIdentityAuthorizer(config)
+		}
+
+		return auth.NewAuthorizerFromEnvironment()
+	case AzureAuthMethodEnv:
+		return auth.NewAuthorizerFromEnvironment()
+	case AzureAuthMethodMSI:
+		return newAzureMSIAuthorizer(config)
+	case AzureAuthMethodWorkloadIdentity:
+		return newAzureWorkloadIdentityAuthorizer(config)
+	default:
+		return nil, fmt.Errorf("unsupported Azure authentication method: %s", config.AuthMethod)
+	}
+}
+
+func newAzureClientSecretAuthorizer(config AzureConfig) (autorest.Authorizer, error) {
+	oauthConfig, err := adal.NewOAuthConfig(azure.PublicCloud.ActiveDirectoryEndpoint, config.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	spt, err := adal.NewServicePrincipalToken(*oauthConfig, config.ClientID, config.ClientSecret, azure.PublicCloud.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.HTTPClient != nil {
+		spt.SetSender(config.HTTPClient)
+	}
+
+	return autorest.NewBearerAuthorizer(spt), nil
+}
+
+func newAzureMSIAuthorizer(config AzureConfig) (autorest.Authorizer, error) {
+	msiEndpoint, err := adal.GetMSIVMEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	var spt *adal.ServicePrincipalToken
+	if config.ClientID != "" {
+		// user-assigned managed identity
+		spt, err = adal.NewServicePrincipalTokenFromMSIWithUserAssignedID(msiEndpoint, azure.PublicCloud.ResourceManagerEndpoint, config.ClientID)
+	} else {
+		spt, err = adal.NewServicePrincipalTokenFromMSI(msiEndpoint, azure.PublicCloud.ResourceManagerEndpoint)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if config.HTTPClient != nil {
+		spt.SetSender(config.HTTPClient)
+	}
+
+	return autorest.NewBearerAuthorizer(spt), nil
+}
+
+func newAzureWorkloadIdentityAuthorizer(config AzureConfig) (autorest.Authorizer, error) {
+	if config.TenantID == "" {
+		config.TenantID = os.Getenv(auth.TenantID)
+	}
+
+	if config.ClientID == "" {
+		config.ClientID = os.Getenv(auth.ClientID)
+	}
+
+	if config.TenantID == "" || config.ClientID == "" || config.FederatedTokenFile == "" {
+		return nil, fmt.Errorf("the workload identity requires a tenant ID, a client ID, and a federated token file (%s)", envAzureFederatedTokenFile)
+	}
+
+	authorityHost := os.Getenv(envAzureAuthorityHost)
+	if authorityHost == "" {
+		authorityHost = azure.PublicCloud.ActiveDirectoryEndpoint
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	tp := &azureFederatedTokenProvider{
+		tokenURL:   fmt.Sprintf("%s/%s/oauth2/v2.0/token", trimSlash(authorityHost), config.TenantID),
+		clientID:   config.ClientID,
+		scope:      trimSlash(azure.PublicCloud.ResourceManagerEndpoint) + "/.default",
+		tokenFile:  config.FederatedTokenFile,
+		httpClient: httpClient,
+	}
+
+	return autorest.NewBearerAuthorizer(tp), nil
+}
@@ -0,0 +1,73 @@
+package cloudcreds
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAzureAuthorizer_workloadIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tokenFile := filepath.Join(dir, "token")
+	err = ioutil.WriteFile(tokenFile, []byte("federated-token\n"), 0600)
+	require.NoError(t, err)
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+
+		if req.URL.Path != "/tenant/oauth2/v2.0/token" {
+			http.Error(rw, req.URL.Path, http.StatusNotFound)
+			return
+		}
+
+		if req.FormValue("client_assertion") != "federated-token" || req.FormValue("client_id") != "client" {
+			http.Error(rw, "invalid assertion", http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(azureTokenResponse{AccessToken: "access-token", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	os.Setenv(envAzureAuthorityHost, server.URL)
+	defer os.Unsetenv(envAzureAuthorityHost)
+
+	authorizer, err := NewAzureAuthorizer(AzureConfig{
+		TenantID:           "tenant",
+		ClientID:           "client",
+		FederatedTokenFile: tokenFile,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		req, err := autorest.Prepare(&http.Request{Header: http.Header{}}, authorizer.WithAuthorization())
+		require.NoError(t, err)
+
+		assert.Equal(t, "Bearer access-token", req.Header.Get("Authorization"))
+	}
+
+	// the token is cached.
+	assert.Equal(t, 1, calls)
+}
+
+func TestNewAzureAuthorizer_workloadIdentityMissingConfig(t *testing.T) {
+	_, err := NewAzureAuthorizer(AzureConfig{AuthMethod: AzureAuthMethodWorkloadIdentity, FederatedTokenFile: "token"})
+	require.Error(t, err)
+}
+
+func TestNewAzureAuthorizer_unsupportedMethod(t *testing.T) {
+	_, err := NewAzureAuthorizer(AzureConfig{AuthMethod: "foo"})
+	require.EqualError(t, err, "unsupported Azure authentication method: foo")
+}
@@ -0,0 +1,118 @@
+package cloudcreds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// azureTokenRefreshMargin the token is refreshed before its expiration.
+const azureTokenRefreshMargin = 5 * time.Minute
+
+// azureFederatedTokenProvider exchanges the federated token of a workload identity
+// (ex: Kubernetes service account token) for an Azure AD access token.
+// It implements adal.OAuthTokenProvider and adal.RefresherWithContext.
+// https://docs.microsoft.com/en-us/azure/active-directory/develop/v2-oauth2-client-creds-grant-flow#third-case-access-token-request-with-a-federated-credential
+type azureFederatedTokenProvider struct {
+	tokenURL   string
+	clientID   string
+	scope      string
+	tokenFile  string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresOn time.Time
+}
+
+type azureTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (p *azureFederatedTokenProvider) OAuthToken() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.token
+}
+
+func (p *azureFederatedTokenProvider) EnsureFreshWithContext(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Now().Add(azureTokenRefreshMargin).Before(p.expiresOn) {
+		return nil
+	}
+
+	return p.refresh(ctx)
+}
+
+func (p *azureFederatedTokenProvider) RefreshWithContext(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.refresh(ctx)
+}
+
+func (p *azureFederatedTokenProvider) RefreshExchangeWithContext(ctx context.Context, _ string) error {
+	return p.RefreshWithContext(ctx)
+}
+
+func (p *azureFederatedTokenProvider) refresh(ctx context.Context) error {
+	assertion, err := readTokenFile(p.tokenFile)
+	if err != nil {
+		return err
+	}
+
+	data := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {p.clientID},
+		"scope":                 {p.scope},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("unable to get the Azure AD token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to get the Azure AD token: %d: %s", resp.StatusCode, string(body))
+	}
+
+	var token azureTokenResponse
+	err = json.Unmarshal(body, &token)
+	if err != nil {
+		return fmt.Errorf("unable to parse the Azure AD token: %w", err)
+	}
+
+	p.token = token.AccessToken
+	p.expiresOn = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	return nil
+}
+
+func trimSlash(s string) string {
+	return strings.TrimSuffix(s, "/")
+}
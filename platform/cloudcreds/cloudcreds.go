@@ -0,0 +1,29 @@
+// Package cloudcreds resolves the credentials of the DNS providers of the cloud platforms (AWS, Azure, Google Cloud).
+//
+// The credentials are resolved from the first available source, in this order:
+// the static credentials of the configuration, the environment variables, the credentials files,
+// the workload identity (ex: Kubernetes service account tokens), and the instance metadata service.
+// The resolved credentials can be used to assume another role (when supported by the platform).
+package cloudcreds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// readTokenFile reads a token from a file.
+// The file is read each time a token is needed: the projected tokens are rotated.
+func readTokenFile(filename string) (string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("the token file %s is empty", filename)
+	}
+
+	return token, nil
+}
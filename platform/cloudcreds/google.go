@@ -0,0 +1,74 @@
+package cloudcreds
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// envGoogleApplicationCredentials the path of the application default credentials.
+const envGoogleApplicationCredentials = "GOOGLE_APPLICATION_CREDENTIALS"
+
+// GoogleConfig the credentials configuration of the Google Cloud DNS providers.
+type GoogleConfig struct {
+	// ServiceAccountKey the JSON key of a service account, or the JSON external account credentials (optional).
+	ServiceAccountKey []byte
+	// ServiceAccountFile the path of the JSON key of a service account, or of the JSON external account credentials (optional).
+	ServiceAccountFile string
+}
+
+// NewGoogleClient creates an HTTP client authenticated for the scopes.
+//
+// The credentials are resolved in the following order:
+// 1. Service account key, or external account credentials (GoogleConfig)
+// 2. Application default credentials:
+//    the file defined by GOOGLE_APPLICATION_CREDENTIALS, the gcloud credentials, the metadata server (GCE, GKE).
+//
+// The external account credentials (workload identity federation) exchange the token of an external identity provider
+// (ex: a Kubernetes service account token, a token of another cloud) for a Google access token.
+func NewGoogleClient(ctx context.Context, config GoogleConfig, scopes ...string) (*http.Client, error) {
+	saKey := config.ServiceAccountKey
+
+	if len(saKey) == 0 && config.ServiceAccountFile != "" {
+		var err error
+		saKey, err = ioutil.ReadFile(config.ServiceAccountFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read Service Account file: %w", err)
+		}
+	}
+
+	if len(saKey) == 0 {
+		// the application default credentials don't support the external accounts.
+		if filename := os.Getenv(envGoogleApplicationCredentials); filename != "" {
+			data, err := ioutil.ReadFile(filename)
+			if err == nil && isGoogleExternalAccount(data) {
+				saKey = data
+			}
+		}
+	}
+
+	if len(saKey) == 0 {
+		return google.DefaultClient(ctx, scopes...)
+	}
+
+	if isGoogleExternalAccount(saKey) {
+		ts, err := newGoogleExternalAccountTokenSource(ctx, saKey, scopes)
+		if err != nil {
+			return nil, err
+		}
+
+		return oauth2.NewClient(ctx, ts), nil
+	}
+
+	conf, err := google.JWTConfigFromJSON(saKey, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to acquire config: %w", err)
+	}
+
+	return conf.Client(ctx), nil
+}
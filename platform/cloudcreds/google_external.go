@@ -0,0 +1,259 @@
+package cloudcreds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	googleExternalAccountType = "external_account"
+	googleCloudPlatformScope  = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// googleExternalAccount the external account credentials (workload identity federation).
+// https://google.aip.dev/auth/4117
+type googleExternalAccount struct {
+	Type                           string `json:"type"`
+	Audience                       string `json:"audience"`
+	SubjectTokenType               string `json:"subject_token_type"`
+	TokenURL                       string `json:"token_url"`
+	ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+	CredentialSource               struct {
+		File    string            `json:"file"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+		Format  struct {
+			Type                  string `json:"type"`
+			SubjectTokenFieldName string `json:"subject_token_field_name"`
+		} `json:"format"`
+	} `json:"credential_source"`
+}
+
+// isGoogleExternalAccount returns true if the JSON credentials are external account credentials.
+func isGoogleExternalAccount(data []byte) bool {
+	var creds struct {
+		Type string `json:"type"`
+	}
+
+	return json.Unmarshal(data, &creds) == nil && creds.Type == googleExternalAccountType
+}
+
+// newGoogleExternalAccountTokenSource creates a token source exchanging the token of an external identity provider
+// for a Google access token (with the Security Token Service), then impersonating a service account (optional).
+func newGoogleExternalAccountTokenSource(ctx context.Context, data []byte, scopes []string) (oauth2.TokenSource, error) {
+	var account googleExternalAccount
+	err := json.Unmarshal(data, &account)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the external account credentials: %w", err)
+	}
+
+	if account.Audience == "" || account.TokenURL == "" || account.SubjectTokenType == "" {
+		return nil, errors.New("the external account credentials require audience, token_url and subject_token_type")
+	}
+
+	if account.CredentialSource.File == "" && account.CredentialSource.URL == "" {
+		return nil, errors.New("the external account credentials require a file or an URL credential source")
+	}
+
+	if len(scopes) == 0 {
+		scopes = []string{googleCloudPlatformScope}
+	}
+
+	ts := &googleExternalAccountTokenSource{
+		ctx:        ctx,
+		account:    account,
+		scopes:     scopes,
+		httpClient: http.DefaultClient,
+	}
+
+	return oauth2.ReuseTokenSource(nil, ts), nil
+}
+
+type googleExternalAccountTokenSource struct {
+	ctx        context.Context
+	account    googleExternalAccount
+	scopes     []string
+	httpClient *http.Client
+}
+
+func (s *googleExternalAccountTokenSource) Token() (*oauth2.Token, error) {
+	subjectToken, err := s.subjectToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.exchange(subjectToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.account.ServiceAccountImpersonationURL == "" {
+		return token, nil
+	}
+
+	return s.impersonate(token)
+}
+
+// subjectToken reads the token of the external identity provider.
+func (s *googleExternalAccountTokenSource) subjectToken() (string, error) {
+	source := s.account.CredentialSource
+
+	var data []byte
+	if source.File != "" {
+		var err error
+		data, err = ioutil.ReadFile(source.File)
+		if err != nil {
+			return "", fmt.Errorf("unable to read the subject token: %w", err)
+		}
+	} else {
+		req, err := http.NewRequest(http.MethodGet, source.URL, nil)
+		if err != nil {
+			return "", err
+		}
+
+		for k, v := range source.Headers {
+			req.Header.Set(k, v)
+		}
+
+		data, err = s.do(req)
+		if err != nil {
+			return "", fmt.Errorf("unable to get the subject token: %w", err)
+		}
+	}
+
+	if source.Format.Type != "json" {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	var fields map[string]interface{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse the subject token: %w", err)
+	}
+
+	token, ok := fields[source.Format.SubjectTokenFieldName].(string)
+	if !ok || token == "" {
+		return "", fmt.Errorf("the subject token field %q is missing", source.Format.SubjectTokenFieldName)
+	}
+
+	return token, nil
+}
+
+// exchange exchanges the subject token for a Google access token.
+// https://cloud.google.com/iam/docs/reference/sts/rest/v1/TopLevel/token
+func (s *googleExternalAccountTokenSource) exchange(subjectToken string) (*oauth2.Token, error) {
+	data := url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"audience":             {s.account.Audience},
+		"scope":                {strings.Join(s.stsScopes(), " ")},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {s.account.SubjectTokenType},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.account.TokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	body, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to exchange the subject token: %w", err)
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the exchanged token: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: resp.AccessToken,
+		TokenType:   resp.TokenType,
+		Expiry:      time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// stsScopes the impersonation of a service account requires the cloud-platform scope.
+func (s *googleExternalAccountTokenSource) stsScopes() []string {
+	if s.account.ServiceAccountImpersonationURL != "" {
+		return []string{googleCloudPlatformScope}
+	}
+
+	return s.scopes
+}
+
+// impersonate generates an access token of the service account.
+// https://cloud.google.com/iam/docs/reference/credentials/rest/v1/projects.serviceAccounts/generateAccessToken
+func (s *googleExternalAccountTokenSource) impersonate(token *oauth2.Token) (*oauth2.Token, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"scope":    s.scopes,
+		"lifetime": "3600s",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.account.ServiceAccountImpersonationURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	token.SetAuthHeader(req)
+
+	body, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to impersonate the service account: %w", err)
+	}
+
+	var resp struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the service account token: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: resp.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      resp.ExpireTime,
+	}, nil
+}
+
+func (s *googleExternalAccountTokenSource) do(req *http.Request) ([]byte, error) {
+	resp, err := s.httpClient.Do(req.WithContext(s.ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%d: %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
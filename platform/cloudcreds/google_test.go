@@ -0,0 +1,94 @@
+package cloudcreds
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGoogleClient_serviceAccountKey(t *testing.T) {
+	key := []byte(`{"type":"service_account","client_email":"lego@example.iam.gserviceaccount.com","private_key":"key"}`)
+
+	client, err := NewGoogleClient(context.Background(), GoogleConfig{ServiceAccountKey: key}, "scope")
+	require.NoError(t, err)
+	require.NotNil(t, client)
+}
+
+func TestNewGoogleClient_invalidServiceAccountKey(t *testing.T) {
+	_, err := NewGoogleClient(context.Background(), GoogleConfig{ServiceAccountKey: []byte("{")}, "scope")
+	require.Error(t, err)
+}
+
+func TestNewGoogleClient_missingServiceAccountFile(t *testing.T) {
+	_, err := NewGoogleClient(context.Background(), GoogleConfig{ServiceAccountFile: "/missing.json"}, "scope")
+	require.Error(t, err)
+}
+
+func TestNewGoogleClient_externalAccount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tokenFile := filepath.Join(dir, "token.json")
+	err = ioutil.WriteFile(tokenFile, []byte(`{"id_token":"subject-token"}`), 0600)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/sts", func(rw http.ResponseWriter, req *http.Request) {
+		if req.FormValue("subject_token") != "subject-token" || req.FormValue("audience") != "//iam.googleapis.com/pool" {
+			http.Error(rw, "invalid subject token", http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{"access_token":"federated-token","token_type":"Bearer","expires_in":3600}`))
+	})
+
+	mux.HandleFunc("/impersonate", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer federated-token" {
+			http.Error(rw, "invalid federated token", http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = fmt.Fprintf(rw, `{"accessToken":"sa-token","expireTime":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+
+	mux.HandleFunc("/api", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(req.Header.Get("Authorization")))
+	})
+
+	key := fmt.Sprintf(`{
+  "type": "external_account",
+  "audience": "//iam.googleapis.com/pool",
+  "subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+  "token_url": %q,
+  "service_account_impersonation_url": %q,
+  "credential_source": {
+    "file": %q,
+    "format": {"type": "json", "subject_token_field_name": "id_token"}
+  }
+}`, server.URL+"/sts", server.URL+"/impersonate", tokenFile)
+
+	client, err := NewGoogleClient(context.Background(), GoogleConfig{ServiceAccountKey: []byte(key)}, "scope")
+	require.NoError(t, err)
+
+	resp, err := client.Get(server.URL + "/api")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer sa-token", string(body))
+}
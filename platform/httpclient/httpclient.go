@@ -0,0 +1,50 @@
+// Package httpclient provides the HTTP transport shared by the ACME client and the DNS providers.
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 16
+	idleConnTimeout     = 90 * time.Second
+)
+
+// shared is the transport shared by the clients created by New.
+var shared = NewTransport()
+
+// NewTransport creates an HTTP transport tuned for the ACME and the DNS provider APIs:
+// the connections are kept alive and reused by the following requests (up to 16 idle connections by host),
+// and HTTP/2 is used when the server supports it.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// SharedTransport returns the transport shared by the clients created by New.
+func SharedTransport() *http.Transport {
+	return shared
+}
+
+// New creates an HTTP client using the shared transport:
+// the clients created by New share their connection pool.
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: shared,
+	}
+}
@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_sharedConnections(t *testing.T) {
+	var conns int32
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	// two clients, as two providers would create.
+	clients := []*http.Client{New(5 * time.Second), New(10 * time.Second)}
+
+	for i := 0; i < 4; i++ {
+		resp, err := clients[i%2].Get(server.URL)
+		require.NoError(t, err)
+
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&conns))
+	assert.Equal(t, 5*time.Second, clients[0].Timeout)
+	assert.Same(t, SharedTransport(), clients[1].Transport)
+}
+
+func TestNewTransport(t *testing.T) {
+	transport := NewTransport()
+
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.Equal(t, maxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.NotSame(t, SharedTransport(), transport)
+}
@@ -0,0 +1,345 @@
+// Package journal records the HTTP interactions of a provider with its API to golden files, and replays them in tests.
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// EnvMode is the environment variable used to switch a journal to the record mode (LEGO_JOURNAL_MODE=record).
+const EnvMode = "LEGO_JOURNAL_MODE"
+
+// Mode the mode of a journal.
+type Mode string
+
+// Modes of a journal.
+const (
+	// Replay replays the interactions of the golden file, no request is sent to the API.
+	Replay Mode = "replay"
+	// Record sends the requests to the API and records the interactions into the golden file.
+	Record Mode = "record"
+)
+
+const redacted = "[REDACTED]"
+
+// Interaction a recorded HTTP request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Option configures a Journal.
+type Option func(*Journal)
+
+// WithSecret replaces the value by the placeholder in the recorded interactions (URLs, headers and bodies).
+func WithSecret(value, placeholder string) Option {
+	return func(j *Journal) {
+		if value == "" || value == placeholder {
+			return
+		}
+
+		j.replacer = append(j.replacer, value, placeholder)
+		if escaped := url.QueryEscape(value); escaped != value {
+			j.replacer = append(j.replacer, escaped, url.QueryEscape(placeholder))
+		}
+	}
+}
+
+// WithRedactedHeaders redacts the values of the headers in the recorded interactions.
+func WithRedactedHeaders(names ...string) Option {
+	return func(j *Journal) {
+		for _, name := range names {
+			j.redactedHeaders = append(j.redactedHeaders, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// WithMode forces the mode of the journal instead of reading it from the environment variable LEGO_JOURNAL_MODE.
+func WithMode(mode Mode) Option {
+	return func(j *Journal) {
+		j.mode = mode
+	}
+}
+
+// WithTransport defines the transport used to send the requests in record mode.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(j *Journal) {
+		j.transport = transport
+	}
+}
+
+// Journal an http.RoundTripper recording or replaying HTTP interactions.
+type Journal struct {
+	t    testing.TB
+	path string
+	mode Mode
+
+	transport       http.RoundTripper
+	replacer        []string
+	redactedHeaders []string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	position     int
+}
+
+// New creates a journal backed by the golden file located at path.
+// In record mode, the golden file is written at the end of the test.
+// In replay mode, the test fails if some interactions of the golden file have not been replayed.
+func New(t testing.TB, path string, opts ...Option) *Journal {
+	t.Helper()
+
+	j := &Journal{
+		t:         t,
+		path:      path,
+		mode:      Replay,
+		transport: http.DefaultTransport,
+	}
+
+	if os.Getenv(EnvMode) == string(Record) {
+		j.mode = Record
+	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	switch j.mode {
+	case Record:
+		t.Cleanup(j.save)
+	case Replay:
+		err := j.load()
+		if err != nil {
+			t.Fatalf("journal: %v", err)
+		}
+		t.Cleanup(j.checkReplayed)
+	default:
+		t.Fatalf("journal: unknown mode %q", j.mode)
+	}
+
+	return j
+}
+
+// Mode returns the mode of the journal.
+func (j *Journal) Mode() Mode {
+	return j.mode
+}
+
+// Client returns an HTTP client using the journal as transport.
+func (j *Journal) Client() *http.Client {
+	return &http.Client{Transport: j}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (j *Journal) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := j.sanitizeRequest(req, body)
+
+	if j.mode == Replay {
+		return j.replay(req, recorded)
+	}
+
+	return j.record(req, recorded)
+}
+
+func (j *Journal) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.position >= len(j.interactions) {
+		return nil, fmt.Errorf("journal: unexpected request %s %s: no more recorded interactions", recorded.Method, recorded.URL)
+	}
+
+	interaction := j.interactions[j.position]
+
+	if !matches(interaction.Request, recorded) {
+		return nil, fmt.Errorf("journal: unexpected request %s %s (body: %q): expected %s %s (body: %q)",
+			recorded.Method, recorded.URL, recorded.Body,
+			interaction.Request.Method, interaction.Request.URL, interaction.Request.Body)
+	}
+
+	j.position++
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Header.Clone(),
+		Body:          ioutil.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (j *Journal) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := j.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(raw))
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.interactions = append(j.interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     j.sanitizeHeader(resp.Header),
+			Body:       j.sanitize(string(raw)),
+		},
+	})
+
+	return resp, nil
+}
+
+func (j *Journal) load() error {
+	raw, err := ioutil.ReadFile(j.path)
+	if err != nil {
+		return fmt.Errorf("unable to read the golden file (use %s=%s to record it): %w", EnvMode, Record, err)
+	}
+
+	return json.Unmarshal(raw, &j.interactions)
+}
+
+func (j *Journal) save() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.t.Failed() {
+		j.t.Logf("journal: the test failed, the golden file %s is not written", j.path)
+		return
+	}
+
+	raw, err := json.MarshalIndent(j.interactions, "", "  ")
+	if err != nil {
+		j.t.Errorf("journal: %v", err)
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(j.path), 0755)
+	if err != nil {
+		j.t.Errorf("journal: %v", err)
+		return
+	}
+
+	err = ioutil.WriteFile(j.path, append(raw, '\n'), 0644)
+	if err != nil {
+		j.t.Errorf("journal: %v", err)
+	}
+}
+
+func (j *Journal) checkReplayed() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.position < len(j.interactions) {
+		j.t.Errorf("journal: %d interaction(s) of %s have not been replayed", len(j.interactions)-j.position, j.path)
+	}
+}
+
+func (j *Journal) sanitizeRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Method: req.Method,
+		URL:    j.sanitize(req.URL.String()),
+		Header: j.sanitizeHeader(req.Header),
+		Body:   j.sanitize(string(body)),
+	}
+}
+
+func (j *Journal) sanitizeHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	sanitized := make(http.Header, len(header))
+	for name, values := range header {
+		for _, value := range values {
+			if containsString(j.redactedHeaders, name) {
+				value = redacted
+			}
+			sanitized.Add(name, j.sanitize(value))
+		}
+	}
+
+	return sanitized
+}
+
+func (j *Journal) sanitize(value string) string {
+	if len(j.replacer) == 0 {
+		return value
+	}
+
+	return strings.NewReplacer(j.replacer...).Replace(value)
+}
+
+// matches compares the parts of the requests which are relevant to identify them.
+// The headers are ignored because they may contain volatile values (dates, signatures, etc.).
+func matches(expected, actual Request) bool {
+	return expected.Method == actual.Method &&
+		expected.URL == actual.URL &&
+		expected.Body == actual.Body
+}
+
+// readBody reads the body of a request and makes it readable again.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	raw, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(raw))
+
+	return raw, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package journal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_recordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		rw.Header().Set("X-Secret", "s3cr3t")
+		_, _ = fmt.Fprintf(rw, "%s %s %s", req.URL.Query().Get("token"), req.Header.Get("Authorization"), body)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "lego-journal")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "fixtures", "journal.json")
+
+	t.Run("record", func(t *testing.T) {
+		j := New(t, path, WithMode(Record), WithSecret("s3cr3t", "TOKEN"), WithRedactedHeaders("Authorization"))
+
+		body := doRequest(t, j.Client(), server.URL+"/update?token=s3cr3t", "Bearer s3cr3t")
+		assert.Equal(t, "s3cr3t Bearer s3cr3t payload", body)
+	})
+
+	raw, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	golden := string(raw)
+	assert.NotContains(t, golden, "s3cr3t")
+	assert.Contains(t, golden, "token=TOKEN")
+	assert.Contains(t, golden, redacted)
+
+	t.Run("replay", func(t *testing.T) {
+		j := New(t, path, WithMode(Replay))
+
+		body := doRequest(t, j.Client(), server.URL+"/update?token=TOKEN", "Bearer TOKEN")
+		assert.Equal(t, "TOKEN Bearer TOKEN payload", body)
+	})
+}
+
+func TestJournal_replay_unexpectedRequest(t *testing.T) {
+	j := New(t, filepath.FromSlash("fixtures/journal.json"), WithMode(Replay))
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/unknown", nil)
+	require.NoError(t, err)
+
+	_, err = j.RoundTrip(req)
+	require.Error(t, err)
+
+	// consumes the recorded interaction.
+	req, err = http.NewRequest(http.MethodPost, "https://example.com/update?token=TOKEN", strings.NewReader("payload"))
+	require.NoError(t, err)
+
+	resp, err := j.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func doRequest(t *testing.T, client *http.Client, rawURL, authorization string) string {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, rawURL, strings.NewReader("payload"))
+	require.NoError(t, err)
+
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return string(raw)
+}
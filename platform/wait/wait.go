@@ -5,14 +5,20 @@ import (
 	"time"
 
 	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/clock"
 )
 
 // For polls the given function 'f', once every 'interval', up to 'timeout'.
 func For(msg string, timeout, interval time.Duration, f func() (bool, error)) error {
+	return ForWithClock(clock.Real, msg, timeout, interval, f)
+}
+
+// ForWithClock polls the given function 'f', once every 'interval', up to 'timeout', using the given clock.
+func ForWithClock(clk clock.Clock, msg string, timeout, interval time.Duration, f func() (bool, error)) error {
 	log.Infof("Wait for %s [timeout: %s, interval: %s]", msg, timeout, interval)
 
 	var lastErr error
-	timeUp := time.After(timeout)
+	timeUp := clk.After(timeout)
 	for {
 		select {
 		case <-timeUp:
@@ -28,6 +34,6 @@ func For(msg string, timeout, interval time.Duration, f func() (bool, error)) er
 			lastErr = err
 		}
 
-		time.Sleep(interval)
+		clk.Sleep(interval)
 	}
 }
@@ -3,6 +3,10 @@ package wait
 import (
 	"testing"
 	"time"
+
+	"github.com/go-acme/lego/v3/platform/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestForTimeout(t *testing.T) {
@@ -24,3 +28,31 @@ func TestForTimeout(t *testing.T) {
 		}
 	}
 }
+
+func TestForWithClock_timeout(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	start := clk.Now()
+
+	var calls int
+	err := ForWithClock(clk, "", 3*time.Minute, 1*time.Minute, func() (bool, error) {
+		calls++
+		return false, nil
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3*time.Minute, clk.Now().Sub(start))
+}
+
+func TestForWithClock_success(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+
+	var calls int
+	err := ForWithClock(clk, "", 3*time.Minute, 1*time.Minute, func() (bool, error) {
+		calls++
+		return calls == 2, nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+}
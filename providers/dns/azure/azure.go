@@ -3,7 +3,6 @@
 package azure
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -11,13 +10,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/dns/mgmt/2017-09-01/dns"
 	"github.com/Azure/go-autorest/autorest"
-	"github.com/Azure/go-autorest/autorest/adal"
-	"github.com/Azure/go-autorest/autorest/azure"
-	"github.com/Azure/go-autorest/autorest/azure/auth"
-	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/platform/cloudcreds"
 	"github.com/go-acme/lego/v3/platform/config/env"
 )
 
@@ -33,6 +29,8 @@ const (
 	EnvTenantID         = envNamespace + "TENANT_ID"
 	EnvClientID         = envNamespace + "CLIENT_ID"
 	EnvClientSecret     = envNamespace + "CLIENT_SECRET"
+	EnvAuthMethod       = envNamespace + "AUTH_METHOD"
+	EnvPrivateZone      = envNamespace + "PRIVATE_ZONE"
 
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
@@ -51,6 +49,11 @@ type Config struct {
 
 	MetadataEndpoint string
 
+	// AuthMethod forces an authentication method: "env", "msi" (managed identity), "wli" (workload identity).
+	AuthMethod string
+	// PrivateZone targets the Azure Private DNS zones instead of the public DNS zones.
+	PrivateZone bool
+
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	TTL                int
@@ -64,20 +67,23 @@ func NewDefaultConfig() *Config {
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
 		MetadataEndpoint:   env.GetOrFile(EnvMetadataEndpoint),
+		AuthMethod:         env.GetOrFile(EnvAuthMethod),
+		PrivateZone:        env.GetOrDefaultBool(EnvPrivateZone, false),
 	}
 }
 
 // DNSProvider is an implementation of the challenge.Provider interface
 type DNSProvider struct {
-	config     *Config
-	authorizer autorest.Authorizer
+	provider challenge.ProviderTimeout
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for azure.
 // Credentials can be passed in the environment variables:
 // AZURE_CLIENT_ID, AZURE_CLIENT_SECRET, AZURE_SUBSCRIPTION_ID, AZURE_TENANT_ID, AZURE_RESOURCE_GROUP
 // If the credentials are _not_ set via the environment,
-// then it will attempt to get a bearer token via the instance metadata service.
+// then it will attempt to use a workload identity (AZURE_FEDERATED_TOKEN_FILE),
+// or to get a bearer token via the instance metadata service (managed identity).
+// AZURE_AUTH_METHOD forces the authentication method.
 // see: https://github.com/Azure/go-autorest/blob/v10.14.0/autorest/azure/auth/auth.go#L38-L42
 func NewDNSProvider() (*DNSProvider, error) {
 	config := NewDefaultConfig()
@@ -129,108 +135,27 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		config.ResourceGroup = resGroup
 	}
 
-	return &DNSProvider{config: config, authorizer: authorizer}, nil
+	if config.PrivateZone {
+		return &DNSProvider{provider: &dnsProviderPrivate{config: config, authorizer: authorizer}}, nil
+	}
+
+	return &DNSProvider{provider: &dnsProviderPublic{config: config, authorizer: authorizer}}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
 // Adjusting here to cope with spikes in propagation times.
 func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
-	return d.config.PropagationTimeout, d.config.PollingInterval
+	return d.provider.Timeout()
 }
 
 // Present creates a TXT record to fulfill the dns-01 challenge
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
-	ctx := context.Background()
-	fqdn, value := dns01.GetRecord(domain, keyAuth)
-
-	zone, err := d.getHostedZoneID(ctx, fqdn)
-	if err != nil {
-		return fmt.Errorf("azure: %w", err)
-	}
-
-	rsc := dns.NewRecordSetsClient(d.config.SubscriptionID)
-	rsc.Authorizer = d.authorizer
-
-	relative := toRelativeRecord(fqdn, dns01.ToFqdn(zone))
-
-	// Get existing record set
-	rset, err := rsc.Get(ctx, d.config.ResourceGroup, zone, relative, dns.TXT)
-	if err != nil {
-		detailedError, ok := err.(autorest.DetailedError)
-		if !ok || detailedError.StatusCode != http.StatusNotFound {
-			return fmt.Errorf("azure: %w", err)
-		}
-	}
-
-	// Construct unique TXT records using map
-	uniqRecords := map[string]struct{}{value: {}}
-	if rset.RecordSetProperties != nil && rset.TxtRecords != nil {
-		for _, txtRecord := range *rset.TxtRecords {
-			// Assume Value doesn't contain multiple strings
-			if txtRecord.Value != nil && len(*txtRecord.Value) > 0 {
-				uniqRecords[(*txtRecord.Value)[0]] = struct{}{}
-			}
-		}
-	}
-
-	var txtRecords []dns.TxtRecord
-	for txt := range uniqRecords {
-		txtRecords = append(txtRecords, dns.TxtRecord{Value: &[]string{txt}})
-	}
-
-	rec := dns.RecordSet{
-		Name: &relative,
-		RecordSetProperties: &dns.RecordSetProperties{
-			TTL:        to.Int64Ptr(int64(d.config.TTL)),
-			TxtRecords: &txtRecords,
-		},
-	}
-
-	_, err = rsc.CreateOrUpdate(ctx, d.config.ResourceGroup, zone, relative, dns.TXT, rec, "", "")
-	if err != nil {
-		return fmt.Errorf("azure: %w", err)
-	}
-	return nil
+	return d.provider.Present(domain, token, keyAuth)
 }
 
 // CleanUp removes the TXT record matching the specified parameters
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
-	ctx := context.Background()
-	fqdn, _ := dns01.GetRecord(domain, keyAuth)
-
-	zone, err := d.getHostedZoneID(ctx, fqdn)
-	if err != nil {
-		return fmt.Errorf("azure: %w", err)
-	}
-
-	relative := toRelativeRecord(fqdn, dns01.ToFqdn(zone))
-	rsc := dns.NewRecordSetsClient(d.config.SubscriptionID)
-	rsc.Authorizer = d.authorizer
-
-	_, err = rsc.Delete(ctx, d.config.ResourceGroup, zone, relative, dns.TXT, "")
-	if err != nil {
-		return fmt.Errorf("azure: %w", err)
-	}
-	return nil
-}
-
-// Checks that azure has a zone for this domain name.
-func (d *DNSProvider) getHostedZoneID(ctx context.Context, fqdn string) (string, error) {
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
-	if err != nil {
-		return "", err
-	}
-
-	dc := dns.NewZonesClient(d.config.SubscriptionID)
-	dc.Authorizer = d.authorizer
-
-	zone, err := dc.Get(ctx, d.config.ResourceGroup, dns01.UnFqdn(authZone))
-	if err != nil {
-		return "", err
-	}
-
-	// zone.Name shouldn't have a trailing dot(.)
-	return to.String(zone.Name), nil
+	return d.provider.CleanUp(domain, token, keyAuth)
 }
 
 // Returns the relative record to the domain
@@ -239,22 +164,13 @@ func toRelativeRecord(domain, zone string) string {
 }
 
 func getAuthorizer(config *Config) (autorest.Authorizer, error) {
-	if config.ClientID != "" && config.ClientSecret != "" && config.TenantID != "" {
-		oauthConfig, err := adal.NewOAuthConfig(azure.PublicCloud.ActiveDirectoryEndpoint, config.TenantID)
-		if err != nil {
-			return nil, err
-		}
-
-		spt, err := adal.NewServicePrincipalToken(*oauthConfig, config.ClientID, config.ClientSecret, azure.PublicCloud.ResourceManagerEndpoint)
-		if err != nil {
-			return nil, err
-		}
-
-		spt.SetSender(config.HTTPClient)
-		return autorest.NewBearerAuthorizer(spt), nil
-	}
-
-	return auth.NewAuthorizerFromEnvironment()
+	return cloudcreds.NewAzureAuthorizer(cloudcreds.AzureConfig{
+		TenantID:     config.TenantID,
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		AuthMethod:   config.AuthMethod,
+		HTTPClient:   config.HTTPClient,
+	})
 }
 
 // Fetches metadata from environment or he instance metadata service
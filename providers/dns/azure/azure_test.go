@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,7 +18,9 @@ var envTest = tester.NewEnvTest(
 	EnvClientSecret,
 	EnvSubscriptionID,
 	EnvTenantID,
-	EnvResourceGroup).
+	EnvResourceGroup,
+	EnvAuthMethod,
+	EnvPrivateZone).
 	WithDomain(envDomain)
 
 func TestNewDNSProvider(t *testing.T) {
@@ -61,7 +64,7 @@ func TestNewDNSProvider(t *testing.T) {
 			if len(test.expected) == 0 {
 				require.NoError(t, err)
 				require.NotNil(t, p)
-				require.NotNil(t, p.config)
+				require.NotNil(t, p.provider)
 			} else {
 				require.EqualError(t, err, test.expected)
 			}
@@ -146,7 +149,7 @@ func TestNewDNSProviderConfig(t *testing.T) {
 			if len(test.expected) == 0 {
 				require.NoError(t, err)
 				require.NotNil(t, p)
-				require.NotNil(t, p.config)
+				require.NotNil(t, p.provider)
 			} else {
 				require.EqualError(t, err, test.expected)
 			}
@@ -154,6 +157,25 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestNewDNSProviderConfig_privateZone(t *testing.T) {
+	config := NewDefaultConfig()
+	config.ClientID = "A"
+	config.ClientSecret = "B"
+	config.TenantID = "C"
+	config.SubscriptionID = "D"
+	config.ResourceGroup = "E"
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+	assert.IsType(t, &dnsProviderPublic{}, p.provider)
+
+	config.PrivateZone = true
+
+	p, err = NewDNSProviderConfig(config)
+	require.NoError(t, err)
+	assert.IsType(t, &dnsProviderPrivate{}, p.provider)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-acme/lego/v3/challenge/dns01"
 	"github.com/go-acme/lego/v3/platform/config/env"
+	"github.com/go-acme/lego/v3/platform/httpclient"
 	"github.com/labbsr0x/bindman-dns-webhook/src/client"
 )
 
@@ -36,9 +37,7 @@ func NewDefaultConfig() *Config {
 	return &Config{
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
-		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, time.Minute),
-		},
+		HTTPClient:         httpclient.New(env.GetOrDefaultSecond(EnvHTTPTimeout, time.Minute)),
 	}
 }
 
@@ -1,73 +1,195 @@
 package cloudflare
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/cloudflare/cloudflare-go"
 	"github.com/go-acme/lego/v3/challenge/dns01"
 )
 
+// apiBaseURL overrides the Cloudflare API endpoint when not empty (used by tests).
+var apiBaseURL string
+
+const recordsPerPage = 100
+
+// dnsRecord a DNS record with its comment.
+type dnsRecord struct {
+	ID        string    `json:"id,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Content   string    `json:"content,omitempty"`
+	TTL       int       `json:"ttl,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
 type metaClient struct {
 	clientEdit *cloudflare.API // needs Zone/DNS/Edit permissions
 	clientRead *cloudflare.API // needs Zone/Zone/Read permissions
 
+	// zoneClients holds the clients built from zone scoped tokens, by zone name.
+	zoneClients map[string]*cloudflare.API
+
 	zones   map[string]string // caches calls to ZoneIDByName, see lookupZoneID()
 	zonesMu *sync.RWMutex
+
+	editClients map[string]*cloudflare.API // edit clients by zone ID, filled by ZoneIDByName
 }
 
 func newClient(config *Config) (*metaClient, error) {
+	client := &metaClient{
+		zoneClients: make(map[string]*cloudflare.API),
+		zones:       make(map[string]string),
+		zonesMu:     &sync.RWMutex{},
+		editClients: make(map[string]*cloudflare.API),
+	}
+
+	for zone, token := range config.ZoneTokens {
+		api, err := newAPIWithToken(token, config)
+		if err != nil {
+			return nil, fmt.Errorf("token for zone %s: %w", zone, err)
+		}
+
+		client.zoneClients[normalizeZone(zone)] = api
+	}
+
+	// zone scoped tokens only.
+	if config.AuthToken == "" && config.AuthKey == "" && config.AuthEmail == "" && len(client.zoneClients) > 0 {
+		return client, nil
+	}
+
 	// with AuthKey/AuthEmail we can access all available APIs
 	if config.AuthToken == "" {
-		client, err := cloudflare.New(config.AuthKey, config.AuthEmail, cloudflare.HTTPClient(config.HTTPClient))
+		api, err := cloudflare.New(config.AuthKey, config.AuthEmail, cloudflare.HTTPClient(config.HTTPClient))
 		if err != nil {
 			return nil, err
 		}
+		setBaseURL(api)
 
-		return &metaClient{
-			clientEdit: client,
-			clientRead: client,
-			zones:      make(map[string]string),
-			zonesMu:    &sync.RWMutex{},
-		}, nil
+		client.clientEdit = api
+		client.clientRead = api
+		return client, nil
 	}
 
-	dns, err := cloudflare.NewWithAPIToken(config.AuthToken, cloudflare.HTTPClient(config.HTTPClient))
+	dns, err := newAPIWithToken(config.AuthToken, config)
 	if err != nil {
 		return nil, err
 	}
 
+	client.clientEdit = dns
+	client.clientRead = dns
+
 	if config.ZoneToken == "" || config.ZoneToken == config.AuthToken {
-		return &metaClient{
-			clientEdit: dns,
-			clientRead: dns,
-			zones:      make(map[string]string),
-			zonesMu:    &sync.RWMutex{},
-		}, nil
+		return client, nil
 	}
 
-	zone, err := cloudflare.NewWithAPIToken(config.ZoneToken, cloudflare.HTTPClient(config.HTTPClient))
+	zone, err := newAPIWithToken(config.ZoneToken, config)
+	if err != nil {
+		return nil, err
+	}
+
+	client.clientRead = zone
+	return client, nil
+}
+
+func newAPIWithToken(token string, config *Config) (*cloudflare.API, error) {
+	api, err := cloudflare.NewWithAPIToken(token, cloudflare.HTTPClient(config.HTTPClient))
 	if err != nil {
 		return nil, err
 	}
+	setBaseURL(api)
 
-	return &metaClient{
-		clientEdit: dns,
-		clientRead: zone,
-		zones:      make(map[string]string),
-		zonesMu:    &sync.RWMutex{},
-	}, nil
+	return api, nil
 }
 
-func (m *metaClient) CreateDNSRecord(zoneID string, rr cloudflare.DNSRecord) (*cloudflare.DNSRecordResponse, error) {
-	return m.clientEdit.CreateDNSRecord(zoneID, rr)
+func setBaseURL(api *cloudflare.API) {
+	if apiBaseURL != "" {
+		api.BaseURL = apiBaseURL
+	}
+}
+
+// verifyZoneTokens runs the permission self-check on every zone scoped token.
+func (m *metaClient) verifyZoneTokens() error {
+	for zone, api := range m.zoneClients {
+		if err := verifyZoneToken(api, zone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateDNSRecord creates a record with its comment (not supported by cloudflare.DNSRecord).
+func (m *metaClient) CreateDNSRecord(zoneID string, rr dnsRecord) (*dnsRecord, error) {
+	payload := struct {
+		Type    string `json:"type"`
+		Name    string `json:"name"`
+		Content string `json:"content"`
+		TTL     int    `json:"ttl"`
+		Comment string `json:"comment,omitempty"`
+	}{Type: rr.Type, Name: rr.Name, Content: rr.Content, TTL: rr.TTL, Comment: rr.Comment}
+
+	raw, err := m.editClient(zoneID).Raw(http.MethodPost, "/zones/"+zoneID+"/dns_records", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var record dnsRecord
+	err = json.Unmarshal(raw, &record)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// DNSRecord returns a record with its comment.
+func (m *metaClient) DNSRecord(zoneID, recordID string) (*dnsRecord, error) {
+	raw, err := m.editClient(zoneID).Raw(http.MethodGet, "/zones/"+zoneID+"/dns_records/"+recordID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var record dnsRecord
+	err = json.Unmarshal(raw, &record)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
 }
 
-func (m *metaClient) DNSRecords(zoneID string, rr cloudflare.DNSRecord) ([]cloudflare.DNSRecord, error) {
-	return m.clientEdit.DNSRecords(zoneID, rr)
+// DNSRecords lists the records of a type with their comments.
+func (m *metaClient) DNSRecords(zoneID, recordType string) ([]dnsRecord, error) {
+	var records []dnsRecord
+
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("/zones/%s/dns_records?type=%s&per_page=%d&page=%d", zoneID, recordType, recordsPerPage, page)
+
+		raw, err := m.editClient(zoneID).Raw(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result []dnsRecord
+		err = json.Unmarshal(raw, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, result...)
+
+		if len(result) < recordsPerPage {
+			return records, nil
+		}
+	}
 }
 
 func (m *metaClient) DeleteDNSRecord(zoneID, recordID string) error {
-	return m.clientEdit.DeleteDNSRecord(zoneID, recordID)
+	return m.editClient(zoneID).DeleteDNSRecord(zoneID, recordID)
 }
 
 func (m *metaClient) ZoneIDByName(fdqn string) (string, error) {
@@ -79,13 +201,71 @@ func (m *metaClient) ZoneIDByName(fdqn string) (string, error) {
 		return id, nil
 	}
 
-	id, err := m.clientRead.ZoneIDByName(dns01.UnFqdn(fdqn))
+	readClient, editClient := m.clientRead, m.clientEdit
+	if api, ok := m.zoneClients[normalizeZone(fdqn)]; ok {
+		readClient, editClient = api, api
+	}
+
+	if readClient == nil {
+		return "", fmt.Errorf("no API token configured for zone %s", dns01.UnFqdn(fdqn))
+	}
+
+	id, err := readClient.ZoneIDByName(dns01.UnFqdn(fdqn))
 	if err != nil {
 		return "", err
 	}
 
 	m.zonesMu.Lock()
 	m.zones[fdqn] = id
+	m.editClients[id] = editClient
 	m.zonesMu.Unlock()
 	return id, nil
 }
+
+// ListZones lists the zones reachable with the configured tokens.
+func (m *metaClient) ListZones() ([]cloudflare.Zone, error) {
+	var zones []cloudflare.Zone
+
+	if m.clientRead != nil {
+		all, err := m.clientRead.ListZones()
+		if err != nil {
+			return nil, err
+		}
+
+		m.zonesMu.Lock()
+		for _, zone := range all {
+			if _, ok := m.zoneClients[normalizeZone(zone.Name)]; ok {
+				continue
+			}
+			zones = append(zones, zone)
+			m.editClients[zone.ID] = m.clientEdit
+		}
+		m.zonesMu.Unlock()
+	}
+
+	for name, api := range m.zoneClients {
+		scoped, err := api.ListZones(name)
+		if err != nil {
+			return nil, fmt.Errorf("zone %s: %w", name, err)
+		}
+
+		m.zonesMu.Lock()
+		for _, zone := range scoped {
+			zones = append(zones, zone)
+			m.editClients[zone.ID] = api
+		}
+		m.zonesMu.Unlock()
+	}
+
+	return zones, nil
+}
+
+func (m *metaClient) editClient(zoneID string) *cloudflare.API {
+	m.zonesMu.RLock()
+	defer m.zonesMu.RUnlock()
+
+	if api, ok := m.editClients[zoneID]; ok {
+		return api
+	}
+	return m.clientEdit
+}
@@ -5,13 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
-	cloudflare "github.com/cloudflare/cloudflare-go"
+	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/challenge/dns01"
 	"github.com/go-acme/lego/v3/log"
 	"github.com/go-acme/lego/v3/platform/config/env"
+	"github.com/go-acme/lego/v3/platform/httpclient"
 )
 
 const (
@@ -26,6 +28,11 @@ type Config struct {
 	AuthToken string
 	ZoneToken string
 
+	// ZoneTokens maps zone names to API tokens scoped to those zones.
+	ZoneTokens map[string]string
+	// SkipTokenVerification disables the permission self-check of the zone tokens.
+	SkipTokenVerification bool
+
 	TTL                int
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
@@ -35,12 +42,11 @@ type Config struct {
 // NewDefaultConfig returns a default configuration for the DNSProvider
 func NewDefaultConfig() *Config {
 	return &Config{
-		TTL:                env.GetOrDefaultInt("CLOUDFLARE_TTL", minTTL),
-		PropagationTimeout: env.GetOrDefaultSecond("CLOUDFLARE_PROPAGATION_TIMEOUT", 2*time.Minute),
-		PollingInterval:    env.GetOrDefaultSecond("CLOUDFLARE_POLLING_INTERVAL", 2*time.Second),
-		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond("CLOUDFLARE_HTTP_TIMEOUT", 30*time.Second),
-		},
+		TTL:                   env.GetOrDefaultInt("CLOUDFLARE_TTL", minTTL),
+		PropagationTimeout:    env.GetOrDefaultSecond("CLOUDFLARE_PROPAGATION_TIMEOUT", 2*time.Minute),
+		PollingInterval:       env.GetOrDefaultSecond("CLOUDFLARE_POLLING_INTERVAL", 2*time.Second),
+		SkipTokenVerification: env.GetOrDefaultBool("CLOUDFLARE_SKIP_TOKEN_VERIFICATION", false),
+		HTTPClient:            httpclient.New(env.GetOrDefaultSecond("CLOUDFLARE_HTTP_TIMEOUT", 30*time.Second)),
 	}
 }
 
@@ -65,7 +71,22 @@ type DNSProvider struct {
 // Instead setup a API token with both Zone:Read and DNS:Edit permission, and pass the CLOUDFLARE_DNS_API_TOKEN environment variable.
 // You can split the Zone:Read and DNS:Edit permissions across multiple API tokens:
 // in this case pass both CLOUDFLARE_ZONE_API_TOKEN and CLOUDFLARE_DNS_API_TOKEN accordingly.
+//
+// To never use a token with rights on more than one zone,
+// provide CLOUDFLARE_ZONE_TOKENS_FILE: a file mapping each zone to its own token.
 func NewDNSProvider() (*DNSProvider, error) {
+	if filename := env.GetOrDefaultString("CLOUDFLARE_ZONE_TOKENS_FILE", env.GetOrDefaultString("CF_ZONE_TOKENS_FILE", "")); filename != "" {
+		tokens, err := readZoneTokens(filename)
+		if err != nil {
+			return nil, fmt.Errorf("cloudflare: %w", err)
+		}
+
+		config := NewDefaultConfig()
+		config.ZoneTokens = tokens
+
+		return NewDNSProviderConfig(config)
+	}
+
 	values, err := env.GetWithFallback(
 		[]string{"CLOUDFLARE_EMAIL", "CF_API_EMAIL"},
 		[]string{"CLOUDFLARE_API_KEY", "CF_API_KEY"},
@@ -105,6 +126,12 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, fmt.Errorf("cloudflare: %w", err)
 	}
 
+	if !config.SkipTokenVerification {
+		if err = client.verifyZoneTokens(); err != nil {
+			return nil, fmt.Errorf("cloudflare: %w", err)
+		}
+	}
+
 	return &DNSProvider{
 		client:    client,
 		config:    config,
@@ -132,27 +159,24 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		return fmt.Errorf("cloudflare: failed to find zone %s: %w", authZone, err)
 	}
 
-	dnsRecord := cloudflare.DNSRecord{
+	dnsRecord := dnsRecord{
 		Type:    "TXT",
 		Name:    dns01.UnFqdn(fqdn),
 		Content: value,
 		TTL:     d.config.TTL,
+		Comment: dns01.OwnershipComment(time.Now()),
 	}
 
-	response, err := d.client.CreateDNSRecord(zoneID, dnsRecord)
+	record, err := d.client.CreateDNSRecord(zoneID, dnsRecord)
 	if err != nil {
 		return fmt.Errorf("cloudflare: failed to create TXT record: %w", err)
 	}
 
-	if !response.Success {
-		return fmt.Errorf("cloudflare: failed to create TXT record: %+v %+v", response.Errors, response.Messages)
-	}
-
 	d.recordIDsMu.Lock()
-	d.recordIDs[token] = response.Result.ID
+	d.recordIDs[token] = record.ID
 	d.recordIDsMu.Unlock()
 
-	log.Infof("cloudflare: new record for %s, ID %s", domain, response.Result.ID)
+	log.Infof("cloudflare: new record for %s, ID %s", domain, record.ID)
 
 	return nil
 }
@@ -179,9 +203,9 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		return fmt.Errorf("cloudflare: unknown record ID for '%s'", fqdn)
 	}
 
-	err = d.client.DeleteDNSRecord(zoneID, recordID)
+	err = d.deleteOwnedRecord(zoneID, recordID)
 	if err != nil {
-		log.Printf("cloudflare: failed to delete TXT record: %w", err)
+		log.Printf("cloudflare: failed to delete TXT record: %v", err)
 	}
 
 	// Delete record ID from map
@@ -191,3 +215,69 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 
 	return nil
 }
+
+// ListChallengeRecords lists the challenge TXT records created by lego (carrying the ownership marker)
+// in all the zones reachable with the configured tokens.
+func (d *DNSProvider) ListChallengeRecords() ([]challenge.Record, error) {
+	zones, err := d.client.ListZones()
+	if err != nil {
+		return nil, fmt.Errorf("cloudflare: failed to list zones: %w", err)
+	}
+
+	var challengeRecords []challenge.Record
+	for _, zone := range zones {
+		records, err := d.client.DNSRecords(zone.ID, "TXT")
+		if err != nil {
+			return nil, fmt.Errorf("cloudflare: failed to list TXT records of zone %s: %w", zone.Name, err)
+		}
+
+		for _, record := range records {
+			if !strings.HasPrefix(record.Name, "_acme-challenge.") {
+				continue
+			}
+
+			created, owned := dns01.ParseOwnershipComment(record.Comment)
+			if !owned {
+				continue
+			}
+
+			if created.IsZero() {
+				created = record.CreatedOn
+			}
+
+			challengeRecords = append(challengeRecords, challenge.Record{
+				FQDN:    dns01.ToFqdn(record.Name),
+				Value:   record.Content,
+				Zone:    zone.ID,
+				ID:      record.ID,
+				Created: created,
+			})
+		}
+	}
+
+	return challengeRecords, nil
+}
+
+// DeleteChallengeRecord deletes a record returned by ListChallengeRecords.
+func (d *DNSProvider) DeleteChallengeRecord(record challenge.Record) error {
+	err := d.deleteOwnedRecord(record.Zone, record.ID)
+	if err != nil {
+		return fmt.Errorf("cloudflare: failed to delete TXT record %s: %w", record.FQDN, err)
+	}
+	return nil
+}
+
+// deleteOwnedRecord deletes a record only if it carries the ownership marker of lego,
+// so a record of the user with a colliding name is never deleted.
+func (d *DNSProvider) deleteOwnedRecord(zoneID, recordID string) error {
+	record, err := d.client.DNSRecord(zoneID, recordID)
+	if err != nil {
+		return err
+	}
+
+	if _, owned := dns01.ParseOwnershipComment(record.Comment); !owned {
+		return fmt.Errorf("the record %s (ID %s) was not created by lego", record.Name, recordID)
+	}
+
+	return d.client.DeleteDNSRecord(zoneID, recordID)
+}
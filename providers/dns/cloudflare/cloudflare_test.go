@@ -1,9 +1,14 @@
 package cloudflare
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/platform/tester"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,7 +18,9 @@ var envTest = tester.NewEnvTest(
 	"CLOUDFLARE_EMAIL",
 	"CLOUDFLARE_API_KEY",
 	"CLOUDFLARE_DNS_API_TOKEN",
-	"CLOUDFLARE_ZONE_API_TOKEN").
+	"CLOUDFLARE_ZONE_API_TOKEN",
+	"CLOUDFLARE_ZONE_TOKENS_FILE",
+	"CF_ZONE_TOKENS_FILE").
 	WithDomain("CLOUDFLARE_DOMAIN")
 
 func TestNewDNSProvider(t *testing.T) {
@@ -265,6 +272,86 @@ func TestNewDNSProviderConfig(t *testing.T) {
 	}
 }
 
+func TestDNSProvider_ListChallengeRecords(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	apiBaseURL = server.URL
+	defer func() { apiBaseURL = "" }()
+
+	mux.HandleFunc("/zones", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, `{"success":true,"result":[{"id":"zone1","name":"example.com"}],"result_info":{"page":1,"total_pages":1}}`)
+	})
+
+	mux.HandleFunc("/zones/zone1/dns_records", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, `{"success":true,"result":[
+{"id":"rec1","type":"TXT","name":"_acme-challenge.example.com","content":"aaa","comment":"lego-acme-challenge","created_on":"2020-04-07T09:19:38Z"},
+{"id":"rec2","type":"TXT","name":"example.com","content":"v=spf1 -all","comment":"lego-acme-challenge"},
+{"id":"rec3","type":"TXT","name":"_acme-challenge.www.example.com","content":"bbb","comment":"lego-acme-challenge created=2020-04-06T10:00:00Z","created_on":"2020-04-07T09:19:38Z"},
+{"id":"rec4","type":"TXT","name":"_acme-challenge.mail.example.com","content":"verification of the user","created_on":"2020-04-07T09:19:38Z"}
+],"result_info":{"page":1,"total_pages":1}}`)
+	})
+
+	config := NewDefaultConfig()
+	config.ZoneTokens = map[string]string{"example.com": "zone-token"}
+	config.SkipTokenVerification = true
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	records, err := p.ListChallengeRecords()
+	require.NoError(t, err)
+
+	expected := []challenge.Record{
+		{FQDN: "_acme-challenge.example.com.", Value: "aaa", Zone: "zone1", ID: "rec1", Created: time.Date(2020, time.April, 7, 9, 19, 38, 0, time.UTC)},
+		{FQDN: "_acme-challenge.www.example.com.", Value: "bbb", Zone: "zone1", ID: "rec3", Created: time.Date(2020, time.April, 6, 10, 0, 0, 0, time.UTC)},
+	}
+	assert.Equal(t, expected, records)
+}
+
+func TestDNSProvider_DeleteChallengeRecord(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	apiBaseURL = server.URL
+	defer func() { apiBaseURL = "" }()
+
+	var deleted []string
+
+	mux.HandleFunc("/zones/zone1/dns_records/", func(rw http.ResponseWriter, req *http.Request) {
+		id := strings.TrimPrefix(req.URL.Path, "/zones/zone1/dns_records/")
+
+		if req.Method == http.MethodDelete {
+			deleted = append(deleted, id)
+			_, _ = fmt.Fprintf(rw, `{"success":true,"result":{"id":%q}}`, id)
+			return
+		}
+
+		comment := "lego-acme-challenge created=2020-04-07T09:19:38Z"
+		if id == "foreign" {
+			comment = "verification of the user"
+		}
+
+		_, _ = fmt.Fprintf(rw, `{"success":true,"result":{"id":%q,"type":"TXT","name":"_acme-challenge.example.com","content":"aaa","comment":%q}}`, id, comment)
+	})
+
+	config := NewDefaultConfig()
+	config.AuthToken = "token"
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = p.DeleteChallengeRecord(challenge.Record{FQDN: "_acme-challenge.example.com.", Zone: "zone1", ID: "owned"})
+	require.NoError(t, err)
+
+	err = p.DeleteChallengeRecord(challenge.Record{FQDN: "_acme-challenge.example.com.", Zone: "zone1", ID: "foreign"})
+	require.EqualError(t, err, "cloudflare: failed to delete TXT record _acme-challenge.example.com.: the record _acme-challenge.example.com (ID foreign) was not created by lego")
+
+	assert.Equal(t, []string{"owned"}, deleted)
+}
+
 func TestLivePresent(t *testing.T) {
 	if !envTest.IsLiveTest() {
 		t.Skip("skipping live test")
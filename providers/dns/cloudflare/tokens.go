@@ -0,0 +1,93 @@
+package cloudflare
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+)
+
+// readZoneTokens reads a zone tokens file.
+// Each non-empty line maps a zone to the API token scoped to it: `example.com=token`.
+// Lines starting with `#` are ignored.
+func readZoneTokens(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	tokens := make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("%s:%d: invalid zone token entry, expected 'zone=token'", filename, n)
+		}
+
+		tokens[normalizeZone(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%s: no zone tokens found", filename)
+	}
+
+	return tokens, nil
+}
+
+func normalizeZone(zone string) string {
+	return strings.ToLower(dns01.UnFqdn(strings.TrimSpace(zone)))
+}
+
+type tokenStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// verifyZoneToken checks that a zone token is active
+// and that it grants access to the zone and its DNS records.
+// The DNS:Edit permission cannot be checked without modifying the zone,
+// so this only proves that the token is not broader than needed and not useless.
+func verifyZoneToken(client *cloudflare.API, zone string) error {
+	raw, err := client.Raw(http.MethodGet, "/user/tokens/verify", nil)
+	if err != nil {
+		return fmt.Errorf("token for zone %s: failed to verify token: %w", zone, err)
+	}
+
+	var status tokenStatus
+	err = json.Unmarshal(raw, &status)
+	if err != nil {
+		return fmt.Errorf("token for zone %s: failed to verify token: %w", zone, err)
+	}
+
+	if status.Status != "active" {
+		return fmt.Errorf("token for zone %s: token %s is %q", zone, status.ID, status.Status)
+	}
+
+	zoneID, err := client.ZoneIDByName(zone)
+	if err != nil {
+		return fmt.Errorf("token for zone %s: missing Zone:Read permission: %w", zone, err)
+	}
+
+	_, err = client.DNSRecords(zoneID, cloudflare.DNSRecord{Type: "TXT"})
+	if err != nil {
+		return fmt.Errorf("token for zone %s: missing DNS permission: %w", zone, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package cloudflare
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadZoneTokens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-cloudflare")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	testCases := []struct {
+		desc     string
+		content  string
+		expected map[string]string
+		error    string
+	}{
+		{
+			desc:    "valid",
+			content: "# tokens\nexample.com=aaa\n\nExample.ORG. = bbb\n",
+			expected: map[string]string{
+				"example.com": "aaa",
+				"example.org": "bbb",
+			},
+		},
+		{
+			desc:    "missing token",
+			content: "example.com=\n",
+			error:   "tokens:1: invalid zone token entry, expected 'zone=token'",
+		},
+		{
+			desc:    "empty",
+			content: "# nothing\n",
+			error:   "tokens: no zone tokens found",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			filename := filepath.Join(dir, "tokens")
+			err := ioutil.WriteFile(filename, []byte(test.content), 0600)
+			require.NoError(t, err)
+
+			tokens, err := readZoneTokens(filename)
+			if test.error != "" {
+				require.EqualError(t, err, filepath.Join(dir, test.error))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, tokens)
+		})
+	}
+}
+
+func TestNewDNSProviderConfig_zoneTokens(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	apiBaseURL = server.URL
+	defer func() { apiBaseURL = "" }()
+
+	mux.HandleFunc("/user/tokens/verify", func(rw http.ResponseWriter, req *http.Request) {
+		status := "active"
+		if req.Header.Get("Authorization") == "Bearer revoked" {
+			status = "disabled"
+		}
+		_, _ = fmt.Fprintf(rw, `{"success":true,"result":{"id":"tok","status":%q}}`, status)
+	})
+
+	mux.HandleFunc("/zones", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer zone-token" || req.URL.Query().Get("name") != "example.com" {
+			_, _ = fmt.Fprint(rw, `{"success":true,"result":[],"result_info":{"page":1,"total_pages":1}}`)
+			return
+		}
+		_, _ = fmt.Fprint(rw, `{"success":true,"result":[{"id":"zone1","name":"example.com"}],"result_info":{"page":1,"total_pages":1}}`)
+	})
+
+	mux.HandleFunc("/zones/zone1/dns_records", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer zone-token" {
+			rw.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(rw, `{"success":false,"errors":[{"code":10000,"message":"Authentication error"}]}`)
+			return
+		}
+		_, _ = fmt.Fprint(rw, `{"success":true,"result":[{"id":"rec1","type":"TXT"}],"result_info":{"page":1,"total_pages":1}}`)
+	})
+
+	testCases := []struct {
+		desc   string
+		tokens map[string]string
+		error  string
+	}{
+		{
+			desc:   "success",
+			tokens: map[string]string{"example.com": "zone-token"},
+		},
+		{
+			desc:   "inactive token",
+			tokens: map[string]string{"example.com": "revoked"},
+			error:  `cloudflare: token for zone example.com: token tok is "disabled"`,
+		},
+		{
+			desc:   "token not scoped to the zone",
+			tokens: map[string]string{"example.com": "other-token"},
+			error:  "cloudflare: token for zone example.com: missing Zone:Read permission: Zone could not be found",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.ZoneTokens = test.tokens
+
+			p, err := NewDNSProviderConfig(config)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+				return
+			}
+			require.NoError(t, err)
+
+			zoneID, err := p.client.ZoneIDByName("example.com.")
+			require.NoError(t, err)
+			assert.Equal(t, "zone1", zoneID)
+
+			records, err := p.client.DNSRecords(zoneID, "TXT")
+			require.NoError(t, err)
+			assert.Len(t, records, 1)
+
+			_, err = p.client.ZoneIDByName("example.org.")
+			require.EqualError(t, err, "no API token configured for zone example.org")
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package dns
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// DelegateProvider presents the records of the delegated domains with a second provider,
+// managing the zone to which their _acme-challenge name is delegated (NS or CNAME).
+// The records of the other domains are presented by the primary provider.
+type DelegateProvider struct {
+	primary  challenge.Provider
+	delegate challenge.Provider
+
+	// targets the domain whose _acme-challenge record is created, by delegated domain.
+	targets map[string]string
+}
+
+// NewDNSChallengeProviderWithDelegation returns a provider using the provider named delegate for the delegated domains,
+// and the provider named primary for the other domains.
+func NewDNSChallengeProviderWithDelegation(primary challenge.Provider, delegate string, delegations []string) (*DelegateProvider, error) {
+	targets, err := ParseDelegations(delegations)
+	if err != nil {
+		return nil, err
+	}
+
+	delegateProvider, err := NewDNSChallengeProviderByName(delegate)
+	if err != nil {
+		return nil, fmt.Errorf("delegate: %w", err)
+	}
+
+	return NewDelegateProvider(primary, delegateProvider, targets), nil
+}
+
+// NewDelegateProvider creates a DelegateProvider.
+// The targets are the domains whose _acme-challenge record is created by the delegate provider, by delegated domain.
+func NewDelegateProvider(primary, delegate challenge.Provider, targets map[string]string) *DelegateProvider {
+	return &DelegateProvider{
+		primary:  primary,
+		delegate: delegate,
+		targets:  targets,
+	}
+}
+
+// ParseDelegations parses the delegated domains: <domain> or <domain>=<target>.
+//
+// <domain>: _acme-challenge.<domain> is a zone (NS delegation) managed by the delegate provider.
+// <domain>=<target>: _acme-challenge.<domain> is an alias (CNAME) of _acme-challenge.<target>, managed by the delegate provider.
+func ParseDelegations(values []string) (map[string]string, error) {
+	targets := make(map[string]string)
+
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+
+		domain := normalizeDelegatedDomain(parts[0])
+		if domain == "" {
+			return nil, fmt.Errorf("invalid delegation %q: empty domain", value)
+		}
+
+		target := domain
+		if len(parts) == 2 {
+			target = normalizeDelegatedDomain(parts[1])
+			if target == "" {
+				return nil, fmt.Errorf("invalid delegation %q: empty target", value)
+			}
+		}
+
+		targets[domain] = target
+	}
+
+	return targets, nil
+}
+
+func normalizeDelegatedDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "*.")
+	domain = strings.TrimPrefix(domain, "_acme-challenge.")
+	return strings.TrimSuffix(domain, ".")
+}
+
+// Present presents the record with the delegate provider if the domain is delegated, with the primary provider otherwise.
+func (d *DelegateProvider) Present(domain, token, keyAuth string) error {
+	provider, target := d.resolve(domain)
+	if target != domain {
+		log.Infof("[%s] the _acme-challenge record is delegated to _acme-challenge.%s", domain, target)
+	}
+
+	return present(provider, target, token, keyAuth)
+}
+
+// CleanUp cleans the record up with the provider which presented it.
+func (d *DelegateProvider) CleanUp(domain, token, keyAuth string) error {
+	provider, target := d.resolve(domain)
+
+	return cleanUp(provider, target, token, keyAuth)
+}
+
+// WrapsProviders implements dns01.ProviderWrapper: the calls of the primary and delegate providers are reported.
+func (d *DelegateProvider) WrapsProviders() {}
+
+// Timeout returns the largest timeout and interval of the providers,
+// as the propagation check doesn't know the provider used for a record.
+func (d *DelegateProvider) Timeout() (timeout, interval time.Duration) {
+	timeout, interval = providerTimeout(d.primary)
+	delegateTimeout, delegateInterval := providerTimeout(d.delegate)
+
+	if delegateTimeout > timeout {
+		timeout = delegateTimeout
+	}
+	if delegateInterval > interval {
+		interval = delegateInterval
+	}
+
+	return timeout, interval
+}
+
+func (d *DelegateProvider) resolve(domain string) (challenge.Provider, string) {
+	target, ok := d.targets[normalizeDelegatedDomain(domain)]
+	if !ok {
+		return d.primary, domain
+	}
+
+	return d.delegate, target
+}
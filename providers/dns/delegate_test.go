@@ -0,0 +1,58 @@
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/providers/dns/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDelegations(t *testing.T) {
+	targets, err := ParseDelegations([]string{
+		"example.com",
+		"*.Example.org=example-org.acme.example.net.",
+		"_acme-challenge.example.net=acme.example.com",
+	})
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"example.com": "example.com",
+		"example.org": "example-org.acme.example.net",
+		"example.net": "acme.example.com",
+	}
+	assert.Equal(t, expected, targets)
+
+	_, err = ParseDelegations([]string{"example.com="})
+	require.EqualError(t, err, `invalid delegation "example.com=": empty target`)
+
+	_, err = ParseDelegations([]string{"=example.com"})
+	require.EqualError(t, err, `invalid delegation "=example.com": empty domain`)
+}
+
+func TestDelegateProvider(t *testing.T) {
+	primary := fake.NewProvider(fake.WithTimeout(time.Minute, time.Second))
+	delegate := fake.NewProvider(fake.WithTimeout(5*time.Minute, time.Second))
+
+	provider := NewDelegateProvider(primary, delegate, map[string]string{
+		"example.com": "example.com",
+		"example.org": "example-org.acme.example.net",
+	})
+
+	require.NoError(t, provider.Present("example.com", "a", "keyAuth"))
+	require.NoError(t, provider.Present("example.org", "b", "keyAuth"))
+	require.NoError(t, provider.Present("www.example.org", "c", "keyAuth"))
+
+	assert.Equal(t, []string{"example.com", "example-org.acme.example.net"}, delegate.Domains(fake.MethodPresent))
+	assert.Equal(t, []string{"www.example.org"}, primary.Domains(fake.MethodPresent))
+
+	require.NoError(t, provider.CleanUp("example.org", "b", "keyAuth"))
+	require.NoError(t, provider.CleanUp("www.example.org", "c", "keyAuth"))
+
+	assert.Equal(t, []string{"b"}, delegate.Tokens(fake.MethodCleanUp))
+	assert.Equal(t, []string{"c"}, primary.Tokens(fake.MethodCleanUp))
+
+	timeout, _ := provider.Timeout()
+	assert.Equal(t, 5*time.Minute, timeout)
+}
@@ -0,0 +1,193 @@
+// Package desec implements a DNS provider for solving the DNS-01 challenge using deSEC DNS.
+package desec
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/config/env"
+	"github.com/go-acme/lego/v3/platform/httpclient"
+	"github.com/go-acme/lego/v3/providers/dns/desec/internal"
+)
+
+// defaultTTL the default minimum TTL of deSEC domains.
+const defaultTTL = 3600
+
+// Environment variables names.
+const (
+	envNamespace = "DESEC_"
+
+	EnvToken = envNamespace + "TOKEN"
+
+	EnvTTL                = envNamespace + "TTL"
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+)
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	Token              string
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+	TTL                int
+	HTTPClient         *http.Client
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return &Config{
+		TTL:                env.GetOrDefaultInt(EnvTTL, defaultTTL),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 4*time.Second),
+		HTTPClient:         httpclient.New(env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second)),
+	}
+}
+
+// DNSProvider is an implementation of the challenge.Provider interface.
+type DNSProvider struct {
+	config *Config
+	client *internal.Client
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for deSEC.
+// Credentials must be passed in the environment variable: DESEC_TOKEN.
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get(EnvToken)
+	if err != nil {
+		return nil, fmt.Errorf("desec: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.Token = values[EnvToken]
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for deSEC.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("desec: the configuration of the DNS provider is nil")
+	}
+
+	if config.Token == "" {
+		return nil, errors.New("desec: incomplete credentials, missing token")
+	}
+
+	client := internal.NewClient(config.Token)
+	if config.HTTPClient != nil {
+		client.HTTPClient = config.HTTPClient
+	}
+
+	return &DNSProvider{config: config, client: client}, nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// Adjusting here to cope with spikes in propagation times.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+// Present creates a TXT record using the specified parameters.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+	quotedValue := strconv.Quote(value)
+
+	domainName, subName, minTTL, err := d.getDomain(fqdn)
+	if err != nil {
+		return fmt.Errorf("desec: %w", err)
+	}
+
+	// deSEC rejects RRsets with a TTL lower than the minimum TTL of the domain.
+	ttl := d.config.TTL
+	if ttl < minTTL {
+		log.Infof("desec: the TTL %d is lower than the minimum TTL of %s, using %d", ttl, domainName, minTTL)
+		ttl = minTTL
+	}
+
+	rrSet, err := d.client.GetTxtRRSet(domainName, subName)
+	if err != nil {
+		if !errors.Is(err, internal.ErrNotFound) {
+			return fmt.Errorf("desec: failed to get records: domainName=%s, subName=%s: %w", domainName, subName, err)
+		}
+
+		rrSet := internal.RRSet{
+			Domain:  domainName,
+			SubName: subName,
+			Type:    "TXT",
+			Records: []string{quotedValue},
+			TTL:     ttl,
+		}
+
+		_, err = d.client.AddRRSet(rrSet)
+		if err != nil {
+			return fmt.Errorf("desec: failed to create records: domainName=%s, subName=%s: %w", domainName, subName, err)
+		}
+
+		return nil
+	}
+
+	for _, record := range rrSet.Records {
+		if record == quotedValue {
+			return nil
+		}
+	}
+
+	records := append(rrSet.Records, quotedValue)
+
+	err = d.client.UpdateRRSet(domainName, subName, "TXT", records, ttl)
+	if err != nil {
+		return fmt.Errorf("desec: failed to update records: domainName=%s, subName=%s: %w", domainName, subName, err)
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+	quotedValue := strconv.Quote(value)
+
+	domainName, subName, _, err := d.getDomain(fqdn)
+	if err != nil {
+		return fmt.Errorf("desec: %w", err)
+	}
+
+	rrSet, err := d.client.GetTxtRRSet(domainName, subName)
+	if err != nil {
+		return fmt.Errorf("desec: failed to get records: domainName=%s, subName=%s: %w", domainName, subName, err)
+	}
+
+	records := make([]string, 0, len(rrSet.Records))
+	for _, record := range rrSet.Records {
+		if record != quotedValue {
+			records = append(records, record)
+		}
+	}
+
+	// an empty list of records deletes the RRset.
+	err = d.client.UpdateRRSet(domainName, subName, "TXT", records, rrSet.TTL)
+	if err != nil {
+		return fmt.Errorf("desec: failed to update records: domainName=%s, subName=%s: %w", domainName, subName, err)
+	}
+
+	return nil
+}
+
+// getDomain returns the deSEC domain owning the FQDN, the subname of the FQDN in this domain and the minimum TTL of the domain.
+func (d *DNSProvider) getDomain(fqdn string) (string, string, int, error) {
+	domain, err := d.client.GetOwningDomain(dns01.UnFqdn(fqdn))
+	if err != nil {
+		return "", "", 0, fmt.Errorf("could not find domain for %s: %w", fqdn, err)
+	}
+
+	subName := strings.TrimSuffix(strings.TrimSuffix(dns01.UnFqdn(fqdn), domain.Name), ".")
+
+	return domain.Name, subName, domain.MinimumTTL, nil
+}
@@ -0,0 +1,214 @@
+package desec
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/go-acme/lego/v3/providers/dns/desec/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envDomain = envNamespace + "DOMAIN"
+
+var envTest = tester.NewEnvTest(EnvToken).WithDomain(envDomain)
+
+func setupTest(t *testing.T) (*DNSProvider, *http.ServeMux) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config := NewDefaultConfig()
+	config.Token = "secret"
+	config.TTL = 300
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	p.client.BaseURL = server.URL + "/api/v1/"
+
+	mux.HandleFunc("/api/v1/domains/", func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("owns_qname") != "_acme-challenge.example.com" {
+			http.Error(rw, "unexpected query", http.StatusBadRequest)
+			return
+		}
+		_, _ = fmt.Fprint(rw, `[{"name":"example.com","minimum_ttl":3600}]`)
+	})
+
+	return p, mux
+}
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvToken: "123",
+			},
+		},
+		{
+			desc: "missing credentials",
+			envVars: map[string]string{
+				EnvToken: "",
+			},
+			expected: "desec: some credentials information are missing: DESEC_TOKEN",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider()
+
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		token    string
+		expected string
+	}{
+		{
+			desc:  "success",
+			token: "api_key",
+		},
+		{
+			desc:     "missing credentials",
+			expected: "desec: incomplete credentials, missing token",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Token = test.token
+
+			p, err := NewDNSProviderConfig(config)
+
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_Present(t *testing.T) {
+	p, mux := setupTest(t)
+
+	var created internal.RRSet
+	mux.HandleFunc("/api/v1/domains/example.com/rrsets/_acme-challenge/TXT/", func(rw http.ResponseWriter, req *http.Request) {
+		http.NotFound(rw, req)
+	})
+	mux.HandleFunc("/api/v1/domains/example.com/rrsets/", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := json.NewDecoder(req.Body).Decode(&created)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rw.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(rw).Encode(created)
+	})
+
+	err := p.Present("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	expected := internal.RRSet{
+		Domain:  "example.com",
+		SubName: "_acme-challenge",
+		Type:    "TXT",
+		Records: []string{`"ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY"`},
+		// raised to the minimum TTL of the domain.
+		TTL: 3600,
+	}
+	assert.Equal(t, expected, created)
+}
+
+func TestDNSProvider_CleanUp(t *testing.T) {
+	p, mux := setupTest(t)
+
+	var updated internal.RRSet
+	mux.HandleFunc("/api/v1/domains/example.com/rrsets/_acme-challenge/TXT/", func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			_, _ = fmt.Fprint(rw, `{"subname":"_acme-challenge","type":"TXT","ttl":3600,"records":["\"ADw2sEd82DUgXcQ9hNBZThJs7zVJkR5v9JeSbAb9mZY\"","\"other\""]}`)
+		case http.MethodPatch:
+			err := json.NewDecoder(req.Body).Decode(&updated)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_, _ = fmt.Fprint(rw, `{}`)
+		default:
+			http.Error(rw, "unexpected method", http.StatusMethodNotAllowed)
+		}
+	})
+
+	err := p.CleanUp("example.com", "", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`"other"`}, updated.Records)
+}
+
+func TestLivePresent(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	err = provider.Present(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
+
+func TestLiveCleanUp(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+const defaultBaseURL = "https://desec.io/api/v1/"
+
+// maxRetries the number of retries when the API rate limit is exceeded.
+const maxRetries = 5
+
+// ErrNotFound is returned when the requested resource does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Client a deSEC API client.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+
+	token string
+}
+
+// NewClient creates a new Client.
+func NewClient(token string) *Client {
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		BaseURL:    defaultBaseURL,
+		token:      token,
+	}
+}
+
+// GetOwningDomain returns the domain responsible for a name.
+// https://desec.readthedocs.io/en/latest/dns/domains.html#identifying-the-responsible-domain-for-a-dns-name
+func (c *Client) GetOwningDomain(qname string) (*Domain, error) {
+	endpoint, err := c.createEndpoint("domains")
+	if err != nil {
+		return nil, err
+	}
+
+	query := endpoint.Query()
+	query.Set("owns_qname", qname)
+	endpoint.RawQuery = query.Encode()
+
+	var domains []Domain
+	err = c.do(http.MethodGet, endpoint, nil, &domains)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("no domain found for %s: %w", qname, ErrNotFound)
+	}
+
+	return &domains[0], nil
+}
+
+// GetTxtRRSet returns the TXT RRset of a subname.
+// https://desec.readthedocs.io/en/latest/dns/rrsets.html#retrieving-a-specific-rrset
+func (c *Client) GetTxtRRSet(domainName, subName string) (*RRSet, error) {
+	endpoint, err := c.createEndpoint("domains", domainName, "rrsets", apexName(subName), "TXT")
+	if err != nil {
+		return nil, err
+	}
+
+	var rrSet RRSet
+	err = c.do(http.MethodGet, endpoint, nil, &rrSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rrSet, nil
+}
+
+// AddRRSet creates a new RRset.
+// https://desec.readthedocs.io/en/latest/dns/rrsets.html#creating-an-rrset
+func (c *Client) AddRRSet(rrSet RRSet) (*RRSet, error) {
+	endpoint, err := c.createEndpoint("domains", rrSet.Domain, "rrsets")
+	if err != nil {
+		return nil, err
+	}
+
+	var result RRSet
+	err = c.do(http.MethodPost, endpoint, rrSet, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateRRSet replaces the records of an RRset.
+// An empty records list deletes the RRset.
+// https://desec.readthedocs.io/en/latest/dns/rrsets.html#modifying-an-rrset
+func (c *Client) UpdateRRSet(domainName, subName, recordType string, records []string, ttl int) error {
+	endpoint, err := c.createEndpoint("domains", domainName, "rrsets", apexName(subName), recordType)
+	if err != nil {
+		return err
+	}
+
+	body := RRSet{Records: records, TTL: ttl, SubName: subName}
+
+	return c.do(http.MethodPatch, endpoint, body, nil)
+}
+
+func (c *Client) do(method string, endpoint *url.URL, body interface{}, result interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, endpoint.String(), bytes.NewReader(reqBody))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", "Token "+c.token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		// deSEC enforces strict rate limits, the response tells how long to wait.
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			_ = resp.Body.Close()
+			time.Sleep(retryAfter(resp))
+			continue
+		}
+
+		return readResponse(resp, result)
+	}
+}
+
+func readResponse(resp *http.Response, result interface{}) error {
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	if resp.StatusCode/100 != 2 {
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(raw, &apiErr) != nil || apiErr.Detail == "" {
+			apiErr.Detail = string(raw)
+		}
+		return apiErr
+	}
+
+	if result == nil || len(raw) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(raw, result)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to unmarshal response: %w: %s", err, string(raw))
+	}
+
+	return nil
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 1 {
+		return time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *Client) createEndpoint(fragments ...string) (*url.URL, error) {
+	baseURL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// the API requires a trailing slash.
+	return baseURL.Parse(path.Join(baseURL.Path, path.Join(fragments...)) + "/")
+}
+
+// apexName the subname of the zone apex is '@' in URLs.
+func apexName(subName string) string {
+	if subName == "" {
+		return "@"
+	}
+	return subName
+}
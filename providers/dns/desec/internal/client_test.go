@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T) (*Client, *http.ServeMux) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := NewClient("secret")
+	client.BaseURL = server.URL + "/api/v1/"
+
+	return client, mux
+}
+
+func writeFixture(rw http.ResponseWriter, status int, filename string) {
+	file, err := os.Open(filename)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	rw.WriteHeader(status)
+	_, _ = io.Copy(rw, file)
+}
+
+func checkRequest(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != method {
+			http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Token secret" {
+			writeFixture(rw, http.StatusUnauthorized, "./fixtures/error.json")
+			return
+		}
+
+		next(rw, req)
+	}
+}
+
+func TestClient_GetOwningDomain(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/api/v1/domains/", checkRequest(http.MethodGet, func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("owns_qname") != "_acme-challenge.example.com" {
+			_, _ = rw.Write([]byte("[]"))
+			return
+		}
+		writeFixture(rw, http.StatusOK, "./fixtures/get_owning_domain.json")
+	}))
+
+	domain, err := client.GetOwningDomain("_acme-challenge.example.com")
+	require.NoError(t, err)
+
+	expected := &Domain{Name: "example.com", MinimumTTL: 3600}
+	assert.Equal(t, expected, domain)
+
+	_, err = client.GetOwningDomain("example.org")
+	require.EqualError(t, err, "no domain found for example.org: not found")
+}
+
+func TestClient_GetTxtRRSet(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/api/v1/domains/example.com/rrsets/_acme-challenge/TXT/", checkRequest(http.MethodGet, func(rw http.ResponseWriter, req *http.Request) {
+		writeFixture(rw, http.StatusOK, "./fixtures/get_txt_rrset.json")
+	}))
+
+	rrSet, err := client.GetTxtRRSet("example.com", "_acme-challenge")
+	require.NoError(t, err)
+
+	expected := &RRSet{
+		Name:    "_acme-challenge.example.com.",
+		Domain:  "example.com",
+		SubName: "_acme-challenge",
+		Type:    "TXT",
+		Records: []string{`"txtxtxt"`},
+		TTL:     3600,
+	}
+	assert.Equal(t, expected, rrSet)
+
+	_, err = client.GetTxtRRSet("example.com", "_acme-challenge.foo")
+	require.Equal(t, ErrNotFound, err)
+}
+
+func TestClient_AddRRSet(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/api/v1/domains/example.com/rrsets/", checkRequest(http.MethodPost, func(rw http.ResponseWriter, req *http.Request) {
+		raw, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		expected := `{"domain":"example.com","subname":"_acme-challenge","type":"TXT","records":["\"txtxtxt\""],"ttl":3600}`
+		if string(raw) != expected {
+			http.Error(rw, fmt.Sprintf("invalid body: %s", raw), http.StatusBadRequest)
+			return
+		}
+
+		writeFixture(rw, http.StatusCreated, "./fixtures/get_txt_rrset.json")
+	}))
+
+	rrSet := RRSet{
+		Domain:  "example.com",
+		SubName: "_acme-challenge",
+		Type:    "TXT",
+		Records: []string{`"txtxtxt"`},
+		TTL:     3600,
+	}
+
+	result, err := client.AddRRSet(rrSet)
+	require.NoError(t, err)
+
+	assert.Equal(t, "_acme-challenge.example.com.", result.Name)
+}
+
+func TestClient_UpdateRRSet(t *testing.T) {
+	client, mux := setupTest(t)
+
+	var body RRSet
+	mux.HandleFunc("/api/v1/domains/example.com/rrsets/@/TXT/", checkRequest(http.MethodPatch, func(rw http.ResponseWriter, req *http.Request) {
+		err := json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+
+	err := client.UpdateRRSet("example.com", "", "TXT", []string{}, 3600)
+	require.NoError(t, err)
+
+	assert.Equal(t, RRSet{Records: []string{}, TTL: 3600}, body)
+}
+
+func TestClient_error(t *testing.T) {
+	client, mux := setupTest(t)
+	client.token = "invalid"
+
+	mux.HandleFunc("/api/v1/domains/example.com/rrsets/_acme-challenge/TXT/", checkRequest(http.MethodGet, func(rw http.ResponseWriter, req *http.Request) {
+		writeFixture(rw, http.StatusOK, "./fixtures/get_txt_rrset.json")
+	}))
+
+	_, err := client.GetTxtRRSet("example.com", "_acme-challenge")
+	require.EqualError(t, err, "401: Invalid token.")
+}
+
+func TestClient_rateLimit(t *testing.T) {
+	client, mux := setupTest(t)
+
+	var calls int
+	mux.HandleFunc("/api/v1/domains/example.com/rrsets/_acme-challenge/TXT/", checkRequest(http.MethodGet, func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls == 1 {
+			rw.Header().Set("Retry-After", "1")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			_, _ = rw.Write([]byte(`{"detail":"Request was throttled."}`))
+			return
+		}
+		writeFixture(rw, http.StatusOK, "./fixtures/get_txt_rrset.json")
+	}))
+
+	_, err := client.GetTxtRRSet("example.com", "_acme-challenge")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,29 @@
+package internal
+
+import "fmt"
+
+// Domain a deSEC domain.
+type Domain struct {
+	Name       string `json:"name,omitempty"`
+	MinimumTTL int    `json:"minimum_ttl,omitempty"`
+}
+
+// RRSet a deSEC RRset.
+type RRSet struct {
+	Name    string   `json:"name,omitempty"`
+	Domain  string   `json:"domain,omitempty"`
+	SubName string   `json:"subname"`
+	Type    string   `json:"type,omitempty"`
+	Records []string `json:"records"`
+	TTL     int      `json:"ttl,omitempty"`
+}
+
+// APIError an error returned by the API.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Detail     string `json:"detail"`
+}
+
+func (a APIError) Error() string {
+	return fmt.Sprintf("%d: %s", a.StatusCode, a.Detail)
+}
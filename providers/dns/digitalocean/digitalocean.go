@@ -5,11 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"sync"
 	"time"
 
+	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/challenge/dns01"
 	"github.com/go-acme/lego/v3/platform/config/env"
+	"github.com/go-acme/lego/v3/platform/httpclient"
 )
 
 // Environment variables names.
@@ -41,18 +42,16 @@ func NewDefaultConfig() *Config {
 		TTL:                env.GetOrDefaultInt(EnvTTL, 30),
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 60*time.Second),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 5*time.Second),
-		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
-		},
+		HTTPClient:         httpclient.New(env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second)),
 	}
 }
 
-// DNSProvider is an implementation of the challenge.Provider interface
+// DNSProvider is an implementation of the challenge.ProviderState interface
 // that uses DigitalOcean's REST API to manage TXT records for a domain.
 type DNSProvider struct {
-	config      *Config
-	recordIDs   map[string]int
-	recordIDsMu sync.Mutex
+	config *Config
+	// recordIDs the IDs of the records created by Present, by token.
+	recordIDs challenge.TokenStates
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for Digital
@@ -84,10 +83,7 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		config.BaseURL = defaultBaseURL
 	}
 
-	return &DNSProvider{
-		config:    config,
-		recordIDs: make(map[string]int),
-	}, nil
+	return &DNSProvider{config: config}, nil
 }
 
 // Timeout returns the timeout and interval to use when checking for DNS propagation.
@@ -98,46 +94,65 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 
 // Present creates a TXT record using the specified parameters
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
-	fqdn, value := dns01.GetRecord(domain, keyAuth)
-
-	respData, err := d.addTxtRecord(fqdn, value)
+	recordID, err := d.PresentWithState(domain, token, keyAuth)
 	if err != nil {
-		return fmt.Errorf("digitalocean: %w", err)
+		return err
 	}
 
-	d.recordIDsMu.Lock()
-	d.recordIDs[token] = respData.DomainRecord.ID
-	d.recordIDsMu.Unlock()
+	d.recordIDs.Store(token, recordID)
 
 	return nil
 }
 
 // CleanUp removes the TXT record matching the specified parameters
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
-	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+	// get the record's unique ID from when we created it
+	recordID, ok := d.recordIDs.Load(token)
+	if !ok {
+		fqdn, _ := dns01.GetRecord(domain, keyAuth)
+		return fmt.Errorf("digitalocean: unknown record ID for '%s'", fqdn)
+	}
 
-	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	err := d.CleanUpWithState(domain, token, keyAuth, recordID)
 	if err != nil {
-		return fmt.Errorf("digitalocean: %w", err)
+		return err
 	}
 
-	// get the record's unique ID from when we created it
-	d.recordIDsMu.Lock()
-	recordID, ok := d.recordIDs[token]
-	d.recordIDsMu.Unlock()
+	d.recordIDs.Delete(token)
+
+	return nil
+}
+
+// PresentWithState creates a TXT record using the specified parameters, and returns its ID.
+func (d *DNSProvider) PresentWithState(domain, token, keyAuth string) (interface{}, error) {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+
+	respData, err := d.addTxtRecord(fqdn, value)
+	if err != nil {
+		return nil, fmt.Errorf("digitalocean: %w", err)
+	}
+
+	return respData.DomainRecord.ID, nil
+}
+
+// CleanUpWithState removes the TXT record with the ID returned by PresentWithState.
+func (d *DNSProvider) CleanUpWithState(domain, token, keyAuth string, state interface{}) error {
+	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+
+	recordID, ok := state.(int)
 	if !ok {
-		return fmt.Errorf("digitalocean: unknown record ID for '%s'", fqdn)
+		return fmt.Errorf("digitalocean: invalid record ID for '%s': %v", fqdn, state)
 	}
 
-	err = d.removeTxtRecord(authZone, recordID)
+	authZone, err := dns01.FindZoneByFqdn(fqdn)
 	if err != nil {
 		return fmt.Errorf("digitalocean: %w", err)
 	}
 
-	// Delete record ID from map
-	d.recordIDsMu.Lock()
-	delete(d.recordIDs, token)
-	d.recordIDsMu.Unlock()
+	err = d.removeTxtRecord(authZone, recordID)
+	if err != nil {
+		return fmt.Errorf("digitalocean: %w", err)
+	}
 
 	return nil
 }
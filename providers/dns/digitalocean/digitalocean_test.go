@@ -64,7 +64,6 @@ func TestNewDNSProvider(t *testing.T) {
 				require.NoError(t, err)
 				require.NotNil(t, p)
 				require.NotNil(t, p.config)
-				require.NotNil(t, p.recordIDs)
 			} else {
 				require.EqualError(t, err, test.expected)
 			}
@@ -99,7 +98,6 @@ func TestNewDNSProviderConfig(t *testing.T) {
 				require.NoError(t, err)
 				require.NotNil(t, p)
 				require.NotNil(t, p.config)
-				require.NotNil(t, p.recordIDs)
 			} else {
 				require.EqualError(t, err, test.expected)
 			}
@@ -162,10 +160,39 @@ func TestDNSProvider_CleanUp(t *testing.T) {
 		w.WriteHeader(http.StatusNoContent)
 	})
 
-	provider.recordIDsMu.Lock()
-	provider.recordIDs["token"] = 1234567
-	provider.recordIDsMu.Unlock()
+	provider.recordIDs.Store("token", 1234567)
 
 	err := provider.CleanUp("example.com", "token", "")
 	require.NoError(t, err, "fail to remove TXT record")
 }
+
+func TestDNSProvider_PresentWithState(t *testing.T) {
+	provider, mux, tearDown := setupTest()
+	defer tearDown()
+
+	mux.HandleFunc("/v2/domains/example.com/records", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "method")
+
+		w.WriteHeader(http.StatusCreated)
+		_, err := fmt.Fprintf(w, `{"domain_record": {"id": 1234567, "type": "TXT", "name": "_acme-challenge"}}`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	state, err := provider.PresentWithState("example.com", "token", "foobar")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1234567, state)
+
+	_, ok := provider.recordIDs.Load("token")
+	assert.False(t, ok, "the state must be kept by the caller")
+}
+
+func TestDNSProvider_CleanUpWithState_invalidState(t *testing.T) {
+	provider, _, tearDown := setupTest()
+	defer tearDown()
+
+	err := provider.CleanUpWithState("example.com", "token", "", "1234567")
+	require.EqualError(t, err, "digitalocean: invalid record ID for '_acme-challenge.example.com.': 1234567")
+}
@@ -2,6 +2,7 @@ package dns
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-acme/lego/v3/challenge"
 	"github.com/go-acme/lego/v3/challenge/dns01"
@@ -19,6 +20,7 @@ import (
 	"github.com/go-acme/lego/v3/providers/dns/conoha"
 	"github.com/go-acme/lego/v3/providers/dns/constellix"
 	"github.com/go-acme/lego/v3/providers/dns/designate"
+	"github.com/go-acme/lego/v3/providers/dns/desec"
 	"github.com/go-acme/lego/v3/providers/dns/digitalocean"
 	"github.com/go-acme/lego/v3/providers/dns/dnsimple"
 	"github.com/go-acme/lego/v3/providers/dns/dnsmadeeasy"
@@ -37,9 +39,11 @@ import (
 	"github.com/go-acme/lego/v3/providers/dns/gcloud"
 	"github.com/go-acme/lego/v3/providers/dns/glesys"
 	"github.com/go-acme/lego/v3/providers/dns/godaddy"
+	"github.com/go-acme/lego/v3/providers/dns/hetzner"
 	"github.com/go-acme/lego/v3/providers/dns/hostingde"
 	"github.com/go-acme/lego/v3/providers/dns/httpreq"
 	"github.com/go-acme/lego/v3/providers/dns/iij"
+	"github.com/go-acme/lego/v3/providers/dns/infomaniak"
 	"github.com/go-acme/lego/v3/providers/dns/inwx"
 	"github.com/go-acme/lego/v3/providers/dns/joker"
 	"github.com/go-acme/lego/v3/providers/dns/lightsail"
@@ -57,6 +61,8 @@ import (
 	"github.com/go-acme/lego/v3/providers/dns/otc"
 	"github.com/go-acme/lego/v3/providers/dns/ovh"
 	"github.com/go-acme/lego/v3/providers/dns/pdns"
+	"github.com/go-acme/lego/v3/providers/dns/plugin"
+	"github.com/go-acme/lego/v3/providers/dns/porkbun"
 	"github.com/go-acme/lego/v3/providers/dns/rackspace"
 	"github.com/go-acme/lego/v3/providers/dns/regru"
 	"github.com/go-acme/lego/v3/providers/dns/rfc2136"
@@ -78,6 +84,10 @@ import (
 
 // NewDNSChallengeProviderByName Factory for DNS providers
 func NewDNSChallengeProviderByName(name string) (challenge.Provider, error) {
+	if strings.HasPrefix(name, "plugin:") {
+		return plugin.NewDNSProvider(strings.TrimPrefix(name, "plugin:"))
+	}
+
 	switch name {
 	case "acme-dns":
 		return acmedns.NewDNSProvider()
@@ -107,6 +117,8 @@ func NewDNSChallengeProviderByName(name string) (challenge.Provider, error) {
 		return constellix.NewDNSProvider()
 	case "designate":
 		return designate.NewDNSProvider()
+	case "desec":
+		return desec.NewDNSProvider()
 	case "digitalocean":
 		return digitalocean.NewDNSProvider()
 	case "dnsimple":
@@ -143,12 +155,16 @@ func NewDNSChallengeProviderByName(name string) (challenge.Provider, error) {
 		return gcloud.NewDNSProvider()
 	case "godaddy":
 		return godaddy.NewDNSProvider()
+	case "hetzner":
+		return hetzner.NewDNSProvider()
 	case "hostingde":
 		return hostingde.NewDNSProvider()
 	case "httpreq":
 		return httpreq.NewDNSProvider()
 	case "iij":
 		return iij.NewDNSProvider()
+	case "infomaniak":
+		return infomaniak.NewDNSProvider()
 	case "inwx":
 		return inwx.NewDNSProvider()
 	case "joker":
@@ -185,6 +201,8 @@ func NewDNSChallengeProviderByName(name string) (challenge.Provider, error) {
 		return ovh.NewDNSProvider()
 	case "pdns":
 		return pdns.NewDNSProvider()
+	case "porkbun":
+		return porkbun.NewDNSProvider()
 	case "rackspace":
 		return rackspace.NewDNSProvider()
 	case "regru":
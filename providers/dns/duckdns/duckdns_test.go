@@ -1,10 +1,13 @@
 package duckdns
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/go-acme/lego/v3/platform/tester/journal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -175,3 +178,32 @@ func TestLiveCleanUp(t *testing.T) {
 	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
 	require.NoError(t, err)
 }
+
+func TestDNSProvider_journal(t *testing.T) {
+	const (
+		fakeToken  = "TOKEN"
+		fakeDomain = "lego.duckdns.org"
+	)
+
+	token, domain := fakeToken, fakeDomain
+	if os.Getenv(journal.EnvMode) == string(journal.Record) {
+		token, domain = envTest.GetValue(EnvToken), envTest.GetDomain()
+	}
+
+	j := journal.New(t, filepath.FromSlash("fixtures/journal.json"),
+		journal.WithSecret(token, fakeToken),
+		journal.WithSecret(domain, fakeDomain))
+
+	config := NewDefaultConfig()
+	config.Token = token
+	config.HTTPClient = j.Client()
+
+	provider, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = provider.Present(domain, "", "123d==")
+	require.NoError(t, err)
+
+	err = provider.CleanUp(domain, "", "123d==")
+	require.NoError(t, err)
+}
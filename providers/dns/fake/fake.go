@@ -0,0 +1,298 @@
+// Package fake implements a scriptable in-memory DNS provider, to test the code using the challenge.Provider interface
+// (solvers, provider wrappers, integrations) without DNS service.
+//
+// The provider is deterministic: the failures follow a schedule, the latency uses an injectable sleep function,
+// and the propagation of a record is simulated by a number of lookups.
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+)
+
+var _ challenge.ProviderTimeout = (*Provider)(nil)
+
+// Methods of the provider, used by the failure schedules and the calls.
+const (
+	MethodPresent = "Present"
+	MethodCleanUp = "CleanUp"
+)
+
+// Record a TXT record presented by the provider.
+type Record struct {
+	Domain  string
+	Token   string
+	KeyAuth string
+
+	// FQDN the name of the record (_acme-challenge.<domain>.).
+	FQDN string
+	// Value the value of the record.
+	Value string
+
+	// Propagated true once the record is visible to the lookups.
+	Propagated bool
+
+	lookups int
+}
+
+// Call a call of Present or CleanUp.
+type Call struct {
+	Method  string
+	Domain  string
+	Token   string
+	KeyAuth string
+	// Err the error returned by the call.
+	Err error
+}
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithLatency delays each call of Present and CleanUp.
+func WithLatency(latency time.Duration) Option {
+	return func(p *Provider) {
+		p.latency = latency
+	}
+}
+
+// WithSleep replaces the function used to wait for the latency (time.Sleep by default),
+// e.g. to record the delays instead of waiting.
+func WithSleep(sleep func(time.Duration)) Option {
+	return func(p *Provider) {
+		p.sleep = sleep
+	}
+}
+
+// WithFailures sets the schedule of the results of a method (MethodPresent or MethodCleanUp):
+// the n-th call returns the n-th error (nil for a success), the calls after the schedule succeed.
+func WithFailures(method string, errs ...error) Option {
+	return func(p *Provider) {
+		p.failures[method] = append(p.failures[method], errs...)
+	}
+}
+
+// WithDomainFailure makes all the calls of Present for a domain fail.
+func WithDomainFailure(domain string, err error) Option {
+	return func(p *Provider) {
+		p.domainFailures[domain] = err
+	}
+}
+
+// WithPropagation makes a record visible only after a number of lookups (Lookup) has failed to find it.
+func WithPropagation(lookups int) Option {
+	return func(p *Provider) {
+		p.propagationLookups = lookups
+	}
+}
+
+// WithTimeout sets the timeout and interval returned by Timeout.
+func WithTimeout(timeout, interval time.Duration) Option {
+	return func(p *Provider) {
+		p.timeout = timeout
+		p.interval = interval
+	}
+}
+
+// Provider a scriptable in-memory challenge.Provider. It is safe for concurrent use.
+type Provider struct {
+	latency            time.Duration
+	sleep              func(time.Duration)
+	failures           map[string][]error
+	domainFailures     map[string]error
+	propagationLookups int
+	timeout, interval  time.Duration
+
+	mu      sync.Mutex
+	records map[string]*Record
+	calls   []Call
+}
+
+// NewProvider creates a Provider.
+// By default, the calls succeed without latency, and the records are propagated immediately.
+func NewProvider(opts ...Option) *Provider {
+	p := &Provider{
+		sleep:          time.Sleep,
+		failures:       make(map[string][]error),
+		domainFailures: make(map[string]error),
+		timeout:        time.Minute,
+		interval:       time.Second,
+		records:        make(map[string]*Record),
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Present creates the TXT record, unless the call is scheduled to fail.
+func (p *Provider) Present(domain, token, keyAuth string) error {
+	p.wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	err := p.nextResult(MethodPresent)
+	if err == nil {
+		err = p.domainFailures[domain]
+	}
+
+	p.calls = append(p.calls, Call{Method: MethodPresent, Domain: domain, Token: token, KeyAuth: keyAuth, Err: err})
+
+	if err != nil {
+		return err
+	}
+
+	fqdn, value := GetRecord(domain, keyAuth)
+
+	p.records[token] = &Record{
+		Domain:     domain,
+		Token:      token,
+		KeyAuth:    keyAuth,
+		FQDN:       fqdn,
+		Value:      value,
+		Propagated: p.propagationLookups == 0,
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record, unless the call is scheduled to fail.
+// Cleaning up an unknown record is an error.
+func (p *Provider) CleanUp(domain, token, keyAuth string) error {
+	p.wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	err := p.nextResult(MethodCleanUp)
+	if err == nil {
+		if _, ok := p.records[token]; !ok {
+			err = fmt.Errorf("fake: unknown record for the token %q (%s)", token, domain)
+		}
+	}
+
+	p.calls = append(p.calls, Call{Method: MethodCleanUp, Domain: domain, Token: token, KeyAuth: keyAuth, Err: err})
+
+	if err != nil {
+		return err
+	}
+
+	delete(p.records, token)
+
+	return nil
+}
+
+// Timeout returns the timeout and interval set with WithTimeout (1 minute and 1 second by default).
+func (p *Provider) Timeout() (timeout, interval time.Duration) {
+	return p.timeout, p.interval
+}
+
+// Lookup checks the propagation of a record (a dns01.PreCheckFunc):
+// a presented record is found after the number of lookups set with WithPropagation.
+func (p *Provider) Lookup(fqdn, value string) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, record := range p.records {
+		if record.FQDN != fqdn || record.Value != value {
+			continue
+		}
+
+		if !record.Propagated {
+			record.lookups++
+			record.Propagated = record.lookups > p.propagationLookups
+		}
+
+		return record.Propagated, nil
+	}
+
+	return false, nil
+}
+
+// Propagate makes all the presented records visible to the lookups.
+func (p *Provider) Propagate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, record := range p.records {
+		record.Propagated = true
+	}
+}
+
+// Records returns the current records.
+func (p *Provider) Records() []Record {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	records := make([]Record, 0, len(p.records))
+	for _, record := range p.records {
+		records = append(records, *record)
+	}
+
+	return records
+}
+
+// Calls returns the calls of Present and CleanUp, in order.
+func (p *Provider) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Call(nil), p.calls...)
+}
+
+// Domains returns the domains of the calls of a method, in order.
+func (p *Provider) Domains(method string) []string {
+	var domains []string
+	for _, call := range p.Calls() {
+		if call.Method == method {
+			domains = append(domains, call.Domain)
+		}
+	}
+
+	return domains
+}
+
+// Tokens returns the tokens of the successful calls of a method, in order.
+func (p *Provider) Tokens(method string) []string {
+	var tokens []string
+	for _, call := range p.Calls() {
+		if call.Method == method && call.Err == nil {
+			tokens = append(tokens, call.Token)
+		}
+	}
+
+	return tokens
+}
+
+func (p *Provider) wait() {
+	if p.latency > 0 {
+		p.sleep(p.latency)
+	}
+}
+
+// nextResult pops the next result of the schedule of a method.
+func (p *Provider) nextResult(method string) error {
+	schedule := p.failures[method]
+	if len(schedule) == 0 {
+		return nil
+	}
+
+	p.failures[method] = schedule[1:]
+
+	return schedule[0]
+}
+
+// GetRecord returns the name and the value of the TXT record of the dns-01 challenge, as dns01.GetRecord
+// (without the CNAME support), so this package can be used by the tests of the dns01 package.
+func GetRecord(domain, keyAuth string) (fqdn, value string) {
+	keyAuthShaBytes := sha256.Sum256([]byte(keyAuth))
+
+	return fmt.Sprintf("_acme-challenge.%s.", domain), base64.RawURLEncoding.EncodeToString(keyAuthShaBytes[:])
+}
@@ -0,0 +1,96 @@
+package fake
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider(t *testing.T) {
+	provider := NewProvider()
+
+	require.NoError(t, provider.Present("example.com", "a", "keyAuthA"))
+	require.NoError(t, provider.Present("example.org", "b", "keyAuthB"))
+
+	fqdn, value := GetRecord("example.com", "keyAuthA")
+	assert.Equal(t, "_acme-challenge.example.com.", fqdn)
+
+	found, err := provider.Lookup(fqdn, value)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	require.NoError(t, provider.CleanUp("example.com", "a", "keyAuthA"))
+
+	found, err = provider.Lookup(fqdn, value)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.Len(t, provider.Records(), 1)
+	assert.Equal(t, "example.org", provider.Records()[0].Domain)
+
+	err = provider.CleanUp("example.com", "a", "keyAuthA")
+	require.EqualError(t, err, `fake: unknown record for the token "a" (example.com)`)
+
+	assert.Equal(t, []string{"a", "b"}, provider.Tokens(MethodPresent))
+	assert.Equal(t, []string{"a"}, provider.Tokens(MethodCleanUp))
+	assert.Equal(t, []string{"example.com", "example.com"}, provider.Domains(MethodCleanUp))
+}
+
+func TestProvider_failures(t *testing.T) {
+	boom := errors.New("boom")
+
+	provider := NewProvider(
+		WithFailures(MethodPresent, boom, nil, boom),
+		WithDomainFailure("broken.example.com", errors.New("zone not found")),
+	)
+
+	assert.Equal(t, boom, provider.Present("example.com", "a", "keyAuth"))
+	assert.NoError(t, provider.Present("example.com", "a", "keyAuth"))
+	assert.Equal(t, boom, provider.Present("example.com", "a", "keyAuth"))
+	assert.NoError(t, provider.Present("example.com", "a", "keyAuth"))
+
+	assert.EqualError(t, provider.Present("broken.example.com", "b", "keyAuth"), "zone not found")
+
+	calls := provider.Calls()
+	require.Len(t, calls, 5)
+	assert.Equal(t, boom, calls[0].Err)
+	assert.NoError(t, calls[1].Err)
+}
+
+func TestProvider_propagation(t *testing.T) {
+	provider := NewProvider(WithPropagation(2))
+
+	require.NoError(t, provider.Present("example.com", "a", "keyAuth"))
+
+	fqdn, value := GetRecord("example.com", "keyAuth")
+
+	var results []bool
+	for i := 0; i < 4; i++ {
+		found, err := provider.Lookup(fqdn, value)
+		require.NoError(t, err)
+		results = append(results, found)
+	}
+
+	assert.Equal(t, []bool{false, false, true, true}, results)
+
+	require.NoError(t, provider.Present("example.org", "b", "keyAuth"))
+	provider.Propagate()
+
+	found, err := provider.Lookup("_acme-challenge.example.org.", value)
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestProvider_latency(t *testing.T) {
+	var delays []time.Duration
+
+	provider := NewProvider(WithLatency(3*time.Second), WithSleep(func(d time.Duration) { delays = append(delays, d) }))
+
+	require.NoError(t, provider.Present("example.com", "a", "keyAuth"))
+	require.NoError(t, provider.CleanUp("example.com", "a", "keyAuth"))
+
+	assert.Equal(t, []time.Duration{3 * time.Second, 3 * time.Second}, delays)
+}
@@ -0,0 +1,186 @@
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/log"
+	"google.golang.org/api/googleapi"
+)
+
+// credentialStatusPattern matches the HTTP status 401 or 403 in the message of an error,
+// for the providers which don't expose the status code in the type of their errors.
+var credentialStatusPattern = regexp.MustCompile(`(?i)\bstatus(\s*code)?\W{0,3}40[13]\b|\b401 unauthorized\b|\b403 forbidden\b`)
+
+// FallbackProvider uses a fallback provider when the primary provider fails to present a record.
+// The failures caused by credential errors are not recovered: they must be fixed, not hidden.
+type FallbackProvider struct {
+	primary  challenge.Provider
+	fallback challenge.Provider
+
+	// the provider which presented the record of each token, used by CleanUp.
+	used   map[string]challenge.Provider
+	usedMu sync.Mutex
+}
+
+// NewDNSChallengeProviderWithFallback returns a provider using the provider named primary,
+// and the provider named fallback when the primary one fails.
+func NewDNSChallengeProviderWithFallback(primary, fallback string) (*FallbackProvider, error) {
+	primaryProvider, err := NewDNSChallengeProviderByName(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackProvider, err := NewDNSChallengeProviderByName(fallback)
+	if err != nil {
+		return nil, fmt.Errorf("fallback: %w", err)
+	}
+
+	return NewFallbackProvider(primaryProvider, fallbackProvider), nil
+}
+
+// NewFallbackProvider creates a FallbackProvider.
+func NewFallbackProvider(primary, fallback challenge.Provider) *FallbackProvider {
+	return &FallbackProvider{
+		primary:  primary,
+		fallback: fallback,
+		used:     make(map[string]challenge.Provider),
+	}
+}
+
+// Present presents the record with the primary provider, or with the fallback provider if the primary one fails.
+func (f *FallbackProvider) Present(domain, token, keyAuth string) error {
+	err := present(f.primary, domain, token, keyAuth)
+	if err == nil {
+		f.setUsed(token, f.primary)
+		return nil
+	}
+
+	if IsCredentialError(err) {
+		return err
+	}
+
+	log.Warnf("[%s] the DNS provider failed, using the fallback provider: %v", domain, err)
+
+	// the primary provider may have created the record before failing.
+	if errC := cleanUp(f.primary, domain, token, keyAuth); errC != nil {
+		log.Infof("[%s] cleanup of the failed DNS provider: %v", domain, errC)
+	}
+
+	errF := present(f.fallback, domain, token, keyAuth)
+	if errF != nil {
+		return fmt.Errorf("%v; fallback: %w", err, errF)
+	}
+
+	f.setUsed(token, f.fallback)
+	return nil
+}
+
+// CleanUp cleans the record up with the provider which presented it.
+func (f *FallbackProvider) CleanUp(domain, token, keyAuth string) error {
+	f.usedMu.Lock()
+	provider, ok := f.used[token]
+	delete(f.used, token)
+	f.usedMu.Unlock()
+
+	if !ok {
+		provider = f.primary
+	}
+
+	return cleanUp(provider, domain, token, keyAuth)
+}
+
+// WrapsProviders implements dns01.ProviderWrapper: the calls of the primary and fallback providers are reported.
+func (f *FallbackProvider) WrapsProviders() {}
+
+// Timeout returns the largest timeout and interval of the providers,
+// as the provider used for a record is not known in advance.
+func (f *FallbackProvider) Timeout() (timeout, interval time.Duration) {
+	timeout, interval = providerTimeout(f.primary)
+	fallbackTimeout, fallbackInterval := providerTimeout(f.fallback)
+
+	if fallbackTimeout > timeout {
+		timeout = fallbackTimeout
+	}
+	if fallbackInterval > interval {
+		interval = fallbackInterval
+	}
+
+	return timeout, interval
+}
+
+func (f *FallbackProvider) setUsed(token string, provider challenge.Provider) {
+	f.usedMu.Lock()
+	f.used[token] = provider
+	f.usedMu.Unlock()
+}
+
+// present presents the record with a wrapped provider, reporting the call to the observers of the providers.
+func present(provider challenge.Provider, domain, token, keyAuth string) error {
+	return dns01.ObserveProviderCall(provider, dns01.OperationPresent, domain, func() error {
+		return provider.Present(domain, token, keyAuth)
+	})
+}
+
+// cleanUp cleans the record up with a wrapped provider, reporting the call to the observers of the providers.
+func cleanUp(provider challenge.Provider, domain, token, keyAuth string) error {
+	return dns01.ObserveProviderCall(provider, dns01.OperationCleanUp, domain, func() error {
+		return provider.CleanUp(domain, token, keyAuth)
+	})
+}
+
+func providerTimeout(provider challenge.Provider) (time.Duration, time.Duration) {
+	if p, ok := provider.(challenge.ProviderTimeout); ok {
+		return p.Timeout()
+	}
+	return dns01.DefaultPropagationTimeout, dns01.DefaultPollingInterval
+}
+
+// IsCredentialError reports whether the error of a provider is a credential or permission error (HTTP status 401 or 403).
+// The status code is read from the errors of the SDKs (AWS, Azure, Google Cloud) or from a CredentialError method,
+// and from the message of the error otherwise.
+func IsCredentialError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var credErr interface{ CredentialError() bool }
+	if errors.As(err, &credErr) {
+		return credErr.CredentialError()
+	}
+
+	if code, ok := httpStatusCode(err); ok {
+		return code == http.StatusUnauthorized || code == http.StatusForbidden
+	}
+
+	return credentialStatusPattern.MatchString(err.Error())
+}
+
+// httpStatusCode returns the HTTP status code of the error of a provider, if its type exposes it.
+func httpStatusCode(err error) (int, bool) {
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return googleErr.Code, true
+	}
+
+	var azureErr autorest.DetailedError
+	if errors.As(err, &azureErr) {
+		code, ok := azureErr.StatusCode.(int)
+		return code, ok
+	}
+
+	// e.g. awserr.RequestFailure.
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode(), true
+	}
+
+	return 0, false
+}
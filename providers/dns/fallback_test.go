@@ -0,0 +1,158 @@
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/go-acme/lego/v3/providers/dns/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+)
+
+func TestFallbackProvider(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		primaryErr       error
+		fallbackErr      error
+		expectedPrimary  bool
+		expectedFallback bool
+		expectedError    string
+	}{
+		{
+			desc:            "primary succeeds",
+			expectedPrimary: true,
+		},
+		{
+			desc:             "primary fails",
+			primaryErr:       errors.New("clouddns: 503: service unavailable"),
+			expectedFallback: true,
+		},
+		{
+			desc:          "primary credential error",
+			primaryErr:    errors.New("clouddns: status code 401: invalid credentials"),
+			expectedError: "clouddns: status code 401: invalid credentials",
+		},
+		{
+			desc:          "both fail",
+			primaryErr:    errors.New("clouddns: 503: service unavailable"),
+			fallbackErr:   errors.New("acme-dns: timeout"),
+			expectedError: "clouddns: 503: service unavailable; fallback: acme-dns: timeout",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			primary := fake.NewProvider(fake.WithFailures(fake.MethodPresent, test.primaryErr))
+			fallback := fake.NewProvider(fake.WithFailures(fake.MethodPresent, test.fallbackErr))
+
+			provider := NewFallbackProvider(primary, fallback)
+
+			err := provider.Present("example.com", "token", "keyAuth")
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			err = provider.CleanUp("example.com", "token", "keyAuth")
+			require.NoError(t, err)
+
+			if test.expectedPrimary {
+				assert.Equal(t, []string{"token"}, primary.Tokens(fake.MethodPresent))
+				assert.Equal(t, []string{"token"}, primary.Tokens(fake.MethodCleanUp))
+				assert.Empty(t, fallback.Tokens(fake.MethodCleanUp))
+			}
+
+			if test.expectedFallback {
+				assert.Equal(t, []string{"token"}, fallback.Tokens(fake.MethodPresent))
+				assert.Equal(t, []string{"token"}, fallback.Tokens(fake.MethodCleanUp))
+			}
+		})
+	}
+}
+
+func TestFallbackProvider_Timeout(t *testing.T) {
+	provider := NewFallbackProvider(
+		fake.NewProvider(fake.WithTimeout(time.Minute, time.Second)),
+		fake.NewProvider(fake.WithTimeout(5*time.Minute, time.Second)),
+	)
+
+	timeout, interval := provider.Timeout()
+	assert.Equal(t, 5*time.Minute, timeout)
+	assert.Equal(t, time.Second, interval)
+}
+
+func TestIsCredentialError(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc:     "AWS",
+			err:      fmt.Errorf("route53: %w", awserr.NewRequestFailure(awserr.New("AccessDenied", "access denied", nil), http.StatusForbidden, "id")),
+			expected: true,
+		},
+		{
+			desc:     "AWS server error",
+			err:      fmt.Errorf("route53: %w", awserr.NewRequestFailure(awserr.New("InternalFailure", "the credentials could not be checked", nil), http.StatusInternalServerError, "id")),
+			expected: false,
+		},
+		{
+			desc:     "Google Cloud",
+			err:      fmt.Errorf("googlecloud: %w", &googleapi.Error{Code: http.StatusUnauthorized, Message: "invalid credentials"}),
+			expected: true,
+		},
+		{
+			desc:     "Azure",
+			err:      fmt.Errorf("azure: %w", autorest.DetailedError{StatusCode: http.StatusForbidden}),
+			expected: true,
+		},
+		{
+			desc:     "HTTP status text",
+			err:      errors.New("cloudflare: failed to find zone: 403 Forbidden"),
+			expected: true,
+		},
+		{
+			desc:     "status code",
+			err:      errors.New("desec: unexpected status code: 401: Invalid token."),
+			expected: true,
+		},
+		{
+			desc:     "server error",
+			err:      errors.New("hetzner: status code 500: internal error"),
+			expected: false,
+		},
+		{
+			desc:     "local permission error",
+			err:      errors.New("exec: open /etc/lego/key: permission denied"),
+			expected: false,
+		},
+		{
+			desc:     "number in a message",
+			err:      errors.New("gandi: record 403 of the zone not found"),
+			expected: false,
+		},
+		{
+			desc:     "network error",
+			err:      errors.New("dial tcp: i/o timeout"),
+			expected: false,
+		},
+		{
+			desc:     "nil",
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsCredentialError(test.err))
+		})
+	}
+}
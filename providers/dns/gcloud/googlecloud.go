@@ -13,10 +13,10 @@ import (
 	"cloud.google.com/go/compute/metadata"
 	"github.com/go-acme/lego/v3/challenge/dns01"
 	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/cloudcreds"
 	"github.com/go-acme/lego/v3/platform/config/env"
 	"github.com/go-acme/lego/v3/platform/wait"
 	"golang.org/x/net/context"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/dns/v1"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
@@ -32,6 +32,7 @@ const (
 
 	EnvServiceAccount = envNamespace + "SERVICE_ACCOUNT"
 	EnvProject        = envNamespace + "PROJECT"
+	EnvZoneID         = envNamespace + "ZONE_ID"
 	EnvDebug          = envNamespace + "DEBUG"
 
 	EnvTTL                = envNamespace + "TTL"
@@ -43,6 +44,7 @@ const (
 type Config struct {
 	Debug              bool
 	Project            string
+	ZoneID             string
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	TTL                int
@@ -53,6 +55,7 @@ type Config struct {
 func NewDefaultConfig() *Config {
 	return &Config{
 		Debug:              env.GetOrDefaultBool(EnvDebug, false),
+		ZoneID:             env.GetOrDefaultString(EnvZoneID, ""),
 		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 180*time.Second),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 5*time.Second),
@@ -68,7 +71,7 @@ type DNSProvider struct {
 // NewDNSProvider returns a DNSProvider instance configured for Google Cloud DNS.
 // By default, the project name is auto-detected by using the metadata service,
 // it can be overridden using the GCE_PROJECT environment variable.
-// A Service Account can be passed in the environment variable: GCE_SERVICE_ACCOUNT
+// A Service Account (or external account credentials) can be passed in the environment variable: GCE_SERVICE_ACCOUNT
 // or by specifying the keyfile location: GCE_SERVICE_ACCOUNT_FILE
 func NewDNSProvider() (*DNSProvider, error) {
 	// Use a service account file if specified via environment variable.
@@ -88,7 +91,7 @@ func NewDNSProviderCredentials(project string) (*DNSProvider, error) {
 		return nil, errors.New("googlecloud: project name missing")
 	}
 
-	client, err := google.DefaultClient(context.Background(), dns.NdevClouddnsReadwriteScope)
+	client, err := cloudcreds.NewGoogleClient(context.Background(), cloudcreds.GoogleConfig{}, dns.NdevClouddnsReadwriteScope)
 	if err != nil {
 		return nil, fmt.Errorf("googlecloud: unable to get Google Cloud client: %w", err)
 	}
@@ -122,11 +125,10 @@ func NewDNSProviderServiceAccountKey(saKey []byte) (*DNSProvider, error) {
 		project = datJSON.ProjectID
 	}
 
-	conf, err := google.JWTConfigFromJSON(saKey, dns.NdevClouddnsReadwriteScope)
+	client, err := cloudcreds.NewGoogleClient(context.Background(), cloudcreds.GoogleConfig{ServiceAccountKey: saKey}, dns.NdevClouddnsReadwriteScope)
 	if err != nil {
-		return nil, fmt.Errorf("googlecloud: unable to acquire config: %w", err)
+		return nil, fmt.Errorf("googlecloud: %w", err)
 	}
-	client := conf.Client(context.Background())
 
 	config := NewDefaultConfig()
 	config.Project = project
@@ -308,6 +310,11 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 
 // getHostedZone returns the managed-zone
 func (d *DNSProvider) getHostedZone(domain string) (string, error) {
+	// several zones can contain the domain (ex: split-horizon public and private zones).
+	if d.config.ZoneID != "" {
+		return d.config.ZoneID, nil
+	}
+
 	authZone, err := dns01.FindZoneByFqdn(dns01.ToFqdn(domain))
 	if err != nil {
 		return "", err
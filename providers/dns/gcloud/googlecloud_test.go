@@ -27,6 +27,7 @@ const (
 
 var envTest = tester.NewEnvTest(
 	EnvProject,
+	EnvZoneID,
 	envServiceAccountFile,
 	envGoogleApplicationCredentials,
 	envMetadataHost,
@@ -220,6 +221,72 @@ func TestPresentNoExistingRR(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPresentWithZoneID(t *testing.T) {
+	mux := http.NewServeMux()
+
+	// getHostedZone: the zone is defined by the configuration.
+	mux.HandleFunc("/manhattan/managedZones", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "the zones must not be listed", http.StatusBadRequest)
+	})
+
+	// findTxtRecords: /manhattan/managedZones/private-zone/rrsets?alt=json&name=_acme-challenge.lego.wtf.&type=TXT
+	mux.HandleFunc("/manhattan/managedZones/private-zone/rrsets", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		rrslr := &dns.ResourceRecordSetsListResponse{
+			Rrsets: []*dns.ResourceRecordSet{},
+		}
+
+		err := json.NewEncoder(w).Encode(rrslr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	// applyChanges [Create]: /manhattan/managedZones/private-zone/changes?alt=json
+	mux.HandleFunc("/manhattan/managedZones/private-zone/changes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var chgReq dns.Change
+		if err := json.NewDecoder(r.Body).Decode(&chgReq); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		chgResp := chgReq
+		chgResp.Status = changeStatusDone
+
+		if err := json.NewEncoder(w).Encode(chgResp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+
+	server := httptest.NewServer(mux)
+
+	config := NewDefaultConfig()
+	config.HTTPClient = &http.Client{}
+	config.Project = "manhattan"
+	config.ZoneID = "private-zone"
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	p.client.BasePath = server.URL
+
+	domain := "lego.wtf"
+
+	err = p.Present(domain, "", "")
+	require.NoError(t, err)
+}
+
 func TestPresentWithExistingRR(t *testing.T) {
 	mux := http.NewServeMux()
 
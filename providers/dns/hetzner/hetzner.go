@@ -0,0 +1,285 @@
+// Package hetzner implements a DNS provider for solving the DNS-01 challenge using Hetzner DNS.
+package hetzner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/platform/config/env"
+	"github.com/go-acme/lego/v3/platform/httpclient"
+	"github.com/go-acme/lego/v3/providers/dns/hetzner/internal"
+)
+
+const minTTL = 600
+
+// Environment variables names.
+const (
+	envNamespace = "HETZNER_"
+
+	EnvAPIKey = envNamespace + "API_KEY"
+
+	EnvTTL                = envNamespace + "TTL"
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+)
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	APIKey             string
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+	TTL                int
+	HTTPClient         *http.Client
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return &Config{
+		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
+		HTTPClient:         httpclient.New(env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second)),
+	}
+}
+
+// DNSProvider is an implementation of the challenge.Provider interface.
+type DNSProvider struct {
+	config *Config
+	client *internal.Client
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for Hetzner.
+// Credentials must be passed in the environment variable: HETZNER_API_KEY.
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get(EnvAPIKey)
+	if err != nil {
+		return nil, fmt.Errorf("hetzner: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.APIKey = values[EnvAPIKey]
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for Hetzner.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("hetzner: the configuration of the DNS provider is nil")
+	}
+
+	if config.APIKey == "" {
+		return nil, errors.New("hetzner: credentials missing")
+	}
+
+	if config.TTL < minTTL {
+		return nil, fmt.Errorf("hetzner: invalid TTL, TTL (%d) must be greater than %d", config.TTL, minTTL)
+	}
+
+	client := internal.NewClient(config.APIKey)
+
+	if config.HTTPClient != nil {
+		client.HTTPClient = config.HTTPClient
+	}
+
+	return &DNSProvider{config: config, client: client}, nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// Adjusting here to cope with spikes in propagation times.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+
+	zone, err := getZone(fqdn)
+	if err != nil {
+		return fmt.Errorf("hetzner: failed to find zone: fqdn=%s: %w", fqdn, err)
+	}
+
+	zoneID, err := d.client.GetZoneID(zone)
+	if err != nil {
+		return fmt.Errorf("hetzner: %w", err)
+	}
+
+	record := internal.DNSRecord{
+		Type:   "TXT",
+		Name:   extractRecordName(fqdn, zone),
+		Value:  value,
+		TTL:    d.config.TTL,
+		ZoneID: zoneID,
+	}
+
+	if err := d.client.CreateRecord(record); err != nil {
+		return fmt.Errorf("hetzner: failed to add TXT record: fqdn=%s, zoneID=%s: %w", fqdn, zoneID, err)
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+
+	zone, err := getZone(fqdn)
+	if err != nil {
+		return fmt.Errorf("hetzner: failed to find zone: fqdn=%s: %w", fqdn, err)
+	}
+
+	zoneID, err := d.client.GetZoneID(zone)
+	if err != nil {
+		return fmt.Errorf("hetzner: %w", err)
+	}
+
+	records, err := d.client.GetTxtRecords(extractRecordName(fqdn, zone), zoneID)
+	if err != nil {
+		return fmt.Errorf("hetzner: %w", err)
+	}
+
+	for _, record := range records {
+		if record.Value != value {
+			continue
+		}
+
+		if err := d.client.DeleteRecord(record.ID); err != nil {
+			return fmt.Errorf("hetzner: failed to delete TXT record: fqdn=%s, recordID=%s: %w", fqdn, record.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// ListChallengeRecords lists the challenge TXT records of all the zones of the account.
+func (d *DNSProvider) ListChallengeRecords() ([]challenge.Record, error) {
+	zones, err := d.client.GetZones()
+	if err != nil {
+		return nil, fmt.Errorf("hetzner: %w", err)
+	}
+
+	var challengeRecords []challenge.Record
+	for _, zone := range zones {
+		records, err := d.client.GetRecords(zone.ID)
+		if err != nil {
+			return nil, fmt.Errorf("hetzner: %w", err)
+		}
+
+		for _, record := range records {
+			if record.Type != "TXT" || !isChallengeRecordName(record.Name) {
+				continue
+			}
+
+			challengeRecords = append(challengeRecords, challenge.Record{
+				FQDN:    dns01.ToFqdn(record.Name + "." + zone.Name),
+				Value:   record.Value,
+				Zone:    zone.ID,
+				ID:      record.ID,
+				Created: record.CreatedAt(),
+			})
+		}
+	}
+
+	return challengeRecords, nil
+}
+
+// DeleteChallengeRecord deletes a record returned by ListChallengeRecords.
+func (d *DNSProvider) DeleteChallengeRecord(record challenge.Record) error {
+	if err := d.client.DeleteRecord(record.ID); err != nil {
+		return fmt.Errorf("hetzner: failed to delete TXT record: fqdn=%s, recordID=%s: %w", record.FQDN, record.ID, err)
+	}
+	return nil
+}
+
+// PublishTLSA replaces the TLSA records of the fqdn.
+func (d *DNSProvider) PublishTLSA(fqdn string, values []string) error {
+	zone, err := getZone(fqdn)
+	if err != nil {
+		return fmt.Errorf("hetzner: failed to find zone: fqdn=%s: %w", fqdn, err)
+	}
+
+	zoneID, err := d.client.GetZoneID(zone)
+	if err != nil {
+		return fmt.Errorf("hetzner: %w", err)
+	}
+
+	name := extractRecordName(fqdn, zone)
+
+	records, err := d.client.GetRecords(zoneID)
+	if err != nil {
+		return fmt.Errorf("hetzner: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	for _, record := range records {
+		if record.Type != "TLSA" || record.Name != name {
+			continue
+		}
+
+		if containsValue(values, record.Value) {
+			existing[record.Value] = true
+			continue
+		}
+
+		if err := d.client.DeleteRecord(record.ID); err != nil {
+			return fmt.Errorf("hetzner: failed to delete TLSA record: fqdn=%s, recordID=%s: %w", fqdn, record.ID, err)
+		}
+	}
+
+	for _, value := range values {
+		if existing[value] {
+			continue
+		}
+
+		record := internal.DNSRecord{
+			Type:   "TLSA",
+			Name:   name,
+			Value:  value,
+			TTL:    d.config.TTL,
+			ZoneID: zoneID,
+		}
+
+		if err := d.client.CreateRecord(record); err != nil {
+			return fmt.Errorf("hetzner: failed to add TLSA record: fqdn=%s, zoneID=%s: %w", fqdn, zoneID, err)
+		}
+	}
+
+	return nil
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func isChallengeRecordName(name string) bool {
+	return name == "_acme-challenge" || strings.HasPrefix(name, "_acme-challenge.")
+}
+
+func extractRecordName(fqdn, zone string) string {
+	name := dns01.UnFqdn(fqdn)
+	if idx := strings.Index(name, "."+zone); idx != -1 {
+		return name[:idx]
+	}
+	return name
+}
+
+func getZone(fqdn string) (string, error) {
+	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	if err != nil {
+		return "", err
+	}
+
+	return dns01.UnFqdn(authZone), nil
+}
@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+)
+
+const defaultBaseURL = "https://dns.hetzner.com"
+
+const authHeader = "Auth-API-Token"
+
+// Client the Hetzner client.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+
+	apiKey string
+}
+
+// NewClient Creates a new Client.
+func NewClient(apiKey string) *Client {
+	return &Client{
+		HTTPClient: http.DefaultClient,
+		BaseURL:    defaultBaseURL,
+		apiKey:     apiKey,
+	}
+}
+
+// GetZoneID gets the zone ID for a domain.
+func (c *Client) GetZoneID(domain string) (string, error) {
+	endpoint, err := c.createEndpoint("api", "v1", "zones")
+	if err != nil {
+		return "", err
+	}
+
+	query := endpoint.Query()
+	query.Set("name", domain)
+	endpoint.RawQuery = query.Encode()
+
+	var zones Zones
+	err = c.do(http.MethodGet, endpoint, nil, &zones)
+	if err != nil {
+		return "", fmt.Errorf("could not get zone %s: %w", domain, err)
+	}
+
+	for _, zone := range zones.Zones {
+		if zone.Name == domain {
+			return zone.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("zone %s not found", domain)
+}
+
+// GetZones gets all the zones.
+func (c *Client) GetZones() ([]Zone, error) {
+	var all []Zone
+
+	for page := 1; ; page++ {
+		endpoint, err := c.createEndpoint("api", "v1", "zones")
+		if err != nil {
+			return nil, err
+		}
+
+		query := endpoint.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", "100")
+		endpoint.RawQuery = query.Encode()
+
+		var zones Zones
+		err = c.do(http.MethodGet, endpoint, nil, &zones)
+		if err != nil {
+			return nil, fmt.Errorf("could not get zones: %w", err)
+		}
+
+		all = append(all, zones.Zones...)
+
+		if zones.Meta.Pagination.Page >= zones.Meta.Pagination.LastPage {
+			return all, nil
+		}
+	}
+}
+
+// GetRecords gets all the records of a zone.
+func (c *Client) GetRecords(zoneID string) ([]DNSRecord, error) {
+	endpoint, err := c.createEndpoint("api", "v1", "records")
+	if err != nil {
+		return nil, err
+	}
+
+	query := endpoint.Query()
+	query.Set("zone_id", zoneID)
+	endpoint.RawQuery = query.Encode()
+
+	var records DNSRecords
+	err = c.do(http.MethodGet, endpoint, nil, &records)
+	if err != nil {
+		return nil, fmt.Errorf("could not get records: zone ID: %s: %w", zoneID, err)
+	}
+
+	return records.Records, nil
+}
+
+// GetTxtRecords gets the TXT records of a zone with the given name.
+func (c *Client) GetTxtRecords(name, zoneID string) ([]DNSRecord, error) {
+	records, err := c.GetRecords(zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	var txtRecords []DNSRecord
+	for _, record := range records {
+		if record.Type == "TXT" && record.Name == name {
+			txtRecords = append(txtRecords, record)
+		}
+	}
+
+	return txtRecords, nil
+}
+
+// CreateRecord creates a DNS record.
+func (c *Client) CreateRecord(record DNSRecord) error {
+	endpoint, err := c.createEndpoint("api", "v1", "records")
+	if err != nil {
+		return err
+	}
+
+	err = c.do(http.MethodPost, endpoint, record, nil)
+	if err != nil {
+		return fmt.Errorf("could not create record %s: %w", record.Name, err)
+	}
+
+	return nil
+}
+
+// DeleteRecord deletes a DNS record.
+func (c *Client) DeleteRecord(recordID string) error {
+	endpoint, err := c.createEndpoint("api", "v1", "records", recordID)
+	if err != nil {
+		return err
+	}
+
+	err = c.do(http.MethodDelete, endpoint, nil, nil)
+	if err != nil {
+		return fmt.Errorf("could not delete record %s: %w", recordID, err)
+	}
+
+	return nil
+}
+
+func (c *Client) do(method string, endpoint *url.URL, body interface{}, result interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequest(method, endpoint.String(), reqBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(authHeader, c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr APIError
+		if json.Unmarshal(raw, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%d: %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return fmt.Errorf("%d: %s", resp.StatusCode, string(raw))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(raw, result)
+}
+
+func (c *Client) createEndpoint(fragments ...string) (*url.URL, error) {
+	baseURL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return baseURL.Parse(path.Join(baseURL.Path, path.Join(fragments...)))
+}
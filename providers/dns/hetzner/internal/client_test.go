@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T, method, pattern string, status int, file string) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc(pattern, func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != method {
+			http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get(authHeader) != "secret" {
+			http.Error(rw, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		if file == "" {
+			rw.WriteHeader(status)
+			return
+		}
+
+		open, err := os.Open(file)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = open.Close() }()
+
+		rw.WriteHeader(status)
+		_, _ = io.Copy(rw, open)
+	})
+
+	client := NewClient("secret")
+	client.BaseURL = server.URL
+
+	return client
+}
+
+func TestClient_GetZoneID(t *testing.T) {
+	client := setupTest(t, http.MethodGet, "/api/v1/zones", http.StatusOK, "./fixtures/get_zones.json")
+
+	zoneID, err := client.GetZoneID("example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, "zoneA", zoneID)
+
+	_, err = client.GetZoneID("example.org")
+	require.EqualError(t, err, "zone example.org not found")
+}
+
+func TestClient_GetTxtRecords(t *testing.T) {
+	client := setupTest(t, http.MethodGet, "/api/v1/records", http.StatusOK, "./fixtures/get_records.json")
+
+	records, err := client.GetTxtRecords("_acme-challenge", "zoneA")
+	require.NoError(t, err)
+
+	expected := []DNSRecord{
+		{ID: "recA", Name: "_acme-challenge", Type: "TXT", Value: "txtxtxt", TTL: 120, ZoneID: "zoneA", Created: "2020-04-07 09:19:38.543 +0000 UTC"},
+	}
+	assert.Equal(t, expected, records)
+
+	assert.Equal(t, time.Date(2020, time.April, 7, 9, 19, 38, 543000000, time.UTC), records[0].CreatedAt().UTC())
+}
+
+func TestClient_GetZones(t *testing.T) {
+	client := setupTest(t, http.MethodGet, "/api/v1/zones", http.StatusOK, "./fixtures/get_zones.json")
+
+	zones, err := client.GetZones()
+	require.NoError(t, err)
+
+	expected := []Zone{{ID: "zoneA", Name: "example.com"}}
+	assert.Equal(t, expected, zones)
+}
+
+func TestClient_CreateRecord(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	var created DNSRecord
+	mux.HandleFunc("/api/v1/records", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		err := json.NewDecoder(req.Body).Decode(&created)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(map[string]DNSRecord{"record": created})
+	})
+
+	client := NewClient("secret")
+	client.BaseURL = server.URL
+
+	record := DNSRecord{Name: "_acme-challenge", Type: "TXT", Value: "txtxtxt", TTL: 120, ZoneID: "zoneA"}
+
+	err := client.CreateRecord(record)
+	require.NoError(t, err)
+
+	assert.Equal(t, record, created)
+}
+
+func TestClient_DeleteRecord(t *testing.T) {
+	client := setupTest(t, http.MethodDelete, "/api/v1/records/recA", http.StatusOK, "")
+
+	err := client.DeleteRecord("recA")
+	require.NoError(t, err)
+}
+
+func TestClient_error(t *testing.T) {
+	client := setupTest(t, http.MethodGet, "/api/v1/zones", http.StatusUnauthorized, "./fixtures/error.json")
+
+	_, err := client.GetZoneID("example.com")
+	require.EqualError(t, err, "could not get zone example.com: 401: invalid API token")
+}
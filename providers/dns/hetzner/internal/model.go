@@ -0,0 +1,57 @@
+package internal
+
+import "time"
+
+// DNSRecord a DNS record.
+type DNSRecord struct {
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	TTL    int    `json:"ttl,omitempty"`
+	ZoneID string `json:"zone_id,omitempty"`
+
+	Created string `json:"created,omitempty"`
+}
+
+// createdLayout the layout of the creation time of the records.
+const createdLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// CreatedAt returns the creation time of the record, zero if unknown.
+func (r DNSRecord) CreatedAt() time.Time {
+	created, err := time.Parse(createdLayout, r.Created)
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
+// DNSRecords a set of DNS records.
+type DNSRecords struct {
+	Records []DNSRecord `json:"records"`
+}
+
+// Zone a DNS zone.
+type Zone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Zones a set of DNS zones.
+type Zones struct {
+	Zones []Zone `json:"zones"`
+	Meta  struct {
+		Pagination struct {
+			Page     int `json:"page"`
+			LastPage int `json:"last_page"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+// APIError an API error.
+type APIError struct {
+	Error struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
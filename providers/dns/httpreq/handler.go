@@ -0,0 +1,126 @@
+package httpreq
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"github.com/go-acme/lego/v3/log"
+)
+
+// NewHandler returns the server side of the protocol, in RAW mode:
+// the requests received on `/present` and `/cleanup` are forwarded to the DNS provider.
+// This allows to keep the DNS credentials on a single host (a bastion host),
+// the other hosts using this provider with HTTPREQ_MODE=RAW.
+// If username and password are not empty, the requests must use the basic authentication.
+// If domains are given, only the challenges of these domains and of their subdomains are accepted.
+func NewHandler(provider challenge.Provider, username, password string, domains ...string) http.Handler {
+	h := &handler{username: username, password: password}
+
+	for _, domain := range domains {
+		h.domains = append(h.domains, normalizeDomain(domain))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/present", h.serve(provider.Present))
+	mux.HandleFunc("/cleanup", h.serve(provider.CleanUp))
+
+	return mux
+}
+
+type handler struct {
+	username string
+	password string
+	domains  []string
+}
+
+func (h *handler) serve(action func(domain, token, keyAuth string) error) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !h.authorized(req) {
+			rw.Header().Set("WWW-Authenticate", `Basic realm="lego"`)
+			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		msg, err := readMessage(req)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !h.allowed(msg.Domain) {
+			log.Warnf("httpreq: %s %s: domain not allowed", req.URL.Path, msg.Domain)
+			http.Error(rw, fmt.Sprintf("domain not allowed: %s", msg.Domain), http.StatusForbidden)
+			return
+		}
+
+		err = action(msg.Domain, msg.Token, msg.KeyAuth)
+		if err != nil {
+			log.Warnf("httpreq: %s %s: %v", req.URL.Path, msg.Domain, err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Infof("httpreq: %s %s", req.URL.Path, msg.Domain)
+	}
+}
+
+func (h *handler) authorized(req *http.Request) bool {
+	if h.username == "" && h.password == "" {
+		return true
+	}
+
+	username, password, ok := req.BasicAuth()
+
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(username), []byte(h.username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) == 1
+}
+
+// allowed reports whether the challenges of the domain can be served:
+// the domain, or one of its parents, is in the allowed domains.
+func (h *handler) allowed(domain string) bool {
+	if len(h.domains) == 0 {
+		return true
+	}
+
+	domain = normalizeDomain(domain)
+
+	for _, allowed := range h.domains {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeDomain returns the domain in lower case, without the wildcard prefix and the trailing dot.
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	return strings.TrimPrefix(domain, "*.")
+}
+
+func readMessage(req *http.Request) (*messageRaw, error) {
+	var msg messageRaw
+	err := json.NewDecoder(http.MaxBytesReader(nil, req.Body, 1<<16)).Decode(&msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	// the default mode only contains the FQDN and the value of the record, not enough for the provider.
+	if msg.Domain == "" || msg.KeyAuth == "" {
+		return nil, errors.New("invalid request body: domain and keyAuth are required (the client must use HTTPREQ_MODE=RAW)")
+	}
+
+	return &msg, nil
+}
@@ -0,0 +1,146 @@
+package httpreq
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	presented []string
+	cleaned   []string
+}
+
+func (f *fakeProvider) Present(domain, token, keyAuth string) error {
+	if domain == "fail.example.com" {
+		return errors.New("boom")
+	}
+	f.presented = append(f.presented, domain+" "+token+" "+keyAuth)
+	return nil
+}
+
+func (f *fakeProvider) CleanUp(domain, token, keyAuth string) error {
+	f.cleaned = append(f.cleaned, domain+" "+token+" "+keyAuth)
+	return nil
+}
+
+func TestNewHandler(t *testing.T) {
+	provider := &fakeProvider{}
+
+	server := httptest.NewServer(NewHandler(provider, "user", "secret"))
+	defer server.Close()
+
+	testCases := []struct {
+		desc          string
+		mode          string
+		username      string
+		password      string
+		domain        string
+		expectedError string
+	}{
+		{
+			desc:     "success",
+			mode:     "RAW",
+			username: "user",
+			password: "secret",
+			domain:   "example.com",
+		},
+		{
+			desc:          "invalid credentials",
+			mode:          "RAW",
+			username:      "user",
+			password:      "nope",
+			domain:        "example.com",
+			expectedError: "httpreq: 401: request failed: Unauthorized\n",
+		},
+		{
+			desc:          "default mode",
+			username:      "user",
+			password:      "secret",
+			domain:        "example.com",
+			expectedError: "httpreq: 400: request failed: invalid request body: domain and keyAuth are required (the client must use HTTPREQ_MODE=RAW)\n",
+		},
+		{
+			desc:          "provider error",
+			mode:          "RAW",
+			username:      "user",
+			password:      "secret",
+			domain:        "fail.example.com",
+			expectedError: "httpreq: 500: request failed: boom\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Endpoint = mustParse(server.URL)
+			config.Mode = test.mode
+			config.Username = test.username
+			config.Password = test.password
+
+			p, err := NewDNSProviderConfig(config)
+			require.NoError(t, err)
+
+			err = p.Present(test.domain, "token", "key")
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			err = p.CleanUp(test.domain, "token", "key")
+			require.NoError(t, err)
+		})
+	}
+
+	assert.Equal(t, []string{"example.com token key"}, provider.presented)
+	assert.Equal(t, []string{"example.com token key"}, provider.cleaned)
+}
+
+func TestNewHandler_domains(t *testing.T) {
+	provider := &fakeProvider{}
+
+	server := httptest.NewServer(NewHandler(provider, "", "", "example.com", "*.example.org"))
+	defer server.Close()
+
+	testCases := []struct {
+		domain        string
+		expectedError string
+	}{
+		{domain: "example.com"},
+		{domain: "*.example.com"},
+		{domain: "sub.Example.com."},
+		{domain: "example.org"},
+		{
+			domain:        "example.net",
+			expectedError: "httpreq: 403: request failed: domain not allowed: example.net\n",
+		},
+		{
+			domain:        "notexample.com",
+			expectedError: "httpreq: 403: request failed: domain not allowed: notexample.com\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.domain, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.Endpoint = mustParse(server.URL)
+			config.Mode = "RAW"
+
+			p, err := NewDNSProviderConfig(config)
+			require.NoError(t, err)
+
+			err = p.Present(test.domain, "token", "key")
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	assert.Len(t, provider.presented, 4)
+}
@@ -0,0 +1,177 @@
+package infomaniak
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envDomain = envNamespace + "DOMAIN"
+
+var envTest = tester.NewEnvTest(EnvEndpoint, EnvAccessToken, EnvTTL).WithDomain(envDomain)
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvAccessToken: "123",
+			},
+		},
+		{
+			desc: "missing access token",
+			envVars: map[string]string{
+				EnvAccessToken: "",
+			},
+			expected: "infomaniak: some credentials information are missing: INFOMANIAK_ACCESS_TOKEN",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider()
+
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		accessToken string
+		ttl         int
+		expected    string
+	}{
+		{
+			desc:        "success",
+			accessToken: "123",
+			ttl:         minTTL,
+		},
+		{
+			desc:     "missing access token",
+			ttl:      minTTL,
+			expected: "infomaniak: missing access token",
+		},
+		{
+			desc:        "invalid TTL",
+			accessToken: "123",
+			ttl:         60,
+			expected:    "infomaniak: invalid TTL, TTL (60) must be greater than 300",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.AccessToken = test.accessToken
+			config.TTL = test.ttl
+
+			p, err := NewDNSProviderConfig(config)
+
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestDNSProvider_PresentAndCleanUp(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/1/product", func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("customer_name") != "example.com" {
+			_, _ = fmt.Fprint(rw, `{"result":"success","data":[]}`)
+			return
+		}
+		_, _ = fmt.Fprint(rw, `{"result":"success","data":[{"id":123,"customer_name":"example.com"}]}`)
+	})
+
+	var deleted bool
+	mux.HandleFunc("/1/domain/123/dns/record", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, `{"result":"success","data":"456"}`)
+	})
+	mux.HandleFunc("/1/domain/123/dns/record/456", func(rw http.ResponseWriter, req *http.Request) {
+		deleted = req.Method == http.MethodDelete
+		_, _ = fmt.Fprint(rw, `{"result":"success","data":true}`)
+	})
+
+	config := NewDefaultConfig()
+	config.APIEndpoint = server.URL
+	config.AccessToken = "token"
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	err = p.Present("sub.example.com", "abc", "123d==")
+	require.NoError(t, err)
+
+	assert.Equal(t, "456", p.recordIDs["abc"])
+
+	err = p.CleanUp("sub.example.com", "abc", "123d==")
+	require.NoError(t, err)
+
+	assert.True(t, deleted)
+	assert.Empty(t, p.recordIDs)
+}
+
+func Test_extractRecordName(t *testing.T) {
+	assert.Equal(t, "_acme-challenge.sub", extractRecordName("_acme-challenge.sub.example.com.", "example.com"))
+}
+
+func TestLivePresent(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	err = provider.Present(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
+
+func TestLiveCleanUp(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
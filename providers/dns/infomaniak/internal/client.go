@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+)
+
+// DefaultBaseURL the default API endpoint.
+const DefaultBaseURL = "https://api.infomaniak.com"
+
+// Client the Infomaniak client.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+
+	accessToken string
+}
+
+// NewClient Creates a new Client.
+func NewClient(baseURL, accessToken string) *Client {
+	return &Client{
+		HTTPClient:  http.DefaultClient,
+		BaseURL:     baseURL,
+		accessToken: accessToken,
+	}
+}
+
+// CreateDNSRecord creates a DNS record in a domain and returns its ID.
+func (c *Client) CreateDNSRecord(domain *DNSDomain, record Record) (string, error) {
+	endpoint, err := c.createEndpoint("1", "domain", strconv.FormatUint(domain.ID, 10), "dns", "record")
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	var recordID string
+	err = c.do(http.MethodPost, endpoint, bytes.NewReader(raw), &recordID)
+	if err != nil {
+		return "", err
+	}
+
+	return recordID, nil
+}
+
+// DeleteDNSRecord deletes a DNS record of a domain.
+func (c *Client) DeleteDNSRecord(domainID uint64, recordID string) error {
+	endpoint, err := c.createEndpoint("1", "domain", strconv.FormatUint(domainID, 10), "dns", "record", recordID)
+	if err != nil {
+		return err
+	}
+
+	return c.do(http.MethodDelete, endpoint, nil, nil)
+}
+
+// GetDomainByName gets the domain product managing a name,
+// by looking up the name and then each of its parents.
+func (c *Client) GetDomainByName(name string) (*DNSDomain, error) {
+	name = dns01.UnFqdn(name)
+	original := name
+
+	for {
+		domain, err := c.getDomainByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if domain != nil {
+			return domain, nil
+		}
+
+		idx := strings.Index(name, ".")
+		if idx == -1 {
+			break
+		}
+		name = name[idx+1:]
+	}
+
+	return nil, fmt.Errorf("domain not found: %s", original)
+}
+
+func (c *Client) getDomainByName(name string) (*DNSDomain, error) {
+	endpoint, err := c.createEndpoint("1", "product")
+	if err != nil {
+		return nil, err
+	}
+
+	query := endpoint.Query()
+	query.Set("service_name", "domain")
+	query.Set("customer_name", name)
+	endpoint.RawQuery = query.Encode()
+
+	var domains []DNSDomain
+	err = c.do(http.MethodGet, endpoint, nil, &domains)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, domain := range domains {
+		if domain.CustomerName == name {
+			return &domain, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (c *Client) do(method string, endpoint *url.URL, body io.Reader, result interface{}) error {
+	req, err := http.NewRequest(method, endpoint.String(), body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp APIResponse
+	err = json.Unmarshal(raw, &apiResp)
+	if err != nil {
+		return fmt.Errorf("%d: failed to unmarshal response: %w: %s", resp.StatusCode, err, string(raw))
+	}
+
+	if apiResp.Result != "success" {
+		if apiResp.ErrResponse != nil {
+			return fmt.Errorf("%d: %w", resp.StatusCode, apiResp.ErrResponse)
+		}
+		return fmt.Errorf("%d: unexpected response: %s", resp.StatusCode, string(raw))
+	}
+
+	if result == nil || len(apiResp.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(apiResp.Data, result)
+}
+
+func (c *Client) createEndpoint(fragments ...string) (*url.URL, error) {
+	baseURL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return baseURL.Parse(path.Join(baseURL.Path, path.Join(fragments...)))
+}
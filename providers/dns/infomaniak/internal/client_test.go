@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T) (*Client, *http.ServeMux) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return NewClient(server.URL, "token"), mux
+}
+
+func authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			_, _ = fmt.Fprint(rw, `{"result":"error","error":{"code":"not_authorized","description":"Authorization required"}}`)
+			return
+		}
+		next(rw, req)
+	}
+}
+
+func TestClient_GetDomainByName(t *testing.T) {
+	client, mux := setupTest(t)
+
+	var lookups []string
+	mux.HandleFunc("/1/product", authenticated(func(rw http.ResponseWriter, req *http.Request) {
+		name := req.URL.Query().Get("customer_name")
+		lookups = append(lookups, name)
+
+		if name != "example.com" {
+			_, _ = fmt.Fprint(rw, `{"result":"success","data":[]}`)
+			return
+		}
+		_, _ = fmt.Fprint(rw, `{"result":"success","data":[{"id":123,"service_name":"domain","customer_name":"example.com"}]}`)
+	}))
+
+	domain, err := client.GetDomainByName("_acme-challenge.sub.example.com.")
+	require.NoError(t, err)
+
+	assert.Equal(t, &DNSDomain{ID: 123, CustomerName: "example.com"}, domain)
+	assert.Equal(t, []string{"_acme-challenge.sub.example.com", "sub.example.com", "example.com"}, lookups)
+
+	_, err = client.GetDomainByName("example.org.")
+	require.EqualError(t, err, "domain not found: example.org")
+}
+
+func TestClient_CreateDNSRecord(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/1/domain/123/dns/record", authenticated(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var record Record
+		err := json.NewDecoder(req.Body).Decode(&record)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if record != (Record{Source: "_acme-challenge", Type: "TXT", TTL: 300, Target: "txtxtxt"}) {
+			http.Error(rw, fmt.Sprintf("unexpected record: %v", record), http.StatusBadRequest)
+			return
+		}
+
+		_, _ = fmt.Fprint(rw, `{"result":"success","data":"456"}`)
+	}))
+
+	record := Record{Source: "_acme-challenge", Type: "TXT", TTL: 300, Target: "txtxtxt"}
+
+	recordID, err := client.CreateDNSRecord(&DNSDomain{ID: 123, CustomerName: "example.com"}, record)
+	require.NoError(t, err)
+
+	assert.Equal(t, "456", recordID)
+}
+
+func TestClient_DeleteDNSRecord(t *testing.T) {
+	client, mux := setupTest(t)
+
+	mux.HandleFunc("/1/domain/123/dns/record/456", authenticated(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodDelete {
+			http.Error(rw, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = fmt.Fprint(rw, `{"result":"success","data":true}`)
+	}))
+
+	err := client.DeleteDNSRecord(123, "456")
+	require.NoError(t, err)
+}
+
+func TestClient_error(t *testing.T) {
+	client, mux := setupTest(t)
+	client.accessToken = "invalid"
+
+	mux.HandleFunc("/1/domain/123/dns/record/456", authenticated(nil))
+
+	err := client.DeleteDNSRecord(123, "456")
+	require.EqualError(t, err, "401: code: not_authorized, description: Authorization required")
+}
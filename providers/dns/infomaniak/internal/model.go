@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Record a DNS record.
+type Record struct {
+	ID     string `json:"id,omitempty"`
+	Source string `json:"source,omitempty"`
+	Type   string `json:"type,omitempty"`
+	TTL    int    `json:"ttl,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// DNSDomain a domain product.
+type DNSDomain struct {
+	ID           uint64 `json:"id,omitempty"`
+	CustomerName string `json:"customer_name,omitempty"`
+}
+
+// APIResponse the envelope of all the API responses.
+type APIResponse struct {
+	Result      string          `json:"result"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	ErrResponse *APIError       `json:"error,omitempty"`
+}
+
+// APIError an API error.
+type APIError struct {
+	Code        string `json:"code"`
+	Description string `json:"description,omitempty"`
+}
+
+func (a APIError) Error() string {
+	return fmt.Sprintf("code: %s, description: %s", a.Code, a.Description)
+}
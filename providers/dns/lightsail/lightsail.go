@@ -10,9 +10,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/request"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/lightsail"
 	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/platform/cloudcreds"
 	"github.com/go-acme/lego/v3/platform/config/env"
 )
 
@@ -102,7 +102,7 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 	retryer.NumMaxRetries = maxRetries
 
 	conf := aws.NewConfig().WithRegion(config.Region)
-	sess, err := session.NewSession(request.WithRetryer(conf, retryer))
+	sess, err := cloudcreds.NewAWSSession(cloudcreds.AWSConfig{}, request.WithRetryer(conf, retryer))
 	if err != nil {
 		return nil, err
 	}
@@ -1,10 +1,12 @@
 package pdns
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -25,8 +27,13 @@ type hostedZone struct {
 	ID     string  `json:"id"`
 	Name   string  `json:"name"`
 	URL    string  `json:"url"`
+	Kind   string  `json:"kind"`
 	RRSets []rrSet `json:"rrsets"`
 
+	// DNSSEC signed zones need to be rectified after changes, unless the server does it (API-RECTIFY).
+	DNSSec     bool `json:"dnssec"`
+	APIRectify bool `json:"api_rectify"`
+
 	// pre-v1 API
 	Records []Record `json:"records"`
 }
@@ -58,13 +65,18 @@ type apiVersion struct {
 }
 
 func (d *DNSProvider) getHostedZone(fqdn string) (*hostedZone, error) {
-	var zone hostedZone
 	authZone, err := dns01.FindZoneByFqdn(fqdn)
 	if err != nil {
 		return nil, err
 	}
 
-	u := "/servers/localhost/zones"
+	return d.getZone(authZone)
+}
+
+func (d *DNSProvider) getZone(authZone string) (*hostedZone, error) {
+	var zone hostedZone
+
+	u := "/servers/" + url.PathEscape(d.config.ServerName) + "/zones"
 	result, err := d.sendRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
@@ -84,6 +96,10 @@ func (d *DNSProvider) getHostedZone(fqdn string) (*hostedZone, error) {
 		}
 	}
 
+	if u == "" {
+		return nil, fmt.Errorf("zone %s not found on server %s", authZone, d.config.ServerName)
+	}
+
 	result, err = d.sendRequest(http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
@@ -130,6 +146,32 @@ func (d *DNSProvider) findTxtRecord(fqdn string) (*rrSet, error) {
 	return nil, nil
 }
 
+// updateRecords applies the RRSet changes to the zone,
+// and rectifies the zone if it is DNSSEC signed.
+func (d *DNSProvider) updateRecords(zone *hostedZone, rrsets rrSets) error {
+	body, err := json.Marshal(rrsets)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.sendRequest(http.MethodPatch, zone.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// the rectify endpoint only exists since API v1.
+	if d.apiVersion < 1 || !zone.DNSSec || zone.APIRectify || zone.Kind == "Slave" {
+		return nil
+	}
+
+	_, err = d.sendRequest(http.MethodPut, strings.TrimSuffix(zone.URL, "/")+"/rectify", nil)
+	if err != nil {
+		return fmt.Errorf("failed to rectify zone %s: %w", zone.Name, err)
+	}
+
+	return nil
+}
+
 func (d *DNSProvider) getAPIVersion() (int, error) {
 	result, err := d.sendRequest(http.MethodGet, "/api", nil)
 	if err != nil {
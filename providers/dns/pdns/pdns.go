@@ -2,8 +2,6 @@
 package pdns
 
 import (
-	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -13,6 +11,7 @@ import (
 	"github.com/go-acme/lego/v3/challenge/dns01"
 	"github.com/go-acme/lego/v3/log"
 	"github.com/go-acme/lego/v3/platform/config/env"
+	"github.com/go-acme/lego/v3/platform/httpclient"
 )
 
 // Environment variables names.
@@ -22,6 +21,8 @@ const (
 	EnvAPIKey = envNamespace + "API_KEY"
 	EnvAPIURL = envNamespace + "API_URL"
 
+	EnvServerName = envNamespace + "SERVER_NAME"
+
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
 	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
@@ -32,6 +33,7 @@ const (
 type Config struct {
 	APIKey             string
 	Host               *url.URL
+	ServerName         string
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	TTL                int
@@ -41,12 +43,11 @@ type Config struct {
 // NewDefaultConfig returns a default configuration for the DNSProvider
 func NewDefaultConfig() *Config {
 	return &Config{
+		ServerName:         env.GetOrDefaultString(EnvServerName, "localhost"),
 		TTL:                env.GetOrDefaultInt(EnvTTL, dns01.DefaultTTL),
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 120*time.Second),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 2*time.Second),
-		HTTPClient: &http.Client{
-			Timeout: env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second),
-		},
+		HTTPClient:         httpclient.New(env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second)),
 	}
 }
 
@@ -91,6 +92,10 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("pdns: API URL missing")
 	}
 
+	if config.ServerName == "" {
+		config.ServerName = "localhost"
+	}
+
 	d := &DNSProvider{config: config}
 
 	apiVersion, err := d.getAPIVersion()
@@ -160,12 +165,7 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 		},
 	}
 
-	body, err := json.Marshal(rrsets)
-	if err != nil {
-		return fmt.Errorf("pdns: %w", err)
-	}
-
-	_, err = d.sendRequest(http.MethodPatch, zone.URL, bytes.NewReader(body))
+	err = d.updateRecords(zone, rrsets)
 	if err != nil {
 		return fmt.Errorf("pdns: %w", err)
 	}
@@ -198,12 +198,7 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 			},
 		},
 	}
-	body, err := json.Marshal(rrsets)
-	if err != nil {
-		return fmt.Errorf("pdns: %w", err)
-	}
-
-	_, err = d.sendRequest(http.MethodPatch, zone.URL, bytes.NewReader(body))
+	err = d.updateRecords(zone, rrsets)
 	if err != nil {
 		return fmt.Errorf("pdns: %w", err)
 	}
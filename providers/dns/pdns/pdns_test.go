@@ -1,10 +1,14 @@
 package pdns
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -12,7 +16,8 @@ const envDomain = envNamespace + "DOMAIN"
 
 var envTest = tester.NewEnvTest(
 	EnvAPIURL,
-	EnvAPIKey).
+	EnvAPIKey,
+	EnvServerName).
 	WithDomain(envDomain)
 
 func TestNewDNSProvider(t *testing.T) {
@@ -143,3 +148,99 @@ func TestLivePresentAndCleanup(t *testing.T) {
 	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
 	require.NoError(t, err)
 }
+
+func TestDNSProvider_updateRecords_rectify(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		zone      string
+		rectified bool
+	}{
+		{
+			desc:      "DNSSEC signed zone",
+			zone:      `{"name":"example.com.","kind":"Native","url":"/api/v1/servers/ns1/zones/example.com.","dnssec":true,"api_rectify":false}`,
+			rectified: true,
+		},
+		{
+			desc: "DNSSEC signed zone with API-RECTIFY",
+			zone: `{"name":"example.com.","kind":"Native","url":"/api/v1/servers/ns1/zones/example.com.","dnssec":true,"api_rectify":true}`,
+		},
+		{
+			desc: "unsigned zone",
+			zone: `{"name":"example.com.","kind":"Master","url":"/api/v1/servers/ns1/zones/example.com.","dnssec":false}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var patched, rectified bool
+
+			mux := http.NewServeMux()
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			mux.HandleFunc("/api", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = fmt.Fprint(rw, `[{"url":"/api/v1","version":1}]`)
+			})
+			mux.HandleFunc("/api/v1/servers/ns1/zones", func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = fmt.Fprint(rw, `[{"name":"example.com.","url":"/api/v1/servers/ns1/zones/example.com."}]`)
+			})
+			mux.HandleFunc("/api/v1/servers/ns1/zones/example.com.", func(rw http.ResponseWriter, req *http.Request) {
+				switch req.Method {
+				case http.MethodGet:
+					_, _ = fmt.Fprint(rw, test.zone)
+				case http.MethodPatch:
+					patched = true
+					rw.WriteHeader(http.StatusNoContent)
+				default:
+					http.Error(rw, "unexpected method", http.StatusMethodNotAllowed)
+				}
+			})
+			mux.HandleFunc("/api/v1/servers/ns1/zones/example.com./rectify", func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPut {
+					http.Error(rw, "unexpected method", http.StatusMethodNotAllowed)
+					return
+				}
+				rectified = true
+				_, _ = fmt.Fprint(rw, `{"result":"Rectified"}`)
+			})
+
+			config := NewDefaultConfig()
+			config.APIKey = "secret"
+			config.ServerName = "ns1"
+			config.Host, _ = url.Parse(server.URL)
+
+			p, err := NewDNSProviderConfig(config)
+			require.NoError(t, err)
+			require.Equal(t, 1, p.apiVersion)
+
+			zone, err := p.getZone("example.com.")
+			require.NoError(t, err)
+
+			err = p.updateRecords(zone, rrSets{RRSets: []rrSet{{Name: "_acme-challenge.example.com.", Type: "TXT", ChangeType: "DELETE"}}})
+			require.NoError(t, err)
+
+			assert.True(t, patched)
+			assert.Equal(t, test.rectified, rectified)
+		})
+	}
+}
+
+func TestDNSProvider_getZone_notFound(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/servers/localhost/zones", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprint(rw, `[{"name":"example.org.","url":"/servers/localhost/zones/example.org."}]`)
+	})
+
+	config := NewDefaultConfig()
+	config.APIKey = "secret"
+	config.Host, _ = url.Parse(server.URL)
+
+	p, err := NewDNSProviderConfig(config)
+	require.NoError(t, err)
+
+	_, err = p.getZone("example.com.")
+	require.EqualError(t, err, "zone example.com. not found on server localhost")
+}
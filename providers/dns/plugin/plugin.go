@@ -0,0 +1,310 @@
+// Package plugin implements a DNS provider for solving the DNS-01 challenge through an external program (plugin) using a gRPC protocol.
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/log"
+	"github.com/go-acme/lego/v3/platform/config/env"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Handshake between lego and a plugin.
+const (
+	// MagicCookieKey the environment variable defined by lego when it starts a plugin.
+	MagicCookieKey = "LEGO_PLUGIN_MAGIC_COOKIE"
+	// MagicCookieValue the value of the magic cookie.
+	MagicCookieValue = "7d1f3c0e5b9a4e26a8c1f4d2b6e0a9c3"
+	// SecretKey the environment variable holding the secret generated by lego for each start of a plugin.
+	// The secret is sent with each request (metadata SecretMetadataKey), the plugin rejects the requests without it.
+	SecretKey = "LEGO_PLUGIN_SECRET"
+	// SecretMetadataKey the gRPC metadata holding the secret.
+	SecretMetadataKey = "lego-plugin-secret"
+	// ProtocolVersion the version of the protocol between lego and a plugin.
+	ProtocolVersion = 1
+)
+
+// Environment variables names.
+const (
+	envNamespace = "PLUGIN_"
+
+	EnvStartTimeout   = envNamespace + "START_TIMEOUT"
+	EnvRequestTimeout = envNamespace + "REQUEST_TIMEOUT"
+
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+)
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	Path               string
+	StartTimeout       time.Duration
+	RequestTimeout     time.Duration
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return &Config{
+		StartTimeout:       env.GetOrDefaultSecond(EnvStartTimeout, 10*time.Second),
+		RequestTimeout:     env.GetOrDefaultSecond(EnvRequestTimeout, 2*time.Minute),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, dns01.DefaultPropagationTimeout),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, dns01.DefaultPollingInterval),
+	}
+}
+
+// DNSProvider delegates the DNS challenge to a plugin running in a child process.
+type DNSProvider struct {
+	config *Config
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	conn   *grpc.ClientConn
+	client *DNSProviderClient
+}
+
+// NewDNSProvider starts the plugin located at the given path.
+func NewDNSProvider(path string) (*DNSProvider, error) {
+	config := NewDefaultConfig()
+	config.Path = path
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig starts the plugin defined by the configuration.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("plugin: the configuration of the DNS provider is nil")
+	}
+
+	if config.Path == "" {
+		return nil, errors.New("plugin: the path of the plugin is missing")
+	}
+
+	d := &DNSProvider{config: config}
+
+	err := d.start()
+	if err != nil {
+		d.Close()
+		return nil, fmt.Errorf("plugin: %s: %w", config.Path, err)
+	}
+
+	return d, nil
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.RequestTimeout)
+	defer cancel()
+
+	_, err := d.client.Present(ctx, &ChallengeRequest{Domain: domain, Token: token, KeyAuth: keyAuth})
+	if err != nil {
+		return fmt.Errorf("plugin: %s", status.Convert(err).Message())
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.RequestTimeout)
+	defer cancel()
+
+	_, err := d.client.CleanUp(ctx, &ChallengeRequest{Domain: domain, Token: token, KeyAuth: keyAuth})
+	if err != nil {
+		return fmt.Errorf("plugin: %s", status.Convert(err).Message())
+	}
+
+	return nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// The values of the configuration are used if the plugin doesn't define them.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	timeout, interval = d.config.PropagationTimeout, d.config.PollingInterval
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.RequestTimeout)
+	defer cancel()
+
+	resp, err := d.client.Timeout(ctx, &Empty{})
+	if err != nil {
+		log.Warnf("plugin: unable to get the timeout: %s", status.Convert(err).Message())
+		return timeout, interval
+	}
+
+	if resp.TimeoutMs > 0 {
+		timeout = time.Duration(resp.TimeoutMs) * time.Millisecond
+	}
+	if resp.IntervalMs > 0 {
+		interval = time.Duration(resp.IntervalMs) * time.Millisecond
+	}
+
+	return timeout, interval
+}
+
+// Close stops the plugin.
+func (d *DNSProvider) Close() {
+	if d.conn != nil {
+		_ = d.conn.Close()
+	}
+
+	if d.stdin != nil {
+		// the plugin exits when its standard input is closed.
+		_ = d.stdin.Close()
+	}
+
+	if d.cmd == nil || d.cmd.Process == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_ = d.cmd.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		_ = d.cmd.Process.Kill()
+	}
+}
+
+func (d *DNSProvider) start() error {
+	secret, err := newSecret()
+	if err != nil {
+		return err
+	}
+
+	d.cmd = exec.Command(d.config.Path)
+	d.cmd.Env = append(os.Environ(), MagicCookieKey+"="+MagicCookieValue, SecretKey+"="+secret)
+	d.cmd.Stderr = os.Stderr
+
+	stdin, err := d.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	d.stdin = stdin
+
+	stdout, err := d.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	err = d.cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(stdout)
+
+	lines := make(chan string, 1)
+	errs := make(chan error, 1)
+	go func() {
+		line, errR := reader.ReadString('\n')
+		if errR != nil {
+			errs <- fmt.Errorf("unable to read the handshake: %w", errR)
+			return
+		}
+		lines <- line
+
+		// the remaining output of the plugin is only informative.
+		_, _ = io.Copy(os.Stderr, reader)
+	}()
+
+	var line string
+	select {
+	case line = <-lines:
+	case err = <-errs:
+		return err
+	case <-time.After(d.config.StartTimeout):
+		return fmt.Errorf("timeout while waiting for the handshake (%s)", d.config.StartTimeout)
+	}
+
+	network, address, err := parseHandshake(line)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.StartTimeout)
+	defer cancel()
+
+	d.conn, err = grpc.DialContext(ctx, address,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithPerRPCCredentials(secretCredentials(secret)),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s://%s: %w", network, address, err)
+	}
+
+	d.client = NewDNSProviderClient(d.conn)
+
+	return nil
+}
+
+// newSecret returns a random secret shared with a plugin.
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate the secret: %w", err)
+	}
+
+	return hex.EncodeToString(secret), nil
+}
+
+// secretCredentials sends the secret shared with a plugin with each request.
+type secretCredentials string
+
+func (c secretCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{SecretMetadataKey: string(c)}, nil
+}
+
+// RequireTransportSecurity the connection to the plugin is local.
+func (c secretCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// parseHandshake parses the handshake line written by a plugin: <protocol version>|<network>|<address>|grpc
+func parseHandshake(line string) (network, address string, err error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 4 {
+		return "", "", fmt.Errorf("invalid handshake: %q", line)
+	}
+
+	version, err := strconv.Atoi(parts[0])
+	if err != nil || version != ProtocolVersion {
+		return "", "", fmt.Errorf("unsupported protocol version: %q (expected %d)", parts[0], ProtocolVersion)
+	}
+
+	switch parts[1] {
+	case "tcp", "unix":
+	default:
+		return "", "", fmt.Errorf("unsupported network: %q", parts[1])
+	}
+
+	if parts[3] != "grpc" {
+		return "", "", fmt.Errorf("unsupported protocol: %q", parts[3])
+	}
+
+	return parts[1], parts[2], nil
+}
@@ -0,0 +1,190 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const envTestPlugin = "LEGO_TEST_PLUGIN"
+
+// TestMain runs the test binary as a plugin when it's started by the tests.
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestPlugin) == "true" {
+		err := Serve(&fakeProvider{})
+		if err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+type fakeProvider struct{}
+
+func (p *fakeProvider) Present(domain, _, _ string) error {
+	if domain == "error.example.com" {
+		return errors.New("present failed")
+	}
+	return nil
+}
+
+func (p *fakeProvider) CleanUp(domain, _, _ string) error {
+	if domain == "error.example.com" {
+		return errors.New("cleanup failed")
+	}
+	return nil
+}
+
+func (p *fakeProvider) Timeout() (timeout, interval time.Duration) {
+	return 3 * time.Minute, 5 * time.Second
+}
+
+func TestDNSProvider(t *testing.T) {
+	require.NoError(t, os.Setenv(envTestPlugin, "true"))
+	defer func() { _ = os.Unsetenv(envTestPlugin) }()
+
+	provider, err := NewDNSProvider(os.Args[0])
+	require.NoError(t, err)
+	defer provider.Close()
+
+	err = provider.Present("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	err = provider.CleanUp("example.com", "token", "keyAuth")
+	require.NoError(t, err)
+
+	err = provider.Present("error.example.com", "token", "keyAuth")
+	require.EqualError(t, err, "plugin: present failed")
+
+	err = provider.CleanUp("error.example.com", "token", "keyAuth")
+	require.EqualError(t, err, "plugin: cleanup failed")
+
+	timeout, interval := provider.Timeout()
+	assert.Equal(t, 3*time.Minute, timeout)
+	assert.Equal(t, 5*time.Second, interval)
+}
+
+func TestNewDNSProviderConfig_missingPath(t *testing.T) {
+	_, err := NewDNSProviderConfig(NewDefaultConfig())
+	require.EqualError(t, err, "plugin: the path of the plugin is missing")
+}
+
+func TestServe_withoutMagicCookie(t *testing.T) {
+	err := Serve(&fakeProvider{})
+	require.Error(t, err)
+}
+
+func Test_serve_invalidSecret(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() { done <- serve(&fakeProvider{}, "secret", stdinReader, stdoutWriter) }()
+
+	line, err := bufio.NewReader(stdoutReader).ReadString('\n')
+	require.NoError(t, err)
+
+	_, address, err := parseHandshake(line)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc    string
+		options []grpc.DialOption
+	}{
+		{
+			desc: "no secret",
+		},
+		{
+			desc:    "invalid secret",
+			options: []grpc.DialOption{grpc.WithPerRPCCredentials(secretCredentials("nope"))},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			conn, err := grpc.Dial(address, append(test.options, grpc.WithInsecure())...)
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+
+			client := NewDNSProviderClient(conn)
+
+			_, err = client.Present(context.Background(), &ChallengeRequest{Domain: "example.com"})
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+
+	require.NoError(t, stdinWriter.Close())
+	require.NoError(t, <-done)
+}
+
+func Test_parseHandshake(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		line            string
+		expectedNetwork string
+		expectedAddress string
+		expectedErr     string
+	}{
+		{
+			desc:            "tcp",
+			line:            "1|tcp|127.0.0.1:4242|grpc\n",
+			expectedNetwork: "tcp",
+			expectedAddress: "127.0.0.1:4242",
+		},
+		{
+			desc:            "unix",
+			line:            "1|unix|/tmp/plugin.sock|grpc\n",
+			expectedNetwork: "unix",
+			expectedAddress: "/tmp/plugin.sock",
+		},
+		{
+			desc:        "invalid",
+			line:        "hello\n",
+			expectedErr: `invalid handshake: "hello\n"`,
+		},
+		{
+			desc:        "unsupported version",
+			line:        "2|tcp|127.0.0.1:4242|grpc\n",
+			expectedErr: `unsupported protocol version: "2" (expected 1)`,
+		},
+		{
+			desc:        "unsupported network",
+			line:        "1|udp|127.0.0.1:4242|grpc\n",
+			expectedErr: `unsupported network: "udp"`,
+		},
+		{
+			desc:        "unsupported protocol",
+			line:        "1|tcp|127.0.0.1:4242|netrpc\n",
+			expectedErr: `unsupported protocol: "netrpc"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			network, address, err := parseHandshake(test.line)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedNetwork, network)
+			assert.Equal(t, test.expectedAddress, address)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// The messages and the service mirror the definitions of provider.proto.
+// The service is also used by the challenge agents (challenge/remote).
+
+const serviceName = "lego.plugin.v1.DNSProvider"
+
+// ChallengeRequest the parameters of a dns-01 challenge.
+type ChallengeRequest struct {
+	Domain  string `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Token   string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	KeyAuth string `protobuf:"bytes,3,opt,name=key_auth,json=keyAuth,proto3" json:"key_auth,omitempty"`
+}
+
+func (m *ChallengeRequest) Reset()         { *m = ChallengeRequest{} }
+func (m *ChallengeRequest) String() string { return proto.CompactTextString(m) }
+func (*ChallengeRequest) ProtoMessage()    {}
+
+// TimeoutResponse the timeout and interval to use when checking for DNS propagation.
+type TimeoutResponse struct {
+	TimeoutMs  int64 `protobuf:"varint,1,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	IntervalMs int64 `protobuf:"varint,2,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+}
+
+func (m *TimeoutResponse) Reset()         { *m = TimeoutResponse{} }
+func (m *TimeoutResponse) String() string { return proto.CompactTextString(m) }
+func (*TimeoutResponse) ProtoMessage()    {}
+
+// Empty an empty message.
+type Empty struct{}
+
+func (m *Empty) Reset()         { *m = Empty{} }
+func (m *Empty) String() string { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()    {}
+
+// DNSProviderServer the server side of the DNSProvider service.
+type DNSProviderServer interface {
+	Present(context.Context, *ChallengeRequest) (*Empty, error)
+	CleanUp(context.Context, *ChallengeRequest) (*Empty, error)
+	Timeout(context.Context, *Empty) (*TimeoutResponse, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*DNSProviderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Present",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(ChallengeRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return intercept(ctx, in, srv, "Present", interceptor, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(DNSProviderServer).Present(ctx, req.(*ChallengeRequest))
+				})
+			},
+		},
+		{
+			MethodName: "CleanUp",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(ChallengeRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return intercept(ctx, in, srv, "CleanUp", interceptor, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(DNSProviderServer).CleanUp(ctx, req.(*ChallengeRequest))
+				})
+			},
+		},
+		{
+			MethodName: "Timeout",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return intercept(ctx, in, srv, "Timeout", interceptor, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(DNSProviderServer).Timeout(ctx, req.(*Empty))
+				})
+			},
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "provider.proto",
+}
+
+// RegisterDNSProviderServer registers the implementation of the DNSProvider service on a gRPC server.
+func RegisterDNSProviderServer(s *grpc.Server, srv DNSProviderServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// intercept calls the handler of a method through the interceptor of the server, if any.
+func intercept(ctx context.Context, in, srv interface{}, method string, interceptor grpc.UnaryServerInterceptor, handler grpc.UnaryHandler) (interface{}, error) {
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/" + method}
+
+	return interceptor(ctx, in, info, handler)
+}
+
+// DNSProviderClient the client side of the DNSProvider service.
+type DNSProviderClient struct {
+	cc *grpc.ClientConn
+}
+
+// NewDNSProviderClient creates a client of the DNSProvider service.
+func NewDNSProviderClient(cc *grpc.ClientConn) *DNSProviderClient {
+	return &DNSProviderClient{cc: cc}
+}
+
+func (c *DNSProviderClient) Present(ctx context.Context, in *ChallengeRequest) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/Present", in, out)
+	return out, err
+}
+
+func (c *DNSProviderClient) CleanUp(ctx context.Context, in *ChallengeRequest) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/CleanUp", in, out)
+	return out, err
+}
+
+func (c *DNSProviderClient) Timeout(ctx context.Context, in *Empty) (*TimeoutResponse, error) {
+	out := new(TimeoutResponse)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/Timeout", in, out)
+	return out, err
+}
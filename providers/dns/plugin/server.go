@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+
+	"github.com/go-acme/lego/v3/challenge"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Serve serves the provider as a plugin.
+// It must be called by the main function of the plugin program, and it returns when lego stops the plugin.
+func Serve(provider challenge.Provider) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return errors.New("this program is a lego DNS plugin, it must be started by lego: --dns plugin:<path>")
+	}
+
+	secret := os.Getenv(SecretKey)
+	if secret == "" {
+		return errors.New("the secret shared with lego is missing, lego must be upgraded")
+	}
+
+	// the secret is not passed to the programs started by the plugin.
+	_ = os.Unsetenv(SecretKey)
+
+	return serve(provider, secret, os.Stdin, os.Stdout)
+}
+
+func serve(provider challenge.Provider, secret string, stdin io.Reader, stdout io.Writer) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+
+	// the address is reachable by any local user: only the requests holding the secret of lego are served.
+	server := grpc.NewServer(grpc.UnaryInterceptor(checkSecret(secret)))
+	RegisterDNSProviderServer(server, &providerServer{provider: provider})
+
+	_, err = fmt.Fprintf(stdout, "%d|%s|%s|grpc\n", ProtocolVersion, listener.Addr().Network(), listener.Addr().String())
+	if err != nil {
+		return err
+	}
+
+	// lego closes the standard input of the plugin to stop it.
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, stdin)
+		server.Stop()
+	}()
+
+	return server.Serve(listener)
+}
+
+// checkSecret rejects the requests without the secret shared with lego.
+func checkSecret(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		values := md.Get(SecretMetadataKey)
+		if len(values) != 1 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(secret)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid secret")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// providerServer implements the DNSProvider service on top of a challenge.Provider.
+type providerServer struct {
+	provider challenge.Provider
+}
+
+func (s *providerServer) Present(_ context.Context, req *ChallengeRequest) (*Empty, error) {
+	err := s.provider.Present(req.Domain, req.Token, req.KeyAuth)
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+func (s *providerServer) CleanUp(_ context.Context, req *ChallengeRequest) (*Empty, error) {
+	err := s.provider.CleanUp(req.Domain, req.Token, req.KeyAuth)
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+func (s *providerServer) Timeout(_ context.Context, _ *Empty) (*TimeoutResponse, error) {
+	p, ok := s.provider.(challenge.ProviderTimeout)
+	if !ok {
+		return &TimeoutResponse{}, nil
+	}
+
+	timeout, interval := p.Timeout()
+
+	return &TimeoutResponse{
+		TimeoutMs:  timeout.Milliseconds(),
+		IntervalMs: interval.Milliseconds(),
+	}, nil
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-acme/lego/v3/log"
+)
+
+const defaultBaseURL = "https://porkbun.com/api/json/v3"
+
+const statusSuccess = "SUCCESS"
+
+// Client a Porkbun API client.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+
+	// MaxElapsedTime the maximum time spent retrying a rate limited request.
+	MaxElapsedTime time.Duration
+
+	apiKey       string
+	secretAPIKey string
+}
+
+// NewClient creates a new Client.
+func NewClient(apiKey, secretAPIKey string) *Client {
+	return &Client{
+		HTTPClient:     &http.Client{Timeout: 30 * time.Second},
+		BaseURL:        defaultBaseURL,
+		MaxElapsedTime: 2 * time.Minute,
+		apiKey:         apiKey,
+		secretAPIKey:   secretAPIKey,
+	}
+}
+
+// CreateRecord creates a DNS record and returns its ID.
+// https://porkbun.com/api/json/v3/documentation#DNS%20Create%20Record
+func (c *Client) CreateRecord(domain string, record Record) (string, error) {
+	endpoint, err := c.createEndpoint("dns", "create", domain)
+	if err != nil {
+		return "", err
+	}
+
+	var resp createResponse
+	err = c.do(endpoint, &record, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(resp.ID, 10), nil
+}
+
+// RetrieveRecords returns all the DNS records of a domain.
+// https://porkbun.com/api/json/v3/documentation#DNS%20Retrieve%20Records
+func (c *Client) RetrieveRecords(domain string) ([]Record, error) {
+	endpoint, err := c.createEndpoint("dns", "retrieve", domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp retrieveResponse
+	err = c.do(endpoint, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Records, nil
+}
+
+// DeleteRecord deletes a DNS record.
+// https://porkbun.com/api/json/v3/documentation#DNS%20Delete%20Record%20by%20Domain%20and%20ID
+func (c *Client) DeleteRecord(domain, id string) error {
+	endpoint, err := c.createEndpoint("dns", "delete", domain, id)
+	if err != nil {
+		return err
+	}
+
+	return c.do(endpoint, nil, nil)
+}
+
+// statusError an HTTP status error.
+type statusError struct {
+	code int
+	body string
+}
+
+func (s statusError) Error() string {
+	return fmt.Sprintf("%d: %s", s.code, s.body)
+}
+
+// do sends an authenticated request.
+// Porkbun aggressively rate limits its API: the rate limited requests are retried with an exponential backoff.
+func (c *Client) do(endpoint *url.URL, record *Record, result interface{}) error {
+	body, err := json.Marshal(authRequest{APIKey: c.apiKey, SecretAPIKey: c.secretAPIKey, Record: record})
+	if err != nil {
+		return err
+	}
+
+	var raw []byte
+
+	operation := func() error {
+		req, err := http.NewRequest(http.MethodPost, endpoint.String(), bytes.NewReader(body))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		defer func() { _ = resp.Body.Close() }()
+
+		raw, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("failed to read response body: %w", err))
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			return statusError{code: resp.StatusCode, body: string(raw)}
+		}
+
+		return nil
+	}
+
+	notify := func(err error, duration time.Duration) {
+		log.Infof("porkbun: rate limited (%v), retrying in %s", err, duration)
+	}
+
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = 1 * time.Second
+	bo.MaxElapsedTime = c.MaxElapsedTime
+
+	err = backoff.RetryNotify(operation, backoff.WithContext(bo, context.Background()), notify)
+	if err != nil {
+		return err
+	}
+
+	var status Status
+	err = json.Unmarshal(raw, &status)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w: %s", err, string(raw))
+	}
+
+	if status.Status != statusSuccess {
+		return status
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(raw, result)
+}
+
+func (c *Client) createEndpoint(fragments ...string) (*url.URL, error) {
+	baseURL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return baseURL.Parse(path.Join(baseURL.Path, path.Join(fragments...)))
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T, pattern string, handler func(rw http.ResponseWriter, body map[string]string)) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc(pattern, func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body := map[string]string{}
+		err := json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if body["apikey"] != "key" || body["secretapikey"] != "secret" {
+			_, _ = fmt.Fprint(rw, `{"status":"ERROR","message":"Invalid API key. (002)"}`)
+			return
+		}
+
+		handler(rw, body)
+	})
+
+	client := NewClient("key", "secret")
+	client.BaseURL = server.URL
+	client.MaxElapsedTime = 5 * time.Second
+
+	return client
+}
+
+func TestClient_CreateRecord(t *testing.T) {
+	client := setupTest(t, "/dns/create/example.com", func(rw http.ResponseWriter, body map[string]string) {
+		if body["name"] != "_acme-challenge" || body["type"] != "TXT" || body["content"] != "txtxtxt" || body["ttl"] != "600" {
+			_, _ = fmt.Fprintf(rw, `{"status":"ERROR","message":"unexpected body: %v"}`, body)
+			return
+		}
+		_, _ = fmt.Fprint(rw, `{"status":"SUCCESS","id":106926659}`)
+	})
+
+	id, err := client.CreateRecord("example.com", Record{Name: "_acme-challenge", Type: "TXT", Content: "txtxtxt", TTL: "600"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "106926659", id)
+}
+
+func TestClient_RetrieveRecords(t *testing.T) {
+	client := setupTest(t, "/dns/retrieve/example.com", func(rw http.ResponseWriter, _ map[string]string) {
+		_, _ = fmt.Fprint(rw, `{"status":"SUCCESS","records":[{"id":"106926652","name":"_acme-challenge.example.com","type":"TXT","content":"txtxtxt","ttl":"600","prio":"0","notes":""}]}`)
+	})
+
+	records, err := client.RetrieveRecords("example.com")
+	require.NoError(t, err)
+
+	expected := []Record{{ID: "106926652", Name: "_acme-challenge.example.com", Type: "TXT", Content: "txtxtxt", TTL: "600", Prio: "0"}}
+	assert.Equal(t, expected, records)
+}
+
+func TestClient_DeleteRecord(t *testing.T) {
+	client := setupTest(t, "/dns/delete/example.com/106926652", func(rw http.ResponseWriter, _ map[string]string) {
+		_, _ = fmt.Fprint(rw, `{"status":"SUCCESS"}`)
+	})
+
+	err := client.DeleteRecord("example.com", "106926652")
+	require.NoError(t, err)
+}
+
+func TestClient_error(t *testing.T) {
+	client := setupTest(t, "/dns/retrieve/example.com", nil)
+	client.secretAPIKey = "invalid"
+
+	_, err := client.RetrieveRecords("example.com")
+	require.EqualError(t, err, "ERROR: Invalid API key. (002)")
+}
+
+func TestClient_rateLimit(t *testing.T) {
+	var calls int
+	client := setupTest(t, "/dns/delete/example.com/106926652", func(rw http.ResponseWriter, _ map[string]string) {
+		calls++
+		if calls < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(rw, `{"status":"SUCCESS"}`)
+	})
+
+	err := client.DeleteRecord("example.com", "106926652")
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, calls)
+}
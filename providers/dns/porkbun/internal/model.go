@@ -0,0 +1,43 @@
+package internal
+
+import "fmt"
+
+// Record a DNS record.
+type Record struct {
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Content string `json:"content,omitempty"`
+	TTL     string `json:"ttl,omitempty"`
+	Prio    string `json:"prio,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+// Status the common part of all the responses.
+type Status struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+func (s Status) Error() string {
+	return fmt.Sprintf("%s: %s", s.Status, s.Message)
+}
+
+type authRequest struct {
+	APIKey       string `json:"apikey"`
+	SecretAPIKey string `json:"secretapikey"`
+
+	*Record
+}
+
+type createResponse struct {
+	Status
+
+	ID int64 `json:"id"`
+}
+
+type retrieveResponse struct {
+	Status
+
+	Records []Record `json:"records"`
+}
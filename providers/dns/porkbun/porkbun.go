@@ -0,0 +1,184 @@
+// Package porkbun implements a DNS provider for solving the DNS-01 challenge using Porkbun.
+package porkbun
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/platform/config/env"
+	"github.com/go-acme/lego/v3/platform/httpclient"
+	"github.com/go-acme/lego/v3/providers/dns/porkbun/internal"
+)
+
+const minTTL = 600
+
+// Environment variables names.
+const (
+	envNamespace = "PORKBUN_"
+
+	EnvAPIKey       = envNamespace + "API_KEY"
+	EnvSecretAPIKey = envNamespace + "SECRET_API_KEY"
+
+	EnvTTL                = envNamespace + "TTL"
+	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
+	EnvPollingInterval    = envNamespace + "POLLING_INTERVAL"
+	EnvHTTPTimeout        = envNamespace + "HTTP_TIMEOUT"
+)
+
+// Config is used to configure the creation of the DNSProvider.
+type Config struct {
+	APIKey             string
+	SecretAPIKey       string
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+	TTL                int
+	HTTPClient         *http.Client
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider.
+func NewDefaultConfig() *Config {
+	return &Config{
+		TTL:                env.GetOrDefaultInt(EnvTTL, minTTL),
+		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 10*time.Minute),
+		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 10*time.Second),
+		HTTPClient:         httpclient.New(env.GetOrDefaultSecond(EnvHTTPTimeout, 30*time.Second)),
+	}
+}
+
+// DNSProvider is an implementation of the challenge.Provider interface.
+type DNSProvider struct {
+	config *Config
+	client *internal.Client
+
+	recordIDs   map[string]string
+	recordIDsMu sync.Mutex
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for Porkbun.
+// Credentials must be passed in the environment variables: PORKBUN_API_KEY and PORKBUN_SECRET_API_KEY.
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get(EnvAPIKey, EnvSecretAPIKey)
+	if err != nil {
+		return nil, fmt.Errorf("porkbun: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.APIKey = values[EnvAPIKey]
+	config.SecretAPIKey = values[EnvSecretAPIKey]
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for Porkbun.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("porkbun: the configuration of the DNS provider is nil")
+	}
+
+	if config.APIKey == "" || config.SecretAPIKey == "" {
+		return nil, errors.New("porkbun: some credentials information are missing")
+	}
+
+	if config.TTL < minTTL {
+		return nil, fmt.Errorf("porkbun: invalid TTL, TTL (%d) must be greater than %d", config.TTL, minTTL)
+	}
+
+	client := internal.NewClient(config.APIKey, config.SecretAPIKey)
+
+	if config.HTTPClient != nil {
+		client.HTTPClient = config.HTTPClient
+	}
+
+	return &DNSProvider{
+		config:    config,
+		client:    client,
+		recordIDs: make(map[string]string),
+	}, nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+// Adjusting here to cope with spikes in propagation times.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
+
+	zone, err := getZone(fqdn)
+	if err != nil {
+		return fmt.Errorf("porkbun: failed to find zone: fqdn=%s: %w", fqdn, err)
+	}
+
+	record := internal.Record{
+		Name:    extractRecordName(fqdn, zone),
+		Type:    "TXT",
+		Content: value,
+		TTL:     strconv.Itoa(d.config.TTL),
+	}
+
+	recordID, err := d.client.CreateRecord(zone, record)
+	if err != nil {
+		return fmt.Errorf("porkbun: failed to create record: fqdn=%s: %w", fqdn, err)
+	}
+
+	d.recordIDsMu.Lock()
+	d.recordIDs[token] = recordID
+	d.recordIDsMu.Unlock()
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+
+	// gets the record's unique ID from when we created it
+	d.recordIDsMu.Lock()
+	recordID, ok := d.recordIDs[token]
+	d.recordIDsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("porkbun: unknown record ID for '%s'", fqdn)
+	}
+
+	zone, err := getZone(fqdn)
+	if err != nil {
+		return fmt.Errorf("porkbun: failed to find zone: fqdn=%s: %w", fqdn, err)
+	}
+
+	err = d.client.DeleteRecord(zone, recordID)
+	if err != nil {
+		return fmt.Errorf("porkbun: failed to delete record: fqdn=%s, recordID=%s: %w", fqdn, recordID, err)
+	}
+
+	// deletes record ID from map
+	d.recordIDsMu.Lock()
+	delete(d.recordIDs, token)
+	d.recordIDsMu.Unlock()
+
+	return nil
+}
+
+func extractRecordName(fqdn, zone string) string {
+	name := dns01.UnFqdn(fqdn)
+	if idx := strings.Index(name, "."+zone); idx != -1 {
+		return name[:idx]
+	}
+	return name
+}
+
+func getZone(fqdn string) (string, error) {
+	authZone, err := dns01.FindZoneByFqdn(fqdn)
+	if err != nil {
+		return "", err
+	}
+
+	return dns01.UnFqdn(authZone), nil
+}
@@ -0,0 +1,148 @@
+package porkbun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v3/platform/tester"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envDomain = envNamespace + "DOMAIN"
+
+var envTest = tester.NewEnvTest(EnvAPIKey, EnvSecretAPIKey).WithDomain(envDomain)
+
+func TestNewDNSProvider(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		envVars  map[string]string
+		expected string
+	}{
+		{
+			desc: "success",
+			envVars: map[string]string{
+				EnvAPIKey:       "key",
+				EnvSecretAPIKey: "secret",
+			},
+		},
+		{
+			desc: "missing credentials",
+			envVars: map[string]string{
+				EnvAPIKey:       "",
+				EnvSecretAPIKey: "",
+			},
+			expected: "porkbun: some credentials information are missing: PORKBUN_API_KEY,PORKBUN_SECRET_API_KEY",
+		},
+		{
+			desc: "missing secret API key",
+			envVars: map[string]string{
+				EnvAPIKey:       "key",
+				EnvSecretAPIKey: "",
+			},
+			expected: "porkbun: some credentials information are missing: PORKBUN_SECRET_API_KEY",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			defer envTest.RestoreEnv()
+			envTest.ClearEnv()
+
+			envTest.Apply(test.envVars)
+
+			p, err := NewDNSProvider()
+
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewDNSProviderConfig(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		apiKey       string
+		secretAPIKey string
+		ttl          int
+		expected     string
+	}{
+		{
+			desc:         "success",
+			apiKey:       "key",
+			secretAPIKey: "secret",
+			ttl:          minTTL,
+		},
+		{
+			desc:     "missing credentials",
+			ttl:      minTTL,
+			expected: "porkbun: some credentials information are missing",
+		},
+		{
+			desc:         "invalid TTL",
+			apiKey:       "key",
+			secretAPIKey: "secret",
+			ttl:          60,
+			expected:     "porkbun: invalid TTL, TTL (60) must be greater than 600",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.APIKey = test.apiKey
+			config.SecretAPIKey = test.secretAPIKey
+			config.TTL = test.ttl
+
+			p, err := NewDNSProviderConfig(config)
+
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.NotNil(t, p.config)
+				require.NotNil(t, p.client)
+			} else {
+				require.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func Test_extractRecordName(t *testing.T) {
+	assert.Equal(t, "_acme-challenge", extractRecordName("_acme-challenge.example.com.", "example.com"))
+	assert.Equal(t, "_acme-challenge.sub", extractRecordName("_acme-challenge.sub.example.com.", "example.com"))
+}
+
+func TestLivePresent(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	err = provider.Present(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
+
+func TestLiveCleanUp(t *testing.T) {
+	if !envTest.IsLiveTest() {
+		t.Skip("skipping live test")
+	}
+
+	envTest.RestoreEnv()
+	provider, err := NewDNSProvider()
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	err = provider.CleanUp(envTest.GetDomain(), "", "123d==")
+	require.NoError(t, err)
+}
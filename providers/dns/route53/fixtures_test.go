@@ -37,3 +37,24 @@ const GetChangeResponse = `<?xml version="1.0" encoding="UTF-8"?>
       <SubmittedAt>2016-02-10T01:36:41.958Z</SubmittedAt>
    </ChangeInfo>
 </GetChangeResponse>`
+
+const ListResourceRecordSetsResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<ListResourceRecordSetsResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+   <ResourceRecordSets>
+      <ResourceRecordSet>
+         <Name>_acme-challenge.example.com.</Name>
+         <Type>TXT</Type>
+         <TTL>10</TTL>
+         <ResourceRecords>
+            <ResourceRecord>
+               <Value>"verification of the user"</Value>
+            </ResourceRecord>
+            <ResourceRecord>
+               <Value>"O2UTPYgIzRNt5N27EVcNKDxv6goSF7ru3zi3chZXKUw"</Value>
+            </ResourceRecord>
+         </ResourceRecords>
+      </ResourceRecordSet>
+   </ResourceRecordSets>
+   <IsTruncated>false</IsTruncated>
+   <MaxItems>100</MaxItems>
+</ListResourceRecordSetsResponse>`
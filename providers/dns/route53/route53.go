@@ -11,9 +11,9 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/request"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/go-acme/lego/v3/challenge/dns01"
+	"github.com/go-acme/lego/v3/platform/cloudcreds"
 	"github.com/go-acme/lego/v3/platform/config/env"
 	"github.com/go-acme/lego/v3/platform/wait"
 )
@@ -27,6 +27,8 @@ const (
 	EnvRegion          = envNamespace + "REGION"
 	EnvHostedZoneID    = envNamespace + "HOSTED_ZONE_ID"
 	EnvMaxRetries      = envNamespace + "MAX_RETRIES"
+	EnvAssumeRoleArn   = envNamespace + "ASSUME_ROLE_ARN"
+	EnvExternalID      = envNamespace + "EXTERNAL_ID"
 
 	EnvTTL                = envNamespace + "TTL"
 	EnvPropagationTimeout = envNamespace + "PROPAGATION_TIMEOUT"
@@ -40,6 +42,8 @@ type Config struct {
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 	HostedZoneID       string
+	AssumeRoleArn      string
+	ExternalID         string
 	Client             *route53.Route53
 }
 
@@ -51,6 +55,8 @@ func NewDefaultConfig() *Config {
 		PropagationTimeout: env.GetOrDefaultSecond(EnvPropagationTimeout, 2*time.Minute),
 		PollingInterval:    env.GetOrDefaultSecond(EnvPollingInterval, 4*time.Second),
 		HostedZoneID:       env.GetOrFile(EnvHostedZoneID),
+		AssumeRoleArn:      env.GetOrFile(EnvAssumeRoleArn),
+		ExternalID:         env.GetOrFile(EnvExternalID),
 	}
 }
 
@@ -87,7 +93,10 @@ func (d customRetryer) RetryRules(r *request.Request) time.Duration {
 // 1. Environment variables: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
 //    AWS_REGION, [AWS_SESSION_TOKEN]
 // 2. Shared credentials file (defaults to ~/.aws/credentials)
-// 3. Amazon EC2 IAM role
+// 3. Web identity token: AWS_WEB_IDENTITY_TOKEN_FILE, AWS_ROLE_ARN
+// 4. Amazon EC2 IAM role
+//
+// If AWS_ASSUME_ROLE_ARN is set, the role is assumed with these credentials.
 //
 // If AWS_HOSTED_ZONE_ID is not set, Lego tries to determine the correct public hosted zone via the FQDN.
 //
@@ -110,7 +119,12 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 	retry.NumMaxRetries = config.MaxRetries
 	sessionCfg := request.WithRetryer(aws.NewConfig(), retry)
 
-	sess, err := session.NewSessionWithOptions(session.Options{Config: *sessionCfg})
+	credsConfig := cloudcreds.AWSConfig{
+		AssumeRoleARN: config.AssumeRoleArn,
+		ExternalID:    config.ExternalID,
+	}
+
+	sess, err := cloudcreds.NewAWSSession(credsConfig, sessionCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -166,9 +180,11 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	return nil
 }
 
-// CleanUp removes the TXT record matching the specified parameters
+// CleanUp removes the TXT record matching the specified parameters.
+// Route 53 has no comments on the records: only the value of the challenge is removed,
+// the other values of the record set (e.g. created by the user) are kept.
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
-	fqdn, _ := dns01.GetRecord(domain, keyAuth)
+	fqdn, value := dns01.GetRecord(domain, keyAuth)
 
 	hostedZoneID, err := d.getHostedZoneID(fqdn)
 	if err != nil {
@@ -180,18 +196,36 @@ func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 		return fmt.Errorf("route53: %w", err)
 	}
 
-	if len(records) == 0 {
+	realValue := `"` + value + `"`
+
+	var found bool
+	var others []*route53.ResourceRecord
+	for _, record := range records {
+		if aws.StringValue(record.Value) == realValue {
+			found = true
+			continue
+		}
+		others = append(others, record)
+	}
+
+	if !found {
 		return nil
 	}
 
+	action := route53.ChangeActionUpsert
+	if len(others) == 0 {
+		action = route53.ChangeActionDelete
+		others = []*route53.ResourceRecord{{Value: aws.String(realValue)}}
+	}
+
 	recordSet := &route53.ResourceRecordSet{
 		Name:            aws.String(fqdn),
 		Type:            aws.String("TXT"),
 		TTL:             aws.Int64(int64(d.config.TTL)),
-		ResourceRecords: records,
+		ResourceRecords: others,
 	}
 
-	err = d.changeRecord(route53.ChangeActionDelete, hostedZoneID, recordSet)
+	err = d.changeRecord(action, hostedZoneID, recordSet)
 	if err != nil {
 		return fmt.Errorf("route53: %w", err)
 	}
@@ -202,7 +236,7 @@ func (d *DNSProvider) changeRecord(action, hostedZoneID string, recordSet *route
 	recordSetInput := &route53.ChangeResourceRecordSetsInput{
 		HostedZoneId: aws.String(hostedZoneID),
 		ChangeBatch: &route53.ChangeBatch{
-			Comment: aws.String("Managed by Lego"),
+			Comment: aws.String(dns01.OwnershipComment(time.Now())),
 			Changes: []*route53.Change{{
 				Action:            aws.String(action),
 				ResourceRecordSet: recordSet,
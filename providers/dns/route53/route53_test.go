@@ -1,6 +1,8 @@
 package route53
 
 import (
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
@@ -23,6 +25,8 @@ var envTest = tester.NewEnvTest(
 	EnvRegion,
 	EnvHostedZoneID,
 	EnvMaxRetries,
+	EnvAssumeRoleArn,
+	EnvExternalID,
 	EnvTTL,
 	EnvPropagationTimeout,
 	EnvPollingInterval).
@@ -177,3 +181,36 @@ func TestDNSProvider_Present(t *testing.T) {
 	err := provider.Present(domain, "", keyAuth)
 	require.NoError(t, err, "Expected Present to return no error")
 }
+
+func TestDNSProvider_CleanUp_keepsOtherValues(t *testing.T) {
+	var change string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/xml")
+
+		switch {
+		case req.URL.Path == "/2013-04-01/change/123456":
+			_, _ = rw.Write([]byte(GetChangeResponse))
+		case req.Method == http.MethodPost:
+			body, _ := ioutil.ReadAll(req.Body)
+			change = string(body)
+			_, _ = rw.Write([]byte(ChangeResourceRecordSetsResponse))
+		default:
+			_, _ = rw.Write([]byte(ListResourceRecordSetsResponse))
+		}
+	}))
+	defer ts.Close()
+
+	defer envTest.RestoreEnv()
+	envTest.ClearEnv()
+	provider := makeTestProvider(ts)
+	provider.config.HostedZoneID = "ABCDEFG"
+
+	err := provider.CleanUp("example.com", "", "123456d==")
+	require.NoError(t, err)
+
+	assert.Contains(t, change, "<Action>UPSERT</Action>")
+	assert.Contains(t, change, "verification of the user")
+	assert.NotContains(t, change, "O2UTPYgIzRNt5N27EVcNKDxv6goSF7ru3zi3chZXKUw")
+	assert.Contains(t, change, "<Comment>lego-acme-challenge created=")
+}
@@ -0,0 +1,140 @@
+// Package agent implements a signing agent holding the account key in a separate process (like ssh-agent),
+// so the account key never exists in the memory of the ACME client.
+//
+// The agent listens on a Unix socket and uses JSON-RPC (net/rpc/jsonrpc).
+package agent
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+
+	"github.com/go-acme/lego/v3/certcrypto"
+)
+
+const serviceName = "Agent"
+
+// PublicKeyArgs the arguments of Agent.PublicKey.
+type PublicKeyArgs struct{}
+
+// PublicKeyReply the reply of Agent.PublicKey.
+type PublicKeyReply struct {
+	// Key the public key, DER encoded (PKIX).
+	Key []byte
+}
+
+// SignArgs the arguments of Agent.Sign.
+type SignArgs struct {
+	Digest []byte
+	Hash   crypto.Hash
+}
+
+// SignReply the reply of Agent.Sign.
+type SignReply struct {
+	Signature []byte
+}
+
+// Serve serves the signer on the listener, until the listener is closed.
+func Serve(listener net.Listener, signer crypto.Signer) error {
+	server := rpc.NewServer()
+
+	err := server.RegisterName(serviceName, &service{signer: signer})
+	if err != nil {
+		return err
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+type service struct {
+	signer crypto.Signer
+}
+
+func (s *service) PublicKey(_ PublicKeyArgs, reply *PublicKeyReply) error {
+	key, err := x509.MarshalPKIXPublicKey(s.signer.Public())
+	if err != nil {
+		return err
+	}
+
+	reply.Key = key
+	return nil
+}
+
+func (s *service) Sign(args SignArgs, reply *SignReply) error {
+	signature, err := s.signer.Sign(certcrypto.Reader, args.Digest, args.Hash)
+	if err != nil {
+		return err
+	}
+
+	reply.Signature = signature
+	return nil
+}
+
+// Signer a crypto.Signer delegating the signatures to an agent.
+type Signer struct {
+	client *rpc.Client
+	public crypto.PublicKey
+}
+
+// Dial connects to the agent listening on the Unix socket.
+func Dial(socketPath string) (*Signer, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("agent: %w", err)
+	}
+
+	client := jsonrpc.NewClient(conn)
+
+	var reply PublicKeyReply
+	err = client.Call(serviceName+".PublicKey", PublicKeyArgs{}, &reply)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("agent: failed to get the public key: %w", err)
+	}
+
+	public, err := x509.ParsePKIXPublicKey(reply.Key)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("agent: failed to parse the public key: %w", err)
+	}
+
+	return &Signer{client: client, public: public}, nil
+}
+
+// Public returns the public key of the account key.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign signs the digest with the account key held by the agent.
+func (s *Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if _, ok := opts.(*rsa.PSSOptions); ok {
+		return nil, errors.New("agent: RSA-PSS signatures are not supported")
+	}
+
+	var reply SignReply
+	err := s.client.Call(serviceName+".Sign", SignArgs{Digest: digest, Hash: opts.HashFunc()}, &reply)
+	if err != nil {
+		return nil, fmt.Errorf("agent: failed to sign: %w", err)
+	}
+
+	return reply.Signature, nil
+}
+
+// Close closes the connection to the agent.
+func (s *Signer) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lego-agent")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	socketPath := filepath.Join(dir, "agent.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	go func() { _ = Serve(listener, privateKey) }()
+
+	signer, err := Dial(socketPath)
+	require.NoError(t, err)
+	defer func() { _ = signer.Close() }()
+
+	assert.Equal(t, privateKey.Public(), signer.Public())
+
+	digest := sha256.Sum256([]byte("lego"))
+
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+
+	var sig struct{ R, S *big.Int }
+	_, err = asn1.Unmarshal(signature, &sig)
+	require.NoError(t, err)
+
+	assert.True(t, ecdsa.Verify(&privateKey.PublicKey, digest[:], sig.R, sig.S))
+}
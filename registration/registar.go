@@ -1,6 +1,7 @@
 package registration
 
 import (
+	"crypto"
 	"errors"
 	"net/http"
 
@@ -9,6 +10,9 @@ import (
 	"github.com/go-acme/lego/v3/log"
 )
 
+// ErrAccountDoesNotExist is returned by ResolveAccountByKey when no account is registered with the key.
+var ErrAccountDoesNotExist = errors.New("acme: no account is registered with this key")
+
 // Resource represents all important information about a registration
 // of which the client needs to keep track itself.
 // WARNING: will be remove in the future (acme.ExtendedAccount), https://github.com/go-acme/lego/issues/855.
@@ -139,6 +143,100 @@ func (r *Registrar) UpdateRegistration(options RegisterOptions) (*Resource, erro
 	return &Resource{URI: account.Location, Body: account.Account}, nil
 }
 
+// UpdateContact updates the contact of the user registration on the ACME server.
+// The emails are sent as mailto URIs, some CAs only accept one email.
+func (r *Registrar) UpdateContact(emails []string) (*Resource, error) {
+	if r == nil || r.user == nil {
+		return nil, errors.New("acme: cannot update a nil client or user")
+	}
+
+	if len(emails) == 0 {
+		return nil, errors.New("acme: at least one email is required to update the contact")
+	}
+
+	accMsg := acme.Account{}
+	for _, email := range emails {
+		accMsg.Contact = append(accMsg.Contact, "mailto:"+email)
+	}
+
+	log.Infof("acme: Updating account contact for %s", r.user.GetRegistration().URI)
+
+	account, err := r.core.Accounts.Update(r.user.GetRegistration().URI, accMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	// the Location header is not returned by an account update.
+	return &Resource{URI: r.user.GetRegistration().URI, Body: account.Account}, nil
+}
+
+// AgreeToTermsOfService agrees to the current terms of service of the CA for the user registration.
+// The contact of the registration is not modified.
+func (r *Registrar) AgreeToTermsOfService() (*Resource, error) {
+	if r == nil || r.user == nil {
+		return nil, errors.New("acme: cannot update a nil client or user")
+	}
+
+	log.Infof("acme: Agreeing to the terms of service for %s", r.user.GetRegistration().URI)
+
+	account, err := r.core.Accounts.Update(r.user.GetRegistration().URI, acme.Account{TermsOfServiceAgreed: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// the Location header is not returned by an account update.
+	return &Resource{URI: r.user.GetRegistration().URI, Body: account.Account}, nil
+}
+
+// ChangeKey changes the key of the user registration (account key rollover), e.g. to move to another key type.
+// The new key is used by the following requests of the client: the user must be updated with the new key.
+func (r *Registrar) ChangeKey(newKey crypto.PrivateKey) error {
+	if r == nil || r.user == nil {
+		return errors.New("acme: cannot update a nil client or user")
+	}
+
+	log.Infof("acme: Changing the account key of %s", r.user.GetRegistration().URI)
+
+	return r.core.Accounts.KeyChange(newKey)
+}
+
+// ListOrders lists the URLs of the orders of the user registration.
+// The CA should include the pending orders, and may omit the other orders.
+func (r *Registrar) ListOrders() ([]string, error) {
+	if r == nil || r.user == nil || r.user.GetRegistration() == nil {
+		return nil, errors.New("acme: cannot list the orders of a nil client or user")
+	}
+
+	account, err := r.core.Accounts.Get(r.user.GetRegistration().URI)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Orders == "" {
+		return nil, errors.New("acme: the CA doesn't provide the orders of the accounts")
+	}
+
+	return r.core.Orders.List(account.Orders)
+}
+
+// GetOrder fetches an order of the user registration.
+func (r *Registrar) GetOrder(orderURL string) (acme.Order, error) {
+	if r == nil || r.user == nil {
+		return acme.Order{}, errors.New("acme: cannot get an order of a nil client or user")
+	}
+
+	return r.core.Orders.Get(orderURL)
+}
+
+// GetAuthorization fetches an authorization of the user registration (e.g. an authorization of an order).
+func (r *Registrar) GetAuthorization(authzURL string) (acme.Authorization, error) {
+	if r == nil || r.user == nil {
+		return acme.Authorization{}, errors.New("acme: cannot get an authorization of a nil client or user")
+	}
+
+	return r.core.Authorizations.Get(authzURL)
+}
+
 // DeleteRegistration deletes the client's user registration from the ACME server.
 func (r *Registrar) DeleteRegistration() error {
 	if r == nil || r.user == nil {
@@ -152,12 +250,17 @@ func (r *Registrar) DeleteRegistration() error {
 
 // ResolveAccountByKey will attempt to look up an account using the given account key
 // and return its registration resource.
+// No account is created (onlyReturnExisting): ErrAccountDoesNotExist is returned if the key is unknown to the CA.
 func (r *Registrar) ResolveAccountByKey() (*Resource, error) {
 	log.Infof("acme: Trying to resolve account by key")
 
 	accMsg := acme.Account{OnlyReturnExisting: true}
 	accountTransit, err := r.core.Accounts.New(accMsg)
 	if err != nil {
+		var problem *acme.ProblemDetails
+		if errors.As(err, &problem) && problem.Type == acme.AccountDoesNotExistErr {
+			return nil, ErrAccountDoesNotExist
+		}
 		return nil, err
 	}
 
@@ -3,6 +3,8 @@ package registration
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"testing"
 
@@ -54,3 +56,125 @@ func TestRegistrar_ResolveAccountByKey(t *testing.T) {
 
 	assert.Equal(t, "valid", res.Body.Status, "Unexpected account status")
 }
+
+func TestRegistrar_ResolveAccountByKey_doesNotExist(t *testing.T) {
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	mux.HandleFunc("/account", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(acme.ProblemDetails{
+			Type:       acme.AccountDoesNotExistErr,
+			Detail:     "No account exists with the provided key",
+			HTTPStatus: http.StatusBadRequest,
+		})
+	})
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", "", key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	_, err = registrar.ResolveAccountByKey()
+	require.Equal(t, ErrAccountDoesNotExist, err)
+}
+
+func TestRegistrar_UpdateContact(t *testing.T) {
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	mux.HandleFunc("/account/1", echoAccount)
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: apiURL + "/account/1"},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", apiURL+"/account/1", key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	res, err := registrar.UpdateContact([]string{"foo@example.com", "bar@example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/account/1", res.URI)
+	assert.Equal(t, []string{"mailto:foo@example.com", "mailto:bar@example.com"}, res.Body.Contact)
+
+	_, err = registrar.UpdateContact(nil)
+	require.Error(t, err)
+}
+
+func TestRegistrar_AgreeToTermsOfService(t *testing.T) {
+	mux, apiURL, tearDown := tester.SetupFakeAPI()
+	defer tearDown()
+
+	mux.HandleFunc("/account/1", echoAccount)
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err, "Could not generate test key")
+
+	user := mockUser{
+		email:      "test@test.com",
+		regres:     &Resource{URI: apiURL + "/account/1"},
+		privatekey: key,
+	}
+
+	core, err := api.New(http.DefaultClient, "lego-test", apiURL+"/dir", apiURL+"/account/1", key)
+	require.NoError(t, err)
+
+	registrar := NewRegistrar(core, user)
+
+	res, err := registrar.AgreeToTermsOfService()
+	require.NoError(t, err)
+
+	assert.Equal(t, apiURL+"/account/1", res.URI)
+	assert.True(t, res.Body.TermsOfServiceAgreed)
+	assert.Empty(t, res.Body.Contact)
+}
+
+// echoAccount responds with the account sent in the JWS payload.
+func echoAccount(w http.ResponseWriter, r *http.Request) {
+	var jws struct {
+		Payload string `json:"payload"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&jws)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var account acme.Account
+	err = json.Unmarshal(payload, &account)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account.Status = acme.StatusValid
+
+	err = tester.WriteJSONResponse(w, account)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}